@@ -0,0 +1,315 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package dispatch routes Bazel extra actions to the Kythe extractor for the
+// language of the action, and normalizes the VNames of the resulting
+// compilations so that every language agrees on corpus assignment.
+package dispatch // import "kythe.io/kythe/go/extractors/bazel/dispatch"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"kythe.io/kythe/go/extractors/bazel"
+	"kythe.io/kythe/go/extractors/exclude"
+	"kythe.io/kythe/go/platform/analysis/provenance"
+	"kythe.io/kythe/go/platform/kzip"
+	"kythe.io/kythe/go/util/log"
+	"kythe.io/kythe/go/util/vnameutil"
+
+	"google.golang.org/protobuf/proto"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// Placeholders expanded in the arguments of an Extractor.
+const (
+	ExtraActionFile = "$(EXTRA_ACTION_FILE)" // path of the ExtraActionInfo
+	Output          = "$(OUTPUT)"            // path of the kzip to write
+	VNames          = "$(VNAMES)"            // path of the vnames.json rules
+	Corpus          = "$(CORPUS)"            // the default corpus label
+	Release         = "$(RELEASE)"           // root of the Kythe release
+)
+
+// ErrNoExtractor is reported by Run when no extractor is registered for the
+// mnemonic of an extra action.
+var ErrNoExtractor = errors.New("no extractor for action mnemonic")
+
+// An Extractor describes how to invoke a language extractor for the extra
+// actions of one or more Bazel mnemonics.
+type Extractor struct {
+	Mnemonics []string `json:"mnemonics"` // action mnemonics handled
+	Language  string   `json:"language"`  // language label, for logging
+	Path      string   `json:"path"`      // executable to run
+	Args      []string `json:"args"`      // arguments, with placeholders
+}
+
+// ReleaseExtractors returns the extractors shipped in the Kythe release
+// rooted at dir, configured as kythe/extractors/BUILD configures them.
+func ReleaseExtractors(dir string) []*Extractor {
+	positional := []string{ExtraActionFile, Output, VNames}
+	javaJar := func(jar string) []string {
+		return append([]string{"-jar", filepath.Join(Release, "extractors", jar)}, positional...)
+	}
+	return expandRelease(dir, []*Extractor{{
+		Mnemonics: []string{"CppCompile"},
+		Language:  "c++",
+		Path:      "$(RELEASE)/extractors/bazel_cxx_extractor",
+		Args:      positional,
+	}, {
+		Mnemonics: []string{"GoCompilePkg"},
+		Language:  "go",
+		Path:      "$(RELEASE)/extractors/bazel_go_extractor",
+		Args:      append([]string{"-corpus", Corpus}, positional...),
+	}, {
+		Mnemonics: []string{"Javac"},
+		Language:  "java",
+		Path:      "java",
+		Args:      javaJar("bazel_java_extractor.jar"),
+	}, {
+		Mnemonics: []string{"JavaIjar"},
+		Language:  "jvm",
+		Path:      "java",
+		Args:      javaJar("bazel_jvm_extractor.jar"),
+	}, {
+		Mnemonics: []string{"GenProtoDescriptorSet"},
+		Language:  "protobuf",
+		Path:      "$(RELEASE)/extractors/bazel_proto_extractor",
+		Args: []string{
+			"--extra_action=" + ExtraActionFile,
+			"--language=protobuf",
+			"--rules=" + VNames,
+			"--output=" + Output,
+		},
+	}, {
+		Mnemonics: []string{"TypeScriptCompile", "AngularTemplateCompile"},
+		Language:  "typescript",
+		Path:      "$(RELEASE)/extractors/bazel_extract_kzip",
+		Args: []string{
+			"--extra_action=" + ExtraActionFile,
+			`--include=\.(js|json|tsx?|d\.ts)$`,
+			"--language=typescript",
+			"--output=" + Output,
+			"--rules=" + VNames,
+			"--scoped=true",
+			`--source=\.ts$`,
+		},
+	}})
+}
+
+func expandRelease(dir string, exs []*Extractor) []*Extractor {
+	for _, ex := range exs {
+		ex.Path = strings.ReplaceAll(ex.Path, Release, dir)
+		args := make([]string, len(ex.Args))
+		for i, arg := range ex.Args {
+			args[i] = strings.ReplaceAll(arg, Release, dir)
+		}
+		ex.Args = args
+	}
+	return exs
+}
+
+// LoadExtractors reads a JSON array of Extractor records from path.  Any
+// occurrence of $(RELEASE) in the path or arguments of an extractor is
+// replaced by release.
+func LoadExtractors(path, release string) ([]*Extractor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading extractors: %v", err)
+	}
+	var exs []*Extractor
+	if err := json.Unmarshal(data, &exs); err != nil {
+		return nil, fmt.Errorf("parsing extractors: %v", err)
+	}
+	for i, ex := range exs {
+		if ex.Path == "" || len(ex.Mnemonics) == 0 {
+			return nil, fmt.Errorf("extractor %d: missing path or mnemonics", i)
+		}
+	}
+	return expandRelease(release, exs), nil
+}
+
+// A Runner invokes extractors for extra actions.
+type Runner struct {
+	Extractors []*Extractor
+
+	// The default corpus for compilations and inputs whose VNames do not
+	// otherwise specify one.
+	Corpus string
+
+	// The path of the vnames.json file passed to each extractor, and the rules
+	// loaded from it.  The rules are also applied to the required inputs of
+	// each compilation that lack a corpus after extraction.
+	VNames string
+	Rules  vnameutil.Rules
+
+	// If set, compilations excluded by this filter are left out of the output.
+	Exclude *exclude.Filter
+
+	// If set, this provenance is recorded on each compilation written.
+	Provenance *provenance.Provenance
+
+	// If set, the standard output and error of each extractor are copied here.
+	Stdout, Stderr io.Writer
+}
+
+// Lookup returns the extractor registered for mnemonic, or nil.  If several
+// extractors handle the same mnemonic, the first one wins.
+func (r *Runner) Lookup(mnemonic string) *Extractor {
+	for _, ex := range r.Extractors {
+		for _, m := range ex.Mnemonics {
+			if m == mnemonic {
+				return ex
+			}
+		}
+	}
+	return nil
+}
+
+// Run extracts the extra action stored at xaPath into a kzip file at
+// outputPath.  The extractor is chosen by the action mnemonic; if none is
+// registered, Run reports an error wrapping ErrNoExtractor.
+func (r *Runner) Run(ctx context.Context, xaPath, outputPath string) error {
+	info, err := bazel.LoadAction(xaPath)
+	if err != nil {
+		return err
+	}
+	ex := r.Lookup(info.GetMnemonic())
+	if ex == nil {
+		return fmt.Errorf("%w %q (owner %q)", ErrNoExtractor, info.GetMnemonic(), info.GetOwner())
+	}
+	log.Infof("Extracting %s action for %q with %s", info.GetMnemonic(), info.GetOwner(), ex.Path)
+
+	tmp, err := os.MkdirTemp("", "extract_action")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	rawPath := filepath.Join(tmp, "raw.kzip")
+
+	cmd := exec.CommandContext(ctx, ex.Path, r.expand(ex.Args, xaPath, rawPath)...)
+	cmd.Env = append(os.Environ(), "KYTHE_CORPUS="+r.Corpus)
+	if r.VNames != "" {
+		cmd.Env = append(cmd.Env, "KYTHE_VNAMES="+r.VNames)
+	}
+	cmd.Stdout = r.Stdout
+	cmd.Stderr = r.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running %s extractor: %v", ex.Language, err)
+	}
+	return r.normalizeFile(rawPath, outputPath)
+}
+
+func (r *Runner) expand(args []string, xaPath, outputPath string) []string {
+	rep := strings.NewReplacer(
+		ExtraActionFile, xaPath,
+		Output, outputPath,
+		VNames, r.VNames,
+		Corpus, r.Corpus,
+	)
+	out := make([]string, len(args))
+	for i, arg := range args {
+		out[i] = rep.Replace(arg)
+	}
+	return out
+}
+
+func (r *Runner) normalizeFile(inputPath, outputPath string) error {
+	f, err := os.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("extractor output: %v", err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	in, err := kzip.NewReader(f, fi.Size())
+	if err != nil {
+		return fmt.Errorf("reading extractor output: %v", err)
+	}
+	out, err := bazel.NewKZIP(outputPath)
+	if err != nil {
+		return err
+	}
+	if err := r.Normalize(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// Normalize copies every compilation in in to out, filling in the VNames the
+// extractor left without a corpus.  Required inputs are renamed by r.Rules
+// when a rule matches their path and otherwise given r.Corpus; the unit itself
+// is given r.Corpus.  VNames that already have a corpus are left unchanged.
+// Compilations that r.Exclude does not keep are skipped, and r.Provenance, if
+// set, is attached to the rest.
+func (r *Runner) Normalize(out *kzip.Writer, in *kzip.Reader) error {
+	corpus := r.Corpus
+	return in.Scan(func(u *kzip.Unit) error {
+		if keep, err := r.Exclude.Keep(in, u); err != nil {
+			return fmt.Errorf("checking unit %s: %v", u.Digest, err)
+		} else if !keep {
+			log.Infof("Excluding compilation %s", u.Digest)
+			return nil
+		}
+		cu := proto.Clone(u.Proto).(*apb.CompilationUnit)
+		if v := cu.GetVName(); v != nil && v.GetCorpus() == "" {
+			v.Corpus = corpus
+		}
+		for _, ri := range cu.GetRequiredInput() {
+			if ri.GetVName().GetCorpus() != "" {
+				continue
+			}
+			path := ri.GetInfo().GetPath()
+			if v, ok := r.Rules.Apply(path); ok {
+				ri.VName = v
+			} else {
+				v := &spb.VName{Corpus: corpus, Root: ri.GetVName().GetRoot(), Path: ri.GetVName().GetPath()}
+				if v.Path == "" {
+					v.Path = path
+				}
+				ri.VName = v
+			}
+		}
+		if err := provenance.Attach(cu, r.Provenance); err != nil {
+			return fmt.Errorf("recording provenance of unit %s: %v", u.Digest, err)
+		}
+		if _, err := out.CopyUnit(in, &kzip.Unit{Proto: cu, Index: u.Index}); err != nil && err != kzip.ErrUnitExists {
+			return fmt.Errorf("copying unit %s: %v", u.Digest, err)
+		}
+		return nil
+	})
+}
+
+// WriteEmpty writes a kzip file containing no compilations to outputPath, for
+// use when an action is deliberately skipped but Bazel still expects output.
+func WriteEmpty(outputPath string) error {
+	w, err := bazel.NewKZIP(outputPath)
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
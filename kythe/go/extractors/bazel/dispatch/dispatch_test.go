@@ -0,0 +1,231 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dispatch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/extractors/exclude"
+	"kythe.io/kythe/go/platform/analysis/provenance"
+	"kythe.io/kythe/go/platform/kzip"
+	"kythe.io/kythe/go/util/vnameutil"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+	xapb "kythe.io/third_party/bazel/extra_actions_base_go_proto"
+)
+
+const testRules = `[
+  {"pattern": "third_party/(.*)", "vname": {"corpus": "vendor", "path": "@1@"}}
+]`
+
+func writeKzip(t *testing.T, path string, units ...*apb.CompilationUnit) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := kzip.NewWriteCloser(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cu := range units {
+		for _, ri := range cu.GetRequiredInput() {
+			digest, err := w.AddFile(strings.NewReader(ri.GetInfo().GetPath()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ri.Info.Digest = digest
+		}
+		if _, err := w.AddUnit(cu, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readUnits(t *testing.T, path string) []*apb.CompilationUnit {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := kzip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var units []*apb.CompilationUnit
+	if err := r.Scan(func(u *kzip.Unit) error {
+		units = append(units, u.Proto)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return units
+}
+
+func input(path string, v *spb.VName) *apb.CompilationUnit_FileInput {
+	return &apb.CompilationUnit_FileInput{
+		VName: v,
+		Info:  &apb.FileInfo{Path: path},
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	rules, err := vnameutil.ParseRules([]byte(testRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The "extractor" copies a canned kzip to its output.
+	canned := filepath.Join(dir, "canned.kzip")
+	writeKzip(t, canned, &apb.CompilationUnit{
+		VName: &spb.VName{Language: "c++", Signature: "//foo:bar"},
+		RequiredInput: []*apb.CompilationUnit_FileInput{
+			input("foo/bar.cc", nil),
+			input("third_party/baz.h", &spb.VName{Path: "third_party/baz.h"}),
+			input("gen/quux.h", &spb.VName{Corpus: "other", Root: "gen", Path: "quux.h"}),
+		},
+	})
+	xa := filepath.Join(dir, "action.xa")
+	data, err := proto.Marshal(&xapb.ExtraActionInfo{
+		Owner:    proto.String("//foo:bar"),
+		Mnemonic: proto.String("CppCompile"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(xa, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Runner{
+		Extractors: []*Extractor{{
+			Mnemonics: []string{"CppCompile"},
+			Language:  "c++",
+			Path:      "cp",
+			Args:      []string{canned, Output},
+		}},
+		Corpus: "kythe",
+		Rules:  rules,
+	}
+	out := filepath.Join(dir, "out.kzip")
+	if err := r.Run(context.Background(), xa, out); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	got := readUnits(t, out)
+	if len(got) != 1 {
+		t.Fatalf("Got %d units, want 1", len(got))
+	}
+	if diff := cmp.Diff(&spb.VName{Corpus: "kythe", Language: "c++", Signature: "//foo:bar"}, got[0].GetVName(), protocmp.Transform()); diff != "" {
+		t.Errorf("Unit VName (-want +got):\n%s", diff)
+	}
+	var gotInputs []*spb.VName
+	for _, ri := range got[0].GetRequiredInput() {
+		gotInputs = append(gotInputs, ri.GetVName())
+	}
+	sort.Slice(gotInputs, func(i, j int) bool { return gotInputs[i].GetPath() < gotInputs[j].GetPath() })
+	wantInputs := []*spb.VName{
+		{Corpus: "vendor", Path: "baz.h"},
+		{Corpus: "kythe", Path: "foo/bar.cc"},
+		{Corpus: "other", Root: "gen", Path: "quux.h"},
+	}
+	if diff := cmp.Diff(wantInputs, gotInputs, protocmp.Transform()); diff != "" {
+		t.Errorf("Input VNames (-want +got):\n%s", diff)
+	}
+
+	// An excluded compilation is left out, and accounted for.
+	excluded, err := (&exclude.Config{Languages: []string{"c++"}}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Exclude = exclude.NewFilter(excluded)
+	if err := r.Run(context.Background(), xa, out); err != nil {
+		t.Fatalf("Run with exclusions failed: %v", err)
+	}
+	if got := readUnits(t, out); len(got) != 0 {
+		t.Errorf("Got %d units with exclusions, want 0", len(got))
+	}
+	if m := r.Exclude.Manifest(); m.Units != 0 || m.SkippedByReason[exclude.ReasonLanguage] != 1 {
+		t.Errorf("Manifest: got %+v, want one unit skipped by language", m)
+	}
+	r.Exclude = nil
+
+	// Provenance is recorded on each compilation written.
+	r.Provenance = &provenance.Provenance{Commit: "abc123"}
+	if err := r.Run(context.Background(), xa, out); err != nil {
+		t.Fatalf("Run with provenance failed: %v", err)
+	}
+	if got := readUnits(t, out); len(got) != 1 || provenance.FromUnit(got[0]) == nil || provenance.FromUnit(got[0]).Commit != "abc123" {
+		t.Errorf("Got units %v, want one with provenance %+v", got, r.Provenance)
+	}
+
+	// An action nobody handles is reported as such.
+	r.Extractors[0].Mnemonics = []string{"Javac"}
+	if err := r.Run(context.Background(), xa, out); !errors.Is(err, ErrNoExtractor) {
+		t.Errorf("Run with no extractor: got %v, want %v", err, ErrNoExtractor)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	r := &Runner{Corpus: "C", VNames: "v.json"}
+	got := r.expand([]string{"--extra_action=" + ExtraActionFile, Output, VNames, "-corpus", Corpus}, "a.xa", "o.kzip")
+	want := []string{"--extra_action=a.xa", "o.kzip", "v.json", "-corpus", "C"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("expand (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadExtractors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "extractors.json")
+	if err := os.WriteFile(path, []byte(`[{
+  "mnemonics": ["Foo"],
+  "language": "foo",
+  "path": "$(RELEASE)/foo_extractor",
+  "args": ["$(EXTRA_ACTION_FILE)", "$(OUTPUT)"]
+}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadExtractors(path, "/opt/kythe")
+	if err != nil {
+		t.Fatalf("LoadExtractors failed: %v", err)
+	}
+	want := []*Extractor{{
+		Mnemonics: []string{"Foo"},
+		Language:  "foo",
+		Path:      "/opt/kythe/foo_extractor",
+		Args:      []string{ExtraActionFile, Output},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LoadExtractors (-want +got):\n%s", diff)
+	}
+}
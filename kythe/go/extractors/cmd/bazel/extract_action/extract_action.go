@@ -0,0 +1,141 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Program extract_action implements a Bazel extra action that captures a Kythe
+// compilation record for any supported action.  It chooses the language
+// extractor by the mnemonic of the action, runs it with a shared corpus and
+// vnames.json configuration, and fills in any VNames the extractor left
+// without a corpus, so that every language produces consistent names.
+//
+// Usage:
+//
+//	extract_action --corpus C --vnames vnames.json \
+//	  --extra_action $(EXTRA_ACTION_FILE) --output $(output $(ACTION_ID).kzip)
+//
+// By default the extractors of the Kythe release at --release are used, wired
+// as in kythe/extractors/BUILD.  Use --extractors to supply a JSON array of
+// records of the form
+//
+//	{"mnemonics": ["CppCompile"], "language": "c++",
+//	 "path": "$(RELEASE)/extractors/bazel_cxx_extractor",
+//	 "args": ["$(EXTRA_ACTION_FILE)", "$(OUTPUT)", "$(VNAMES)"]}
+//
+// The placeholders $(EXTRA_ACTION_FILE), $(OUTPUT), $(VNAMES), $(CORPUS), and
+// $(RELEASE) are replaced in the path and arguments of each extractor.
+//
+// With --exclusions, compilations matching the exclusion rules in the given
+// JSON file (see package kythe.io/kythe/go/extractors/exclude) are left out of
+// the output, and --manifest names a file to which the compilations kept and
+// skipped are reported.
+//
+// If any of the KYTHE_PROVENANCE_COMMIT, KYTHE_PROVENANCE_BUILD_TIME, or
+// KYTHE_PROVENANCE_TOOLCHAIN environment variables is set, the provenance they
+// describe is recorded on each compilation (see package
+// kythe.io/kythe/go/platform/analysis/provenance).
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"time"
+
+	"kythe.io/kythe/go/extractors/bazel/dispatch"
+	"kythe.io/kythe/go/extractors/exclude"
+	"kythe.io/kythe/go/platform/analysis/provenance"
+	"kythe.io/kythe/go/util/log"
+	"kythe.io/kythe/go/util/vnameutil"
+)
+
+var (
+	extraAction    = flag.String("extra_action", "", "Path of blaze.ExtraActionInfo file (required)")
+	outputPath     = flag.String("output", "", "Path of output kzip file (required)")
+	corpus         = flag.String("corpus", "", "Default corpus for compilations and their inputs (required)")
+	vnamesPath     = flag.String("vnames", "", "Path of vnames.json file (optional)")
+	releaseDir     = flag.String("release", "/opt/kythe", "Root directory of the Kythe release")
+	extractorsPath = flag.String("extractors", "", "Path of a JSON extractor configuration (if empty, use the release extractors)")
+	allowUnknown   = flag.Bool("allow_unknown", false, "Write an empty kzip for actions with no registered extractor instead of failing")
+	exclusions     = flag.String("exclusions", "", "Path of a JSON file of exclusion rules (optional)")
+	manifestPath   = flag.String("manifest", "", "Path of the manifest of kept and skipped compilations to write (requires --exclusions)")
+)
+
+func main() {
+	flag.Parse()
+
+	// Verify that required flags are set.
+	switch {
+	case *extraAction == "":
+		log.Fatal("You must provide a non-empty --extra_action file path")
+	case *outputPath == "":
+		log.Fatal("You must provide a non-empty --output file path")
+	case *corpus == "":
+		log.Fatal("You must provide a non-empty --corpus label")
+	case *manifestPath != "" && *exclusions == "":
+		log.Fatal("The --manifest flag requires --exclusions")
+	}
+
+	r := &dispatch.Runner{
+		Corpus:     *corpus,
+		Provenance: provenance.FromEnv(),
+		Stdout:     os.Stderr,
+		Stderr:     os.Stderr,
+	}
+	if *vnamesPath != "" {
+		abs, err := filepath.Abs(*vnamesPath)
+		if err != nil {
+			log.Fatalf("Invalid --vnames path: %v", err)
+		}
+		rules, err := vnameutil.LoadRules(abs)
+		if err != nil {
+			log.Fatalf("Loading rules: %v", err)
+		}
+		r.VNames, r.Rules = abs, rules
+	}
+	if *extractorsPath != "" {
+		exs, err := dispatch.LoadExtractors(*extractorsPath, *releaseDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		r.Extractors = exs
+	} else {
+		r.Extractors = dispatch.ReleaseExtractors(*releaseDir)
+	}
+	if *exclusions != "" {
+		rules, err := exclude.LoadRules(*exclusions)
+		if err != nil {
+			log.Fatalf("Loading exclusions: %v", err)
+		}
+		r.Exclude = exclude.NewFilter(rules)
+	}
+
+	start := time.Now()
+	err := r.Run(context.Background(), *extraAction, *outputPath)
+	if errors.Is(err, dispatch.ErrNoExtractor) && *allowUnknown {
+		log.Warningf("Skipping action: %v", err)
+		err = dispatch.WriteEmpty(*outputPath)
+	}
+	if err != nil {
+		log.Fatalf("Extraction failed: %v", err)
+	}
+	if *manifestPath != "" {
+		if err := r.Exclude.WriteManifest(*manifestPath); err != nil {
+			log.Fatalf("Writing manifest: %v", err)
+		}
+	}
+	log.Infof("Finished extracting [%v elapsed]", time.Since(start))
+}
@@ -0,0 +1,218 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary extract_delta re-extracts only the compilations invalidated by the
+// changes between two revisions of a git repository.
+//
+// Given the compilations previously extracted at --base, extract_delta asks
+// git which files changed up to --head, and runs --extract_command in the
+// repository once per invalidated build target to write replacements into
+// --output_dir.  The digests of the invalidated compilations are written, one
+// per line, to the tombstones file.  The repository should be checked out at
+// --head.  Changed files that no compilation reads, such as new sources, are
+// not extracted; they are reported, or with --fail_on_uncovered, fail the run.
+//
+// With --remote_cache, each re-extracted compilation is also stored in the
+// HTTP cache at the given URL, keyed by its unit digest, so that later runs on
+// other machines can fetch it (e.g., with "kzip download") rather than
+// extracting it again.
+//
+// Example:
+//
+//	extract_delta --repo . --base $OLD --head HEAD \
+//	  --corpus github.com/org/repo --output_dir delta \
+//	  --extract_command 'bazel build --config=kythe "$KYTHE_TARGET"' \
+//	  nightly.kzip
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"kythe.io/kythe/go/extractors/incremental"
+	"kythe.io/kythe/go/platform/kzip"
+	"kythe.io/kythe/go/platform/remotecache"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+)
+
+var (
+	repoDir       = flag.String("repo", ".", "Path of the git repository")
+	baseRev       = flag.String("base", "", "Revision at which the given compilations were extracted (required)")
+	headRev       = flag.String("head", "HEAD", "Revision to extract")
+	corpus        = flag.String("corpus", "", "If set, only inputs in this corpus belong to the repository")
+	prefix        = flag.String("prefix", "", "Path of the repository relative to the working directory of the compilations")
+	command       = flag.String("extract_command", "", "Shell command that re-extracts $KYTHE_TARGET into $KYTHE_OUTPUT_DIRECTORY")
+	outputDir     = flag.String("output_dir", "", "Directory for re-extracted .kzip files (required unless --dry_run)")
+	tombstones    = flag.String("tombstones", "", "Path of the tombstone list (default <output_dir>/tombstones.txt)")
+	dryRun        = flag.Bool("dry_run", false, "Print the invalidated compilations without extracting")
+	failUncovered = flag.Bool("fail_on_uncovered", false, "Fail if any added or modified file is read by none of the given compilations")
+	remote        = flag.String("remote_cache", "", "If set, store the re-extracted compilations in the HTTP cache at this URL")
+)
+
+func init() {
+	flag.Usage = flagutil.SimpleUsage(
+		"Re-extract the compilations invalidated by changes between two git revisions",
+		"--base rev", "[--head rev]", "--extract_command cmd", "--output_dir dir", "<kzip-file>...")
+}
+
+func main() {
+	flag.Parse()
+	switch {
+	case flag.NArg() == 0:
+		flagutil.UsageError("no .kzip files given")
+	case *baseRev == "":
+		flagutil.UsageError("missing --base")
+	case !*dryRun && *outputDir == "":
+		flagutil.UsageError("missing --output_dir")
+	case !*dryRun && *command == "":
+		flagutil.UsageError("missing --extract_command")
+	}
+
+	ctx := context.Background()
+	changes, err := incremental.GitChanges(ctx, *repoDir, *baseRev, *headRev)
+	if err != nil {
+		log.Fatalf("Finding changes: %v", err)
+	}
+	log.Infof("Found %d changed files between %s and %s", changes.Len(), *baseRev, *headRev)
+
+	opts := &incremental.Options{Corpus: *corpus, Prefix: *prefix}
+	plan := new(incremental.Plan)
+	for _, path := range flag.Args() {
+		if err := addPlan(plan, path, changes, opts); err != nil {
+			log.Fatalf("Reading %s: %v", path, err)
+		}
+	}
+	log.Infof("%d compilations unchanged, %d stale", len(plan.Unchanged), len(plan.Stale))
+	if uncovered := plan.Uncovered(changes); len(uncovered) > 0 {
+		const msg = "%d changed files are read by no compilation and will not be extracted: %s"
+		if *failUncovered {
+			log.Fatalf(msg, len(uncovered), strings.Join(uncovered, ", "))
+		}
+		log.Warningf(msg, len(uncovered), strings.Join(uncovered, ", "))
+	}
+
+	if *dryRun {
+		for _, s := range plan.Stale {
+			status := "stale"
+			if s.Deleted {
+				status = "deleted"
+			}
+			fmt.Printf("%s\t%s\t%s\t%s\n", s.Digest, status, s.Target, strings.Join(s.Changed, " "))
+		}
+		return
+	}
+
+	out, err := filepath.Abs(*outputDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.MkdirAll(out, 0755); err != nil {
+		log.Fatalf("Creating output directory: %v", err)
+	}
+	tombPath := *tombstones
+	if tombPath == "" {
+		tombPath = filepath.Join(out, "tombstones.txt")
+	}
+	if err := writeLines(tombPath, plan.Tombstones()); err != nil {
+		log.Fatalf("Writing tombstones: %v", err)
+	}
+	if failed := incremental.Reextract(ctx, plan, *repoDir, out, *command); failed != 0 {
+		log.Fatalf("Failed to re-extract %d targets", failed)
+	}
+	if *remote != "" {
+		c, err := remotecache.New(*remote)
+		if err != nil {
+			log.Fatal(err)
+		}
+		n, err := uploadUnits(ctx, c, out)
+		if err != nil {
+			log.Fatalf("Uploading to %v: %v", c, err)
+		}
+		log.Infof("Stored %d compilations in %v", n, c)
+	}
+}
+
+// uploadUnits stores the compilations in each .kzip file in dir in the given
+// cache, returning the number stored.
+func uploadUnits(ctx context.Context, c *remotecache.Client, dir string) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.kzip"))
+	if err != nil {
+		return 0, err
+	}
+	var total int
+	for _, path := range paths {
+		r, f, err := openKzip(path)
+		if err != nil {
+			return total, fmt.Errorf("reading %s: %v", path, err)
+		}
+		n, err := remotecache.PutUnits(ctx, c, r)
+		f.Close()
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	return total, nil
+}
+
+// openKzip opens the .kzip file at path, returning a reader for it and the
+// file, which the caller must close.
+func openKzip(path string) (*kzip.Reader, *os.File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	r, err := kzip.NewReader(f, fi.Size())
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return r, f, nil
+}
+
+// addPlan merges the plan for the compilations in the kzip at path into p.
+func addPlan(p *incremental.Plan, path string, changes *incremental.Changes, opts *incremental.Options) error {
+	r, f, err := openKzip(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	next, err := incremental.NewPlan(r, changes, opts)
+	if err != nil {
+		return err
+	}
+	p.Unchanged = append(p.Unchanged, next.Unchanged...)
+	p.Stale = append(p.Stale, next.Stale...)
+	return nil
+}
+
+func writeLines(path string, lines []string) error {
+	var text string
+	for _, line := range lines {
+		text += line + "\n"
+	}
+	return os.WriteFile(path, []byte(text), 0644)
+}
@@ -31,6 +31,7 @@ import (
 
 	"kythe.io/kythe/go/extractors/golang"
 	"kythe.io/kythe/go/platform/analysis"
+	"kythe.io/kythe/go/platform/analysis/provenance"
 	"kythe.io/kythe/go/platform/kzip"
 	"kythe.io/kythe/go/platform/vfs"
 	"kythe.io/kythe/go/util/flagutil"
@@ -44,6 +45,7 @@ var (
 
 	corpus     = flag.String("corpus", "", "Default corpus name to use")
 	rulesFile  = flag.String("rules", "", "Path to vnames.json file that maps file paths to output corpus, root, and path.")
+	modRules   = flag.String("module_rules", "", "Path to vnames.json file that maps module@version/path for third-party files to output corpus, root, and path.")
 	outputPath = flag.String("output", "", "KZip output path")
 	extraFiles = flag.String("extra_files", "", "Additional files to include in each compilation (CSV)")
 	byDir      = flag.Bool("bydir", false, "Import by directory rather than import path")
@@ -112,6 +114,14 @@ func main() {
 			log.Fatalf("loading rules file: %v", err)
 		}
 	}
+	var moduleRules vnameutil.Rules
+	if *modRules != "" {
+		var err error
+		moduleRules, err = vnameutil.LoadRules(*modRules)
+		if err != nil {
+			log.Fatalf("loading module rules file: %v", err)
+		}
+	}
 
 	ctx := context.Background()
 	ext := &golang.Extractor{
@@ -125,6 +135,7 @@ func main() {
 			UseDefaultCorpusForStdLib: *useDefaultCorpusForStdLib,
 			UseDefaultCorpusForDeps:   *useDefaultCorpusForDeps,
 		},
+		ModuleRules: moduleRules,
 	}
 	if *extraFiles != "" {
 		ext.ExtraFiles = strings.Split(*extraFiles, ",")
@@ -166,9 +177,13 @@ func main() {
 	if err != nil {
 		maybeFatal("Error creating kzip writer: %v", err)
 	}
+	prov := provenance.FromEnv()
 	for _, pkg := range ext.Packages {
 		maybeLog("Package %q:\n\t// %s", pkg.Path, pkg.BuildPackage.Doc)
 		if err := pkg.EachUnit(ctx, func(cu *apb.CompilationUnit, fetcher analysis.Fetcher) error {
+			if err := provenance.Attach(cu, prov); err != nil {
+				return err
+			}
 			if _, err := w.AddUnit(cu, nil); err != nil {
 				return err
 			}
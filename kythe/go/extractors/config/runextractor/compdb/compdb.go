@@ -23,6 +23,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
@@ -31,6 +33,8 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"kythe.io/kythe/go/extractors/exclude"
+	"kythe.io/kythe/go/platform/kzip"
 	"kythe.io/kythe/go/util/log"
 
 	"bitbucket.org/creachadair/shell"
@@ -43,6 +47,7 @@ type compileCommand struct {
 	Arguments []string
 	Command   string
 	Directory string
+	File      string
 }
 
 func (cc *compileCommand) asCommand() string {
@@ -59,9 +64,42 @@ func (cc *compileCommand) asArguments() ([]string, bool) {
 	return shell.Split(cc.Command)
 }
 
+// shardKey returns a stable key used to assign cc to a shard.  It depends only
+// on the location of the compiled file, so that adding or removing entries does
+// not move the remaining ones between shards.
+func (cc *compileCommand) shardKey() uint32 {
+	h := fnv.New32a()
+	io.WriteString(h, cc.Directory)
+	io.WriteString(h, "\x00")
+	io.WriteString(h, cc.File)
+	if cc.File == "" {
+		io.WriteString(h, cc.asCommand())
+	}
+	return h.Sum32()
+}
+
 // ExtractOptions holds additional options related to compilation DB extraction.
 type ExtractOptions struct {
 	ExtraArguments []string // additional arguments to pass to the extractor
+
+	// If Shards > 1, only the commands assigned to shard number Shard
+	// (0 ≤ Shard < Shards) are extracted.  Commands are assigned to shards by
+	// a hash of their directory and file.
+	Shard, Shards int
+
+	// The maximum number of extractors run concurrently (if ≤ 0, 128).
+	Concurrency int
+
+	// If set, each extractor writes to a private output directory, and all of
+	// the resulting compilations are merged into a single kzip file at this
+	// path.  Otherwise, the extractors write to $KYTHE_OUTPUT_DIRECTORY.
+	Output string
+
+	// If set, commands whose file matches an exclusion path are not run.  If
+	// Output is also set, the extracted compilations are filtered by all the
+	// exclusion rules, and a manifest of the compilations kept and skipped is
+	// written to Output plus exclude.ManifestSuffix.
+	Exclude *exclude.Filter
 }
 
 // ExtractCompilations runs the specified extractor over each compilation record
@@ -71,13 +109,31 @@ func ExtractCompilations(ctx context.Context, extractor, path string, opts *Extr
 	if err != nil {
 		return err
 	}
-	env, err := extractorEnv()
+	commands, err = opts.selectShard(commands)
 	if err != nil {
 		return err
 	}
+	commands = opts.excludePaths(commands)
+	env, err := extractorEnv(opts.output() == "")
+	if err != nil {
+		return err
+	}
+
+	var out *kzip.Writer
+	if path := opts.output(); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("creating output: %v", err)
+		}
+		out, err = kzip.NewWriteCloser(f)
+		if err != nil {
+			f.Close()
+			return err
+		}
+	}
 
 	var failCount uint64
-	sem := semaphore.NewWeighted(128) // Limit concurrency.
+	sem := semaphore.NewWeighted(opts.concurrency()) // Limit concurrency.
 	var wg sync.WaitGroup
 	wg.Add(len(commands))
 	for _, entry := range commands {
@@ -90,7 +146,13 @@ func ExtractCompilations(ctx context.Context, extractor, path string, opts *Extr
 			}
 			defer sem.Release(1)
 
-			if err := extractOne(ctx, extractor, entry, env, opts); err != nil {
+			var err error
+			if out != nil {
+				err = extractAndMerge(ctx, extractor, entry, env, opts, out)
+			} else {
+				err = extractOne(ctx, extractor, entry, env, opts)
+			}
+			if err != nil {
 				// Log error, but continue processing other compilations.
 				atomic.AddUint64(&failCount, 1)
 				log.ErrorContextf(ctx, "extracting compilation with command '%s': %v", entry.asCommand(), err)
@@ -99,6 +161,16 @@ func ExtractCompilations(ctx context.Context, extractor, path string, opts *Extr
 	}
 	wg.Wait()
 
+	if out != nil {
+		if err := out.Close(); err != nil {
+			return fmt.Errorf("closing output: %v", err)
+		}
+		if f := opts.filter(); f != nil {
+			if err := f.WriteManifest(opts.output() + exclude.ManifestSuffix); err != nil {
+				return fmt.Errorf("writing manifest: %v", err)
+			}
+		}
+	}
 	if failCount != 0 {
 		return fmt.Errorf("Failed to extract %d compilations", failCount)
 	}
@@ -106,6 +178,50 @@ func ExtractCompilations(ctx context.Context, extractor, path string, opts *Extr
 	return nil
 }
 
+// extractAndMerge invokes the extractor for the given compileCommand with a
+// private output directory, and merges whatever it writes there into out.
+func extractAndMerge(ctx context.Context, extractor string, cc compileCommand, env []string, opts *ExtractOptions, out *kzip.Writer) error {
+	dir, err := ioutil.TempDir("", "compdb")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	env = append(env[:len(env):len(env)], "KYTHE_OUTPUT_DIRECTORY="+dir)
+	if err := extractOne(ctx, extractor, cc, env, opts); err != nil {
+		return err
+	}
+	kzips, err := filepath.Glob(filepath.Join(dir, "*.kzip"))
+	if err != nil {
+		return err
+	}
+	for _, path := range kzips {
+		if err := mergeFile(out, path, opts.filter()); err != nil {
+			return fmt.Errorf("merging %s: %v", filepath.Base(path), err)
+		}
+	}
+	return nil
+}
+
+// mergeFile copies the compilations in the kzip at path that filter keeps
+// into out.
+func mergeFile(out *kzip.Writer, path string, filter *exclude.Filter) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	r, err := kzip.NewReader(f, fi.Size())
+	if err != nil {
+		return err
+	}
+	return filter.Copy(out, r)
+}
+
 // extractOne invokes the extractor for the given compileCommand.
 func extractOne(ctx context.Context, extractor string, cc compileCommand, env []string, opts *ExtractOptions) error {
 	cmd := exec.CommandContext(ctx, extractor, "--with_executable")
@@ -144,8 +260,10 @@ func readCommands(path string) ([]compileCommand, error) {
 	return commands, nil
 }
 
-// extractorEnv copies the existing environment and modifies it to be suitable for an extractor invocation.
-func extractorEnv() ([]string, error) {
+// extractorEnv copies the existing environment and modifies it to be suitable
+// for an extractor invocation.  If needOutput is false, any existing
+// KYTHE_OUTPUT_DIRECTORY is dropped, and the caller must supply its own.
+func extractorEnv(needOutput bool) ([]string, error) {
 	var env []string
 	outputFound := false
 	for _, value := range os.Environ() {
@@ -154,6 +272,9 @@ func extractorEnv() ([]string, error) {
 		if parts[0] == "KYTHE_INDEX_PACK" || parts[0] == "KYTHE_OUTPUT_FILE" {
 			continue
 		} else if parts[0] == "KYTHE_OUTPUT_DIRECTORY" {
+			if !needOutput {
+				continue
+			}
 			// Remap KYTHE_OUTPUT_DIRECTORY to be an absolute path.
 			output, err := filepath.Abs(parts[1])
 			if err != nil {
@@ -167,7 +288,7 @@ func extractorEnv() ([]string, error) {
 		}
 
 	}
-	if !outputFound {
+	if needOutput && !outputFound {
 		return nil, errors.New("missing mandatory environment variable: KYTHE_OUTPUT_DIRECTORY")
 	}
 	return env, nil
@@ -180,3 +301,61 @@ func (o *ExtractOptions) extraArguments() []string {
 	}
 	return nil
 }
+
+// selectShard returns the subset of commands assigned to the selected shard.
+func (o *ExtractOptions) selectShard(commands []compileCommand) ([]compileCommand, error) {
+	if o == nil || o.Shards <= 1 {
+		return commands, nil
+	} else if o.Shard < 0 || o.Shard >= o.Shards {
+		return nil, fmt.Errorf("shard %d out of range [0, %d)", o.Shard, o.Shards)
+	}
+	var selected []compileCommand
+	for _, cc := range commands {
+		if int(cc.shardKey()%uint32(o.Shards)) == o.Shard {
+			selected = append(selected, cc)
+		}
+	}
+	return selected, nil
+}
+
+// excludePaths returns the commands whose file is not excluded.
+func (o *ExtractOptions) excludePaths(commands []compileCommand) []compileCommand {
+	f := o.filter()
+	if f == nil {
+		return commands
+	}
+	var kept []compileCommand
+	for _, cc := range commands {
+		if !f.SkipPath(cc.File) {
+			kept = append(kept, cc)
+		}
+	}
+	if n := len(commands) - len(kept); n > 0 {
+		log.Infof("Skipping %d excluded compilations", n)
+	}
+	return kept
+}
+
+// filter returns the exclusion filter, or nil.
+func (o *ExtractOptions) filter() *exclude.Filter {
+	if o != nil {
+		return o.Exclude
+	}
+	return nil
+}
+
+// concurrency returns the maximum number of concurrent extractions.
+func (o *ExtractOptions) concurrency() int64 {
+	if o != nil && o.Concurrency > 0 {
+		return int64(o.Concurrency)
+	}
+	return 128
+}
+
+// output returns the path of the aggregate output file, or "".
+func (o *ExtractOptions) output() string {
+	if o != nil {
+		return o.Output
+	}
+	return ""
+}
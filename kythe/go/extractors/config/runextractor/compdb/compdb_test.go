@@ -18,12 +18,20 @@ package compdb
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
+	"kythe.io/kythe/go/extractors/exclude"
 	"kythe.io/kythe/go/platform/kzip"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
 )
 
 const (
@@ -106,3 +114,163 @@ func TestExtractCompilationsEndToEnd(t *testing.T) {
 		testExtractCompilationsEndToEndWithDatabase(t, "compilation_database_arguments.json")
 	})
 }
+
+func TestSelectShard(t *testing.T) {
+	var commands []compileCommand
+	for i := 0; i < 50; i++ {
+		commands = append(commands, compileCommand{Directory: "/src", File: fmt.Sprintf("f%d.cc", i)})
+	}
+	const shards = 4
+	seen := make(map[string]int)
+	for shard := 0; shard < shards; shard++ {
+		opts := &ExtractOptions{Shard: shard, Shards: shards}
+		got, err := opts.selectShard(commands)
+		if err != nil {
+			t.Fatalf("selectShard(%d): %v", shard, err)
+		}
+		for _, cc := range got {
+			seen[cc.File]++
+		}
+		// Selection is stable, and independent of the other commands.
+		for _, cc := range got {
+			again, err := opts.selectShard([]compileCommand{cc})
+			if err != nil || len(again) != 1 {
+				t.Errorf("selectShard(%d) of %q alone: got %v, %v", shard, cc.File, again, err)
+			}
+		}
+	}
+	if len(seen) != len(commands) {
+		t.Errorf("Shards covered %d commands, want %d", len(seen), len(commands))
+	}
+	for file, n := range seen {
+		if n != 1 {
+			t.Errorf("Command for %q selected by %d shards, want 1", file, n)
+		}
+	}
+
+	if _, err := (&ExtractOptions{Shard: 4, Shards: 4}).selectShard(commands); err == nil {
+		t.Error("selectShard with shard out of range: got nil error")
+	}
+}
+
+// writeUnit writes a kzip containing a single compilation for source to path.
+func writeUnit(t *testing.T, path, source string, size int) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := kzip.NewWriteCloser(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := w.AddFile(strings.NewReader(strings.Repeat("x", size)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.AddUnit(&apb.CompilationUnit{
+		VName:      &spb.VName{Language: "c++", Signature: source},
+		SourceFile: []string{source},
+		RequiredInput: []*apb.CompilationUnit_FileInput{{
+			Info: &apb.FileInfo{Path: source, Digest: digest},
+		}},
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakeDatabase writes a compilation database to dir with one command for each
+// of the named sources, and an extractor that "extracts" a unit whose single
+// input has the given size.  It returns the paths of the extractor and the
+// database.
+func fakeDatabase(t *testing.T, dir string, sizes map[string]int) (extractor, dbPath string) {
+	t.Helper()
+
+	// The fake extractor copies the unit.kzip from its working directory into
+	// its output directory, which verifies both the directory and environment.
+	extractor = filepath.Join(dir, "extractor.sh")
+	if err := os.WriteFile(extractor, []byte("#!/bin/sh\ncp unit.kzip \"$KYTHE_OUTPUT_DIRECTORY/out.kzip\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	var db []string
+	for name, size := range sizes {
+		sub := filepath.Join(dir, name)
+		if err := os.Mkdir(sub, 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeUnit(t, filepath.Join(sub, "unit.kzip"), name+".cc", size)
+		db = append(db, fmt.Sprintf(`{"directory": %q, "command": "cc -c %s.cc", "file": "%s.cc"}`, sub, name, name))
+	}
+	dbPath = filepath.Join(dir, "compile_commands.json")
+	if err := os.WriteFile(dbPath, []byte("["+strings.Join(db, ",")+"]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return extractor, dbPath
+}
+
+// readSources returns the sorted source files of the units in the kzip at path.
+func readSources(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []string
+	if err := kzip.Scan(f, func(_ *kzip.Reader, unit *kzip.Unit) error {
+		got = append(got, unit.Proto.SourceFile...)
+		return nil
+	}); err != nil {
+		t.Fatalf("Reading output: %v", err)
+	}
+	sort.Strings(got)
+	return got
+}
+
+func TestExtractCompilationsMerged(t *testing.T) {
+	dir := t.TempDir()
+	extractor, dbPath := fakeDatabase(t, dir, map[string]int{"a": 1, "b": 1})
+
+	output := filepath.Join(dir, "all.kzip")
+	if err := ExtractCompilations(context.Background(), extractor, dbPath, &ExtractOptions{Output: output}); err != nil {
+		t.Fatalf("ExtractCompilations: %v", err)
+	}
+	if got, want := readSources(t, output), []string{"a.cc", "b.cc"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Merged sources: got %v, want %v", got, want)
+	}
+}
+
+func TestExtractCompilationsExcluded(t *testing.T) {
+	dir := t.TempDir()
+	extractor, dbPath := fakeDatabase(t, dir, map[string]int{"a": 1, "b": 1, "big": 2048})
+	rules, err := (&exclude.Config{Paths: []string{"^b\\."}, MaxFileSize: "1KiB"}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "all.kzip")
+	opts := &ExtractOptions{Output: output, Exclude: exclude.NewFilter(rules)}
+	if err := ExtractCompilations(context.Background(), extractor, dbPath, opts); err != nil {
+		t.Fatalf("ExtractCompilations: %v", err)
+	}
+	if got, want := readSources(t, output), []string{"a.cc"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Merged sources: got %v, want %v", got, want)
+	}
+
+	f, err := os.Open(output + exclude.ManifestSuffix)
+	if err != nil {
+		t.Fatalf("Opening manifest: %v", err)
+	}
+	defer f.Close()
+	var m exclude.Manifest
+	if err := json.NewDecoder(f).Decode(&m); err != nil {
+		t.Fatalf("Reading manifest: %v", err)
+	}
+	want := map[string]int{exclude.ReasonPath: 1, exclude.ReasonFileSize: 1}
+	if m.Units != 1 || len(m.Skipped) != 2 || !reflect.DeepEqual(m.SkippedByReason, want) {
+		t.Errorf("Manifest: got %+v, want 1 unit and skips %v", m, want)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"path/filepath"
 
 	"kythe.io/kythe/go/extractors/config/runextractor/compdb"
+	"kythe.io/kythe/go/extractors/exclude"
 	"kythe.io/kythe/go/util/cmdutil"
 
 	"github.com/google/subcommands"
@@ -33,8 +34,13 @@ import (
 type compdbCommand struct {
 	cmdutil.Info
 
-	extractor string
-	path      string
+	extractor   string
+	path        string
+	output      string
+	shard       int
+	shards      int
+	concurrency int
+	exclusions  string
 }
 
 // New creates a new subcommand for running compdb extraction.
@@ -53,10 +59,18 @@ Any flags specified in [extractor_args...] will be passed verbatim to the chosen
 func (c *compdbCommand) SetFlags(fs *flag.FlagSet) {
 	fs.StringVar(&c.extractor, "extractor", "", "A required path to the extractor binary to use.")
 	fs.StringVar(&c.path, "path", "./compile_commands.json", "Path to JSON compilations database.")
+	fs.StringVar(&c.output, "output", "", "If set, merge all extracted compilations into a single kzip at this path instead of writing to $KYTHE_OUTPUT_DIRECTORY.")
+	fs.IntVar(&c.shard, "shard", 0, "Index of the shard of compilations to extract (0 ≤ shard < shards).")
+	fs.IntVar(&c.shards, "shards", 1, "Number of shards to divide the compilations into.")
+	fs.IntVar(&c.concurrency, "concurrency", 0, "Maximum number of concurrent extractor processes (if ≤ 0, use a default).")
+	fs.StringVar(&c.exclusions, "exclusions", "", "If set, path to a JSON file of exclusion rules for the compilations to extract.")
 }
 
 func (c *compdbCommand) checkFlags() error {
 	for _, key := range []string{"KYTHE_CORPUS", "KYTHE_ROOT_DIRECTORY", "KYTHE_OUTPUT_DIRECTORY"} {
+		if key == "KYTHE_OUTPUT_DIRECTORY" && c.output != "" {
+			continue
+		}
 		if os.Getenv(key) == "" {
 			return fmt.Errorf("required %s not set", key)
 		}
@@ -64,6 +78,9 @@ func (c *compdbCommand) checkFlags() error {
 	if c.extractor == "" {
 		return fmt.Errorf("required -extractor not set")
 	}
+	if c.shards < 1 || c.shard < 0 || c.shard >= c.shards {
+		return fmt.Errorf("invalid -shard %d of -shards %d", c.shard, c.shards)
+	}
 	return nil
 }
 
@@ -77,7 +94,28 @@ func (c *compdbCommand) Execute(ctx context.Context, fs *flag.FlagSet, args ...a
 	if err != nil {
 		return c.Fail("Unable to resolve path to extractor: %v", err)
 	}
-	if err := compdb.ExtractCompilations(ctx, extractor, c.path, &compdb.ExtractOptions{ExtraArguments: fs.Args()}); err != nil {
+	output := c.output
+	if output != "" {
+		output, err = filepath.Abs(output)
+		if err != nil {
+			return c.Fail("Unable to resolve output path: %v", err)
+		}
+	}
+	opts := &compdb.ExtractOptions{
+		ExtraArguments: fs.Args(),
+		Shard:          c.shard,
+		Shards:         c.shards,
+		Concurrency:    c.concurrency,
+		Output:         output,
+	}
+	if c.exclusions != "" {
+		rules, err := exclude.LoadRules(c.exclusions)
+		if err != nil {
+			return c.Fail("Unable to load exclusion rules: %v", err)
+		}
+		opts.Exclude = exclude.NewFilter(rules)
+	}
+	if err := compdb.ExtractCompilations(ctx, extractor, c.path, opts); err != nil {
 		return c.Fail("Error extracting repository: %v", err)
 	}
 	return subcommands.ExitSuccess
@@ -0,0 +1,293 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package exclude implements rules for leaving compilations out of the output
+// of extraction, for code that cannot be indexed for legal or practical
+// reasons, and a manifest accounting for the compilations left out.
+//
+// Rules are read from a JSON configuration file, for example:
+//
+//	{
+//	  "paths": ["^third_party/licensed/", "\\.pb\\.cc$"],
+//	  "languages": ["objc"],
+//	  "max_file_size": "16MiB",
+//	  "max_unit_size": "512MiB"
+//	}
+//
+// A compilation is excluded if any of its source files matches one of the
+// path expressions, if its language is listed, if any one of its required
+// inputs is larger than max_file_size, or if its required inputs together are
+// larger than max_unit_size.
+package exclude // import "kythe.io/kythe/go/extractors/exclude"
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+
+	"kythe.io/kythe/go/platform/kzip"
+	"kythe.io/kythe/go/util/datasize"
+	"kythe.io/kythe/go/util/ptypes"
+
+	"bitbucket.org/creachadair/stringset"
+
+	bipb "kythe.io/kythe/proto/buildinfo_go_proto"
+)
+
+// The reasons for which a compilation may be excluded.
+const (
+	ReasonPath     = "path"      // a source file matches a path expression
+	ReasonLanguage = "language"  // the language is excluded
+	ReasonFileSize = "file_size" // a required input is too large
+	ReasonUnitSize = "unit_size" // the required inputs together are too large
+)
+
+// ManifestSuffix is the conventional suffix of a manifest file, appended to
+// the path of the .kzip file it describes.
+const ManifestSuffix = ".manifest.json"
+
+// Config is the JSON encoding of a set of exclusion rules.
+type Config struct {
+	Paths       []string `json:"paths,omitempty"`         // RE2 expressions matched against source paths
+	Languages   []string `json:"languages,omitempty"`     // languages to exclude entirely
+	MaxFileSize string   `json:"max_file_size,omitempty"` // limit on each required input
+	MaxUnitSize string   `json:"max_unit_size,omitempty"` // limit on all required inputs together
+}
+
+// Rules are a compiled Config.
+type Rules struct {
+	paths     []*regexp.Regexp
+	languages stringset.Set
+	maxFile   datasize.Size
+	maxUnit   datasize.Size
+}
+
+// LoadRules reads a JSON Config from the file at path and compiles it.
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", path, err)
+	}
+	r, err := c.Compile()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return r, nil
+}
+
+// Compile checks and compiles the rules of c.
+func (c *Config) Compile() (*Rules, error) {
+	r := &Rules{languages: stringset.New(c.Languages...)}
+	for _, expr := range c.Paths {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path expression: %v", err)
+		}
+		r.paths = append(r.paths, re)
+	}
+	var err error
+	if c.MaxFileSize != "" {
+		if r.maxFile, err = datasize.Parse(c.MaxFileSize); err != nil {
+			return nil, fmt.Errorf("invalid max_file_size: %v", err)
+		}
+	}
+	if c.MaxUnitSize != "" {
+		if r.maxUnit, err = datasize.Parse(c.MaxUnitSize); err != nil {
+			return nil, fmt.Errorf("invalid max_unit_size: %v", err)
+		}
+	}
+	return r, nil
+}
+
+// MatchPath reports whether path matches one of the path expressions of r.
+func (r *Rules) MatchPath(path string) bool {
+	for _, re := range r.paths {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// A Skip records a compilation excluded from the output.
+type Skip struct {
+	Digest   string `json:"digest,omitempty"`   // empty if the unit was never extracted
+	Target   string `json:"target,omitempty"`   // the build target or source file
+	Language string `json:"language,omitempty"` // the language of the compilation
+	Reason   string `json:"reason"`             // one of the Reason constants
+	Detail   string `json:"detail,omitempty"`   // e.g., the path that matched
+}
+
+// Check reports why u, read from r, should be excluded by the rules, or nil
+// if it should be kept.
+func (r *Rules) Check(rd *kzip.Reader, u *kzip.Unit) (*Skip, error) {
+	cu := u.Proto
+	skip := func(reason, detail string) *Skip {
+		return &Skip{
+			Digest:   u.Digest,
+			Target:   target(u),
+			Language: cu.GetVName().GetLanguage(),
+			Reason:   reason,
+			Detail:   detail,
+		}
+	}
+	if lang := cu.GetVName().GetLanguage(); r.languages.Contains(lang) {
+		return skip(ReasonLanguage, lang), nil
+	}
+	for _, src := range cu.GetSourceFile() {
+		if r.MatchPath(src) {
+			return skip(ReasonPath, src), nil
+		}
+	}
+	if r.maxFile == 0 && r.maxUnit == 0 {
+		return nil, nil
+	}
+	var total datasize.Size
+	for _, ri := range cu.GetRequiredInput() {
+		n, err := rd.FileSize(ri.GetInfo().GetDigest())
+		if err != nil {
+			return nil, fmt.Errorf("required input %q: %v", ri.GetInfo().GetPath(), err)
+		}
+		size := datasize.Size(n)
+		if r.maxFile > 0 && size > r.maxFile {
+			return skip(ReasonFileSize, fmt.Sprintf("%s (%v)", ri.GetInfo().GetPath(), size)), nil
+		}
+		total += size
+	}
+	if r.maxUnit > 0 && total > r.maxUnit {
+		return skip(ReasonUnitSize, total.String()), nil
+	}
+	return nil, nil
+}
+
+// target returns the build target of u if it is recorded, otherwise its
+// first source file.
+func target(u *kzip.Unit) string {
+	for _, detail := range u.Proto.GetDetails() {
+		var info bipb.BuildDetails
+		if err := ptypes.UnmarshalAny(detail, &info); err == nil && info.BuildTarget != "" {
+			return info.BuildTarget
+		}
+	}
+	if srcs := u.Proto.GetSourceFile(); len(srcs) > 0 {
+		return srcs[0]
+	}
+	return u.Proto.GetVName().GetSignature()
+}
+
+// A Manifest accounts for the compilations kept and excluded by a Filter.
+type Manifest struct {
+	Units           int            `json:"units"` // compilations kept
+	Skipped         []*Skip        `json:"skipped,omitempty"`
+	SkippedByReason map[string]int `json:"skipped_by_reason,omitempty"`
+}
+
+// A Filter applies Rules to compilations and records the outcome in a
+// Manifest.  A Filter is safe for concurrent use.  A nil *Filter keeps every
+// compilation and records nothing.
+type Filter struct {
+	rules *Rules
+
+	mu sync.Mutex
+	m  Manifest
+}
+
+// NewFilter returns a Filter for the given rules.
+func NewFilter(r *Rules) *Filter { return &Filter{rules: r} }
+
+func (f *Filter) record(s *Skip) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s == nil {
+		f.m.Units++
+		return
+	}
+	f.m.Skipped = append(f.m.Skipped, s)
+	if f.m.SkippedByReason == nil {
+		f.m.SkippedByReason = make(map[string]int)
+	}
+	f.m.SkippedByReason[s.Reason]++
+}
+
+// SkipPath reports whether a compilation of the given source file should be
+// skipped before it is extracted, recording the skip if so.
+func (f *Filter) SkipPath(path string) bool {
+	if f == nil || !f.rules.MatchPath(path) {
+		return false
+	}
+	f.record(&Skip{Target: path, Reason: ReasonPath, Detail: path})
+	return true
+}
+
+// Keep reports whether u, read from r, should be kept, recording the outcome.
+func (f *Filter) Keep(r *kzip.Reader, u *kzip.Unit) (bool, error) {
+	if f == nil {
+		return true, nil
+	}
+	s, err := f.rules.Check(r, u)
+	if err != nil {
+		return false, err
+	}
+	f.record(s)
+	return s == nil, nil
+}
+
+// Copy copies the compilations in r that f keeps to w, as kzip.Merge does.
+func (f *Filter) Copy(w *kzip.Writer, r *kzip.Reader) error {
+	return r.Scan(func(u *kzip.Unit) error {
+		if keep, err := f.Keep(r, u); err != nil {
+			return fmt.Errorf("checking unit %s: %v", u.Digest, err)
+		} else if !keep {
+			return nil
+		}
+		if _, err := w.CopyUnit(r, u); err != nil && err != kzip.ErrUnitExists {
+			return fmt.Errorf("copying unit %s: %v", u.Digest, err)
+		}
+		return nil
+	})
+}
+
+// Manifest returns a copy of the manifest recorded by f, with the skipped
+// compilations sorted by target.
+func (f *Filter) Manifest() *Manifest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m := &Manifest{Units: f.m.Units, Skipped: append([]*Skip(nil), f.m.Skipped...)}
+	sort.SliceStable(m.Skipped, func(i, j int) bool { return m.Skipped[i].Target < m.Skipped[j].Target })
+	if len(f.m.SkippedByReason) > 0 {
+		m.SkippedByReason = make(map[string]int)
+		for k, v := range f.m.SkippedByReason {
+			m.SkippedByReason[k] = v
+		}
+	}
+	return m
+}
+
+// WriteManifest writes the manifest recorded by f as JSON to the file at path.
+func (f *Filter) WriteManifest(path string) error {
+	data, err := json.MarshalIndent(f.Manifest(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
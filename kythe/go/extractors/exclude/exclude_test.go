@@ -0,0 +1,164 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package exclude
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/extractors/bazel"
+	"kythe.io/kythe/go/platform/kzip"
+
+	"github.com/google/go-cmp/cmp"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	bipb "kythe.io/kythe/proto/buildinfo_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// unit returns a compilation of src in lang whose required inputs have the
+// given contents.
+func unit(t *testing.T, w *kzip.Writer, lang, src string, inputs ...string) *apb.CompilationUnit {
+	t.Helper()
+	cu := &apb.CompilationUnit{
+		VName:      &spb.VName{Language: lang, Signature: src},
+		SourceFile: []string{src},
+	}
+	for i, in := range inputs {
+		digest, err := w.AddFile(strings.NewReader(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cu.RequiredInput = append(cu.RequiredInput, &apb.CompilationUnit_FileInput{
+			Info: &apb.FileInfo{Path: src + string(rune('a'+i)), Digest: digest},
+		})
+	}
+	return cu
+}
+
+func TestFilter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := kzip.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withTarget := unit(t, w, "go", "gen/x.go")
+	if err := bazel.AddDetail(withTarget, &bipb.BuildDetails{BuildTarget: "//gen:x"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, cu := range []*apb.CompilationUnit{
+		unit(t, w, "c++", "ok.cc", "small", "small too"),
+		unit(t, w, "objc", "a.m"),
+		withTarget,
+		unit(t, w, "c++", "big.cc", strings.Repeat("x", 20)),
+		unit(t, w, "c++", "many.cc", "0123456789", "0123456789", "0123456789"),
+	} {
+		if _, err := w.AddUnit(cu, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := kzip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := (&Config{
+		Paths:       []string{"^gen/"},
+		Languages:   []string{"objc"},
+		MaxFileSize: "16B",
+		MaxUnitSize: "25B",
+	}).Compile()
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	f := NewFilter(rules)
+	if !f.SkipPath("gen/y.go") || f.SkipPath("ok.cc") {
+		t.Error("SkipPath: got wrong result for gen/y.go or ok.cc")
+	}
+
+	var out bytes.Buffer
+	ow, err := kzip.NewWriter(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Copy(ow, r); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	if err := ow.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var kept []string
+	if err := kzip.Scan(bytes.NewReader(out.Bytes()), func(_ *kzip.Reader, u *kzip.Unit) error {
+		kept = append(kept, u.Proto.SourceFile...)
+		return nil
+	}); err != nil {
+		t.Fatalf("Reading output: %v", err)
+	}
+	if diff := cmp.Diff([]string{"ok.cc"}, kept); diff != "" {
+		t.Errorf("Kept units (-want +got):\n%s", diff)
+	}
+
+	m := f.Manifest()
+	for _, s := range m.Skipped {
+		s.Digest = "" // not stable
+	}
+	want := &Manifest{
+		Units: 1,
+		Skipped: []*Skip{
+			{Target: "//gen:x", Language: "go", Reason: ReasonPath, Detail: "gen/x.go"},
+			{Target: "a.m", Language: "objc", Reason: ReasonLanguage, Detail: "objc"},
+			{Target: "big.cc", Language: "c++", Reason: ReasonFileSize, Detail: "big.cca (20B)"},
+			{Target: "gen/y.go", Reason: ReasonPath, Detail: "gen/y.go"},
+			{Target: "many.cc", Language: "c++", Reason: ReasonUnitSize, Detail: "30B"},
+		},
+		SkippedByReason: map[string]int{
+			ReasonPath:     2,
+			ReasonLanguage: 1,
+			ReasonFileSize: 1,
+			ReasonUnitSize: 1,
+		},
+	}
+	if diff := cmp.Diff(want, m); diff != "" {
+		t.Errorf("Manifest (-want +got):\n%s", diff)
+	}
+}
+
+func TestNilFilter(t *testing.T) {
+	var f *Filter
+	if f.SkipPath("anything") {
+		t.Error("nil Filter skipped a path")
+	}
+	if keep, err := f.Keep(nil, &kzip.Unit{Proto: new(apb.CompilationUnit)}); !keep || err != nil {
+		t.Errorf("nil Filter Keep: got (%v, %v), want (true, nil)", keep, err)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, c := range []*Config{
+		{Paths: []string{"("}},
+		{MaxFileSize: "lots"},
+		{MaxUnitSize: "-"},
+	} {
+		if _, err := c.Compile(); err == nil {
+			t.Errorf("Compile(%+v): got nil error", c)
+		}
+	}
+}
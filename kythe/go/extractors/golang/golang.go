@@ -48,6 +48,7 @@ import (
 	"kythe.io/kythe/go/platform/vfs"
 	"kythe.io/kythe/go/util/log"
 	"kythe.io/kythe/go/util/ptypes"
+	"kythe.io/kythe/go/util/vnameutil"
 
 	"bitbucket.org/creachadair/stringset"
 
@@ -94,7 +95,16 @@ type Extractor struct {
 	// context's GOROOT or GOPATH or the current working directory.
 	DirToImport func(path string) (string, error)
 
-	pmap map[string]*build.Package // Map of import path to build package
+	// Rules for the VNames of files and packages in third-party modules, in
+	// the module cache or a vendor directory.  The rules are matched against
+	// paths of the form module@version/path, where path is relative to the
+	// module root (the @version is omitted if the version is unknown).  If
+	// no rule matches, the corpus is the module path and the path is relative
+	// to the module root.  The general Rules are not applied to these files.
+	ModuleRules vnameutil.Rules
+
+	pmap    map[string]*build.Package // Map of import path to build package
+	modules map[string]*Module        // Map of import path to module, if known
 }
 
 // addPackage imports the specified package, if it has not already been
@@ -132,6 +142,13 @@ func (e *Extractor) findPackage(importPath string) *Package {
 
 // vnameFor returns a vname for the specified package.
 func (e *Extractor) vnameFor(bp *build.Package) *spb.VName {
+	if d := dependencyOf(bp, e.modules[bp.ImportPath]); d != nil {
+		if rel, ok := d.rel(bp.Dir); ok {
+			v := e.depVName(d, rel)
+			v.Language = govname.Language
+			return v
+		}
+	}
 	v := govname.ForPackage(bp, &e.PackageVNameOptions)
 	v.Signature = "" // not useful in this context
 	return v
@@ -172,6 +189,12 @@ func (e *Extractor) Locate(importPath string) ([]*Package, error) {
 		}
 
 		importPath := pkg.ImportPath
+		if pkg.Module != nil {
+			if e.modules == nil {
+				e.modules = make(map[string]*Module)
+			}
+			e.modules[importPath] = pkg.Module
+		}
 		p := e.findPackage(importPath)
 		if p == nil {
 			p = &Package{
@@ -179,6 +202,7 @@ func (e *Extractor) Locate(importPath string) ([]*Package, error) {
 				Path:         importPath,
 				DepOnly:      pkg.DepOnly,
 				BuildPackage: pkg.buildPackage(),
+				Module:       pkg.Module,
 			}
 			e.Packages = append(e.Packages, p)
 			e.mapPackage(importPath, p.BuildPackage)
@@ -243,6 +267,7 @@ type Package struct {
 	DepOnly      bool                   // Whether the package is only seen as a dependency
 	Err          error                  // Error discovered during processing
 	BuildPackage *build.Package         // Package info from the go/build library
+	Module       *Module                // The module providing the package, if known
 	VName        *spb.VName             // The package's Kythe vname
 	Units        []*apb.CompilationUnit // Compilations generated from Package
 }
@@ -385,6 +410,7 @@ func (p *Package) addFiles(cu *apb.CompilationUnit, root, base string, names []s
 	if !strings.HasSuffix(root, "/") {
 		root += "/"
 	}
+	dep := dependencyOf(p.BuildPackage, p.Module)
 
 	for _, name := range names {
 		path := name
@@ -398,23 +424,18 @@ func (p *Package) addFiles(cu *apb.CompilationUnit, root, base string, names []s
 		}
 
 		var details []*anypb.Any
-		if p.ext.Rules != nil {
+		if rel, ok := dep.rel(path); ok {
+			// Files of third-party packages are named by their module, so
+			// that they do not appear under the paths of the main corpus.
+			vn = p.ext.depVName(dep, rel)
+			details = p.packageInfo(vn)
+		} else if p.ext.Rules != nil {
 			v2, ok := p.ext.Rules.Apply(trimmed)
 			if ok {
 				vn.Corpus = v2.Corpus
 				vn.Root = v2.Root
 				vn.Path = v2.Path
-
-				if govname.ImportPath(vn, p.ext.BuildContext.GOROOT) != p.BuildPackage.ImportPath {
-					// Add GoPackageInfo if constructed VName differs from actual ImportPath.
-					if info, err := ptypes.MarshalAny(&gopb.GoPackageInfo{
-						ImportPath: p.BuildPackage.ImportPath,
-					}); err == nil {
-						details = append(details, info)
-					} else {
-						log.Warningf("failed to marshal GoPackageInfo for input: %v", err)
-					}
-				}
+				details = p.packageInfo(vn)
 			}
 		}
 
@@ -442,6 +463,22 @@ func (p *Package) addFiles(cu *apb.CompilationUnit, root, base string, names []s
 	}
 }
 
+// packageInfo returns a GoPackageInfo detail for an input of p with VName
+// vn, if the import path implied by vn differs from the actual import path.
+func (p *Package) packageInfo(vn *spb.VName) []*anypb.Any {
+	if govname.ImportPath(vn, p.ext.BuildContext.GOROOT) == p.BuildPackage.ImportPath {
+		return nil
+	}
+	info, err := ptypes.MarshalAny(&gopb.GoPackageInfo{
+		ImportPath: p.BuildPackage.ImportPath,
+	})
+	if err != nil {
+		log.Warningf("failed to marshal GoPackageInfo for input: %v", err)
+		return nil
+	}
+	return []*anypb.Any{info}
+}
+
 // addSource acts as addFiles, and in addition marks each trimmed path as a
 // source input for the compilation.
 func (p *Package) addSource(cu *apb.CompilationUnit, root, base string, names []string) {
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package golang
+
+import (
+	"go/build"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"kythe.io/kythe/go/extractors/govname"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// A Module describes a module that provides a package, as reported by go list.
+type Module struct {
+	Path    string  // module path
+	Version string  // module version, if any
+	Dir     string  // directory holding the files of the module, if known
+	Main    bool    // whether this is the main module
+	Replace *Module // the replacement of this module, if any
+}
+
+// A dependency locates the files of a third-party package: a package in the
+// module cache or a vendor directory.
+type dependency struct {
+	module  string // module path (or repository root, for GOPATH vendoring)
+	version string // module version, if known
+	dir     string // the directory corresponding to the module path
+}
+
+// vendorDir is the path component that introduces a vendor directory.
+const vendorDir = string(filepath.Separator) + "vendor" + string(filepath.Separator)
+
+// dependencyOf reports where the files of bp, provided by module m (which may
+// be nil), come from, or returns nil if bp is not a third-party package.  A
+// package is third-party if it is in a vendor directory, or if its module is
+// neither the main module nor replaced by a local directory.
+func dependencyOf(bp *build.Package, m *Module) *dependency {
+	if bp.Goroot {
+		return nil
+	}
+	if i := strings.LastIndex(bp.Dir+string(filepath.Separator), vendorDir); i >= 0 {
+		vendor := bp.Dir[:i+len(vendorDir)]
+		if m != nil && !m.Main {
+			return &dependency{module: m.Path, version: m.Version, dir: filepath.Join(vendor, filepath.FromSlash(m.Path))}
+		}
+		// GOPATH vendoring does not record modules; use the repository
+		// root of the import path if it can be found, and otherwise treat
+		// the package as its own module.
+		ip := filepath.ToSlash(strings.TrimPrefix(bp.Dir, vendor))
+		mod := ip
+		if r, err := govname.RepoRoot(ip); err == nil {
+			mod = r.Root
+		}
+		return &dependency{module: mod, dir: filepath.Join(vendor, filepath.FromSlash(mod))}
+	}
+	if m == nil || m.Main || m.Dir == "" {
+		return nil
+	}
+	if r := m.Replace; r != nil {
+		if r.Version == "" {
+			return nil // replaced by a local directory
+		}
+		return &dependency{module: r.Path, version: r.Version, dir: m.Dir}
+	}
+	return &dependency{module: m.Path, version: m.Version, dir: m.Dir}
+}
+
+// rel returns the slash-separated path of file relative to the module
+// directory of d, and whether file is within it.  If d == nil, rel reports
+// false.
+func (d *dependency) rel(file string) (string, bool) {
+	if d == nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(d.dir, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if rel == "." {
+		rel = ""
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// key returns the path matched by ModuleRules for the file or directory at
+// rel within d: module@version/rel, or module/rel if the version is unknown.
+func (d *dependency) key(rel string) string {
+	mod := d.module
+	if d.version != "" {
+		mod += "@" + d.version
+	}
+	return strings.TrimSuffix(path.Join(mod, rel), "/")
+}
+
+// depVName returns the VName for the file or directory at rel within d.  The
+// first of the ModuleRules of e matching d.key(rel) is used; otherwise the
+// corpus is the module path and the path is rel, unless
+// UseDefaultCorpusForDeps is set, in which case the corpus is the default and
+// the root is the module path.
+func (e *Extractor) depVName(d *dependency, rel string) *spb.VName {
+	if v, ok := e.ModuleRules.Apply(d.key(rel)); ok {
+		return v
+	}
+	v := &spb.VName{Corpus: d.module, Path: rel}
+	if e.UseDefaultCorpusForDeps && e.DefaultCorpus != "" {
+		v.Corpus, v.Root = e.DefaultCorpus, d.module
+	}
+	return v
+}
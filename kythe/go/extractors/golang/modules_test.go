@@ -0,0 +1,114 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package golang
+
+import (
+	"go/build"
+	"testing"
+
+	"kythe.io/kythe/go/util/vnameutil"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestDependencyOf(t *testing.T) {
+	tests := []struct {
+		desc string
+		dir  string
+		mod  *Module
+		want *dependency
+	}{
+		{"main module", "/src/proj/x", &Module{Path: "example.com/proj", Main: true}, nil},
+		{"no module", "/go/src/example.com/proj/x", nil, nil},
+		{"local replacement", "/src/fork/x", &Module{
+			Path: "example.com/lib", Dir: "/src/fork", Replace: &Module{Path: "../fork"},
+		}, nil},
+		{"module cache", "/go/pkg/mod/example.com/lib@v1.2.0/x", &Module{
+			Path: "example.com/lib", Version: "v1.2.0", Dir: "/go/pkg/mod/example.com/lib@v1.2.0",
+		}, &dependency{module: "example.com/lib", version: "v1.2.0", dir: "/go/pkg/mod/example.com/lib@v1.2.0"}},
+		{"module replacement", "/go/pkg/mod/example.com/fork@v0.1.0/x", &Module{
+			Path: "example.com/lib", Version: "v1.2.0", Dir: "/go/pkg/mod/example.com/fork@v0.1.0",
+			Replace: &Module{Path: "example.com/fork", Version: "v0.1.0"},
+		}, &dependency{module: "example.com/fork", version: "v0.1.0", dir: "/go/pkg/mod/example.com/fork@v0.1.0"}},
+		{"module vendoring", "/src/proj/vendor/example.com/lib/x", &Module{
+			Path: "example.com/lib", Version: "v1.2.0",
+		}, &dependency{module: "example.com/lib", version: "v1.2.0", dir: "/src/proj/vendor/example.com/lib"}},
+		{"GOPATH vendoring", "/go/src/proj/vendor/github.com/org/lib/x", nil,
+			&dependency{module: "github.com/org/lib", dir: "/go/src/proj/vendor/github.com/org/lib"}},
+	}
+	for _, test := range tests {
+		got := dependencyOf(&build.Package{Dir: test.dir}, test.mod)
+		if diff := cmp.Diff(test.want, got, cmp.AllowUnexported(dependency{})); diff != "" {
+			t.Errorf("%s: dependencyOf (-want +got):\n%s", test.desc, diff)
+		}
+	}
+}
+
+func TestDepVName(t *testing.T) {
+	rules, err := vnameutil.ParseRules([]byte(`[
+  {"pattern": "example.com/acme/([^@/]*)@[^/]*/(.*)", "vname": {"corpus": "acme", "root": "@1@", "path": "@2@"}}
+]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &dependency{module: "example.com/lib", version: "v1.2.0", dir: "/mod/example.com/lib@v1.2.0"}
+	acme := &dependency{module: "example.com/acme/tools", version: "v0.3.0", dir: "/mod/example.com/acme/tools@v0.3.0"}
+	tests := []struct {
+		desc string
+		ext  *Extractor
+		dep  *dependency
+		file string
+		want *spb.VName
+	}{
+		{"default", &Extractor{ModuleRules: rules}, d, "/mod/example.com/lib@v1.2.0/x/a.go",
+			&spb.VName{Corpus: "example.com/lib", Path: "x/a.go"}},
+		{"rule", &Extractor{ModuleRules: rules}, acme, "/mod/example.com/acme/tools@v0.3.0/cmd/b.go",
+			&spb.VName{Corpus: "acme", Root: "tools", Path: "cmd/b.go"}},
+		{"default corpus", &Extractor{PackageVNameOptions: PackageVNameOptions{
+			DefaultCorpus: "main", UseDefaultCorpusForDeps: true,
+		}}, d, "/mod/example.com/lib@v1.2.0/a.go",
+			&spb.VName{Corpus: "main", Root: "example.com/lib", Path: "a.go"}},
+	}
+	for _, test := range tests {
+		rel, ok := test.dep.rel(test.file)
+		if !ok {
+			t.Errorf("%s: %q is not in %q", test.desc, test.file, test.dep.dir)
+			continue
+		}
+		if diff := cmp.Diff(test.want, test.ext.depVName(test.dep, rel), protocmp.Transform()); diff != "" {
+			t.Errorf("%s: depVName (-want +got):\n%s", test.desc, diff)
+		}
+	}
+
+	if _, ok := d.rel("/mod/example.com/other/a.go"); ok {
+		t.Error("rel: file outside the module reported as within it")
+	}
+}
+
+func TestVNameForDependency(t *testing.T) {
+	e := &Extractor{modules: map[string]*Module{
+		"example.com/lib/x": {Path: "example.com/lib", Version: "v1.2.0", Dir: "/mod/example.com/lib@v1.2.0"},
+	}}
+	got := e.vnameFor(&build.Package{ImportPath: "example.com/lib/x", Dir: "/mod/example.com/lib@v1.2.0/x"})
+	want := &spb.VName{Corpus: "example.com/lib", Path: "x", Language: "go"}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("vnameFor (-want +got):\n%s", diff)
+	}
+}
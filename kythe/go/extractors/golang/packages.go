@@ -66,6 +66,7 @@ type jsonPackage struct {
 
 	ForTest string // q in a "p [q.test]" package, else ""
 	DepOnly bool
+	Module  *Module
 
 	Error *jsonPackageError
 }
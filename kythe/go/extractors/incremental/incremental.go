@@ -0,0 +1,287 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package incremental determines which compilations extracted at one revision
+// of a git repository are invalidated by the changes up to another revision,
+// and re-extracts only those.
+//
+// The result of an incremental extraction is a delta: a set of .kzip files
+// holding the re-extracted compilations, and a list of tombstones naming the
+// digests of the previously extracted compilations they replace.  A unit whose
+// source files were all deleted is tombstoned without a replacement.
+package incremental // import "kythe.io/kythe/go/extractors/incremental"
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"kythe.io/kythe/go/platform/kzip"
+	"kythe.io/kythe/go/util/log"
+	"kythe.io/kythe/go/util/ptypes"
+
+	"bitbucket.org/creachadair/stringset"
+
+	bipb "kythe.io/kythe/proto/buildinfo_go_proto"
+)
+
+// Changes records the repository-relative paths of the files that differ
+// between two revisions.
+type Changes struct {
+	Modified stringset.Set // paths added or modified
+	Deleted  stringset.Set // paths deleted
+}
+
+// Len returns the total number of changed paths.
+func (c *Changes) Len() int { return c.Modified.Len() + c.Deleted.Len() }
+
+// GitChanges reports the files that differ between revisions oldRev and
+// newRev of the git repository at dir.  A rename is reported as a deletion of
+// the old path and an addition of the new one.
+func GitChanges(ctx context.Context, dir, oldRev, newRev string) (*Changes, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "diff", "--name-status", "-z", "--no-renames", oldRev, newRev, "--")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseNameStatus(out)
+}
+
+// parseNameStatus parses the output of git diff --name-status -z, a sequence
+// of NUL-terminated status and path fields.
+func parseNameStatus(data []byte) (*Changes, error) {
+	c := &Changes{Modified: stringset.New(), Deleted: stringset.New()}
+	fields := strings.Split(strings.TrimSuffix(string(data), "\x00"), "\x00")
+	if len(fields) == 1 && fields[0] == "" {
+		return c, nil
+	} else if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("malformed git status output (%d fields)", len(fields))
+	}
+	for i := 0; i < len(fields); i += 2 {
+		status, path := fields[i], fields[i+1]
+		if status == "" {
+			return nil, fmt.Errorf("empty status for %q", path)
+		}
+		if status[0] == 'D' {
+			c.Deleted.Add(path)
+		} else {
+			c.Modified.Add(path)
+		}
+	}
+	return c, nil
+}
+
+// Options control how compilation inputs are matched against changes.
+type Options struct {
+	// If set, only inputs in this corpus (or with no corpus, for which the
+	// corpus of the unit is assumed) are considered to belong to the repository.
+	Corpus string
+
+	// The path of the repository relative to the working directory of the
+	// compilations, if they were not extracted from its root (e.g., "src").
+	Prefix string
+}
+
+// repoPath returns the repository-relative path of the given input path, and
+// whether it lies within the repository.
+func (o *Options) repoPath(p string) (string, bool) {
+	p = path.Clean(p)
+	if o == nil || o.Prefix == "" {
+		return p, !strings.HasPrefix(p, "../") && !path.IsAbs(p)
+	}
+	rel := strings.TrimPrefix(p, path.Clean(o.Prefix)+"/")
+	return rel, rel != p
+}
+
+// A Stale unit is a compilation invalidated by a change.
+type Stale struct {
+	Digest   string   // the digest of the old compilation
+	Target   string   // the build target, if known
+	Language string   // the language of the compilation
+	Sources  []string // the source files of the compilation
+	Changed  []string // the changed inputs, in repository-relative form
+	Deleted  bool     // whether every source file was deleted
+}
+
+// A Plan describes the work of an incremental extraction.
+type Plan struct {
+	Unchanged []string // digests of compilations still valid
+	Stale     []*Stale // compilations invalidated by changes
+}
+
+// Tombstones returns the sorted digests of the compilations to be removed.
+func (p *Plan) Tombstones() []string {
+	var ds []string
+	for _, s := range p.Stale {
+		ds = append(ds, s.Digest)
+	}
+	sort.Strings(ds)
+	return ds
+}
+
+// Uncovered returns the sorted paths added or modified by changes that no
+// compilation in p reads, such as new source files.  An incremental
+// extraction does not cover them: they need compilations of their own.
+func (p *Plan) Uncovered(changes *Changes) []string {
+	covered := stringset.New()
+	for _, s := range p.Stale {
+		covered.Add(s.Changed...)
+	}
+	return changes.Modified.Diff(covered).Elements()
+}
+
+// NewPlan scans the compilations in r and classifies each as unchanged or
+// stale with respect to changes.  Changed paths that none of them read are
+// reported by the Uncovered method of the plan.
+func NewPlan(r *kzip.Reader, changes *Changes, opts *Options) (*Plan, error) {
+	p := new(Plan)
+	err := r.Scan(func(u *kzip.Unit) error {
+		if s := stale(u, changes, opts); s != nil {
+			p.Stale = append(p.Stale, s)
+		} else {
+			p.Unchanged = append(p.Unchanged, u.Digest)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(p.Unchanged)
+	sort.Slice(p.Stale, func(i, j int) bool { return p.Stale[i].Digest < p.Stale[j].Digest })
+	return p, nil
+}
+
+// stale returns a Stale record for u if any of its inputs changed, or nil.
+func stale(u *kzip.Unit, changes *Changes, opts *Options) *Stale {
+	cu := u.Proto
+	changed := stringset.New()
+	check := func(p string) {
+		if rp, ok := opts.repoPath(p); ok && (changes.Modified.Contains(rp) || changes.Deleted.Contains(rp)) {
+			changed.Add(rp)
+		}
+	}
+	for _, ri := range cu.GetRequiredInput() {
+		corpus := ri.GetVName().GetCorpus()
+		if corpus == "" {
+			corpus = cu.GetVName().GetCorpus()
+		}
+		if opts != nil && opts.Corpus != "" && corpus != opts.Corpus {
+			continue
+		}
+		check(ri.GetInfo().GetPath())
+	}
+	for _, src := range cu.GetSourceFile() {
+		check(src)
+	}
+	if changed.Empty() {
+		return nil
+	}
+
+	s := &Stale{
+		Digest:   u.Digest,
+		Target:   cu.GetVName().GetSignature(),
+		Language: cu.GetVName().GetLanguage(),
+		Sources:  cu.GetSourceFile(),
+		Changed:  changed.Elements(),
+		Deleted:  len(cu.GetSourceFile()) > 0,
+	}
+	for _, detail := range cu.GetDetails() {
+		var info bipb.BuildDetails
+		if err := ptypes.UnmarshalAny(detail, &info); err == nil && info.BuildTarget != "" {
+			s.Target = info.BuildTarget
+		}
+	}
+	for _, src := range cu.GetSourceFile() {
+		if rp, ok := opts.repoPath(src); !ok || !changes.Deleted.Contains(rp) {
+			s.Deleted = false
+			break
+		}
+	}
+	return s
+}
+
+// Reextract runs command through the shell in dir once for each stale
+// compilation that was not deleted, to write a replacement into outputDir.
+// Compilations sharing a build target are re-extracted once.  The command can
+// use the following environment variables:
+//
+//	KYTHE_OUTPUT_DIRECTORY -- outputDir
+//	KYTHE_TARGET           -- the build target of the compilation, if known
+//	KYTHE_LANGUAGE         -- the language of the compilation
+//	KYTHE_SOURCES_FILE     -- a file listing the source files, each followed
+//	                          by a NUL byte (e.g., for xargs -0)
+//
+// Reextract returns the number of commands that failed; failures are logged
+// but do not prevent the remaining compilations from being extracted.
+func Reextract(ctx context.Context, p *Plan, dir, outputDir, command string) (failed int) {
+	tmp, err := os.MkdirTemp("", "reextract")
+	if err != nil {
+		log.ErrorContextf(ctx, "Creating source lists: %v", err)
+		return len(p.Stale)
+	}
+	defer os.RemoveAll(tmp)
+	done := stringset.New()
+	for _, s := range p.Stale {
+		if s.Deleted {
+			continue
+		}
+		key := s.Target
+		if key == "" {
+			key = s.Digest
+		}
+		if !done.Add(key) {
+			continue
+		}
+		sources := filepath.Join(tmp, fmt.Sprintf("sources%d", done.Len()))
+		if err := os.WriteFile(sources, nulTerminated(s.Sources), 0644); err != nil {
+			failed++
+			log.ErrorContextf(ctx, "Re-extracting %s (%s): %v", key, s.Language, err)
+			continue
+		}
+		cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"KYTHE_OUTPUT_DIRECTORY="+outputDir,
+			"KYTHE_TARGET="+s.Target,
+			"KYTHE_LANGUAGE="+s.Language,
+			"KYTHE_SOURCES_FILE="+sources,
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			failed++
+			log.ErrorContextf(ctx, "Re-extracting %s (%s): %v\n%s", key, s.Language, err, out)
+		}
+	}
+	return failed
+}
+
+// nulTerminated returns the concatenation of paths, each followed by a NUL
+// byte, which cannot occur in a path.
+func nulTerminated(paths []string) []byte {
+	var buf bytes.Buffer
+	for _, p := range paths {
+		buf.WriteString(p)
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
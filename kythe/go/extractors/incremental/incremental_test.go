@@ -0,0 +1,253 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package incremental
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/extractors/bazel"
+	"kythe.io/kythe/go/platform/kzip"
+
+	"bitbucket.org/creachadair/stringset"
+	"github.com/google/go-cmp/cmp"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	bipb "kythe.io/kythe/proto/buildinfo_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestParseNameStatus(t *testing.T) {
+	c, err := parseNameStatus([]byte("M\x00a/b.cc\x00A\x00new file.h\x00D\x00old.h\x00T\x00link\x00"))
+	if err != nil {
+		t.Fatalf("parseNameStatus: %v", err)
+	}
+	if diff := cmp.Diff([]string{"a/b.cc", "link", "new file.h"}, c.Modified.Elements()); diff != "" {
+		t.Errorf("Modified (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"old.h"}, c.Deleted.Elements()); diff != "" {
+		t.Errorf("Deleted (-want +got):\n%s", diff)
+	}
+
+	if c, err := parseNameStatus(nil); err != nil || c.Len() != 0 {
+		t.Errorf("parseNameStatus(nil): got %v, %v; want empty", c, err)
+	}
+	if _, err := parseNameStatus([]byte("M\x00")); err == nil {
+		t.Error("parseNameStatus with missing path: got nil error")
+	}
+}
+
+func unit(target string, sources []string, inputs ...string) *apb.CompilationUnit {
+	cu := &apb.CompilationUnit{
+		VName:      &spb.VName{Corpus: "repo", Language: "c++", Signature: target},
+		SourceFile: sources,
+	}
+	for _, in := range inputs {
+		v := &spb.VName{Path: in}
+		if strings.HasPrefix(in, "external/") {
+			v.Corpus = "other"
+		}
+		cu.RequiredInput = append(cu.RequiredInput, &apb.CompilationUnit_FileInput{
+			VName: v,
+			Info:  &apb.FileInfo{Path: in},
+		})
+	}
+	return cu
+}
+
+func newReader(t *testing.T, units ...*apb.CompilationUnit) (*kzip.Reader, []string) {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := kzip.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var digests []string
+	for _, cu := range units {
+		d, err := w.AddUnit(cu, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digests = append(digests, d)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := kzip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, digests
+}
+
+func TestNewPlan(t *testing.T) {
+	withTarget := unit("ignored", []string{"b.cc"}, "b.cc", "lib.h")
+	if err := bazel.AddDetail(withTarget, &bipb.BuildDetails{BuildTarget: "//:b"}); err != nil {
+		t.Fatal(err)
+	}
+	r, ds := newReader(t,
+		// Unchanged.
+		unit("a", []string{"a.cc"}, "a.cc", "other.h"),
+		// A header changed.
+		withTarget,
+		// The only source was deleted.
+		unit("c", []string{"c.cc"}, "c.cc"),
+		// Only an input in another corpus changed.
+		unit("d", []string{"d.cc"}, "d.cc", "external/x"),
+	)
+	changes := &Changes{
+		Modified: stringset.New("lib.h", "external/x"),
+		Deleted:  stringset.New("c.cc"),
+	}
+	p, err := NewPlan(r, changes, &Options{Corpus: "repo"})
+	if err != nil {
+		t.Fatalf("NewPlan: %v", err)
+	}
+
+	want := stringset.New(ds[0], ds[3]).Elements()
+	if diff := cmp.Diff(want, p.Unchanged); diff != "" {
+		t.Errorf("Unchanged (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(stringset.New(ds[1], ds[2]).Elements(), p.Tombstones()); diff != "" {
+		t.Errorf("Tombstones (-want +got):\n%s", diff)
+	}
+	byDigest := make(map[string]*Stale)
+	for _, s := range p.Stale {
+		byDigest[s.Digest] = s
+	}
+	if diff := cmp.Diff(&Stale{
+		Digest:   ds[1],
+		Target:   "//:b",
+		Language: "c++",
+		Sources:  []string{"b.cc"},
+		Changed:  []string{"lib.h"},
+	}, byDigest[ds[1]]); diff != "" {
+		t.Errorf("Stale header unit (-want +got):\n%s", diff)
+	}
+	if s := byDigest[ds[2]]; s == nil || !s.Deleted {
+		t.Errorf("Unit with deleted source: got %+v, want Deleted", s)
+	}
+
+	// The input in another corpus is read by no compilation in the repository.
+	changes.Modified.Add("new.cc")
+	if diff := cmp.Diff([]string{"external/x", "new.cc"}, p.Uncovered(changes)); diff != "" {
+		t.Errorf("Uncovered (-want +got):\n%s", diff)
+	}
+}
+
+func TestOptionsPrefix(t *testing.T) {
+	opts := &Options{Prefix: "src"}
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"src/a.cc", "a.cc", true},
+		{"./src/x/../b.h", "b.h", true},
+		{"bazel-out/gen.h", "bazel-out/gen.h", false},
+	}
+	for _, test := range tests {
+		got, ok := opts.repoPath(test.in)
+		if got != test.want || ok != test.ok {
+			t.Errorf("repoPath(%q): got (%q, %v), want (%q, %v)", test.in, got, ok, test.want, test.ok)
+		}
+	}
+	if _, ok := (*Options)(nil).repoPath("../up.h"); ok {
+		t.Error("repoPath(../up.h) is in the repository, want not")
+	}
+}
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestGitChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	dir := t.TempDir()
+	write := func(name, text string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git(t, dir, "init", "-q")
+	write("keep.cc", "keep")
+	write("edit.cc", "v1")
+	write("gone.cc", "gone")
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", "old")
+	old := git(t, dir, "rev-parse", "HEAD")
+
+	write("edit.cc", "v2")
+	write("new.cc", "new")
+	os.Remove(filepath.Join(dir, "gone.cc"))
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", "new")
+
+	c, err := GitChanges(context.Background(), dir, old, "HEAD")
+	if err != nil {
+		t.Fatalf("GitChanges: %v", err)
+	}
+	if diff := cmp.Diff([]string{"edit.cc", "new.cc"}, c.Modified.Elements()); diff != "" {
+		t.Errorf("Modified (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"gone.cc"}, c.Deleted.Elements()); diff != "" {
+		t.Errorf("Deleted (-want +got):\n%s", diff)
+	}
+}
+
+func TestReextract(t *testing.T) {
+	out := t.TempDir()
+	p := &Plan{Stale: []*Stale{
+		{Digest: "1", Target: "//:a", Language: "go", Sources: []string{"a.go", "b c.go"}},
+		{Digest: "2", Target: "//:a", Language: "go"}, // same target, skipped
+		{Digest: "3", Deleted: true},                  // deleted, skipped
+		{Digest: "4", Language: "c++", Sources: []string{"c.cc"}},
+	}}
+	const command = `echo "$KYTHE_TARGET|$KYTHE_LANGUAGE|$(tr '\0' ,  < "$KYTHE_SOURCES_FILE")" >> "$KYTHE_OUTPUT_DIRECTORY/log"`
+	if failed := Reextract(context.Background(), p, t.TempDir(), out, command); failed != 0 {
+		t.Errorf("Reextract: %d failures", failed)
+	}
+	data, err := os.ReadFile(filepath.Join(out, "log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "//:a|go|a.go,b c.go,\n|c++|c.cc,\n"
+	if got := string(data); got != want {
+		t.Errorf("Commands run: got %q, want %q", got, want)
+	}
+
+	if failed := Reextract(context.Background(), p, t.TempDir(), out, "exit 1"); failed != 2 {
+		t.Errorf("Reextract with failing command: got %d failures, want 2", failed)
+	}
+}
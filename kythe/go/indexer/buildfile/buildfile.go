@@ -0,0 +1,349 @@
+/*
+ * Copyright 2014 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package buildfile indexes Bazel BUILD files and the labels referenced from
+// configuration files.
+//
+// Each rule with a name becomes a process node whose signature is its
+// canonical label.  The name attribute of the rule defines the node, and each
+// label in an attribute that names dependencies or inputs is a reference to
+// the target or source file it denotes, with a corresponding depends (or
+// exports) edge from the rule.  Absolute labels found in YAML files are
+// emitted as references too, so that configuration can be navigated to the
+// targets it mentions.
+package buildfile // import "kythe.io/kythe/go/indexer/buildfile"
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	"google.golang.org/protobuf/proto"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// Language is the language of the target and anchor nodes emitted.
+const Language = "bazel"
+
+// DefaultFileAttrs are the attributes whose relative labels default to naming
+// source files when no matching target is known.
+var DefaultFileAttrs = []string{"srcs", "hdrs", "textual_hdrs", "data", "src", "main", "resources"}
+
+// DefaultTargetAttrs are the attributes whose labels default to naming
+// targets.
+var DefaultTargetAttrs = []string{"deps", "runtime_deps", "exports", "embed", "tools", "plugins", "library", "proto", "exported_deps"}
+
+// Options control the nodes emitted by an Indexer.
+type Options struct {
+	// Corpus and Root are used in the VNames of all emitted nodes.
+	Corpus, Root string
+
+	// FileAttrs and TargetAttrs are the rule attributes whose labels are
+	// indexed.  If both are nil, DefaultFileAttrs and DefaultTargetAttrs
+	// are used.
+	FileAttrs, TargetAttrs []string
+}
+
+// An Indexer collects BUILD and configuration files and emits entries for
+// them.  Because labels may refer to targets in any package, all files must
+// be added before calling Emit.
+type Indexer struct {
+	opts     Options
+	attrs    map[string]bool // attribute → whether it defaults to files
+	targets  map[string]bool // canonical labels of known targets
+	files    map[string]bool // paths of known source files
+	builds   []*buildFile
+	configs  []configFile
+	hasFiles bool
+}
+
+type buildFile struct {
+	path, pkg string
+	src       []byte
+	calls     []call
+}
+
+type configFile struct {
+	path string
+	src  []byte
+}
+
+// NewIndexer returns a new, empty Indexer.  If opts == nil, default options
+// are used.
+func NewIndexer(opts *Options) *Indexer {
+	ix := &Indexer{
+		attrs:   make(map[string]bool),
+		targets: make(map[string]bool),
+		files:   make(map[string]bool),
+	}
+	if opts != nil {
+		ix.opts = *opts
+	}
+	if ix.opts.FileAttrs == nil && ix.opts.TargetAttrs == nil {
+		ix.opts.FileAttrs, ix.opts.TargetAttrs = DefaultFileAttrs, DefaultTargetAttrs
+	}
+	for _, a := range ix.opts.FileAttrs {
+		ix.attrs[a] = true
+	}
+	for _, a := range ix.opts.TargetAttrs {
+		ix.attrs[a] = false
+	}
+	return ix
+}
+
+// IsBuildFile reports whether the file at p is a BUILD file.
+func IsBuildFile(p string) bool {
+	base := path.Base(p)
+	return base == "BUILD" || base == "BUILD.bazel"
+}
+
+// IsConfigFile reports whether the file at p is a configuration file
+// scanned for labels.
+func IsConfigFile(p string) bool {
+	ext := strings.ToLower(path.Ext(p))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// AddBuildFile parses the BUILD file at path, relative to the workspace
+// root, and records the targets it defines.
+func (ix *Indexer) AddBuildFile(path string, src []byte) error {
+	calls, err := parse(src)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %v", path, err)
+	}
+	pkg := pathDir(path)
+	for _, c := range calls {
+		if name, ok := c.keyword("name"); ok && len(name) == 1 {
+			ix.targets[canonical(pkg, name[0].Value)] = true
+		}
+	}
+	ix.builds = append(ix.builds, &buildFile{path: path, pkg: pkg, src: src, calls: calls})
+	return nil
+}
+
+// AddConfigFile records a configuration file at path, relative to the
+// workspace root, to be scanned for absolute labels.
+func (ix *Indexer) AddConfigFile(path string, src []byte) {
+	ix.configs = append(ix.configs, configFile{path: path, src: src})
+}
+
+// AddSourceFile records the existence of a source file at path, relative to
+// the workspace root.  Once any source file has been added, labels are only
+// resolved to files that have been added.
+func (ix *Indexer) AddSourceFile(path string) {
+	ix.files[path] = true
+	ix.hasFiles = true
+}
+
+// Emit calls f with each entry for the files added to ix.
+func (ix *Indexer) Emit(f func(*spb.Entry) error) error {
+	e := &emitter{ix: ix, emit: f}
+	for _, b := range ix.builds {
+		e.buildFile(b)
+	}
+	for _, c := range ix.configs {
+		e.configFile(c)
+	}
+	return e.err
+}
+
+type emitter struct {
+	ix   *Indexer
+	emit func(*spb.Entry) error
+	err  error
+}
+
+func (e *emitter) fact(src *spb.VName, name string, value []byte) {
+	if e.err == nil {
+		e.err = e.emit(&spb.Entry{Source: src, FactName: name, FactValue: value})
+	}
+}
+
+func (e *emitter) edge(src, tgt *spb.VName, kind string) {
+	if e.err == nil {
+		e.err = e.emit(&spb.Entry{Source: src, EdgeKind: kind, Target: tgt, FactName: "/"})
+	}
+}
+
+func (e *emitter) fileVName(path string) *spb.VName {
+	return &spb.VName{Corpus: e.ix.opts.Corpus, Root: e.ix.opts.Root, Path: path}
+}
+
+func (e *emitter) targetVName(label string) *spb.VName {
+	pkg := strings.TrimPrefix(label, "//")
+	if i := strings.Index(pkg, ":"); i >= 0 {
+		pkg = pkg[:i]
+	}
+	return &spb.VName{
+		Corpus:    e.ix.opts.Corpus,
+		Root:      e.ix.opts.Root,
+		Path:      pkg,
+		Language:  Language,
+		Signature: label,
+	}
+}
+
+func (e *emitter) file(path string, src []byte) *spb.VName {
+	v := e.fileVName(path)
+	e.fact(v, facts.NodeKind, []byte(nodes.File))
+	e.fact(v, facts.Text, src)
+	return v
+}
+
+// anchor emits an anchor spanning s in file and an edge of the given kind to
+// target.
+func (e *emitter) anchor(file *spb.VName, s str, kind string, target *spb.VName) {
+	a := proto.Clone(file).(*spb.VName)
+	a.Language = Language
+	a.Signature = "#" + strconv.Itoa(s.Start) + ":" + strconv.Itoa(s.End)
+	e.fact(a, facts.NodeKind, []byte(nodes.Anchor))
+	e.fact(a, facts.AnchorStart, []byte(strconv.Itoa(s.Start)))
+	e.fact(a, facts.AnchorEnd, []byte(strconv.Itoa(s.End)))
+	e.edge(a, target, kind)
+}
+
+func (e *emitter) buildFile(b *buildFile) {
+	file := e.file(b.path, b.src)
+	for _, c := range b.calls {
+		switch c.Func {
+		case "load", "package":
+			continue
+		case "exports_files":
+			if len(c.Args) > 0 && c.Args[0].Name == "" {
+				for _, s := range c.Args[0].Strings {
+					e.anchor(file, s, edges.Ref, e.fileVName(path.Join(b.pkg, s.Value)))
+				}
+			}
+			continue
+		}
+		name, ok := c.keyword("name")
+		if !ok || len(name) != 1 {
+			continue
+		}
+		label := canonical(b.pkg, name[0].Value)
+		target := e.targetVName(label)
+		e.fact(target, facts.NodeKind, []byte(nodes.Process))
+		if code, err := proto.Marshal(markedSource(c.Func, label)); err == nil {
+			e.fact(target, facts.Code, code)
+		}
+		e.anchor(file, name[0], edges.DefinesBinding, target)
+
+		for _, a := range c.Args {
+			isFile, ok := e.ix.attrs[a.Name]
+			if !ok {
+				continue
+			}
+			kind := edges.Depends
+			if a.Name == "exports" {
+				kind = edges.Exports
+			}
+			for _, s := range a.Strings {
+				dep := e.resolve(b.pkg, s.Value, isFile)
+				if dep == nil {
+					continue
+				}
+				e.anchor(file, s, edges.Ref, dep)
+				e.edge(target, dep, kind)
+			}
+		}
+	}
+}
+
+// labelPattern matches absolute labels in configuration files.
+var labelPattern = regexp.MustCompile(`(^|[\s"'\[,{=])(//[\w./+-]*(?::[\w./+=,@~-]+)?)`)
+
+func (e *emitter) configFile(c configFile) {
+	file := e.file(c.path, c.src)
+	for _, m := range labelPattern.FindAllSubmatchIndex(c.src, -1) {
+		start, end := m[4], m[5]
+		if dep := e.resolve("", string(c.src[start:end]), false); dep != nil && e.known(dep) {
+			e.anchor(file, str{Value: string(c.src[start:end]), Start: start, End: end}, edges.Ref, dep)
+		}
+	}
+}
+
+// known reports whether v is a target or file added to the indexer.
+func (e *emitter) known(v *spb.VName) bool {
+	if v.Language == Language {
+		return e.ix.targets[v.Signature]
+	}
+	return e.ix.files[v.Path]
+}
+
+// resolve returns the VName of the target or file denoted by label in pkg, or
+// nil if label is not a local label.  Labels that match no known target are
+// resolved to files if isFile is set or if a matching file is known.
+func (e *emitter) resolve(pkg, label string, isFile bool) *spb.VName {
+	if label == "" || strings.HasPrefix(label, "@") || strings.ContainsAny(label, "$*") {
+		return nil
+	}
+	relative := !strings.HasPrefix(label, "//") && !strings.HasPrefix(label, ":")
+	if strings.HasPrefix(label, "//") {
+		rest := label[2:]
+		if i := strings.Index(rest, ":"); i >= 0 {
+			pkg, label = rest[:i], rest[i+1:]
+		} else {
+			pkg, label = rest, path.Base(rest)
+		}
+	} else {
+		label = strings.TrimPrefix(label, ":")
+	}
+	if label == "" {
+		return nil
+	}
+	canon := canonical(pkg, label)
+	if e.ix.targets[canon] {
+		return e.targetVName(canon)
+	}
+	file := path.Join(pkg, label)
+	if e.ix.files[file] || (isFile && relative && !e.ix.hasFiles) {
+		return e.fileVName(file)
+	}
+	return e.targetVName(canon)
+}
+
+// canonical returns the canonical label of the target name in pkg.
+func canonical(pkg, name string) string { return "//" + pkg + ":" + name }
+
+// markedSource returns the MarkedSource for a target defined by a call to
+// rule.
+func markedSource(rule, label string) *cpb.MarkedSource {
+	return &cpb.MarkedSource{
+		Child: []*cpb.MarkedSource{{
+			Kind:     cpb.MarkedSource_MODIFIER,
+			PreText:  rule,
+			PostText: " ",
+		}, {
+			Kind:    cpb.MarkedSource_IDENTIFIER,
+			PreText: label,
+		}},
+	}
+}
+
+func pathDir(p string) string {
+	if d := path.Dir(p); d != "." {
+		return d
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 2014 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package buildfile
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	"github.com/google/go-cmp/cmp"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestIndexer(t *testing.T) {
+	files := map[string]string{
+		"foo/BUILD": `go_library(
+    name = "foo",
+    srcs = ["foo.go"],
+    deps = ["//bar", "@ext//x"],
+    exports = [":foo_dep"],
+)
+
+go_library(name = "foo_dep")
+`,
+		"bar/BUILD.bazel": `exports_files(["bar.go"])
+
+go_library(name = "bar", srcs = ["bar.go", "missing.go"])
+`,
+		"config.yaml": "target: //foo:foo\nurl: http://example.com//foo\nlist: [\"//bar\", //missing]\n",
+	}
+
+	ix := NewIndexer(&Options{Corpus: "c"})
+	for _, path := range []string{"foo/foo.go", "bar/bar.go", "foo/BUILD", "bar/BUILD.bazel", "config.yaml"} {
+		ix.AddSourceFile(path)
+	}
+	for path, src := range files {
+		if IsBuildFile(path) {
+			if err := ix.AddBuildFile(path, []byte(src)); err != nil {
+				t.Fatal(err)
+			}
+		} else if IsConfigFile(path) {
+			ix.AddConfigFile(path, []byte(src))
+		}
+	}
+
+	// Render edges from anchors as "file[text] kind target", and other
+	// edges as "source kind target".
+	var got []string
+	anchors := make(map[string]*spb.VName)
+	var entries []*spb.Entry
+	if err := ix.Emit(func(e *spb.Entry) error {
+		entries = append(entries, e)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	name := func(v *spb.VName) string {
+		if v.GetSignature() != "" {
+			return v.GetSignature()
+		}
+		return v.GetPath()
+	}
+	for _, e := range entries {
+		if e.FactName == facts.NodeKind && string(e.FactValue) == "anchor" {
+			anchors[e.Source.String()] = e.Source
+		}
+	}
+	for _, e := range entries {
+		if e.EdgeKind == "" {
+			continue
+		}
+		src := name(e.Source)
+		if _, ok := anchors[e.Source.String()]; ok {
+			span := strings.Split(strings.TrimPrefix(e.Source.Signature, "#"), ":")
+			start, _ := strconv.Atoi(span[0])
+			end, _ := strconv.Atoi(span[1])
+			src = fmt.Sprintf("%s[%s]", e.Source.Path, files[e.Source.Path][start:end])
+		}
+		got = append(got, fmt.Sprintf("%s %s %s", src, strings.TrimPrefix(e.EdgeKind, edges.Prefix), name(e.Target)))
+	}
+	sort.Strings(got)
+
+	want := []string{
+		"//bar:bar depends //bar:missing.go", // not a known file, so assumed to be generated
+		"//bar:bar depends bar/bar.go",
+		"//foo:foo depends //bar:bar",
+		"//foo:foo depends foo/foo.go",
+		"//foo:foo exports //foo:foo_dep",
+		"bar/BUILD.bazel[bar.go] ref bar/bar.go",
+		"bar/BUILD.bazel[bar.go] ref bar/bar.go",
+		"bar/BUILD.bazel[bar] defines/binding //bar:bar",
+		"bar/BUILD.bazel[missing.go] ref //bar:missing.go",
+		"config.yaml[//bar] ref //bar:bar",
+		"config.yaml[//foo:foo] ref //foo:foo",
+		"foo/BUILD[//bar] ref //bar:bar",
+		"foo/BUILD[:foo_dep] ref //foo:foo_dep",
+		"foo/BUILD[foo.go] ref foo/foo.go",
+		"foo/BUILD[foo] defines/binding //foo:foo",
+		"foo/BUILD[foo_dep] defines/binding //foo:foo_dep",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected edges: (-want +got)\n%s", diff)
+	}
+}
@@ -0,0 +1,253 @@
+/*
+ * Copyright 2014 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package buildfile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A call is a top-level function call in a BUILD file, such as a rule
+// invocation.
+type call struct {
+	Func string
+	Args []arg
+}
+
+// An arg is an argument to a call.  Name is empty for positional arguments.
+type arg struct {
+	Name    string
+	Strings []str // string literals in the argument value
+}
+
+// A str is a string literal, with the offsets of its contents (excluding
+// quotes and prefix) in the source.
+type str struct {
+	Value      string
+	Start, End int
+}
+
+// keyword returns the string literals of the argument named name.
+func (c *call) keyword(name string) ([]str, bool) {
+	for _, a := range c.Args {
+		if a.Name == name {
+			return a.Strings, true
+		}
+	}
+	return nil, false
+}
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokPunct
+	tokOther
+)
+
+type token struct {
+	kind tokenKind
+	text string // for strings, the decoded value
+	str  str
+}
+
+// parse extracts the top-level calls from a BUILD file.  It understands only
+// enough Starlark to find calls and the string literals in their arguments;
+// string literals inside glob calls and dictionary keys (such as select
+// conditions) are omitted.
+func parse(src []byte) ([]call, error) {
+	toks, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	var calls []call
+	depth := 0
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		if t.kind == tokPunct {
+			switch t.text {
+			case "(", "[", "{":
+				depth++
+			case ")", "]", "}":
+				depth--
+			}
+			continue
+		}
+		if depth != 0 || t.kind != tokIdent || i+1 >= len(toks) || toks[i+1].text != "(" ||
+			(i > 0 && toks[i-1].text == ".") {
+			continue
+		}
+		c, next, err := parseCall(toks, i)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, c)
+		i = next - 1
+	}
+	return calls, nil
+}
+
+// parseCall parses the call beginning at toks[i], returning the index of the
+// first token after it.
+func parseCall(toks []token, i int) (call, int, error) {
+	c := call{Func: toks[i].text}
+	i += 2 // skip name and "("
+	var cur arg
+	depth := 0
+	glob := 0 // depth at which an enclosing glob call began, or 0
+	start := true
+	for ; i < len(toks); i++ {
+		t := toks[i]
+		if start && t.kind == tokIdent && i+1 < len(toks) && toks[i+1].text == "=" {
+			cur.Name = t.text
+			i++
+			start = false
+			continue
+		}
+		start = false
+		switch {
+		case t.kind == tokPunct && (t.text == "(" || t.text == "[" || t.text == "{"):
+			depth++
+			if t.text == "(" && glob == 0 && i > 0 && toks[i-1].text == "glob" {
+				glob = depth
+			}
+		case t.kind == tokPunct && (t.text == ")" || t.text == "]" || t.text == "}"):
+			if depth == 0 {
+				if t.text != ")" {
+					return c, 0, fmt.Errorf("unbalanced %q in call to %s", t.text, c.Func)
+				}
+				if cur.Name != "" || len(cur.Strings) > 0 {
+					c.Args = append(c.Args, cur)
+				}
+				return c, i + 1, nil
+			}
+			if depth == glob {
+				glob = 0
+			}
+			depth--
+		case t.kind == tokPunct && t.text == "," && depth == 0:
+			c.Args = append(c.Args, cur)
+			cur = arg{}
+			start = true
+		case t.kind == tokString:
+			if glob != 0 || (i+1 < len(toks) && toks[i+1].text == ":") {
+				continue
+			}
+			cur.Strings = append(cur.Strings, t.str)
+		}
+	}
+	return c, 0, fmt.Errorf("unterminated call to %s", c.Func)
+}
+
+// tokenize splits src into tokens, discarding comments and whitespace.
+func tokenize(src []byte) ([]token, error) {
+	var toks []token
+	s := string(src)
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '#':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\\':
+			i++
+		case isIdentStart(c):
+			j := i
+			for j < len(s) && isIdentPart(s[j]) {
+				j++
+			}
+			if j < len(s) && (s[j] == '"' || s[j] == '\'') && isStringPrefix(s[i:j]) {
+				t, n, err := scanString(s, j, strings.ContainsAny(s[i:j], "rR"))
+				if err != nil {
+					return nil, err
+				}
+				toks = append(toks, t)
+				i = n
+				continue
+			}
+			toks = append(toks, token{kind: tokIdent, text: s[i:j]})
+			i = j
+		case c == '"' || c == '\'':
+			t, n, err := scanString(s, i, false)
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, t)
+			i = n
+		case strings.IndexByte("()[]{},:=.", c) >= 0:
+			toks = append(toks, token{kind: tokPunct, text: s[i : i+1]})
+			i++
+		default:
+			j := i + 1
+			for j < len(s) && !isIdentStart(s[j]) && strings.IndexByte("()[]{},:=.#\"' \t\r\n", s[j]) < 0 {
+				j++
+			}
+			toks = append(toks, token{kind: tokOther, text: s[i:j]})
+			i = j
+		}
+	}
+	return toks, nil
+}
+
+// scanString scans the string literal whose opening quote is at s[i],
+// returning its token and the offset following it.
+func scanString(s string, i int, raw bool) (token, int, error) {
+	q := s[i : i+1]
+	if strings.HasPrefix(s[i:], q+q+q) {
+		q = q + q + q
+	}
+	start := i + len(q)
+	for j := start; j < len(s); j++ {
+		switch {
+		case s[j] == '\\':
+			j++
+		case s[j] == '\n' && len(q) == 1:
+			return token{}, 0, fmt.Errorf("unterminated string at offset %d", i)
+		case strings.HasPrefix(s[j:], q):
+			body := s[start:j]
+			value := body
+			if !raw && strings.Contains(body, `\`) {
+				if v, err := strconv.Unquote(`"` + body + `"`); err == nil {
+					value = v
+				}
+			}
+			return token{
+				kind: tokString,
+				text: value,
+				str:  str{Value: value, Start: start, End: j},
+			}, j + len(q), nil
+		}
+	}
+	return token{}, 0, fmt.Errorf("unterminated string at offset %d", i)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool { return isIdentStart(c) || ('0' <= c && c <= '9') }
+
+func isStringPrefix(s string) bool {
+	switch strings.ToLower(s) {
+	case "r", "b", "rb", "br":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2014 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package buildfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	const src = `load("@rules_go//go:def.bzl", "go_library")
+
+# A library.
+go_library(
+    name = "lib",
+    srcs = glob(["*.go"]) + ["extra.go"],
+    deps = select({
+        "//conditions:default": [":dep"],
+    }),
+    visibility = ['//visibility:public'],
+    doc = """multi
+line""",
+)
+
+x = native.cc_library(name = "skipped")
+`
+	// lit returns the str for the first occurrence of v in src.  If v is
+	// quoted, the quotes are matched but excluded from the result.
+	lit := func(v string) str {
+		i := strings.Index(src, v)
+		if uq := strings.Trim(v, `"`); uq != v {
+			return str{Value: uq, Start: i + 1, End: i + 1 + len(uq)}
+		}
+		return str{Value: v, Start: i, End: i + len(v)}
+	}
+	calls, err := parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []call{{
+		Func: "load",
+		Args: []arg{
+			{Strings: []str{lit("@rules_go//go:def.bzl")}},
+			{Strings: []str{lit("go_library")}},
+		},
+	}, {
+		Func: "go_library",
+		Args: []arg{
+			{Name: "name", Strings: []str{lit(`"lib"`)}},
+			{Name: "srcs", Strings: []str{lit("extra.go")}},
+			{Name: "deps", Strings: []str{lit(":dep")}},
+			{Name: "visibility", Strings: []str{lit("//visibility:public")}},
+			{Name: "doc", Strings: []str{lit("multi\nline")}},
+		},
+	}}
+	if diff := cmp.Diff(want, calls); diff != "" {
+		t.Errorf("Unexpected calls: (-want +got)\n%s", diff)
+	}
+	for _, c := range calls {
+		for _, a := range c.Args {
+			for _, s := range a.Strings {
+				if got := src[s.Start:s.End]; got != s.Value {
+					t.Errorf("Span of %q covers %q", s.Value, got)
+				}
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		`go_library(name = "x"`,
+		`go_library(name = "x]`,
+		`go_library(name = "x"])`,
+	} {
+		if calls, err := parse([]byte(src)); err == nil {
+			t.Errorf("parse(%q): got %v, want error", src, calls)
+		}
+	}
+}
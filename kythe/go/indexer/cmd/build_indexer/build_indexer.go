@@ -0,0 +1,116 @@
+/*
+ * Copyright 2014 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary build_indexer emits Kythe entries for the Bazel BUILD files in a
+// workspace, linking each target to its sources and dependencies, and for the
+// labels mentioned in YAML configuration files.
+//
+// Usage:
+//
+//	build_indexer --corpus example.com/repo ~/repo > entries
+//
+// BUILD and YAML files are emitted as file nodes with their text; the source
+// files they refer to are expected to be emitted by the indexers for their
+// languages.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"kythe.io/kythe/go/indexer/buildfile"
+	"kythe.io/kythe/go/platform/delimited"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+var (
+	corpus = flag.String("corpus", "", "Corpus of the emitted nodes")
+	root   = flag.String("root", "", "Root of the emitted nodes")
+	doJSON = flag.Bool("json", false, "Write output as JSON")
+)
+
+func init() {
+	flag.Usage = flagutil.SimpleUsage("Emit Kythe entries for the BUILD and YAML files in a workspace",
+		"[--corpus c] [--root r] [--json] workspace_dir")
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flagutil.UsageError("expected a single workspace directory")
+	}
+	dir := flag.Arg(0)
+
+	ix := buildfile.NewIndexer(&buildfile.Options{Corpus: *corpus, Root: *root})
+	if err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel != "." && (strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "bazel-")) {
+				return filepath.SkipDir
+			}
+			return nil
+		} else if !d.Type().IsRegular() {
+			return nil
+		}
+		ix.AddSourceFile(rel)
+		if !buildfile.IsBuildFile(rel) && !buildfile.IsConfigFile(rel) {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if buildfile.IsConfigFile(rel) {
+			ix.AddConfigFile(rel, src)
+		} else if err := ix.AddBuildFile(rel, src); err != nil {
+			log.Warningf("Skipping %s: %v", path, err)
+		}
+		return nil
+	}); err != nil {
+		log.Fatalf("Error reading workspace: %v", err)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	var write func(*spb.Entry) error
+	if *doJSON {
+		enc := json.NewEncoder(out)
+		write = func(e *spb.Entry) error { return enc.Encode(e) }
+	} else {
+		wr := delimited.NewWriter(out)
+		write = func(e *spb.Entry) error { return wr.PutProto(e) }
+	}
+	if err := ix.Emit(write); err != nil {
+		log.Fatal(err)
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2014 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary markdown_indexer emits Kythe entries linking the code references in
+// Markdown documents to the nodes they name.  Names are resolved against the
+// code facts of previously indexed entry streams.
+//
+// Usage:
+//
+//	markdown_indexer --entries go.entries,java.entries --corpus example.com/repo ~/repo > doc.entries
+//
+// Each argument may be a Markdown file or a directory to search for them.
+// Paths in the emitted VNames are relative to the --workspace directory,
+// which defaults to the current directory.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"kythe.io/kythe/go/indexer/markdown"
+	"kythe.io/kythe/go/platform/delimited"
+	"kythe.io/kythe/go/platform/vfs"
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+var (
+	corpus    = flag.String("corpus", "", "Corpus of the emitted nodes")
+	root      = flag.String("root", "", "Root of the emitted nodes")
+	workspace = flag.String("workspace", ".", "Directory to which emitted paths are relative")
+	doJSON    = flag.Bool("json", false, "Write output as JSON")
+
+	entryFiles flagutil.StringList
+)
+
+func init() {
+	flag.Var(&entryFiles, "entries", "Comma-separated delimited entry streams whose code facts are used to resolve names (required)")
+	flag.Usage = flagutil.SimpleUsage("Emit Kythe entries for the code references in Markdown documents",
+		"--entries path,... [--corpus c] [--root r] [--workspace dir] [--json] (file|dir)+")
+}
+
+func main() {
+	flag.Parse()
+	if len(entryFiles) == 0 {
+		flagutil.UsageError("missing required --entries")
+	} else if flag.NArg() == 0 {
+		flagutil.UsageError("no Markdown files or directories given")
+	}
+	ctx := context.Background()
+
+	syms := markdown.NewSymbols()
+	for _, path := range entryFiles {
+		f, err := vfs.Open(ctx, path)
+		if err != nil {
+			log.Fatalf("Failed to open entries %q: %v", path, err)
+		}
+		if err := stream.NewReader(bufio.NewReader(f))(syms.AddEntry); err != nil {
+			log.Fatalf("Failed to read entries %q: %v", path, err)
+		}
+		f.Close()
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	var write func(*spb.Entry) error
+	if *doJSON {
+		enc := json.NewEncoder(out)
+		write = func(e *spb.Entry) error { return enc.Encode(e) }
+	} else {
+		wr := delimited.NewWriter(out)
+		write = func(e *spb.Entry) error { return wr.PutProto(e) }
+	}
+
+	opts := &markdown.Options{Corpus: *corpus, Root: *root}
+	index := func(path string) error {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(*workspace, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			rel = path
+		}
+		return markdown.Index(filepath.ToSlash(rel), src, syms, opts, write)
+	}
+	for _, arg := range flag.Args() {
+		if err := filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			} else if d.IsDir() {
+				if path != arg && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			} else if path != arg && !markdown.IsMarkdown(path) {
+				return nil
+			}
+			return index(path)
+		}); err != nil {
+			log.Fatalf("Error indexing %s: %v", arg, err)
+		}
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
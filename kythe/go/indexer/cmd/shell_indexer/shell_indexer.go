@@ -0,0 +1,129 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary shell_indexer emits Kythe entries for the shell scripts in a
+// directory tree: their file nodes, the functions they define, and the files
+// they include with source (or .).
+//
+// Usage:
+//
+//	shell_indexer --corpus example.com/repo ~/repo > entries
+//
+// Scripts are recognized by their extension or by a #! line naming a shell.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"kythe.io/kythe/go/indexer/shell"
+	"kythe.io/kythe/go/platform/delimited"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+var (
+	corpus = flag.String("corpus", "", "Corpus of the emitted nodes")
+	root   = flag.String("root", "", "Root of the emitted nodes")
+	doJSON = flag.Bool("json", false, "Write output as JSON")
+)
+
+func init() {
+	flag.Usage = flagutil.SimpleUsage("Emit Kythe entries for the shell scripts in a directory tree",
+		"[--corpus c] [--root r] [--json] dir")
+}
+
+// sniffSize is the number of bytes read from a file without a shell
+// extension to check for a #! line.
+const sniffSize = 128
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flagutil.UsageError("expected a single directory")
+	}
+	dir := flag.Arg(0)
+
+	ix := shell.NewIndexer(&shell.Options{Corpus: *corpus, Root: *root})
+	if err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel != "." && (strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "bazel-")) {
+				return filepath.SkipDir
+			}
+			return nil
+		} else if !d.Type().IsRegular() {
+			return nil
+		}
+		ix.AddSourceFile(rel)
+		head, err := readHead(path)
+		if err != nil {
+			return err
+		} else if !shell.IsShellScript(rel, head) {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		ix.AddScript(rel, src)
+		return nil
+	}); err != nil {
+		log.Fatalf("Error reading directory: %v", err)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	var write func(*spb.Entry) error
+	if *doJSON {
+		enc := json.NewEncoder(out)
+		write = func(e *spb.Entry) error { return enc.Encode(e) }
+	} else {
+		wr := delimited.NewWriter(out)
+		write = func(e *spb.Entry) error { return wr.PutProto(e) }
+	}
+	if err := ix.Emit(write); err != nil {
+		log.Fatal(err)
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readHead returns up to sniffSize bytes from the start of the file at path.
+func readHead(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	buf := make([]byte, sniffSize)
+	n, _ := f.Read(buf)
+	return buf[:n], nil
+}
@@ -0,0 +1,215 @@
+/*
+ * Copyright 2014 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package markdown indexes references to code in Markdown documents.
+//
+// Inline code spans that consist of a single identifier or qualified name
+// (optionally followed by "()"), and qualified names appearing in fenced code
+// blocks, are resolved against a table of known symbols.  Each name that
+// resolves to exactly one node is emitted as an anchor with a ref edge to
+// that node, so documentation appears among the node's cross-references.
+package markdown // import "kythe.io/kythe/go/indexer/markdown"
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// Language is the language of the anchors emitted.
+const Language = "markdown"
+
+// IsMarkdown reports whether the file at p is a Markdown document.
+func IsMarkdown(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// Options control the nodes emitted by Index.
+type Options struct {
+	// Corpus and Root are used in the VNames of the emitted nodes.
+	Corpus, Root string
+}
+
+// A span is a candidate reference in a document.
+type span struct {
+	name       string
+	start, end int
+}
+
+var (
+	// inlineName matches the entire contents of an inline code span that
+	// names a symbol.
+	inlineName = regexp.MustCompile(`^[A-Za-z_]\w*(?:(?:\.|::|/)[A-Za-z_]\w*)*(?:\(\))?$`)
+
+	// blockName matches qualified names within code blocks.
+	blockName = regexp.MustCompile(`[A-Za-z_]\w*(?:(?:\.|::)[A-Za-z_]\w*)+`)
+)
+
+// Index emits the file node for the Markdown document at path, relative to
+// the corpus root, along with an anchor and ref edge for each reference in src
+// that resolves in syms.
+func Index(path string, src []byte, syms *Symbols, opts *Options, emit func(*spb.Entry) error) error {
+	if opts == nil {
+		opts = new(Options)
+	}
+	file := &spb.VName{Corpus: opts.Corpus, Root: opts.Root, Path: path}
+	fact := func(v *spb.VName, name, value string) error {
+		return emit(&spb.Entry{Source: v, FactName: name, FactValue: []byte(value)})
+	}
+	if err := fact(file, facts.NodeKind, nodes.File); err != nil {
+		return err
+	}
+	if err := emit(&spb.Entry{Source: file, FactName: facts.Text, FactValue: src}); err != nil {
+		return err
+	}
+
+	for _, s := range scan(string(src)) {
+		target := syms.Lookup(s.name)
+		if target == nil {
+			continue
+		}
+		anchor := &spb.VName{
+			Corpus:    opts.Corpus,
+			Root:      opts.Root,
+			Path:      path,
+			Language:  Language,
+			Signature: "#" + strconv.Itoa(s.start) + ":" + strconv.Itoa(s.end),
+		}
+		if err := fact(anchor, facts.NodeKind, nodes.Anchor); err != nil {
+			return err
+		} else if err := fact(anchor, facts.AnchorStart, strconv.Itoa(s.start)); err != nil {
+			return err
+		} else if err := fact(anchor, facts.AnchorEnd, strconv.Itoa(s.end)); err != nil {
+			return err
+		} else if err := emit(&spb.Entry{Source: anchor, EdgeKind: edges.Ref, Target: target, FactName: "/"}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scan returns the candidate references in a Markdown document: the contents
+// of inline code spans that look like names, and the qualified names in
+// fenced code blocks.
+func scan(src string) []span {
+	var spans []span
+	var fence string // the opening fence of the current code block, if any
+	for off := 0; off < len(src); {
+		end := strings.IndexByte(src[off:], '\n')
+		if end < 0 {
+			end = len(src)
+		} else {
+			end += off
+		}
+		line := src[off:end]
+
+		if f := fenceOf(line); f != "" && (fence == "" || (f[0] == fence[0] && len(f) >= len(fence) && strings.TrimSpace(line) == f)) {
+			if fence == "" {
+				fence = f
+			} else {
+				fence = ""
+			}
+		} else if fence != "" {
+			for _, m := range blockName.FindAllStringIndex(line, -1) {
+				spans = append(spans, span{name: line[m[0]:m[1]], start: off + m[0], end: off + m[1]})
+			}
+		} else {
+			spans = append(spans, inlineSpans(line, off)...)
+		}
+		off = end + 1
+	}
+	return spans
+}
+
+// fenceOf returns the code fence that line begins with, or "".
+func fenceOf(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || len(trimmed) < 3 || (trimmed[0] != '`' && trimmed[0] != '~') {
+		return ""
+	}
+	n := 0
+	for n < len(trimmed) && trimmed[n] == trimmed[0] {
+		n++
+	}
+	if n < 3 {
+		return ""
+	}
+	return trimmed[:n]
+}
+
+// inlineSpans returns the names in the inline code spans of line, which
+// begins at offset off in the document.
+func inlineSpans(line string, off int) []span {
+	var spans []span
+	for i := 0; i < len(line); {
+		if line[i] != '`' {
+			i++
+			continue
+		}
+		n := runLength(line, i)
+		open := i + n
+		closing := -1
+		for j := open; j < len(line); {
+			if line[j] != '`' {
+				j++
+				continue
+			}
+			if m := runLength(line, j); m == n {
+				closing = j
+				break
+			} else {
+				j += m
+			}
+		}
+		if closing < 0 {
+			i = open
+			continue
+		}
+		start, end := open, closing
+		for start < end && line[start] == ' ' {
+			start++
+		}
+		for end > start && line[end-1] == ' ' {
+			end--
+		}
+		if text := line[start:end]; inlineName.MatchString(text) {
+			text = strings.TrimSuffix(text, "()")
+			spans = append(spans, span{name: text, start: off + start, end: off + start + len(text)})
+		}
+		i = closing + n
+	}
+	return spans
+}
+
+// runLength returns the number of consecutive backticks in s beginning at i.
+func runLength(s string, i int) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == '`' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2014 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package markdown
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestScan(t *testing.T) {
+	const src = "Use `stream.NewReader()` or ``Foo`` but not `a + b`.\n" +
+		"```go\n" +
+		"rd := stream.NewReader(r) // x.y\n" +
+		"```\n" +
+		"Unclosed `span and ~~~ not a fence\n"
+	var got []string
+	for _, s := range scan(src) {
+		if src[s.start:s.end] != s.name {
+			t.Errorf("Span %+v covers %q", s, src[s.start:s.end])
+		}
+		got = append(got, s.name)
+	}
+	want := []string{"stream.NewReader", "Foo", "stream.NewReader", "x.y"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected spans: (-want +got)\n%s", diff)
+	}
+}
+
+func code(v *spb.VName, pkg, name string) *spb.Entry {
+	ms := &cpb.MarkedSource{
+		Child: []*cpb.MarkedSource{{
+			Kind:          cpb.MarkedSource_CONTEXT,
+			PostChildText: ".",
+			Child:         []*cpb.MarkedSource{{Kind: cpb.MarkedSource_IDENTIFIER, PreText: pkg}},
+		}, {
+			Kind:    cpb.MarkedSource_IDENTIFIER,
+			PreText: name,
+		}},
+	}
+	rec, err := proto.Marshal(ms)
+	if err != nil {
+		panic(err)
+	}
+	return &spb.Entry{Source: v, FactName: facts.Code, FactValue: rec}
+}
+
+func TestIndex(t *testing.T) {
+	newReader := &spb.VName{Signature: "NewReader", Language: "go"}
+	streamClose := &spb.VName{Signature: "stream.Close", Language: "go"}
+	mergeClose := &spb.VName{Signature: "merge.Close", Language: "go"}
+
+	syms := NewSymbols()
+	for _, e := range []*spb.Entry{
+		code(newReader, "kythe.io/storage/stream", "NewReader"),
+		code(streamClose, "kythe.io/storage/stream", "Close"),
+		code(mergeClose, "kythe.io/storage/merge", "Close"),
+		{Source: newReader, FactName: facts.NodeKind, FactValue: []byte("function")},
+	} {
+		if err := syms.AddEntry(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const src = "Call `NewReader` then `Close` (ambiguous) or `merge.Close()`.\n" +
+		"See `kythe.io/storage/stream.Close` and `Unknown`.\n"
+	var refs []string
+	if err := Index("doc/README.md", []byte(src), syms, &Options{Corpus: "c"}, func(e *spb.Entry) error {
+		if e.EdgeKind == edges.Ref {
+			from, to, _ := strings.Cut(strings.TrimPrefix(e.Source.Signature, "#"), ":")
+			start, _ := strconv.Atoi(from)
+			end, _ := strconv.Atoi(to)
+			refs = append(refs, src[start:end]+" -> "+e.Target.Signature)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(refs)
+	want := []string{
+		"NewReader -> NewReader",
+		"kythe.io/storage/stream.Close -> stream.Close",
+		"merge.Close -> merge.Close",
+	}
+	if diff := cmp.Diff(want, refs); diff != "" {
+		t.Errorf("Unexpected refs: (-want +got)\n%s", diff)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2014 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package markdown
+
+import (
+	"fmt"
+
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/markedsource"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	"google.golang.org/protobuf/proto"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// Symbols maps the names by which code may be referred to in documentation
+// to the nodes they denote.  A node is known by its qualified name and by
+// each suffix of it that begins after a separator; for example, a node named
+// "kythe.io/pkg/stream.NewReader" may be found as "stream.NewReader" or
+// "NewReader".  Names shared by more than one node are ambiguous and are not
+// resolved.
+type Symbols struct {
+	names map[string]*symbol
+}
+
+type symbol struct {
+	ticket string
+	vname  *spb.VName // nil if the name is ambiguous
+}
+
+// NewSymbols returns an empty symbol table.
+func NewSymbols() *Symbols { return &Symbols{names: make(map[string]*symbol)} }
+
+// AddEntry adds the name described by e to the table, if e is a code fact.
+// Other entries are ignored.
+func (s *Symbols) AddEntry(e *spb.Entry) error {
+	if e.GetFactName() != facts.Code || e.GetEdgeKind() != "" {
+		return nil
+	}
+	var ms cpb.MarkedSource
+	if err := proto.Unmarshal(e.GetFactValue(), &ms); err != nil {
+		return fmt.Errorf("error unmarshalling code for %s: %v", kytheuri.ToString(e.GetSource()), err)
+	}
+	info := markedsource.RenderQualifiedName(&ms)
+	name := info.GetQualifiedName()
+	if name == "" {
+		name = info.GetBaseName()
+	}
+	s.Add(name, e.GetSource())
+	return nil
+}
+
+// Add records that v is known by name and each of its suffixes.
+func (s *Symbols) Add(name string, v *spb.VName) {
+	if name == "" {
+		return
+	}
+	ticket := kytheuri.ToString(v)
+	s.add(name, ticket, v)
+	for i := 0; i+1 < len(name); i++ {
+		if isSeparator(name[i]) && !isSeparator(name[i+1]) {
+			s.add(name[i+1:], ticket, v)
+		}
+	}
+}
+
+func (s *Symbols) add(name, ticket string, v *spb.VName) {
+	if sym, ok := s.names[name]; !ok {
+		s.names[name] = &symbol{ticket: ticket, vname: v}
+	} else if sym.ticket != ticket {
+		sym.vname = nil
+	}
+}
+
+// Lookup returns the node uniquely known by name, or nil.
+func (s *Symbols) Lookup(name string) *spb.VName {
+	if sym, ok := s.names[name]; ok {
+		return sym.vname
+	}
+	return nil
+}
+
+func isSeparator(c byte) bool { return c == '.' || c == '/' || c == ':' }
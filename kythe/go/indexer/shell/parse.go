@@ -0,0 +1,418 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shell
+
+import (
+	"strings"
+)
+
+// A word is a shell word, with the offsets of its full text (including any
+// quotes) in the source.
+type word struct {
+	Raw        string // the source text of the word
+	Value      string // the unquoted value, with each expansion replaced by expansion
+	Start, End int
+}
+
+// expansion replaces each parameter, command, or arithmetic expansion in the
+// Value of a word.
+const expansion = "\x00"
+
+// literal reports whether w contains no expansions.
+func (w word) literal() bool { return !strings.Contains(w.Value, expansion) }
+
+// A command is a simple command: its words, excluding assignments,
+// redirections, and reserved words that introduce it.
+type command struct {
+	Words []word
+}
+
+// A function is a function definition.
+type function struct {
+	Name word
+}
+
+// A script holds the constructs found in a shell script.
+type script struct {
+	Commands  []command
+	Functions []function
+}
+
+// reserved are the reserved words after which a command may begin.
+var reserved = map[string]bool{
+	"!": true, "{": true, "}": true, "do": true, "done": true, "elif": true,
+	"else": true, "fi": true, "if": true, "then": true, "time": true,
+	"until": true, "while": true,
+}
+
+type tokenKind int
+
+const (
+	tokWord     tokenKind = iota
+	tokOperator           // a control or redirection operator
+	tokNewline
+)
+
+type token struct {
+	kind tokenKind
+	text string // for operators, the operator
+	word word
+}
+
+// parse finds the simple commands and function definitions in a shell
+// script.  It understands quoting, expansions, comments, and here-documents
+// well enough to find the start of each command, but not the structure of
+// compound commands; an unterminated construct extends to the end of src.
+func parse(src []byte) *script {
+	toks := tokenize(string(src))
+	s := new(script)
+	var cur *command
+	atStart := true   // whether the next word begins a command
+	redirect := false // whether the next word is the target of a redirection
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		if t.kind != tokWord {
+			cur = nil
+			redirect = false
+			switch {
+			case t.kind == tokNewline, t.text == "(", t.text == ")":
+				atStart = true
+			case strings.ContainsAny(t.text, "<>"):
+				redirect = true
+			default: // ; & | && || ;;
+				atStart = true
+			}
+			continue
+		}
+		w := t.word
+		if redirect {
+			redirect = false
+			continue
+		}
+		if cur == nil && atStart {
+			if reserved[w.Raw] {
+				continue
+			}
+			// function name [()]
+			if w.Raw == "function" && i+1 < len(toks) && toks[i+1].kind == tokWord {
+				s.Functions = append(s.Functions, function{Name: toks[i+1].word})
+				i++
+				if i+2 < len(toks) && toks[i+1].text == "(" && toks[i+2].text == ")" {
+					i += 2
+				}
+				continue
+			}
+			// name ()
+			if i+2 < len(toks) && toks[i+1].text == "(" && toks[i+2].text == ")" && isName(w.Raw) {
+				s.Functions = append(s.Functions, function{Name: w})
+				i += 2
+				continue
+			}
+			if isAssignment(w.Raw) {
+				continue
+			}
+			s.Commands = append(s.Commands, command{Words: []word{w}})
+			cur = &s.Commands[len(s.Commands)-1]
+			atStart = false
+			continue
+		}
+		if cur != nil {
+			cur.Words = append(cur.Words, w)
+		}
+	}
+	return s
+}
+
+// isName reports whether s can name a function.  Bash accepts almost any
+// word; this accepts the names in common use.
+func isName(s string) bool {
+	if s == "" || reserved[s] {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '-' || c == '.' || c == ':'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isAssignment reports whether s is a variable assignment.
+func isAssignment(s string) bool {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return false
+	}
+	name := strings.TrimSuffix(s[:i], "+")
+	for j, c := range name {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || j > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// operators are the control and redirection operators, longest first.
+var operators = []string{
+	"&&", "||", ";;", "<<-", "<<<", "<<", ">>", "<&", ">&", "&>", "<>", ">|",
+	"&", "|", ";", "(", ")", "<", ">",
+}
+
+// tokenize splits src into words, operators, and newlines, dropping comments
+// and the bodies of here-documents.
+func tokenize(src string) []token {
+	var toks []token
+	var heredocs []string // delimiters of pending here-documents; "-" prefix strips tabs
+	for pos := 0; pos < len(src); {
+		c := src[pos]
+		switch {
+		case c == '\n':
+			toks = append(toks, token{kind: tokNewline})
+			pos++
+			for _, delim := range heredocs {
+				pos = skipHeredoc(src, pos, delim)
+			}
+			heredocs = nil
+		case c == ' ' || c == '\t' || c == '\r':
+			pos++
+		case c == '\\' && pos+1 < len(src) && src[pos+1] == '\n':
+			pos += 2
+		case c == '#':
+			for pos < len(src) && src[pos] != '\n' {
+				pos++
+			}
+		default:
+			if op := operatorAt(src, pos); op != "" {
+				toks = append(toks, token{kind: tokOperator, text: op})
+				pos += len(op)
+				if op == "<<" || op == "<<-" {
+					end := skipBlanks(src, pos)
+					if w, next := scanWord(src, end); next > end {
+						delim := strings.ReplaceAll(w.Value, expansion, "")
+						if op == "<<-" {
+							delim = "-" + delim
+						}
+						heredocs = append(heredocs, delim)
+						toks = append(toks, token{kind: tokWord, word: w})
+						pos = next
+					}
+				}
+				continue
+			}
+			w, next := scanWord(src, pos)
+			toks = append(toks, token{kind: tokWord, word: w})
+			pos = next
+		}
+	}
+	return toks
+}
+
+func operatorAt(src string, pos int) string {
+	for _, op := range operators {
+		if strings.HasPrefix(src[pos:], op) {
+			return op
+		}
+	}
+	return ""
+}
+
+func skipBlanks(src string, pos int) int {
+	for pos < len(src) && (src[pos] == ' ' || src[pos] == '\t') {
+		pos++
+	}
+	return pos
+}
+
+// skipHeredoc returns the offset following the here-document beginning at
+// pos and terminated by delim.
+func skipHeredoc(src string, pos int, delim string) int {
+	stripTabs := strings.HasPrefix(delim, "-")
+	delim = strings.TrimPrefix(delim, "-")
+	for pos < len(src) {
+		end := strings.IndexByte(src[pos:], '\n')
+		if end < 0 {
+			end = len(src)
+		} else {
+			end += pos
+		}
+		line := src[pos:end]
+		if stripTabs {
+			line = strings.TrimLeft(line, "\t")
+		}
+		pos = end + 1
+		if line == delim {
+			break
+		}
+	}
+	if pos > len(src) {
+		return len(src)
+	}
+	return pos
+}
+
+// scanWord scans the word beginning at pos, returning it and the offset
+// following it.
+func scanWord(src string, pos int) (word, int) {
+	var val strings.Builder
+	start := pos
+	for pos < len(src) {
+		c := src[pos]
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' || operatorAt(src, pos) != "" {
+			break
+		}
+		switch c {
+		case '\\':
+			if pos+1 < len(src) {
+				if src[pos+1] != '\n' {
+					val.WriteByte(src[pos+1])
+				}
+				pos += 2
+			} else {
+				pos++
+			}
+		case '\'':
+			end := strings.IndexByte(src[pos+1:], '\'')
+			if end < 0 {
+				val.WriteString(src[pos+1:])
+				pos = len(src)
+			} else {
+				val.WriteString(src[pos+1 : pos+1+end])
+				pos += end + 2
+			}
+		case '"':
+			pos = scanDouble(src, pos+1, &val)
+		case '$', '`':
+			next := scanExpansion(src, pos)
+			if next == pos+1 && c == '$' {
+				val.WriteByte('$') // a lone $
+			} else {
+				val.WriteString(expansion)
+			}
+			pos = next
+		default:
+			val.WriteByte(c)
+			pos++
+		}
+	}
+	return word{Raw: src[start:pos], Value: val.String(), Start: start, End: pos}, pos
+}
+
+// scanDouble scans the contents of a double-quoted string beginning at pos,
+// appending its value to val and returning the offset following the closing
+// quote.
+func scanDouble(src string, pos int, val *strings.Builder) int {
+	for pos < len(src) {
+		switch c := src[pos]; c {
+		case '"':
+			return pos + 1
+		case '\\':
+			if pos+1 < len(src) && strings.IndexByte("$`\"\\\n", src[pos+1]) >= 0 {
+				if src[pos+1] != '\n' {
+					val.WriteByte(src[pos+1])
+				}
+				pos += 2
+			} else {
+				val.WriteByte(c)
+				pos++
+			}
+		case '$', '`':
+			next := scanExpansion(src, pos)
+			if next == pos+1 && c == '$' {
+				val.WriteByte('$')
+			} else {
+				val.WriteString(expansion)
+			}
+			pos = next
+		default:
+			val.WriteByte(c)
+			pos++
+		}
+	}
+	return pos
+}
+
+// scanExpansion returns the offset following the expansion beginning with
+// the $ or ` at pos.
+func scanExpansion(src string, pos int) int {
+	if src[pos] == '`' {
+		for pos++; pos < len(src); pos++ {
+			switch src[pos] {
+			case '\\':
+				pos++
+			case '`':
+				return pos + 1
+			}
+		}
+		return len(src)
+	}
+	pos++ // $
+	if pos >= len(src) {
+		return pos
+	}
+	switch c := src[pos]; {
+	case c == '(':
+		return scanBalanced(src, pos+1, '(', ')')
+	case c == '{':
+		return scanBalanced(src, pos+1, '{', '}')
+	case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		for pos < len(src) && (src[pos] == '_' || src[pos] >= 'a' && src[pos] <= 'z' ||
+			src[pos] >= 'A' && src[pos] <= 'Z' || src[pos] >= '0' && src[pos] <= '9') {
+			pos++
+		}
+		return pos
+	case strings.IndexByte("0123456789@*#?$!-", c) >= 0:
+		return pos + 1
+	}
+	return pos
+}
+
+// scanBalanced returns the offset following the close bracket matching an
+// open bracket just before pos, skipping quoted text and nested expansions.
+func scanBalanced(src string, pos int, open, close byte) int {
+	var discard strings.Builder
+	for depth := 1; pos < len(src); {
+		switch c := src[pos]; c {
+		case open:
+			depth++
+			pos++
+		case close:
+			depth--
+			pos++
+			if depth == 0 {
+				return pos
+			}
+		case '\\':
+			pos += 2
+		case '\'':
+			if end := strings.IndexByte(src[pos+1:], '\''); end >= 0 {
+				pos += end + 2
+			} else {
+				return len(src)
+			}
+		case '"':
+			pos = scanDouble(src, pos+1, &discard)
+		case '$', '`':
+			pos = scanExpansion(src, pos)
+		default:
+			pos++
+		}
+	}
+	return len(src)
+}
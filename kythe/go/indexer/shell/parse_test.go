@@ -0,0 +1,97 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shell
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	const src = `#!/bin/bash
+# setup() { in a comment }
+set -e
+setup() {
+  FOO=bar source "lib/$NAME.sh" # trailing
+}
+function teardown {
+  echo "done; source x" >/dev/null && . ./common.sh
+}
+function main() ( cd "$(dirname "$0")"; x=$(source y.sh) )
+cat <<-'EOF'
+	source inside.sh
+	EOF
+if true; then source a\
+b.sh; fi
+`
+	s := parse([]byte(src))
+
+	var funcs []string
+	for _, fn := range s.Functions {
+		if got := src[fn.Name.Start:fn.Name.End]; got != fn.Name.Raw {
+			t.Errorf("Function %q covers %q", fn.Name.Raw, got)
+		}
+		funcs = append(funcs, fn.Name.Value)
+	}
+	if diff := cmp.Diff([]string{"setup", "teardown", "main"}, funcs); diff != "" {
+		t.Errorf("Functions: (-want +got)\n%s", diff)
+	}
+
+	var cmds [][]string
+	for _, c := range s.Commands {
+		var ws []string
+		for _, w := range c.Words {
+			ws = append(ws, w.Value)
+		}
+		cmds = append(cmds, ws)
+	}
+	want := [][]string{
+		{"set", "-e"},
+		{"source", "lib/" + expansion + ".sh"},
+		{"echo", "done; source x"},
+		{".", "./common.sh"},
+		{"cd", expansion},
+		{"cat"},
+		{"true"},
+		{"source", "ab.sh"},
+	}
+	if diff := cmp.Diff(want, cmds); diff != "" {
+		t.Errorf("Commands: (-want +got)\n%s", diff)
+	}
+}
+
+func TestScanWord(t *testing.T) {
+	tests := []struct {
+		src, raw, value string
+	}{
+		{`plain rest`, `plain`, `plain`},
+		{`'single $x'x`, `'single $x'x`, `single $xx`},
+		{`"a\"b"`, `"a\"b"`, `a"b`},
+		{`$HOME/x`, `$HOME/x`, expansion + "/x"},
+		{`"${x%/*}"/y`, `"${x%/*}"/y`, expansion + "/y"},
+		{"`pwd`/z;", "`pwd`/z", expansion + "/z"},
+		{`$((1+(2)))`, `$((1+(2)))`, expansion},
+		{`cost$`, `cost$`, `cost$`},
+	}
+	for _, test := range tests {
+		w, end := scanWord(test.src, 0)
+		if w.Raw != test.raw || w.Value != test.value || end != len(test.raw) {
+			t.Errorf("scanWord(%q): got %+v ending at %d, want raw %q value %q", test.src, w, end, test.raw, test.value)
+		}
+	}
+}
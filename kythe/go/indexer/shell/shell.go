@@ -0,0 +1,242 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package shell indexes shell scripts.
+//
+// Each script is emitted as a file node with its text.  Each function defined
+// in a script becomes a function node, with a defines/binding anchor at its
+// name, and each argument of a source (or .) command that names a known file
+// becomes an anchor with a ref/includes edge to that file.  Arguments
+// containing expansions are resolved by the literal path that follows the
+// last expansion, to handle the common idiom
+//
+//	source "$(dirname "$0")/lib.sh"
+//
+// Other uses of functions and variables are not indexed.
+package shell // import "kythe.io/kythe/go/indexer/shell"
+
+import (
+	"path"
+	"strconv"
+	"strings"
+
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	"google.golang.org/protobuf/proto"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// Language is the language of the function and anchor nodes emitted.
+const Language = "sh"
+
+// Options control the nodes emitted by an Indexer.
+type Options struct {
+	// Corpus and Root are used in the VNames of all emitted nodes.
+	Corpus, Root string
+}
+
+// An Indexer collects shell scripts and emits entries for them.  Because a
+// script may source any other file, all files must be added before calling
+// Emit.
+type Indexer struct {
+	opts     Options
+	scripts  []*scriptFile
+	files    map[string]bool // paths of known files
+	hasFiles bool            // whether any file was added by AddSourceFile
+}
+
+type scriptFile struct {
+	path string
+	src  []byte
+	*script
+}
+
+// NewIndexer returns a new, empty Indexer.  If opts == nil, default options
+// are used.
+func NewIndexer(opts *Options) *Indexer {
+	ix := &Indexer{files: make(map[string]bool)}
+	if opts != nil {
+		ix.opts = *opts
+	}
+	return ix
+}
+
+// shells are the interpreters recognized in the #! line of a script.
+var shells = map[string]bool{"sh": true, "bash": true, "dash": true, "ksh": true, "zsh": true}
+
+// IsShellScript reports whether the file at p, with contents src, is a shell
+// script: whether it has a shell extension or a #! line naming a shell.
+func IsShellScript(p string, src []byte) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".sh", ".bash", ".ksh", ".zsh":
+		return true
+	}
+	if !strings.HasPrefix(string(src), "#!") {
+		return false
+	}
+	line := string(src[2:])
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false
+	}
+	interp := path.Base(fields[0])
+	if interp == "env" && len(fields) > 1 {
+		interp = fields[1]
+	}
+	return shells[interp]
+}
+
+// AddScript parses the shell script at path, relative to the corpus root.
+func (ix *Indexer) AddScript(path string, src []byte) {
+	ix.files[path] = true
+	ix.scripts = append(ix.scripts, &scriptFile{path: path, src: src, script: parse(src)})
+}
+
+// AddSourceFile records the existence of a file at path, relative to the
+// corpus root, that may be sourced by a script.  Once any file has been
+// added, includes are only resolved to scripts and files that have been
+// added.
+func (ix *Indexer) AddSourceFile(path string) {
+	ix.files[path] = true
+	ix.hasFiles = true
+}
+
+// Emit calls f with each entry for the scripts added to ix.
+func (ix *Indexer) Emit(f func(*spb.Entry) error) error {
+	e := &emitter{ix: ix, emit: f}
+	for _, s := range ix.scripts {
+		e.script(s)
+	}
+	return e.err
+}
+
+type emitter struct {
+	ix   *Indexer
+	emit func(*spb.Entry) error
+	err  error
+}
+
+func (e *emitter) fact(src *spb.VName, name string, value []byte) {
+	if e.err == nil {
+		e.err = e.emit(&spb.Entry{Source: src, FactName: name, FactValue: value})
+	}
+}
+
+func (e *emitter) edge(src, tgt *spb.VName, kind string) {
+	if e.err == nil {
+		e.err = e.emit(&spb.Entry{Source: src, EdgeKind: kind, Target: tgt, FactName: "/"})
+	}
+}
+
+func (e *emitter) fileVName(path string) *spb.VName {
+	return &spb.VName{Corpus: e.ix.opts.Corpus, Root: e.ix.opts.Root, Path: path}
+}
+
+// anchor emits an anchor spanning w in file and an edge of the given kind to
+// target.
+func (e *emitter) anchor(file *spb.VName, w word, kind string, target *spb.VName) {
+	a := proto.Clone(file).(*spb.VName)
+	a.Language = Language
+	a.Signature = "#" + strconv.Itoa(w.Start) + ":" + strconv.Itoa(w.End)
+	e.fact(a, facts.NodeKind, []byte(nodes.Anchor))
+	e.fact(a, facts.AnchorStart, []byte(strconv.Itoa(w.Start)))
+	e.fact(a, facts.AnchorEnd, []byte(strconv.Itoa(w.End)))
+	e.edge(a, target, kind)
+}
+
+func (e *emitter) script(s *scriptFile) {
+	file := e.fileVName(s.path)
+	e.fact(file, facts.NodeKind, []byte(nodes.File))
+	e.fact(file, facts.Text, s.src)
+
+	for _, fn := range s.Functions {
+		name := fn.Name.Value
+		v := &spb.VName{
+			Corpus:    e.ix.opts.Corpus,
+			Root:      e.ix.opts.Root,
+			Path:      s.path,
+			Language:  Language,
+			Signature: name,
+		}
+		e.fact(v, facts.NodeKind, []byte(nodes.Function))
+		e.fact(v, facts.Complete, []byte("definition"))
+		if code, err := proto.Marshal(markedSource(name)); err == nil {
+			e.fact(v, facts.Code, code)
+		}
+		e.anchor(file, fn.Name, edges.DefinesBinding, v)
+	}
+
+	for _, c := range s.Commands {
+		if len(c.Words) < 2 || (c.Words[0].Raw != "source" && c.Words[0].Raw != ".") {
+			continue
+		}
+		if target := e.resolve(s.path, c.Words[1]); target != "" {
+			e.anchor(file, c.Words[1], edges.RefIncludes, e.fileVName(target))
+		}
+	}
+}
+
+// resolve returns the path of the file included by w in the script at from,
+// or "" if it cannot be determined.  Relative paths are tried against the
+// directory of the script and then the corpus root.
+func (e *emitter) resolve(from string, w word) string {
+	p := w.Value
+	if !w.literal() {
+		// Only a path following an expansion (usually of the script
+		// directory) can be resolved.
+		p = p[strings.LastIndex(p, expansion)+len(expansion):]
+		if !strings.HasPrefix(p, "/") {
+			return ""
+		}
+		p = p[1:]
+	} else if path.IsAbs(p) {
+		return ""
+	}
+	if p == "" {
+		return ""
+	}
+	dirRel := path.Join(path.Dir(from), p)
+	for _, cand := range []string{dirRel, path.Clean(p)} {
+		if e.ix.files[cand] {
+			return cand
+		}
+	}
+	if w.literal() && !e.ix.hasFiles && !strings.HasPrefix(dirRel, "../") {
+		return dirRel
+	}
+	return ""
+}
+
+// markedSource returns the MarkedSource for a function.
+func markedSource(name string) *cpb.MarkedSource {
+	return &cpb.MarkedSource{
+		Child: []*cpb.MarkedSource{{
+			Kind:     cpb.MarkedSource_MODIFIER,
+			PreText:  "function",
+			PostText: " ",
+		}, {
+			Kind:    cpb.MarkedSource_IDENTIFIER,
+			PreText: name,
+		}},
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shell
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	"github.com/google/go-cmp/cmp"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestIndexer(t *testing.T) {
+	files := map[string]string{
+		"tools/build.sh": `#!/bin/sh
+. "$(dirname "$0")/lib/common.sh"
+source ../env.conf
+source "$CONFIG"
+build() { make; }
+build
+`,
+		"tools/lib/common.sh": "function log { echo \"$@\" >&2; }\nsource /etc/profile\n",
+		"env.conf":            "export A=1\n",
+	}
+
+	ix := NewIndexer(&Options{Corpus: "c"})
+	for _, path := range []string{"tools/build.sh", "tools/lib/common.sh"} {
+		ix.AddScript(path, []byte(files[path]))
+	}
+	ix.AddSourceFile("env.conf")
+
+	var entries []*spb.Entry
+	if err := ix.Emit(func(e *spb.Entry) error {
+		entries = append(entries, e)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Render edges from anchors as "file[text] kind target", and record the
+	// kinds of other nodes.
+	var got []string
+	kinds := make(map[string]string)
+	for _, e := range entries {
+		if e.EdgeKind == "" {
+			if e.FactName == facts.NodeKind && string(e.FactValue) != "anchor" {
+				kinds[e.Source.Path+":"+e.Source.Signature] = string(e.FactValue)
+			}
+			continue
+		}
+		span := strings.Split(strings.TrimPrefix(e.Source.Signature, "#"), ":")
+		start, _ := strconv.Atoi(span[0])
+		end, _ := strconv.Atoi(span[1])
+		target := e.Target.Path
+		if e.Target.Signature != "" {
+			target += ":" + e.Target.Signature
+		}
+		got = append(got, fmt.Sprintf("%s[%s] %s %s", e.Source.Path, files[e.Source.Path][start:end],
+			strings.TrimPrefix(e.EdgeKind, edges.Prefix), target))
+	}
+	sort.Strings(got)
+
+	want := []string{
+		`tools/build.sh["$(dirname "$0")/lib/common.sh"] ref/includes tools/lib/common.sh`,
+		`tools/build.sh[../env.conf] ref/includes env.conf`,
+		`tools/build.sh[build] defines/binding tools/build.sh:build`,
+		`tools/lib/common.sh[log] defines/binding tools/lib/common.sh:log`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected edges: (-want +got)\n%s", diff)
+	}
+	wantKinds := map[string]string{
+		"tools/build.sh:":         "file",
+		"tools/build.sh:build":    "function",
+		"tools/lib/common.sh:":    "file",
+		"tools/lib/common.sh:log": "function",
+	}
+	if diff := cmp.Diff(wantKinds, kinds); diff != "" {
+		t.Errorf("Unexpected node kinds: (-want +got)\n%s", diff)
+	}
+}
+
+func TestIsShellScript(t *testing.T) {
+	tests := []struct {
+		path, src string
+		want      bool
+	}{
+		{"a.sh", "", true},
+		{"b.BASH", "", true},
+		{"run", "#!/bin/bash -e\n", true},
+		{"run", "#!/usr/bin/env sh\n", true},
+		{"run", "#!/usr/bin/env python3\n", false},
+		{"run.py", "print()\n", false},
+		{"empty", "#!", false},
+	}
+	for _, test := range tests {
+		if got := IsShellScript(test.path, []byte(test.src)); got != test.want {
+			t.Errorf("IsShellScript(%q, %q): got %v, want %v", test.path, test.src, got, test.want)
+		}
+	}
+}
@@ -30,6 +30,7 @@ import (
 
 	"kythe.io/kythe/go/languageserver"
 	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/serving/identifiers"
 
 	"github.com/sourcegraph/jsonrpc2"
 )
@@ -64,9 +65,10 @@ func main() {
 	}
 	conn.Close()
 
-	client := xrefs.WebClient("http://" + *serverAddr)
-	server := languageserver.NewServer(client, &languageserver.Options{
-		PageSize: *pageSize,
+	addr := "http://" + *serverAddr
+	server := languageserver.NewServer(xrefs.WebClient(addr), &languageserver.Options{
+		PageSize:    *pageSize,
+		Identifiers: identifiers.WebClient(addr),
 	})
 
 	<-jsonrpc2.NewConn(
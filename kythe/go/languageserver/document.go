@@ -31,6 +31,7 @@ type document struct {
 	newSrc    string
 	staleRefs bool
 	defLocs   map[string]*lsp.Location
+	symbols   []*symbol
 }
 
 func newDocument(refs []*RefResolution, oldSrc string, newSrc string, defLocs map[string]*lsp.Location) *document {
@@ -80,6 +80,18 @@ func ServerHandler(ls *Server) jsonrpc2.Handler {
 					return nil, err
 				}
 				ret, err = ls.TextDocumentHover(p)
+			case "textDocument/documentSymbol":
+				var p lsp.DocumentSymbolParams
+				if err := json.Unmarshal(*req.Params, &p); err != nil {
+					return nil, err
+				}
+				ret, err = ls.TextDocumentDocumentSymbol(p)
+			case "workspace/symbol":
+				var p lsp.WorkspaceSymbolParams
+				if err := json.Unmarshal(*req.Params, &p); err != nil {
+					return nil, err
+				}
+				ret, err = ls.WorkspaceSymbol(p)
 			case "shutdown":
 				log.Info("shutdown command received...")
 				shutdownIssued = true
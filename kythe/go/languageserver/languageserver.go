@@ -20,6 +20,10 @@
 //
 //	textDocumentSync (full)
 //	referenceProvider
+//	definitionProvider
+//	hoverProvider
+//	documentSymbolProvider
+//	workspaceSymbolProvider (if Options.Identifiers is set)
 package languageserver // import "kythe.io/kythe/go/languageserver"
 
 import (
@@ -29,10 +33,13 @@ import (
 	"strings"
 
 	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/serving/identifiers"
 	"kythe.io/kythe/go/util/kytheuri"
 	"kythe.io/kythe/go/util/log"
 	"kythe.io/kythe/go/util/markedsource"
+	"kythe.io/kythe/go/util/schema/facts"
 	cpb "kythe.io/kythe/proto/common_go_proto"
+	ipb "kythe.io/kythe/proto/identifier_go_proto"
 	xpb "kythe.io/kythe/proto/xref_go_proto"
 
 	"github.com/sourcegraph/go-langserver/pkg/lsp"
@@ -55,6 +62,10 @@ type Options struct {
 	// If set, this function will be called to produce a workspace for the
 	// given LSP document. If unset, uses NewSettingsWorkspaceFromURI.
 	NewWorkspace func(lsp.DocumentURI) (Workspace, error)
+
+	// If set, workspace symbol queries are answered by looking up the query
+	// as a qualified name in this service.
+	Identifiers identifiers.Service
 }
 
 func (o *Options) pageSize() int {
@@ -64,6 +75,13 @@ func (o *Options) pageSize() int {
 	return o.PageSize
 }
 
+func (o *Options) identifiers() identifiers.Service {
+	if o == nil {
+		return nil
+	}
+	return o.Identifiers
+}
+
 func (o *Options) newWorkspace(u lsp.DocumentURI) (Workspace, error) {
 	if o == nil || o.NewWorkspace == nil {
 		return NewSettingsWorkspaceFromURI(u)
@@ -94,9 +112,11 @@ func (ls *Server) Initialize(params lsp.InitializeParams) (*lsp.InitializeResult
 				Kind:    &fullSync,
 				Options: nil,
 			},
-			ReferencesProvider: true,
-			HoverProvider:      true,
-			DefinitionProvider: true,
+			ReferencesProvider:      true,
+			HoverProvider:           true,
+			DefinitionProvider:      true,
+			DocumentSymbolProvider:  true,
+			WorkspaceSymbolProvider: ls.opts.identifiers() != nil,
 		},
 	}, nil
 }
@@ -127,6 +147,7 @@ func (ls *Server) TextDocumentDidOpen(params lsp.DidOpenTextDocumentParams) erro
 		References:        true,
 		TargetDefinitions: true,
 		SourceText:        true,
+		Filter:            []string{facts.NodeKind, facts.Subkind},
 	})
 
 	if err != nil {
@@ -156,7 +177,9 @@ func (ls *Server) TextDocumentDidOpen(params lsp.DidOpenTextDocumentParams) erro
 	defLocs := ls.defLocations(local.Workspace, dec.DefinitionLocations)
 	log.Infof("Found %d defs in file %q", len(defLocs), ticket.String())
 	log.Infof("Found %d refs in file %q", len(refs), ticket.String())
-	ls.docs[local] = newDocument(refs, string(dec.SourceText), params.TextDocument.Text, defLocs)
+	doc := newDocument(refs, string(dec.SourceText), params.TextDocument.Text, defLocs)
+	doc.symbols = documentSymbols(dec)
+	ls.docs[local] = doc
 	log.Infof("Currently opened: %d files", len(ls.docs))
 
 	return nil
@@ -398,6 +421,123 @@ func (ls *Server) TextDocumentHover(params lsp.TextDocumentPositionParams) (lsp.
 	}, nil
 }
 
+// TextDocumentDocumentSymbol lists the symbols defined in a document, at
+// their locations in the current contents of the document.  Symbols whose
+// definitions have since been edited away are omitted.
+func (ls *Server) TextDocumentDocumentSymbol(params lsp.DocumentSymbolParams) ([]lsp.SymbolInformation, error) {
+	local, err := ls.localFromURI(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	syms := []lsp.SymbolInformation{}
+	doc, exists := ls.docs[local]
+	if !exists {
+		log.Warningf("Symbols requested from unknown file %q", local)
+		return syms, nil
+	}
+
+	for _, s := range doc.symbols {
+		r := doc.rangeInNewSource(s.oldRange)
+		if r == nil {
+			continue
+		}
+		syms = append(syms, lsp.SymbolInformation{
+			Name:     s.name,
+			Kind:     s.kind,
+			Location: lsp.Location{URI: params.TextDocument.URI, Range: *r},
+		})
+	}
+	return syms, nil
+}
+
+// WorkspaceSymbol finds the symbols whose qualified name matches the query,
+// and returns their definitions.  Only definitions in files that map into a
+// workspace of the server are reported, so at least one document must have
+// been opened.
+func (ls *Server) WorkspaceSymbol(params lsp.WorkspaceSymbolParams) ([]lsp.SymbolInformation, error) {
+	syms := []lsp.SymbolInformation{}
+	ids := ls.opts.identifiers()
+	query := strings.TrimSpace(params.Query)
+	if ids == nil || query == "" {
+		return syms, nil
+	}
+
+	ctx := context.TODO()
+	found, err := ids.Find(ctx, &ipb.FindRequest{
+		Identifier:         query,
+		PickCanonicalNodes: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find identifier %q: %v", query, err)
+	}
+
+	var (
+		tickets []string
+		matches []*ipb.FindReply_Match
+	)
+	seen := make(map[string]bool)
+	for _, m := range found.Matches {
+		if _, ok := symbolKind(m.NodeKind, m.NodeSubkind); !ok || seen[m.Ticket] {
+			continue
+		}
+		seen[m.Ticket] = true
+		tickets = append(tickets, m.Ticket)
+		matches = append(matches, m)
+	}
+	if len(tickets) == 0 {
+		return syms, nil
+	}
+
+	limit := ls.opts.pageSize()
+	if params.Limit > 0 && params.Limit < limit {
+		limit = params.Limit
+	}
+	xrefs, err := ls.XRefs.CrossReferences(ctx, &xpb.CrossReferencesRequest{
+		Ticket:         tickets,
+		DefinitionKind: xpb.CrossReferencesRequest_BINDING_DEFINITIONS,
+		PageSize:       int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find definitions of %q: %v", query, err)
+	}
+
+	for _, m := range matches {
+		kind, _ := symbolKind(m.NodeKind, m.NodeSubkind)
+		name := m.BaseName
+		if name == "" {
+			name = m.QualifiedName
+		}
+		for _, def := range xrefs.CrossReferences[m.Ticket].GetDefinition() {
+			loc := ls.workspaceLoc(def.Anchor)
+			if loc == nil {
+				continue
+			}
+			syms = append(syms, lsp.SymbolInformation{
+				Name:          name,
+				Kind:          kind,
+				Location:      ls.locationInNewSource(*loc),
+				ContainerName: containerName(m.QualifiedName, m.BaseName),
+			})
+			if len(syms) == limit {
+				return syms, nil
+			}
+		}
+	}
+	return syms, nil
+}
+
+// workspaceLoc returns the location of an anchor in the first workspace of
+// the server that contains it, or nil if there is none.
+func (ls *Server) workspaceLoc(a *xpb.Anchor) *lsp.Location {
+	for _, w := range ls.workspaces {
+		if loc := ls.anchorToLoc(w, a); loc != nil {
+			return loc
+		}
+	}
+	return nil
+}
+
 func (ls *Server) localFromURI(u lsp.DocumentURI) (LocalFile, error) {
 	for _, w := range ls.workspaces {
 		local, err := w.LocalFromURI(u)
@@ -22,9 +22,13 @@ import (
 	"testing"
 
 	"kythe.io/kythe/go/test/testutil"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
 
 	cpb "kythe.io/kythe/proto/common_go_proto"
 	gpb "kythe.io/kythe/proto/graph_go_proto"
+	ipb "kythe.io/kythe/proto/identifier_go_proto"
 	xpb "kythe.io/kythe/proto/xref_go_proto"
 
 	"github.com/sourcegraph/go-langserver/pkg/lsp"
@@ -296,3 +300,148 @@ func TestReferences(t *testing.T) {
 		t.Errorf("Hover results:\ngot  %+v\nwant %+v", hovExpected, hover)
 	}
 }
+
+type mockIdentifiers struct{ matches []*ipb.FindReply_Match }
+
+func (mockIdentifiers) Close(context.Context) error { return nil }
+
+func (m mockIdentifiers) Find(_ context.Context, req *ipb.FindRequest) (*ipb.FindReply, error) {
+	var reply ipb.FindReply
+	for _, match := range m.matches {
+		if match.QualifiedName == req.Identifier {
+			reply.Matches = append(reply.Matches, match)
+		}
+	}
+	return &reply, nil
+}
+
+func TestSymbols(t *testing.T) {
+	const (
+		sourceText = "func f() {}\nvar x int\n"
+		file       = "kythe://corpus?path=file.go"
+		fn         = "kythe://corpus?lang=go?path=file.go#f"
+		local      = "kythe://corpus?lang=go?path=file.go#x"
+	)
+	span := func(line, col, offset, length int32) *cpb.Span {
+		return &cpb.Span{
+			Start: &cpb.Point{LineNumber: line, ColumnOffset: col, ByteOffset: offset},
+			End:   &cpb.Point{LineNumber: line, ColumnOffset: col + length, ByteOffset: offset + length},
+		}
+	}
+	fnDef := &xpb.Anchor{Parent: file, Span: span(1, 5, 5, 1)}
+	c := MockClient{
+		decRsp: []mockDec{{
+			ticket: file,
+			resp: xpb.DecorationsReply{
+				SourceText: []byte(sourceText),
+				Reference: []*xpb.DecorationsReply_Reference{
+					{TargetTicket: fn, Kind: edges.DefinesBinding, Span: span(1, 5, 5, 1)},
+					{TargetTicket: local, Kind: edges.DefinesBinding, Span: span(2, 4, 16, 1)},
+					{TargetTicket: fn, Kind: edges.Ref, Span: span(2, 6, 18, 3)},
+				},
+				Nodes: map[string]*cpb.NodeInfo{
+					fn:    {Facts: map[string][]byte{facts.NodeKind: []byte(nodes.Function)}},
+					local: {Facts: map[string][]byte{facts.NodeKind: []byte(nodes.Variable), facts.Subkind: []byte(nodes.Local)}},
+				},
+			},
+		}},
+		refRsp: []mockRef{{
+			ticket: fn,
+			resp: xpb.CrossReferencesReply{
+				CrossReferences: map[string]*xpb.CrossReferencesReply_CrossReferenceSet{
+					fn: {Definition: []*xpb.CrossReferencesReply_RelatedAnchor{{Anchor: fnDef}}},
+				},
+			},
+		}},
+	}
+	ids := mockIdentifiers{matches: []*ipb.FindReply_Match{{
+		Ticket:        fn,
+		NodeKind:      nodes.Function,
+		BaseName:      "f",
+		QualifiedName: "pkg.f",
+	}}}
+
+	srv := NewServer(c, &Options{
+		Identifiers: ids,
+		NewWorkspace: func(_ lsp.DocumentURI) (Workspace, error) {
+			return NewSettingsWorkspace(Settings{
+				Root: "/root/dir/",
+				Mappings: []MappingConfig{{
+					Local: ":path*",
+					VName: VNameConfig{
+						Path:   ":path*",
+						Corpus: "corpus",
+					}},
+				},
+			})
+		},
+	})
+	res, err := srv.Initialize(lsp.InitializeParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps := res.Capabilities; !caps.DocumentSymbolProvider || !caps.WorkspaceSymbolProvider {
+		t.Errorf("Symbol providers not announced: %+v", caps)
+	}
+
+	const u = "file:///root/dir/file.go"
+	if err := srv.TextDocumentDidOpen(lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{URI: u, Text: sourceText},
+	}); err != nil {
+		t.Fatalf("Unexpected error opening document (%s): %v", u, err)
+	}
+	// Move the definition down a line.
+	if err := srv.TextDocumentDidChange(lsp.DidChangeTextDocumentParams{
+		TextDocument: lsp.VersionedTextDocumentIdentifier{
+			TextDocumentIdentifier: lsp.TextDocumentIdentifier{URI: u},
+		},
+		ContentChanges: []lsp.TextDocumentContentChangeEvent{{Text: "\n" + sourceText}},
+	}); err != nil {
+		t.Fatalf("Unexpected error changing document (%s): %v", u, err)
+	}
+
+	moved := lsp.Location{
+		URI: u,
+		Range: lsp.Range{
+			Start: lsp.Position{Line: 1, Character: 5},
+			End:   lsp.Position{Line: 1, Character: 6},
+		},
+	}
+	docSyms, err := srv.TextDocumentDocumentSymbol(lsp.DocumentSymbolParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: u},
+	})
+	if err != nil {
+		t.Errorf("Unexpected error listing document symbols: %v", err)
+	}
+	want := []lsp.SymbolInformation{{Name: "f", Kind: lsp.SKFunction, Location: moved}}
+	if err := testutil.DeepEqual(want, docSyms); err != nil {
+		t.Errorf("Incorrect document symbols: %v", err)
+	}
+
+	wsSyms, err := srv.WorkspaceSymbol(lsp.WorkspaceSymbolParams{Query: "pkg.f"})
+	if err != nil {
+		t.Errorf("Unexpected error finding workspace symbols: %v", err)
+	}
+	want[0].ContainerName = "pkg"
+	if err := testutil.DeepEqual(want, wsSyms); err != nil {
+		t.Errorf("Incorrect workspace symbols: %v", err)
+	}
+
+	if got, err := srv.WorkspaceSymbol(lsp.WorkspaceSymbolParams{Query: "pkg.g"}); err != nil || len(got) != 0 {
+		t.Errorf("WorkspaceSymbol(pkg.g): got %v, %v; want no symbols", got, err)
+	}
+}
+
+func TestContainerName(t *testing.T) {
+	tests := []struct{ qualified, base, want string }{
+		{"pkg.f", "f", "pkg"},
+		{"ns::C::m", "m", "ns::C"},
+		{"f", "f", ""},
+		{"other", "f", ""},
+	}
+	for _, test := range tests {
+		if got := containerName(test.qualified, test.base); got != test.want {
+			t.Errorf("containerName(%q, %q): got %q, want %q", test.qualified, test.base, got, test.want)
+		}
+	}
+}
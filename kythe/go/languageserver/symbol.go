@@ -0,0 +1,105 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package languageserver
+
+import (
+	"strings"
+
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+
+	"github.com/sourcegraph/go-langserver/pkg/lsp"
+)
+
+// symbol is a definition within a document, as reported by documentSymbol.
+type symbol struct {
+	name     string
+	kind     lsp.SymbolKind
+	oldRange lsp.Range
+}
+
+// documentSymbols returns the symbols bound by the anchors of a decorations
+// reply whose nodes were requested with their kind and subkind facts.
+func documentSymbols(dec *xpb.DecorationsReply) []*symbol {
+	var syms []*symbol
+	for _, r := range dec.Reference {
+		if r.Kind != edges.DefinesBinding {
+			continue
+		}
+		rng := spanToRange(r.Span)
+		if rng == nil {
+			continue
+		}
+		info := dec.Nodes[r.TargetTicket].GetFacts()
+		kind, ok := symbolKind(string(info[facts.NodeKind]), string(info[facts.Subkind]))
+		if !ok {
+			continue
+		}
+		start, end := r.Span.GetStart().GetByteOffset(), r.Span.GetEnd().GetByteOffset()
+		if start < 0 || end <= start || int(end) > len(dec.SourceText) {
+			continue
+		}
+		syms = append(syms, &symbol{
+			name:     string(dec.SourceText[start:end]),
+			kind:     kind,
+			oldRange: *rng,
+		})
+	}
+	return syms
+}
+
+// symbolKind returns the kind of LSP symbol that best describes a Kythe node
+// with the given kind and subkind.  It returns false for nodes that are not
+// worth listing as symbols, such as anchors and local variables.
+func symbolKind(kind, subkind string) (lsp.SymbolKind, bool) {
+	switch subkind {
+	case nodes.Local, nodes.LocalParameter, nodes.Implicit:
+		return 0, false
+	case nodes.Enum, nodes.EnumClass:
+		return lsp.SKEnum, true
+	}
+	switch kind {
+	case nodes.Function:
+		return lsp.SKFunction, true
+	case nodes.Record, nodes.TAlias:
+		return lsp.SKClass, true
+	case nodes.Interface:
+		return lsp.SKInterface, true
+	case nodes.Variable:
+		if subkind == nodes.Field {
+			return lsp.SKField, true
+		}
+		return lsp.SKVariable, true
+	case nodes.Constant:
+		return lsp.SKConstant, true
+	case nodes.Package:
+		return lsp.SKPackage, true
+	}
+	return 0, false
+}
+
+// containerName returns the part of a qualified name that precedes its base
+// name, without trailing separators.
+func containerName(qualified, base string) string {
+	if base == "" || !strings.HasSuffix(qualified, base) {
+		return ""
+	}
+	return strings.TrimRight(strings.TrimSuffix(qualified, base), ".:/#")
+}
@@ -21,6 +21,7 @@ package driver // import "kythe.io/kythe/go/platform/analysis/driver"
 import (
 	"context"
 	goerrors "errors"
+	"fmt"
 	"time"
 
 	"kythe.io/kythe/go/platform/analysis"
@@ -169,5 +170,10 @@ func (d *Driver) runAnalysis(ctx context.Context, cu Compilation) error {
 		Revision:        cu.Revision,
 		BuildId:         cu.BuildID,
 	}, d.writeOutput)
+	if err != nil && ctx.Err() == context.DeadlineExceeded && !goerrors.Is(err, context.DeadlineExceeded) {
+		// Analyzers that run a subprocess report how it was killed rather than
+		// why; make the timeout visible to AnalysisError.
+		err = fmt.Errorf("%w after %v: %w", context.DeadlineExceeded, d.AnalysisOptions.Timeout, err)
+	}
 	return err
 }
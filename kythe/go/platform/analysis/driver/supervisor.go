@@ -0,0 +1,245 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"kythe.io/kythe/go/util/log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+)
+
+var (
+	// ErrTransient may be wrapped by an analyzer error to mark a failure that
+	// is likely to succeed if the analysis is retried.
+	ErrTransient = errors.New("transient analysis failure")
+
+	// ErrBudgetExceeded is reported by Supervisor.Err when more compilations
+	// failed than the failure budget allows.
+	ErrBudgetExceeded = errors.New("failure budget exceeded")
+)
+
+// IsTransient reports whether err wraps ErrTransient or carries a gRPC status
+// indicating a temporary condition (Unavailable, ResourceExhausted, or
+// Aborted).
+func IsTransient(err error) bool {
+	if errors.Is(err, ErrTransient) {
+		return true
+	}
+	var se interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &se) {
+		switch se.GRPCStatus().Code() {
+		case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+			return true
+		}
+	}
+	return false
+}
+
+// A Supervisor is a Context that retries transient analysis failures, and
+// tolerates failed compilations up to a budget rather than stopping the run at
+// the first failure.  After the driver finishes, Err reports whether the
+// budget was exceeded and Report describes every failure.
+//
+// Outputs written by a failed attempt are not withdrawn when the analysis is
+// retried, so unless the analyzer buffers its outputs (as the sandbox
+// analyzer does), consumers must tolerate duplicate entries.
+type Supervisor struct {
+	// The number of times to retry a transient failure of one compilation.
+	MaxRetries int
+
+	// The delay before the first retry of a compilation, doubled for each
+	// later retry.  Zero means retry immediately.
+	Backoff time.Duration
+
+	// Reports whether an analysis error is transient.  If nil, IsTransient is
+	// used.  Timeouts (errors wrapping context.DeadlineExceeded) are transient
+	// only if RetryTimeouts is set.
+	Transient     func(error) bool
+	RetryTimeouts bool
+
+	// The fraction of compilations, between 0 and 1, that may fail without
+	// failing the run.
+	FailureBudget float64
+
+	// If set, the first failure that is not retried stops the run.
+	FailFast bool
+
+	mu       sync.Mutex
+	attempts map[*apb.CompilationUnit]*attempt
+	report   Report
+}
+
+// attempt tracks the analysis of one compilation.
+type attempt struct {
+	retries int
+	failure *Failure
+}
+
+// A Report describes the outcome of a supervised run.  It is designed to be
+// encoded as JSON.
+type Report struct {
+	Compilations   int        `json:"compilations"`
+	Succeeded      int        `json:"succeeded"`
+	Failed         int        `json:"failed"`
+	Retries        int        `json:"retries"`
+	FailureRate    float64    `json:"failure_rate"`
+	FailureBudget  float64    `json:"failure_budget"`
+	BudgetExceeded bool       `json:"budget_exceeded"`
+	Failures       []*Failure `json:"failures,omitempty"`
+}
+
+// A Failure describes a compilation that could not be analyzed.
+type Failure struct {
+	Digest    string `json:"digest,omitempty"`
+	Corpus    string `json:"corpus,omitempty"`
+	Language  string `json:"language,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Attempts  int    `json:"attempts"`
+	Timeout   bool   `json:"timeout,omitempty"`
+	Error     string `json:"error"`
+}
+
+// Setup implements part of the Context interface.
+func (s *Supervisor) Setup(_ context.Context, cu Compilation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.attempts == nil {
+		s.attempts = make(map[*apb.CompilationUnit]*attempt)
+	}
+	s.attempts[cu.Unit] = new(attempt)
+	return nil
+}
+
+// Teardown implements part of the Context interface.
+func (s *Supervisor) Teardown(_ context.Context, cu Compilation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.report.Compilations++
+	if a := s.attempts[cu.Unit]; a != nil && a.failure != nil {
+		s.report.Failed++
+		s.report.Failures = append(s.report.Failures, a.failure)
+	} else {
+		s.report.Succeeded++
+	}
+	delete(s.attempts, cu.Unit)
+	return nil
+}
+
+// AnalysisError implements part of the Context interface.  Transient failures
+// are retried up to MaxRetries times; other failures are recorded and, unless
+// FailFast is set, skipped.
+func (s *Supervisor) AnalysisError(ctx context.Context, cu Compilation, err error) error {
+	timeout := errors.Is(err, context.DeadlineExceeded)
+	s.mu.Lock()
+	a := s.attempts[cu.Unit]
+	if a == nil {
+		a = new(attempt)
+		s.attempts[cu.Unit] = a
+	}
+	retry := a.retries < s.MaxRetries && s.transient(err, timeout) && ctx.Err() == nil
+	if retry {
+		a.retries++
+		s.report.Retries++
+	} else {
+		v := cu.Unit.GetVName()
+		a.failure = &Failure{
+			Digest:    cu.UnitDigest,
+			Corpus:    v.GetCorpus(),
+			Language:  v.GetLanguage(),
+			Signature: v.GetSignature(),
+			Attempts:  a.retries + 1,
+			Timeout:   timeout,
+			Error:     err.Error(),
+		}
+	}
+	retries := a.retries
+	s.mu.Unlock()
+
+	if !retry {
+		if s.FailFast {
+			return err
+		}
+		log.ErrorContextf(ctx, "Skipping compilation: %v", err)
+		return nil
+	}
+	delay := s.Backoff << (retries - 1)
+	log.WarningContextf(ctx, "Retrying compilation (attempt %d) in %v: %v", retries+1, delay, err)
+	if delay > 0 {
+		t := time.NewTimer(delay)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	return ErrRetry
+}
+
+func (s *Supervisor) transient(err error, timeout bool) bool {
+	if timeout {
+		return s.RetryTimeouts
+	} else if s.Transient != nil {
+		return s.Transient(err)
+	}
+	return IsTransient(err)
+}
+
+// Report returns a snapshot of the outcome of the compilations analyzed so
+// far.
+func (s *Supervisor) Report() *Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.report
+	r.Failures = append([]*Failure(nil), s.report.Failures...)
+	r.FailureBudget = s.FailureBudget
+	if r.Compilations > 0 {
+		r.FailureRate = float64(r.Failed) / float64(r.Compilations)
+	}
+	r.BudgetExceeded = r.FailureRate > s.FailureBudget
+	return &r
+}
+
+// Err returns an error wrapping ErrBudgetExceeded if the fraction of
+// compilations that failed exceeds the failure budget, and otherwise nil.
+func (s *Supervisor) Err() error {
+	if r := s.Report(); r.BudgetExceeded {
+		return fmt.Errorf("%w: %d of %d compilations failed (%.1f%%, budget %.1f%%)",
+			ErrBudgetExceeded, r.Failed, r.Compilations, 100*r.FailureRate, 100*r.FailureBudget)
+	}
+	return nil
+}
+
+// WriteReport writes the report of s as JSON to the file at path.
+func (s *Supervisor) WriteReport(path string) error {
+	data, err := json.MarshalIndent(s.Report(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
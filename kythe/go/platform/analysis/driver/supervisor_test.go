@@ -0,0 +1,168 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"kythe.io/kythe/go/platform/analysis"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// flaky is an analyzer whose behavior for each compilation, keyed by
+// signature, is a sequence of errors returned by successive attempts.  After
+// the sequence is exhausted, analysis succeeds.
+type flaky map[string][]error
+
+func (f flaky) Analyze(ctx context.Context, req *apb.AnalysisRequest, _ analysis.OutputFunc) (*apb.AnalysisResult, error) {
+	sig := req.GetCompilation().GetVName().GetSignature()
+	if errs := f[sig]; len(errs) > 0 {
+		f[sig] = errs[1:]
+		if errs[0] == context.DeadlineExceeded {
+			<-ctx.Done()
+			return nil, errors.New("signal: killed")
+		}
+		return nil, errs[0]
+	}
+	return &apb.AnalysisResult{Status: apb.AnalysisResult_COMPLETE}, nil
+}
+
+// units is a Queue of compilations with the given signatures.
+type units []string
+
+func (u *units) Next(ctx context.Context, f CompilationFunc) error {
+	if len(*u) == 0 {
+		return ErrEndOfQueue
+	}
+	sig := (*u)[0]
+	*u = (*u)[1:]
+	return f(ctx, Compilation{
+		Unit:       &apb.CompilationUnit{VName: &spb.VName{Language: "go", Signature: sig}},
+		UnitDigest: "digest-" + sig,
+	})
+}
+
+func TestSupervisor(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "worker went away")
+	a := flaky{
+		"retried":   {unavailable, fmt.Errorf("wrapped: %w", ErrTransient)},
+		"gave up":   {unavailable, unavailable, unavailable},
+		"broken":    {errors.New("syntax error")},
+		"timed out": {context.DeadlineExceeded},
+	}
+	s := &Supervisor{MaxRetries: 2, Backoff: time.Millisecond, FailureBudget: 0.5}
+	d := &Driver{
+		Analyzer:        a,
+		AnalysisOptions: AnalysisOptions{Timeout: 10 * time.Millisecond},
+		Context:         s,
+	}
+	q := &units{"ok", "retried", "gave up", "broken", "timed out", "ok too"}
+	if err := d.Run(context.Background(), q); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	r := s.Report()
+	for _, f := range r.Failures {
+		f.Error = "" // not worth matching exactly
+	}
+	want := &Report{
+		Compilations:  6,
+		Succeeded:     3,
+		Failed:        3,
+		Retries:       4,
+		FailureRate:   0.5,
+		FailureBudget: 0.5,
+		Failures: []*Failure{
+			{Digest: "digest-gave up", Language: "go", Signature: "gave up", Attempts: 3},
+			{Digest: "digest-broken", Language: "go", Signature: "broken", Attempts: 1},
+			{Digest: "digest-timed out", Language: "go", Signature: "timed out", Attempts: 1, Timeout: true},
+		},
+	}
+	if diff := cmp.Diff(want, r); diff != "" {
+		t.Errorf("Report (-want +got):\n%s", diff)
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("Err: got %v, want nil within budget", err)
+	}
+
+	s.FailureBudget = 0.1
+	if err := s.Err(); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Err: got %v, want %v", err, ErrBudgetExceeded)
+	}
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := s.WriteReport(path); err != nil {
+		t.Fatalf("WriteReport: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Report
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Decoding report: %v", err)
+	} else if !got.BudgetExceeded || len(got.Failures) != 3 {
+		t.Errorf("Decoded report: got %+v, want budget exceeded with 3 failures", got)
+	}
+}
+
+func TestSupervisorFailFast(t *testing.T) {
+	broken := errors.New("syntax error")
+	d := &Driver{
+		Analyzer: flaky{"broken": {broken}},
+		Context:  &Supervisor{FailFast: true, FailureBudget: 1},
+	}
+	q := &units{"ok", "broken", "never"}
+	if err := d.Run(context.Background(), q); err != broken {
+		t.Errorf("Run: got %v, want %v", err, broken)
+	}
+	if len(*q) != 1 {
+		t.Errorf("Run continued after failure: %d compilations left, want 1", len(*q))
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("nope"), false},
+		{ErrTransient, true},
+		{fmt.Errorf("x: %w", ErrTransient), true},
+		{status.Error(codes.ResourceExhausted, "busy"), true},
+		{status.Error(codes.InvalidArgument, "bad"), false},
+		{context.DeadlineExceeded, false},
+	}
+	for _, test := range tests {
+		if got := IsTransient(test.err); got != test.want {
+			t.Errorf("IsTransient(%v): got %v, want %v", test.err, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package provenance records where and how compilations were built, and
+// attaches that record to the file nodes produced by analyzing them.
+//
+// Extractors attach a Provenance to each compilation unit they write, as a
+// google.protobuf.Struct detail keyed by fact name.  An Analyzer wraps the
+// analyzer for a driver, and emits the provenance of each compilation (or a
+// default) as facts on every file node in its output, where they are served
+// with the other facts of the node:
+//
+//	/kythe/provenance/commit      -- the VCS revision built
+//	/kythe/provenance/build_time  -- when the build ran (RFC 3339)
+//	/kythe/provenance/toolchain   -- the compiler or toolchain version
+//
+// An Analyzer may also stamp every node in its output with its Origin, so that
+// bad data can be traced back to the compilation that produced it:
+//
+//	/kythe/provenance/unit        -- the ticket of the compilation unit
+//	/kythe/provenance/invocation  -- the indexer invocation that analyzed it
+//
+// A Stamper stamps the nodes of any entry stream with a given Origin, e.g. as
+// it is written to a GraphStore.
+package provenance // import "kythe.io/kythe/go/platform/analysis/provenance"
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"kythe.io/kythe/go/platform/analysis"
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/ptypes"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// Environment variables read by FromEnv.
+const (
+	CommitEnv    = "KYTHE_PROVENANCE_COMMIT"
+	BuildTimeEnv = "KYTHE_PROVENANCE_BUILD_TIME"
+	ToolchainEnv = "KYTHE_PROVENANCE_TOOLCHAIN"
+
+	// InvocationEnv identifies the indexer invocation for origins.
+	InvocationEnv = "KYTHE_PROVENANCE_INVOCATION"
+)
+
+// factPrefix is the common prefix of the provenance fact names.
+const factPrefix = "/kythe/provenance/"
+
+// A Provenance records the origin of a compilation.  Empty fields are unknown.
+type Provenance struct {
+	Commit    string // the VCS revision built
+	BuildTime string // when the build ran, in RFC 3339 format
+	Toolchain string // the compiler or toolchain version
+}
+
+// FromEnv returns the provenance given by the KYTHE_PROVENANCE_* environment
+// variables, or nil if none is set.
+func FromEnv() *Provenance {
+	p := &Provenance{
+		Commit:    os.Getenv(CommitEnv),
+		BuildTime: os.Getenv(BuildTimeEnv),
+		Toolchain: os.Getenv(ToolchainEnv),
+	}
+	if p.empty() {
+		return nil
+	}
+	return p
+}
+
+func (p *Provenance) empty() bool { return p == nil || *p == Provenance{} }
+
+// facts returns the fact names and values of the known fields of p.
+func (p *Provenance) facts() [][2]string {
+	var fs [][2]string
+	for _, f := range [][2]string{
+		{facts.ProvCommit, p.Commit},
+		{facts.ProvBuildTime, p.BuildTime},
+		{facts.ProvToolchain, p.Toolchain},
+	} {
+		if f[1] != "" {
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
+
+// merge returns a copy of p with its unknown fields filled from q.
+func (p *Provenance) merge(q *Provenance) *Provenance {
+	var out Provenance
+	if p != nil {
+		out = *p
+	}
+	if q != nil {
+		if out.Commit == "" {
+			out.Commit = q.Commit
+		}
+		if out.BuildTime == "" {
+			out.BuildTime = q.BuildTime
+		}
+		if out.Toolchain == "" {
+			out.Toolchain = q.Toolchain
+		}
+	}
+	return &out
+}
+
+// Entries returns the provenance facts of p for the node v.
+func (p *Provenance) Entries(v *spb.VName) []*spb.Entry {
+	var es []*spb.Entry
+	for _, f := range p.facts() {
+		es = append(es, &spb.Entry{Source: v, FactName: f[0], FactValue: []byte(f[1])})
+	}
+	return es
+}
+
+// Attach records p in the details of cu, replacing any provenance already
+// recorded.  Attach does nothing if p has no known fields.
+func Attach(cu *apb.CompilationUnit, p *Provenance) error {
+	if p.empty() {
+		return nil
+	}
+	fields := make(map[string]any)
+	for _, f := range p.facts() {
+		fields[f[0]] = f[1]
+	}
+	st, err := structpb.NewStruct(fields)
+	if err != nil {
+		return err
+	}
+	detail, err := ptypes.MarshalAny(st)
+	if err != nil {
+		return fmt.Errorf("marshaling provenance: %v", err)
+	}
+	_, cu.Details = split(cu.GetDetails())
+	cu.Details = append(cu.Details, detail)
+	return nil
+}
+
+// FromUnit returns the provenance recorded in the details of cu, or nil.
+func FromUnit(cu *apb.CompilationUnit) *Provenance {
+	p, _ := split(cu.GetDetails())
+	return p
+}
+
+// split separates the provenance from the other details.
+func split(details []*ptypes.Any) (*Provenance, []*ptypes.Any) {
+	var p *Provenance
+	var rest []*ptypes.Any
+	for _, detail := range details {
+		var st structpb.Struct
+		if !strings.HasSuffix(detail.GetTypeUrl(), "/google.protobuf.Struct") || ptypes.UnmarshalAny(detail, &st) != nil || !isProvenance(&st) {
+			rest = append(rest, detail)
+			continue
+		}
+		p = &Provenance{
+			Commit:    st.Fields[facts.ProvCommit].GetStringValue(),
+			BuildTime: st.Fields[facts.ProvBuildTime].GetStringValue(),
+			Toolchain: st.Fields[facts.ProvToolchain].GetStringValue(),
+		}
+	}
+	return p, rest
+}
+
+// isProvenance reports whether st is a provenance detail, whose fields are
+// all provenance facts.
+func isProvenance(st *structpb.Struct) bool {
+	for name := range st.GetFields() {
+		if !strings.HasPrefix(name, factPrefix) {
+			return false
+		}
+	}
+	return len(st.GetFields()) > 0
+}
+
+// An Origin identifies what produced a node: the compilation unit whose
+// analysis emitted it, and the indexer invocation that ran the analysis.
+// Empty fields are unknown.
+//
+// A node emitted by several compilations records the origin of whichever was
+// written last.
+type Origin struct {
+	Unit       string // the ticket of the compilation unit
+	Invocation string // an identifier of the indexer invocation
+}
+
+func (o *Origin) empty() bool { return o == nil || *o == Origin{} }
+
+// OriginOf returns the origin recorded in the facts of a node, or nil.
+func OriginOf(fs map[string][]byte) *Origin {
+	o := &Origin{
+		Unit:       string(fs[facts.ProvUnit]),
+		Invocation: string(fs[facts.ProvInvocation]),
+	}
+	if o.empty() {
+		return nil
+	}
+	return o
+}
+
+// Entries returns the origin facts of o for the node v.
+func (o *Origin) Entries(v *spb.VName) []*spb.Entry {
+	var es []*spb.Entry
+	for _, f := range [][2]string{
+		{facts.ProvUnit, o.Unit},
+		{facts.ProvInvocation, o.Invocation},
+	} {
+		if f[1] != "" {
+			es = append(es, &spb.Entry{Source: v, FactName: f[0], FactValue: []byte(f[1])})
+		}
+	}
+	return es
+}
+
+// maxStamped bounds the number of nodes a Stamper remembers having stamped.
+const maxStamped = 1 << 16
+
+// A Stamper stamps each node of an entry stream with an origin.  It remembers
+// the nodes it has stamped recently, and stamps a node again only once it has
+// been forgotten; the repeated facts are identical, so they are harmless.
+type Stamper struct {
+	origin *Origin
+	seen   map[string]bool
+}
+
+// NewStamper returns a Stamper for the origin o.
+func NewStamper(o *Origin) *Stamper {
+	return &Stamper{origin: o, seen: make(map[string]bool)}
+}
+
+// Stamp returns the origin facts for the source of e, or nil if it was
+// recently stamped.
+func (s *Stamper) Stamp(e *spb.Entry) []*spb.Entry {
+	if s.origin.empty() {
+		return nil
+	}
+	v := e.GetSource()
+	key := strings.Join([]string{v.GetSignature(), v.GetCorpus(), v.GetRoot(), v.GetPath(), v.GetLanguage()}, "\x00")
+	if s.seen[key] {
+		return nil
+	}
+	if len(s.seen) >= maxStamped {
+		clear(s.seen)
+	}
+	s.seen[key] = true
+	return s.origin.Entries(v)
+}
+
+// Entries returns a channel of the entries of in, in order, each followed by
+// the origin facts of its source if Stamp returns any.
+func (s *Stamper) Entries(in <-chan *spb.Entry) <-chan *spb.Entry {
+	out := make(chan *spb.Entry)
+	go func() {
+		defer close(out)
+		for e := range in {
+			out <- e
+			for _, oe := range s.Stamp(e) {
+				out <- oe
+			}
+		}
+	}()
+	return out
+}
+
+// An Analyzer is an analysis.CompilationAnalyzer that emits the provenance
+// of each compilation as facts on the file nodes in the output of an
+// underlying analyzer.  The output is expected to consist of serialized
+// kythe.proto.storage.Entry messages.
+//
+// The provenance of a compilation is the one recorded in its details, with
+// unknown fields filled from the default.  The provenance detail is removed
+// from the compilation passed to the underlying analyzer, so that a change in
+// provenance alone does not change the identity of the compilation (e.g., for
+// a resultcache.Analyzer).
+type Analyzer struct {
+	analyzer analysis.CompilationAnalyzer
+	defaults *Provenance
+
+	stampOrigins bool
+	invocation   string
+}
+
+// NewAnalyzer returns an Analyzer that wraps a, using defaults (which may be
+// nil) for the provenance that compilations do not record.
+func NewAnalyzer(a analysis.CompilationAnalyzer, defaults *Provenance) *Analyzer {
+	return &Analyzer{analyzer: a, defaults: defaults}
+}
+
+// StampOrigins makes a stamp every node in the output for a compilation with
+// its Origin: the ticket of the compilation unit, and invocation if it is
+// non-empty.  It returns a.
+func (a *Analyzer) StampOrigins(invocation string) *Analyzer {
+	a.stampOrigins = true
+	a.invocation = invocation
+	return a
+}
+
+// Analyze implements the analysis.CompilationAnalyzer interface.
+func (a *Analyzer) Analyze(ctx context.Context, req *apb.AnalysisRequest, f analysis.OutputFunc) (*apb.AnalysisResult, error) {
+	p, rest := split(req.GetCompilation().GetDetails())
+	p = p.merge(a.defaults)
+	var stamper *Stamper
+	if a.stampOrigins {
+		var unit string
+		if v := req.GetCompilation().GetVName(); v != nil {
+			unit = kytheuri.ToString(v)
+		}
+		stamper = NewStamper(&Origin{Unit: unit, Invocation: a.invocation})
+	}
+	if len(rest) != len(req.GetCompilation().GetDetails()) {
+		req = proto.Clone(req).(*apb.AnalysisRequest)
+		req.Compilation.Details = rest
+	}
+	if p.empty() && stamper == nil {
+		return a.analyzer.Analyze(ctx, req, f)
+	}
+
+	return a.analyzer.Analyze(ctx, req, func(ctx context.Context, out *apb.AnalysisOutput) error {
+		if err := f(ctx, out); err != nil {
+			return err
+		}
+		var e spb.Entry
+		if err := proto.Unmarshal(out.Value, &e); err != nil {
+			return nil // not an entry; pass it through unchanged
+		}
+		var added []*spb.Entry
+		if !p.empty() && e.GetFactName() == facts.NodeKind && string(e.GetFactValue()) == nodes.File {
+			added = p.Entries(e.Source)
+		}
+		if stamper != nil {
+			added = append(added, stamper.Stamp(&e)...)
+		}
+		for _, pe := range added {
+			rec, err := proto.Marshal(pe)
+			if err != nil {
+				return err
+			}
+			if err := f(ctx, &apb.AnalysisOutput{Value: rec}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
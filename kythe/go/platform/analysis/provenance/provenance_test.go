@@ -0,0 +1,218 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package provenance
+
+import (
+	"context"
+	"testing"
+
+	"kythe.io/kythe/go/platform/analysis"
+	"kythe.io/kythe/go/util/ptypes"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	bipb "kythe.io/kythe/proto/buildinfo_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestAttach(t *testing.T) {
+	build, err := ptypes.MarshalAny(&bipb.BuildDetails{BuildTarget: "//:a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cu := &apb.CompilationUnit{Details: []*ptypes.Any{build}}
+	if got := FromUnit(cu); got != nil {
+		t.Errorf("FromUnit before Attach: got %+v, want nil", got)
+	}
+
+	// Attaching again replaces the earlier provenance, and leaves the other
+	// details alone.
+	for _, p := range []*Provenance{
+		{Commit: "abc123", Toolchain: "go1.0"},
+		{Commit: "def456", BuildTime: "2026-01-02T03:04:05Z"},
+	} {
+		if err := Attach(cu, p); err != nil {
+			t.Fatalf("Attach(%+v): %v", p, err)
+		}
+		if diff := cmp.Diff(p, FromUnit(cu)); diff != "" {
+			t.Errorf("FromUnit (-want +got):\n%s", diff)
+		}
+	}
+	if n := len(cu.Details); n != 2 {
+		t.Errorf("Unit has %d details, want 2", n)
+	}
+	if err := Attach(cu, nil); err != nil || len(cu.Details) != 2 {
+		t.Errorf("Attach(nil): got %v with %d details, want no change", err, len(cu.Details))
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(CommitEnv, "")
+	t.Setenv(BuildTimeEnv, "")
+	t.Setenv(ToolchainEnv, "")
+	if p := FromEnv(); p != nil {
+		t.Errorf("FromEnv with nothing set: got %+v, want nil", p)
+	}
+	t.Setenv(CommitEnv, "abc123")
+	if diff := cmp.Diff(&Provenance{Commit: "abc123"}, FromEnv()); diff != "" {
+		t.Errorf("FromEnv (-want +got):\n%s", diff)
+	}
+}
+
+// fakeAnalyzer emits a file node and an anchor, and records the details of
+// the compilation it was given.
+type fakeAnalyzer struct{ details []*ptypes.Any }
+
+func (f *fakeAnalyzer) Analyze(ctx context.Context, req *apb.AnalysisRequest, out analysis.OutputFunc) (*apb.AnalysisResult, error) {
+	f.details = req.GetCompilation().GetDetails()
+	for _, e := range []*spb.Entry{
+		{Source: &spb.VName{Path: "a.go"}, FactName: facts.NodeKind, FactValue: []byte(nodes.File)},
+		{Source: &spb.VName{Signature: "@0:1"}, FactName: facts.NodeKind, FactValue: []byte(nodes.Anchor)},
+	} {
+		rec, err := proto.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		if err := out(ctx, &apb.AnalysisOutput{Value: rec}); err != nil {
+			return nil, err
+		}
+	}
+	return &apb.AnalysisResult{Status: apb.AnalysisResult_COMPLETE}, nil
+}
+
+func TestAnalyzer(t *testing.T) {
+	cu := new(apb.CompilationUnit)
+	if err := Attach(cu, &Provenance{Commit: "abc123"}); err != nil {
+		t.Fatal(err)
+	}
+	inner := new(fakeAnalyzer)
+	a := NewAnalyzer(inner, &Provenance{Commit: "ignored", Toolchain: "go1.0"})
+
+	var got []string
+	_, err := a.Analyze(context.Background(), &apb.AnalysisRequest{Compilation: cu}, func(_ context.Context, out *apb.AnalysisOutput) error {
+		var e spb.Entry
+		if err := proto.Unmarshal(out.Value, &e); err != nil {
+			return err
+		}
+		got = append(got, e.Source.GetPath()+" "+e.FactName+"="+string(e.FactValue))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+
+	want := []string{
+		"a.go /kythe/node/kind=file",
+		"a.go /kythe/provenance/commit=abc123",
+		"a.go /kythe/provenance/toolchain=go1.0",
+		" /kythe/node/kind=anchor",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Outputs (-want +got):\n%s", diff)
+	}
+	if len(inner.details) != 0 {
+		t.Errorf("Underlying analyzer got details %v, want provenance removed", inner.details)
+	}
+	if len(cu.Details) != 1 {
+		t.Error("Analyze modified the compilation of its request")
+	}
+}
+
+func TestAnalyzerOrigins(t *testing.T) {
+	cu := &apb.CompilationUnit{VName: &spb.VName{Corpus: "c", Signature: "unit", Language: "go"}}
+	a := NewAnalyzer(new(fakeAnalyzer), nil).StampOrigins("run1")
+
+	var got []string
+	_, err := a.Analyze(context.Background(), &apb.AnalysisRequest{Compilation: cu}, func(_ context.Context, out *apb.AnalysisOutput) error {
+		var e spb.Entry
+		if err := proto.Unmarshal(out.Value, &e); err != nil {
+			return err
+		}
+		got = append(got, e.Source.GetPath()+e.Source.GetSignature()+" "+e.FactName+"="+string(e.FactValue))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+
+	want := []string{
+		"a.go /kythe/node/kind=file",
+		"a.go /kythe/provenance/unit=kythe://c?lang=go#unit",
+		"a.go /kythe/provenance/invocation=run1",
+		"@0:1 /kythe/node/kind=anchor",
+		"@0:1 /kythe/provenance/unit=kythe://c?lang=go#unit",
+		"@0:1 /kythe/provenance/invocation=run1",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Outputs (-want +got):\n%s", diff)
+	}
+}
+
+func TestStamper(t *testing.T) {
+	a := &spb.VName{Path: "a.go"}
+	b := &spb.VName{Path: "b.go"}
+	in := make(chan *spb.Entry)
+	go func() {
+		defer close(in)
+		for _, e := range []*spb.Entry{
+			{Source: a, FactName: facts.NodeKind, FactValue: []byte(nodes.File)},
+			{Source: a, FactName: facts.Text, FactValue: []byte("package a")},
+			{Source: b, FactName: facts.NodeKind, FactValue: []byte(nodes.File)},
+			{Source: a, EdgeKind: "/kythe/edge/childof", Target: b},
+		} {
+			in <- e
+		}
+	}()
+
+	var got []string
+	for e := range NewStamper(&Origin{Unit: "kythe://c#unit"}).Entries(in) {
+		got = append(got, e.Source.GetPath()+" "+e.FactName+e.EdgeKind)
+	}
+	want := []string{
+		"a.go /kythe/node/kind",
+		"a.go /kythe/provenance/unit",
+		"a.go /kythe/text",
+		"b.go /kythe/node/kind",
+		"b.go /kythe/provenance/unit",
+		"a.go /kythe/edge/childof",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Entries (-want +got):\n%s", diff)
+	}
+
+	if es := NewStamper(nil).Stamp(&spb.Entry{Source: a}); es != nil {
+		t.Errorf("Stamp with no origin: got %v, want nil", es)
+	}
+}
+
+func TestOriginOf(t *testing.T) {
+	if o := OriginOf(map[string][]byte{facts.ProvCommit: []byte("abc123")}); o != nil {
+		t.Errorf("OriginOf without origin facts: got %+v, want nil", o)
+	}
+	want := &Origin{Unit: "kythe://c#unit", Invocation: "run1"}
+	fs := make(map[string][]byte)
+	for _, e := range want.Entries(&spb.VName{Path: "a.go"}) {
+		fs[e.FactName] = e.FactValue
+	}
+	if diff := cmp.Diff(want, OriginOf(fs)); diff != "" {
+		t.Errorf("OriginOf (-want +got):\n%s", diff)
+	}
+}
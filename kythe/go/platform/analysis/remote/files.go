@@ -0,0 +1,71 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"context"
+
+	"kythe.io/kythe/go/platform/analysis"
+	"kythe.io/kythe/go/util/log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	aspb "kythe.io/kythe/proto/analysis_service_go_proto"
+)
+
+// A FileServer implements the FileDataService by reading files from a
+// Fetcher.  A file the Fetcher cannot supply is reported as missing.
+type FileServer struct {
+	aspb.UnimplementedFileDataServiceServer
+
+	Fetcher analysis.Fetcher
+}
+
+// GetFileData implements part of the FileDataServiceServer interface.
+func (s *FileServer) GetFileData(ctx context.Context, info *apb.FileInfo) (*apb.FileData, error) {
+	if info.GetPath() == "" && info.GetDigest() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing path and digest")
+	}
+	data, err := s.Fetcher.Fetch(info.GetPath(), info.GetDigest())
+	if err != nil {
+		log.WarningContextf(ctx, "Fetching %q (%s): %v", info.GetPath(), info.GetDigest(), err)
+		return &apb.FileData{Info: info, Missing: true}, nil
+	}
+	return &apb.FileData{Info: info, Content: data}, nil
+}
+
+// Get implements part of the FileDataServiceServer interface.
+func (s *FileServer) Get(req *apb.FilesRequest, stream aspb.FileDataService_GetServer) error {
+	type key struct{ path, digest string }
+	seen := make(map[key]bool)
+	for _, info := range req.GetFiles() {
+		k := key{info.GetPath(), info.GetDigest()}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		fd, err := s.GetFileData(stream.Context(), info)
+		if err != nil {
+			return err
+		} else if err := stream.Send(fd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
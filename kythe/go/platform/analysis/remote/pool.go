@@ -0,0 +1,253 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package remote distributes the analysis of compilations over a pool of
+// worker processes reached by gRPC, so that indexing can scale across
+// machines.
+//
+// A coordinator serves the required inputs of its compilations with a
+// FileServer, and analyzes each compilation with a Pool, which sends it to a
+// worker with spare capacity.  Each worker runs a Worker, which analyzes the
+// compilations it receives with a local analyzer (typically a sandboxed
+// indexer), fetching their inputs back from the coordinator, and streams the
+// outputs in reply.
+//
+// A worker that cannot be reached is taken out of the pool for a cooldown
+// period, and the compilation it was given fails with a transient error, so
+// that a driver.Supervisor can retry it on another worker.
+package remote // import "kythe.io/kythe/go/platform/analysis/remote"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"kythe.io/kythe/go/platform/analysis"
+	"kythe.io/kythe/go/platform/analysis/driver"
+	"kythe.io/kythe/go/util/log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	aspb "kythe.io/kythe/proto/analysis_service_go_proto"
+)
+
+// ErrNoWorkers is returned by Pool.Analyze when the pool has no workers.
+var ErrNoWorkers = errors.New("no workers in pool")
+
+// DefaultCooldown is the cooldown used by a Pool whose Cooldown is zero.
+const DefaultCooldown = 30 * time.Second
+
+// A Pool is an analysis.CompilationAnalyzer that sends each compilation to one
+// of a set of remote workers.  Each worker is given at most its capacity of
+// compilations at once; Analyze blocks until a worker is available.
+type Pool struct {
+	// The address of the FileDataService from which workers should fetch the
+	// required inputs of compilations, for requests that do not specify one.
+	FileDataService string
+
+	// How long a worker that fails to respond is left out of the pool.  If
+	// zero, DefaultCooldown is used.
+	Cooldown time.Duration
+
+	mu      sync.Mutex
+	workers []*member
+	wake    chan struct{} // closed and replaced when a worker may be free
+}
+
+// member is a worker in a Pool.
+type member struct {
+	name      string
+	client    aspb.CompilationAnalyzerClient
+	capacity  int
+	busy      int
+	downUntil time.Time
+
+	analyses, failures int
+}
+
+// WorkerStats record the work done by one worker of a Pool.
+type WorkerStats struct {
+	Name     string // the name the worker was added with
+	Analyses int    // compilations sent to the worker
+	Failures int    // times the worker could not be reached
+	Down     bool   // whether the worker is out of the pool
+}
+
+// Add adds a worker to the pool that may analyze up to capacity compilations
+// at once.  The name identifies the worker in logs and statistics.
+func (p *Pool) Add(name string, client aspb.CompilationAnalyzerClient, capacity int) {
+	if capacity < 1 {
+		capacity = 1
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.workers = append(p.workers, &member{name: name, client: client, capacity: capacity})
+	p.signal()
+}
+
+// Stats returns the statistics of each worker in the pool.
+func (p *Pool) Stats() []WorkerStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	var ws []WorkerStats
+	for _, m := range p.workers {
+		ws = append(ws, WorkerStats{
+			Name:     m.name,
+			Analyses: m.analyses,
+			Failures: m.failures,
+			Down:     now.Before(m.downUntil),
+		})
+	}
+	return ws
+}
+
+// signal wakes any callers waiting for a worker.  The caller must hold p.mu.
+func (p *Pool) signal() {
+	if p.wake != nil {
+		close(p.wake)
+		p.wake = nil
+	}
+}
+
+// acquire waits for a worker with spare capacity, preferring the least busy,
+// and reserves a slot on it.
+func (p *Pool) acquire(ctx context.Context) (*member, error) {
+	for {
+		p.mu.Lock()
+		if len(p.workers) == 0 {
+			p.mu.Unlock()
+			return nil, ErrNoWorkers
+		}
+		now := time.Now()
+		var best *member
+		var nextUp time.Time
+		for _, m := range p.workers {
+			if now.Before(m.downUntil) {
+				if nextUp.IsZero() || m.downUntil.Before(nextUp) {
+					nextUp = m.downUntil
+				}
+				continue
+			}
+			if m.busy < m.capacity && (best == nil || m.busy*best.capacity < best.busy*m.capacity) {
+				best = m
+			}
+		}
+		if best != nil {
+			best.busy++
+			best.analyses++
+			p.mu.Unlock()
+			return best, nil
+		}
+		if p.wake == nil {
+			p.wake = make(chan struct{})
+		}
+		wake := p.wake
+		p.mu.Unlock()
+
+		// Wait for a worker to finish, or to come back from its cooldown.
+		var timer *time.Timer
+		var up <-chan time.Time
+		if !nextUp.IsZero() {
+			timer = time.NewTimer(time.Until(nextUp))
+			up = timer.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-wake:
+		case <-up:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *Pool) cooldown() time.Duration {
+	if p.Cooldown == 0 {
+		return DefaultCooldown
+	}
+	return p.Cooldown
+}
+
+// release returns the slot reserved on m, taking m out of the pool if it
+// failed.
+func (p *Pool) release(m *member, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	m.busy--
+	if failed {
+		m.failures++
+		m.downUntil = time.Now().Add(p.cooldown())
+	}
+	p.signal()
+}
+
+// Analyze implements the analysis.CompilationAnalyzer interface.  If the
+// worker chosen cannot be reached, the error returned wraps
+// driver.ErrTransient.
+func (p *Pool) Analyze(ctx context.Context, req *apb.AnalysisRequest, f analysis.OutputFunc) (*apb.AnalysisResult, error) {
+	m, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := p.analyze(ctx, m, req, f)
+	failed := err != nil && ctx.Err() == nil && status.Code(err) == codes.Unavailable
+	p.release(m, failed)
+	if failed {
+		log.WarningContextf(ctx, "Worker %s is unavailable; leaving it out for %v", m.name, p.cooldown())
+		return nil, fmt.Errorf("worker %s: %w: %v", m.name, driver.ErrTransient, err)
+	}
+	return res, err
+}
+
+func (p *Pool) analyze(ctx context.Context, m *member, req *apb.AnalysisRequest, f analysis.OutputFunc) (*apb.AnalysisResult, error) {
+	if req.GetFileDataService() == "" && p.FileDataService != "" {
+		req = proto.Clone(req).(*apb.AnalysisRequest)
+		req.FileDataService = p.FileDataService
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := m.client.Analyze(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	res := &apb.AnalysisResult{Status: apb.AnalysisResult_COMPLETE}
+	for {
+		out, err := stream.Recv()
+		if err == io.EOF {
+			return res, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if out.FinalResult != nil {
+			res = out.FinalResult
+			continue
+		}
+		if err := f(ctx, out); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,209 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"kythe.io/kythe/go/platform/analysis"
+	"kythe.io/kythe/go/platform/analysis/driver"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	aspb "kythe.io/kythe/proto/analysis_service_go_proto"
+)
+
+// files is an analysis.Fetcher over a map from digest to content.
+type files map[string]string
+
+func (f files) Fetch(_, digest string) ([]byte, error) {
+	if s, ok := f[digest]; ok {
+		return []byte(s), nil
+	}
+	return nil, fmt.Errorf("no file %q", digest)
+}
+
+// echo is an analyzer that emits the contents of each required input, or
+// fails if the compilation has no inputs.
+type echo struct{ f analysis.Fetcher }
+
+func (e echo) Analyze(ctx context.Context, req *apb.AnalysisRequest, out analysis.OutputFunc) (*apb.AnalysisResult, error) {
+	ris := req.GetCompilation().GetRequiredInput()
+	if len(ris) == 0 {
+		return &apb.AnalysisResult{Status: apb.AnalysisResult_INVALID_REQUEST}, errors.New("nothing to do")
+	}
+	for _, ri := range ris {
+		data, err := e.f.Fetch(ri.GetInfo().GetPath(), ri.GetInfo().GetDigest())
+		if err != nil {
+			return nil, err
+		}
+		if err := out(ctx, &apb.AnalysisOutput{Value: data}); err != nil {
+			return nil, err
+		}
+	}
+	return &apb.AnalysisResult{Status: apb.AnalysisResult_COMPLETE}, nil
+}
+
+// serve starts a server on an in-memory listener, and returns a connection to
+// it and a function that stops it.
+func serve(t *testing.T, register func(*grpc.Server)) (*grpc.ClientConn, func()) {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	register(srv)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.Dial("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, srv.Stop
+}
+
+// newPool returns a pool of n workers, each running an echo analyzer that
+// fetches inputs from a file server over the given files, and functions to
+// stop each worker.
+func newPool(t *testing.T, fs files, n int) (*Pool, []func()) {
+	t.Helper()
+	fileLis := bufconn.Listen(1 << 20)
+	fileSrv := grpc.NewServer()
+	aspb.RegisterFileDataServiceServer(fileSrv, &FileServer{Fetcher: fs})
+	go fileSrv.Serve(fileLis)
+	t.Cleanup(fileSrv.Stop)
+
+	p := &Pool{FileDataService: "passthrough:///files", Cooldown: time.Hour}
+	var stops []func()
+	for i := 0; i < n; i++ {
+		w := &Worker{
+			NewAnalyzer: func(f analysis.Fetcher) analysis.CompilationAnalyzer { return echo{f} },
+			DialOptions: []grpc.DialOption{
+				grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return fileLis.DialContext(ctx) }),
+				grpc.WithTransportCredentials(insecure.NewCredentials()),
+			},
+		}
+		t.Cleanup(func() { w.Close() })
+		conn, stop := serve(t, func(s *grpc.Server) { aspb.RegisterCompilationAnalyzerServer(s, w) })
+		p.Add(fmt.Sprintf("worker%d", i), aspb.NewCompilationAnalyzerClient(conn), 1)
+		stops = append(stops, stop)
+	}
+	return p, stops
+}
+
+func request(digests ...string) *apb.AnalysisRequest {
+	cu := new(apb.CompilationUnit)
+	for _, d := range digests {
+		cu.RequiredInput = append(cu.RequiredInput, &apb.CompilationUnit_FileInput{
+			Info: &apb.FileInfo{Path: d + ".go", Digest: d},
+		})
+	}
+	return &apb.AnalysisRequest{Compilation: cu}
+}
+
+func analyze(p *Pool, req *apb.AnalysisRequest) ([]string, *apb.AnalysisResult, error) {
+	var got []string
+	res, err := p.Analyze(context.Background(), req, func(_ context.Context, out *apb.AnalysisOutput) error {
+		got = append(got, string(out.Value))
+		return nil
+	})
+	return got, res, err
+}
+
+func TestPool(t *testing.T) {
+	p, stops := newPool(t, files{"a": "alpha", "b": "beta"}, 2)
+
+	got, res, err := analyze(p, request("a", "b"))
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	} else if res.GetStatus() != apb.AnalysisResult_COMPLETE {
+		t.Errorf("Analyze status: got %v, want COMPLETE", res.GetStatus())
+	}
+	if diff := cmp.Diff([]string{"alpha", "beta"}, got); diff != "" {
+		t.Errorf("Outputs (-want +got):\n%s", diff)
+	}
+
+	// An analysis failure is reported, but is not the fault of the worker.
+	if _, _, err := analyze(p, request()); err == nil || errors.Is(err, driver.ErrTransient) {
+		t.Errorf("Analyze with no inputs: got %v, want a permanent failure", err)
+	}
+	// A missing input is reported by the analyzer.
+	if _, _, err := analyze(p, request("missing")); err == nil {
+		t.Error("Analyze with missing input: got nil error")
+	}
+
+	// A worker that goes away is left out of the pool, and its analysis can
+	// be retried on the other.
+	stops[0]()
+	if _, _, err := analyze(p, request("a")); !errors.Is(err, driver.ErrTransient) || !driver.IsTransient(err) {
+		t.Errorf("Analyze on stopped worker: got %v, want %v", err, driver.ErrTransient)
+	}
+	if got, _, err := analyze(p, request("a")); err != nil || len(got) != 1 {
+		t.Errorf("Analyze on remaining worker: got %v, %v; want 1 output", got, err)
+	}
+	want := []WorkerStats{
+		{Name: "worker0", Analyses: 4, Failures: 1, Down: true},
+		{Name: "worker1", Analyses: 1},
+	}
+	if diff := cmp.Diff(want, p.Stats()); diff != "" {
+		t.Errorf("Stats (-want +got):\n%s", diff)
+	}
+
+	// With every worker down, Analyze waits until cancelled.
+	stops[1]()
+	analyze(p, request("a"))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.Analyze(ctx, request("a"), nil); err != context.DeadlineExceeded {
+		t.Errorf("Analyze with no workers up: got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestPoolEmpty(t *testing.T) {
+	if _, err := new(Pool).Analyze(context.Background(), request(), nil); err != ErrNoWorkers {
+		t.Errorf("Analyze: got %v, want %v", err, ErrNoWorkers)
+	}
+}
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{errors.New("bad"), codes.Unknown},
+		{fmt.Errorf("flaky: %w", driver.ErrTransient), codes.Unavailable},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{status.Error(codes.NotFound, "gone"), codes.NotFound},
+	}
+	for _, test := range tests {
+		if got := status.Code(toStatus(test.err)); got != test.want {
+			t.Errorf("toStatus(%v): got %v, want %v", test.err, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"kythe.io/kythe/go/platform/analysis"
+	"kythe.io/kythe/go/platform/analysis/driver"
+	"kythe.io/kythe/go/util/log"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	aspb "kythe.io/kythe/proto/analysis_service_go_proto"
+)
+
+// A Worker implements the CompilationAnalyzer service by analyzing each
+// compilation it receives with a local analyzer.  The required inputs of each
+// compilation are fetched from the FileDataService named in its request.
+//
+// The result of each analysis is sent as the final output of the stream.  If
+// the analysis fails, the RPC fails with the error of the analyzer, coded as
+// Unavailable if the error wraps driver.ErrTransient.
+type Worker struct {
+	aspb.UnimplementedCompilationAnalyzerServer
+
+	// NewAnalyzer returns an analyzer that fetches required inputs from f.
+	NewAnalyzer func(f analysis.Fetcher) analysis.CompilationAnalyzer
+
+	// Options used to connect to file data services.  If empty, connections
+	// are made without transport security.
+	DialOptions []grpc.DialOption
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+// Analyze implements the CompilationAnalyzerServer interface.
+func (w *Worker) Analyze(req *apb.AnalysisRequest, stream aspb.CompilationAnalyzer_AnalyzeServer) error {
+	ctx := stream.Context()
+	if req.GetFileDataService() == "" && len(req.GetCompilation().GetRequiredInput()) > 0 {
+		return status.Error(codes.InvalidArgument, "no file data service for required inputs")
+	}
+	files, err := w.files(req.GetFileDataService())
+	if err != nil {
+		return status.Errorf(codes.Internal, "connecting to file data service: %v", err)
+	}
+	a := w.NewAnalyzer(&Fetcher{Context: ctx, Client: files})
+	res, err := a.Analyze(ctx, req, func(_ context.Context, out *apb.AnalysisOutput) error {
+		return stream.Send(out)
+	})
+	if res != nil {
+		if serr := stream.Send(&apb.AnalysisOutput{FinalResult: res}); serr != nil && err == nil {
+			err = serr
+		}
+	}
+	if err != nil {
+		log.WarningContextf(ctx, "Analysis of %v failed: %v", req.GetCompilation().GetVName(), err)
+		return toStatus(err)
+	}
+	return nil
+}
+
+// files returns a client for the file data service at addr, reusing an
+// existing connection where possible.
+func (w *Worker) files(addr string) (aspb.FileDataServiceClient, error) {
+	if addr == "" {
+		return nil, nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if conn, ok := w.conns[addr]; ok {
+		return aspb.NewFileDataServiceClient(conn), nil
+	}
+	opts := w.DialOptions
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	conn, err := grpc.Dial(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if w.conns == nil {
+		w.conns = make(map[string]*grpc.ClientConn)
+	}
+	w.conns[addr] = conn
+	return aspb.NewFileDataServiceClient(conn), nil
+}
+
+// Close closes the connections of w to file data services.
+func (w *Worker) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var errs []error
+	for addr, conn := range w.conns {
+		errs = append(errs, conn.Close())
+		delete(w.conns, addr)
+	}
+	return errors.Join(errs...)
+}
+
+// toStatus converts an analysis error to a gRPC status error.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	} else if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	} else if errors.Is(err, driver.ErrTransient) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Unknown, err.Error())
+}
+
+// A Fetcher is an analysis.Fetcher that reads files from a FileDataService.
+type Fetcher struct {
+	Context context.Context
+	Client  aspb.FileDataServiceClient
+}
+
+// Fetch implements the analysis.Fetcher interface.
+func (f *Fetcher) Fetch(path, digest string) ([]byte, error) {
+	if f.Client == nil {
+		return nil, errors.New("no file data service")
+	}
+	fd, err := f.Client.GetFileData(f.Context, &apb.FileInfo{Path: path, Digest: digest})
+	if err != nil {
+		return nil, err
+	} else if fd.GetMissing() {
+		return nil, fmt.Errorf("file %q (%s) not found", path, digest)
+	}
+	return fd.GetContent(), nil
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package resultcache implements a CompilationAnalyzer that reuses the stored
+// outputs of earlier analyses of identical compilations.
+//
+// Compilations are keyed by their canonical digest, which covers the digests
+// of all their required inputs, so a compilation whose inputs are unchanged
+// is not analyzed again.  Only analyses that complete successfully are
+// stored.
+package resultcache // import "kythe.io/kythe/go/platform/analysis/resultcache"
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"kythe.io/kythe/go/platform/analysis"
+	"kythe.io/kythe/go/platform/delimited"
+	"kythe.io/kythe/go/platform/kcd/kythe"
+	"kythe.io/kythe/go/util/log"
+
+	"google.golang.org/protobuf/proto"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+)
+
+// ErrNotFound is returned by a Store when a key is not present.
+var ErrNotFound = errors.New("not found in cache")
+
+// A Store holds the outputs of analyses by key.
+type Store interface {
+	// Get returns the stored outputs for key, or ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Put stores the outputs for key, replacing any existing value.
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// DirStore is a Store that keeps each value in a file in a directory.
+type DirStore string
+
+func (d DirStore) path(key string) string {
+	return filepath.Join(string(d), key[:2], key)
+}
+
+// Get implements a method of the Store interface.
+func (d DirStore) Get(_ context.Context, key string) ([]byte, error) {
+	data, err := os.ReadFile(d.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Put implements a method of the Store interface.  The value is written to a
+// temporary file and renamed into place, so concurrent readers never observe
+// a partial value.
+func (d DirStore) Put(_ context.Context, key string, data []byte) error {
+	path := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-"+key)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Stats record the effectiveness of an Analyzer.
+type Stats struct {
+	Hits   int64 // analyses answered from the store
+	Misses int64 // analyses delegated to the underlying analyzer
+	Stored int64 // results written to the store
+	Errors int64 // store operations that failed
+}
+
+// HitRate returns the fraction of analyses answered from the store.
+func (s Stats) HitRate() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+	return 0
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%d hits, %d misses (%.1f%% hit rate), %d stored, %d errors",
+		s.Hits, s.Misses, 100*s.HitRate(), s.Stored, s.Errors)
+}
+
+// An Analyzer is an analysis.CompilationAnalyzer that answers from a Store
+// when it can, and otherwise delegates to an underlying analyzer and stores
+// its outputs.  Errors reading or writing the store are logged and counted,
+// but do not cause analysis to fail.
+type Analyzer struct {
+	analyzer analysis.CompilationAnalyzer
+	store    Store
+	version  string
+
+	hits, misses, stored, errors int64
+}
+
+// New returns an Analyzer that caches the outputs of a in store.  The version
+// string is mixed into every key; it should identify the underlying analyzer
+// and its configuration, so that a change to either invalidates old results.
+func New(a analysis.CompilationAnalyzer, store Store, version string) *Analyzer {
+	return &Analyzer{analyzer: a, store: store, version: version}
+}
+
+// Key returns the store key for the given compilation.
+func (a *Analyzer) Key(unit *apb.CompilationUnit) string {
+	u := kythe.Unit{Proto: proto.Clone(unit).(*apb.CompilationUnit)}
+	u.Canonicalize()
+	sum := sha256.Sum256([]byte(a.version + "\x00" + u.Digest()))
+	return hex.EncodeToString(sum[:])
+}
+
+// Stats returns a snapshot of the statistics for a.
+func (a *Analyzer) Stats() Stats {
+	return Stats{
+		Hits:   atomic.LoadInt64(&a.hits),
+		Misses: atomic.LoadInt64(&a.misses),
+		Stored: atomic.LoadInt64(&a.stored),
+		Errors: atomic.LoadInt64(&a.errors),
+	}
+}
+
+// Analyze implements the analysis.CompilationAnalyzer interface.
+func (a *Analyzer) Analyze(ctx context.Context, req *apb.AnalysisRequest, f analysis.OutputFunc) (*apb.AnalysisResult, error) {
+	key := a.Key(req.GetCompilation())
+	data, err := a.store.Get(ctx, key)
+	if err == nil {
+		atomic.AddInt64(&a.hits, 1)
+		return &apb.AnalysisResult{Status: apb.AnalysisResult_COMPLETE}, replay(ctx, data, f)
+	} else if err != ErrNotFound {
+		atomic.AddInt64(&a.errors, 1)
+		log.WarningContextf(ctx, "Reading cached result %s: %v", key, err)
+	}
+	atomic.AddInt64(&a.misses, 1)
+
+	var buf bytes.Buffer
+	w := delimited.NewWriter(&buf)
+	res, err := a.analyzer.Analyze(ctx, req, func(ctx context.Context, out *apb.AnalysisOutput) error {
+		if err := w.Put(out.Value); err != nil {
+			return err
+		}
+		return f(ctx, out)
+	})
+	if err != nil || res.GetStatus() != apb.AnalysisResult_COMPLETE {
+		return res, err
+	}
+	if err := a.store.Put(ctx, key, buf.Bytes()); err != nil {
+		atomic.AddInt64(&a.errors, 1)
+		log.WarningContextf(ctx, "Storing result %s: %v", key, err)
+	} else {
+		atomic.AddInt64(&a.stored, 1)
+	}
+	return res, nil
+}
+
+// replay delivers each of the delimited outputs in data to f.
+func replay(ctx context.Context, data []byte, f analysis.OutputFunc) error {
+	rd := delimited.NewReader(bytes.NewReader(data))
+	for {
+		rec, err := rd.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("reading cached result: %v", err)
+		}
+		out := &apb.AnalysisOutput{Value: append([]byte(nil), rec...)}
+		if err := f(ctx, out); err != nil {
+			return err
+		}
+	}
+}
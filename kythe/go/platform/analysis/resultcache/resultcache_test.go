@@ -0,0 +1,134 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resultcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"kythe.io/kythe/go/platform/analysis"
+
+	"github.com/google/go-cmp/cmp"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// fakeAnalyzer emits the source files of each compilation as outputs.
+type fakeAnalyzer struct {
+	calls int
+	fail  bool
+}
+
+func (f *fakeAnalyzer) Analyze(ctx context.Context, req *apb.AnalysisRequest, out analysis.OutputFunc) (*apb.AnalysisResult, error) {
+	f.calls++
+	for _, src := range req.GetCompilation().GetSourceFile() {
+		if err := out(ctx, &apb.AnalysisOutput{Value: []byte(src)}); err != nil {
+			return nil, err
+		}
+	}
+	if f.fail {
+		return &apb.AnalysisResult{Status: apb.AnalysisResult_INCOMPLETE}, errors.New("failed")
+	}
+	return &apb.AnalysisResult{Status: apb.AnalysisResult_COMPLETE}, nil
+}
+
+func request(digest string, sources ...string) *apb.AnalysisRequest {
+	return &apb.AnalysisRequest{Compilation: &apb.CompilationUnit{
+		VName:      &spb.VName{Signature: "unit", Language: "go"},
+		SourceFile: sources,
+		RequiredInput: []*apb.CompilationUnit_FileInput{{
+			Info: &apb.FileInfo{Path: "a.go", Digest: digest},
+		}},
+	}}
+}
+
+func analyze(t *testing.T, a analysis.CompilationAnalyzer, req *apb.AnalysisRequest) ([]string, error) {
+	t.Helper()
+	var got []string
+	_, err := a.Analyze(context.Background(), req, func(_ context.Context, out *apb.AnalysisOutput) error {
+		got = append(got, string(out.Value))
+		return nil
+	})
+	return got, err
+}
+
+func TestAnalyzer(t *testing.T) {
+	inner := new(fakeAnalyzer)
+	a := New(inner, DirStore(t.TempDir()), "v1")
+	want := []string{"a.go", "b.go"}
+
+	// The first analysis is a miss, the second a hit that replays the same
+	// outputs without calling the underlying analyzer.
+	for i := 0; i < 2; i++ {
+		got, err := analyze(t, a, request("d1", "a.go", "b.go"))
+		if err != nil {
+			t.Fatalf("Analyze #%d: %v", i+1, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Analyze #%d outputs (-want +got):\n%s", i+1, diff)
+		}
+	}
+	if inner.calls != 1 {
+		t.Errorf("Underlying analyzer called %d times, want 1", inner.calls)
+	}
+
+	// A change in an input digest is a miss.
+	if _, err := analyze(t, a, request("d2", "a.go", "b.go")); err != nil {
+		t.Fatal(err)
+	}
+	// A change of version is a miss, even for the same store.
+	if _, err := analyze(t, New(inner, a.store, "v2"), request("d1", "a.go", "b.go")); err != nil {
+		t.Fatal(err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("Underlying analyzer called %d times, want 3", inner.calls)
+	}
+
+	if diff := cmp.Diff(Stats{Hits: 1, Misses: 2, Stored: 2}, a.Stats()); diff != "" {
+		t.Errorf("Stats (-want +got):\n%s", diff)
+	}
+}
+
+func TestAnalyzerFailure(t *testing.T) {
+	inner := &fakeAnalyzer{fail: true}
+	a := New(inner, DirStore(t.TempDir()), "")
+	for i := 0; i < 2; i++ {
+		if _, err := analyze(t, a, request("d", "a.go")); err == nil {
+			t.Fatalf("Analyze #%d: got nil error, want failure", i+1)
+		}
+	}
+	if inner.calls != 2 {
+		t.Errorf("Failed analysis was cached: %d calls, want 2", inner.calls)
+	}
+	if got := a.Stats(); got.Stored != 0 || got.Hits != 0 {
+		t.Errorf("Stats: got %+v, want no hits or stored results", got)
+	}
+}
+
+func TestKeyCanonical(t *testing.T) {
+	a := New(nil, nil, "v")
+	r1 := request("d", "a.go", "b.go")
+	r2 := request("d", "b.go", "a.go")
+	if k1, k2 := a.Key(r1.Compilation), a.Key(r2.Compilation); k1 != k2 {
+		t.Errorf("Keys differ for equivalent compilations: %q, %q", k1, k2)
+	}
+	if got := r2.Compilation.SourceFile; got[0] != "b.go" {
+		t.Errorf("Key modified its argument: %v", got)
+	}
+}
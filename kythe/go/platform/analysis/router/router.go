@@ -0,0 +1,230 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package router implements a CompilationAnalyzer that sends each compilation
+// to one of several analyzers according to its language and corpus, with a
+// separate limit on the number of concurrent analyses for each language.
+//
+// A Router is usually built from a JSON configuration file naming an indexer
+// command for each language, for example:
+//
+//	{
+//	  "indexers": [
+//	    {"language": "java", "command": "java",
+//	     "args": ["-jar", "/opt/kythe/indexers/java_indexer.jar"],
+//	     "memory": "4GiB"},
+//	    {"language": "c++", "corpus": "chromium.*",
+//	     "command": "/opt/kythe/indexers/cxx_indexer",
+//	     "args": ["--experimental_drop_instantiation_independent_data"]},
+//	    {"language": "c++", "command": "/opt/kythe/indexers/cxx_indexer"},
+//	    {"language": "go", "command": "/opt/kythe/indexers/go_indexer",
+//	     "cpu_time": "5m"}
+//	  ],
+//	  "concurrency": {"java": 2, "c++": 8}
+//	}
+//
+// Each indexer is run in a sandbox (see package sandbox).  Indexers are tried
+// in order, and the first whose language and corpus match a compilation is
+// used.
+package router // import "kythe.io/kythe/go/platform/analysis/router"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"kythe.io/kythe/go/platform/analysis"
+	"kythe.io/kythe/go/platform/analysis/sandbox"
+	"kythe.io/kythe/go/util/datasize"
+
+	"golang.org/x/sync/semaphore"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+)
+
+// ErrNoRoute is returned by Analyze for a compilation that matches no route.
+var ErrNoRoute = errors.New("no analyzer for compilation")
+
+// A Route sends the compilations it matches to an analyzer.
+type Route struct {
+	// If set, the language a compilation must have to match.
+	Language string
+
+	// If non-nil, the corpus of a matching compilation must match this
+	// expression.
+	Corpus *regexp.Regexp
+
+	Analyzer analysis.CompilationAnalyzer
+}
+
+// Matches reports whether r accepts the given compilation.
+func (r *Route) Matches(unit *apb.CompilationUnit) bool {
+	if r.Language != "" && r.Language != unit.GetVName().GetLanguage() {
+		return false
+	}
+	if r.Corpus != nil {
+		return r.Corpus.MatchString(unit.GetVName().GetCorpus())
+	}
+	return true
+}
+
+// A Router is an analysis.CompilationAnalyzer that delegates each compilation
+// to the analyzer of the first matching route.  It is safe for concurrent use
+// if the analyzers of its routes are.
+type Router struct {
+	routes []*Route
+	limits map[string]*semaphore.Weighted
+}
+
+// New returns a Router for the given routes.  The concurrency map gives, for
+// each language in it, the maximum number of compilations of that language to
+// analyze at once; languages not in the map are not limited.
+func New(routes []*Route, concurrency map[string]int) *Router {
+	r := &Router{routes: routes, limits: make(map[string]*semaphore.Weighted)}
+	for lang, n := range concurrency {
+		if n > 0 {
+			r.limits[lang] = semaphore.NewWeighted(int64(n))
+		}
+	}
+	return r
+}
+
+// Lookup returns the first route matching unit, or nil.
+func (r *Router) Lookup(unit *apb.CompilationUnit) *Route {
+	for _, route := range r.routes {
+		if route.Matches(unit) {
+			return route
+		}
+	}
+	return nil
+}
+
+// Analyze implements the analysis.CompilationAnalyzer interface.  If the
+// language of the compilation is at its concurrency limit, Analyze blocks
+// until another analysis of that language finishes or ctx ends.
+func (r *Router) Analyze(ctx context.Context, req *apb.AnalysisRequest, f analysis.OutputFunc) (*apb.AnalysisResult, error) {
+	unit := req.GetCompilation()
+	route := r.Lookup(unit)
+	if route == nil {
+		return nil, fmt.Errorf("%w (language %q, corpus %q)", ErrNoRoute,
+			unit.GetVName().GetLanguage(), unit.GetVName().GetCorpus())
+	}
+	if sem := r.limits[unit.GetVName().GetLanguage()]; sem != nil {
+		if err := sem.Acquire(ctx, 1); err != nil {
+			return nil, err
+		}
+		defer sem.Release(1)
+	}
+	return route.Analyzer.Analyze(ctx, req, f)
+}
+
+// Config is the JSON encoding of a set of indexer routes.
+type Config struct {
+	Indexers []*IndexerConfig `json:"indexers"`
+
+	// The maximum number of concurrent compilations for each language.
+	Concurrency map[string]int `json:"concurrency,omitempty"`
+}
+
+// IndexerConfig describes an indexer command and the compilations it handles.
+type IndexerConfig struct {
+	Language string `json:"language,omitempty"` // empty matches any language
+	Corpus   string `json:"corpus,omitempty"`   // a regular expression for the whole corpus; empty matches any
+
+	Command string   `json:"command"`        // the indexer binary
+	Args    []string `json:"args,omitempty"` // arguments preceding the .kzip path
+
+	// Resource limits for each compilation, as durations ("10m") and sizes
+	// ("4GiB").  Empty means no limit.
+	CPUTime   string `json:"cpu_time,omitempty"`
+	Memory    string `json:"memory,omitempty"`
+	MaxOutput string `json:"max_output,omitempty"`
+}
+
+// LoadConfig reads a JSON Config from the file at path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", path, err)
+	}
+	return &c, nil
+}
+
+// Options are the settings shared by all the indexers of a Config.
+type Options struct {
+	// Fetcher supplies the contents of the required inputs of each compilation.
+	Fetcher analysis.Fetcher
+
+	// If set, the standard error of each indexer run is saved in this
+	// directory (see sandbox.Analyzer).
+	LogDir string
+}
+
+// Router returns a Router that runs the indexers of c in sandboxes.
+func (c *Config) Router(opts *Options) (*Router, error) {
+	var routes []*Route
+	for i, ic := range c.Indexers {
+		route, err := ic.route(opts)
+		if err != nil {
+			return nil, fmt.Errorf("indexer %d (%s): %v", i, ic.Language, err)
+		}
+		routes = append(routes, route)
+	}
+	return New(routes, c.Concurrency), nil
+}
+
+func (ic *IndexerConfig) route(opts *Options) (*Route, error) {
+	if ic.Command == "" {
+		return nil, errors.New("missing command")
+	}
+	a := &sandbox.Analyzer{
+		Command: ic.Command,
+		Args:    ic.Args,
+		Fetcher: opts.Fetcher,
+		LogDir:  opts.LogDir,
+	}
+	var err error
+	if ic.CPUTime != "" {
+		if a.Limits.CPUTime, err = time.ParseDuration(ic.CPUTime); err != nil {
+			return nil, fmt.Errorf("invalid cpu_time: %v", err)
+		}
+	}
+	if ic.Memory != "" {
+		if a.Limits.Memory, err = datasize.Parse(ic.Memory); err != nil {
+			return nil, fmt.Errorf("invalid memory: %v", err)
+		}
+	}
+	if ic.MaxOutput != "" {
+		if a.Limits.Output, err = datasize.Parse(ic.MaxOutput); err != nil {
+			return nil, fmt.Errorf("invalid max_output: %v", err)
+		}
+	}
+	route := &Route{Language: ic.Language, Analyzer: a}
+	if ic.Corpus != "" {
+		if route.Corpus, err = regexp.Compile("^(?:" + ic.Corpus + ")$"); err != nil {
+			return nil, fmt.Errorf("invalid corpus: %v", err)
+		}
+	}
+	return route, nil
+}
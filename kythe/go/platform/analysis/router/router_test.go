@@ -0,0 +1,187 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kythe.io/kythe/go/platform/analysis"
+	"kythe.io/kythe/go/platform/analysis/sandbox"
+
+	"github.com/google/go-cmp/cmp"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// nameAnalyzer emits its name for each compilation, and records the peak
+// number of concurrent analyses.
+type nameAnalyzer struct {
+	name         string
+	delay        time.Duration
+	active, peak int32
+}
+
+func (n *nameAnalyzer) Analyze(ctx context.Context, req *apb.AnalysisRequest, f analysis.OutputFunc) (*apb.AnalysisResult, error) {
+	cur := atomic.AddInt32(&n.active, 1)
+	defer atomic.AddInt32(&n.active, -1)
+	for {
+		peak := atomic.LoadInt32(&n.peak)
+		if cur <= peak || atomic.CompareAndSwapInt32(&n.peak, peak, cur) {
+			break
+		}
+	}
+	time.Sleep(n.delay)
+	if err := f(ctx, &apb.AnalysisOutput{Value: []byte(n.name)}); err != nil {
+		return nil, err
+	}
+	return &apb.AnalysisResult{Status: apb.AnalysisResult_COMPLETE}, nil
+}
+
+func request(lang, corpus string) *apb.AnalysisRequest {
+	return &apb.AnalysisRequest{Compilation: &apb.CompilationUnit{
+		VName: &spb.VName{Language: lang, Corpus: corpus},
+	}}
+}
+
+func analyze(r *Router, req *apb.AnalysisRequest) (string, error) {
+	var got string
+	_, err := r.Analyze(context.Background(), req, func(_ context.Context, out *apb.AnalysisOutput) error {
+		got = string(out.Value)
+		return nil
+	})
+	return got, err
+}
+
+func TestRouting(t *testing.T) {
+	r := New([]*Route{
+		{Language: "c++", Corpus: regexp.MustCompile("^chromium$"), Analyzer: &nameAnalyzer{name: "chromium"}},
+		{Language: "c++", Analyzer: &nameAnalyzer{name: "cxx"}},
+		{Language: "java", Analyzer: &nameAnalyzer{name: "java"}},
+	}, nil)
+	tests := []struct {
+		lang, corpus, want string
+	}{
+		{"c++", "chromium", "chromium"},
+		{"c++", "chromiumos", "cxx"},
+		{"c++", "", "cxx"},
+		{"java", "chromium", "java"},
+	}
+	for _, test := range tests {
+		got, err := analyze(r, request(test.lang, test.corpus))
+		if err != nil {
+			t.Errorf("Analyze(%q, %q): %v", test.lang, test.corpus, err)
+		} else if got != test.want {
+			t.Errorf("Analyze(%q, %q): routed to %q, want %q", test.lang, test.corpus, got, test.want)
+		}
+	}
+
+	if _, err := analyze(r, request("go", "")); !errors.Is(err, ErrNoRoute) {
+		t.Errorf("Analyze(go): got error %v, want %v", err, ErrNoRoute)
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	java := &nameAnalyzer{name: "java", delay: 20 * time.Millisecond}
+	cxx := &nameAnalyzer{name: "cxx", delay: 20 * time.Millisecond}
+	r := New([]*Route{
+		{Language: "java", Analyzer: java},
+		{Language: "c++", Analyzer: cxx},
+	}, map[string]int{"java": 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		for _, lang := range []string{"java", "c++"} {
+			wg.Add(1)
+			go func(lang string) {
+				defer wg.Done()
+				if _, err := analyze(r, request(lang, "")); err != nil {
+					t.Errorf("Analyze(%q): %v", lang, err)
+				}
+			}(lang)
+		}
+	}
+	wg.Wait()
+	if java.peak > 2 {
+		t.Errorf("Peak concurrent java analyses: got %d, want at most 2", java.peak)
+	}
+	if cxx.peak < 3 {
+		t.Errorf("Peak concurrent c++ analyses: got %d, want unlimited", cxx.peak)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{
+  "indexers": [
+    {"language": "java", "command": "java", "args": ["-jar", "indexer.jar"], "memory": "4GiB"},
+    {"language": "c++", "corpus": "chromium", "command": "cxx_indexer", "cpu_time": "10m"}
+  ],
+  "concurrency": {"java": 2}
+}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	r, err := c.Router(&Options{LogDir: "logs"})
+	if err != nil {
+		t.Fatalf("Router: %v", err)
+	}
+
+	java := r.Lookup(request("java", "").Compilation)
+	if java == nil {
+		t.Fatal("No route for java")
+	}
+	if diff := cmp.Diff(&sandbox.Analyzer{
+		Command: "java",
+		Args:    []string{"-jar", "indexer.jar"},
+		Limits:  sandbox.Limits{Memory: 4 << 30},
+		LogDir:  "logs",
+	}, java.Analyzer); diff != "" {
+		t.Errorf("Java analyzer (-want +got):\n%s", diff)
+	}
+	if r.Lookup(request("c++", "chromium").Compilation) == nil {
+		t.Error("No route for c++ in chromium")
+	}
+	if r.Lookup(request("c++", "chromiumos").Compilation) != nil {
+		t.Error("Corpus pattern matched a prefix of the corpus")
+	}
+	if r.limits["java"] == nil || r.limits["c++"] != nil {
+		t.Errorf("Concurrency limits: got %v, want java only", r.limits)
+	}
+
+	for _, bad := range []*IndexerConfig{
+		{Language: "go"},
+		{Command: "x", Corpus: "("},
+		{Command: "x", CPUTime: "forever"},
+		{Command: "x", Memory: "lots"},
+	} {
+		if _, err := (&Config{Indexers: []*IndexerConfig{bad}}).Router(new(Options)); err == nil {
+			t.Errorf("Router(%+v): got nil error", bad)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package sandbox implements a CompilationAnalyzer that runs an indexer binary
+// as a separate process for each compilation, in a private working directory
+// and subject to resource limits, so that a single pathological compilation
+// fails on its own rather than taking down the process that drives analysis.
+//
+// The indexer is invoked with the path of a .kzip file holding the compilation
+// and its required inputs as its final argument, and must write a delimited
+// stream of kythe.proto.Entry messages to standard output.
+//
+// The sandbox does not isolate the filesystem: the indexer runs as the same
+// user as its caller and can read and write anything that user can.  Only its
+// working directory and TMPDIR point into the private directory, which is
+// removed after the run, and its output is kept outside that directory.
+package sandbox // import "kythe.io/kythe/go/platform/analysis/sandbox"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"kythe.io/kythe/go/platform/analysis"
+	"kythe.io/kythe/go/platform/delimited"
+	"kythe.io/kythe/go/platform/kzip"
+	"kythe.io/kythe/go/util/datasize"
+	"kythe.io/kythe/go/util/kytheuri"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+)
+
+// ErrOutputLimit is reported when an indexer writes more output than its
+// Limits permit.
+var ErrOutputLimit = errors.New("indexer output limit exceeded")
+
+// Limits bound the resources available to the indexer for one compilation.
+// A zero value for any field means that resource is not limited.
+type Limits struct {
+	CPUTime time.Duration // processor time, enforced by the kernel
+	Memory  datasize.Size // address space, enforced by the kernel
+	Output  datasize.Size // total bytes written to standard output
+}
+
+// ulimits returns the shell commands to apply the kernel-enforced limits.
+func (l Limits) ulimits() string {
+	var cmds string
+	if l.CPUTime > 0 {
+		secs := int64((l.CPUTime + time.Second - 1) / time.Second)
+		cmds += "ulimit -t " + strconv.FormatInt(secs, 10) + " && "
+	}
+	if l.Memory > 0 {
+		kib := (l.Memory.Bytes() + 1023) / 1024
+		cmds += "ulimit -v " + strconv.FormatUint(kib, 10) + " && "
+	}
+	return cmds
+}
+
+// An Analyzer implements analysis.CompilationAnalyzer by running an indexer
+// binary in a sandbox for each compilation.
+type Analyzer struct {
+	Command string   // the indexer binary to run
+	Args    []string // arguments preceding the .kzip path
+
+	// Fetcher supplies the contents of the required inputs of each compilation.
+	Fetcher analysis.Fetcher
+
+	Limits Limits
+
+	// If set, the standard error of each run is written to a file in this
+	// directory named by the digest of the compilation, with extension
+	// ".stderr".  Otherwise, it is discarded except for the summary of a
+	// failed run.
+	LogDir string
+
+	// The directory in which to create the private working directories.  If
+	// empty, the default directory for temporary files is used.
+	TempDir string
+}
+
+// stderrSummaryLen is the number of trailing bytes of standard error included
+// in the error for a failed run.
+const stderrSummaryLen = 1024
+
+// Analyze implements the analysis.CompilationAnalyzer interface.  Entries are
+// delivered to f only once the indexer has exited successfully, so that a
+// failed run contributes no partial output.
+func (a *Analyzer) Analyze(ctx context.Context, req *apb.AnalysisRequest, f analysis.OutputFunc) (*apb.AnalysisResult, error) {
+	unit := req.GetCompilation()
+	if unit == nil {
+		return &apb.AnalysisResult{
+			Status:  apb.AnalysisResult_INVALID_REQUEST,
+			Summary: "missing compilation",
+		}, nil
+	}
+
+	// Resolve the command before changing directories, so relative paths work.
+	command, err := exec.LookPath(a.Command)
+	if err != nil {
+		return nil, fmt.Errorf("finding indexer: %v", err)
+	}
+	if command, err = filepath.Abs(command); err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(a.TempDir, "sandbox")
+	if err != nil {
+		return nil, fmt.Errorf("creating sandbox: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The indexer's working directory holds only the compilation; its output is
+	// kept outside, where the indexer is not pointed.
+	work := filepath.Join(dir, "work")
+	if err := os.Mkdir(work, 0700); err != nil {
+		return nil, fmt.Errorf("creating sandbox: %v", err)
+	}
+	kzipPath := filepath.Join(work, "unit.kzip")
+	digest, err := a.writeUnit(kzipPath, unit)
+	if err != nil {
+		return nil, fmt.Errorf("writing compilation: %v", err)
+	}
+
+	out, err := os.Create(filepath.Join(dir, "output.entries"))
+	if err != nil {
+		return nil, err
+	}
+	defer out.Close()
+
+	stderr := &tailBuffer{max: stderrSummaryLen}
+	var logFile *os.File
+	if a.LogDir != "" {
+		logFile, err = os.Create(filepath.Join(a.LogDir, digest+".stderr"))
+		if err != nil {
+			return nil, fmt.Errorf("creating log: %v", err)
+		}
+		defer logFile.Close()
+	}
+
+	// The kernel-enforced limits are applied by the shell before it replaces
+	// itself with the indexer, so that they do not apply to this process.
+	args := append([]string{"-c", a.Limits.ulimits() + `exec "$0" "$@"`, command}, a.Args...)
+	cmd := exec.CommandContext(ctx, "/bin/sh", append(args, kzipPath)...)
+	cmd.Dir = work
+	cmd.Env = append(os.Environ(), "TMPDIR="+work)
+	cmd.Stdout = &limitWriter{w: out, n: int64(a.Limits.Output), cancel: func() {
+		if cmd.Process != nil {
+			cmd.Process.Kill()
+		}
+	}}
+	if logFile != nil {
+		cmd.Stderr = io.MultiWriter(stderr, logFile)
+	} else {
+		cmd.Stderr = stderr
+	}
+
+	runErr := cmd.Run()
+	if lw := cmd.Stdout.(*limitWriter); lw.exceeded {
+		runErr = fmt.Errorf("%w (%v)", ErrOutputLimit, a.Limits.Output)
+	}
+	if runErr != nil {
+		return &apb.AnalysisResult{
+			Status:  apb.AnalysisResult_INCOMPLETE,
+			Summary: stderr.String(),
+		}, fmt.Errorf("indexing %s: %w", unitName(unit, digest), runErr)
+	}
+
+	if _, err := out.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	rd := delimited.NewReader(out)
+	for {
+		rec, err := rd.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("reading indexer output: %v", err)
+		}
+		if err := f(ctx, &apb.AnalysisOutput{Value: rec}); err != nil {
+			return nil, err
+		}
+	}
+	return &apb.AnalysisResult{Status: apb.AnalysisResult_COMPLETE}, nil
+}
+
+// writeUnit writes unit and its required inputs as a .kzip file at path, and
+// returns the digest of the unit.
+func (a *Analyzer) writeUnit(path string, unit *apb.CompilationUnit) (string, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	w, err := kzip.NewWriteCloser(f)
+	if err != nil {
+		f.Close()
+		return "", err
+	}
+	for _, ri := range unit.GetRequiredInput() {
+		info := ri.GetInfo()
+		if a.Fetcher == nil {
+			w.Close()
+			return "", errors.New("no fetcher for required inputs")
+		}
+		data, err := a.Fetcher.Fetch(info.GetPath(), info.GetDigest())
+		if err != nil {
+			w.Close()
+			return "", fmt.Errorf("fetching %q: %v", info.GetPath(), err)
+		}
+		if _, err := w.AddFile(bytes.NewReader(data)); err != nil {
+			w.Close()
+			return "", err
+		}
+	}
+	digest, err := w.AddUnit(unit, nil)
+	if err != nil {
+		w.Close()
+		return "", err
+	}
+	return digest, w.Close()
+}
+
+func unitName(unit *apb.CompilationUnit, digest string) string {
+	if v := unit.GetVName(); v.GetSignature() != "" || v.GetPath() != "" {
+		return kytheuri.FromVName(v).String()
+	}
+	return "unit " + digest
+}
+
+// A limitWriter passes writes to w until n bytes have been written (n ≤ 0
+// means no limit), then calls cancel and fails all further writes.
+type limitWriter struct {
+	w        io.Writer
+	n        int64
+	written  int64
+	exceeded bool
+	cancel   func()
+}
+
+func (l *limitWriter) Write(data []byte) (int, error) {
+	if l.exceeded {
+		return 0, ErrOutputLimit
+	}
+	if l.n > 0 && l.written+int64(len(data)) > l.n {
+		l.exceeded = true
+		l.cancel()
+		return 0, ErrOutputLimit
+	}
+	nw, err := l.w.Write(data)
+	l.written += int64(nw)
+	return nw, err
+}
+
+// A tailBuffer retains the last max bytes written to it.
+type tailBuffer struct {
+	max int
+	buf []byte
+}
+
+func (t *tailBuffer) Write(data []byte) (int, error) {
+	t.buf = append(t.buf, data...)
+	if over := len(t.buf) - t.max; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+	return len(data), nil
+}
+
+func (t *tailBuffer) String() string { return string(t.buf) }
@@ -0,0 +1,169 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+type fakeFetcher map[string]string
+
+func (f fakeFetcher) Fetch(path, _ string) ([]byte, error) {
+	if s, ok := f[path]; ok {
+		return []byte(s), nil
+	}
+	return nil, os.ErrNotExist
+}
+
+// script writes a shell script with the given body and returns its path.
+func script(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "indexer.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testRequest(t *testing.T) *apb.AnalysisRequest {
+	t.Helper()
+	return &apb.AnalysisRequest{
+		Compilation: &apb.CompilationUnit{
+			VName:      &spb.VName{Corpus: "test", Signature: "main", Language: "c++"},
+			SourceFile: []string{"main.cc"},
+			RequiredInput: []*apb.CompilationUnit_FileInput{{
+				VName: &spb.VName{Corpus: "test", Path: "main.cc"},
+				Info:  &apb.FileInfo{Path: "main.cc"},
+			}},
+		},
+	}
+}
+
+func (a *Analyzer) run(t *testing.T) ([]string, *apb.AnalysisResult, error) {
+	t.Helper()
+	if a.Fetcher == nil {
+		a.Fetcher = fakeFetcher{"main.cc": "int main() {}"}
+	}
+	var got []string
+	res, err := a.Analyze(context.Background(), testRequest(t), func(_ context.Context, out *apb.AnalysisOutput) error {
+		got = append(got, string(out.Value))
+		return nil
+	})
+	return got, res, err
+}
+
+func TestAnalyze(t *testing.T) {
+	logDir := t.TempDir()
+	// Each "entry" is a single-byte length prefix followed by its data.  The
+	// script checks it was given a readable kzip, and runs in a fresh
+	// directory containing nothing else.
+	a := &Analyzer{
+		Command: script(t, `test -s "$2" || exit 1
+test "$(ls)" = unit.kzip || exit 2
+printf '\003abc\002de'
+echo "indexing $1" >&2`),
+		Args:   []string{"--flag"},
+		LogDir: logDir,
+	}
+	got, res, err := a.run(t)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if res.GetStatus() != apb.AnalysisResult_COMPLETE {
+		t.Errorf("Status: got %v, want COMPLETE", res.GetStatus())
+	}
+	if diff := cmp.Diff([]string{"abc", "de"}, got); diff != "" {
+		t.Errorf("Outputs (-want +got):\n%s", diff)
+	}
+
+	logs, err := filepath.Glob(filepath.Join(logDir, "*.stderr"))
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("Log files: got %v, %v; want 1 file", logs, err)
+	}
+	if data, err := os.ReadFile(logs[0]); err != nil {
+		t.Error(err)
+	} else if got := string(data); got != "indexing --flag\n" {
+		t.Errorf("Log: got %q, want %q", got, "indexing --flag\n")
+	}
+}
+
+func TestAnalyzeFailure(t *testing.T) {
+	a := &Analyzer{Command: script(t, `printf '\003abc'; echo "bad unit" >&2; exit 3`)}
+	got, res, err := a.run(t)
+	if err == nil {
+		t.Fatal("Analyze: got nil error, want failure")
+	}
+	if len(got) != 0 {
+		t.Errorf("Outputs from failed run: got %q, want none", got)
+	}
+	if res.GetStatus() != apb.AnalysisResult_INCOMPLETE || res.GetSummary() != "bad unit\n" {
+		t.Errorf("Result: got %v", res)
+	}
+}
+
+func TestAnalyzeOutputLimit(t *testing.T) {
+	a := &Analyzer{
+		Command: script(t, `while :; do printf '\003abc'; done`),
+		Limits:  Limits{Output: 100},
+	}
+	if _, _, err := a.run(t); !errors.Is(err, ErrOutputLimit) {
+		t.Errorf("Analyze: got error %v, want %v", err, ErrOutputLimit)
+	}
+}
+
+func TestAnalyzeCPULimit(t *testing.T) {
+	a := &Analyzer{
+		Command: script(t, `while :; do :; done`),
+		Fetcher: fakeFetcher{"main.cc": ""},
+		Limits:  Limits{CPUTime: time.Second},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	res, err := a.Analyze(ctx, testRequest(t), func(context.Context, *apb.AnalysisOutput) error { return nil })
+	if err == nil || res.GetStatus() != apb.AnalysisResult_INCOMPLETE {
+		t.Fatalf("Analyze: got %v, %v; want failed run", res, err)
+	}
+	if ctx.Err() != nil {
+		t.Errorf("CPU limit was not enforced: %v", err)
+	}
+}
+
+func TestLimitsUlimits(t *testing.T) {
+	tests := []struct {
+		limits Limits
+		want   string
+	}{
+		{Limits{}, ""},
+		{Limits{CPUTime: 1500 * time.Millisecond}, "ulimit -t 2 && "},
+		{Limits{Memory: 1 << 20, Output: 5}, "ulimit -v 1024 && "},
+	}
+	for _, test := range tests {
+		if got := test.limits.ulimits(); got != test.want {
+			t.Errorf("%+v.ulimits(): got %q, want %q", test.limits, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+/*
+ * Copyright 2014 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package delimited
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultBlockSize is the default size of the blocks compressed by a
+// CompressingWriter.
+const DefaultBlockSize = 1 << 20
+
+// NoCompression is the CompressionOptions Level that stores blocks without
+// compressing them.  It stands in for gzip.NoCompression, which is zero and
+// so selects the default level.
+const NoCompression = gzip.HuffmanOnly - 1
+
+// CompressionOptions control the behavior of a CompressingWriter.
+type CompressionOptions struct {
+	// BlockSize is the approximate number of uncompressed bytes in each
+	// compressed block.  If zero, DefaultBlockSize is used.
+	BlockSize int
+
+	// Concurrency is the maximum number of blocks compressed at once.  If
+	// zero, runtime.GOMAXPROCS(0) is used.
+	Concurrency int
+
+	// Level is the gzip compression level.  If zero, gzip.DefaultCompression
+	// is used; use NoCompression rather than gzip.NoCompression to disable
+	// compression.
+	Level int
+}
+
+// A CompressingWriter writes delimited records to an io.Writer as a gzip
+// stream.  Records are batched into blocks which are compressed concurrently
+// as separate gzip members and written to the output in order.  The result
+// may be read with a gzip.Reader (which handles multiple members by default)
+// wrapped in a delimited Reader.
+//
+// Records written with Put are never split across blocks.  Errors writing to
+// the underlying io.Writer are reported by a subsequent call to Put or Close.
+type CompressingWriter struct {
+	w     io.Writer
+	opts  CompressionOptions
+	block []byte
+	sent  bool // whether any block has been sent
+
+	queue chan *compressBlock // blocks in output order
+	work  chan *compressBlock // blocks to be compressed
+	done  chan struct{}       // closed when all blocks have been written
+
+	mu  sync.Mutex
+	err error
+}
+
+type compressBlock struct {
+	data  []byte
+	out   bytes.Buffer
+	err   error
+	ready chan struct{} // closed once out is complete
+}
+
+// NewCompressingWriter constructs a CompressingWriter that writes to w.  The
+// caller must call Close to flush the remaining records.  If opts == nil,
+// default options are used.
+func NewCompressingWriter(w io.Writer, opts *CompressionOptions) (*CompressingWriter, error) {
+	var o CompressionOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.BlockSize <= 0 {
+		o.BlockSize = DefaultBlockSize
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = runtime.GOMAXPROCS(0)
+	}
+	switch o.Level {
+	case 0:
+		o.Level = gzip.DefaultCompression
+	case NoCompression:
+		o.Level = gzip.NoCompression
+	}
+	if _, err := gzip.NewWriterLevel(io.Discard, o.Level); err != nil {
+		return nil, err
+	}
+
+	cw := &CompressingWriter{
+		w:     w,
+		opts:  o,
+		queue: make(chan *compressBlock, o.Concurrency),
+		work:  make(chan *compressBlock, o.Concurrency),
+		done:  make(chan struct{}),
+	}
+	for i := 0; i < o.Concurrency; i++ {
+		go cw.compress()
+	}
+	go cw.write()
+	return cw, nil
+}
+
+// Put writes the specified record to the writer.
+func (w *CompressingWriter) Put(record []byte) error {
+	if err := w.error(); err != nil {
+		return err
+	}
+	w.block = binary.AppendUvarint(w.block, uint64(len(record)))
+	w.block = append(w.block, record...)
+	if len(w.block) >= w.opts.BlockSize {
+		w.flush()
+	}
+	return nil
+}
+
+// PutProto encodes and writes the specified proto.Message to the writer.
+func (w *CompressingWriter) PutProto(msg proto.Message) error {
+	rec, err := proto.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("error encoding proto: %v", err)
+	}
+	return w.Put(rec)
+}
+
+// Write implements io.Writer, appending raw bytes to the uncompressed stream.
+// This allows w to be used beneath other writers of delimited records.
+func (w *CompressingWriter) Write(data []byte) (int, error) {
+	if err := w.error(); err != nil {
+		return 0, err
+	}
+	w.block = append(w.block, data...)
+	if len(w.block) >= w.opts.BlockSize {
+		w.flush()
+	}
+	return len(data), nil
+}
+
+var errClosed = errors.New("delimited: write to closed CompressingWriter")
+
+// Close flushes any buffered records and waits for all blocks to be written.
+// It does not close the underlying io.Writer.
+func (w *CompressingWriter) Close() error {
+	if w.queue == nil {
+		return errClosed
+	}
+	if len(w.block) > 0 || !w.sent {
+		w.flush()
+	}
+	close(w.queue)
+	close(w.work)
+	<-w.done
+	w.queue = nil
+	err := w.error()
+	w.setError(errClosed)
+	return err
+}
+
+// flush sends the current block to be compressed.
+func (w *CompressingWriter) flush() {
+	b := &compressBlock{data: w.block, ready: make(chan struct{})}
+	w.block = make([]byte, 0, w.opts.BlockSize)
+	w.sent = true
+	w.queue <- b
+	w.work <- b
+}
+
+func (w *CompressingWriter) compress() {
+	var zw *gzip.Writer
+	for b := range w.work {
+		if zw == nil {
+			zw, _ = gzip.NewWriterLevel(&b.out, w.opts.Level) // level checked by constructor
+		} else {
+			zw.Reset(&b.out)
+		}
+		if _, err := zw.Write(b.data); err != nil {
+			b.err = err
+		} else {
+			b.err = zw.Close()
+		}
+		b.data = nil
+		close(b.ready)
+	}
+}
+
+func (w *CompressingWriter) write() {
+	defer close(w.done)
+	for b := range w.queue {
+		<-b.ready
+		if w.error() != nil {
+			continue
+		}
+		err := b.err
+		if err == nil {
+			_, err = w.w.Write(b.out.Bytes())
+		}
+		if err != nil {
+			w.setError(err)
+		}
+	}
+}
+
+func (w *CompressingWriter) error() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+func (w *CompressingWriter) setError(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+}
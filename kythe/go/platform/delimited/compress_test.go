@@ -0,0 +1,130 @@
+/*
+ * Copyright 2014 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package delimited
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestCompressingWriter(t *testing.T) {
+	var want []string
+	for i := 0; i < 1000; i++ {
+		want = append(want, fmt.Sprintf("record %d", i))
+	}
+
+	var buf bytes.Buffer
+	wr, err := NewCompressingWriter(&buf, &CompressionOptions{BlockSize: 100, Concurrency: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range want {
+		if err := wr.Put([]byte(rec)); err != nil {
+			t.Fatalf("Put %q: unexpected error: %v", rec, err)
+		}
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatalf("Close: unexpected error: %v", err)
+	}
+	if err := wr.Put([]byte("late")); err == nil {
+		t.Error("Put after Close: got nil error")
+	}
+
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	rd := NewReader(zr)
+	for {
+		rec, err := rd.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Next: unexpected error: %v", err)
+		}
+		got = append(got, string(rec))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Round trip: got %d records, want %d", len(got), len(want))
+	}
+}
+
+func TestCompressingWriterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	wr, err := NewCompressingWriter(&buf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("Empty output is not valid gzip: %v", err)
+	}
+	if data, err := io.ReadAll(zr); err != nil || len(data) != 0 {
+		t.Errorf("ReadAll: got %q, %v; want empty", data, err)
+	}
+}
+
+func TestCompressingWriterError(t *testing.T) {
+	bad := errors.New("FAIL")
+	wr, err := NewCompressingWriter(&errWriter{err: bad}, &CompressionOptions{BlockSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if err := wr.Put([]byte("x")); err != nil {
+			break
+		}
+	}
+	if err := wr.Close(); err != bad {
+		t.Errorf("Close: got error %v, want %v", err, bad)
+	}
+}
+
+func TestCompressingWriterNoCompression(t *testing.T) {
+	rec := bytes.Repeat([]byte("abc"), 100)
+	var buf bytes.Buffer
+	wr, err := NewCompressingWriter(&buf, &CompressionOptions{Level: NoCompression})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Put(rec); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Stored blocks keep the records as written.
+	if !bytes.Contains(buf.Bytes(), rec) {
+		t.Errorf("Output with NoCompression does not contain the record: %q", buf.Bytes())
+	}
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := NewReader(zr).Next(); err != nil || !bytes.Equal(got, rec) {
+		t.Errorf("Next: got %q, %v; want %q", got, err, rec)
+	}
+}
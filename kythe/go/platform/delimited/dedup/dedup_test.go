@@ -0,0 +1,80 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dedup
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"kythe.io/kythe/go/platform/delimited"
+	"kythe.io/kythe/go/test/entrygen"
+
+	"google.golang.org/protobuf/proto"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestReaderProperties(t *testing.T) {
+	entrygen.Run(t, 20, nil, func(t *testing.T, g *entrygen.Generator) {
+		entries := g.Duplicate(g.Graph())
+		var buf bytes.Buffer
+		w := delimited.NewWriter(&buf)
+		var want []*spb.Entry
+		seen := make(map[string]bool)
+		for _, e := range entries {
+			rec, err := proto.Marshal(e)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Put(rec); err != nil {
+				t.Fatal(err)
+			}
+			if !seen[string(rec)] {
+				seen[string(rec)] = true
+				want = append(want, e)
+			}
+		}
+
+		// Each distinct entry is read once, in the order first written.
+		rd, err := NewReader(&buf, 1<<20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []*spb.Entry
+		for {
+			var e spb.Entry
+			if err := rd.NextProto(&e); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, &e)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("Read %d entries, want %d", len(got), len(want))
+		}
+		for i := range got {
+			if !proto.Equal(got[i], want[i]) {
+				t.Errorf("Entry %d: got {%v}, want {%v}", i, got[i], want[i])
+			}
+		}
+		if skipped := rd.Skipped(); skipped != uint64(len(entries)-len(want)) {
+			t.Errorf("Skipped %d entries, want %d", skipped, len(entries)-len(want))
+		}
+	})
+}
@@ -24,9 +24,11 @@ package delimited // import "kythe.io/kythe/go/platform/delimited"
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 
 	"google.golang.org/protobuf/proto"
 )
@@ -46,29 +48,77 @@ import (
 //	  doStuffWith(rec)
 //	}
 type Reader struct {
-	buf  *bufio.Reader
-	data []byte
+	buf      *bufio.Reader
+	data     []byte
+	zeroCopy bool // set by NewZeroCopyReader
+
+	recovery *recovery // set by NewRecoveringReader
 }
 
 // Next returns the next length-delimited record from the input, or io.EOF if
 // there are no more records available.  Returns io.ErrUnexpectedEOF if a short
 // record is found, with a length of n but fewer than n bytes of data.  Because
 // there is no resynchronization mechanism, it is generally not possible to
-// recover from a short record in this format.
+// recover from a short record in this format; see NewRecoveringReader.
 //
 // The slice returned is valid only until a subsequent call to Next.
 func (r *Reader) Next() ([]byte, error) {
+	if r.recovery != nil {
+		return r.nextRecovering()
+	}
 	size, err := binary.ReadUvarint(r.buf)
 	if err != nil {
 		return nil, err
 	}
+	if r.zeroCopy && size <= uint64(r.buf.Size()) {
+		return r.nextBuffered(int(size))
+	}
+	if size > maxPreallocSize && uint64(cap(r.data)) < size {
+		return r.nextUnsized(size)
+	}
 	if cap(r.data) < int(size) {
 		r.data = make([]byte, size)
 	} else {
 		r.data = r.data[:size]
 	}
 
-	if _, err := io.ReadFull(r.buf, r.data); err != nil {
+	if _, err := io.ReadFull(r.buf, r.data); err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+	return r.data, nil
+}
+
+// nextBuffered returns the next size bytes of the input as a slice of the
+// internal buffer of r, which must be at least size bytes long.
+func (r *Reader) nextBuffered(size int) ([]byte, error) {
+	rec, err := r.buf.Peek(size)
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+	r.buf.Discard(size)
+	// Limit the capacity so that appending to the record cannot overwrite the
+	// input that follows it.
+	return rec[:size:size], nil
+}
+
+// maxPreallocSize is the largest record for which Next allocates space before
+// reading its data.
+const maxPreallocSize = 1 << 20
+
+// nextUnsized returns the next size bytes of the input, growing the record
+// buffer only as its data are read so that a corrupt size cannot exhaust
+// memory.
+func (r *Reader) nextUnsized(size uint64) ([]byte, error) {
+	buf := bytes.NewBuffer(r.data[:0])
+	_, err := io.CopyN(buf, r.buf, int64(min(size, math.MaxInt64)))
+	r.data = buf.Bytes()
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
 		return nil, err
 	}
 	return r.data, nil
@@ -87,6 +137,15 @@ func (r *Reader) NextProto(pb proto.Message) error {
 // NewReader constructs a new delimited Reader for the records in r.
 func NewReader(r io.Reader) *Reader { return &Reader{buf: bufio.NewReader(r)} }
 
+// NewZeroCopyReader constructs a new delimited Reader for the records in r
+// that avoids copying records out of its input buffer, which holds bufSize
+// bytes.  Each record no longer than bufSize is returned by Next as a slice of
+// that buffer, and longer records are copied as usual.  In either case, the
+// record is valid only until the next call to Next.
+func NewZeroCopyReader(r io.Reader, bufSize int) *Reader {
+	return &Reader{buf: bufio.NewReaderSize(r, bufSize), zeroCopy: true}
+}
+
 // A Writer outputs delimited records to an io.Writer.
 //
 // Basic usage:
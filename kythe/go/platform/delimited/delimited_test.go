@@ -132,3 +132,88 @@ func TestRoundTrip(t *testing.T) {
 		t.Errorf("Round trip of %q: got %+q, want %+q", input, got, words)
 	}
 }
+
+func TestZeroCopyReader(t *testing.T) {
+	// A 16-byte buffer holds the short records, but not the last.
+	long := strings.Repeat("x", 20)
+	var buf bytes.Buffer
+	wr := NewWriter(&buf)
+	records := []string{"", "A", "BC", "DEF", long, "GH"}
+	for _, rec := range records {
+		if err := wr.Put([]byte(rec)); err != nil {
+			t.Fatalf("Put %q: unexpected error: %v", rec, err)
+		}
+	}
+
+	rd := NewZeroCopyReader(&buf, 16)
+	for _, want := range records {
+		got, err := rd.Next()
+		if err != nil {
+			t.Fatalf("Unexpected read error: %v", err)
+		} else if s := string(got); s != want {
+			t.Errorf("Next record: got %q, want %q", s, want)
+		} else if cap(got) != len(got) {
+			t.Errorf("Next record %q: capacity %d extends past the record", s, cap(got))
+		}
+	}
+	if got, err := rd.Next(); err != io.EOF {
+		t.Errorf("Next record: got %q [%v], want EOF", string(got), err)
+	}
+
+	rd = NewZeroCopyReader(strings.NewReader("\x05ABCD"), 16)
+	if got, err := rd.Next(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Next record: got %q [%v], want %v", string(got), err, io.ErrUnexpectedEOF)
+	}
+}
+
+func benchRecords(b *testing.B) []byte {
+	var buf bytes.Buffer
+	wr := NewWriter(&buf)
+	rec := bytes.Repeat([]byte("x"), 100)
+	for i := 0; i < 10000; i++ {
+		if err := wr.Put(rec); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func BenchmarkReader(b *testing.B) {
+	data := benchRecords(b)
+	for _, test := range []struct {
+		name      string
+		newReader func(io.Reader) *Reader
+	}{
+		{"Copy", NewReader},
+		{"ZeroCopy", func(r io.Reader) *Reader { return NewZeroCopyReader(r, 64*1024) }},
+	} {
+		b.Run(test.name, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				rd := test.newReader(bytes.NewReader(data))
+				for {
+					if _, err := rd.Next(); err == io.EOF {
+						break
+					} else if err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkWriter(b *testing.B) {
+	rec := bytes.Repeat([]byte("x"), 100)
+	var buf bytes.Buffer
+	b.SetBytes(int64(len(rec)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if i%10000 == 0 {
+			buf.Reset()
+		}
+		if err := NewWriter(&buf).Put(rec); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
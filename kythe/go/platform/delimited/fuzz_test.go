@@ -0,0 +1,92 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package delimited
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+// readAll returns the records read from rd and the error that ended them.
+func readAll(rd *Reader) ([][]byte, error) {
+	var recs [][]byte
+	for {
+		rec, err := rd.Next()
+		if err != nil {
+			return recs, err
+		}
+		recs = append(recs, bytes.Clone(rec))
+	}
+}
+
+func FuzzReader(f *testing.F) {
+	f.Add([]byte(testData))
+	f.Add([]byte{})
+	f.Add([]byte("\x05ABC"))
+	f.Add([]byte("\x80"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		recs, err := readAll(NewReader(bytes.NewReader(data)))
+		if err == nil {
+			t.Fatal("Reader stopped without error")
+		}
+
+		// A zero-copy reader, whether or not its buffer holds a record, reads
+		// the same records.
+		zrecs, zerr := readAll(NewZeroCopyReader(bytes.NewReader(data), 16))
+		if len(zrecs) != len(recs) {
+			t.Fatalf("Zero-copy reader read %d records, want %d", len(zrecs), len(recs))
+		}
+		for i, rec := range recs {
+			if !bytes.Equal(zrecs[i], rec) {
+				t.Errorf("Zero-copy record %d: got %q, want %q", i, zrecs[i], rec)
+			}
+		}
+		if (zerr == io.EOF) != (err == io.EOF) {
+			t.Errorf("Zero-copy reader error: got %v, want %v", zerr, err)
+		}
+
+		// The records read survive being written and read back.
+		var buf bytes.Buffer
+		w := NewWriter(&buf)
+		for _, rec := range recs {
+			if err := w.Put(rec); err != nil {
+				t.Fatal(err)
+			}
+		}
+		again, err := readAll(NewReader(&buf))
+		if err != io.EOF {
+			t.Errorf("Reading rewritten records: got %v, want EOF", err)
+		} else if len(again) != len(recs) {
+			t.Errorf("Read %d rewritten records, want %d", len(again), len(recs))
+		}
+		for i := 0; i < len(again) && i < len(recs); i++ {
+			if !bytes.Equal(again[i], recs[i]) {
+				t.Errorf("Rewritten record %d: got %q, want %q", i, again[i], recs[i])
+			}
+		}
+
+		// A recovering reader reads to the end of any input.
+		rrecs, rerr := readAll(NewRecoveringReader(bytes.NewReader(data), &RecoveryOptions{MaxRecordSize: 64}))
+		if !errors.Is(rerr, io.EOF) {
+			t.Errorf("Recovering reader error: got %v, want EOF", rerr)
+		} else if len(rrecs) > len(data) {
+			t.Errorf("Recovering reader read %d records from %d bytes", len(rrecs), len(data))
+		}
+	})
+}
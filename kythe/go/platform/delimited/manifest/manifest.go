@@ -0,0 +1,250 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package manifest implements integrity manifests for delimited streams.
+//
+// A manifest records the total number of records and bytes in a stream, along
+// with a CRC-32C checksum of each consecutive chunk of records.  It is stored
+// alongside the stream (conventionally at the stream's path plus Extension)
+// so that the stream itself remains readable by any delimited reader.  Before
+// loading a stream, Verify may be used to detect truncation or corruption and
+// to locate the first damaged chunk.
+package manifest // import "kythe.io/kythe/go/platform/delimited/manifest"
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+
+	"kythe.io/kythe/go/platform/delimited"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Extension is the conventional suffix of a manifest file, appended to the
+// path of the stream it describes.
+const Extension = ".manifest"
+
+// DefaultChunkRecords is the default number of records per chunk.
+const DefaultChunkRecords = 4096
+
+// A Manifest describes the contents of a delimited stream.
+type Manifest struct {
+	Records      int64   `json:"records"`
+	Bytes        int64   `json:"bytes"`
+	ChunkRecords int     `json:"chunk_records"`
+	Chunks       []Chunk `json:"chunks"`
+}
+
+// A Chunk describes a consecutive run of records.  Every chunk except the last
+// has exactly Manifest.ChunkRecords records.
+type Chunk struct {
+	Records int    `json:"records"`
+	Bytes   int64  `json:"bytes"`
+	CRC32C  uint32 `json:"crc32c"`
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Read decodes a manifest from r.
+func Read(r io.Reader) (*Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("decoding manifest: %v", err)
+	} else if m.ChunkRecords <= 0 {
+		return nil, fmt.Errorf("invalid manifest chunk size: %d", m.ChunkRecords)
+	}
+	return &m, nil
+}
+
+// Write encodes m to w.
+func Write(w io.Writer, m *Manifest) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// chunker accumulates records into chunks.
+type chunker struct {
+	m   Manifest
+	cur Chunk
+	crc hash.Hash32
+}
+
+func newChunker(chunkRecords int) *chunker {
+	return &chunker{m: Manifest{ChunkRecords: chunkRecords}, crc: crc32.New(castagnoli)}
+}
+
+// add accounts for one record, whose encoding (including its length prefix)
+// is data.
+func (c *chunker) add(data ...[]byte) (finished *Chunk) {
+	for _, d := range data {
+		c.crc.Write(d)
+		c.cur.Bytes += int64(len(d))
+		c.m.Bytes += int64(len(d))
+	}
+	c.cur.Records++
+	c.m.Records++
+	if c.cur.Records == c.m.ChunkRecords {
+		return c.finish()
+	}
+	return nil
+}
+
+func (c *chunker) finish() *Chunk {
+	if c.cur.Records == 0 {
+		return nil
+	}
+	c.cur.CRC32C = c.crc.Sum32()
+	c.m.Chunks = append(c.m.Chunks, c.cur)
+	c.cur = Chunk{}
+	c.crc.Reset()
+	return &c.m.Chunks[len(c.m.Chunks)-1]
+}
+
+// A Writer writes delimited records and computes the manifest describing
+// them.
+type Writer struct {
+	w *delimited.Writer
+	c *chunker
+}
+
+// NewWriter returns a Writer that writes delimited records to w, checksumming
+// every chunkRecords records.  If chunkRecords ≤ 0, DefaultChunkRecords is
+// used.
+func NewWriter(w io.Writer, chunkRecords int) *Writer {
+	if chunkRecords <= 0 {
+		chunkRecords = DefaultChunkRecords
+	}
+	return &Writer{w: delimited.NewWriter(w), c: newChunker(chunkRecords)}
+}
+
+// Put writes a single record.
+func (w *Writer) Put(record []byte) error {
+	if err := w.w.Put(record); err != nil {
+		return err
+	}
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(len(record)))
+	w.c.add(buf[:n], record)
+	return nil
+}
+
+// PutProto encodes and writes msg as a single record.
+func (w *Writer) PutProto(msg proto.Message) error {
+	rec, err := proto.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("error encoding proto: %v", err)
+	}
+	return w.Put(rec)
+}
+
+// Manifest returns the manifest for the records written so far.  It should be
+// called once, after the last record is written.
+func (w *Writer) Manifest() *Manifest {
+	w.c.finish()
+	m := w.c.m
+	return &m
+}
+
+// An Error describes a mismatch between a stream and its manifest.
+type Error struct {
+	// Chunk is the index of the first chunk that does not match, and Record
+	// is the index of its first record.
+	Chunk  int
+	Record int64
+
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("stream does not match manifest at chunk %d (record %d): %s", e.Chunk, e.Record, e.Reason)
+}
+
+// Verify reads the delimited stream r to its end and reports whether it
+// matches m.  If it does not, the error is an *Error.
+func Verify(r io.Reader, m *Manifest) error {
+	if m.ChunkRecords <= 0 {
+		return fmt.Errorf("invalid manifest chunk size: %d", m.ChunkRecords)
+	}
+	c := newChunker(m.ChunkRecords)
+	fail := func(reason string, args ...any) error {
+		i := len(c.m.Chunks)
+		return &Error{Chunk: i, Record: int64(i) * int64(m.ChunkRecords), Reason: fmt.Sprintf(reason, args...)}
+	}
+	check := func(got *Chunk) error {
+		i := len(c.m.Chunks) - 1
+		if i >= len(m.Chunks) {
+			c.m.Chunks = c.m.Chunks[:i]
+			return fail("unexpected data after %d records", m.Records)
+		}
+		if want := m.Chunks[i]; *got != want {
+			c.m.Chunks = c.m.Chunks[:i]
+			return fail("got %d records/%d bytes/checksum %08x; want %d/%d/%08x",
+				got.Records, got.Bytes, got.CRC32C, want.Records, want.Bytes, want.CRC32C)
+		}
+		return nil
+	}
+
+	rd := &countingReader{r: r}
+	dr := delimited.NewReader(rd)
+	var last int64
+	for {
+		rec, err := dr.Next()
+		if errors.Is(err, io.EOF) && rd.n == last {
+			break
+		} else if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return fail("truncated record")
+		} else if err != nil {
+			return err
+		}
+		// The delimited reader buffers ahead, so derive the record's encoded
+		// size rather than measuring the underlying reader.
+		var buf [binary.MaxVarintLen64]byte
+		n := binary.PutUvarint(buf[:], uint64(len(rec)))
+		last += int64(n + len(rec))
+		if done := c.add(buf[:n], rec); done != nil {
+			if err := check(done); err != nil {
+				return err
+			}
+		}
+	}
+	if done := c.finish(); done != nil {
+		if err := check(done); err != nil {
+			return err
+		}
+	}
+	if len(c.m.Chunks) < len(m.Chunks) {
+		return fail("stream has %d records; want %d", c.m.Records, m.Records)
+	}
+	return nil
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
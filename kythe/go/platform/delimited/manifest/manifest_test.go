@@ -0,0 +1,112 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"kythe.io/kythe/go/platform/delimited"
+)
+
+// writeStream writes n records with a manifest of the given chunk size.
+func writeStream(t *testing.T, n, chunkRecords int) ([]byte, *Manifest) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewWriter(&buf, chunkRecords)
+	for i := 0; i < n; i++ {
+		if err := w.Put([]byte(fmt.Sprintf("record %d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes(), w.Manifest()
+}
+
+func TestWriter(t *testing.T) {
+	data, m := writeStream(t, 10, 4)
+	if m.Records != 10 || m.Bytes != int64(len(data)) || len(m.Chunks) != 3 {
+		t.Errorf("Unexpected manifest: %+v", m)
+	}
+	if m.Chunks[0].Records != 4 || m.Chunks[2].Records != 2 {
+		t.Errorf("Unexpected chunks: %+v", m.Chunks)
+	}
+
+	// The output is an ordinary delimited stream.
+	rd := delimited.NewReader(bytes.NewReader(data))
+	for i := 0; i < 10; i++ {
+		if rec, err := rd.Next(); err != nil || string(rec) != fmt.Sprintf("record %d", i) {
+			t.Fatalf("Next: got (%q, %v)", rec, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Read(&buf); err != nil {
+		t.Fatal(err)
+	} else if fmt.Sprint(got) != fmt.Sprint(m) {
+		t.Errorf("Read: got %+v, want %+v", got, m)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	data, m := writeStream(t, 10, 4)
+	if err := Verify(bytes.NewReader(data), m); err != nil {
+		t.Errorf("Verify of intact stream: %v", err)
+	}
+
+	corrupt := func(i int) []byte {
+		d := append([]byte(nil), data...)
+		d[i] ^= 0x20
+		return d
+	}
+	boundary := int(m.Chunks[0].Bytes)
+	tests := []struct {
+		name      string
+		data      []byte
+		wantChunk int
+	}{
+		{"bit flip in first chunk", corrupt(3), 0},
+		{"bit flip in second chunk", corrupt(boundary + 3), 1},
+		{"truncated record", data[:len(data)-2], 2},
+		{"missing chunk", data[:boundary], 1},
+		{"extra data", append(append([]byte(nil), data...), 1, 'x'), 2},
+		{"empty", nil, 0},
+	}
+	for _, test := range tests {
+		err := Verify(bytes.NewReader(test.data), m)
+		var merr *Error
+		if !errors.As(err, &merr) {
+			t.Errorf("%s: got error %v; want *Error", test.name, err)
+		} else if merr.Chunk != test.wantChunk {
+			t.Errorf("%s: got error at chunk %d; want %d: %v", test.name, merr.Chunk, test.wantChunk, err)
+		}
+	}
+}
+
+func TestVerifyEmpty(t *testing.T) {
+	data, m := writeStream(t, 0, 4)
+	if m.Records != 0 || len(m.Chunks) != 0 {
+		t.Errorf("Unexpected manifest: %+v", m)
+	}
+	if err := Verify(bytes.NewReader(data), m); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright 2014 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package delimited
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxRecordSize is the default limit on the size of a record accepted
+// by a recovering Reader.
+const DefaultMaxRecordSize = 8 << 20
+
+// A Skip describes a range of corrupt input bytes discarded by a recovering
+// Reader.
+type Skip struct {
+	Offset int64 // the offset in the input of the first skipped byte
+	Length int64 // the number of bytes skipped
+	Err    error // the error that triggered the skip
+}
+
+func (s Skip) String() string {
+	return fmt.Sprintf("skipped %d bytes at offset %d: %v", s.Length, s.Offset, s.Err)
+}
+
+// RecoveryOptions control the behavior of a recovering Reader.
+type RecoveryOptions struct {
+	// MaxRecordSize is the largest record considered plausible.  If zero,
+	// DefaultMaxRecordSize is used.  The Reader buffers up to twice this many
+	// bytes of input.
+	MaxRecordSize int
+
+	// Valid, if set, reports an error if rec is not a plausible record.
+	// Invalid records are treated as corruption.
+	Valid func(rec []byte) error
+
+	// OnSkip, if set, is called for each range of input discarded while
+	// resynchronizing.
+	OnSkip func(Skip)
+}
+
+// NewRecoveringReader constructs a delimited Reader for the records in r that
+// tolerates corruption.  When a record is truncated, has an implausible size,
+// or is rejected by opts.Valid, the Reader scans forward one byte at a time
+// until it finds a plausible record that is followed by another plausible
+// record (or by the end of the input), reports the bytes it skipped to
+// opts.OnSkip, and resumes reading there.
+//
+// Because the format has no synchronization markers, recovery is heuristic:
+// a short valid-looking sequence inside corrupt data may be returned as a
+// record.  Supplying a strict opts.Valid function makes this less likely.
+func NewRecoveringReader(r io.Reader, opts *RecoveryOptions) *Reader {
+	var ro RecoveryOptions
+	if opts != nil {
+		ro = *opts
+	}
+	if ro.MaxRecordSize <= 0 {
+		ro.MaxRecordSize = DefaultMaxRecordSize
+	}
+	size := 2 * (ro.MaxRecordSize + binary.MaxVarintLen64)
+	return &Reader{buf: bufio.NewReaderSize(r, size), recovery: &recovery{opts: ro}}
+}
+
+type recovery struct {
+	opts   RecoveryOptions
+	offset int64 // input offset of the next unread byte
+}
+
+var errRecordSize = errors.New("implausible record size")
+
+// readError wraps an error returned by the underlying reader, which is
+// reported to the caller rather than treated as corruption.
+type readError struct{ err error }
+
+func (e readError) Error() string { return e.err.Error() }
+
+// peekErr converts an error from Peek into a recovery error.
+func peekErr(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return readError{err}
+}
+
+// peekRecord returns the record starting at offset at in the buffered input
+// and the total number of bytes it occupies.  It returns io.EOF if there is no
+// input at that offset.
+func (r *Reader) peekRecord(at int) ([]byte, int, error) {
+	hdr, err := r.buf.Peek(at + binary.MaxVarintLen64)
+	if len(hdr) <= at {
+		if err == nil || err == io.EOF {
+			return nil, 0, io.EOF
+		}
+		return nil, 0, readError{err}
+	}
+	size, n := binary.Uvarint(hdr[at:])
+	if n <= 0 {
+		if n == 0 && err != nil {
+			return nil, 0, peekErr(err)
+		}
+		return nil, 0, errRecordSize
+	} else if size > uint64(r.recovery.opts.MaxRecordSize) {
+		return nil, 0, errRecordSize
+	}
+	end := at + n + int(size)
+	data, err := r.buf.Peek(end)
+	if len(data) < end {
+		return nil, 0, peekErr(err)
+	}
+	rec := data[at+n : end]
+	if v := r.recovery.opts.Valid; v != nil {
+		if err := v(rec); err != nil {
+			return nil, 0, err
+		}
+	}
+	return rec, end - at, nil
+}
+
+// plausible reports whether a valid record begins at the current position
+// and is followed by either another valid record or the end of the input.
+func (r *Reader) plausible() bool {
+	_, n, err := r.peekRecord(0)
+	if err != nil {
+		return false
+	}
+	_, _, err = r.peekRecord(n)
+	return err == nil || err == io.EOF
+}
+
+func (r *Reader) nextRecovering() ([]byte, error) {
+	rc := r.recovery
+	rec, n, err := r.peekRecord(0)
+	if err == io.EOF {
+		return nil, io.EOF
+	} else if rerr, ok := err.(readError); ok {
+		return nil, rerr.err
+	} else if err != nil {
+		skip := Skip{Offset: rc.offset, Err: err}
+		for {
+			if _, err := r.buf.Discard(1); err != nil {
+				return nil, err
+			}
+			rc.offset++
+			skip.Length++
+			if _, err := r.buf.Peek(1); err == io.EOF {
+				r.reportSkip(skip)
+				return nil, io.EOF
+			}
+			if r.plausible() {
+				break
+			}
+		}
+		r.reportSkip(skip)
+		if rec, n, err = r.peekRecord(0); err != nil {
+			if rerr, ok := err.(readError); ok {
+				return nil, rerr.err
+			}
+			return nil, err
+		}
+	}
+
+	r.data = append(r.data[:0], rec...)
+	if _, err := r.buf.Discard(n); err != nil {
+		return nil, err
+	}
+	rc.offset += int64(n)
+	return r.data, nil
+}
+
+func (r *Reader) reportSkip(s Skip) {
+	if f := r.recovery.opts.OnSkip; f != nil {
+		f(s)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2014 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package delimited
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// lowercase accepts non-empty records of lowercase ASCII letters.
+func lowercase(rec []byte) error {
+	if len(rec) == 0 {
+		return errors.New("empty record")
+	}
+	for _, b := range rec {
+		if b < 'a' || b > 'z' {
+			return fmt.Errorf("invalid byte %q", b)
+		}
+	}
+	return nil
+}
+
+func readRecovering(t *testing.T, input []byte) ([]string, []Skip) {
+	t.Helper()
+	var skips []Skip
+	rd := NewRecoveringReader(bytes.NewReader(input), &RecoveryOptions{
+		MaxRecordSize: 64,
+		Valid:         lowercase,
+		OnSkip:        func(s Skip) { skips = append(skips, s) },
+	})
+	var got []string
+	for {
+		rec, err := rd.Next()
+		if err == io.EOF {
+			return got, skips
+		} else if err != nil {
+			t.Fatalf("Next: unexpected error: %v", err)
+		}
+		got = append(got, string(rec))
+	}
+}
+
+func encode(records ...string) []byte {
+	var buf bytes.Buffer
+	wr := NewWriter(&buf)
+	for _, rec := range records {
+		if err := wr.Put([]byte(rec)); err != nil {
+			panic(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestRecoveringReader(t *testing.T) {
+	head := encode("alpha", "beta")
+	garbage := []byte("\xff\xff\xff\xff\x7f\x03XYZ")
+	tail := encode("gamma", "delta")
+	input := append(append(append([]byte(nil), head...), garbage...), tail...)
+
+	got, skips := readRecovering(t, input)
+	if want := []string{"alpha", "beta", "gamma", "delta"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Records: got %q, want %q", got, want)
+	}
+	if len(skips) != 1 {
+		t.Fatalf("Skips: got %v, want 1", skips)
+	}
+	if s := skips[0]; s.Offset != int64(len(head)) || s.Length != int64(len(garbage)) {
+		t.Errorf("Skip: got offset %d length %d, want offset %d length %d",
+			s.Offset, s.Length, len(head), len(garbage))
+	}
+}
+
+func TestRecoveringReaderTruncated(t *testing.T) {
+	input := encode("alpha", "beta")
+	input = append(input, 0x10, 'g', 'a') // record claims 16 bytes
+
+	got, skips := readRecovering(t, input)
+	if want := []string{"alpha", "beta"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Records: got %q, want %q", got, want)
+	}
+	if len(skips) != 1 || skips[0].Length != 3 || skips[0].Err != io.ErrUnexpectedEOF {
+		t.Errorf("Skips: got %v, want 3 bytes at end", skips)
+	}
+}
+
+func TestRecoveringReaderClean(t *testing.T) {
+	words := []string{"some", "of", "what", "a", "fool", "thinks"}
+	got, skips := readRecovering(t, encode(words...))
+	if !reflect.DeepEqual(got, words) {
+		t.Errorf("Records: got %q, want %q", got, words)
+	}
+	if len(skips) != 0 {
+		t.Errorf("Unexpected skips: %v", skips)
+	}
+}
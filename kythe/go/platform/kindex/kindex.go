@@ -0,0 +1,125 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package kindex implements reading and writing of the legacy .kindex
+// compilation format, and conversion between it and kzip archives.
+//
+// A .kindex file stores a single compilation as a gzip-compressed stream of
+// length-delimited protobuf messages: a CompilationUnit followed by one
+// FileData message for each of its required inputs.
+//
+// The .kindex format is deprecated; this package exists to migrate existing
+// artifacts to (and, when necessary, from) kzip archives.
+package kindex // import "kythe.io/kythe/go/platform/kindex"
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"kythe.io/kythe/go/platform/delimited"
+	"kythe.io/kythe/go/platform/kzip"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+)
+
+// Extension is the conventional file extension of a .kindex file.
+const Extension = ".kindex"
+
+// Read decodes a single compilation from the .kindex data in r.
+func Read(r io.Reader) (*kzip.Compilation, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("opening gzip stream: %v", err)
+	}
+	defer gz.Close()
+
+	rd := delimited.NewReader(gz)
+	c := &kzip.Compilation{Proto: new(apb.CompilationUnit)}
+	if err := rd.NextProto(c.Proto); err == io.EOF {
+		return nil, errors.New("missing compilation unit")
+	} else if err != nil {
+		return nil, fmt.Errorf("reading compilation unit: %v", err)
+	}
+	for {
+		fd := new(apb.FileData)
+		if err := rd.NextProto(fd); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("reading file data: %v", err)
+		}
+		c.Files = append(c.Files, fd)
+	}
+	return c, nil
+}
+
+// Write encodes c in .kindex format to w.
+func Write(w io.Writer, c *kzip.Compilation) error {
+	gz := gzip.NewWriter(w)
+	wr := delimited.NewWriter(gz)
+	if err := wr.PutProto(c.Unit()); err != nil {
+		return fmt.Errorf("writing compilation unit: %v", err)
+	}
+	for _, fd := range c.Files {
+		if err := wr.PutProto(fd); err != nil {
+			return fmt.Errorf("writing file data %q: %v", fd.GetInfo().GetPath(), err)
+		}
+	}
+	return gz.Close()
+}
+
+// AddToKZip adds the compilation c and the contents of all of its files to w,
+// returning the digest of the unit in w.  It is an error if the recorded digest
+// of any file does not match its contents.  If an identical unit has already
+// been added to w, its digest is returned along with kzip.ErrUnitExists.
+func AddToKZip(w *kzip.Writer, c *kzip.Compilation) (string, error) {
+	for _, fd := range c.Files {
+		digest, err := w.AddFile(bytes.NewReader(fd.Content))
+		if err != nil {
+			return "", fmt.Errorf("adding file %q: %v", fd.GetInfo().GetPath(), err)
+		}
+		if want := fd.GetInfo().GetDigest(); want != "" && digest != want {
+			return "", fmt.Errorf("file %q has digest %q; recorded as %q", fd.GetInfo().GetPath(), digest, want)
+		}
+	}
+	return w.AddUnit(c.Unit(), nil)
+}
+
+// FromKZip returns the compilation u, read from r, along with the contents of
+// each of its required inputs.  The order of the files matches the order of
+// the unit's required inputs; duplicate inputs are stored only once.
+func FromKZip(r *kzip.Reader, u *kzip.Unit) (*kzip.Compilation, error) {
+	c := &kzip.Compilation{Proto: u.Proto}
+	seen := make(map[string]bool)
+	for _, ri := range u.Proto.GetRequiredInput() {
+		info := ri.GetInfo()
+		if seen[info.GetDigest()] {
+			continue
+		}
+		seen[info.GetDigest()] = true
+		data, err := r.ReadAll(info.GetDigest())
+		if err != nil {
+			return nil, fmt.Errorf("reading required input %q: %v", info.GetPath(), err)
+		}
+		c.Files = append(c.Files, &apb.FileData{
+			Content: data,
+			Info:    &apb.FileInfo{Path: info.GetPath(), Digest: info.GetDigest()},
+		})
+	}
+	return c, nil
+}
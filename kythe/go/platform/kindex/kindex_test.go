@@ -0,0 +1,127 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kindex_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/platform/kindex"
+	"kythe.io/kythe/go/platform/kzip"
+
+	"google.golang.org/protobuf/proto"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func testCompilation(t *testing.T) *kzip.Compilation {
+	t.Helper()
+	c := &kzip.Compilation{Proto: &apb.CompilationUnit{
+		VName:      &spb.VName{Corpus: "corpus", Language: "go"},
+		SourceFile: []string{"a.go"},
+	}}
+	for _, f := range []struct{ path, content string }{
+		{"a.go", "package a\n"},
+		{"b.go", "package b\n"},
+	} {
+		if err := c.AddFile(f.path, strings.NewReader(f.content), nil); err != nil {
+			t.Fatalf("AddFile %q: %v", f.path, err)
+		}
+	}
+	return c
+}
+
+func TestRoundTrip(t *testing.T) {
+	want := testCompilation(t)
+
+	var buf bytes.Buffer
+	if err := kindex.Write(&buf, want); err != nil {
+		t.Fatalf("Write: unexpected error: %v", err)
+	}
+	got, err := kindex.Read(&buf)
+	if err != nil {
+		t.Fatalf("Read: unexpected error: %v", err)
+	}
+	if !proto.Equal(got.Proto, want.Proto) {
+		t.Errorf("Read unit: got %v, want %v", got.Proto, want.Proto)
+	}
+	if len(got.Files) != len(want.Files) {
+		t.Fatalf("Read files: got %d, want %d", len(got.Files), len(want.Files))
+	}
+	for i, fd := range got.Files {
+		if !proto.Equal(fd, want.Files[i]) {
+			t.Errorf("Read file %d: got %v, want %v", i, fd, want.Files[i])
+		}
+	}
+}
+
+func TestKZipRoundTrip(t *testing.T) {
+	want := testCompilation(t)
+
+	var buf bytes.Buffer
+	w, err := kzip.NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("NewWriter: unexpected error: %v", err)
+	}
+	digest, err := kindex.AddToKZip(w, want)
+	if err != nil {
+		t.Fatalf("AddToKZip: unexpected error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: unexpected error: %v", err)
+	}
+
+	r, err := kzip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("NewReader: unexpected error: %v", err)
+	}
+	u, err := r.Lookup(digest)
+	if err != nil {
+		t.Fatalf("Lookup %q: unexpected error: %v", digest, err)
+	}
+	got, err := kindex.FromKZip(r, u)
+	if err != nil {
+		t.Fatalf("FromKZip: unexpected error: %v", err)
+	}
+	if !proto.Equal(got.Proto, want.Proto) {
+		t.Errorf("FromKZip unit: got %v, want %v", got.Proto, want.Proto)
+	}
+	if len(got.Files) != len(want.Files) {
+		t.Fatalf("FromKZip files: got %d, want %d", len(got.Files), len(want.Files))
+	}
+	for i, fd := range got.Files {
+		if !proto.Equal(fd, want.Files[i]) {
+			t.Errorf("FromKZip file %d: got %v, want %v", i, fd, want.Files[i])
+		}
+	}
+}
+
+func TestAddToKZipDigestMismatch(t *testing.T) {
+	c := testCompilation(t)
+	c.Files[0].Info.Digest = "bogus"
+
+	w, err := kzip.NewWriter(new(bytes.Buffer))
+	if err != nil {
+		t.Fatalf("NewWriter: unexpected error: %v", err)
+	}
+	defer w.Close()
+	if digest, err := kindex.AddToKZip(w, c); err == nil {
+		t.Errorf("AddToKZip: got digest %q, want error", digest)
+	}
+}
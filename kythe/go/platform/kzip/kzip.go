@@ -58,6 +58,12 @@
 //	// Add file contents.
 //	fdigest, err := w.AddFile(file)
 //	...
+//
+// Merging Archives:
+//
+//	// Copy all compilation records and their required inputs from r into w.
+//	err := kzip.Merge(w, r)
+//	...
 package kzip // import "kythe.io/kythe/go/platform/kzip"
 
 import (
@@ -446,6 +452,19 @@ func (r *Reader) Open(fileDigest string) (io.ReadCloser, error) {
 	return nil, ErrDigestNotFound
 }
 
+// FileSize returns the size in bytes of the contents of the specified file
+// digest.  If the requested digest is not in the archive, ErrDigestNotFound is
+// returned.
+func (r *Reader) FileSize(fileDigest string) (int64, error) {
+	needle := r.filePath(fileDigest)
+	if pos := r.firstIndex(needle); pos >= 0 {
+		if f := r.zip.File[pos]; f.Name == needle {
+			return int64(f.UncompressedSize64), nil
+		}
+	}
+	return 0, ErrDigestNotFound
+}
+
 // ReadAll returns the complete contents of the file with the specified digest.
 // It is a convenience wrapper for Open followed by ioutil.ReadAll.
 func (r *Reader) ReadAll(fileDigest string) ([]byte, error) {
@@ -611,6 +630,63 @@ func (w *Writer) AddFile(r io.Reader) (string, error) {
 	return digest, nil
 }
 
+// CopyUnit copies the compilation record u read from r into w, along with the
+// contents of each of its required inputs, returning the digest of the unit in
+// w.  Files already present in w are not copied again.  As with AddUnit, if the
+// unit is already present in w, its digest is returned along with
+// ErrUnitExists.
+func (w *Writer) CopyUnit(r *Reader, u *Unit) (string, error) {
+	for _, ri := range u.Proto.GetRequiredInput() {
+		digest := ri.GetInfo().GetDigest()
+		if w.hasFile(digest) {
+			continue
+		}
+		if err := w.copyFile(r, digest); err != nil {
+			return "", fmt.Errorf("copying required input %q: %v", ri.GetInfo().GetPath(), err)
+		}
+	}
+	return w.AddUnit(u.Proto, u.Index)
+}
+
+func (w *Writer) hasFile(digest string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.fd.Contains(digest)
+}
+
+func (w *Writer) copyFile(r *Reader, digest string) error {
+	rc, err := r.Open(digest)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	got, err := w.AddFile(rc)
+	if err != nil {
+		return err
+	} else if got != digest {
+		return fmt.Errorf("file digest mismatch: got %q, want %q", got, digest)
+	}
+	return nil
+}
+
+// Merge copies every compilation record stored in each of rs, together with
+// the contents of their required inputs, into w.  Compilations and files that
+// are already present in w are skipped, so that the result contains a single
+// copy of each unique record.
+func Merge(w *Writer, rs ...*Reader) error {
+	for _, r := range rs {
+		if err := r.Scan(func(u *Unit) error {
+			if _, err := w.CopyUnit(r, u); err != nil && err != ErrUnitExists {
+				return fmt.Errorf("copying unit %s: %v", u.Digest, err)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Close closes the writer, flushing any remaining unwritten data out to the
 // underlying zip file. It is safe to close w arbitrarily many times; all calls
 // after the first will report nil.
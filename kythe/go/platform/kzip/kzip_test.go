@@ -24,6 +24,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
@@ -128,6 +130,12 @@ func testRoundTrip(encoding kzip.Encoding, t *testing.T) {
 	} else if got := string(bits); got != fileIn {
 		t.Errorf("ReadAll %q: got %q, want %q", fdigest, got, fileIn)
 	}
+	if size, err := r.FileSize(fdigest); err != nil || size != int64(len(fileIn)) {
+		t.Errorf("FileSize %q: got %d, %v; want %d", fdigest, size, err, len(fileIn))
+	}
+	if _, err := r.FileSize("does not exist"); err != kzip.ErrDigestNotFound {
+		t.Errorf("FileSize (non-existing file): got error %v, want %v", err, kzip.ErrDigestNotFound)
+	}
 
 	// Verify that a non-existing file digest reports ErrDigestNotFound.
 	if f, err := r.Open("does not exist"); err != kzip.ErrDigestNotFound {
@@ -240,6 +248,82 @@ func TestErrors(t *testing.T) {
 	}
 }
 
+// writeKZip returns the bytes of a kzip containing one compilation for each
+// of the given source files, each of which requires its source as input.
+func writeKZip(t *testing.T, srcs ...string) []byte {
+	t.Helper()
+	buf := bytes.NewBuffer(nil)
+	w, err := kzip.NewWriter(buf)
+	if err != nil {
+		t.Fatalf("NewWriter: unexpected error: %v", err)
+	}
+	for _, src := range srcs {
+		digest, err := w.AddFile(strings.NewReader(src))
+		if err != nil {
+			t.Fatalf("AddFile %q: unexpected error: %v", src, err)
+		}
+		if _, err := w.AddUnit(&apb.CompilationUnit{
+			SourceFile: []string{src},
+			RequiredInput: []*apb.CompilationUnit_FileInput{{
+				Info: &apb.FileInfo{Path: src, Digest: digest},
+			}},
+		}, nil); err != nil {
+			t.Fatalf("AddUnit %q: unexpected error: %v", src, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: unexpected error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestMerge(t *testing.T) {
+	var rs []*kzip.Reader
+	for _, srcs := range [][]string{{"a", "b"}, {"b", "c"}, {"c"}} {
+		data := writeKZip(t, srcs...)
+		r, err := kzip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("NewReader: unexpected error: %v", err)
+		}
+		rs = append(rs, r)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	w, err := kzip.NewWriter(buf)
+	if err != nil {
+		t.Fatalf("NewWriter: unexpected error: %v", err)
+	}
+	if err := kzip.Merge(w, rs...); err != nil {
+		t.Fatalf("Merge: unexpected error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: unexpected error: %v", err)
+	}
+
+	r, err := kzip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("NewReader: unexpected error: %v", err)
+	}
+	var got []string
+	if err := r.Scan(func(u *kzip.Unit) error {
+		src := u.Proto.SourceFile[0]
+		got = append(got, src)
+		digest := u.Proto.RequiredInput[0].Info.Digest
+		if bits, err := r.ReadAll(digest); err != nil {
+			t.Errorf("ReadAll %q: unexpected error: %v", digest, err)
+		} else if string(bits) != src {
+			t.Errorf("ReadAll %q: got %q, want %q", digest, bits, src)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("Scan: unexpected error: %v", err)
+	}
+	sort.Strings(got)
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Merged units: got %q, want %q", got, want)
+	}
+}
+
 func TestScanHelper(t *testing.T) {
 	buf := bytes.NewBuffer(nil)
 	w, err := kzip.NewWriter(buf)
@@ -0,0 +1,231 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package remotecache stores the outputs of extraction and analysis in a
+// remote cache shared between machines, so that repeated runs can reuse work
+// done elsewhere.
+//
+// A Client speaks the HTTP protocol of the Bazel remote cache: a value is
+// stored by a PUT to <base>/ac/<key> and fetched by a GET from the same path,
+// where key is a hex-encoded SHA-256 digest.  Any server implementing this
+// subset can be used, such as bazel-remote or a plain HTTP server with WebDAV
+// uploads.  The values are not Bazel ActionResult messages, so bazel-remote
+// must be run with --disable_http_ac_validation.
+//
+// Compilations are cached as single-unit .kzip archives keyed by their unit
+// digest (see PutUnits and GetUnit).  A Client also implements the Store
+// interface of the resultcache package, to share analysis outputs.
+package remotecache // import "kythe.io/kythe/go/platform/remotecache"
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"kythe.io/kythe/go/platform/analysis/resultcache"
+	"kythe.io/kythe/go/platform/kzip"
+)
+
+// A Client reads and writes entries of a remote HTTP cache.  Credentials for
+// basic authentication may be given in the user info of the base URL.
+type Client struct {
+	base *url.URL
+
+	// The HTTP client used for requests; if nil, http.DefaultClient is used.
+	HTTP *http.Client
+
+	// Additional headers sent with each request, such as Authorization.
+	Header http.Header
+
+	// If set, Put reports success without storing anything.  This allows
+	// untrusted builds to read from a cache without writing to it.
+	ReadOnly bool
+}
+
+// New returns a Client for the cache at the given http or https base URL.
+func New(base string) (*Client, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache URL: %v", err)
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid cache URL %q: scheme must be http or https", base)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return &Client{base: u}, nil
+}
+
+func (c *Client) String() string { return c.base.Redacted() }
+
+func (c *Client) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	if !validKey(key) {
+		return nil, fmt.Errorf("invalid cache key %q", key)
+	}
+	u := *c.base
+	u.Path += "/ac/" + key
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range c.Header {
+		req.Header[k] = vs
+	}
+	hc := c.HTTP
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	return hc.Do(req)
+}
+
+// Get implements a method of the resultcache.Store interface.  A key absent
+// from the cache is reported as resultcache.ErrNotFound.
+func (c *Client) Get(ctx context.Context, key string) ([]byte, error) {
+	rsp, err := c.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	switch {
+	case rsp.StatusCode == http.StatusNotFound:
+		return nil, resultcache.ErrNotFound
+	case rsp.StatusCode != http.StatusOK:
+		return nil, statusError(http.MethodGet, key, rsp)
+	}
+	return io.ReadAll(rsp.Body)
+}
+
+// Put implements a method of the resultcache.Store interface.
+func (c *Client) Put(ctx context.Context, key string, data []byte) error {
+	if c.ReadOnly {
+		return nil
+	}
+	rsp, err := c.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode/100 != 2 {
+		return statusError(http.MethodPut, key, rsp)
+	}
+	return nil
+}
+
+// Contains reports whether the cache holds an entry for key, without fetching
+// its value.
+func (c *Client) Contains(ctx context.Context, key string) (bool, error) {
+	rsp, err := c.do(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return false, err
+	}
+	rsp.Body.Close()
+	switch rsp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, statusError(http.MethodHead, key, rsp)
+}
+
+func statusError(method, key string, rsp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(rsp.Body, 512))
+	return fmt.Errorf("%s %s: %s: %s", method, key, rsp.Status, strings.TrimSpace(string(msg)))
+}
+
+// validKey reports whether key is a hex-encoded SHA-256 digest, as the Bazel
+// cache protocol requires.
+func validKey(key string) bool {
+	if len(key) != 2*sha256.Size {
+		return false
+	}
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
+
+// UnitKey returns the cache key of the archive holding the compilation with
+// the given unit digest.
+func UnitKey(digest string) string {
+	sum := sha256.Sum256([]byte("kzip\x00" + digest))
+	return hex.EncodeToString(sum[:])
+}
+
+// A containsStore is a Store that can report the presence of a key without
+// fetching its value.
+type containsStore interface {
+	Contains(ctx context.Context, key string) (bool, error)
+}
+
+// PutUnits stores each compilation in r, together with its required inputs,
+// as a separate archive keyed by its unit digest.  If store implements a
+// Contains method, compilations already in the store are not uploaded again.
+// PutUnits returns the number of compilations stored.
+func PutUnits(ctx context.Context, store resultcache.Store, r *kzip.Reader) (int, error) {
+	cs, _ := store.(containsStore)
+	var stored int
+	err := r.Scan(func(u *kzip.Unit) error {
+		key := UnitKey(u.Digest)
+		if cs != nil {
+			if ok, err := cs.Contains(ctx, key); err != nil {
+				return err
+			} else if ok {
+				return nil
+			}
+		}
+		var buf bytes.Buffer
+		w, err := kzip.NewWriter(&buf)
+		if err != nil {
+			return err
+		}
+		if _, err := w.CopyUnit(r, u); err != nil {
+			return fmt.Errorf("copying unit %s: %v", u.Digest, err)
+		} else if err := w.Close(); err != nil {
+			return err
+		}
+		if err := store.Put(ctx, key, buf.Bytes()); err != nil {
+			return fmt.Errorf("storing unit %s: %v", u.Digest, err)
+		}
+		stored++
+		return nil
+	})
+	return stored, err
+}
+
+// GetUnit copies the compilation with the given unit digest, together with
+// its required inputs, from store into w.  A compilation absent from the store
+// is reported as resultcache.ErrNotFound.
+func GetUnit(ctx context.Context, store resultcache.Store, w *kzip.Writer, digest string) error {
+	data, err := store.Get(ctx, UnitKey(digest))
+	if err != nil {
+		return err
+	}
+	r, err := kzip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("reading cached unit %s: %v", digest, err)
+	}
+	u, err := r.Lookup(digest)
+	if err != nil {
+		return fmt.Errorf("reading cached unit %s: %v", digest, err)
+	}
+	if _, err := w.CopyUnit(r, u); err != nil && err != kzip.ErrUnitExists {
+		return fmt.Errorf("copying unit %s: %v", digest, err)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remotecache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"kythe.io/kythe/go/platform/analysis/resultcache"
+	"kythe.io/kythe/go/platform/kzip"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// fakeCache is an in-memory HTTP cache server that records its requests.
+type fakeCache struct {
+	mu      sync.Mutex
+	data    map[string][]byte
+	methods []string
+}
+
+func (f *fakeCache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.methods = append(f.methods, r.Method)
+	key, ok := strings.CutPrefix(r.URL.Path, "/cache/ac/")
+	if !ok {
+		http.Error(w, "bad path", http.StatusBadRequest)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		data, ok := f.data[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.data[key] = data
+		w.WriteHeader(http.StatusCreated)
+	default:
+		http.Error(w, "bad method", http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestClient(t *testing.T) (*Client, *fakeCache) {
+	t.Helper()
+	fc := &fakeCache{data: make(map[string][]byte)}
+	srv := httptest.NewServer(fc)
+	t.Cleanup(srv.Close)
+	c, err := New(srv.URL + "/cache/")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c, fc
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t)
+	key := UnitKey("x")
+
+	if _, err := c.Get(ctx, key); err != resultcache.ErrNotFound {
+		t.Errorf("Get of missing key: got %v, want %v", err, resultcache.ErrNotFound)
+	}
+	if ok, err := c.Contains(ctx, key); err != nil || ok {
+		t.Errorf("Contains of missing key: got %v, %v; want false, nil", ok, err)
+	}
+	if err := c.Put(ctx, key, []byte("value")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got, err := c.Get(ctx, key); err != nil || string(got) != "value" {
+		t.Errorf("Get: got %q, %v; want %q, nil", got, err, "value")
+	}
+	if ok, err := c.Contains(ctx, key); err != nil || !ok {
+		t.Errorf("Contains: got %v, %v; want true, nil", ok, err)
+	}
+	if err := c.Put(ctx, "not-a-digest", nil); err == nil {
+		t.Error("Put of invalid key: unexpectedly succeeded")
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	ctx := context.Background()
+	c, fc := newTestClient(t)
+	c.ReadOnly = true
+	if err := c.Put(ctx, UnitKey("x"), []byte("value")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if len(fc.methods) != 0 {
+		t.Errorf("Read-only Put sent requests: %v", fc.methods)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	for _, base := range []string{"ftp://cache", "cache:8080", "%zz"} {
+		if c, err := New(base); err == nil {
+			t.Errorf("New(%q): got %v, want error", base, c)
+		}
+	}
+}
+
+func TestUnits(t *testing.T) {
+	ctx := context.Background()
+	c, fc := newTestClient(t)
+
+	var buf bytes.Buffer
+	w, err := kzip.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var digests []string
+	for _, src := range []string{"a.go", "b.go"} {
+		fd, err := w.AddFile(strings.NewReader("package " + src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		digest, err := w.AddUnit(&apb.CompilationUnit{
+			VName:      &spb.VName{Signature: src, Language: "go"},
+			SourceFile: []string{src},
+			RequiredInput: []*apb.CompilationUnit_FileInput{{
+				Info: &apb.FileInfo{Path: src, Digest: fd},
+			}},
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digests = append(digests, digest)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := kzip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := PutUnits(ctx, c, r); err != nil || n != 2 {
+		t.Fatalf("PutUnits: got %d, %v; want 2, nil", n, err)
+	}
+	if n, err := PutUnits(ctx, c, r); err != nil || n != 0 {
+		t.Errorf("PutUnits again: got %d, %v; want 0, nil", n, err)
+	}
+	for _, m := range fc.methods {
+		if m == http.MethodGet {
+			t.Errorf("PutUnits fetched values: %v", fc.methods)
+			break
+		}
+	}
+
+	var out bytes.Buffer
+	ow, err := kzip.NewWriter(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, digest := range digests {
+		if err := GetUnit(ctx, c, ow, digest); err != nil {
+			t.Errorf("GetUnit(%s): %v", digest, err)
+		}
+	}
+	if err := GetUnit(ctx, c, ow, "missing"); err != resultcache.ErrNotFound {
+		t.Errorf("GetUnit of missing unit: got %v, want %v", err, resultcache.ErrNotFound)
+	}
+	if err := ow.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	or, err := kzip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, digest := range digests {
+		u, err := or.Lookup(digest)
+		if err != nil {
+			t.Errorf("Lookup(%s): %v", digest, err)
+			continue
+		}
+		src := u.Proto.GetSourceFile()[0]
+		if data, err := or.ReadAll(u.Proto.GetRequiredInput()[0].GetInfo().GetDigest()); err != nil || string(data) != "package "+src {
+			t.Errorf("Input of %s: got %q, %v; want %q", src, data, err, "package "+src)
+		}
+	}
+}
@@ -25,6 +25,16 @@
 //	$ ... | entrystream --entrysets          # Prints combined entry sets as JSON
 //	$ ... | entrystream --count              # Prints the number of entries in the incoming stream
 //	$ ... | entrystream --read_format=json   # Reads entry stream as JSON and prints a proto stream
+//	$ ... | entrystream --validate --rejects=bad.json  # Drops entries that violate the schema
+//	$ ... | entrystream --manifest=out.entries.manifest > out.entries  # Writes an integrity manifest
+//	$ ... | entrystream --write_header > out.entries   # Embeds the entry format descriptors in the output
+//	$ ... | entrystream --recover                      # Skips corrupt regions of a delimited input stream
+//	$ ... | entrystream --gzip > out.entries.gz        # Compresses the output stream on multiple threads
+//	$ ... | entrystream --sample_percent=5             # Keeps the entries of ~5% of nodes
+//	$ ... | entrystream --sample_per_kind=10           # Keeps the entries of 10 random nodes per node kind (implies --sort)
+//
+//	$ ... | entrystream --write_format=csv --header                   # Writes entries as CSV
+//	$ ... | entrystream --write_format=tsv --columns=source_dir,fact_name  # Writes selected columns as TSV
 //
 //	$ ... | entrystream --write_format=riegeli # Writes entry stream as a Riegeli file
 //	$ ... | entrystream --read_format=riegeli  # Reads the entry stream from a Riegeli file
@@ -32,22 +42,33 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"strings"
+	"unicode/utf8"
 
 	"kythe.io/kythe/go/platform/delimited"
+	"kythe.io/kythe/go/platform/delimited/manifest"
+	"kythe.io/kythe/go/platform/vfs"
 	"kythe.io/kythe/go/storage/entryset"
 	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/storage/stream/sample"
 	"kythe.io/kythe/go/util/compare"
 	"kythe.io/kythe/go/util/disksort"
+	"kythe.io/kythe/go/util/encoding/tabular"
 	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/kytheuri"
 	"kythe.io/kythe/go/util/log"
 	"kythe.io/kythe/go/util/riegeli"
+	"kythe.io/kythe/go/util/schema/validate"
 
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/proto"
 
@@ -68,6 +89,8 @@ const (
 	jsonFormat      = "json"
 	riegeliFormat   = "riegeli"
 	textprotoFormat = "textproto"
+	csvFormat       = "csv"
+	tsvFormat       = "tsv"
 )
 
 var (
@@ -75,10 +98,13 @@ var (
 	writeJSON = flag.Bool("write_json", false, "Print JSON stream as output (deprecated: use --write_format)")
 
 	readFormat  = flag.String("read_format", delimitedFormat, "Format of the input stream (accepted formats: {delimited,json,riegeli})")
-	writeFormat = flag.String("write_format", delimitedFormat, "Format of the output stream (accepted formats: {delimited,json,riegeli,textproto})")
+	writeFormat = flag.String("write_format", delimitedFormat, "Format of the output stream (accepted formats: {delimited,json,riegeli,textproto,csv,tsv})")
 
 	riegeliOptions = flag.String("riegeli_writer_options", "", "Riegeli writer options")
 
+	tableColumns flagutil.StringList
+	tableHeader  = flag.Bool("header", false, "Write a header row for --write_format={csv,tsv}")
+
 	sortStream  = flag.Bool("sort", false, "Sort entry stream into GraphStore order")
 	uniqEntries = flag.Bool("unique", false, "Print only unique entries (implies --sort)")
 
@@ -87,11 +113,32 @@ var (
 	countOnly         = flag.Bool("count", false, "Only print the count of protos streamed")
 
 	structuredFacts = flag.Bool("structured_facts", false, "Encode and/or decode the fact_value for marked source facts")
+
+	samplePercent = flag.Float64("sample_percent", 0, "If set, keep only the entries of roughly this percentage (0-100) of nodes")
+	samplePerKind = flag.Int("sample_per_kind", 0, "If set, keep only the entries of at most this many random nodes per node kind (implies --sort)")
+	sampleSeed    = flag.Int64("sample_seed", 0, "Seed for --sample_percent and --sample_per_kind")
+
+	recoverInput = flag.Bool("recover", false, "If set with --read_format=delimited, skip over corrupt regions of the input, logging each skipped byte range")
+	writeHeader  = flag.Bool("write_header", false, "If set with --write_format=delimited, begin the output with a header describing the entry format")
+	gzipOutput   = flag.Bool("gzip", false, "If set with --write_format=delimited, gzip the output, compressing blocks in parallel")
+	gzipThreads  = flag.Int("gzip_threads", 0, "Number of blocks to compress concurrently with --gzip (default: GOMAXPROCS)")
+	manifestPath = flag.String("manifest", "", "If set with --write_format=delimited, write an integrity manifest of the output to this path")
+
+	validateEntries = flag.Bool("validate", false, "Drop entries that do not conform to the Kythe schema")
+	rejectsPath     = flag.String("rejects", "", "If set with --validate, write each dropped entry and the reason it was rejected as JSON to this path")
 )
 
+// rejectedEntry is the JSON format of entries dropped by --validate.  The
+// entry is encoded by protojson, as in the other JSON entry formats.
+type rejectedEntry struct {
+	Entry  json.RawMessage `json:"entry"`
+	Reason string          `json:"reason"`
+}
+
 func init() {
+	flag.Var(&tableColumns, "columns", "Comma-separated columns to write for --write_format={csv,tsv} (default: all; available: "+strings.Join(tabular.ColumnNames(entryColumns), ",")+")")
 	flag.Usage = flagutil.SimpleUsage("Manipulate a stream of Entry messages",
-		"[--read_format=<format>] [--unique] ([--write_format=<format>] [--sort] | [--entrysets] | [--count] | [--aggregate_entryset])")
+		"[--read_format=<format>] [--unique] [--validate [--rejects=<path>]] [--sample_percent=<p> | --sample_per_kind=<k>] ([--write_format=<format>] [--sort] | [--entrysets] | [--count] | [--aggregate_entryset])")
 }
 
 func main() {
@@ -143,17 +190,62 @@ func main() {
 			}
 		}
 	case delimitedFormat:
-		rd = stream.NewReader(in)
+		if *recoverInput {
+			rd = stream.NewRecoveringReader(in, func(s delimited.Skip) {
+				log.Warningf("Corrupt input: %v", s)
+			})
+		} else {
+			rd = stream.NewReader(in)
+		}
 	default:
 		log.Fatalf("Unsupported --read_format=%s", *readFormat)
 	}
 
-	if *sortStream || *entrySets || *uniqEntries {
+	if *manifestPath != "" && (*writeFormat != delimitedFormat || *countOnly || *aggregateEntrySet || *entrySets) {
+		flagutil.UsageError("--manifest requires --write_format=delimited")
+	}
+
+	if *recoverInput && *readFormat != delimitedFormat {
+		flagutil.UsageError("--recover requires --read_format=delimited")
+	}
+	if *gzipOutput && (*writeFormat != delimitedFormat || *countOnly || *aggregateEntrySet || *entrySets) {
+		flagutil.UsageError("--gzip requires --write_format=delimited")
+	} else if *gzipOutput && *manifestPath != "" {
+		flagutil.UsageError("--gzip cannot be combined with --manifest")
+	}
+	if *writeHeader && (*writeFormat != delimitedFormat || *countOnly || *aggregateEntrySet || *entrySets) {
+		flagutil.UsageError("--write_header requires --write_format=delimited")
+	}
+
+	if *validateEntries {
+		var rejects *json.Encoder
+		if *rejectsPath != "" {
+			f, err := vfs.Create(context.Background(), *rejectsPath)
+			failOnErr(err)
+			defer func() { failOnErr(f.Close()) }()
+			rejects = json.NewEncoder(f)
+		}
+		rd = validateStream(rd, rejects)
+	} else if *rejectsPath != "" {
+		flagutil.UsageError("--rejects requires --validate")
+	}
+
+	if *samplePercent < 0 || *samplePercent > 100 {
+		flagutil.UsageErrorf("--sample_percent must be between 0 and 100; got %v", *samplePercent)
+	} else if *samplePercent > 0 {
+		rd = sample.Fraction(rd, *samplePercent/100, uint64(*sampleSeed))
+	}
+
+	if *sortStream || *entrySets || *uniqEntries || *samplePerKind > 0 {
 		var err error
 		rd, err = sortEntries(rd)
 		failOnErr(err)
 	}
 
+	if *samplePerKind > 0 {
+		rd = sample.PerKind(rd, *samplePerKind, *sampleSeed)
+	}
+
 	if *uniqEntries {
 		rd = dedupEntries(rd)
 	}
@@ -231,10 +323,41 @@ func main() {
 			}))
 			failOnErr(wr.Flush())
 		case delimitedFormat:
-			wr := delimited.NewWriter(out)
+			if *manifestPath != "" {
+				wr := manifest.NewWriter(out, 0)
+				if *writeHeader {
+					hdr, err := stream.CurrentHeader().Marshal()
+					failOnErr(err)
+					failOnErr(wr.Put(hdr))
+				}
+				failOnErr(rd(func(entry *spb.Entry) error {
+					return wr.PutProto(entry)
+				}))
+				failOnErr(writeManifest(wr.Manifest()))
+				break
+			}
+			var sink io.Writer = out
+			var cw *delimited.CompressingWriter
+			if *gzipOutput {
+				var err error
+				cw, err = delimited.NewCompressingWriter(out, &delimited.CompressionOptions{
+					Concurrency: *gzipThreads,
+				})
+				failOnErr(err)
+				sink = cw
+			}
+			wr := delimited.NewWriter(sink)
+			if *writeHeader {
+				failOnErr(stream.WriteHeader(wr))
+			}
 			failOnErr(rd(func(entry *spb.Entry) error {
 				return wr.PutProto(entry)
 			}))
+			if cw != nil {
+				failOnErr(cw.Close())
+			}
+		case csvFormat, tsvFormat:
+			failOnErr(writeTable(out, rd))
 		case textprotoFormat:
 			entries := &spb.Entries{}
 			failOnErr(rd(func(entry *spb.Entry) error {
@@ -300,6 +423,106 @@ func dedupEntries(rd stream.EntryReader) stream.EntryReader {
 	}
 }
 
+// writeManifest writes m to the --manifest path.
+func writeManifest(m *manifest.Manifest) error {
+	f, err := vfs.Create(context.Background(), *manifestPath)
+	if err != nil {
+		return err
+	}
+	if err := manifest.Write(f, m); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// entryColumns are the columns available for --write_format={csv,tsv}.
+var entryColumns = []tabular.Column[*spb.Entry]{
+	{Name: "source_ticket", Value: func(e *spb.Entry) string { return kytheuri.ToString(e.GetSource()) }},
+	{Name: "source_corpus", Value: func(e *spb.Entry) string { return e.GetSource().GetCorpus() }},
+	{Name: "source_root", Value: func(e *spb.Entry) string { return e.GetSource().GetRoot() }},
+	{Name: "source_path", Value: func(e *spb.Entry) string { return e.GetSource().GetPath() }},
+	{Name: "source_dir", Value: func(e *spb.Entry) string { return dirOf(e.GetSource().GetPath()) }},
+	{Name: "source_language", Value: func(e *spb.Entry) string { return e.GetSource().GetLanguage() }},
+	{Name: "source_signature", Value: func(e *spb.Entry) string { return e.GetSource().GetSignature() }},
+	{Name: "edge_kind", Value: func(e *spb.Entry) string { return e.GetEdgeKind() }},
+	{Name: "target_ticket", Value: func(e *spb.Entry) string {
+		if e.GetTarget() == nil {
+			return ""
+		}
+		return kytheuri.ToString(e.GetTarget())
+	}},
+	{Name: "fact_name", Value: func(e *spb.Entry) string { return e.GetFactName() }},
+	{Name: "fact_value", Value: func(e *spb.Entry) string {
+		// Binary values (e.g. /kythe/code) are not representable as text.
+		if v := e.GetFactValue(); utf8.Valid(v) {
+			return string(v)
+		}
+		return "base64:" + base64.StdEncoding.EncodeToString(e.GetFactValue())
+	}},
+}
+
+// dirOf returns the directory portion of a VName path, or "" if it has none.
+func dirOf(p string) string {
+	if d := path.Dir(p); d != "." {
+		return d
+	}
+	return ""
+}
+
+// writeTable writes the entries of rd to out in the --write_format tabular
+// format.
+func writeTable(out io.Writer, rd stream.EntryReader) error {
+	cols, err := tabular.SelectColumns(entryColumns, tableColumns)
+	if err != nil {
+		return err
+	}
+	format, err := tabular.ParseFormat(*writeFormat)
+	if err != nil {
+		return err
+	}
+	wr := tabular.NewWriter(out, format)
+	if *tableHeader {
+		if err := wr.Write(tabular.ColumnNames(cols)); err != nil {
+			return err
+		}
+	}
+	if err := rd(func(entry *spb.Entry) error {
+		return wr.Write(tabular.Row(cols, entry))
+	}); err != nil {
+		return err
+	}
+	return wr.Flush()
+}
+
+// validateStream returns a reader that passes along only the entries of rd
+// that conform to the schema.  Rejected entries are written to rejects, if it
+// is non-nil, and otherwise logged.
+func validateStream(rd stream.EntryReader, rejects *json.Encoder) stream.EntryReader {
+	return func(f func(*spb.Entry) error) error {
+		var dropped int
+		err := rd(func(e *spb.Entry) error {
+			if verr := validate.Entry(e); verr != nil {
+				dropped++
+				if rejects == nil {
+					log.Warningf("Rejected entry %v: %v", e, verr)
+					return nil
+				}
+				rec, err := protojson.Marshal(e)
+				if err != nil {
+					return fmt.Errorf("encoding rejected entry: %v", err)
+				}
+				return rejects.Encode(rejectedEntry{Entry: rec, Reason: verr.Error()})
+			}
+			return f(e)
+		})
+		if dropped > 0 {
+			log.Warningf("Rejected %d invalid entries", dropped)
+		}
+		return err
+	}
+}
+
 func failOnErr(err error) {
 	if err != nil {
 		log.Fatal(err)
@@ -0,0 +1,243 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary index_coordinator indexes the compilations in a set of .kzip files
+// by sending them to a pool of index_worker processes, and writes the combined
+// delimited entry stream to stdout.
+//
+// The coordinator serves the required inputs of the compilations with the
+// FileDataService on --listen, which the workers must be able to reach at the
+// --advertise address.  Each worker is sent up to --capacity compilations at
+// once.  A worker that cannot be reached is left out of the pool for
+// --cooldown, and the compilations it was given are retried on the others.
+//
+// Example:
+//
+//	index_coordinator --listen :8090 --advertise $(hostname):8090 \
+//	  --workers w1:8080,w2:8080,w3:8080 --capacity 8 --retries 3 \
+//	  --max_failure_percent 1 --report report.json shard-*.kzip > entries
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"kythe.io/kythe/go/platform/analysis/driver"
+	"kythe.io/kythe/go/platform/analysis/remote"
+	"kythe.io/kythe/go/platform/delimited"
+	"kythe.io/kythe/go/platform/kzip"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	aspb "kythe.io/kythe/proto/analysis_service_go_proto"
+)
+
+var (
+	workers   flagutil.StringList
+	listen    = flag.String("listen", ":8090", "Address on which to serve the FileDataService to workers")
+	advertise = flag.String("advertise", "", "Address at which workers reach the FileDataService (default: this host at the --listen port)")
+	capacity  = flag.Int("capacity", 1, "Maximum number of compilations to send to each worker at once")
+	cooldown  = flag.Duration("cooldown", remote.DefaultCooldown, "How long to leave an unreachable worker out of the pool")
+	timeout   = flag.Duration("timeout", 0, "Maximum wall time for each compilation (0 means no limit)")
+	retries   = flag.Int("retries", 3, "Number of times to retry a compilation after a transient failure")
+	backoff   = flag.Duration("retry_backoff", time.Second, "Delay before the first retry of a compilation, doubled for each later retry")
+	budget    = flag.Float64("max_failure_percent", 100, "Fail the run if more than this percentage of compilations fail")
+	report    = flag.String("report", "", "If set, write a JSON report of the run and its failed compilations to this path")
+)
+
+func init() {
+	flag.Var(&workers, "workers", "Comma-separated addresses of the index_worker processes (required)")
+	flag.Usage = flagutil.SimpleUsage(
+		"Index the compilations in the given .kzip files on a pool of remote workers",
+		"--workers addr,...", "[--listen addr]", "[--capacity n]", "<kzip-file>...")
+}
+
+// archives is an analysis.Fetcher over a set of open .kzip files.
+type archives []*kzip.Reader
+
+// Fetch implements the analysis.Fetcher interface.
+func (a archives) Fetch(_, digest string) ([]byte, error) {
+	for _, r := range a {
+		if data, err := r.ReadAll(digest); err == nil {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("file %s not found", digest)
+}
+
+// open opens the .kzip file at path.  The file remains open until the
+// program exits.
+func open(path string) (*kzip.Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return kzip.NewReader(f, fi.Size())
+}
+
+// unitQueue is a driver.Queue of the compilations sent on a channel, which
+// may be shared by several drivers.
+type unitQueue <-chan driver.Compilation
+
+// Next implements the driver.Queue interface.
+func (q unitQueue) Next(ctx context.Context, f driver.CompilationFunc) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case cu, ok := <-q:
+		if !ok {
+			return driver.ErrEndOfQueue
+		}
+		return f(ctx, cu)
+	}
+}
+
+func main() {
+	flag.Parse()
+	switch {
+	case len(workers) == 0:
+		flagutil.UsageError("missing --workers")
+	case flag.NArg() == 0:
+		flagutil.UsageError("no .kzip files given")
+	case *capacity <= 0:
+		flagutil.UsageError("--capacity must be positive")
+	}
+
+	var files archives
+	for _, path := range flag.Args() {
+		r, err := open(path)
+		if err != nil {
+			log.Fatalf("Opening %s: %v", path, err)
+		}
+		files = append(files, r)
+	}
+
+	// Serve the required inputs of the compilations to the workers.
+	lis, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatalf("Listening on %s: %v", *listen, err)
+	}
+	fds := *advertise
+	if fds == "" {
+		host, err := os.Hostname()
+		if err != nil {
+			log.Fatalf("Finding host name (use --advertise): %v", err)
+		}
+		fds = net.JoinHostPort(host, fmt.Sprint(lis.Addr().(*net.TCPAddr).Port))
+	}
+	srv := grpc.NewServer()
+	aspb.RegisterFileDataServiceServer(srv, &remote.FileServer{Fetcher: files})
+	go srv.Serve(lis)
+	defer srv.Stop()
+	log.Infof("Serving FileDataService on %s as %s", lis.Addr(), fds)
+
+	pool := &remote.Pool{FileDataService: fds, Cooldown: *cooldown}
+	for _, addr := range workers {
+		conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			log.Fatalf("Connecting to worker %s: %v", addr, err)
+		}
+		defer conn.Close()
+		pool.Add(addr, aspb.NewCompilationAnalyzerClient(conn), *capacity)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	wr := delimited.NewWriter(out)
+	var outMu sync.Mutex
+	sv := &driver.Supervisor{
+		MaxRetries:    *retries,
+		Backoff:       *backoff,
+		FailureBudget: *budget / 100,
+	}
+
+	// Run enough drivers to keep every worker busy, sharing one queue.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue := make(chan driver.Compilation)
+	errs := make(chan error, len(workers)**capacity)
+	var wg sync.WaitGroup
+	for i := 0; i < len(workers)**capacity; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d := &driver.Driver{
+				Analyzer:        pool,
+				AnalysisOptions: driver.AnalysisOptions{Timeout: *timeout},
+				Context:         sv,
+				WriteOutput: func(_ context.Context, o *apb.AnalysisOutput) error {
+					outMu.Lock()
+					defer outMu.Unlock()
+					return wr.Put(o.Value)
+				},
+			}
+			if err := d.Run(ctx, unitQueue(queue)); err != nil {
+				errs <- err
+				cancel()
+			}
+		}()
+	}
+	for i, r := range files {
+		err := r.Scan(func(u *kzip.Unit) error {
+			select {
+			case queue <- driver.Compilation{Unit: u.Proto, UnitDigest: u.Digest}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil && ctx.Err() == nil {
+			log.Fatalf("Reading %s: %v", flag.Arg(i), err)
+		}
+	}
+	close(queue)
+	wg.Wait()
+	close(errs)
+	err = <-errs
+	if ferr := out.Flush(); err == nil {
+		err = ferr
+	}
+
+	r := sv.Report()
+	log.Infof("Indexed %d compilations (%d failed, %d retries)", r.Succeeded, r.Failed, r.Retries)
+	for _, ws := range pool.Stats() {
+		log.Infof("Worker %s: %d compilations, %d failures", ws.Name, ws.Analyses, ws.Failures)
+	}
+	if *report != "" {
+		if err := sv.WriteReport(*report); err != nil {
+			log.Errorf("Writing report: %v", err)
+		}
+	}
+	if err == nil {
+		err = sv.Err()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary index_worker serves the CompilationAnalyzer gRPC service, indexing
+// each compilation it receives by running an indexer binary in a sandbox (as
+// by sandbox_indexer).  The required inputs of each compilation are fetched
+// from the FileDataService named in its request, normally that of the
+// index_coordinator that sent it.
+//
+// Example:
+//
+//	index_worker --listen :8080 --indexer /opt/kythe/indexers/cxx_indexer \
+//	  --cpu_time 10m --memory 8GiB -- --ignore_unimplemented
+package main
+
+import (
+	"flag"
+	"net"
+	"os"
+
+	"kythe.io/kythe/go/platform/analysis"
+	"kythe.io/kythe/go/platform/analysis/remote"
+	"kythe.io/kythe/go/platform/analysis/sandbox"
+	"kythe.io/kythe/go/util/datasize"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+
+	"google.golang.org/grpc"
+
+	aspb "kythe.io/kythe/proto/analysis_service_go_proto"
+)
+
+var (
+	listen    = flag.String("listen", ":8080", "Address on which to serve the CompilationAnalyzer service")
+	indexer   = flag.String("indexer", "", "Path of the indexer binary to run (required)")
+	cpuTime   = flag.Duration("cpu_time", 0, "Maximum processor time for each compilation (0 means no limit)")
+	memory    = datasize.Flag("memory", "0", "Maximum address space for each compilation (0 means no limit)")
+	maxOutput = datasize.Flag("max_output", "0", "Maximum bytes of output for each compilation (0 means no limit)")
+	logDir    = flag.String("log_dir", "", "If set, save the standard error of each compilation in this directory")
+)
+
+func init() {
+	flag.Usage = flagutil.SimpleUsage(
+		"Serve the CompilationAnalyzer service by running an indexer in a sandbox",
+		"--indexer path", "[--listen addr]", "[--cpu_time d]", "[--memory sz]", "[-- indexer-args...]")
+}
+
+func main() {
+	flag.Parse()
+	if *indexer == "" {
+		flagutil.UsageError("missing --indexer")
+	}
+	args := flag.Args()
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+
+	w := &remote.Worker{
+		NewAnalyzer: func(f analysis.Fetcher) analysis.CompilationAnalyzer {
+			return &sandbox.Analyzer{
+				Command: *indexer,
+				Args:    args,
+				Fetcher: f,
+				Limits: sandbox.Limits{
+					CPUTime: *cpuTime,
+					Memory:  *memory,
+					Output:  *maxOutput,
+				},
+				LogDir: *logDir,
+			}
+		},
+	}
+	defer w.Close()
+	if *logDir != "" {
+		if err := os.MkdirAll(*logDir, 0755); err != nil {
+			log.Fatalf("Creating log directory: %v", err)
+		}
+	}
+
+	lis, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatalf("Listening on %s: %v", *listen, err)
+	}
+	srv := grpc.NewServer()
+	aspb.RegisterCompilationAnalyzerServer(srv, w)
+	log.Infof("Serving CompilationAnalyzer on %s", lis.Addr())
+	if err := srv.Serve(lis); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package cachecmd provides the kzip commands for storing compilations in a
+// remote cache and retrieving them by unit digest.
+package cachecmd // import "kythe.io/kythe/go/platform/tools/kzip/cachecmd"
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"kythe.io/kythe/go/platform/analysis/resultcache"
+	"kythe.io/kythe/go/platform/kzip"
+	"kythe.io/kythe/go/platform/remotecache"
+	"kythe.io/kythe/go/platform/tools/kzip/flags"
+	"kythe.io/kythe/go/platform/vfs"
+	"kythe.io/kythe/go/util/cmdutil"
+	"kythe.io/kythe/go/util/log"
+
+	"github.com/google/subcommands"
+)
+
+type uploadCommand struct {
+	cmdutil.Info
+
+	cache string
+}
+
+// NewUpload creates a new subcommand for storing the compilations of kzip
+// files in a remote cache.
+func NewUpload() subcommands.Command {
+	return &uploadCommand{
+		Info: cmdutil.NewInfo("upload", "store compilations in a remote cache by unit digest", "--remote_cache url kzip-file*"),
+	}
+}
+
+// SetFlags implements the subcommands interface and provides command-specific
+// flags for uploading compilations.
+func (c *uploadCommand) SetFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.cache, "remote_cache", "", "Base URL of the HTTP cache (Bazel remote cache protocol)")
+}
+
+// Execute implements the subcommands interface and uploads the given files.
+func (c *uploadCommand) Execute(ctx context.Context, fs *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	if c.cache == "" {
+		return c.Fail("Required --remote_cache missing")
+	}
+	client, err := remotecache.New(c.cache)
+	if err != nil {
+		return c.Fail("Error: %v", err)
+	}
+	var total int
+	for _, path := range fs.Args() {
+		n, err := upload(ctx, client, path)
+		total += n
+		if err != nil {
+			return c.Fail("Error uploading %s: %v", path, err)
+		}
+	}
+	log.InfoContextf(ctx, "Stored %d compilations in %v", total, client)
+	return subcommands.ExitSuccess
+}
+
+func upload(ctx context.Context, client *remotecache.Client, path string) (int, error) {
+	f, err := vfs.Open(ctx, path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	stat, err := vfs.Stat(ctx, path)
+	if err != nil {
+		return 0, err
+	}
+	rd, err := kzip.NewReader(f, stat.Size())
+	if err != nil {
+		return 0, err
+	}
+	return remotecache.PutUnits(ctx, client, rd)
+}
+
+type downloadCommand struct {
+	cmdutil.Info
+
+	cache        string
+	output       string
+	digestList   string
+	allowMissing bool
+	encoding     flags.EncodingFlag
+}
+
+// NewDownload creates a new subcommand for retrieving compilations from a
+// remote cache by unit digest.
+func NewDownload() subcommands.Command {
+	return &downloadCommand{
+		Info:     cmdutil.NewInfo("download", "retrieve compilations from a remote cache by unit digest", "--remote_cache url --output path unit-digest*"),
+		encoding: flags.EncodingFlag{Encoding: kzip.DefaultEncoding()},
+	}
+}
+
+// SetFlags implements the subcommands interface and provides command-specific
+// flags for downloading compilations.
+func (c *downloadCommand) SetFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.cache, "remote_cache", "", "Base URL of the HTTP cache (Bazel remote cache protocol)")
+	fs.StringVar(&c.output, "output", "", "Path to output kzip file")
+	fs.StringVar(&c.digestList, "digest_list", "", "Path to a newline-delimited list of unit digests to retrieve. If '-' is specified, the list is read from stdin")
+	fs.BoolVar(&c.allowMissing, "allow_missing", false, "Do not fail if some compilations are not in the cache")
+	fs.Var(&c.encoding, "encoding", "Encoding to use on output, one of JSON, PROTO, or ALL")
+}
+
+// Execute implements the subcommands interface and downloads the requested
+// compilations.
+func (c *downloadCommand) Execute(ctx context.Context, fs *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	if c.cache == "" {
+		return c.Fail("Required --remote_cache missing")
+	} else if c.output == "" {
+		return c.Fail("Required --output path missing")
+	}
+	client, err := remotecache.New(c.cache)
+	if err != nil {
+		return c.Fail("Error: %v", err)
+	}
+	digests := fs.Args()
+	if c.digestList != "" {
+		list, err := readList(c.digestList)
+		if err != nil {
+			return c.Fail("Error reading digest list: %v", err)
+		}
+		digests = append(digests, list...)
+	}
+
+	dir, file := filepath.Split(c.output)
+	if dir == "" {
+		dir = "."
+	}
+	tmpOut, err := vfs.CreateTempFile(ctx, dir, file)
+	if err != nil {
+		return c.Fail("Error creating temp output: %v", err)
+	}
+	tmpName := tmpOut.Name()
+	defer func() {
+		if tmpOut != nil {
+			tmpOut.Close()
+			vfs.Remove(ctx, tmpName)
+		}
+	}()
+	wr, err := kzip.NewWriteCloser(tmpOut, kzip.WithEncoding(c.encoding.Encoding))
+	if err != nil {
+		return c.Fail("Error creating writer: %v", err)
+	}
+	var missing int
+	for _, digest := range digests {
+		if err := remotecache.GetUnit(ctx, client, wr, digest); err == resultcache.ErrNotFound {
+			log.WarningContextf(ctx, "Compilation %s not in cache", digest)
+			missing++
+		} else if err != nil {
+			wr.Close()
+			return c.Fail("Error retrieving %s: %v", digest, err)
+		}
+	}
+	if err := wr.Close(); err != nil {
+		return c.Fail("Error closing writer: %v", err)
+	}
+	tmpOut = nil
+	if missing > 0 && !c.allowMissing {
+		vfs.Remove(ctx, tmpName)
+		return c.Fail("%d of %d compilations not in cache", missing, len(digests))
+	}
+	if err := vfs.Rename(ctx, tmpName, c.output); err != nil {
+		vfs.Remove(ctx, tmpName)
+		return c.Fail("Error renaming tmp to output: %v", err)
+	}
+	log.InfoContextf(ctx, "Retrieved %d compilations from %v", len(digests)-missing, client)
+	return subcommands.ExitSuccess
+}
+
+// readList returns the non-blank lines of the file at path, or of stdin if
+// path is "-".
+func readList(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var lines []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, s.Err()
+}
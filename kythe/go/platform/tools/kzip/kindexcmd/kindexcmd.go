@@ -0,0 +1,175 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package kindexcmd provides the kzip commands for converting between legacy
+// .kindex files and kzip archives.
+package kindexcmd // import "kythe.io/kythe/go/platform/tools/kzip/kindexcmd"
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"kythe.io/kythe/go/platform/kindex"
+	"kythe.io/kythe/go/platform/kzip"
+	"kythe.io/kythe/go/platform/tools/kzip/flags"
+	"kythe.io/kythe/go/platform/vfs"
+	"kythe.io/kythe/go/util/cmdutil"
+	"kythe.io/kythe/go/util/log"
+
+	"github.com/google/subcommands"
+)
+
+type fromKIndexCommand struct {
+	cmdutil.Info
+
+	output   string
+	encoding flags.EncodingFlag
+}
+
+// NewFromKIndex creates a new subcommand for converting .kindex files into a
+// kzip archive.
+func NewFromKIndex() subcommands.Command {
+	return &fromKIndexCommand{
+		Info: cmdutil.NewInfo("fromkindex", "convert .kindex files to a kzip archive", `--output path kindex-file*
+
+Convert each of the given .kindex files into a single kzip archive written to
+--output.  Compilation units are added in the order given on the command line.
+`),
+		encoding: flags.EncodingFlag{Encoding: kzip.DefaultEncoding()},
+	}
+}
+
+// SetFlags implements the subcommands interface and provides command-specific
+// flags for converting .kindex files.
+func (c *fromKIndexCommand) SetFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.output, "output", "", "Path to output kzip file (required)")
+	fs.Var(&c.encoding, "encoding", "Encoding to use on output, one of JSON, PROTO, or ALL")
+}
+
+// Execute implements the subcommands interface and converts the given files.
+func (c *fromKIndexCommand) Execute(ctx context.Context, fs *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	if c.output == "" {
+		return c.Fail("Required --output path missing")
+	}
+	out, err := vfs.Create(ctx, c.output)
+	if err != nil {
+		return c.Fail("Error creating output: %v", err)
+	}
+	wr, err := kzip.NewWriteCloser(out, kzip.WithEncoding(c.encoding.Encoding))
+	if err != nil {
+		out.Close()
+		return c.Fail("Error creating writer: %v", err)
+	}
+	for _, path := range fs.Args() {
+		if err := addKIndex(ctx, wr, path); err != nil {
+			wr.Close()
+			return c.Fail("Error converting %q: %v", path, err)
+		}
+	}
+	if err := wr.Close(); err != nil {
+		return c.Fail("Error closing output: %v", err)
+	}
+	return subcommands.ExitSuccess
+}
+
+func addKIndex(ctx context.Context, wr *kzip.Writer, path string) error {
+	f, err := vfs.Open(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	comp, err := kindex.Read(f)
+	if err != nil {
+		return err
+	}
+	digest, err := kindex.AddToKZip(wr, comp)
+	if err == kzip.ErrUnitExists {
+		log.InfoContextf(ctx, "Skipping duplicate compilation %s from %q", digest, path)
+		return nil
+	}
+	return err
+}
+
+type toKIndexCommand struct {
+	cmdutil.Info
+
+	outputDir string
+}
+
+// NewToKIndex creates a new subcommand for converting a kzip archive into
+// .kindex files.
+func NewToKIndex() subcommands.Command {
+	return &toKIndexCommand{
+		Info: cmdutil.NewInfo("tokindex", "convert a kzip archive to .kindex files", `--output_dir path kzip-file*
+
+Write each compilation unit stored in the given kzip archives to a separate
+.kindex file in --output_dir, named by the unit's digest.  The path of each
+file written is printed to stdout in the order the units are stored, so that
+the list may be used to reconstruct an equivalent archive with fromkindex.
+`),
+	}
+}
+
+// SetFlags implements the subcommands interface and provides command-specific
+// flags for converting kzip archives.
+func (c *toKIndexCommand) SetFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.outputDir, "output_dir", "", "Path to output directory (required)")
+}
+
+// Execute implements the subcommands interface and converts the given archives.
+func (c *toKIndexCommand) Execute(ctx context.Context, fs *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	if c.outputDir == "" {
+		return c.Fail("Required --output_dir path missing")
+	}
+	if err := vfs.MkdirAll(ctx, c.outputDir, 0755); err != nil {
+		return c.Fail("Error creating output directory: %v", err)
+	}
+	for _, path := range fs.Args() {
+		if err := c.writeKIndices(ctx, path); err != nil {
+			return c.Fail("Error converting %q: %v", path, err)
+		}
+	}
+	return subcommands.ExitSuccess
+}
+
+func (c *toKIndexCommand) writeKIndices(ctx context.Context, path string) error {
+	f, err := vfs.Open(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return kzip.Scan(f, func(r *kzip.Reader, u *kzip.Unit) error {
+		comp, err := kindex.FromKZip(r, u)
+		if err != nil {
+			return err
+		}
+		out := filepath.Join(c.outputDir, u.Digest+kindex.Extension)
+		w, err := vfs.Create(ctx, out)
+		if err != nil {
+			return err
+		}
+		if err := kindex.Write(w, comp); err != nil {
+			w.Close()
+			return err
+		} else if err := w.Close(); err != nil {
+			return err
+		}
+		fmt.Println(out)
+		return nil
+	})
+}
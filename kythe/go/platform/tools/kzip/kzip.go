@@ -20,6 +20,16 @@
 //
 //	# Merge 5 kzip archives into a single file.
 //	kzip merge --output output.kzip in{0,1,2,3,4}.kzip
+//
+//	# Store each file shared by per-compilation archives once per 2GiB shard.
+//	kzip merge --output output.kzip --max_shard_size 2GiB extracted/*.kzip
+//
+//	# Convert legacy .kindex files into a kzip archive.
+//	kzip fromkindex --output output.kzip *.kindex
+//
+//	# Share compilations between machines through a Bazel remote cache.
+//	kzip upload --remote_cache http://cache:8080 extracted/*.kzip
+//	kzip download --remote_cache http://cache:8080 --output units.kzip $DIGEST
 package main
 
 import (
@@ -27,9 +37,11 @@ import (
 	"flag"
 	"os"
 
+	"kythe.io/kythe/go/platform/tools/kzip/cachecmd"
 	"kythe.io/kythe/go/platform/tools/kzip/createcmd"
 	"kythe.io/kythe/go/platform/tools/kzip/filtercmd"
 	"kythe.io/kythe/go/platform/tools/kzip/infocmd"
+	"kythe.io/kythe/go/platform/tools/kzip/kindexcmd"
 	"kythe.io/kythe/go/platform/tools/kzip/mergecmd"
 	"kythe.io/kythe/go/platform/tools/kzip/metadatacmd"
 	"kythe.io/kythe/go/platform/tools/kzip/viewcmd"
@@ -38,9 +50,13 @@ import (
 )
 
 func init() {
+	subcommands.Register(cachecmd.NewDownload(), "")
+	subcommands.Register(cachecmd.NewUpload(), "")
 	subcommands.Register(createcmd.New(), "")
 	subcommands.Register(filtercmd.New(), "")
 	subcommands.Register(infocmd.New(), "")
+	subcommands.Register(kindexcmd.NewFromKIndex(), "")
+	subcommands.Register(kindexcmd.NewToKIndex(), "")
 	subcommands.Register(mergecmd.New(), "")
 	subcommands.Register(metadatacmd.New(), "")
 	subcommands.Register(viewcmd.New(), "")
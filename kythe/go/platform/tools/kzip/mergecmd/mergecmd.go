@@ -15,6 +15,13 @@
  */
 
 // Package mergecmd provides the kzip command for merging archives.
+//
+// Extractors that write one archive per compilation store a copy of every
+// required input in each archive, so a header included by a thousand
+// translation units is stored a thousand times.  Merging such archives stores
+// each distinct file content once per output archive; with --max_shard_size,
+// the output is split into several archives of bounded size.  The merge
+// reports how much duplication it removed.
 package mergecmd // import "kythe.io/kythe/go/platform/tools/kzip/mergecmd"
 
 import (
@@ -22,15 +29,16 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"kythe.io/kythe/go/platform/kcd/kythe"
 	"kythe.io/kythe/go/platform/kzip"
 	"kythe.io/kythe/go/platform/tools/kzip/flags"
 	"kythe.io/kythe/go/platform/vfs"
 	"kythe.io/kythe/go/util/cmdutil"
+	"kythe.io/kythe/go/util/datasize"
 	"kythe.io/kythe/go/util/log"
 
 	"bitbucket.org/creachadair/stringset"
@@ -47,6 +55,7 @@ type mergeCommand struct {
 	recursive          bool
 	ignoreDuplicateCUs bool
 	rules              vnameRules
+	maxShardSize       datasize.Size
 
 	unitsBeforeFiles bool
 }
@@ -54,7 +63,7 @@ type mergeCommand struct {
 // New creates a new subcommand for merging kzip files.
 func New() subcommands.Command {
 	return &mergeCommand{
-		Info:     cmdutil.NewInfo("merge", "merge kzip files", "--output path kzip-file*"),
+		Info:     cmdutil.NewInfo("merge", "merge kzip files", "--output path [--max_shard_size sz] kzip-file*"),
 		encoding: flags.EncodingFlag{Encoding: kzip.DefaultEncoding()},
 	}
 }
@@ -62,7 +71,7 @@ func New() subcommands.Command {
 // SetFlags implements the subcommands interface and provides command-specific flags
 // for merging kzip files.
 func (c *mergeCommand) SetFlags(fs *flag.FlagSet) {
-	fs.StringVar(&c.output, "output", "", "Path to output kzip file")
+	fs.StringVar(&c.output, "output", "", "Path to output kzip file; with --max_shard_size, shards are named by inserting -NNNNN before the extension")
 	fs.StringVar(&c.inputFileList, "input_file_list", "", "Path to a newline-delimited text file containing a list of input kzip files. If '-' is specified, the file list is read from stdin")
 	fs.BoolVar(&c.append, "append", false, "Whether to additionally merge the contents of the existing output file, if it exists")
 	fs.Var(&c.encoding, "encoding", "Encoding to use on output, one of JSON, PROTO, or ALL")
@@ -70,31 +79,19 @@ func (c *mergeCommand) SetFlags(fs *flag.FlagSet) {
 	fs.Var(&c.rules, "rules", "VName rules to apply while merging (optional)")
 	fs.BoolVar(&c.ignoreDuplicateCUs, "ignore_duplicate_cus", false, "Do not fail if we try to add the same CU twice")
 	fs.BoolVar(&c.unitsBeforeFiles, "experimental_write_units_first", false, "When writing the kzip file, puts CU entries before files")
+	datasize.FlagVar(fs, &c.maxShardSize, "max_shard_size", 0, "Start a new output shard when the file contents of the current one would exceed this size (0 means a single output)")
 }
 
 // Execute implements the subcommands interface and merges the provided files.
 func (c *mergeCommand) Execute(ctx context.Context, fs *flag.FlagSet, _ ...any) subcommands.ExitStatus {
 	if c.output == "" {
 		return c.Fail("Required --output path missing")
+	} else if c.append && c.maxShardSize > 0 {
+		return c.Fail("--append cannot be combined with --max_shard_size")
 	}
-	opt := kzip.WithEncoding(c.encoding.Encoding)
-	dir, file := filepath.Split(c.output)
-	if dir == "" {
-		dir = "."
-	}
-	tmpOut, err := vfs.CreateTempFile(ctx, dir, file)
-	if err != nil {
-		return c.Fail("Error creating temp output: %v", err)
-	}
-	tmpName := tmpOut.Name()
-	defer func() {
-		if tmpOut != nil {
-			tmpOut.Close()
-			vfs.Remove(ctx, tmpName)
-		}
-	}()
 
 	var archives []string
+	var err error
 	if c.inputFileList != "" && len(fs.Args()) > 0 {
 		return c.Fail("Specify *either* --input_file_list or positional arguments, but not both")
 	}
@@ -122,37 +119,94 @@ func (c *mergeCommand) Execute(ctx context.Context, fs *flag.FlagSet, _ ...any)
 			}
 		}
 	}
-	if err := c.mergeArchives(ctx, tmpOut, archives, opt); err != nil {
-		return c.Fail("Error merging archives: %v", err)
+
+	m := &merger{
+		output:             c.output,
+		sharded:            c.maxShardSize > 0,
+		maxSize:            int64(c.maxShardSize),
+		opts:               []kzip.WriterOption{kzip.WithEncoding(c.encoding.Encoding)},
+		rules:              &c.rules,
+		ignoreDuplicateCUs: c.ignoreDuplicateCUs,
+		unitsBeforeFiles:   c.unitsBeforeFiles,
 	}
-	if err := vfs.Rename(ctx, tmpName, c.output); err != nil {
-		return c.Fail("Error renaming tmp to output: %v", err)
+	for _, path := range archives {
+		if err := m.mergeArchive(ctx, path); err != nil {
+			m.abort(ctx)
+			return c.Fail("Error merging archives: %v", err)
+		}
 	}
+	if err := m.close(ctx); err != nil {
+		m.abort(ctx)
+		return c.Fail("Error writing output: %v", err)
+	}
+	log.InfoContextf(ctx, "Wrote %d output archive(s): %v", len(m.shards), m.stats)
 	return subcommands.ExitSuccess
 }
 
-func (c *mergeCommand) mergeArchives(ctx context.Context, out io.WriteCloser, archives []string, opts ...kzip.WriterOption) error {
-	wr, err := kzip.NewWriteCloser(out, opts...)
-	if err != nil {
-		out.Close()
-		return fmt.Errorf("error creating writer: %v", err)
-	}
+// stats record the duplication removed by a merger.
+type stats struct {
+	Units          int   // compilations written
+	DuplicateUnits int   // identical compilations dropped
+	Inputs         int   // required inputs of the compilations written
+	Files          int   // file contents written, summed over shards
+	InputBytes     int64 // size of the required inputs, counted once per compilation
+	FileBytes      int64 // size of the file contents written
+}
 
-	filesAdded := stringset.New()
-	for _, path := range archives {
-		if err := c.mergeInto(ctx, wr, path, filesAdded); err != nil {
-			wr.Close()
-			return err
-		}
+func (s stats) String() string {
+	ratio := 0.0
+	if s.FileBytes > 0 {
+		ratio = float64(s.InputBytes) / float64(s.FileBytes)
 	}
+	return fmt.Sprintf("%d compilations (%d duplicates dropped), %d inputs stored as %d files, %s of inputs stored in %s (%.1fx)",
+		s.Units, s.DuplicateUnits, s.Inputs, s.Files,
+		datasize.Size(s.InputBytes).Round(), datasize.Size(s.FileBytes).Round(), ratio)
+}
+
+// A shard is an output archive, written to a temporary file that is renamed
+// to its path once every shard has been written.
+type shard struct{ tmp, path string }
+
+// A merger copies compilations into one or more output archives, storing
+// each distinct file content once per archive.  Compilations are assigned to
+// shards in the order they are added, so compilations read from the same
+// archive, which tend to share inputs, are usually kept together.
+type merger struct {
+	output             string
+	sharded            bool  // whether to write numbered shards rather than output
+	maxSize            int64 // the file content size at which to start a new shard
+	opts               []kzip.WriterOption
+	rules              *vnameRules
+	ignoreDuplicateCUs bool
+	unitsBeforeFiles   bool
 
-	if err := wr.Close(); err != nil {
-		return fmt.Errorf("error closing writer: %v", err)
+	w       *kzip.Writer  // the current shard, or nil
+	files   stringset.Set // digests of the files in the current shard
+	size    int64         // the size of the files in the current shard
+	pending []pendingFile // files to copy once the current archive's units are written
+	units   stringset.Set // digests of every compilation written
+	shards  []shard       // the shards written
+	stats   stats
+}
+
+// A pendingFile is a file of an input archive to be copied into the current
+// shard after the units that require it.
+type pendingFile struct {
+	rd     *kzip.Reader
+	digest string
+}
+
+// shardPath returns the path of the nth output shard.
+func (m *merger) shardPath(n int) string {
+	if !m.sharded {
+		return m.output
 	}
-	return nil
+	ext := filepath.Ext(m.output)
+	return fmt.Sprintf("%s-%05d%s", strings.TrimSuffix(m.output, ext), n, ext)
 }
 
-func (c *mergeCommand) mergeInto(ctx context.Context, wr *kzip.Writer, path string, filesAdded stringset.Set) error {
+// mergeArchive adds the compilations of the kzip file at path.
+func (m *merger) mergeArchive(ctx context.Context, path string) error {
 	f, err := vfs.Open(ctx, path)
 	if err != nil {
 		return fmt.Errorf("error opening archive: %v", err)
@@ -173,63 +227,183 @@ func (c *mergeCommand) mergeInto(ctx context.Context, wr *kzip.Writer, path stri
 	if err != nil {
 		return fmt.Errorf("error creating reader: %v", err)
 	}
+	if err := rd.Scan(func(u *kzip.Unit) error { return m.add(ctx, rd, u) }); err != nil {
+		return err
+	}
+	return m.copyPending()
+}
 
-	if c.unitsBeforeFiles {
-		var requiredDigests []string
-		if err := c.mergeUnitsInto(ctx, wr, rd, func(digest string) error {
-			requiredDigests = append(requiredDigests, digest)
-			return nil
-		}); err != nil {
-			return err
+// add copies the compilation u read from rd into the current shard, first
+// starting a new shard if the files u adds would exceed the size limit.
+func (m *merger) add(ctx context.Context, rd *kzip.Reader, u *kzip.Unit) error {
+	for _, ri := range u.Proto.GetRequiredInput() {
+		if vname, match := m.rules.Apply(ri.GetInfo().GetPath()); match {
+			ri.VName = vname
 		}
-		for _, digest := range requiredDigests {
-			if err := copyFileInto(wr, rd, digest, filesAdded); err != nil {
-				return err
-			}
+	}
+	// TODO(schroederc): duplicate compilations with different revisions
+	unit := kythe.Unit{Proto: u.Proto}
+	unit.Canonicalize()
+	if digest := unit.Digest(); m.units.Contains(digest) {
+		if !m.ignoreDuplicateCUs {
+			return kzip.ErrUnitExists
 		}
+		log.InfoContextf(ctx, "Found duplicate CU: %v", u.Proto.GetDetails())
+		m.stats.DuplicateUnits++
 		return nil
 	}
-	return c.mergeUnitsInto(ctx, wr, rd, func(digest string) error {
-		return copyFileInto(wr, rd, digest, filesAdded)
-	})
-}
 
-func (c *mergeCommand) mergeUnitsInto(ctx context.Context, wr *kzip.Writer, rd *kzip.Reader, f func(digest string) error) error {
-	return rd.Scan(func(u *kzip.Unit) error {
-		for _, ri := range u.Proto.RequiredInput {
-			if err := f(ri.Info.Digest); err != nil {
-				return err
+	// Find the size of the inputs u requires, and of those not yet in the
+	// current shard.
+	var total, added int64
+	sizes := make(map[string]int64)
+	for _, ri := range u.Proto.GetRequiredInput() {
+		digest := ri.GetInfo().GetDigest()
+		n, ok := sizes[digest]
+		if !ok {
+			var err error
+			if n, err = rd.FileSize(digest); err != nil {
+				return fmt.Errorf("required input %q: %v", ri.GetInfo().GetPath(), err)
 			}
-			if vname, match := c.rules.Apply(ri.Info.Path); match {
-				ri.VName = vname
+			sizes[digest] = n
+			if !m.files.Contains(digest) {
+				added += n
 			}
 		}
-		// TODO(schroederc): duplicate compilations with different revisions
-		_, err := wr.AddUnit(u.Proto, u.Index)
-		if c.ignoreDuplicateCUs && err == kzip.ErrUnitExists {
-			log.InfoContextf(ctx, "Found duplicate CU: %v", u.Proto.GetDetails())
-			return nil
+		total += n
+	}
+	if m.w != nil && m.sharded && m.size+added > m.maxSize {
+		if err := m.closeShard(); err != nil {
+			return err
+		}
+	}
+	if m.w == nil {
+		if err := m.openShard(ctx); err != nil {
+			return err
+		}
+		added = 0
+		for _, n := range sizes {
+			added += n
+		}
+	}
+
+	for _, ri := range u.Proto.GetRequiredInput() {
+		digest := ri.GetInfo().GetDigest()
+		if !m.files.Add(digest) {
+			continue
+		}
+		m.stats.Files++
+		if m.unitsBeforeFiles {
+			m.pending = append(m.pending, pendingFile{rd, digest})
+		} else if err := copyFile(m.w, rd, digest); err != nil {
+			return err
 		}
+	}
+	digest, err := m.w.AddUnit(u.Proto, u.Index)
+	if err != nil {
 		return err
-	})
+	}
+	m.units.Add(digest)
+	m.size += added
+	m.stats.FileBytes += added
+	m.stats.Units++
+	m.stats.Inputs += len(u.Proto.GetRequiredInput())
+	m.stats.InputBytes += total
+	return nil
 }
 
-func copyFileInto(wr *kzip.Writer, rd *kzip.Reader, digest string, filesAdded stringset.Set) error {
-	if filesAdded.Add(digest) {
-		r, err := rd.Open(digest)
-		if err != nil {
-			return fmt.Errorf("error opening file: %v", err)
+// copyPending copies the pending files into the current shard.
+func (m *merger) copyPending() error {
+	for _, f := range m.pending {
+		if err := copyFile(m.w, f.rd, f.digest); err != nil {
+			return err
+		}
+	}
+	m.pending = nil
+	return nil
+}
+
+func copyFile(wr *kzip.Writer, rd *kzip.Reader, digest string) error {
+	r, err := rd.Open(digest)
+	if err != nil {
+		return fmt.Errorf("error opening file: %v", err)
+	}
+	if _, err := wr.AddFile(r); err != nil {
+		r.Close()
+		return fmt.Errorf("error adding file: %v", err)
+	} else if err := r.Close(); err != nil {
+		return fmt.Errorf("error closing file: %v", err)
+	}
+	return nil
+}
+
+func (m *merger) openShard(ctx context.Context) error {
+	path := m.shardPath(len(m.shards))
+	dir, file := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := vfs.CreateTempFile(ctx, dir, file)
+	if err != nil {
+		return fmt.Errorf("error creating temp output: %v", err)
+	}
+	w, err := kzip.NewWriteCloser(f, m.opts...)
+	if err != nil {
+		f.Close()
+		vfs.Remove(ctx, f.Name())
+		return fmt.Errorf("error creating writer: %v", err)
+	}
+	if m.units == nil {
+		m.units = stringset.New()
+	}
+	m.w, m.files, m.size = w, stringset.New(), 0
+	m.shards = append(m.shards, shard{tmp: f.Name(), path: path})
+	return nil
+}
+
+func (m *merger) closeShard() error {
+	err := m.copyPending()
+	if cerr := m.w.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("error closing writer: %v", cerr)
+	}
+	m.w = nil
+	return err
+}
+
+// close finishes the current shard and moves every shard to its path.  If no
+// compilations were added, an empty output archive is written, so that the
+// output always exists.
+func (m *merger) close(ctx context.Context) error {
+	if m.w == nil && len(m.shards) == 0 {
+		if err := m.openShard(ctx); err != nil {
+			return err
 		}
-		if _, err := wr.AddFile(r); err != nil {
-			r.Close()
-			return fmt.Errorf("error adding file: %v", err)
-		} else if err := r.Close(); err != nil {
-			return fmt.Errorf("error closing file: %v", err)
+	}
+	if m.w != nil {
+		if err := m.closeShard(); err != nil {
+			return err
+		}
+	}
+	for i, s := range m.shards {
+		if err := vfs.Rename(ctx, s.tmp, s.path); err != nil {
+			m.shards = m.shards[i:] // leave the rest to abort
+			return fmt.Errorf("error renaming tmp to output: %v", err)
 		}
 	}
 	return nil
 }
 
+// abort discards the shards not yet moved to their paths.
+func (m *merger) abort(ctx context.Context) {
+	if m.w != nil {
+		m.w.Close()
+		m.w = nil
+	}
+	for _, s := range m.shards {
+		vfs.Remove(ctx, s.tmp)
+	}
+}
+
 func recurseDirectories(ctx context.Context, archives []string) ([]string, error) {
 	var files []string
 	for _, path := range archives {
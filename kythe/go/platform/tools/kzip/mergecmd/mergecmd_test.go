@@ -0,0 +1,198 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mergecmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/platform/kzip"
+
+	"github.com/google/go-cmp/cmp"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// header is an input shared by every compilation.
+var header = strings.Repeat("#define X\n", 100)
+
+// archive returns a reader for a kzip holding a single compilation of source,
+// which includes header.
+func archive(t *testing.T, source string) *kzip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := kzip.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cu := &apb.CompilationUnit{
+		VName:      &spb.VName{Language: "c++", Signature: source},
+		SourceFile: []string{source},
+	}
+	for path, text := range map[string]string{source: "#include <x.h>\n" + source, "x.h": header} {
+		digest, err := w.AddFile(strings.NewReader(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cu.RequiredInput = append(cu.RequiredInput, &apb.CompilationUnit_FileInput{
+			Info: &apb.FileInfo{Path: path, Digest: digest},
+		})
+	}
+	if _, err := w.AddUnit(cu, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := kzip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// merge adds the compilations of rs to m and returns the paths of the shards
+// written.
+func merge(t *testing.T, m *merger, rs ...*kzip.Reader) []string {
+	t.Helper()
+	ctx := context.Background()
+	m.output = filepath.Join(t.TempDir(), "out.kzip")
+	if m.rules == nil {
+		m.rules = new(vnameRules)
+	}
+	for _, r := range rs {
+		if err := r.Scan(func(u *kzip.Unit) error { return m.add(ctx, r, u) }); err != nil {
+			t.Fatalf("Adding units: %v", err)
+		}
+		if err := m.copyPending(); err != nil {
+			t.Fatalf("Adding files: %v", err)
+		}
+	}
+	if err := m.close(ctx); err != nil {
+		t.Fatalf("Closing output: %v", err)
+	}
+	var paths []string
+	for _, s := range m.shards {
+		paths = append(paths, s.path)
+	}
+	return paths
+}
+
+// contents returns the sorted source files of the compilations in each of paths,
+// after checking that every required input is present.
+func contents(t *testing.T, paths []string) [][]string {
+	t.Helper()
+	var out [][]string
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := kzip.NewReader(f, fi.Size())
+		if err != nil {
+			t.Fatalf("Reading %s: %v", path, err)
+		}
+		var srcs []string
+		if err := r.Scan(func(u *kzip.Unit) error {
+			for _, ri := range u.Proto.GetRequiredInput() {
+				if _, err := r.ReadAll(ri.GetInfo().GetDigest()); err != nil {
+					t.Errorf("%s: input %q missing: %v", path, ri.GetInfo().GetPath(), err)
+				}
+			}
+			srcs = append(srcs, u.Proto.GetSourceFile()...)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(srcs)
+		out = append(out, srcs)
+	}
+	return out
+}
+
+func TestMerge(t *testing.T) {
+	a, b := archive(t, "a.cc"), archive(t, "b.cc")
+	m := &merger{ignoreDuplicateCUs: true}
+	paths := merge(t, m, a, b, archive(t, "c.cc"), a) // a is a duplicate
+
+	if diff := cmp.Diff([][]string{{"a.cc", "b.cc", "c.cc"}}, contents(t, paths)); diff != "" {
+		t.Errorf("Compilations written (-want +got):\n%s", diff)
+	}
+	src := int64(len("#include <x.h>\na.cc"))
+	want := stats{
+		Units:          3,
+		DuplicateUnits: 1,
+		Inputs:         6,
+		Files:          4,
+		InputBytes:     3 * (src + int64(len(header))),
+		FileBytes:      3*src + int64(len(header)),
+	}
+	if diff := cmp.Diff(want, m.stats); diff != "" {
+		t.Errorf("Stats (-want +got):\n%s", diff)
+	}
+}
+
+func TestMergeDuplicate(t *testing.T) {
+	a := archive(t, "a.cc")
+	m := &merger{rules: new(vnameRules)}
+	if err := a.Scan(func(u *kzip.Unit) error { return m.add(context.Background(), a, u) }); err != nil {
+		t.Fatalf("Adding units: %v", err)
+	}
+	err := a.Scan(func(u *kzip.Unit) error { return m.add(context.Background(), a, u) })
+	m.abort(context.Background())
+	if err != kzip.ErrUnitExists {
+		t.Errorf("Adding a duplicate unit: got error %v, want %v", err, kzip.ErrUnitExists)
+	}
+}
+
+func TestMergeSharded(t *testing.T) {
+	for _, unitsFirst := range []bool{false, true} {
+		// Each shard has room for the header and two sources.
+		m := &merger{sharded: true, maxSize: int64(len(header) + 50), unitsBeforeFiles: unitsFirst}
+		paths := merge(t, m, archive(t, "a.cc"), archive(t, "b.cc"), archive(t, "c.cc"))
+
+		if diff := cmp.Diff([][]string{{"a.cc", "b.cc"}, {"c.cc"}}, contents(t, paths)); diff != "" {
+			t.Errorf("Compilations written with units first %v (-want +got):\n%s", unitsFirst, diff)
+		}
+		for i, path := range paths {
+			if want := m.shardPath(i); path != want || !strings.HasSuffix(path, ".kzip") {
+				t.Errorf("Shard %d: got path %q, want %q", i, path, want)
+			}
+		}
+		if got := m.stats.Files; got != 5 {
+			t.Errorf("Files written: got %d, want 5 (the header once per shard)", got)
+		}
+	}
+}
+
+func TestMergeEmpty(t *testing.T) {
+	paths := merge(t, new(merger))
+	if diff := cmp.Diff([][]string{nil}, contents(t, paths)); diff != "" {
+		t.Errorf("Compilations written (-want +got):\n%s", diff)
+	}
+}
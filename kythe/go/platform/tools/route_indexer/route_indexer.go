@@ -0,0 +1,236 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary route_indexer indexes the compilations in a set of .kzip files,
+// sending each to the indexer configured for its language and corpus, and
+// writes the combined delimited entry stream to stdout.  Each indexer is run
+// in a sandbox (as by sandbox_indexer), and compilations are indexed
+// concurrently within the limits set for each language.
+//
+// Example:
+//
+//	route_indexer --config indexers.json --parallelism 16 --log_dir logs \
+//	  java.kzip cxx-*.kzip go.kzip > entries
+//
+// See package kythe.io/kythe/go/platform/analysis/router for the format of
+// the configuration file.  A compilation for which no indexer is configured,
+// or whose indexer fails, contributes no entries and is logged.
+//
+// With --provenance, the provenance recorded on each compilation by its
+// extractor (or given by the KYTHE_PROVENANCE_* environment variables, where
+// the compilation records none) is emitted as facts of each file indexed, and
+// every node emitted is stamped with the ticket of its compilation unit and
+// the KYTHE_PROVENANCE_INVOCATION identifier of the run, if set.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+
+	"kythe.io/kythe/go/platform/analysis"
+	"kythe.io/kythe/go/platform/analysis/provenance"
+	"kythe.io/kythe/go/platform/analysis/router"
+	"kythe.io/kythe/go/platform/delimited"
+	"kythe.io/kythe/go/platform/kzip"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+
+	"golang.org/x/sync/semaphore"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+)
+
+var (
+	configPath  = flag.String("config", "", "Path of the JSON indexer configuration (required)")
+	parallelism = flag.Int("parallelism", runtime.NumCPU(), "Maximum number of compilations to index at once")
+	timeout     = flag.Duration("timeout", 0, "Maximum wall time for each compilation (0 means no limit)")
+	logDir      = flag.String("log_dir", "", "If set, save the standard error of each compilation in this directory")
+	keepGoing   = flag.Bool("keep_going", true, "Continue with the remaining compilations after a failure")
+	withProv    = flag.Bool("provenance", false, "Emit the provenance of each compilation as facts of its files, and its origin as facts of every node")
+)
+
+func init() {
+	flag.Usage = flagutil.SimpleUsage(
+		"Index the compilations in the given .kzip files with the indexer configured for each language",
+		"--config path", "[--parallelism n]", "[--log_dir dir]", "<kzip-file>...")
+}
+
+// archives is an analysis.Fetcher over a set of open .kzip files.
+type archives []*kzip.Reader
+
+// Fetch implements the analysis.Fetcher interface.
+func (a archives) Fetch(_, digest string) ([]byte, error) {
+	for _, r := range a {
+		if data, err := r.ReadAll(digest); err == nil {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("file %s not found", digest)
+}
+
+// open opens the .kzip file at path.  The file remains open until the
+// program exits.
+func open(path string) (*kzip.Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return kzip.NewReader(f, fi.Size())
+}
+
+// counts records the outcomes of the analyses, by language.
+type counts struct {
+	mu        sync.Mutex
+	units     map[string]int
+	failures  map[string]int
+	unrouted  int
+	firstFail error
+}
+
+func (c *counts) add(lang string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if errors.Is(err, router.ErrNoRoute) {
+		c.unrouted++
+	} else {
+		c.units[lang]++
+		if err == nil {
+			return
+		}
+		c.failures[lang]++
+	}
+	if c.firstFail == nil {
+		c.firstFail = err
+	}
+}
+
+func main() {
+	flag.Parse()
+	switch {
+	case *configPath == "":
+		flagutil.UsageError("missing --config")
+	case flag.NArg() == 0:
+		flagutil.UsageError("no .kzip files given")
+	case *parallelism <= 0:
+		flagutil.UsageError("--parallelism must be positive")
+	}
+
+	var files archives
+	for _, path := range flag.Args() {
+		r, err := open(path)
+		if err != nil {
+			log.Fatalf("Opening %s: %v", path, err)
+		}
+		files = append(files, r)
+	}
+
+	config, err := router.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Loading configuration: %v", err)
+	}
+	r, err := config.Router(&router.Options{Fetcher: files, LogDir: *logDir})
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	var analyzer analysis.CompilationAnalyzer = r
+	if *withProv {
+		analyzer = provenance.NewAnalyzer(r, provenance.FromEnv()).StampOrigins(os.Getenv(provenance.InvocationEnv))
+	}
+	if *logDir != "" {
+		if err := os.MkdirAll(*logDir, 0755); err != nil {
+			log.Fatalf("Creating log directory: %v", err)
+		}
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	wr := delimited.NewWriter(out)
+	var outMu sync.Mutex
+	write := func(_ context.Context, o *apb.AnalysisOutput) error {
+		outMu.Lock()
+		defer outMu.Unlock()
+		return wr.Put(o.Value)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := &counts{units: make(map[string]int), failures: make(map[string]int)}
+	sem := semaphore.NewWeighted(int64(*parallelism))
+	var wg sync.WaitGroup
+	for i, kz := range files {
+		err := kz.Scan(func(u *kzip.Unit) error {
+			if err := sem.Acquire(ctx, 1); err != nil {
+				return err
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer sem.Release(1)
+				err := analyze(ctx, analyzer, u, write)
+				if err != nil {
+					log.ErrorContextf(ctx, "Indexing %s: %v", u.Digest, err)
+					if !*keepGoing {
+						cancel()
+					}
+				}
+				c.add(u.Proto.GetVName().GetLanguage(), err)
+			}()
+			return nil
+		})
+		if err != nil && ctx.Err() == nil {
+			log.Fatalf("Reading %s: %v", flag.Arg(i), err)
+		}
+	}
+	wg.Wait()
+	if err := out.Flush(); err != nil {
+		log.Fatalf("Writing output: %v", err)
+	}
+
+	for lang, n := range c.units {
+		log.Infof("Indexed %d %s compilations (%d failed)", n, lang, c.failures[lang])
+	}
+	if c.unrouted > 0 {
+		log.Warningf("%d compilations had no configured indexer", c.unrouted)
+	}
+	if !*keepGoing && c.firstFail != nil {
+		log.Fatal(c.firstFail)
+	}
+}
+
+// analyze indexes one compilation with a, subject to the --timeout.
+func analyze(ctx context.Context, a analysis.CompilationAnalyzer, u *kzip.Unit, write func(context.Context, *apb.AnalysisOutput) error) error {
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	res, err := a.Analyze(ctx, &apb.AnalysisRequest{Compilation: u.Proto}, write)
+	if err != nil {
+		return err
+	} else if res.GetStatus() != apb.AnalysisResult_COMPLETE {
+		return fmt.Errorf("analysis %v: %s", res.GetStatus(), res.GetSummary())
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary sandbox_indexer runs an indexer binary separately for each
+// compilation in a set of .kzip files, each in a private working directory and
+// under resource limits, and writes the combined delimited entry stream to
+// stdout.  A compilation whose indexer fails or exceeds its limits contributes
+// no entries and is logged, but does not stop the remaining compilations.
+//
+// Example:
+//
+//	sandbox_indexer --indexer /opt/kythe/indexers/cxx_indexer \
+//	  --cpu_time 10m --memory 8GiB --max_output 4GiB --log_dir logs \
+//	  shard-*.kzip -- --ignore_unimplemented > entries
+//
+// With --cache_dir, the outputs of each successful compilation are stored by
+// the digest of the compilation, and compilations whose inputs are unchanged
+// since an earlier run are not indexed again.  With --remote_cache, they are
+// stored instead in an HTTP cache (such as a Bazel remote cache) that can be
+// shared by the machines of a CI system; see package remotecache.
+//
+// With --provenance, the provenance recorded on each compilation by its
+// extractor (or given by the KYTHE_PROVENANCE_* environment variables, where
+// the compilation records none) is emitted as facts of each file indexed, and
+// every node emitted is stamped with the ticket of its compilation unit and
+// the KYTHE_PROVENANCE_INVOCATION identifier of the run, if set.
+// Provenance does not affect the cache key of a compilation.
+//
+// With --retries, a compilation whose indexer fails is retried, and with
+// --max_failure_percent the run fails if too many compilations fail, rather
+// than only if --keep_going is false.  The --report flag names a JSON file to
+// which the outcome of the run, and each failed compilation, are written.
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"kythe.io/kythe/go/platform/analysis"
+	"kythe.io/kythe/go/platform/analysis/driver"
+	"kythe.io/kythe/go/platform/analysis/local"
+	"kythe.io/kythe/go/platform/analysis/provenance"
+	"kythe.io/kythe/go/platform/analysis/resultcache"
+	"kythe.io/kythe/go/platform/analysis/sandbox"
+	"kythe.io/kythe/go/platform/delimited"
+	"kythe.io/kythe/go/platform/remotecache"
+	"kythe.io/kythe/go/util/datasize"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+)
+
+var (
+	indexer   = flag.String("indexer", "", "Path of the indexer binary to run (required)")
+	cpuTime   = flag.Duration("cpu_time", 0, "Maximum processor time for each compilation (0 means no limit)")
+	timeout   = flag.Duration("timeout", 0, "Maximum wall time for each compilation (0 means no limit)")
+	memory    = datasize.Flag("memory", "0", "Maximum address space for each compilation (0 means no limit)")
+	maxOutput = datasize.Flag("max_output", "0", "Maximum bytes of output for each compilation (0 means no limit)")
+	logDir    = flag.String("log_dir", "", "If set, save the standard error of each compilation in this directory")
+	keepGoing = flag.Bool("keep_going", true, "Continue with the remaining compilations after a failure")
+	retries   = flag.Int("retries", 0, "Number of times to retry a failed compilation, unless it exceeded --max_output or --timeout")
+	backoff   = flag.Duration("retry_backoff", time.Second, "Delay before the first retry of a compilation, doubled for each later retry")
+	retryTime = flag.Bool("retry_timeouts", false, "Treat compilations that exceed --timeout as transient failures")
+	budget    = flag.Float64("max_failure_percent", 100, "Fail the run if more than this percentage of compilations fail")
+	report    = flag.String("report", "", "If set, write a JSON report of the run and its failed compilations to this path")
+	cacheDir  = flag.String("cache_dir", "", "If set, reuse the outputs of identical compilations stored in this directory")
+	cacheKey  = flag.String("cache_version", "", "Version of the indexer for cache keys (default: a digest of the indexer binary and arguments)")
+	remote    = flag.String("remote_cache", "", "If set, reuse the outputs of identical compilations stored in the HTTP cache at this URL")
+	remoteRO  = flag.Bool("remote_cache_read_only", false, "Read from --remote_cache without storing new outputs")
+	withProv  = flag.Bool("provenance", false, "Emit the provenance of each compilation as facts of its files, and its origin as facts of every node")
+)
+
+func init() {
+	flag.Usage = flagutil.SimpleUsage(
+		"Run an indexer in a sandbox for each compilation in the given .kzip files",
+		"--indexer path", "[--cpu_time d]", "[--memory sz]", "[--max_output sz]",
+		"<kzip-file>...", "[-- indexer-args...]")
+}
+
+// indexerVersion returns a digest of the indexer binary and its arguments.
+func indexerVersion(path string, args []string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	for _, arg := range args {
+		fmt.Fprintf(h, "\x00%s", arg)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func main() {
+	flag.Parse()
+	if *indexer == "" {
+		flagutil.UsageError("missing --indexer")
+	} else if *cacheDir != "" && *remote != "" {
+		flagutil.UsageError("--cache_dir and --remote_cache are mutually exclusive")
+	}
+
+	// Separate the .kzip paths from any arguments for the indexer.
+	paths, args := flag.Args(), []string(nil)
+	for i, arg := range paths {
+		if arg == "--" {
+			paths, args = paths[:i], paths[i+1:]
+			break
+		}
+	}
+	if len(paths) == 0 {
+		flagutil.UsageError("no .kzip files given")
+	}
+
+	queue := local.NewFileQueue(paths, nil)
+	var analyzer analysis.CompilationAnalyzer = &sandbox.Analyzer{
+		Command: *indexer,
+		Args:    args,
+		Fetcher: queue,
+		Limits: sandbox.Limits{
+			CPUTime: *cpuTime,
+			Memory:  *memory,
+			Output:  *maxOutput,
+		},
+		LogDir: *logDir,
+	}
+	var store resultcache.Store
+	if *cacheDir != "" {
+		store = resultcache.DirStore(*cacheDir)
+	} else if *remote != "" {
+		c, err := remotecache.New(*remote)
+		if err != nil {
+			log.Fatal(err)
+		}
+		c.ReadOnly = *remoteRO
+		store = c
+	}
+	var cache *resultcache.Analyzer
+	if store != nil {
+		version := *cacheKey
+		if version == "" {
+			path, err := exec.LookPath(*indexer)
+			if err != nil {
+				log.Fatalf("Finding indexer: %v", err)
+			}
+			if version, err = indexerVersion(path, args); err != nil {
+				log.Fatalf("Computing indexer version: %v", err)
+			}
+		}
+		cache = resultcache.New(analyzer, store, version)
+		analyzer = cache
+	}
+	if *withProv {
+		analyzer = provenance.NewAnalyzer(analyzer, provenance.FromEnv()).StampOrigins(os.Getenv(provenance.InvocationEnv))
+	}
+	if *logDir != "" {
+		if err := os.MkdirAll(*logDir, 0755); err != nil {
+			log.Fatalf("Creating log directory: %v", err)
+		}
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	wr := delimited.NewWriter(out)
+	sv := &driver.Supervisor{
+		MaxRetries: *retries,
+		Backoff:    *backoff,
+		Transient: func(err error) bool {
+			// The indexer may have crashed for reasons outside its control, but
+			// its output size is deterministic.
+			return !errors.Is(err, sandbox.ErrOutputLimit)
+		},
+		RetryTimeouts: *retryTime,
+		FailureBudget: *budget / 100,
+		FailFast:      !*keepGoing,
+	}
+	d := &driver.Driver{
+		Analyzer:        analyzer,
+		AnalysisOptions: driver.AnalysisOptions{Timeout: *timeout},
+		Context:         sv,
+		WriteOutput: func(_ context.Context, o *apb.AnalysisOutput) error {
+			return wr.Put(o.Value)
+		},
+	}
+	ctx := context.Background()
+	err := d.Run(ctx, queue)
+	if ferr := out.Flush(); err == nil {
+		err = ferr
+	}
+	r := sv.Report()
+	log.Infof("Indexed %d compilations (%d failed, %d retries)", r.Succeeded, r.Failed, r.Retries)
+	if cache != nil {
+		log.Infof("Cache: %v", cache.Stats())
+	}
+	if *report != "" {
+		if err := sv.WriteReport(*report); err != nil {
+			log.Errorf("Writing report: %v", err)
+		}
+	}
+	if err == nil {
+		err = sv.Err()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -18,13 +18,18 @@
 package cli // import "kythe.io/kythe/go/services/cli"
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
+	"kythe.io/kythe/go/services/cli/format"
 	"kythe.io/kythe/go/services/filetree"
 	"kythe.io/kythe/go/services/graph"
 	"kythe.io/kythe/go/services/web"
@@ -37,19 +42,28 @@ import (
 )
 
 // DisplayJSON is true if the user wants all service responses to be displayed
-// as JSON (using the PrintJSON and PrintJSONMessage functions).
+// as JSON (using the PrintJSON and PrintJSONMessage functions).  It is true
+// exactly when OutputFormat is format.JSON.
 var DisplayJSON bool
 
+// OutputFormat is the format in which commands display their results.  The
+// --json flag is shorthand for --format=json.
+var OutputFormat = format.Text
+
 var (
 	logRequests = flag.Bool("log_requests", false, "Log all requests to stderr as JSON")
-	out         = os.Stdout
+	batch       = flag.Bool("batch", false, "Run the command once for each line of standard input, taking the line as its arguments, and write one JSON result per line")
+
+	in  io.Reader = os.Stdin
+	out io.Writer = os.Stdout
 )
 
 var jsonMarshaler = web.JSONMarshaler
 
 func init() {
 	jsonMarshaler.Options.Indent = "  "
-	flag.BoolVar(&DisplayJSON, "json", DisplayJSON, "Display results as JSON")
+	flag.BoolVar(&DisplayJSON, "json", DisplayJSON, "Display results as JSON (shorthand for --format=json)")
+	flag.Var(&OutputFormat, "format", "Output format: text, json, proto (length-delimited), table, tsv, or csv")
 }
 
 // API contains access points the CLI's backend services.
@@ -63,21 +77,41 @@ type API struct {
 // Execute registers all Kythe CLI commands to subcommands.DefaultCommander and
 // executes it with the given API.
 func Execute(ctx context.Context, api API) subcommands.ExitStatus {
+	if DisplayJSON {
+		OutputFormat = format.JSON
+	}
+	if *batch {
+		if OutputFormat != format.Text && OutputFormat != format.JSON {
+			log.Errorf("--batch does not support --format=%s", OutputFormat)
+			return subcommands.ExitUsageError
+		}
+		OutputFormat = format.JSON
+		jsonMarshaler.Options.Indent = ""
+	}
+	DisplayJSON = OutputFormat == format.JSON
+
+	subcommands.ImportantFlag("format")
 	subcommands.ImportantFlag("json")
 	subcommands.ImportantFlag("log_requests")
+	subcommands.ImportantFlag("batch")
 	subcommands.Register(subcommands.HelpCommand(), "usage")
 	subcommands.Register(subcommands.FlagsCommand(), "usage")
 	subcommands.Register(subcommands.CommandsCommand(), "usage")
 
 	RegisterCommand(&nodesCommand{}, "graph")
 	RegisterCommand(&edgesCommand{}, "graph")
+	RegisterCommand(&walkCommand{}, "graph")
 
+	RegisterCommand(&doctorCommand{}, "")
 	RegisterCommand(&identCommand{}, "")
 	RegisterCommand(&lsCommand{}, "")
+	RegisterCommand(&shellCommand{}, "")
 
 	RegisterCommand(&decorCommand{}, "xrefs")
+	RegisterCommand(&defsCommand{}, "xrefs")
 	RegisterCommand(&diagnosticsCommand{}, "xrefs")
 	RegisterCommand(&docsCommand{}, "xrefs")
+	RegisterCommand(&refsCommand{}, "xrefs")
 	RegisterCommand(&sourceCommand{}, "xrefs")
 	RegisterCommand(&xrefsCommand{}, "xrefs")
 
@@ -85,12 +119,14 @@ func Execute(ctx context.Context, api API) subcommands.ExitStatus {
 }
 
 // RegisterCommand adds a KytheCommand to the list of subcommands for the
-// specified group.
+// specified group.  It may also be run from the shell command.
 func RegisterCommand(c KytheCommand, group string) {
 	cmd := &commandWrapper{c}
 	subcommands.Register(cmd, group)
+	commands[c.Name()] = c
 	for _, a := range c.Aliases() {
 		subcommands.Alias(a, cmd)
+		commands[a] = c
 	}
 }
 
@@ -107,13 +143,98 @@ func (w *commandWrapper) Execute(ctx context.Context, f *flag.FlagSet, args ...a
 	if !ok {
 		return subcommands.ExitUsageError
 	}
-	if err := w.Run(ctx, f, api); err != nil {
+	run := w.Run
+	if *batch {
+		run = w.runBatch
+	}
+	if err := run(ctx, f, api); err != nil {
 		log.ErrorContextf(ctx, "%v", err)
 		return subcommands.ExitFailure
 	}
 	return subcommands.ExitSuccess
 }
 
+// batchResult is the line of output for a query run in batch mode.
+type batchResult struct {
+	Query   string            `json:"query"`
+	Results []json.RawMessage `json:"results,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// runBatch runs the command once for each non-blank line of input, with the
+// words of the line as its arguments and the flags given in f.  For each
+// query it writes a line of JSON holding the JSON values written by the
+// command, or its error.  A failed query does not stop the batch.
+func (w *commandWrapper) runBatch(ctx context.Context, f *flag.FlagSet, api API) error {
+	if f.NArg() > 0 {
+		return errors.New("with --batch, arguments are read from standard input")
+	} else if w.Name() == "shell" {
+		return errors.New("the shell cannot be run with --batch")
+	}
+
+	stdout := out
+	defer func() { out = stdout }()
+	enc := json.NewEncoder(stdout)
+	sc := bufio.NewScanner(in)
+	sc.Buffer(nil, 1<<20)
+	var queries, failed int
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		queries++
+		res := &batchResult{Query: line}
+		var buf bytes.Buffer
+		out = &buf
+		if err := w.runQuery(ctx, api, line); err != nil {
+			res.Error = err.Error()
+		} else if res.Results, err = decodeJSONValues(buf.Bytes()); err != nil {
+			res.Error = fmt.Sprintf("command output is not JSON: %v", err)
+		}
+		if res.Error != "" {
+			failed++
+		}
+		if err := enc.Encode(res); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("reading queries: %v", err)
+	} else if failed > 0 {
+		return fmt.Errorf("%d of %d queries failed", failed, queries)
+	}
+	return nil
+}
+
+// runQuery runs the command with the words of line as its arguments.
+func (w *commandWrapper) runQuery(ctx context.Context, api API, line string) error {
+	args, err := splitArgs(line)
+	if err != nil {
+		return err
+	}
+	f := flag.NewFlagSet(w.Name(), flag.ContinueOnError)
+	if err := f.Parse(append([]string{"--"}, args...)); err != nil {
+		return err
+	}
+	return w.Run(ctx, f, api)
+}
+
+// decodeJSONValues splits data into the JSON values it contains.
+func decodeJSONValues(data []byte) ([]json.RawMessage, error) {
+	var vals []json.RawMessage
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var v json.RawMessage
+		if err := dec.Decode(&v); err == io.EOF {
+			return vals, nil
+		} else if err != nil {
+			return nil, err
+		}
+		vals = append(vals, v)
+	}
+}
+
 // A KytheCommand is a type-safe version of the subcommands.Command interface.
 type KytheCommand interface {
 	Name() string
@@ -141,6 +262,26 @@ func LogRequest(req proto.Message) {
 	}
 }
 
+// displayReply displays reply in OutputFormat and reports whether it did so.
+// For format.Text it does nothing, leaving the command to display reply in
+// its own way.  For the tabular formats, reply is displayed as the table
+// returned by rows or, if rows is nil, as the fields of reply.
+func displayReply(reply proto.Message, rows func() *format.Table) (bool, error) {
+	switch {
+	case OutputFormat == format.Text:
+		return false, nil
+	case OutputFormat == format.JSON:
+		return true, PrintJSONMessage(reply)
+	case OutputFormat.Tabular():
+		if rows == nil {
+			return true, format.Flatten(reply).Write(out, OutputFormat)
+		}
+		return true, rows().Write(out, OutputFormat)
+	default:
+		return true, format.WriteMessage(out, OutputFormat, reply)
+	}
+}
+
 // PrintJSONMessage prints the given proto message to the console.  This should
 // be called whenever the DisplayJSON flag is true.
 func PrintJSONMessage(resp proto.Message) error { return jsonMarshaler.Marshal(out, resp) }
@@ -26,6 +26,7 @@ import (
 	"strings"
 
 	"kythe.io/kythe/go/platform/vfs"
+	"kythe.io/kythe/go/services/cli/format"
 	"kythe.io/kythe/go/services/graph"
 	"kythe.io/kythe/go/util/flagutil"
 	"kythe.io/kythe/go/util/kytheuri"
@@ -75,7 +76,7 @@ func (c *baseDecorCommand) SetFlags(flag *flag.FlagSet) {
 }
 
 func (c baseDecorCommand) fileTicketArg(flag *flag.FlagSet) (string, error) {
-	if flag.NArg() < 0 {
+	if flag.NArg() == 0 {
 		return "", errors.New("no file given")
 	}
 	file := flag.Arg(0)
@@ -196,20 +197,33 @@ func (c decorCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) erro
 }
 
 func (c decorCommand) displayDecorations(decor *xpb.DecorationsReply) error {
-	if DisplayJSON {
-		return PrintJSONMessage(decor)
+	nodes := graph.NodesMap(decor.Nodes)
+	targetKind := func(ticket string) (kind, nodeKind, subkind string) {
+		nodeKind = factValue(nodes, ticket, facts.NodeKind, "UNKNOWN")
+		subkind = factValue(nodes, ticket, facts.Subkind, "")
+		kind = nodeKind
+		if subkind != "" {
+			kind += "/" + subkind
+		}
+		return kind, nodeKind, subkind
 	}
 
-	nodes := graph.NodesMap(decor.Nodes)
+	if ok, err := displayReply(decor, func() *format.Table {
+		t := format.NewTable("start_line", "start_col", "end_line", "end_col", "kind", "target", "target_kind", "target_definition")
+		for _, ref := range decor.Reference {
+			kind, _, _ := targetKind(ref.TargetTicket)
+			start, end := ref.GetSpan().GetStart(), ref.GetSpan().GetEnd()
+			t.Add(itoa(start.GetLineNumber()), itoa(start.GetColumnOffset()),
+				itoa(end.GetLineNumber()), itoa(end.GetColumnOffset()),
+				ref.Kind, ref.TargetTicket, kind, ref.TargetDefinition)
+		}
+		return t
+	}); ok {
+		return err
+	}
 
 	for _, ref := range decor.Reference {
-		nodeKind := factValue(nodes, ref.TargetTicket, facts.NodeKind, "UNKNOWN")
-		subkind := factValue(nodes, ref.TargetTicket, facts.Subkind, "")
-
-		tgtKind := nodeKind
-		if subkind != "" {
-			tgtKind += "/" + subkind
-		}
+		tgtKind, nodeKind, subkind := targetKind(ref.TargetTicket)
 
 		var targetDef string
 		if ref.TargetDefinition != "" {
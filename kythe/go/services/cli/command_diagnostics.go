@@ -22,6 +22,8 @@ import (
 	"fmt"
 	"strings"
 
+	"kythe.io/kythe/go/services/cli/format"
+
 	cpb "kythe.io/kythe/proto/common_go_proto"
 	xpb "kythe.io/kythe/proto/xref_go_proto"
 )
@@ -54,8 +56,17 @@ func (c diagnosticsCommand) Run(ctx context.Context, flag *flag.FlagSet, api API
 }
 
 func (c diagnosticsCommand) displayDiagnostics(decor *xpb.DecorationsReply) error {
-	if DisplayJSON {
-		return PrintJSONMessage(decor)
+	if ok, err := displayReply(decor, func() *format.Table {
+		t := format.NewTable("start_line", "start_col", "end_line", "end_col", "message", "context_url")
+		for _, d := range decor.Diagnostic {
+			start, end := d.GetSpan().GetStart(), d.GetSpan().GetEnd()
+			t.Add(itoa(start.GetLineNumber()), itoa(start.GetColumnOffset()),
+				itoa(end.GetLineNumber()), itoa(end.GetColumnOffset()),
+				d.Message, d.ContextUrl)
+		}
+		return t
+	}); ok {
+		return err
 	}
 
 	for _, d := range decor.Diagnostic {
@@ -128,8 +128,8 @@ func (c docsCommand) displayDoc(indent string, doc *xpb.DocumentationReply_Docum
 }
 
 func (c docsCommand) displayDocumentation(reply *xpb.DocumentationReply) error {
-	if DisplayJSON {
-		return PrintJSONMessage(reply)
+	if ok, err := displayReply(reply, nil); ok {
+		return err
 	} else if len(reply.Document) == 0 {
 		return nil
 	}
@@ -0,0 +1,301 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kythe.io/kythe/go/services/cli/format"
+	"kythe.io/kythe/go/services/filetree"
+	"kythe.io/kythe/go/services/graph"
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/schema"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+	gpb "kythe.io/kythe/proto/graph_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+// doctorCommand probes the configured server with a sequence of typical
+// requests and reports what is wrong, if anything, and what to do about it.
+type doctorCommand struct {
+	baseKytheCommand
+	file    string
+	slow    time.Duration
+	maxDirs int
+}
+
+func (doctorCommand) Name() string { return "doctor" }
+func (doctorCommand) Synopsis() string {
+	return "check that the server answers typical requests correctly and promptly"
+}
+func (c *doctorCommand) SetFlags(flag *flag.FlagSet) {
+	flag.StringVar(&c.file, "file", "", "Kythe URI of the file to use for sample requests (default: the first file found)")
+	flag.DurationVar(&c.slow, "slow", time.Second, "Latency above which a request is reported as slow")
+	flag.IntVar(&c.maxDirs, "max_dirs", 20, "Maximum number of directories listed in search of a sample file")
+}
+
+// Finding statuses.
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// A finding is the outcome of one of the doctor's checks.
+type finding struct {
+	Check   string        `json:"check"`
+	Status  string        `json:"status"`
+	Latency time.Duration `json:"latency_ns,omitempty"`
+	Detail  string        `json:"detail"`
+	Advice  string        `json:"advice,omitempty"`
+}
+
+// doctor accumulates findings.
+type doctor struct {
+	slow     time.Duration
+	findings []*finding
+}
+
+// time runs f, recording its latency in a finding for check.  If f fails,
+// the finding is a failure with the given advice; otherwise it is reported
+// as ok with the detail returned by f, or as a warning if f was slow.
+func (d *doctor) time(check, advice string, f func() (string, error)) bool {
+	start := time.Now()
+	detail, err := f()
+	fd := &finding{Check: check, Status: doctorOK, Latency: time.Since(start), Detail: detail}
+	if err != nil {
+		fd.Status, fd.Detail, fd.Advice = doctorFail, err.Error(), advice
+	} else if fd.Latency > d.slow {
+		fd.Status = doctorWarn
+		fd.Advice = fmt.Sprintf("Slower than %v: check the server's load and its distance from you.", d.slow)
+	}
+	d.findings = append(d.findings, fd)
+	return err == nil
+}
+
+func (d *doctor) add(check, status, detail, advice string) {
+	d.findings = append(d.findings, &finding{Check: check, Status: status, Detail: detail, Advice: advice})
+}
+
+func (c doctorCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) error {
+	d := &doctor{slow: c.slow}
+	c.diagnose(ctx, api, d)
+	if err := c.display(d.findings); err != nil {
+		return err
+	}
+	var failed int
+	for _, fd := range d.findings {
+		if fd.Status == doctorFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(d.findings))
+	}
+	return nil
+}
+
+// diagnose runs the checks in order, stopping at the first failure that
+// prevents the checks after it.
+func (c doctorCommand) diagnose(ctx context.Context, api API, d *doctor) {
+	file := c.file
+	if file == "" {
+		var roots *ftpb.CorpusRootsReply
+		if !d.time("corpus_roots", "Check --api or --profile, that the server is running, and that your credentials are accepted.", func() (string, error) {
+			var err error
+			roots, err = api.FileTreeService.CorpusRoots(ctx, &ftpb.CorpusRootsRequest{})
+			if err != nil {
+				return "", err
+			}
+			var n int
+			for _, c := range roots.Corpus {
+				n += len(c.Root)
+			}
+			return fmt.Sprintf("%d corpora, %d roots", len(roots.Corpus), n), nil
+		}) {
+			return
+		} else if len(roots.GetCorpus()) == 0 {
+			d.add("corpus_roots", doctorFail, "the server has no corpora", "The serving data is empty: check that the index was built and written to the serving tables.")
+			return
+		}
+
+		var ok bool
+		if file, ok = c.findFile(ctx, api, d, roots); !ok {
+			return
+		} else if file == "" {
+			d.add("sample_file", doctorFail, "no files found", "The file tree is empty: check that the index includes file nodes, or give --file.")
+			return
+		}
+	}
+
+	var decor *xpb.DecorationsReply
+	if !d.time("decorations", "Check that --file names an indexed file, and the server's logs for errors reading its decorations.", func() (string, error) {
+		var err error
+		decor, err = api.XRefService.Decorations(ctx, &xpb.DecorationsRequest{
+			Location:          &xpb.Location{Ticket: file},
+			SourceText:        true,
+			References:        true,
+			TargetDefinitions: true,
+			Filter:            []string{facts.NodeKind, facts.Subkind},
+		})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s: %d bytes, %d references", file, len(decor.SourceText), len(decor.Reference)), nil
+	}) {
+		return
+	}
+	if len(decor.SourceText) == 0 {
+		d.add("source_text", doctorWarn, "the sample file has no text", "Files were indexed without their text; the source and decor commands will show nothing.")
+	}
+	if len(decor.Reference) == 0 {
+		d.add("references", doctorWarn, "the sample file has no references", "The file may not have been analyzed: check the indexer's logs, or try another --file.")
+		return
+	}
+	c.checkSchema(decor, d)
+
+	target := decor.Reference[0].TargetTicket
+	d.time("cross_references", "Check the server's logs for errors reading cross-references.", func() (string, error) {
+		reply, err := api.XRefService.CrossReferences(ctx, &xpb.CrossReferencesRequest{
+			Ticket:         []string{target},
+			DefinitionKind: xpb.CrossReferencesRequest_BINDING_DEFINITIONS,
+			ReferenceKind:  xpb.CrossReferencesRequest_ALL_REFERENCES,
+			PageSize:       10,
+		})
+		if err != nil {
+			return "", err
+		}
+		set := reply.CrossReferences[target]
+		return fmt.Sprintf("%s: %d definitions, %d references", target, len(set.GetDefinition()), len(set.GetReference())), nil
+	})
+	d.time("edges", "Check the server's logs for errors reading edges.", func() (string, error) {
+		reply, err := api.GraphService.Edges(ctx, &gpb.EdgesRequest{Ticket: []string{target}, PageSize: 10})
+		if err != nil {
+			return "", err
+		}
+		var n int
+		for _, es := range reply.EdgeSets {
+			for _, g := range es.Groups {
+				n += len(g.Edge)
+			}
+		}
+		return fmt.Sprintf("%s: %d edges", target, n), nil
+	})
+}
+
+// findFile searches the first corpus root breadth-first for a sample file.
+// It reports false if a listing failed.
+func (c doctorCommand) findFile(ctx context.Context, api API, d *doctor, roots *ftpb.CorpusRootsReply) (string, bool) {
+	corpus := roots.Corpus[0]
+	var root string
+	if len(corpus.Root) > 0 {
+		root = corpus.Root[0]
+	}
+	var file string
+	ok := d.time("directory", "Check the server's logs for errors reading the file tree.", func() (string, error) {
+		dirs := []string{""}
+		for n := 1; n <= c.maxDirs && len(dirs) > 0; n++ {
+			dir := dirs[0]
+			dirs = dirs[1:]
+			reply, err := api.FileTreeService.Directory(ctx, &ftpb.DirectoryRequest{Corpus: corpus.Name, Root: root, Path: filetree.CleanDirPath(dir)})
+			if err != nil {
+				return "", fmt.Errorf("listing %s: %v", path.Join(corpus.Name, root, dir), err)
+			}
+			for _, e := range reply.Entry {
+				if e.Kind == ftpb.DirectoryReply_FILE {
+					uri := kytheuri.URI{Corpus: corpus.Name, Root: root, Path: path.Join(dir, e.Name)}
+					file = uri.String()
+					return fmt.Sprintf("found %s after listing %d directories", file, n), nil
+				}
+				dirs = append(dirs, path.Join(dir, e.Name))
+			}
+		}
+		return "no file found", nil
+	})
+	return file, ok
+}
+
+// checkSchema reports the edge and node kinds in decor unknown to this
+// client's schema, which suggest that the index was built by a newer
+// version of Kythe.
+func (c doctorCommand) checkSchema(decor *xpb.DecorationsReply, d *doctor) {
+	unknown := make(map[string]bool)
+	for _, ref := range decor.Reference {
+		if kind := edges.Canonical(ref.Kind); schema.EdgeKind(kind) == 0 {
+			unknown["edge kind "+kind] = true
+		}
+	}
+	for _, node := range graph.NodesMap(decor.Nodes) {
+		if kind := string(node[facts.NodeKind]); kind != "" && schema.NodeKind(kind) == 0 {
+			unknown["node kind "+kind] = true
+		}
+		if sub := string(node[facts.Subkind]); sub != "" && schema.Subkind(sub) == 0 {
+			unknown["subkind "+sub] = true
+		}
+	}
+	if len(unknown) == 0 {
+		d.add("schema", doctorOK, "all edge and node kinds in the sample file are known", "")
+		return
+	}
+	names := make([]string, 0, len(unknown))
+	for name := range unknown {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	d.add("schema", doctorWarn, "unknown "+strings.Join(names, ", "),
+		"The index may have been built by a newer version of Kythe than this client: upgrade the kythe tool.")
+}
+
+func (c doctorCommand) display(findings []*finding) error {
+	switch {
+	case DisplayJSON:
+		return PrintJSON(findings)
+	case OutputFormat.Tabular():
+		t := format.NewTable("check", "status", "latency_ms", "detail", "advice")
+		for _, fd := range findings {
+			t.Add(fd.Check, fd.Status, strconv.FormatInt(fd.Latency.Milliseconds(), 10), fd.Detail, fd.Advice)
+		}
+		return t.Write(out, OutputFormat)
+	case OutputFormat != format.Text:
+		return fmt.Errorf("doctor does not support --format=%s", OutputFormat)
+	}
+	for _, fd := range findings {
+		var latency string
+		if fd.Latency > 0 {
+			latency = fd.Latency.Round(time.Millisecond).String()
+		}
+		if _, err := fmt.Fprintf(out, "%-4s  %-16s %7s  %s\n", strings.ToUpper(fd.Status), fd.Check, latency, fd.Detail); err != nil {
+			return err
+		}
+		if fd.Advice != "" {
+			if _, err := fmt.Fprintf(out, "      -> %s\n", fd.Advice); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
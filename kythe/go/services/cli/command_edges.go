@@ -23,8 +23,10 @@ import (
 	"fmt"
 	"html"
 	"sort"
+	"strconv"
 	"strings"
 
+	"kythe.io/kythe/go/services/cli/format"
 	"kythe.io/kythe/go/services/graph"
 	"kythe.io/kythe/go/util/log"
 	"kythe.io/kythe/go/util/schema/edges"
@@ -41,17 +43,22 @@ type edgesCommand struct {
 	countOnly   bool
 	targetsOnly bool
 	edgeKinds   string
+	direction   string
+	targetKinds bool
 	pageToken   string
 	pageSize    int
 }
 
 func (edgesCommand) Name() string     { return "edges" }
-func (edgesCommand) Synopsis() string { return "retrieve outward edges from a node" }
+func (edgesCommand) Synopsis() string { return "retrieve the outgoing and incoming edges of a node" }
+func (edgesCommand) Usage() string    { return "<ticket>..." }
 func (c *edgesCommand) SetFlags(flag *flag.FlagSet) {
 	flag.BoolVar(&c.dotGraph, "graphviz", false, "Print resulting edges as a dot graph")
 	flag.BoolVar(&c.countOnly, "count_only", false, "Only print counts per edge kind")
 	flag.BoolVar(&c.targetsOnly, "targets_only", false, "Only display edge targets")
 	flag.StringVar(&c.edgeKinds, "kinds", "", "Comma-separated list of edge kinds to return (default returns all)")
+	flag.StringVar(&c.direction, "direction", "both", "Direction of edges to return (directions: out, in, or both)")
+	flag.BoolVar(&c.targetKinds, "target_kinds", true, "Whether to display the node kind of each edge target")
 	flag.StringVar(&c.pageToken, "page_token", "", "Edges page token")
 	flag.IntVar(&c.pageSize, "page_size", 0, "Maximum number of edges returned (0 lets the service use a sensible default)")
 }
@@ -63,6 +70,11 @@ func (c edgesCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) erro
 	} else if c.targetsOnly && c.dotGraph {
 		return errors.New("--targets_only and --graphviz are mutually exclusive")
 	}
+	switch c.direction {
+	case "out", "in", "both":
+	default:
+		return fmt.Errorf("unknown edge direction: %q", c.direction)
+	}
 
 	req := &gpb.EdgesRequest{
 		Ticket:    flag.Args(),
@@ -76,12 +88,15 @@ func (c edgesCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) erro
 	}
 	if c.dotGraph {
 		req.Filter = []string{"**"}
+	} else if c.targetKinds {
+		req.Filter = []string{facts.NodeKind, facts.Subkind}
 	}
 	LogRequest(req)
 	reply, err := api.GraphService.Edges(ctx, req)
 	if err != nil {
 		return err
 	}
+	c.filterDirection(reply)
 	if reply.NextPageToken != "" {
 		defer log.InfoContextf(ctx, "Next page token: %s", reply.NextPageToken)
 	}
@@ -95,24 +110,72 @@ func (c edgesCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) erro
 	return c.displayEdges(reply)
 }
 
+// filterDirection removes the edges of reply not in the direction given by
+// the --direction flag.
+func (c edgesCommand) filterDirection(reply *gpb.EdgesReply) {
+	if c.direction == "both" {
+		return
+	}
+	for _, es := range reply.EdgeSets {
+		for kind := range es.Groups {
+			if edges.IsReverse(kind) != (c.direction == "in") {
+				delete(es.Groups, kind)
+			}
+		}
+	}
+}
+
 func (c edgesCommand) displayEdges(reply *gpb.EdgesReply) error {
-	if DisplayJSON {
-		return PrintJSONMessage(reply)
+	nodes := graph.NodesMap(reply.Nodes)
+	if ok, err := displayReply(reply, func() *format.Table {
+		t := format.NewTable("source", "kind", "ordinal", "target", "target_kind")
+		for _, source := range sortedKeys(reply.EdgeSets) {
+			groups := reply.EdgeSets[source].Groups
+			for _, kind := range sortedKeys(groups) {
+				for _, edge := range sortEdges(groups[kind].Edge) {
+					t.Add(source, kind, strconv.Itoa(int(edge.Ordinal)), edge.TargetTicket, nodeKindOf(nodes[edge.TargetTicket]))
+				}
+			}
+		}
+		return t
+	}); ok {
+		return err
 	}
 
-	for source, es := range reply.EdgeSets {
+	for _, source := range sortedKeys(reply.EdgeSets) {
 		if _, err := fmt.Fprintln(out, "source:", source); err != nil {
 			return err
 		}
-		for kind, g := range es.Groups {
-			hasOrdinal := edges.OrdinalKind(kind)
-			for _, edge := range g.Edge {
-				var ordinal string
-				if hasOrdinal || edge.Ordinal != 0 {
-					ordinal = fmt.Sprintf(".%d", edge.Ordinal)
+		groups := reply.EdgeSets[source].Groups
+		for _, dir := range []struct {
+			name    string
+			reverse bool
+		}{{"outgoing", false}, {"incoming", true}} {
+			var kinds []string
+			for _, kind := range sortedKeys(groups) {
+				if edges.IsReverse(kind) == dir.reverse {
+					kinds = append(kinds, kind)
 				}
-				if _, err := fmt.Fprintf(out, "%s%s\t%s\n", kind, ordinal, edge.TargetTicket); err != nil {
-					return err
+			}
+			if len(kinds) == 0 {
+				continue
+			}
+			if _, err := fmt.Fprintf(out, "  %s:\n", dir.name); err != nil {
+				return err
+			}
+			for _, kind := range kinds {
+				hasOrdinal := edges.OrdinalKind(kind)
+				for _, edge := range sortEdges(groups[kind].Edge) {
+					var ordinal, nodeKind string
+					if hasOrdinal || edge.Ordinal != 0 {
+						ordinal = fmt.Sprintf(".%d", edge.Ordinal)
+					}
+					if k := nodeKindOf(nodes[edge.TargetTicket]); k != "" {
+						nodeKind = " [" + k + "]"
+					}
+					if _, err := fmt.Fprintf(out, "    %s%s\t%s%s\n", kind, ordinal, edge.TargetTicket, nodeKind); err != nil {
+						return err
+					}
 				}
 			}
 		}
@@ -120,6 +183,26 @@ func (c edgesCommand) displayEdges(reply *gpb.EdgesReply) error {
 	return nil
 }
 
+// sortEdges sorts g by ordinal and then by target, and returns it.
+func sortEdges(g []*gpb.EdgeSet_Group_Edge) []*gpb.EdgeSet_Group_Edge {
+	sort.SliceStable(g, func(i, j int) bool {
+		if g[i].Ordinal != g[j].Ordinal {
+			return g[i].Ordinal < g[j].Ordinal
+		}
+		return g[i].TargetTicket < g[j].TargetTicket
+	})
+	return g
+}
+
+// nodeKindOf returns the kind of a node, with its subkind if it has one.
+func nodeKindOf(node map[string][]byte) string {
+	kind := string(node[facts.NodeKind])
+	if sub := node[facts.Subkind]; kind != "" && len(sub) > 0 {
+		kind += "/" + string(sub)
+	}
+	return kind
+}
+
 func (c edgesCommand) displayTargets(edges map[string]*gpb.EdgeSet) error {
 	var targets stringset.Set
 	for _, es := range edges {
@@ -130,8 +213,17 @@ func (c edgesCommand) displayTargets(edges map[string]*gpb.EdgeSet) error {
 		}
 	}
 
-	if DisplayJSON {
+	switch {
+	case DisplayJSON:
 		return PrintJSON(targets.Elements())
+	case OutputFormat.Tabular():
+		t := format.NewTable("target")
+		for _, target := range targets.Elements() {
+			t.Add(target)
+		}
+		return t.Write(out, OutputFormat)
+	case OutputFormat != format.Text:
+		return fmt.Errorf("--targets_only does not support --format=%s", OutputFormat)
 	}
 
 	for target := range targets {
@@ -224,8 +316,17 @@ func (c edgesCommand) displayEdgeCounts(edges *gpb.EdgesReply) error {
 		}
 	}
 
-	if DisplayJSON {
+	switch {
+	case DisplayJSON:
 		return PrintJSON(counts)
+	case OutputFormat.Tabular():
+		t := format.NewTable("kind", "count")
+		for _, kind := range sortedKeys(counts) {
+			t.Add(kind, strconv.Itoa(counts[kind]))
+		}
+		return t.Write(out, OutputFormat)
+	case OutputFormat != format.Text:
+		return fmt.Errorf("--count_only does not support --format=%s", OutputFormat)
 	}
 
 	for kind, cnt := range counts {
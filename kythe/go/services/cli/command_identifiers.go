@@ -23,6 +23,8 @@ import (
 	"fmt"
 	"strings"
 
+	"kythe.io/kythe/go/services/cli/format"
+
 	ipb "kythe.io/kythe/proto/identifier_go_proto"
 )
 
@@ -65,8 +67,14 @@ func (c identCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) erro
 }
 
 func (c identCommand) displayMatches(reply *ipb.FindReply) error {
-	if DisplayJSON {
-		return PrintJSONMessage(reply)
+	if ok, err := displayReply(reply, func() *format.Table {
+		t := format.NewTable("ticket", "kind", "subkind")
+		for _, m := range reply.Matches {
+			t.Add(m.Ticket, m.NodeKind, m.NodeSubkind)
+		}
+		return t
+	}); ok {
+		return err
 	}
 
 	for _, m := range reply.Matches {
@@ -0,0 +1,279 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"kythe.io/kythe/go/services/cli/format"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/kytheuri"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+// locateCommand is a shared base for the defs and refs commands, which list
+// the anchors related to the nodes at a location, one per line in the form
+// path:line:column: snippet, as used by grep and editor quickfix lists.
+// Lines and columns are numbered from 1.
+type locateCommand struct {
+	baseKytheCommand
+	corpus, root, pathPrefix string
+	buildConfigs             flagutil.StringSet
+	maxResults               int
+	showURIs                 bool
+}
+
+func (locateCommand) Usage() string { return "(file:line[:col] | kythe-uri)..." }
+func (c *locateCommand) SetFlags(flag *flag.FlagSet) {
+	flag.StringVar(&c.corpus, "corpus", DefaultFileCorpus, "File corpus to use if given a raw path")
+	flag.StringVar(&c.root, "root", DefaultFileRoot, "File root to use if given a raw path")
+	flag.StringVar(&c.pathPrefix, "path_prefix", DefaultFilePathPrefix, "File path prefix to use if given a raw path (this is prepended directly to the raw path without any joining slashes, and removed from the paths displayed)")
+	flag.Var(&c.buildConfigs, "build_config", "CSV set of build configs with which to filter anchors")
+	flag.IntVar(&c.maxResults, "max", 0, "Maximum number of results to display (0 for no limit)")
+	flag.BoolVar(&c.showURIs, "uris", false, "Display each file as a Kythe URI rather than a path")
+}
+
+// parseLocation parses a location of the form file:line[:col].  The column
+// is 0 if it is not given.
+func parseLocation(loc string) (file string, line, col int, err error) {
+	parts := strings.Split(loc, ":")
+	n := len(parts)
+	if n >= 3 {
+		if c, err := strconv.Atoi(parts[n-1]); err == nil {
+			if l, err := strconv.Atoi(parts[n-2]); err == nil {
+				file, line, col = strings.Join(parts[:n-2], ":"), l, c
+			}
+		}
+	}
+	if file == "" && n >= 2 {
+		if l, err := strconv.Atoi(parts[n-1]); err == nil {
+			file, line = strings.Join(parts[:n-1], ":"), l
+		}
+	}
+	if file == "" || line < 1 || col < 0 {
+		return "", 0, 0, fmt.Errorf("invalid location %q (want file:line[:col])", loc)
+	}
+	return file, line, col, nil
+}
+
+// tickets returns the tickets of the nodes named by the command-line
+// arguments.  A location names the nodes referenced by the innermost anchor
+// containing it, or by any anchor starting on its line if no column is
+// given.
+func (c locateCommand) tickets(ctx context.Context, flag *flag.FlagSet, api API) ([]string, error) {
+	if flag.NArg() == 0 {
+		return nil, errors.New("no locations given")
+	}
+	var tickets []string
+	for _, arg := range flag.Args() {
+		if strings.HasPrefix(arg, kytheuri.Scheme) {
+			tickets = append(tickets, arg)
+			continue
+		}
+		file, line, col, err := parseLocation(arg)
+		if err != nil {
+			return nil, err
+		}
+		req := &xpb.DecorationsRequest{
+			Location: &xpb.Location{
+				Ticket: (&kytheuri.URI{Corpus: c.corpus, Root: c.root, Path: c.pathPrefix + file}).String(),
+				Kind:   xpb.Location_SPAN,
+				Span: &cpb.Span{
+					Start: &cpb.Point{LineNumber: int32(line)},
+					End:   &cpb.Point{LineNumber: int32(line + 1)},
+				},
+			},
+			References:  true,
+			BuildConfig: c.buildConfigs.Elements(),
+		}
+		LogRequest(req)
+		reply, err := api.XRefService.Decorations(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		found := targetsAt(reply.Reference, int32(line), int32(col-1))
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no references found at %s", arg)
+		}
+		tickets = append(tickets, found...)
+	}
+	return tickets, nil
+}
+
+// targetsAt returns the distinct targets of the innermost references
+// containing the given line and (0-based) column, or of all references
+// starting on the line if col < 0.
+func targetsAt(refs []*xpb.DecorationsReply_Reference, line, col int32) []string {
+	var inner *cpb.Span
+	var matches []*xpb.DecorationsReply_Reference
+	for _, r := range refs {
+		start, end := r.GetSpan().GetStart(), r.GetSpan().GetEnd()
+		if col < 0 {
+			if start.GetLineNumber() == line {
+				matches = append(matches, r)
+			}
+			continue
+		}
+		if before(line, col, start) || !before(line, col, end) {
+			continue // the point is not within [start, end)
+		}
+		size := end.GetByteOffset() - start.GetByteOffset()
+		switch {
+		case inner == nil || size < inner.GetEnd().GetByteOffset()-inner.GetStart().GetByteOffset():
+			inner, matches = r.Span, []*xpb.DecorationsReply_Reference{r}
+		case size == inner.GetEnd().GetByteOffset()-inner.GetStart().GetByteOffset():
+			matches = append(matches, r)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var tickets []string
+	for _, r := range matches {
+		if !seen[r.TargetTicket] {
+			seen[r.TargetTicket] = true
+			tickets = append(tickets, r.TargetTicket)
+		}
+	}
+	return tickets
+}
+
+// before reports whether the given line and column precede p.
+func before(line, col int32, p *cpb.Point) bool {
+	return line < p.GetLineNumber() || (line == p.GetLineNumber() && col < p.GetColumnOffset())
+}
+
+// locate lists the anchors selected by anchors from the cross-references of
+// the nodes named by the command-line arguments, completing req.
+func (c locateCommand) locate(ctx context.Context, flag *flag.FlagSet, api API, req *xpb.CrossReferencesRequest, anchors func(*xpb.CrossReferencesReply_CrossReferenceSet) []*xpb.CrossReferencesReply_RelatedAnchor) error {
+	tickets, err := c.tickets(ctx, flag, api)
+	if err != nil {
+		return err
+	}
+	req.Ticket = tickets
+	req.Snippets = xpb.SnippetsKind_DEFAULT
+	req.BuildConfig = c.buildConfigs.Elements()
+
+	// Tabular formats collect the anchors of every page into one table.
+	table := format.NewTable("path", "line", "col", "snippet")
+	seen := make(map[string]bool)
+	var shown int
+pages:
+	for {
+		LogRequest(req)
+		reply, err := api.XRefService.CrossReferences(ctx, req)
+		if err != nil {
+			return err
+		}
+		if OutputFormat != format.Text && !OutputFormat.Tabular() {
+			if _, err := displayReply(reply, nil); err != nil {
+				return err
+			}
+		} else {
+			for _, ticket := range tickets {
+				for _, a := range anchors(reply.CrossReferences[ticket]) {
+					if seen[a.GetAnchor().GetTicket()] {
+						continue
+					} else if c.maxResults > 0 && shown >= c.maxResults {
+						break pages
+					}
+					seen[a.GetAnchor().GetTicket()] = true
+					shown++
+					file, line, col, snippet := c.anchorFields(a.GetAnchor())
+					if OutputFormat.Tabular() {
+						table.Add(file, line, col, snippet)
+					} else if _, err := fmt.Fprintf(out, "%s:%s:%s: %s\n", file, line, col, snippet); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		if reply.NextPageToken == "" {
+			break
+		}
+		req.PageToken = reply.NextPageToken
+	}
+	if OutputFormat.Tabular() {
+		return table.Write(out, OutputFormat)
+	}
+	return nil
+}
+
+// anchorFields returns the path, line, column, and snippet of a, with the
+// snippet collapsed onto one line.
+func (c locateCommand) anchorFields(a *xpb.Anchor) (file, line, col, snippet string) {
+	file = a.GetParent()
+	if !c.showURIs {
+		if uri, err := kytheuri.Parse(file); err == nil {
+			file = strings.TrimPrefix(uri.Path, c.pathPrefix)
+		}
+	}
+	start := a.GetSpan().GetStart()
+	snippet = strings.Join(strings.Fields(a.GetSnippet()), " ")
+	return file, itoa(start.GetLineNumber()), itoa(start.GetColumnOffset() + 1), snippet
+}
+
+type defsCommand struct {
+	locateCommand
+	declarations bool
+}
+
+func (defsCommand) Name() string     { return "defs" }
+func (defsCommand) Synopsis() string { return "list the definitions of the nodes at a location" }
+func (c *defsCommand) SetFlags(flag *flag.FlagSet) {
+	c.locateCommand.SetFlags(flag)
+	flag.BoolVar(&c.declarations, "declarations", false, "Whether to also list declarations")
+}
+func (c defsCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) error {
+	req := &xpb.CrossReferencesRequest{
+		DefinitionKind:  xpb.CrossReferencesRequest_BINDING_DEFINITIONS,
+		DeclarationKind: xpb.CrossReferencesRequest_NO_DECLARATIONS,
+	}
+	if c.declarations {
+		req.DeclarationKind = xpb.CrossReferencesRequest_ALL_DECLARATIONS
+	}
+	return c.locate(ctx, flag, api, req, func(xr *xpb.CrossReferencesReply_CrossReferenceSet) []*xpb.CrossReferencesReply_RelatedAnchor {
+		return append(xr.GetDefinition(), xr.GetDeclaration()...)
+	})
+}
+
+type refsCommand struct {
+	locateCommand
+	calls bool
+}
+
+func (refsCommand) Name() string     { return "refs" }
+func (refsCommand) Synopsis() string { return "list the references to the nodes at a location" }
+func (c *refsCommand) SetFlags(flag *flag.FlagSet) {
+	c.locateCommand.SetFlags(flag)
+	flag.BoolVar(&c.calls, "calls", false, "Whether to list only call references")
+}
+func (c refsCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) error {
+	req := &xpb.CrossReferencesRequest{ReferenceKind: xpb.CrossReferencesRequest_ALL_REFERENCES}
+	if c.calls {
+		req.ReferenceKind = xpb.CrossReferencesRequest_CALL_REFERENCES
+	}
+	return c.locate(ctx, flag, api, req, func(xr *xpb.CrossReferencesReply_CrossReferenceSet) []*xpb.CrossReferencesReply_RelatedAnchor {
+		return xr.GetReference()
+	})
+}
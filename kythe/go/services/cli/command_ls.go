@@ -22,7 +22,11 @@ import (
 	"flag"
 	"fmt"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
+	"kythe.io/kythe/go/services/cli/format"
 	"kythe.io/kythe/go/services/filetree"
 	"kythe.io/kythe/go/util/kytheuri"
 
@@ -35,44 +39,86 @@ type lsCommand struct {
 	filesOnly      bool
 	dirsOnly       bool
 	includeMissing bool
+	long           bool
+	recursive      bool
+
+	// table collects the entries of every directory listed, for the tabular
+	// output formats.
+	table *format.Table
 }
 
 func (lsCommand) Name() string     { return "ls" }
 func (lsCommand) Synopsis() string { return "list a directory's contents" }
+func (lsCommand) Usage() string    { return "[kythe-uri]" }
 func (c *lsCommand) SetFlags(flag *flag.FlagSet) {
 	flag.BoolVar(&c.lsURIs, "uris", false, "Display files/directories as Kythe URIs")
 	flag.BoolVar(&c.filesOnly, "files", false, "Display only files")
 	flag.BoolVar(&c.dirsOnly, "dirs", false, "Display only directories")
 	flag.BoolVar(&c.includeMissing, "include_files_missing_text", false, "Include files missing text")
+	for _, name := range []string{"long", "l"} {
+		flag.BoolVar(&c.long, name, false, "Display the kind, flags, and build configurations of each entry")
+	}
+	for _, name := range []string{"recursive", "R"} {
+		flag.BoolVar(&c.recursive, name, false, "List subdirectories recursively")
+	}
 }
 func (c lsCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) error {
 	if c.filesOnly && c.dirsOnly {
 		return errors.New("--files and --dirs are mutually exclusive")
 	}
 
-	if len(flag.Args()) == 0 {
+	var dirs []*kytheuri.URI
+	switch len(flag.Args()) {
+	case 0:
 		req := &ftpb.CorpusRootsRequest{}
 		LogRequest(req)
 		cr, err := api.FileTreeService.CorpusRoots(ctx, req)
 		if err != nil {
 			return err
 		}
-		return c.displayCorpusRoots(cr)
-	}
-	var corpus, root, path string
-	switch len(flag.Args()) {
+		if !c.recursive {
+			return c.displayCorpusRoots(cr)
+		}
+		for _, corpus := range cr.Corpus {
+			for _, root := range corpus.Root {
+				dirs = append(dirs, &kytheuri.URI{Corpus: corpus.Name, Root: root})
+			}
+		}
 	case 1:
 		uri, err := kytheuri.Parse(flag.Arg(0))
 		if err != nil {
 			return fmt.Errorf("invalid uri %q: %v", flag.Arg(0), err)
 		}
-		corpus = uri.Corpus
-		root = uri.Root
-		path = uri.Path
+		dirs = append(dirs, uri)
 	default:
 		return fmt.Errorf("too many arguments given: %v", flag.Args())
 	}
-	path = filetree.CleanDirPath(path)
+
+	if OutputFormat.Tabular() {
+		c.table = format.NewTable("corpus", "root", "path", "kind", "generated", "missing_text", "build_config")
+	}
+	for i, uri := range dirs {
+		if i > 0 && c.showHeaders() {
+			fmt.Fprintln(out)
+		}
+		if err := c.list(ctx, api, uri.Corpus, uri.Root, filetree.CleanDirPath(uri.Path)); err != nil {
+			return err
+		}
+	}
+	if c.table != nil {
+		return c.table.Write(out, OutputFormat)
+	}
+	return nil
+}
+
+// showHeaders reports whether each directory listed is preceded by a header
+// naming it, as by ls -R.
+func (c lsCommand) showHeaders() bool {
+	return c.recursive && !c.lsURIs && OutputFormat == format.Text
+}
+
+// list displays the given directory and, if c.recursive, its subdirectories.
+func (c lsCommand) list(ctx context.Context, api API, corpus, root, path string) error {
 	req := &ftpb.DirectoryRequest{
 		Corpus: corpus,
 		Root:   root,
@@ -86,13 +132,37 @@ func (c lsCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) error {
 		return err
 	}
 
+	var subdirs []string
+	if c.recursive {
+		for _, e := range dir.Entry {
+			if e.Kind == ftpb.DirectoryReply_DIRECTORY {
+				subdirs = append(subdirs, filepath.Join(path, e.Name))
+			}
+		}
+	}
 	if c.filesOnly {
 		dir.Entry = filterEntries(dir.Entry, ftpb.DirectoryReply_FILE)
 	} else if c.dirsOnly {
 		dir.Entry = filterEntries(dir.Entry, ftpb.DirectoryReply_DIRECTORY)
 	}
 
-	return c.displayDirectory(dir)
+	if c.showHeaders() {
+		if _, err := fmt.Fprintf(out, "%s:\n", filepath.Join(corpus, root, path)); err != nil {
+			return err
+		}
+	}
+	if err := c.displayDirectory(dir); err != nil {
+		return err
+	}
+	for _, sub := range subdirs {
+		if c.showHeaders() {
+			fmt.Fprintln(out)
+		}
+		if err := c.list(ctx, api, corpus, root, sub); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func filterEntries(entries []*ftpb.DirectoryReply_Entry, kind ftpb.DirectoryReply_Kind) []*ftpb.DirectoryReply_Entry {
@@ -107,8 +177,16 @@ func filterEntries(entries []*ftpb.DirectoryReply_Entry, kind ftpb.DirectoryRepl
 }
 
 func (c lsCommand) displayCorpusRoots(cr *ftpb.CorpusRootsReply) error {
-	if DisplayJSON {
-		return PrintJSONMessage(cr)
+	if ok, err := displayReply(cr, func() *format.Table {
+		t := format.NewTable("corpus", "root")
+		for _, corpus := range cr.Corpus {
+			for _, root := range corpus.Root {
+				t.Add(corpus.Name, root)
+			}
+		}
+		return t
+	}); ok {
+		return err
 	}
 
 	for _, corpus := range cr.Corpus {
@@ -132,10 +210,17 @@ func (c lsCommand) displayCorpusRoots(cr *ftpb.CorpusRootsReply) error {
 }
 
 func (c lsCommand) displayDirectory(d *ftpb.DirectoryReply) error {
-	if DisplayJSON {
-		return PrintJSONMessage(d)
+	if c.table != nil {
+		for _, e := range d.Entry {
+			c.table.Add(d.Corpus, d.Root, filepath.Join(d.Path, e.Name), strings.ToLower(e.Kind.String()),
+				strconv.FormatBool(e.Generated), strconv.FormatBool(e.MissingText), strings.Join(e.BuildConfig, ","))
+		}
+		return nil
+	} else if ok, err := displayReply(d, nil); ok {
+		return err
 	}
 
+	tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
 	for _, e := range d.Entry {
 		name := e.Name
 		if c.lsURIs {
@@ -149,9 +234,32 @@ func (c lsCommand) displayDirectory(d *ftpb.DirectoryReply) error {
 			name += "/"
 		}
 
-		if _, err := fmt.Fprintln(out, name); err != nil {
+		var err error
+		if c.long {
+			_, err = fmt.Fprintf(tw, "%s\t%s\t%s\n", entryMode(e), strings.Join(e.BuildConfig, ","), name)
+		} else {
+			_, err = fmt.Fprintln(tw, name)
+		}
+		if err != nil {
 			return err
 		}
 	}
-	return nil
+	return tw.Flush()
+}
+
+// entryMode returns a summary of the kind and flags of e for a long listing:
+// "d" for a directory or "-" for a file, then "g" if e is generated and "m"
+// if its text is missing.
+func entryMode(e *ftpb.DirectoryReply_Entry) string {
+	mode := []byte("---")
+	if e.Kind == ftpb.DirectoryReply_DIRECTORY {
+		mode[0] = 'd'
+	}
+	if e.Generated {
+		mode[1] = 'g'
+	}
+	if e.MissingText {
+		mode[2] = 'm'
+	}
+	return string(mode)
 }
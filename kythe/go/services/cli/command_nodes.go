@@ -20,24 +20,34 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"sort"
 	"strings"
 
-	cpb "kythe.io/kythe/proto/common_go_proto"
+	"kythe.io/kythe/go/services/cli/format"
+
 	gpb "kythe.io/kythe/proto/graph_go_proto"
 )
 
+// provenanceFilter matches the facts recording the build provenance of a file,
+// and the origin (compilation unit and indexer invocation) of any node.
+const provenanceFilter = "/kythe/provenance/*"
+
 type nodesCommand struct {
 	baseKytheCommand
 	nodeFilters       string
 	factSizeThreshold int
+	provenance        bool
 }
 
-func (nodesCommand) Name() string     { return "nodes" }
-func (nodesCommand) Synopsis() string { return "retrieve a node's facts" }
+func (nodesCommand) Name() string      { return "nodes" }
+func (nodesCommand) Synopsis() string  { return "retrieve a node's facts" }
+func (nodesCommand) Aliases() []string { return []string{"node"} }
+func (nodesCommand) Usage() string     { return "<ticket>..." }
 func (c *nodesCommand) SetFlags(flag *flag.FlagSet) {
 	flag.StringVar(&c.nodeFilters, "filters", "", "Comma-separated list of node fact filters (default returns all)")
 	flag.IntVar(&c.factSizeThreshold, "max_fact_size", 64,
 		"Maximum size of fact values to display.  Facts with byte lengths longer than this value will only have their fact names displayed.")
+	flag.BoolVar(&c.provenance, "provenance", false, "Display the provenance facts (commit, build time, toolchain, and originating unit and invocation) of each node, in addition to any --filters")
 }
 func (c nodesCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) error {
 	if c.factSizeThreshold < 0 {
@@ -50,24 +60,41 @@ func (c nodesCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) erro
 	if c.nodeFilters != "" {
 		req.Filter = strings.Split(c.nodeFilters, ",")
 	}
+	if c.provenance {
+		req.Filter = append(req.Filter, provenanceFilter)
+	}
 	LogRequest(req)
 	reply, err := api.GraphService.Nodes(ctx, req)
 	if err != nil {
 		return err
 	}
-	return c.displayNodes(reply.Nodes)
+	return c.displayNodes(reply)
 }
 
-func (c *nodesCommand) displayNodes(nodes map[string]*cpb.NodeInfo) error {
+func (c *nodesCommand) displayNodes(reply *gpb.NodesReply) error {
+	nodes := reply.Nodes
 	if DisplayJSON {
 		return PrintJSON(nodes)
+	} else if ok, err := displayReply(reply, func() *format.Table {
+		t := format.NewTable("ticket", "fact", "value")
+		for _, ticket := range sortedKeys(nodes) {
+			facts := nodes[ticket].GetFacts()
+			for _, name := range sortedKeys(facts) {
+				t.Add(ticket, name, string(facts[name]))
+			}
+		}
+		return t
+	}); ok {
+		return err
 	}
 
-	for ticket, n := range nodes {
+	for _, ticket := range sortedKeys(nodes) {
 		if _, err := fmt.Fprintln(out, ticket); err != nil {
 			return err
 		}
-		for name, value := range n.Facts {
+		facts := nodes[ticket].GetFacts()
+		for _, name := range sortedKeys(facts) {
+			value := facts[name]
 			if len(value) <= c.factSizeThreshold {
 				if _, err := fmt.Fprintf(out, "  %s\t%s\n", name, value); err != nil {
 					return err
@@ -81,3 +108,13 @@ func (c *nodesCommand) displayNodes(nodes map[string]*cpb.NodeInfo) error {
 	}
 	return nil
 }
+
+// sortedKeys returns the keys of m in increasing order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
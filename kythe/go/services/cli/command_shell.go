@@ -0,0 +1,440 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"kythe.io/kythe/go/services/filetree"
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+	gpb "kythe.io/kythe/proto/graph_go_proto"
+)
+
+// commands are the registered commands, by name and alias, for the shell.
+var commands = make(map[string]KytheCommand)
+
+const shellHelp = `Any command may be run by name, as in "edges --kinds ref".  In its
+arguments, "." stands for the current node, and a command given no
+arguments is run on the current node.  "ls" and "cd" take paths relative
+to the current directory.
+
+Shell commands:
+  cd [dir|kythe-uri]    change the current directory (none lists corpus roots)
+  pwd                   show the current directory and node
+  open <file>           make a file in the current directory the current node
+  go <n|ticket>         make a listed target, or a ticket, the current node
+  follow <kind> [n]     list the targets of the current node's edges of the
+                        given kind, or go to the nth one
+  back                  return to the previous current node
+  history               list earlier commands; !! or !n runs one again
+  help                  show this message
+  exit                  end the session
+`
+
+// shellCommand runs an interactive session that reads commands from the
+// terminal and runs them relative to a current directory and node.  It does
+// no line editing; wrap it in a tool like rlwrap for that.
+type shellCommand struct {
+	baseKytheCommand
+	historyFile string
+
+	in      io.Reader
+	dir     kytheuri.URI // the current directory; empty at the corpus roots
+	ticket  string       // the current node, or ""
+	visited []string     // earlier current nodes, for "back"
+	targets []string     // the targets listed by the last "follow", for "go"
+	history []string
+}
+
+func (shellCommand) Name() string      { return "shell" }
+func (shellCommand) Aliases() []string { return []string{"repl"} }
+func (shellCommand) Synopsis() string  { return "explore the graph interactively" }
+func (shellCommand) Usage() string     { return "\n" + shellHelp }
+func (c *shellCommand) SetFlags(flag *flag.FlagSet) {
+	var def string
+	if home, err := os.UserHomeDir(); err == nil {
+		def = filepath.Join(home, ".kythe_history")
+	}
+	flag.StringVar(&c.historyFile, "history_file", def, "File in which to keep command history (empty disables saving it)")
+}
+func (c *shellCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) error {
+	if flag.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", flag.Args())
+	}
+	if c.in == nil {
+		c.in = os.Stdin
+	}
+	var hist io.Writer
+	if c.historyFile != "" {
+		if data, err := os.ReadFile(c.historyFile); err == nil {
+			c.history = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		}
+		f, err := os.OpenFile(c.historyFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return fmt.Errorf("opening history: %v", err)
+		}
+		defer f.Close()
+		hist = f
+	}
+
+	sc := bufio.NewScanner(c.in)
+	for {
+		fmt.Fprint(out, c.prompt())
+		if !sc.Scan() {
+			fmt.Fprintln(out)
+			return sc.Err()
+		}
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		line, err := c.expandHistory(line)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		c.history = append(c.history, line)
+		if hist != nil {
+			fmt.Fprintln(hist, line)
+		}
+
+		args, err := splitArgs(line)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		} else if args[0] == "exit" || args[0] == "quit" {
+			return nil
+		}
+		if err := c.run(ctx, api, args); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+	}
+}
+
+// prompt returns the prompt showing the current directory and node.
+func (c *shellCommand) prompt() string {
+	dir := filepath.Join(c.dir.Corpus, c.dir.Root, c.dir.Path)
+	if dir == "" {
+		dir = "/"
+	}
+	if c.ticket == "" {
+		return fmt.Sprintf("kythe %s> ", dir)
+	}
+	return fmt.Sprintf("kythe %s [%s]> ", dir, c.ticket)
+}
+
+// expandHistory replaces a line of the form !! or !n by the command it
+// names, which is echoed.
+func (c *shellCommand) expandHistory(line string) (string, error) {
+	if !strings.HasPrefix(line, "!") {
+		return line, nil
+	}
+	n := len(c.history)
+	if line != "!!" {
+		var err error
+		if n, err = strconv.Atoi(line[1:]); err != nil {
+			return "", fmt.Errorf("bad history reference %q", line)
+		}
+	}
+	if n < 1 || n > len(c.history) {
+		return "", fmt.Errorf("no command %s in history", line)
+	}
+	fmt.Fprintln(out, c.history[n-1])
+	return c.history[n-1], nil
+}
+
+// run runs the shell or Kythe command given by args.
+func (c *shellCommand) run(ctx context.Context, api API, args []string) error {
+	switch name, args := args[0], args[1:]; name {
+	case "help":
+		_, err := fmt.Fprint(out, shellHelp)
+		return err
+	case "pwd":
+		fmt.Fprintln(out, "directory:", c.dir.String())
+		if c.ticket != "" {
+			fmt.Fprintln(out, "node:", c.ticket)
+		}
+		return nil
+	case "history":
+		for i, line := range c.history {
+			fmt.Fprintf(out, "%5d  %s\n", i+1, line)
+		}
+		return nil
+	case "cd":
+		return c.cd(ctx, api, args)
+	case "open":
+		if len(args) != 1 {
+			return errors.New("usage: open <file>")
+		}
+		file := c.resolve(args[0])
+		c.visit(file.String())
+		return nil
+	case "go":
+		if len(args) != 1 {
+			return errors.New("usage: go <n|ticket>")
+		}
+		ticket, err := c.target(args[0])
+		if err != nil {
+			return err
+		}
+		c.visit(ticket)
+		return nil
+	case "follow":
+		return c.follow(ctx, api, args)
+	case "back":
+		if len(c.visited) == 0 {
+			return errors.New("no previous node")
+		}
+		c.ticket = c.visited[len(c.visited)-1]
+		c.visited = c.visited[:len(c.visited)-1]
+		return nil
+	case "shell", "repl":
+		return errors.New("already in the shell")
+	default:
+		cmd, ok := commands[name]
+		if !ok {
+			return fmt.Errorf("unknown command %q (try help)", name)
+		}
+		return c.runCommand(ctx, api, cmd, args)
+	}
+}
+
+// runCommand runs a Kythe command with the given arguments, resolved
+// against the current directory and node.
+func (c *shellCommand) runCommand(ctx context.Context, api API, cmd KytheCommand, args []string) error {
+	parse := func(args []string) (*flag.FlagSet, error) {
+		fs := flag.NewFlagSet(cmd.Name(), flag.ContinueOnError)
+		fs.SetOutput(os.Stderr)
+		cmd.SetFlags(fs)
+		return fs, fs.Parse(args)
+	}
+	fs, err := parse(args)
+	if err != nil {
+		return err
+	}
+
+	flags := args[:len(args)-fs.NArg()]
+	var rest []string
+	if cmd.Name() == "ls" {
+		for _, arg := range fs.Args() {
+			rest = append(rest, c.resolve(arg).String())
+		}
+		if len(rest) == 0 && c.dir != (kytheuri.URI{}) {
+			rest = []string{c.dir.String()}
+		}
+	} else {
+		for _, arg := range fs.Args() {
+			if arg == "." {
+				if c.ticket == "" {
+					return errors.New("no current node")
+				}
+				arg = c.ticket
+			}
+			rest = append(rest, arg)
+		}
+		if len(rest) == 0 && c.ticket != "" {
+			rest = []string{c.ticket}
+		}
+	}
+	if fs, err = parse(append(flags, rest...)); err != nil {
+		return err
+	}
+	return cmd.Run(ctx, fs, api)
+}
+
+// resolve returns the URI of the given path relative to the current
+// directory.  Absolute paths are relative to the current corpus and root,
+// and Kythe URIs are taken as given.
+func (c *shellCommand) resolve(arg string) *kytheuri.URI {
+	if strings.HasPrefix(arg, kytheuri.Scheme) {
+		if uri, err := kytheuri.Parse(arg); err == nil {
+			return uri
+		}
+	}
+	uri := c.dir
+	if path.IsAbs(arg) {
+		uri.Path = path.Clean(arg)
+	} else {
+		uri.Path = path.Join("/", uri.Path, arg)
+	}
+	uri.Path = strings.TrimPrefix(uri.Path, "/")
+	return &uri
+}
+
+// cd changes the current directory, which must exist.
+func (c *shellCommand) cd(ctx context.Context, api API, args []string) error {
+	switch len(args) {
+	case 0:
+		c.dir = kytheuri.URI{}
+		return nil
+	case 1:
+	default:
+		return errors.New("usage: cd [dir|kythe-uri]")
+	}
+	uri := c.resolve(args[0])
+	if uri.Corpus == "" {
+		return errors.New("no corpus given: cd to a kythe URI first")
+	}
+	req := &ftpb.DirectoryRequest{
+		Corpus: uri.Corpus,
+		Root:   uri.Root,
+		Path:   filetree.CleanDirPath(uri.Path),
+	}
+	LogRequest(req)
+	dir, err := api.FileTreeService.Directory(ctx, req)
+	if err != nil {
+		return err
+	} else if len(dir.Entry) == 0 {
+		return fmt.Errorf("no such directory: %s", uri)
+	}
+	c.dir = kytheuri.URI{Corpus: uri.Corpus, Root: uri.Root, Path: uri.Path}
+	return nil
+}
+
+// visit makes ticket the current node.
+func (c *shellCommand) visit(ticket string) {
+	if c.ticket != "" && c.ticket != ticket {
+		c.visited = append(c.visited, c.ticket)
+	}
+	c.ticket = ticket
+}
+
+// target returns the ticket named by arg, either a ticket or the number of
+// a target listed by follow.
+func (c *shellCommand) target(arg string) (string, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		return arg, nil
+	} else if n < 1 || n > len(c.targets) {
+		return "", fmt.Errorf("no target %d (%d listed)", n, len(c.targets))
+	}
+	return c.targets[n-1], nil
+}
+
+// follow lists the targets of the current node's edges of a kind, or goes
+// to one of them if it is chosen or the only one.
+func (c *shellCommand) follow(ctx context.Context, api API, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("usage: follow <kind> [n]")
+	} else if c.ticket == "" {
+		return errors.New("no current node")
+	}
+	req := &gpb.EdgesRequest{
+		Ticket: []string{c.ticket},
+		Kind:   []string{edgesCommand{}.expandEdgeKind(args[0])},
+		Filter: []string{facts.NodeKind, facts.Subkind},
+	}
+	LogRequest(req)
+	reply, err := api.GraphService.Edges(ctx, req)
+	if err != nil {
+		return err
+	}
+	var edges []*gpb.EdgeSet_Group_Edge
+	for _, g := range reply.EdgeSets[c.ticket].GetGroups() {
+		edges = append(edges, g.Edge...)
+	}
+	if len(edges) == 0 {
+		return fmt.Errorf("no %s edges", req.Kind[0])
+	}
+	c.targets = nil
+	for _, e := range sortEdges(edges) {
+		c.targets = append(c.targets, e.TargetTicket)
+	}
+
+	if len(args) == 2 {
+		ticket, err := c.target(args[1])
+		if err != nil {
+			return err
+		}
+		c.visit(ticket)
+		return nil
+	} else if len(c.targets) == 1 {
+		c.visit(c.targets[0])
+		return nil
+	}
+	nodes := reply.GetNodes()
+	for i, ticket := range c.targets {
+		var kind string
+		if k := nodeKindOf(nodes[ticket].GetFacts()); k != "" {
+			kind = " [" + k + "]"
+		}
+		fmt.Fprintf(out, "%3d  %s%s\n", i+1, ticket, kind)
+	}
+	return nil
+}
+
+// splitArgs splits a command line into words separated by spaces.  Single
+// or double quotes group words, and a backslash escapes the next character
+// outside single quotes.
+func splitArgs(line string) ([]string, error) {
+	var (
+		args  []string
+		word  strings.Builder
+		inArg bool
+		quote rune
+		esc   bool
+	)
+	for _, r := range line {
+		switch {
+		case esc:
+			word.WriteRune(r)
+			esc = false
+		case r == '\\' && quote != '\'':
+			esc, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, word.String())
+				word.Reset()
+				inArg = false
+			}
+		default:
+			word.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || esc {
+		return nil, errors.New("unterminated quote or escape")
+	}
+	if inArg {
+		args = append(args, word.String())
+	}
+	if len(args) == 0 {
+		return nil, errors.New("empty command")
+	}
+	return args, nil
+}
@@ -17,7 +17,10 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"flag"
 	"fmt"
@@ -25,7 +28,11 @@ import (
 	"strconv"
 	"strings"
 
+	"kythe.io/kythe/go/services/cli/format"
+	"kythe.io/kythe/go/util/schema/facts"
+
 	cpb "kythe.io/kythe/proto/common_go_proto"
+	gpb "kythe.io/kythe/proto/graph_go_proto"
 	xpb "kythe.io/kythe/proto/xref_go_proto"
 )
 
@@ -103,15 +110,22 @@ func parseSpan(span string) (*cpb.Span, error) {
 	}, nil
 }
 
+// sourceCommand displays the text of a file as it was indexed, which may
+// differ from the text in the user's working tree.
 type sourceCommand struct {
 	baseDecorCommand
+	info        bool
+	lineNumbers bool
 }
 
 func (sourceCommand) Name() string     { return "source" }
-func (sourceCommand) Synopsis() string { return "retrieve a file's source text" }
-func (sourceCommand) Usage() string    { return "" }
+func (sourceCommand) Synopsis() string { return "retrieve a file's indexed source text" }
+func (sourceCommand) Usage() string    { return "<file-ticket|path>" }
 func (c *sourceCommand) SetFlags(flag *flag.FlagSet) {
 	c.baseDecorCommand.SetFlags(flag)
+	flag.BoolVar(&c.info, "info", false, "Instead of the text, display its revision, encoding, size, SHA-256 digest, and provenance facts")
+	flag.BoolVar(&c.lineNumbers, "line_numbers", false, "Prefix each line of text with its line number")
+	flag.BoolVar(&c.lineNumbers, "n", false, "Short for --line_numbers")
 }
 func (c sourceCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) error {
 	req, err := c.baseRequest(flag)
@@ -125,14 +139,118 @@ func (c sourceCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) err
 	if err != nil {
 		return err
 	}
-	return displaySource(reply)
+	if c.info {
+		return c.displayInfo(ctx, api, reply)
+	}
+	return c.displaySource(reply)
 }
 
-func displaySource(decor *xpb.DecorationsReply) error {
-	if DisplayJSON {
-		return PrintJSONMessage(decor)
+func (c sourceCommand) displaySource(decor *xpb.DecorationsReply) error {
+	if ok, err := displayReply(decor, nil); ok {
+		return err
+	} else if !c.lineNumbers {
+		_, err := out.Write(decor.SourceText)
+		return err
 	}
 
-	_, err := out.Write(decor.SourceText)
-	return err
+	line := int(decor.GetLocation().GetSpan().GetStart().GetLineNumber())
+	if line == 0 {
+		line = 1
+	}
+	text := decor.SourceText
+	for len(text) > 0 {
+		next := len(text)
+		if i := bytes.IndexByte(text, '\n'); i >= 0 {
+			next = i + 1
+		}
+		if _, err := fmt.Fprintf(out, "%6d  %s", line, text[:next]); err != nil {
+			return err
+		}
+		text, line = text[next:], line+1
+	}
+	if n := len(decor.SourceText); n > 0 && decor.SourceText[n-1] != '\n' {
+		fmt.Fprintln(out)
+	}
+	return nil
+}
+
+// sourceInfo describes the indexed text of a file, for --info.
+type sourceInfo struct {
+	Ticket   string            `json:"ticket"`
+	Revision string            `json:"revision,omitempty"`
+	Encoding string            `json:"encoding,omitempty"`
+	Bytes    int               `json:"bytes"`
+	Lines    int               `json:"lines"`
+	SHA256   string            `json:"sha256"`
+	Facts    map[string]string `json:"facts,omitempty"`
+}
+
+func (c sourceCommand) displayInfo(ctx context.Context, api API, decor *xpb.DecorationsReply) error {
+	text := decor.SourceText
+	sum := sha256.Sum256(text)
+	info := &sourceInfo{
+		Ticket:   decor.GetLocation().GetTicket(),
+		Revision: decor.Revision,
+		Encoding: decor.Encoding,
+		Bytes:    len(text),
+		Lines:    bytes.Count(text, []byte("\n")),
+		SHA256:   hex.EncodeToString(sum[:]),
+	}
+	if len(text) > 0 && text[len(text)-1] != '\n' {
+		info.Lines++
+	}
+
+	req := &gpb.NodesRequest{
+		Ticket: []string{info.Ticket},
+		Filter: []string{provenanceFilter, facts.TextEncoding},
+	}
+	LogRequest(req)
+	nodes, err := api.GraphService.Nodes(ctx, req)
+	if err != nil {
+		return err
+	}
+	if fs := nodes.Nodes[info.Ticket].GetFacts(); len(fs) > 0 {
+		info.Facts = make(map[string]string)
+		for name, value := range fs {
+			info.Facts[name] = string(value)
+		}
+	}
+
+	switch {
+	case DisplayJSON:
+		return PrintJSON(info)
+	case OutputFormat.Tabular():
+		t := format.NewTable("field", "value")
+		for _, f := range info.fields() {
+			t.Add(f[0], f[1])
+		}
+		return t.Write(out, OutputFormat)
+	case OutputFormat != format.Text:
+		return fmt.Errorf("--info does not support --format=%s", OutputFormat)
+	}
+	for _, f := range info.fields() {
+		if _, err := fmt.Fprintf(out, "%-9s %s\n", f[0]+":", f[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fields returns the populated fields of info as name/value pairs.
+func (info *sourceInfo) fields() [][2]string {
+	fs := [][2]string{{"ticket", info.Ticket}}
+	if info.Revision != "" {
+		fs = append(fs, [2]string{"revision", info.Revision})
+	}
+	if info.Encoding != "" {
+		fs = append(fs, [2]string{"encoding", info.Encoding})
+	}
+	fs = append(fs,
+		[2]string{"bytes", strconv.Itoa(info.Bytes)},
+		[2]string{"lines", strconv.Itoa(info.Lines)},
+		[2]string{"sha256", info.SHA256})
+	for _, name := range sortedKeys(info.Facts) {
+		fs = append(fs, [2]string{name, info.Facts[name]})
+	}
+	return fs
 }
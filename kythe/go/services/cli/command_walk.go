@@ -0,0 +1,235 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"kythe.io/kythe/go/services/cli/format"
+	"kythe.io/kythe/go/services/graph"
+	"kythe.io/kythe/go/util/log"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	gpb "kythe.io/kythe/proto/graph_go_proto"
+)
+
+// walkBatchSize is the maximum number of tickets in each Edges request made
+// by the walk command.
+const walkBatchSize = 100
+
+// walkCommand traverses the graph breadth-first from a set of nodes,
+// following edges of chosen kinds to a bounded depth.
+type walkCommand struct {
+	baseKytheCommand
+	edgeKinds string
+	depth     int
+	maxNodes  int
+	dotGraph  bool
+}
+
+func (walkCommand) Name() string { return "walk" }
+func (walkCommand) Synopsis() string {
+	return "traverse the graph from nodes, following edges of chosen kinds to a bounded depth"
+}
+func (walkCommand) Usage() string { return "<ticket>..." }
+func (c *walkCommand) SetFlags(flag *flag.FlagSet) {
+	flag.StringVar(&c.edgeKinds, "edges", "", "Comma-separated list of edge kinds to follow, e.g. ref,%childof (default follows all)")
+	flag.IntVar(&c.depth, "depth", 2, "Maximum number of edges followed from a starting node")
+	flag.IntVar(&c.maxNodes, "max_nodes", 1000, "Maximum number of nodes to visit (0 for no limit)")
+	flag.BoolVar(&c.dotGraph, "graphviz", false, "Print the visited subgraph as a dot graph")
+}
+func (c walkCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) error {
+	if flag.NArg() == 0 {
+		return errors.New("no tickets given")
+	} else if c.depth < 0 {
+		return fmt.Errorf("invalid --depth: %d", c.depth)
+	}
+	g, err := c.walk(ctx, api.GraphService, flag.Args())
+	if err != nil {
+		return err
+	}
+	if g.truncated {
+		log.Warningf("Stopped after visiting --max_nodes=%d nodes", c.maxNodes)
+	}
+
+	if c.dotGraph {
+		return edgesCommand{}.displayEdgeGraph(g.EdgesReply)
+	}
+	nodes := graph.NodesMap(g.Nodes)
+	if ok, err := displayReply(g.EdgesReply, func() *format.Table {
+		t := format.NewTable("depth", "source", "kind", "ordinal", "target", "target_kind")
+		for _, source := range g.order {
+			groups := g.EdgeSets[source].GetGroups()
+			for _, kind := range sortedKeys(groups) {
+				for _, edge := range sortEdges(groups[kind].Edge) {
+					t.Add(strconv.Itoa(g.depth[source]), source, kind, strconv.Itoa(int(edge.Ordinal)),
+						edge.TargetTicket, nodeKindOf(nodes[edge.TargetTicket]))
+				}
+			}
+		}
+		return t
+	}); ok {
+		return err
+	}
+	return c.displayTree(g, nodes)
+}
+
+// A subgraph is the result of a walk.  Its edge sets hold the edges of each
+// node expanded, and its nodes the facts of each node visited.
+type subgraph struct {
+	*gpb.EdgesReply
+	roots     []string
+	order     []string       // expanded nodes, in the order visited
+	depth     map[string]int // number of edges from a root to each node visited
+	truncated bool           // whether --max_nodes was reached
+}
+
+// walk visits the nodes within c.depth edges of roots.
+func (c walkCommand) walk(ctx context.Context, gs graph.Service, roots []string) (*subgraph, error) {
+	g := &subgraph{
+		EdgesReply: &gpb.EdgesReply{
+			EdgeSets: make(map[string]*gpb.EdgeSet),
+			Nodes:    make(map[string]*cpb.NodeInfo),
+		},
+		depth: make(map[string]int),
+	}
+	for _, r := range roots {
+		if _, ok := g.depth[r]; !ok {
+			g.depth[r] = 0
+			g.roots = append(g.roots, r)
+		}
+	}
+
+	var kinds []string
+	if c.edgeKinds != "" {
+		for _, kind := range strings.Split(c.edgeKinds, ",") {
+			kinds = append(kinds, edgesCommand{}.expandEdgeKind(kind))
+		}
+	}
+	filter := []string{facts.NodeKind, facts.Subkind}
+	if c.dotGraph {
+		filter = []string{"**"}
+	}
+
+	req := &gpb.NodesRequest{Ticket: g.roots, Filter: filter}
+	LogRequest(req)
+	rootNodes, err := gs.Nodes(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for ticket, n := range rootNodes.Nodes {
+		g.Nodes[ticket] = n
+	}
+
+	frontier := g.roots
+	for d := 0; d < c.depth && len(frontier) > 0; d++ {
+		var next []string
+		for i := 0; i < len(frontier); i += walkBatchSize {
+			batch := frontier[i:min(i+walkBatchSize, len(frontier))]
+			req := &gpb.EdgesRequest{Ticket: batch, Kind: kinds, Filter: filter}
+			LogRequest(req)
+			reply, err := graph.AllEdges(ctx, gs, req)
+			if err != nil {
+				return nil, err
+			}
+			for ticket, n := range reply.Nodes {
+				g.Nodes[ticket] = n
+			}
+			for _, source := range batch {
+				es := reply.EdgeSets[source]
+				if es == nil {
+					continue
+				}
+				g.EdgeSets[source] = es
+				g.order = append(g.order, source)
+				for _, kind := range sortedKeys(es.Groups) {
+					for _, e := range es.Groups[kind].Edge {
+						if _, ok := g.depth[e.TargetTicket]; ok {
+							continue
+						} else if c.maxNodes > 0 && len(g.depth) >= c.maxNodes {
+							g.truncated = true
+							continue
+						}
+						g.depth[e.TargetTicket] = d + 1
+						next = append(next, e.TargetTicket)
+					}
+				}
+			}
+		}
+		sort.Strings(next)
+		frontier = next
+	}
+	return g, nil
+}
+
+// displayTree prints the subgraph as a tree of edges from each root.  The
+// edges of a node are shown only under its first appearance.
+func (c walkCommand) displayTree(g *subgraph, nodes map[string]map[string][]byte) error {
+	shown := make(map[string]bool)
+	var show func(ticket, indent string) error
+	show = func(ticket, indent string) error {
+		groups := g.EdgeSets[ticket].GetGroups()
+		for _, kind := range sortedKeys(groups) {
+			for _, edge := range sortEdges(groups[kind].Edge) {
+				var ordinal, nodeKind, seen string
+				if edges.OrdinalKind(kind) || edge.Ordinal != 0 {
+					ordinal = fmt.Sprintf(".%d", edge.Ordinal)
+				}
+				if k := nodeKindOf(nodes[edge.TargetTicket]); k != "" {
+					nodeKind = " [" + k + "]"
+				}
+				tgt := edge.TargetTicket
+				_, expanded := g.EdgeSets[tgt]
+				if expanded && shown[tgt] {
+					seen = " (see above)"
+				}
+				if _, err := fmt.Fprintf(out, "%s%s%s  %s%s%s\n", indent, kind, ordinal, tgt, nodeKind, seen); err != nil {
+					return err
+				}
+				if expanded && !shown[tgt] {
+					shown[tgt] = true
+					if err := show(tgt, indent+"  "); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+	for _, root := range g.roots {
+		var nodeKind string
+		if k := nodeKindOf(nodes[root]); k != "" {
+			nodeKind = " [" + k + "]"
+		}
+		if _, err := fmt.Fprintf(out, "%s%s\n", root, nodeKind); err != nil {
+			return err
+		}
+		shown[root] = true
+		if err := show(root, "  "); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -20,8 +20,13 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
 
+	"kythe.io/kythe/go/services/cli/format"
+	"kythe.io/kythe/go/util/encoding/tabular"
 	"kythe.io/kythe/go/util/flagutil"
 	"kythe.io/kythe/go/util/kytheuri"
 	"kythe.io/kythe/go/util/log"
@@ -58,6 +63,8 @@ type xrefsCommand struct {
 	excludeGenerated bool
 
 	totalsOnly bool
+
+	tableColumns flagutil.StringList
 }
 
 func (xrefsCommand) Name() string     { return "xrefs" }
@@ -80,6 +87,8 @@ func (c *xrefsCommand) SetFlags(flag *flag.FlagSet) {
 
 	flag.BoolVar(&c.totalsOnly, "totals_only", false, "Only output total count of xrefs")
 
+	flag.Var(&c.tableColumns, "columns", "CSV list of columns to output, one row per related anchor, with a tabular --format (default: all; available: "+strings.Join(tabular.ColumnNames(xrefColumns), ",")+")")
+
 	flag.StringVar(&c.pageToken, "page_token", "", "CrossReferences page token")
 	flag.IntVar(&c.pageSize, "page_size", 0, "Maximum number of cross-references returned (0 lets the service use a sensible default)")
 }
@@ -162,6 +171,10 @@ func (c xrefsCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) erro
 	default:
 		return fmt.Errorf("unknown caller kind: %q", c.callerKind)
 	}
+	cols, err := tabular.SelectColumns(xrefColumns, c.tableColumns)
+	if err != nil {
+		return err
+	}
 	LogRequest(req)
 	reply, err := api.XRefService.CrossReferences(ctx, req)
 	if err != nil {
@@ -170,12 +183,110 @@ func (c xrefsCommand) Run(ctx context.Context, flag *flag.FlagSet, api API) erro
 	if reply.NextPageToken != "" {
 		defer log.InfoContextf(ctx, "Next page token: %s", reply.NextPageToken)
 	}
-	return c.displayXRefs(reply)
+	return c.displayXRefs(reply, cols)
+}
+
+// xrefRow is a single related anchor in tabular xrefs output.
+type xrefRow struct {
+	ticket, kind string
+	*xpb.CrossReferencesReply_RelatedAnchor
+}
+
+// xrefColumns are the columns available for tabular xrefs output.
+var xrefColumns = []tabular.Column[*xrefRow]{
+	{Name: "ticket", Value: func(r *xrefRow) string { return r.ticket }},
+	{Name: "kind", Value: func(r *xrefRow) string { return r.kind }},
+	{Name: "anchor", Value: func(r *xrefRow) string { return r.GetAnchor().GetTicket() }},
+	{Name: "anchor_kind", Value: func(r *xrefRow) string { return r.GetAnchor().GetKind() }},
+	{Name: "file", Value: func(r *xrefRow) string { return r.GetAnchor().GetParent() }},
+	{Name: "corpus", Value: func(r *xrefRow) string { return parentURI(r).Corpus }},
+	{Name: "root", Value: func(r *xrefRow) string { return parentURI(r).Root }},
+	{Name: "path", Value: func(r *xrefRow) string { return parentURI(r).Path }},
+	{Name: "dir", Value: func(r *xrefRow) string {
+		if d := path.Dir(parentURI(r).Path); d != "." {
+			return d
+		}
+		return ""
+	}},
+	{Name: "start_line", Value: func(r *xrefRow) string {
+		return strconv.Itoa(int(r.GetAnchor().GetSpan().GetStart().GetLineNumber()))
+	}},
+	{Name: "start_column", Value: func(r *xrefRow) string {
+		return strconv.Itoa(int(r.GetAnchor().GetSpan().GetStart().GetColumnOffset()))
+	}},
+	{Name: "end_line", Value: func(r *xrefRow) string {
+		return strconv.Itoa(int(r.GetAnchor().GetSpan().GetEnd().GetLineNumber()))
+	}},
+	{Name: "end_column", Value: func(r *xrefRow) string {
+		return strconv.Itoa(int(r.GetAnchor().GetSpan().GetEnd().GetColumnOffset()))
+	}},
+	{Name: "snippet", Value: func(r *xrefRow) string { return r.GetAnchor().GetSnippet() }},
+	{Name: "signature", Value: func(r *xrefRow) string {
+		if r.MarkedSource == nil {
+			return ""
+		}
+		return showSignature(r.MarkedSource)
+	}},
+}
+
+// parentURI returns the parsed file ticket of the row's anchor.  Malformed
+// tickets yield an empty URI.
+func parentURI(r *xrefRow) *kytheuri.URI {
+	u, err := kytheuri.Parse(r.GetAnchor().GetParent())
+	if err != nil {
+		return &kytheuri.URI{}
+	}
+	return u
 }
 
-func (c xrefsCommand) displayXRefs(reply *xpb.CrossReferencesReply) error {
-	if DisplayJSON {
-		return PrintJSONMessage(reply)
+// sortedXRefs returns the cross-references of reply ordered by ticket, so
+// that they are displayed in a stable order.
+func sortedXRefs(reply *xpb.CrossReferencesReply) []*xpb.CrossReferencesReply_CrossReferenceSet {
+	tickets := make([]string, 0, len(reply.CrossReferences))
+	for ticket := range reply.CrossReferences {
+		tickets = append(tickets, ticket)
+	}
+	sort.Strings(tickets)
+	xrs := make([]*xpb.CrossReferencesReply_CrossReferenceSet, len(tickets))
+	for i, ticket := range tickets {
+		xrs[i] = reply.CrossReferences[ticket]
+	}
+	return xrs
+}
+
+// eachXRefRow calls f with a row for each related anchor of reply, in order
+// of ticket.
+func eachXRefRow(reply *xpb.CrossReferencesReply, f func(*xrefRow) error) error {
+	for _, xr := range sortedXRefs(reply) {
+		for _, group := range []struct {
+			kind    string
+			anchors []*xpb.CrossReferencesReply_RelatedAnchor
+		}{
+			{"definition", xr.Definition},
+			{"declaration", xr.Declaration},
+			{"reference", xr.Reference},
+			{"caller", xr.Caller},
+		} {
+			for _, a := range group.anchors {
+				if err := f(&xrefRow{xr.Ticket, group.kind, a}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c xrefsCommand) displayXRefs(reply *xpb.CrossReferencesReply, cols []tabular.Column[*xrefRow]) error {
+	if ok, err := displayReply(reply, func() *format.Table {
+		t := format.NewTable(tabular.ColumnNames(cols)...)
+		eachXRefRow(reply, func(r *xrefRow) error {
+			t.Add(tabular.Row(cols, r)...)
+			return nil
+		})
+		return t
+	}); ok {
+		return err
 	}
 
 	fmt.Fprintf(out, "Totals:\n%s\n\n", reply.GetTotal())
@@ -184,7 +295,7 @@ func (c xrefsCommand) displayXRefs(reply *xpb.CrossReferencesReply) error {
 		return nil
 	}
 
-	for _, xr := range reply.CrossReferences {
+	for _, xr := range sortedXRefs(reply) {
 		var sig string
 		if xr.MarkedSource != nil {
 			sig = showSignature(xr.MarkedSource) + " "
@@ -0,0 +1,212 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package format writes the results of command-line tools in a format chosen
+// by the user: text for people to read, or JSON, binary protobuf, an aligned
+// table, or tab- or comma-separated values for scripts to consume.
+package format // import "kythe.io/kythe/go/services/cli/format"
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"kythe.io/kythe/go/platform/delimited"
+	"kythe.io/kythe/go/util/encoding/tabular"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Format is an output format.  A *Format is a flag.Value.
+type Format string
+
+// Supported output formats.
+const (
+	Text    Format = "text"  // a tool's own human-readable output
+	JSON    Format = "json"  // indented protobuf JSON
+	Proto   Format = "proto" // length-delimited binary protobuf messages
+	Aligned Format = "table" // aligned columns under a header row
+	TSV     Format = "tsv"   // tab-separated values under a header row
+	CSV     Format = "csv"   // comma-separated values (RFC 4180) under a header row
+)
+
+var formats = []Format{Text, JSON, Proto, Aligned, TSV, CSV}
+
+// Parse returns the Format with the given name.
+func Parse(name string) (Format, error) {
+	for _, f := range formats {
+		if strings.EqualFold(name, string(f)) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q (formats: %s)", name, names())
+}
+
+func names() string {
+	var ss []string
+	for _, f := range formats {
+		ss = append(ss, string(f))
+	}
+	return strings.Join(ss, ", ")
+}
+
+// String implements part of the flag.Value interface.
+func (f *Format) String() string { return string(*f) }
+
+// Set implements part of the flag.Value interface.
+func (f *Format) Set(name string) error {
+	v, err := Parse(name)
+	if err != nil {
+		return err
+	}
+	*f = v
+	return nil
+}
+
+// Tabular reports whether f writes a Table rather than messages.
+func (f Format) Tabular() bool { return f == Aligned || f == TSV || f == CSV }
+
+var jsonMarshaler = protojson.MarshalOptions{UseProtoNames: true, Indent: "  "}
+
+// WriteMessage writes msg to w in format f, which must be JSON or Proto.
+// Proto messages are each prefixed by their varint-encoded length, so that
+// a sequence of them can be read back with package delimited.
+func WriteMessage(w io.Writer, f Format, msg proto.Message) error {
+	switch f {
+	case JSON:
+		rec, err := jsonMarshaler.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(rec, '\n'))
+		return err
+	case Proto:
+		return delimited.NewWriter(w).PutProto(msg)
+	}
+	return fmt.Errorf("format %q cannot write messages", f)
+}
+
+// A Table is a list of rows of values under named columns.
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// NewTable returns an empty Table with the given columns.
+func NewTable(columns ...string) *Table { return &Table{Columns: columns} }
+
+// Add adds a row of values to t, one per column.
+func (t *Table) Add(values ...string) { t.Rows = append(t.Rows, values) }
+
+// Write writes t to w in format f, which must be Aligned, TSV, or CSV.
+func (t *Table) Write(w io.Writer, f Format) error {
+	switch f {
+	case Aligned:
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		for _, row := range append([][]string{upper(t.Columns)}, t.Rows...) {
+			if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+				return err
+			}
+		}
+		return tw.Flush()
+	case TSV, CSV:
+		tw := tabular.NewWriter(w, tabular.Format(f))
+		for _, row := range append([][]string{t.Columns}, t.Rows...) {
+			if err := tw.Write(row); err != nil {
+				return err
+			}
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("format %q cannot write tables", f)
+}
+
+func upper(ss []string) []string {
+	out := make([]string, len(ss))
+	for i, s := range ss {
+		out[i] = strings.ToUpper(s)
+	}
+	return out
+}
+
+// Flatten returns a Table with a row for each populated scalar field of msg,
+// under the columns "field" and "value".  Fields are named by their path
+// from msg, as in "reference[2].span.start.line_number" or
+// "nodes[kythe:#n].facts[/kythe/node/kind]".
+func Flatten(msg proto.Message) *Table {
+	t := NewTable("field", "value")
+	flatten(t, "", msg.ProtoReflect())
+	return t
+}
+
+func flatten(t *Table, prefix string, m protoreflect.Message) {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !m.Has(fd) {
+			continue
+		}
+		name := prefix + string(fd.Name())
+		v := m.Get(fd)
+		switch {
+		case fd.IsList():
+			list := v.List()
+			for j := 0; j < list.Len(); j++ {
+				flattenValue(t, name+"["+strconv.Itoa(j)+"]", fd, list.Get(j))
+			}
+		case fd.IsMap():
+			var keys []protoreflect.MapKey
+			v.Map().Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+				keys = append(keys, k)
+				return true
+			})
+			sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+			for _, k := range keys {
+				flattenValue(t, name+"["+k.String()+"]", fd.MapValue(), v.Map().Get(k))
+			}
+		default:
+			flattenValue(t, name, fd, v)
+		}
+	}
+}
+
+func flattenValue(t *Table, name string, fd protoreflect.FieldDescriptor, v protoreflect.Value) {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		flatten(t, name+".", v.Message())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			t.Add(name, string(ev.Name()))
+		} else {
+			t.Add(name, strconv.Itoa(int(v.Enum())))
+		}
+	case protoreflect.BytesKind:
+		if b := v.Bytes(); utf8.Valid(b) {
+			t.Add(name, string(b))
+		} else {
+			t.Add(name, base64.StdEncoding.EncodeToString(b))
+		}
+	default:
+		t.Add(name, v.String())
+	}
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package format
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"kythe.io/kythe/go/platform/delimited"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	gpb "kythe.io/kythe/proto/graph_go_proto"
+)
+
+func TestParse(t *testing.T) {
+	for _, name := range []string{"text", "JSON", "proto", "table", "tsv", "csv"} {
+		if _, err := Parse(name); err != nil {
+			t.Errorf("Parse(%q): %v", name, err)
+		}
+	}
+	if f, err := Parse("yaml"); err == nil {
+		t.Errorf("Parse(yaml): got %q, want error", f)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	f := Text
+	fs.Var(&f, "format", "")
+	if err := fs.Parse([]string{"--format=tsv"}); err != nil {
+		t.Fatal(err)
+	} else if f != TSV {
+		t.Errorf("--format=tsv: got %q", f)
+	}
+}
+
+func TestTableWrite(t *testing.T) {
+	tab := NewTable("path", "line")
+	tab.Add("a.go", "1")
+	tab.Add("dir/b c.go", "10")
+
+	tests := []struct {
+		f    Format
+		want string
+	}{
+		{Aligned, "PATH        LINE\na.go        1\ndir/b c.go  10\n"},
+		{TSV, "path\tline\na.go\t1\ndir/b c.go\t10\n"},
+		{CSV, "path,line\na.go,1\ndir/b c.go,10\n"},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := tab.Write(&buf, test.f); err != nil {
+			t.Errorf("Write(%s): %v", test.f, err)
+		} else if diff := cmp.Diff(test.want, buf.String()); diff != "" {
+			t.Errorf("Write(%s) (-want +got):\n%s", test.f, diff)
+		}
+	}
+	if err := tab.Write(new(bytes.Buffer), JSON); err == nil {
+		t.Error("Write(json): got nil error")
+	}
+}
+
+func TestWriteMessage(t *testing.T) {
+	msg := &cpb.Fact{Name: "/kythe/node/kind", Value: []byte("file")}
+
+	var buf bytes.Buffer
+	if err := WriteMessage(&buf, JSON, msg); err != nil {
+		t.Fatal(err)
+	}
+	got := new(cpb.Fact)
+	if err := protojson.Unmarshal(buf.Bytes(), got); err != nil {
+		t.Errorf("JSON: %v", err)
+	} else if !proto.Equal(got, msg) {
+		t.Errorf("JSON: got %v, want %v", got, msg)
+	}
+
+	buf.Reset()
+	for i := 0; i < 2; i++ {
+		if err := WriteMessage(&buf, Proto, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rd := delimited.NewReader(&buf)
+	for i := 0; i < 2; i++ {
+		got := new(cpb.Fact)
+		if err := rd.NextProto(got); err != nil {
+			t.Fatalf("Reading message %d: %v", i, err)
+		} else if !proto.Equal(got, msg) {
+			t.Errorf("Message %d: got %v, want %v", i, got, msg)
+		}
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	reply := &gpb.EdgesReply{
+		EdgeSets: map[string]*gpb.EdgeSet{
+			"kythe:#b": {Groups: map[string]*gpb.EdgeSet_Group{
+				"/kythe/edge/ref": {Edge: []*gpb.EdgeSet_Group_Edge{
+					{TargetTicket: "kythe:#t1"},
+					{TargetTicket: "kythe:#t2", Ordinal: 3},
+				}},
+			}},
+			"kythe:#a": {},
+		},
+		NextPageToken: "next",
+	}
+	got := Flatten(reply)
+	want := &Table{
+		Columns: []string{"field", "value"},
+		Rows: [][]string{
+			{"edge_sets[kythe:#b].groups[/kythe/edge/ref].edge[0].target_ticket", "kythe:#t1"},
+			{"edge_sets[kythe:#b].groups[/kythe/edge/ref].edge[1].target_ticket", "kythe:#t2"},
+			{"edge_sets[kythe:#b].groups[/kythe/edge/ref].edge[1].ordinal", "3"},
+			{"next_page_token", "next"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Flatten (-want +got):\n%s", diff)
+	}
+}
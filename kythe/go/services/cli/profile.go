@@ -0,0 +1,200 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"kythe.io/kythe/go/services/web"
+)
+
+// A Config holds named profiles for the servers the CLI talks to.  It is
+// read from a JSON file such as:
+//
+//	{
+//	  "default_profile": "staging",
+//	  "profiles": {
+//	    "staging": {
+//	      "api": "https://kythe.staging.example.com",
+//	      "auth_token": "$KYTHE_STAGING_TOKEN",
+//	      "corpus": "example.com/repo"
+//	    },
+//	    "prod": {
+//	      "api": "https://kythe.example.com",
+//	      "auth_token_file": "~/.kythe/prod-token",
+//	      "tls": {"ca_file": "/etc/ssl/example-ca.pem"}
+//	    }
+//	  }
+//	}
+type Config struct {
+	DefaultProfile string              `json:"default_profile,omitempty"`
+	Profiles       map[string]*Profile `json:"profiles"`
+}
+
+// A Profile describes a server and how to reach it.  Environment variables
+// in its strings, written $VAR or ${VAR}, are expanded, and paths starting
+// with "~/" are relative to the user's home directory.
+type Profile struct {
+	API           string     `json:"api"`                       // API specification, as for --api
+	AuthToken     string     `json:"auth_token,omitempty"`      // bearer token sent with each request
+	AuthTokenFile string     `json:"auth_token_file,omitempty"` // file holding the bearer token
+	TLS           *TLSConfig `json:"tls,omitempty"`
+	Corpus        string     `json:"corpus,omitempty"` // default --corpus for file paths
+	Root          string     `json:"root,omitempty"`   // default --root for file paths
+}
+
+// TLSConfig configures the TLS connections to a server.
+type TLSConfig struct {
+	CAFile             string `json:"ca_file,omitempty"`   // PEM certificates of trusted authorities
+	CertFile           string `json:"cert_file,omitempty"` // PEM client certificate
+	KeyFile            string `json:"key_file,omitempty"`  // PEM client key
+	ServerName         string `json:"server_name,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+}
+
+// DefaultConfigPath returns the default location of the configuration file,
+// kythe/cli.json in the user's configuration directory.
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "kythe", "cli.json")
+}
+
+// LoadConfig reads the configuration file at path.  A missing file yields an
+// empty Config.
+func LoadConfig(path string) (*Config, error) {
+	cfg := new(Config)
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// Profile returns the named profile or, if name == "", the default profile.
+// It returns nil without error if name == "" and there is no default.
+func (c *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		if name = c.DefaultProfile; name == "" {
+			return nil, nil
+		}
+	}
+	p, ok := c.Profiles[name]
+	if !ok || p == nil {
+		var names []string
+		for n := range c.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown profile %q (profiles: %s)", name, strings.Join(names, ", "))
+	}
+	return p, nil
+}
+
+// Apply makes the defaults of p the defaults of the CLI commands.
+func (p *Profile) Apply() {
+	if p.Corpus != "" {
+		DefaultFileCorpus = os.ExpandEnv(p.Corpus)
+	}
+	if p.Root != "" {
+		DefaultFileRoot = os.ExpandEnv(p.Root)
+	}
+}
+
+// WebOptions returns the options for calling the JSON web API of p.
+func (p *Profile) WebOptions() (*web.Options, error) {
+	opts := new(web.Options)
+	token := os.ExpandEnv(p.AuthToken)
+	if p.AuthTokenFile != "" {
+		if token != "" {
+			return nil, errors.New("auth_token and auth_token_file are mutually exclusive")
+		}
+		data, err := os.ReadFile(expandPath(p.AuthTokenFile))
+		if err != nil {
+			return nil, fmt.Errorf("reading auth token: %v", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		opts.Header = http.Header{"Authorization": {"Bearer " + token}}
+	}
+	if p.TLS != nil {
+		cfg, err := p.TLS.config()
+		if err != nil {
+			return nil, err
+		}
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = cfg
+		opts.HTTPClient = &http.Client{Transport: t}
+	}
+	return opts, nil
+}
+
+func (t *TLSConfig) config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         os.ExpandEnv(t.ServerName),
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(expandPath(t.CAFile))
+		if err != nil {
+			return nil, fmt.Errorf("reading CA certificates: %v", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", t.CAFile)
+		}
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(expandPath(t.CertFile), expandPath(t.KeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// expandPath expands the environment variables in path, and a leading "~/".
+func expandPath(path string) string {
+	path = os.ExpandEnv(path)
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testConfig = `{
+  "default_profile": "staging",
+  "profiles": {
+    "staging": {
+      "api": "https://kythe.staging.example.com",
+      "auth_token": "$KYTHE_TEST_TOKEN",
+      "corpus": "example.com/${KYTHE_TEST_REPO}",
+      "root": "gen"
+    },
+    "prod": {
+      "api": "https://kythe.example.com",
+      "auth_token_file": "TOKEN_FILE"
+    }
+  }
+}`
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	writeFile(t, tokenFile, "prod-token\n")
+	path := filepath.Join(dir, "cli.json")
+	writeFile(t, path, strings.Replace(testConfig, "TOKEN_FILE", tokenFile, 1))
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DefaultProfile != "staging" || len(cfg.Profiles) != 2 {
+		t.Errorf("LoadConfig: got %+v", cfg)
+	}
+
+	// A missing file yields an empty configuration.
+	for _, path := range []string{"", filepath.Join(dir, "missing.json")} {
+		if cfg, err := LoadConfig(path); err != nil || cfg.DefaultProfile != "" || len(cfg.Profiles) != 0 {
+			t.Errorf("LoadConfig(%q): got %+v, %v; want empty config", path, cfg, err)
+		}
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	writeFile(t, bad, "{")
+	if _, err := LoadConfig(bad); err == nil {
+		t.Error("LoadConfig of invalid JSON: got no error")
+	}
+}
+
+func TestProfile(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	writeFile(t, tokenFile, "prod-token\n")
+	path := filepath.Join(dir, "cli.json")
+	writeFile(t, path, strings.Replace(testConfig, "TOKEN_FILE", tokenFile, 1))
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KYTHE_TEST_TOKEN", "staging-token")
+	t.Setenv("KYTHE_TEST_REPO", "repo")
+
+	tests := []struct {
+		name, api, auth string
+	}{
+		{"", "https://kythe.staging.example.com", "Bearer staging-token"},
+		{"staging", "https://kythe.staging.example.com", "Bearer staging-token"},
+		{"prod", "https://kythe.example.com", "Bearer prod-token"},
+	}
+	for _, test := range tests {
+		p, err := cfg.Profile(test.name)
+		if err != nil {
+			t.Fatalf("Profile(%q): %v", test.name, err)
+		}
+		if p.API != test.api {
+			t.Errorf("Profile(%q).API: got %q, want %q", test.name, p.API, test.api)
+		}
+		opts, err := p.WebOptions()
+		if err != nil {
+			t.Fatalf("Profile(%q).WebOptions: %v", test.name, err)
+		}
+		if got := opts.Header.Get("Authorization"); got != test.auth {
+			t.Errorf("Profile(%q) Authorization: got %q, want %q", test.name, got, test.auth)
+		}
+	}
+
+	if _, err := cfg.Profile("missing"); err == nil || !strings.Contains(err.Error(), "prod, staging") {
+		t.Errorf("Profile(missing): got %v, want an error listing the profiles", err)
+	}
+	if p, err := new(Config).Profile(""); p != nil || err != nil {
+		t.Errorf("Profile of an empty config: got %v, %v; want nil, nil", p, err)
+	}
+}
+
+func TestProfileApply(t *testing.T) {
+	defer func(corpus, root string) { DefaultFileCorpus, DefaultFileRoot = corpus, root }(DefaultFileCorpus, DefaultFileRoot)
+	DefaultFileCorpus, DefaultFileRoot = "flag-corpus", "flag-root"
+	t.Setenv("KYTHE_TEST_REPO", "repo")
+
+	// Unset fields keep the defaults.
+	(&Profile{Root: "gen"}).Apply()
+	(&Profile{Corpus: "example.com/${KYTHE_TEST_REPO}"}).Apply()
+	if diff := cmp.Diff([]string{"example.com/repo", "gen"}, []string{DefaultFileCorpus, DefaultFileRoot}); diff != "" {
+		t.Errorf("Defaults after Apply (-want +got):\n%s", diff)
+	}
+}
+
+func TestWebOptionsErrors(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []*Profile{
+		{AuthToken: "token", AuthTokenFile: filepath.Join(dir, "token")},
+		{AuthTokenFile: filepath.Join(dir, "missing")},
+		{TLS: &TLSConfig{CAFile: filepath.Join(dir, "missing.pem")}},
+		{TLS: &TLSConfig{CertFile: filepath.Join(dir, "missing.pem")}},
+	} {
+		if _, err := p.WebOptions(); err == nil {
+			t.Errorf("WebOptions(%+v): got no error", p)
+		}
+	}
+
+	empty := filepath.Join(dir, "empty.pem")
+	writeFile(t, empty, "")
+	if _, err := (&Profile{TLS: &TLSConfig{CAFile: empty}}).WebOptions(); err == nil {
+		t.Error("WebOptions with no CA certificates: got no error")
+	}
+
+	opts, err := (&Profile{TLS: &TLSConfig{ServerName: "kythe.example.com"}}).WebOptions()
+	if err != nil {
+		t.Fatal(err)
+	} else if opts.HTTPClient == nil {
+		t.Error("WebOptions with TLS settings: got no HTTP client")
+	}
+}
@@ -0,0 +1,347 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package editor implements a JSON-RPC endpoint for simple editor plugins.
+//
+// The endpoint offers the core of code navigation to editors that cannot run
+// a full Language Server Protocol client.  A plugin POSTs a JSON-RPC 2.0
+// request naming one of the methods
+//
+//	define      the definitions of the symbol at a position
+//	references  the references to the symbol at a position
+//	hover       the signature and documentation of the symbol at a position
+//
+// with parameters giving the absolute local path of a file and a byte offset
+// within it,
+//
+//	{"jsonrpc": "2.0", "id": 1, "method": "define",
+//	 "params": {"path": "/home/me/src/kythe/go/util/log/log.go", "offset": 1234}}
+//
+// and optionally the current text of the file, if it has unsaved changes.
+// The server maps local paths to Kythe files and back using the workspace
+// settings of the language server, so plugins need no configuration beyond
+// the server's address.  Locations in the result are local paths, or Kythe
+// URIs for files outside every workspace, with byte offsets and 1-based
+// lines and byte columns.
+package editor // import "kythe.io/kythe/go/services/editor"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"kythe.io/kythe/go/languageserver"
+	"kythe.io/kythe/go/services/nav"
+	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+// defaultPageSize is the default maximum number of references returned.
+const defaultPageSize = 1000
+
+// Params are the parameters of each method.
+type Params struct {
+	Path   string `json:"path"`   // absolute local path of the file
+	Offset int    `json:"offset"` // byte offset within the file
+	Text   string `json:"text"`   // optional text of the file, if it is modified
+}
+
+// A Location is a span of a file.
+type Location struct {
+	Path      string `json:"path"` // local path, or Kythe URI of the file
+	Offset    int    `json:"offset"`
+	EndOffset int    `json:"end_offset"`
+	Line      int    `json:"line"` // 1-based
+	Col       int    `json:"col"`  // 1-based, in bytes
+	Snippet   string `json:"snippet,omitempty"`
+}
+
+// A Server answers editor requests from an xrefs service.
+type Server struct {
+	XRefs xrefs.Service
+
+	// Workspaces map between local paths and Kythe files.  The workspace with
+	// the longest root enclosing a path is used for it.
+	Workspaces []*languageserver.SettingsWorkspace
+
+	// PageSize is the maximum number of references returned (default 1000).
+	PageSize int
+}
+
+// NewServer returns a Server for the workspaces of the given settings.
+func NewServer(xs xrefs.Service, settings []languageserver.Settings) (*Server, error) {
+	s := &Server{XRefs: xs}
+	for _, set := range settings {
+		w, err := languageserver.NewSettingsWorkspace(set)
+		if err != nil {
+			return nil, fmt.Errorf("workspace %q: %v", set.Root, err)
+		}
+		s.Workspaces = append(s.Workspaces, w)
+	}
+	return s, nil
+}
+
+func (s *Server) pageSize() int {
+	if s.PageSize <= 0 {
+		return defaultPageSize
+	}
+	return s.PageSize
+}
+
+// ticket returns the Kythe URI of the file at the given local path.
+func (s *Server) ticket(path string) (string, error) {
+	path = filepath.Clean(path)
+	var ws *languageserver.SettingsWorkspace
+	for _, w := range s.Workspaces {
+		root := filepath.Clean(w.Root())
+		if (path == root || strings.HasPrefix(path, root+string(filepath.Separator))) &&
+			(ws == nil || len(root) > len(filepath.Clean(ws.Root()))) {
+			ws = w
+		}
+	}
+	if ws == nil {
+		return "", status.Errorf(codes.InvalidArgument, "%q is not within a workspace", path)
+	}
+	rel, err := filepath.Rel(filepath.Clean(ws.Root()), path)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	u, err := ws.KytheURIFromRelative(rel)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	return u.String(), nil
+}
+
+// localPath returns the local path of the file with the given ticket, or the
+// ticket itself if no workspace holds it.
+func (s *Server) localPath(ticket string) string {
+	u, err := kytheuri.Parse(ticket)
+	if err != nil {
+		return ticket
+	}
+	for _, w := range s.Workspaces {
+		if f, err := w.LocalFromKytheURI(*u); err == nil {
+			return f.String()
+		}
+	}
+	return ticket
+}
+
+// symbol returns the ticket of the symbol at the position of p, or "" if
+// there is none.
+func (s *Server) symbol(ctx context.Context, p *Params) (string, error) {
+	if p.Path == "" || p.Offset < 0 {
+		return "", status.Errorf(codes.InvalidArgument, "invalid position %q:%d", p.Path, p.Offset)
+	}
+	file, err := s.ticket(p.Path)
+	if err != nil {
+		return "", err
+	}
+	point := &cpb.Point{ByteOffset: int32(p.Offset)}
+	decor, err := s.XRefs.Decorations(ctx, &xpb.DecorationsRequest{
+		Location: &xpb.Location{
+			Ticket: file,
+			Kind:   xpb.Location_SPAN,
+			Span:   &cpb.Span{Start: point, End: point},
+		},
+		SpanKind:    xpb.DecorationsRequest_AROUND_SPAN,
+		DirtyBuffer: []byte(p.Text),
+		References:  true,
+	})
+	if err != nil {
+		return "", err
+	}
+	ref := nav.Narrowest(decor.Reference, decor.GetLocation().GetSpan().GetStart().GetByteOffset())
+	if ref == nil {
+		return "", nil
+	}
+	return ref.TargetTicket, nil
+}
+
+// Define returns the definitions of the symbol at the position of p.
+func (s *Server) Define(ctx context.Context, p *Params) ([]*Location, error) {
+	return s.crossReferences(ctx, p, false)
+}
+
+// References returns the references to the symbol at the position of p.
+func (s *Server) References(ctx context.Context, p *Params) ([]*Location, error) {
+	return s.crossReferences(ctx, p, true)
+}
+
+func (s *Server) crossReferences(ctx context.Context, p *Params, refs bool) ([]*Location, error) {
+	ticket, err := s.symbol(ctx, p)
+	if err != nil || ticket == "" {
+		return []*Location{}, err
+	}
+	req := &xpb.CrossReferencesRequest{
+		Ticket:         []string{ticket},
+		DefinitionKind: xpb.CrossReferencesRequest_BINDING_DEFINITIONS,
+		PageSize:       int32(s.pageSize()),
+	}
+	if refs {
+		req.ReferenceKind = xpb.CrossReferencesRequest_ALL_REFERENCES
+		req.Snippets = xpb.SnippetsKind_DEFAULT
+	}
+	reply, err := s.XRefs.CrossReferences(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	set := reply.CrossReferences[ticket]
+	anchors := set.GetDefinition()
+	if refs {
+		anchors = set.GetReference()
+	}
+	locs := []*Location{}
+	for _, a := range anchors {
+		locs = append(locs, s.location(a.Anchor))
+	}
+	return locs, nil
+}
+
+func (s *Server) location(a *xpb.Anchor) *Location {
+	start := a.GetSpan().GetStart()
+	return &Location{
+		Path:      s.localPath(a.Parent),
+		Offset:    int(start.GetByteOffset()),
+		EndOffset: int(a.GetSpan().GetEnd().GetByteOffset()),
+		Line:      int(start.GetLineNumber()),
+		Col:       int(start.GetColumnOffset()) + 1,
+		Snippet:   a.Snippet,
+	}
+}
+
+// Hover returns the signature and documentation of the symbol at the
+// position of p, or nil if it has neither.
+func (s *Server) Hover(ctx context.Context, p *Params) (*nav.Hover, error) {
+	ticket, err := s.symbol(ctx, p)
+	if err != nil || ticket == "" {
+		return nil, err
+	}
+	return nav.FindHover(ctx, s.XRefs, ticket)
+}
+
+// JSON-RPC 2.0 error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+type request struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// call dispatches req to the method it names.
+func (s *Server) call(ctx context.Context, req *request) *response {
+	resp := &response{Version: "2.0", ID: req.ID}
+	if req.ID == nil {
+		resp.ID = json.RawMessage("null")
+	}
+	fail := func(code int, format string, args ...any) *response {
+		resp.Result = nil
+		resp.Error = &rpcError{Code: code, Message: fmt.Sprintf(format, args...)}
+		return resp
+	}
+	if req.Version != "2.0" {
+		return fail(codeInvalidRequest, "unsupported JSON-RPC version %q", req.Version)
+	}
+	var p Params
+	if err := json.Unmarshal(req.Params, &p); err != nil {
+		return fail(codeInvalidParams, "invalid params: %v", err)
+	}
+
+	var err error
+	switch req.Method {
+	case "define":
+		resp.Result, err = s.Define(ctx, &p)
+	case "references":
+		resp.Result, err = s.References(ctx, &p)
+	case "hover":
+		resp.Result, err = s.Hover(ctx, &p)
+	default:
+		return fail(codeMethodNotFound, "unknown method %q", req.Method)
+	}
+	if status.Code(err) == codes.InvalidArgument {
+		return fail(codeInvalidParams, "%v", status.Convert(err).Message())
+	} else if err != nil {
+		return fail(codeInternalError, "%v", err)
+	}
+	return resp
+}
+
+// RegisterHTTPHandlers registers the JSON-RPC endpoint of s with mux at the
+// /editor path.
+func RegisterHTTPHandlers(ctx context.Context, s *Server, mux *http.ServeMux) {
+	mux.HandleFunc("/editor", func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		var req request
+		defer func() {
+			log.InfoContextf(ctx, "editor.%s:\t%s", req.Method, time.Since(start))
+		}()
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var resp *response
+		var tooLarge *http.MaxBytesError
+		if err := json.NewDecoder(r.Body).Decode(&req); errors.As(err, &tooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		} else if err != nil {
+			resp = &response{
+				Version: "2.0",
+				ID:      json.RawMessage("null"),
+				Error:   &rpcError{Code: codeParseError, Message: err.Error()},
+			}
+		} else {
+			resp = s.call(r.Context(), &req)
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			log.ErrorContextf(ctx, "Editor response error: %v", err)
+		}
+	})
+}
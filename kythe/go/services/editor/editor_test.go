@@ -0,0 +1,185 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package editor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/languageserver"
+	"kythe.io/kythe/go/services/xrefs"
+
+	"github.com/google/go-cmp/cmp"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+const (
+	fileTicket  = "kythe://corpus?path=a.go"
+	otherTicket = "kythe://other?path=b.go"
+	funcTicket  = "kythe://corpus?lang=go#f"
+)
+
+func span(start, end, line, col int32) *cpb.Span {
+	return &cpb.Span{
+		Start: &cpb.Point{ByteOffset: start, LineNumber: line, ColumnOffset: col},
+		End:   &cpb.Point{ByteOffset: end, LineNumber: line, ColumnOffset: col + end - start},
+	}
+}
+
+// fakeXRefs serves a.go, which calls f at offsets [10,11).  f is defined in
+// a.go and referred to from b.go in another corpus.
+type fakeXRefs struct {
+	xrefs.Service
+	decor *xpb.DecorationsRequest
+	xrefs *xpb.CrossReferencesRequest
+}
+
+func (f *fakeXRefs) Decorations(_ context.Context, req *xpb.DecorationsRequest) (*xpb.DecorationsReply, error) {
+	f.decor = req
+	if req.Location.Ticket != fileTicket {
+		return nil, xrefs.ErrDecorationsNotFound
+	}
+	return &xpb.DecorationsReply{
+		Location: req.Location,
+		Reference: []*xpb.DecorationsReply_Reference{{
+			TargetTicket: funcTicket,
+			Kind:         "/kythe/edge/ref/call",
+			Span:         span(10, 11, 2, 1),
+		}},
+	}, nil
+}
+
+func (f *fakeXRefs) CrossReferences(_ context.Context, req *xpb.CrossReferencesRequest) (*xpb.CrossReferencesReply, error) {
+	f.xrefs = req
+	return &xpb.CrossReferencesReply{
+		CrossReferences: map[string]*xpb.CrossReferencesReply_CrossReferenceSet{
+			funcTicket: {
+				Ticket: funcTicket,
+				Definition: []*xpb.CrossReferencesReply_RelatedAnchor{{
+					Anchor: &xpb.Anchor{Parent: fileTicket, Span: span(5, 6, 1, 5)},
+				}},
+				Reference: []*xpb.CrossReferencesReply_RelatedAnchor{{
+					Anchor: &xpb.Anchor{Parent: otherTicket, Span: span(0, 1, 1, 0), Snippet: "f()"},
+				}},
+			},
+		},
+	}, nil
+}
+
+func (f *fakeXRefs) Documentation(context.Context, *xpb.DocumentationRequest) (*xpb.DocumentationReply, error) {
+	return &xpb.DocumentationReply{Document: []*xpb.DocumentationReply_Document{{
+		Ticket: funcTicket,
+		Text:   &xpb.Printable{RawText: "f does nothing."},
+	}}}, nil
+}
+
+func newServer(t *testing.T) (*Server, *fakeXRefs) {
+	t.Helper()
+	xs := new(fakeXRefs)
+	s, err := NewServer(xs, []languageserver.Settings{{
+		Root: "/src",
+		Mappings: []languageserver.MappingConfig{{
+			Local: ":path*",
+			VName: languageserver.VNameConfig{Corpus: "corpus", Path: ":path*"},
+		}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, xs
+}
+
+func call(t *testing.T, s *Server, body string) map[string]any {
+	t.Helper()
+	mux := http.NewServeMux()
+	RegisterHTTPHandlers(context.Background(), s, mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/editor", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST %s: status %d", body, rec.Code)
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Decoding %q: %v", rec.Body, err)
+	}
+	return resp
+}
+
+func TestMethods(t *testing.T) {
+	s, xs := newServer(t)
+	tests := []struct {
+		method string
+		want   any
+	}{
+		{"define", []any{map[string]any{
+			"path": "/src/a.go", "offset": 5.0, "end_offset": 6.0, "line": 1.0, "col": 6.0,
+		}}},
+		{"references", []any{map[string]any{
+			"path": otherTicket, "offset": 0.0, "end_offset": 1.0, "line": 1.0, "col": 1.0, "snippet": "f()",
+		}}},
+		{"hover", map[string]any{"doc": "f does nothing."}},
+	}
+	for _, test := range tests {
+		resp := call(t, s, `{"jsonrpc": "2.0", "id": 7, "method": "`+test.method+`", "params": {"path": "/src/./a.go", "offset": 10}}`)
+		want := map[string]any{"jsonrpc": "2.0", "id": 7.0, "result": test.want}
+		if diff := cmp.Diff(want, resp); diff != "" {
+			t.Errorf("%s (-want +got):\n%s", test.method, diff)
+		}
+	}
+	if got := xs.decor.Location; got.Ticket != fileTicket || got.Span.Start.ByteOffset != 10 {
+		t.Errorf("Decorations requested at %v, want %s:10", got, fileTicket)
+	}
+	if xs.xrefs.ReferenceKind != xpb.CrossReferencesRequest_ALL_REFERENCES || xs.xrefs.PageSize != defaultPageSize {
+		t.Errorf("References requested with %v", xs.xrefs)
+	}
+
+	// There is no symbol at offset 0.
+	resp := call(t, s, `{"jsonrpc": "2.0", "id": "x", "method": "hover", "params": {"path": "/src/a.go", "offset": 0}}`)
+	if diff := cmp.Diff(map[string]any{"jsonrpc": "2.0", "id": "x", "result": nil}, resp); diff != "" {
+		t.Errorf("hover without symbol (-want +got):\n%s", diff)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	s, _ := newServer(t)
+	tests := []struct {
+		body string
+		code float64
+	}{
+		{`{`, codeParseError},
+		{`{"jsonrpc": "1.0", "id": 1, "method": "define", "params": {}}`, codeInvalidRequest},
+		{`{"jsonrpc": "2.0", "id": 1, "method": "rename", "params": {}}`, codeMethodNotFound},
+		{`{"jsonrpc": "2.0", "id": 1, "method": "define", "params": {"path": "/elsewhere/a.go"}}`, codeInvalidParams},
+		{`{"jsonrpc": "2.0", "id": 1, "method": "define", "params": {"path": "/src/c.go"}}`, codeInternalError},
+	}
+	for _, test := range tests {
+		resp := call(t, s, test.body)
+		e, ok := resp["error"].(map[string]any)
+		if !ok || e["code"] != test.code {
+			t.Errorf("%s: got %v, want error code %v", test.body, resp, test.code)
+		}
+		if _, ok := resp["result"]; ok {
+			t.Errorf("%s: error response has a result: %v", test.body, resp)
+		}
+	}
+}
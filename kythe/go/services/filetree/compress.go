@@ -0,0 +1,159 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filetree
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// SetCompressed sets whether m stores the entries of its directories
+// compressed.  A compressed directory holds its entries sorted by name and
+// front-coded: each name is stored as the length of the prefix it shares with
+// the previous name, followed by the rest of the name.  Entries are decoded
+// for each request, trading a little CPU per request for a large reduction in
+// the memory used by directories of similarly named files.
+//
+// The shards changed by an Update are compressed as it is committed.  The
+// entries of a compressed directory are listed in order of name, rather than
+// the order in which they were added.
+func (m *Map) SetCompressed(compressed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.compressed = compressed
+}
+
+// A packedEntry is a directory entry being compressed.
+type packedEntry struct {
+	name  string
+	flags uint8
+	info  int32 // as in an entry of the compacted shard
+}
+
+// The flag of a compressed entry followed by the index of its metadata.
+const packedInfo uint8 = 1 << 7
+
+// compact returns a copy of s in which the entries of every directory are
+// compressed into the packed array.  Its name table holds only the roots and
+// paths of its directories, and the names in the metadata of its files.
+func (s *shard) compact() *shard {
+	c := newShard(s.corpus)
+	c.roots = s.roots
+	// copyInfo copies metadata from s to c.
+	copyInfo := func(info int32) int32 {
+		if info == 0 {
+			return 0
+		}
+		fi := s.infos[info-1]
+		c.infos = append(c.infos, fileInfo{
+			language: c.names.intern(s.names.str(fi.language)),
+			encoding: c.names.intern(s.names.str(fi.encoding)),
+			size:     fi.size,
+		})
+		return int32(len(c.infos))
+	}
+	var entries []packedEntry
+	for i, d := range s.dirList {
+		entries = entries[:0]
+		s.eachPacked(int32(i), func(name []byte, flags uint8, info int32) bool {
+			entries = append(entries, packedEntry{string(name), flags, copyInfo(info)})
+			return true
+		})
+		for j := d.first; j >= 0; j = s.entries[j].next {
+			e := s.entries[j]
+			entries = append(entries, packedEntry{s.names.str(e.name), e.flags, copyInfo(e.info)})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].name != entries[j].name {
+				return entries[i].name < entries[j].name
+			}
+			return entries[i].flags < entries[j].flags
+		})
+
+		key := dirKey{
+			root: c.names.intern(s.names.str(d.key.root)),
+			path: c.names.intern(s.names.str(d.key.path)),
+		}
+		off := len(c.packed)
+		c.packed = appendPacked(c.packed, entries)
+		c.dirList[c.newDir(key)].packed = span{off: uint32(off), len: uint32(len(c.packed) - off)}
+	}
+	return c
+}
+
+// appendPacked appends the front-coded encoding of the given sorted entries
+// to buf, omitting duplicates.  The metadata of a compressed entry follows its
+// flags, as the index of the metadata if the flags include packedInfo.
+func appendPacked(buf []byte, entries []packedEntry) []byte {
+	var prev packedEntry
+	for i, e := range entries {
+		if i > 0 && e.name == prev.name && e.flags == prev.flags {
+			continue
+		}
+		shared := commonPrefix(prev.name, e.name)
+		buf = binary.AppendUvarint(buf, uint64(shared))
+		buf = binary.AppendUvarint(buf, uint64(len(e.name)-shared))
+		buf = append(buf, e.name[shared:]...)
+		if e.info > 0 {
+			buf = append(buf, e.flags|packedInfo)
+			buf = binary.AppendUvarint(buf, uint64(e.info))
+		} else {
+			buf = append(buf, e.flags)
+		}
+		prev = e
+	}
+	return buf
+}
+
+func commonPrefix(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// eachPacked calls f with the name, flags, and metadata index of each
+// compressed entry of directory d, in order, until f returns false.  The name
+// is valid only until f returns.
+func (s *shard) eachPacked(d int32, f func(name []byte, flags uint8, info int32) bool) {
+	sp := s.dirList[d].packed
+	if sp.len == 0 {
+		return
+	}
+	data := s.packed[sp.off : sp.off+sp.len]
+	var name []byte
+	for len(data) > 0 {
+		shared, n := binary.Uvarint(data)
+		data = data[n:]
+		suffix, n := binary.Uvarint(data)
+		data = data[n:]
+		name = append(name[:shared], data[:suffix]...)
+		data = data[suffix:]
+		flags := data[0]
+		data = data[1:]
+		var info int32
+		if flags&packedInfo != 0 {
+			i, n := binary.Uvarint(data)
+			data = data[n:]
+			flags, info = flags&^packedInfo, int32(i)
+		}
+		if !f(name, flags, info) {
+			return
+		}
+	}
+}
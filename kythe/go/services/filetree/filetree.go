@@ -20,20 +20,34 @@ package filetree // import "kythe.io/kythe/go/services/filetree"
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"path"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"kythe.io/kythe/go/services/graphstore"
 	"kythe.io/kythe/go/services/web"
+	"kythe.io/kythe/go/storage/keyvalue"
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/util/datasize"
 	"kythe.io/kythe/go/util/log"
+	"kythe.io/kythe/go/util/progress"
+	"kythe.io/kythe/go/util/schema"
 	"kythe.io/kythe/go/util/schema/facts"
 	"kythe.io/kythe/go/util/schema/nodes"
 
-	"google.golang.org/protobuf/proto"
+	"golang.org/x/text/unicode/norm"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	ftpb "kythe.io/kythe/proto/filetree_go_proto"
 	spb "kythe.io/kythe/proto/storage_go_proto"
@@ -47,68 +61,512 @@ type Service interface {
 	// CorpusRoots returns a map from corpus to known roots.
 	CorpusRoots(context.Context, *ftpb.CorpusRootsRequest) (*ftpb.CorpusRootsReply, error)
 
+	// Tree returns the contents of the directory at the given corpus/root/path,
+	// with the contents of its subdirectories nested in their entries.
+	Tree(context.Context, *ftpb.TreeRequest) (*ftpb.DirectoryReply, error)
+
+	// Search returns the tickets of the files of the given corpus/root whose
+	// paths match a pattern, ordered by path.
+	Search(context.Context, *ftpb.SearchRequest) (*ftpb.SearchReply, error)
+
 	// Close releases any underlying resources.
 	Close(context.Context) error
 }
 
-// CleanDirPath returns a clean, corpus root relative equivalent to path.
+// BoundedRequests guards against requests for directories nested more than
+// MaxPathDepth deep, for pages or searches of more than MaxPageSize entries,
+// or for trees more than MaxTreeDepth levels deep.  A zero limit is not
+// enforced.
+type BoundedRequests struct {
+	MaxPathDepth int
+	MaxPageSize  int
+	MaxTreeDepth int
+	Service
+}
+
+// Directory implements part of the Service interface.
+func (b BoundedRequests) Directory(ctx context.Context, req *ftpb.DirectoryRequest) (*ftpb.DirectoryReply, error) {
+	if b.MaxPathDepth > 0 {
+		if depth := PathDepth(req.GetPath()); depth > b.MaxPathDepth {
+			return nil, status.Errorf(codes.InvalidArgument, "path too deep: %d components (max %d)", depth, b.MaxPathDepth)
+		}
+	}
+	if b.MaxPageSize > 0 && int(req.GetPageSize()) > b.MaxPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size too large: %d (max %d)", req.GetPageSize(), b.MaxPageSize)
+	}
+	return b.Service.Directory(ctx, req)
+}
+
+// Tree implements part of the Service interface.
+func (b BoundedRequests) Tree(ctx context.Context, req *ftpb.TreeRequest) (*ftpb.DirectoryReply, error) {
+	if b.MaxPathDepth > 0 {
+		if depth := PathDepth(req.GetPath()); depth > b.MaxPathDepth {
+			return nil, status.Errorf(codes.InvalidArgument, "path too deep: %d components (max %d)", depth, b.MaxPathDepth)
+		}
+	}
+	if b.MaxTreeDepth > 0 && (req.GetMaxDepth() <= 0 || int(req.GetMaxDepth()) > b.MaxTreeDepth) {
+		return nil, status.Errorf(codes.InvalidArgument, "max_depth must be between 1 and %d; got %d", b.MaxTreeDepth, req.GetMaxDepth())
+	}
+	return b.Service.Tree(ctx, req)
+}
+
+// Search implements part of the Service interface.
+func (b BoundedRequests) Search(ctx context.Context, req *ftpb.SearchRequest) (*ftpb.SearchReply, error) {
+	if b.MaxPageSize > 0 && (req.GetMaxResults() <= 0 || int(req.GetMaxResults()) > b.MaxPageSize) {
+		return nil, status.Errorf(codes.InvalidArgument, "max_results must be between 1 and %d; got %d", b.MaxPageSize, req.GetMaxResults())
+	}
+	return b.Service.Search(ctx, req)
+}
+
+// Tree returns the tree requested by req, reading each of its directories
+// with dir.  It implements the Tree method of a Service from its Directory
+// method, which must return a new reply for each call.
+func Tree(ctx context.Context, dir func(context.Context, *ftpb.DirectoryRequest) (*ftpb.DirectoryReply, error), req *ftpb.TreeRequest) (*ftpb.DirectoryReply, error) {
+	var fill func(string, []*ftpb.DirectoryReply_Entry, int32) error
+	fill = func(dirPath string, entries []*ftpb.DirectoryReply_Entry, depth int32) error {
+		if depth == 0 {
+			return nil
+		}
+		for _, e := range entries {
+			if e.GetKind() != ftpb.DirectoryReply_DIRECTORY {
+				continue
+			} else if err := ctx.Err(); err != nil {
+				return err
+			}
+			sub, err := dir(ctx, &ftpb.DirectoryRequest{
+				Corpus:                  req.GetCorpus(),
+				Root:                    req.GetRoot(),
+				Path:                    path.Join(dirPath, e.GetName()),
+				IncludeFilesMissingText: req.GetIncludeFilesMissingText(),
+			})
+			if err != nil {
+				return err
+			}
+			e.Entry = sub.GetEntry()
+			if err := fill(path.Join(dirPath, e.GetName()), e.Entry, depth-1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	reply, err := dir(ctx, &ftpb.DirectoryRequest{
+		Corpus:                  req.GetCorpus(),
+		Root:                    req.GetRoot(),
+		Path:                    req.GetPath(),
+		IncludeFilesMissingText: req.GetIncludeFilesMissingText(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// A non-positive max_depth counts down without reaching zero.
+	if err := fill(req.GetPath(), reply.Entry, req.GetMaxDepth()-1); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// PageEntries limits the entries of reply to the page of them requested by
+// req, and returns reply.  A paged reply orders its entries by their page
+// tokens, which are their names, with a trailing "/" for directories; its
+// NextPageToken is the token of its last entry if more entries follow.
+func PageEntries(req *ftpb.DirectoryRequest, reply *ftpb.DirectoryReply) (*ftpb.DirectoryReply, error) {
+	size, token := req.GetPageSize(), req.GetPageToken()
+	if size < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid page_size: %d", size)
+	} else if size == 0 && token == "" {
+		return reply, nil
+	}
+	slices.SortFunc(reply.Entry, func(a, b *ftpb.DirectoryReply_Entry) int {
+		return strings.Compare(entryToken(a), entryToken(b))
+	})
+	if token != "" {
+		i := sort.Search(len(reply.Entry), func(i int) bool { return entryToken(reply.Entry[i]) > token })
+		reply.Entry = reply.Entry[i:]
+	}
+	reply.NextPageToken = ""
+	if size > 0 && len(reply.Entry) > int(size) {
+		reply.Entry = reply.Entry[:size]
+		reply.NextPageToken = entryToken(reply.Entry[size-1])
+	}
+	return reply, nil
+}
+
+// entryToken returns the page token naming e.
+func entryToken(e *ftpb.DirectoryReply_Entry) string {
+	if e.GetKind() == ftpb.DirectoryReply_DIRECTORY {
+		return e.GetName() + "/"
+	}
+	return e.GetName()
+}
+
+// PathDepth returns the number of components of the clean equivalent of path.
+func PathDepth(path string) int {
+	if path = CleanDirPath(path); path == "" {
+		return 0
+	}
+	return strings.Count(path, string(filepath.Separator)) + 1
+}
+
+// CleanDirPath returns a clean, corpus root relative equivalent to path, in
+// Unicode normalization form C.
 func CleanDirPath(path string) string {
 	const sep = string(filepath.Separator)
-	return strings.TrimPrefix(filepath.Join(sep, path), sep)
+	return strings.TrimPrefix(filepath.Join(sep, norm.NFC.String(path)), sep)
 }
 
-// Map is a FileTree backed by an in-memory map.
+// Map is a FileTree backed by an in-memory map, holding the directories of
+// each corpus in a separate shard.  Directories are keyed by the NFC form of
+// their roots and paths, so that a directory is found however its name was
+// normalized; file names are kept as given, since they name the files' nodes.
+//
+// A Map is safe for concurrent use, so that it can serve requests while it is
+// populated or updated.  Readers never take locks: they use an immutable
+// snapshot of the shards, which each Update replaces.  An Update copies the
+// shards of the corpora it changes, and publishes them together when it is
+// committed; Updates are serialized by a mutex.
 type Map struct {
-	// corpus -> root -> dirPath -> DirectoryReply
-	M map[string]map[string]map[string]*ftpb.DirectoryReply
+	snap atomic.Pointer[snapshot]
+
+	mu         sync.Mutex // held by the Update in progress
+	compressed bool       // whether to compact shards on Commit; see SetCompressed
+	expected   int64      // number of entries Populate expects to scan; see SetExpectedEntries
+	fileFacts  bool       // whether Populate records the facts of files; see SetFileFacts
+
+	// If non-nil, the store to which Populate spills the directories of m
+	// once their size exceeds budget; see NewSpillingMap.
+	db     keyvalue.DB
+	budget datasize.Size
+	spills int // number of times m has been spilled
+}
+
+// A snapshot is the published state of a Map.  Neither it nor its shards are
+// modified once published.
+type snapshot struct {
+	shards map[string]*shard // by corpus
+
+	// Readers see the spilled copies of directories written by the spills
+	// numbered below spills, except those discarded with a failed Populate.
+	spills    int
+	discarded map[int]bool
 }
 
 // NewMap returns an empty filetree map.
 func NewMap() *Map {
-	return &Map{make(map[string]map[string]map[string]*ftpb.DirectoryReply)}
+	m := new(Map)
+	m.snap.Store(&snapshot{shards: make(map[string]*shard)})
+	return m
+}
+
+// SetExpectedEntries sets the number of entries the next call to Populate is
+// expected to scan, such as the number scanned by an earlier run, from which
+// the time it will take is estimated.  Populate reports its progress through
+// package progress, and sets the number it scanned as the expected number for
+// the next call.
+func (m *Map) SetExpectedEntries(n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expected = n
+}
+
+// SetFileFacts sets whether later calls to Populate record the size and
+// encoding of each file, as by SetFileFact.  Doing so requires Populate to
+// read every fact in the GraphStore, including the full text of each file,
+// rather than only node kinds; by default, file entries report only the
+// language of each file.
+func (m *Map) SetFileFacts(fileFacts bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fileFacts = fileFacts
+}
+
+// An Update is a set of changes to a Map, which readers observe together
+// once the Update is committed.
+type Update struct {
+	m      *Map
+	shards map[string]*shard
+	copied map[string]bool // corpora whose shards belong to the update
+}
+
+// Update begins an update of m.  Only one Update may be in progress at a
+// time; Update blocks until any other is committed.
+func (m *Map) Update() *Update {
+	m.mu.Lock()
+	return &Update{
+		m:      m,
+		shards: maps.Clone(m.snap.Load().shards),
+		copied: make(map[string]bool),
+	}
+}
+
+// AddFile adds the given file VName to the map.  The entry of the file
+// reports the language of the VName.
+func (u *Update) AddFile(file *spb.VName) {
+	s := u.shard(file.Corpus)
+	dir := s.ensureDir(norm.NFC.String(file.Root), CleanDirPath(path.Dir(file.Path)))
+	name, flags := filepath.Base(file.Path), entryFlags(ftpb.DirectoryReply_FILE, file.GetRoot() != "")
+	s.addEntry(dir, name, flags)
+	if lang := file.GetLanguage(); lang != "" {
+		s.updateInfo(dir, name, flags, func(fi *fileInfo) { fi.language = s.names.intern(lang) })
+	}
+}
+
+// SetFileFact records a fact of the given file, if it has been added to the
+// map, in the file's entry: the length of its /kythe/text is reported as the
+// entry's size, and its /kythe/text/encoding as the entry's encoding.  Other
+// facts are ignored.
+func (u *Update) SetFileFact(file *spb.VName, fact string, value []byte) {
+	if fact != facts.Text && fact != facts.TextEncoding {
+		return
+	}
+	root, dirPath := norm.NFC.String(file.Root), CleanDirPath(path.Dir(file.Path))
+	name, flags := filepath.Base(file.Path), entryFlags(ftpb.DirectoryReply_FILE, file.GetRoot() != "")
+	if !u.hasFile(file.Corpus, root, dirPath, name, flags) {
+		return
+	}
+	s := u.shard(file.Corpus)
+	d, _ := s.lookupDir(root, dirPath)
+	s.updateInfo(d, name, flags, func(fi *fileInfo) {
+		if fact == facts.Text {
+			fi.size = int64(len(value))
+		} else {
+			fi.encoding = s.names.intern(string(value))
+		}
+	})
+}
+
+// RemoveFile removes the given file VName from the map, if present, along
+// with each of its directories left empty.  A root left without files is
+// removed from its corpus, and a corpus left without roots from the map.
+// Files spilled to disk by a Map returned by NewSpillingMap are not removed.
+//
+// The entries of a removed file remain in memory until its corpus is
+// compacted by the Commit of a compressed map; see SetCompressed.
+func (u *Update) RemoveFile(file *spb.VName) {
+	root, dirPath := norm.NFC.String(file.Root), CleanDirPath(path.Dir(file.Path))
+	generated := file.GetRoot() != ""
+	name, flags := filepath.Base(file.Path), entryFlags(ftpb.DirectoryReply_FILE, generated)
+	if !u.hasFile(file.Corpus, root, dirPath, name, flags) {
+		return
+	}
+	s := u.shard(file.Corpus)
+	for {
+		d, ok := s.lookupDir(root, dirPath)
+		if !ok || !s.removeEntry(d, name, flags) || !s.isEmpty(d) {
+			return
+		}
+		s.removeDir(d)
+		if dirPath == "" {
+			s.roots = slices.DeleteFunc(s.roots, func(r string) bool { return r == root })
+			break
+		}
+		name, flags = filepath.Base(dirPath), entryFlags(ftpb.DirectoryReply_DIRECTORY, generated)
+		dirPath = CleanDirPath(filepath.Dir(dirPath))
+	}
+	if len(s.dirList) == 0 {
+		delete(u.shards, s.corpus)
+	}
 }
 
-// Populate adds each file node in gs to m.
+// hasFile reports whether u has the given file entry, without copying the
+// shard of its corpus.
+func (u *Update) hasFile(corpus, root, dirPath, name string, flags uint8) bool {
+	s := u.shards[corpus]
+	if s == nil {
+		return false
+	}
+	d, ok := s.lookupDir(root, dirPath)
+	return ok && s.hasEntry(d, name, flags)
+}
+
+// shard returns the shard of u for corpus, copying the published shard on
+// its first change.
+func (u *Update) shard(corpus string) *shard {
+	s := u.shards[corpus]
+	if s == nil {
+		s = newShard(strings.Clone(corpus))
+	} else if !u.copied[corpus] {
+		s = s.clone()
+	} else {
+		return s
+	}
+	u.shards[s.corpus] = s
+	u.copied[s.corpus] = true
+	return s
+}
+
+// size returns the total size of the shards of u.
+func (u *Update) size() datasize.Size {
+	var total datasize.Size
+	for _, s := range u.shards {
+		total += s.size()
+	}
+	return total
+}
+
+// Commit publishes the changes of u to readers of the map.  The Update must
+// not be used afterwards.
+func (u *Update) Commit() {
+	if u.m.compressed {
+		for corpus := range u.copied {
+			if s := u.shards[corpus]; s != nil {
+				u.shards[corpus] = s.compact()
+			}
+		}
+	}
+	snap := u.m.snap.Load()
+	u.m.snap.Store(&snapshot{shards: u.shards, spills: u.m.spills, discarded: snap.discarded})
+	u.m.mu.Unlock()
+}
+
+// abort discards the changes of u, including the copies of directories it
+// spilled, which the store keeps but readers skip.  The Update must not be
+// used afterwards.
+func (u *Update) abort() {
+	if snap := u.m.snap.Load(); snap.spills < u.m.spills {
+		discarded := maps.Clone(snap.discarded)
+		if discarded == nil {
+			discarded = make(map[int]bool)
+		}
+		for n := snap.spills; n < u.m.spills; n++ {
+			discarded[n] = true
+		}
+		u.m.snap.Store(&snapshot{shards: snap.shards, spills: u.m.spills, discarded: discarded})
+	}
+	u.m.mu.Unlock()
+}
+
+// Populate adds each file node in gs to m.  If enabled by SetFileFacts, the
+// metadata recorded by SetFileFact is added as well; the facts of each file
+// must then follow its node kind in the scan of gs, as they do in a store
+// ordered by source.  Readers observe the files once the scan is complete; if
+// it fails, m is left unchanged.
 func (m *Map) Populate(ctx context.Context, gs graphstore.Service) error {
 	start := time.Now()
 	log.Info("Populating in-memory file tree")
 	var total int
-	if err := gs.Scan(ctx, &spb.ScanRequest{FactPrefix: facts.NodeKind},
+	u := m.Update()
+	t := progress.Start("filetree.Populate", "entries", m.expected)
+	defer t.Done()
+	prefix := facts.NodeKind
+	if m.fileFacts {
+		prefix = schema.Prefix
+	}
+	// AddFile and SetFileFact retain only the strings of each VName, so
+	// entries can be reused.
+	if err := gs.Scan(graphstore.WithEntryReuse(ctx, true), &spb.ScanRequest{FactPrefix: prefix},
 		func(entry *spb.Entry) error {
-			if entry.FactName == facts.NodeKind && string(entry.FactValue) == nodes.File {
-				m.AddFile(entry.Source)
+			t.Add(1)
+			if entry.EdgeKind != "" {
+				return nil
+			}
+			switch entry.FactName {
+			case facts.NodeKind:
+				if string(entry.FactValue) != nodes.File {
+					return nil
+				}
+				// Spill before adding a file, rather than after, so that the
+				// facts following its kind find it in memory.
+				if m.db != nil && u.size() > m.budget {
+					if err := u.spill(ctx); err != nil {
+						return fmt.Errorf("spilling directories: %v", err)
+					}
+				}
+				u.AddFile(entry.Source)
 				total++
+			case facts.Text, facts.TextEncoding:
+				u.SetFileFact(entry.Source, entry.FactName, entry.FactValue)
 			}
 			return nil
 		}); err != nil {
+		u.abort()
 		return fmt.Errorf("failed to Scan GraphStore for directory structure: %v", err)
 	}
+	// m.expected and m.spills are guarded by m.mu, which the Update holds
+	// until it is committed.
+	m.expected = t.Report().Processed
+	spills := m.spills
+	u.Commit()
+	if spills > 0 {
+		log.InfoContextf(ctx, "Indexed %d files in %s (spilled to disk %d times)", total, time.Since(start), spills)
+		return nil
+	}
 	log.InfoContextf(ctx, "Indexed %d files in %s", total, time.Since(start))
 	return nil
 }
 
-// AddFile adds the given file VName to m.
-func (m *Map) AddFile(file *spb.VName) {
-	dirPath := CleanDirPath(path.Dir(file.Path))
-	dir := m.ensureDir(file.Corpus, file.Root, dirPath)
-	dir.Entry = addEntry(dir.Entry, &ftpb.DirectoryReply_Entry{
-		Kind:      ftpb.DirectoryReply_FILE,
-		Name:      filepath.Base(file.Path),
-		Generated: file.GetRoot() != "",
-	})
+// UpdateFromEntries applies the changes to file nodes read by rd, such as
+// those of an incremental indexing run, to m in a single Update.  An entry
+// giving a node the kind "file" adds the file, while one giving a node an
+// empty kind removes it; the facts of files are recorded as by SetFileFact,
+// and other entries are ignored.  If reading fails, none
+// of the changes are applied.  A Map returned by NewSpillingMap cannot be
+// updated, since files spilled to disk cannot be removed.
+func (m *Map) UpdateFromEntries(ctx context.Context, rd stream.EntryReader) error {
+	if m.db != nil {
+		return errors.New("cannot update a spilling file tree")
+	}
+	var added, removed int
+	u := m.Update()
+	if err := rd(func(entry *spb.Entry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.EdgeKind != "" {
+			return nil
+		} else if entry.FactName != facts.NodeKind {
+			u.SetFileFact(entry.Source, entry.FactName, entry.FactValue)
+			return nil
+		}
+		switch string(entry.FactValue) {
+		case nodes.File:
+			u.AddFile(entry.Source)
+			added++
+		case "":
+			u.RemoveFile(entry.Source)
+			removed++
+		}
+		return nil
+	}); err != nil {
+		u.abort()
+		return fmt.Errorf("reading file tree updates: %v", err)
+	}
+	u.Commit()
+	log.InfoContextf(ctx, "Updated file tree: %d files added, %d removed", added, removed)
+	return nil
+}
+
+// AddFile adds the given file VNames to m in a single Update, which copies
+// the shard of each of their corpora once.  Files added by separate calls are
+// each copied separately, so files should be added together where possible.
+func (m *Map) AddFile(files ...*spb.VName) {
+	u := m.Update()
+	for _, file := range files {
+		u.AddFile(file)
+	}
+	u.Commit()
+}
+
+// RemoveFile removes the given file VNames from m in a single Update; see
+// Update.RemoveFile and AddFile.
+func (m *Map) RemoveFile(files ...*spb.VName) {
+	u := m.Update()
+	for _, file := range files {
+		u.RemoveFile(file)
+	}
+	u.Commit()
 }
 
+// shard returns the published shard of m for corpus, or nil if there is none.
+func (m *Map) shard(corpus string) *shard { return m.snap.Load().shards[corpus] }
+
 // CorpusRoots implements part of the filetree.Service interface.
 func (m *Map) CorpusRoots(ctx context.Context, req *ftpb.CorpusRootsRequest) (*ftpb.CorpusRootsReply, error) {
 	cr := &ftpb.CorpusRootsReply{}
-	for corpus, rootDirs := range m.M {
-		var roots []string
-		for root := range rootDirs {
-			roots = append(roots, root)
-		}
+	for _, s := range m.snap.Load().shards {
 		cr.Corpus = append(cr.Corpus, &ftpb.CorpusRootsReply_Corpus{
-			Name: corpus,
-			Root: roots,
+			Name: s.corpus,
+			Root: append([]string(nil), s.roots...),
 		})
 	}
 	return cr, nil
@@ -116,89 +574,114 @@ func (m *Map) CorpusRoots(ctx context.Context, req *ftpb.CorpusRootsRequest) (*f
 
 // Directory implements part of the filetree.Service interface.
 func (m *Map) Directory(ctx context.Context, req *ftpb.DirectoryRequest) (*ftpb.DirectoryReply, error) {
-	roots := m.M[req.Corpus]
-	if roots == nil {
-		return &ftpb.DirectoryReply{}, nil
+	root, dirPath := norm.NFC.String(req.Root), norm.NFC.String(req.Path)
+	snap := m.snap.Load()
+	var d *ftpb.DirectoryReply
+	if s := snap.shards[req.Corpus]; s != nil {
+		d = s.directory(root, dirPath)
 	}
-	dirs := roots[req.Root]
-	if dirs == nil {
-		return &ftpb.DirectoryReply{}, nil
+	if m.db != nil {
+		spilled, err := m.spilled(ctx, snap, req.Corpus, root, dirPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading spilled directory: %v", err)
+		} else if spilled != nil {
+			if d != nil {
+				spilled.Entry = mergeEntries(spilled.Entry, d.Entry)
+			}
+			d = spilled
+		}
 	}
-	d := dirs[req.Path]
 	if d == nil {
 		return &ftpb.DirectoryReply{}, nil
 	}
-	return d, nil
+	return PageEntries(req, d)
 }
 
-func (m *Map) ensureCorpusRoot(corpus, root string) map[string]*ftpb.DirectoryReply {
-	roots := m.M[corpus]
-	if roots == nil {
-		roots = make(map[string]map[string]*ftpb.DirectoryReply)
-		m.M[corpus] = roots
-	}
-
-	dirs := roots[root]
-	if dirs == nil {
-		dirs = make(map[string]*ftpb.DirectoryReply)
-		roots[root] = dirs
-	}
-	return dirs
+// Tree implements part of the filetree.Service interface.
+func (m *Map) Tree(ctx context.Context, req *ftpb.TreeRequest) (*ftpb.DirectoryReply, error) {
+	return Tree(ctx, m.Directory, req)
 }
 
-func (m *Map) ensureDir(corpus, root, path string) *ftpb.DirectoryReply {
-	if path == "." {
-		path = ""
+// Walk calls f with each directory of m, in no particular order.  If f
+// returns an error, Walk stops and returns it.
+func (m *Map) Walk(ctx context.Context, f func(*ftpb.DirectoryReply) error) error {
+	var inMemory map[string]bool
+	if m.db != nil {
+		inMemory = make(map[string]bool)
 	}
-	dirs := m.ensureCorpusRoot(corpus, root)
-	dir := dirs[path]
-	if dir == nil {
-		dir = &ftpb.DirectoryReply{
-			Corpus: corpus,
-			Root:   root,
-			Path:   path,
-		}
-		dirs[path] = dir
-
-		if path != "" {
-			parent := m.ensureDir(corpus, root, filepath.Dir(path))
-			parent.Entry = addEntry(parent.Entry, &ftpb.DirectoryReply_Entry{
-				Kind:      ftpb.DirectoryReply_DIRECTORY,
-				Name:      filepath.Base(path),
-				Generated: root != "",
-			})
+	snap := m.snap.Load()
+	for _, s := range snap.shards {
+		for i := range s.dirList {
+			d := s.reply(int32(i))
+			if m.db != nil {
+				inMemory[string(spillPrefix(d.Corpus, d.Root, d.Path))] = true
+				spilled, err := m.spilled(ctx, snap, d.Corpus, d.Root, d.Path)
+				if err != nil {
+					return fmt.Errorf("reading spilled directory: %v", err)
+				} else if spilled != nil {
+					spilled.Entry = mergeEntries(spilled.Entry, d.Entry)
+					d = spilled
+				}
+			}
+			if err := f(d); err != nil {
+				return err
+			}
 		}
 	}
-	return dir
+	if m.db == nil {
+		return nil
+	}
+	return m.walkSpilled(ctx, snap, inMemory, f)
 }
 
-func addEntry(entries []*ftpb.DirectoryReply_Entry, e *ftpb.DirectoryReply_Entry) []*ftpb.DirectoryReply_Entry {
-	for _, x := range entries {
-		if proto.Equal(x, e) {
-			return entries
-		}
+// Close implements part of the filetree.Service interface.  It closes the
+// store of a Map returned by NewSpillingMap.
+func (m *Map) Close(ctx context.Context) error {
+	if m.db != nil {
+		return m.db.Close(ctx)
 	}
-	return append(entries, e)
+	return nil
 }
 
-type webClient struct{ addr string }
+type webClient struct {
+	addr   string
+	client *web.Client
+}
 
 func (webClient) Close(context.Context) error { return nil }
 
 // CorpusRoots implements part of the Service interface.
 func (w *webClient) CorpusRoots(ctx context.Context, req *ftpb.CorpusRootsRequest) (*ftpb.CorpusRootsReply, error) {
 	var reply ftpb.CorpusRootsReply
-	return &reply, web.Call(w.addr, "corpusRoots", req, &reply)
+	return &reply, w.client.CallContext(ctx, w.addr, "corpusRoots", req, &reply)
 }
 
 // Directory implements part of the Service interface.
 func (w *webClient) Directory(ctx context.Context, req *ftpb.DirectoryRequest) (*ftpb.DirectoryReply, error) {
 	var reply ftpb.DirectoryReply
-	return &reply, web.Call(w.addr, "dir", req, &reply)
+	return &reply, w.client.CallContext(ctx, w.addr, "dir", req, &reply)
+}
+
+// Tree implements part of the Service interface.
+func (w *webClient) Tree(ctx context.Context, req *ftpb.TreeRequest) (*ftpb.DirectoryReply, error) {
+	var reply ftpb.DirectoryReply
+	return &reply, w.client.CallContext(ctx, w.addr, "tree", req, &reply)
+}
+
+// Search implements part of the Service interface.
+func (w *webClient) Search(ctx context.Context, req *ftpb.SearchRequest) (*ftpb.SearchReply, error) {
+	var reply ftpb.SearchReply
+	return &reply, w.client.CallContext(ctx, w.addr, "files", req, &reply)
 }
 
 // WebClient returns an filetree Service based on a remote web server.
-func WebClient(addr string) Service { return &webClient{addr} }
+func WebClient(addr string) Service { return WebClientWithOptions(addr, nil) }
+
+// WebClientWithOptions returns a filetree Service based on a remote web server,
+// called with the given options.  If opts == nil, default options are used.
+func WebClientWithOptions(addr string, opts *web.Options) Service {
+	return &webClient{addr, web.NewClient(opts)}
+}
 
 // RegisterHTTPHandlers registers JSON HTTP handlers with mux using the given
 // filetree Service.  The following methods with be exposed:
@@ -208,8 +691,18 @@ func WebClient(addr string) Service { return &webClient{addr} }
 //	GET /dir
 //	  Request: JSON encoded filetree.DirectoryRequest
 //	  Response: JSON encoded filetree.DirectoryReply
+//	GET /tree
+//	  Request: JSON encoded filetree.TreeRequest
+//	  Response: JSON encoded filetree.DirectoryReply
+//	GET /files
+//	  Request: JSON encoded filetree.SearchRequest
+//	  Response: JSON encoded filetree.SearchReply
 //
-// Note: /corpusRoots and /dir will return their responses as serialized
+// The page_size and page_token of a /dir request, and the max_depth of a
+// /tree request, may also be given as query parameters, which take
+// precedence over those of its body.
+//
+// Note: /corpusRoots, /dir, /tree, and /files will return their responses as serialized
 // protobufs if the "proto" query parameter is set.
 func RegisterHTTPHandlers(ctx context.Context, ft Service, mux *http.ServeMux) {
 	mux.HandleFunc("/corpusRoots", func(w http.ResponseWriter, r *http.Request) {
@@ -220,12 +713,12 @@ func RegisterHTTPHandlers(ctx context.Context, ft Service, mux *http.ServeMux) {
 
 		var req ftpb.CorpusRootsRequest
 		if err := web.ReadJSONBody(r, &req); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			web.WriteError(w, err, http.StatusBadRequest)
 			return
 		}
-		cr, err := ft.CorpusRoots(ctx, &req)
+		cr, err := ft.CorpusRoots(r.Context(), &req)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			web.WriteError(w, err, http.StatusInternalServerError)
 			return
 		}
 		if err := web.WriteResponse(w, r, cr); err != nil {
@@ -240,12 +733,72 @@ func RegisterHTTPHandlers(ctx context.Context, ft Service, mux *http.ServeMux) {
 
 		var req ftpb.DirectoryRequest
 		if err := web.ReadJSONBody(r, &req); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			web.WriteError(w, err, http.StatusBadRequest)
+			return
+		}
+		q := r.URL.Query()
+		if q.Has("page_size") {
+			n, err := strconv.ParseInt(q.Get("page_size"), 10, 32)
+			if err != nil {
+				http.Error(w, "invalid page_size: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			req.PageSize = int32(n)
+		}
+		if q.Has("page_token") {
+			req.PageToken = q.Get("page_token")
+		}
+		reply, err := ft.Directory(r.Context(), &req)
+		if err != nil {
+			web.WriteError(w, err, http.StatusInternalServerError)
+			return
+		}
+		if err := web.WriteResponse(w, r, reply); err != nil {
+			log.InfoContext(ctx, err)
+		}
+	})
+	mux.HandleFunc("/tree", func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		defer func() {
+			log.InfoContextf(ctx, "filetree.Tree:\t%s", time.Since(start))
+		}()
+
+		var req ftpb.TreeRequest
+		if err := web.ReadJSONBody(r, &req); err != nil {
+			web.WriteError(w, err, http.StatusBadRequest)
+			return
+		}
+		if q := r.URL.Query(); q.Has("max_depth") {
+			n, err := strconv.ParseInt(q.Get("max_depth"), 10, 32)
+			if err != nil {
+				http.Error(w, "invalid max_depth: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			req.MaxDepth = int32(n)
+		}
+		reply, err := ft.Tree(r.Context(), &req)
+		if err != nil {
+			web.WriteError(w, err, http.StatusInternalServerError)
+			return
+		}
+		if err := web.WriteResponse(w, r, reply); err != nil {
+			log.InfoContext(ctx, err)
+		}
+	})
+	mux.HandleFunc("/files", func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		defer func() {
+			log.InfoContextf(ctx, "filetree.Search:\t%s", time.Since(start))
+		}()
+
+		var req ftpb.SearchRequest
+		if err := web.ReadJSONBody(r, &req); err != nil {
+			web.WriteError(w, err, http.StatusBadRequest)
 			return
 		}
-		reply, err := ft.Directory(ctx, &req)
+		reply, err := ft.Search(r.Context(), &req)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			web.WriteError(w, err, http.StatusInternalServerError)
 			return
 		}
 		if err := web.WriteResponse(w, r, reply); err != nil {
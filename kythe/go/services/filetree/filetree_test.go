@@ -0,0 +1,1066 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filetree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"kythe.io/kythe/go/services/graphstore"
+	"kythe.io/kythe/go/storage/inmemory"
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/test/synthetic"
+	"kythe.io/kythe/go/util/datasize"
+	"kythe.io/kythe/go/util/progress"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestMap(t *testing.T) {
+	ctx := context.Background()
+	m := NewMap()
+	for _, path := range []string{"a/b/c.go", "a/b/d.go", "a/e.go", "a/b/c.go"} {
+		m.AddFile(&spb.VName{Corpus: "corpus", Path: path})
+	}
+	m.AddFile(&spb.VName{Corpus: "corpus", Root: "gen", Path: "a/c.go"})
+
+	got, err := m.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", Path: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ftpb.DirectoryReply{
+		Corpus: "corpus",
+		Path:   "a",
+		Entry: []*ftpb.DirectoryReply_Entry{
+			{Kind: ftpb.DirectoryReply_DIRECTORY, Name: "b"},
+			{Kind: ftpb.DirectoryReply_FILE, Name: "e.go"},
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Directory (-want +got):\n%s", diff)
+	}
+
+	got, err = m.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", Root: "gen", Path: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = &ftpb.DirectoryReply{
+		Corpus: "corpus",
+		Root:   "gen",
+		Path:   "a",
+		Entry:  []*ftpb.DirectoryReply_Entry{{Kind: ftpb.DirectoryReply_FILE, Name: "c.go", Generated: true}},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Directory (-want +got):\n%s", diff)
+	}
+
+	// Each distinct root, path, and name is stored once: "", "gen", "a/b",
+	// "a", "b", "c.go", "d.go", and "e.go".
+	if got, want := m.shard("corpus").names.len(), 8; got != want {
+		t.Errorf("Interned %d strings, want %d", got, want)
+	}
+
+	cr, err := m.CorpusRoots(ctx, &ftpb.CorpusRootsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRoots := &ftpb.CorpusRootsReply{Corpus: []*ftpb.CorpusRootsReply_Corpus{{Name: "corpus", Root: []string{"", "gen"}}}}
+	if diff := cmp.Diff(wantRoots, cr, protocmp.Transform()); diff != "" {
+		t.Errorf("CorpusRoots (-want +got):\n%s", diff)
+	}
+
+	var dirs []string
+	if err := m.Walk(ctx, func(d *ftpb.DirectoryReply) error {
+		dirs = append(dirs, d.Root+":"+d.Path)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(dirs)
+	if diff := cmp.Diff([]string{":", ":a", ":a/b", "gen:", "gen:a"}, dirs); diff != "" {
+		t.Errorf("Walk directories (-want +got):\n%s", diff)
+	}
+}
+
+func TestMapUnicode(t *testing.T) {
+	ctx := context.Background()
+	const (
+		nfcDir  = "ソース/caf\u00e9"     // é precomposed
+		nfdDir  = "ソース/cafe\u0301"    // e and a combining acute accent
+		nfdFile = "\u30c6\u3099スト.go" // デスト, with a combining voiced mark
+	)
+	m := NewMap()
+	m.AddFile(&spb.VName{Corpus: "corpus", Path: nfdDir + "/" + nfdFile})
+	m.AddFile(&spb.VName{Corpus: "corpus", Path: nfcDir + "/日本語.go"})
+
+	// Both spellings of the directory find the same entries, and file names
+	// are kept as given.
+	want := []*ftpb.DirectoryReply_Entry{
+		{Kind: ftpb.DirectoryReply_FILE, Name: nfdFile},
+		{Kind: ftpb.DirectoryReply_FILE, Name: "日本語.go"},
+	}
+	for _, dir := range []string{nfcDir, nfdDir} {
+		got, err := m.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", Path: dir})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got.Entry, protocmp.Transform()); diff != "" {
+			t.Errorf("Directory(%q) (-want +got):\n%s", dir, diff)
+		}
+	}
+
+	got, err := m.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", Path: "ソース"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]*ftpb.DirectoryReply_Entry{
+		{Kind: ftpb.DirectoryReply_DIRECTORY, Name: "caf\u00e9"},
+	}, got.Entry, protocmp.Transform()); diff != "" {
+		t.Errorf("Directory(ソース) (-want +got):\n%s", diff)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	m := NewMap()
+	m.AddFile(&spb.VName{Corpus: "corpus", Path: "a/old.go"})
+	old := m.shard("corpus")
+
+	u := m.Update()
+	u.AddFile(&spb.VName{Corpus: "corpus", Path: "a/new.go"})
+	u.AddFile(&spb.VName{Corpus: "other", Path: "b.go"})
+
+	// Readers see none of the changes of an Update until it is committed.
+	dir := func(corpus, path string) []string {
+		d, err := m.Directory(ctx, &ftpb.DirectoryRequest{Corpus: corpus, Path: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, e := range d.Entry {
+			names = append(names, e.Name)
+		}
+		return names
+	}
+	if diff := cmp.Diff([]string{"old.go"}, dir("corpus", "a")); diff != "" {
+		t.Errorf("Directory before Commit (-want +got):\n%s", diff)
+	}
+	if got := dir("other", ""); len(got) != 0 {
+		t.Errorf("Directory of new corpus before Commit: got %v, want none", got)
+	}
+
+	u.Commit()
+	if diff := cmp.Diff([]string{"old.go", "new.go"}, dir("corpus", "a")); diff != "" {
+		t.Errorf("Directory after Commit (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"b.go"}, dir("other", "")); diff != "" {
+		t.Errorf("Directory of new corpus after Commit (-want +got):\n%s", diff)
+	}
+
+	// The previously published shard is unchanged.
+	if d := old.directory("", "a"); len(d.Entry) != 1 {
+		t.Errorf("Old snapshot changed: %v", d)
+	}
+}
+
+// treeContents returns the names of the entries of each directory of m, by
+// the root and path of the directory.
+func treeContents(t *testing.T, m *Map) map[string][]string {
+	t.Helper()
+	dirs := make(map[string][]string)
+	if err := m.Walk(context.Background(), func(d *ftpb.DirectoryReply) error {
+		var names []string
+		for _, e := range d.Entry {
+			names = append(names, e.Name)
+		}
+		sort.Strings(names)
+		dirs[d.Corpus+":"+d.Root+":"+d.Path] = names
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return dirs
+}
+
+func TestRemoveFile(t *testing.T) {
+	ctx := context.Background()
+	for _, compressed := range []bool{false, true} {
+		m := NewMap()
+		m.SetCompressed(compressed)
+		u := m.Update()
+		for _, path := range []string{"a/b/c.go", "a/b/d.go", "a/e.go", "f.go"} {
+			u.AddFile(&spb.VName{Corpus: "corpus", Path: path})
+		}
+		u.AddFile(&spb.VName{Corpus: "corpus", Root: "gen", Path: "a/g.go"})
+		u.AddFile(&spb.VName{Corpus: "other", Path: "x.go"})
+		u.Commit()
+		old := m.shard("corpus")
+
+		// Removing a missing file does not copy the shard of its corpus.
+		m.RemoveFile(&spb.VName{Corpus: "corpus", Path: "a/b/missing.go"})
+		if m.shard("corpus") != old {
+			t.Errorf("Compressed %v: removing a missing file copied its shard", compressed)
+		}
+
+		m.RemoveFile(&spb.VName{Corpus: "corpus", Path: "a/b/c.go"})
+		if diff := cmp.Diff([]string{"d.go"}, treeContents(t, m)["corpus::a/b"]); diff != "" {
+			t.Errorf("Directory a/b after removing c.go (-want +got):\n%s", diff)
+		}
+
+		u = m.Update()
+		for _, f := range []*spb.VName{
+			{Corpus: "corpus", Path: "a/b/d.go"},
+			{Corpus: "corpus", Root: "gen", Path: "a/g.go"},
+			{Corpus: "other", Path: "x.go"},
+			// Removing files not in the map changes nothing.
+			{Corpus: "corpus", Path: "a/missing.go"},
+			{Corpus: "corpus", Path: "missing/f.go"},
+			{Corpus: "corpus", Root: "gen", Path: "f.go"},
+			{Corpus: "missing", Path: "f.go"},
+		} {
+			u.RemoveFile(f)
+		}
+		u.Commit()
+
+		want := map[string][]string{
+			"corpus::":  {"a", "f.go"},
+			"corpus::a": {"e.go"},
+		}
+		if diff := cmp.Diff(want, treeContents(t, m)); diff != "" {
+			t.Errorf("Compressed %v: directories after removals (-want +got):\n%s", compressed, diff)
+		}
+		cr, err := m.CorpusRoots(ctx, &ftpb.CorpusRootsRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantRoots := &ftpb.CorpusRootsReply{Corpus: []*ftpb.CorpusRootsReply_Corpus{{Name: "corpus", Root: []string{""}}}}
+		if diff := cmp.Diff(wantRoots, cr, protocmp.Transform()); diff != "" {
+			t.Errorf("Compressed %v: CorpusRoots (-want +got):\n%s", compressed, diff)
+		}
+
+		// The previously published shard is unchanged.
+		if d := old.directory("", "a/b"); len(d.GetEntry()) != 2 {
+			t.Errorf("Compressed %v: old snapshot changed: %v", compressed, d)
+		}
+
+		// Removed directories are recreated by new files.
+		m.AddFile(&spb.VName{Corpus: "corpus", Path: "a/b/c.go"})
+		if diff := cmp.Diff([]string{"c.go"}, treeContents(t, m)["corpus::a/b"]); diff != "" {
+			t.Errorf("Compressed %v: directory a/b after re-adding c.go (-want +got):\n%s", compressed, diff)
+		}
+	}
+}
+
+func TestUpdateFromEntries(t *testing.T) {
+	ctx := context.Background()
+	m := NewMap()
+	m.AddFile(&spb.VName{Corpus: "corpus", Path: "a/b.go"}, &spb.VName{Corpus: "corpus", Path: "a/c.go"})
+	kind := func(path, kind string) *spb.Entry {
+		return &spb.Entry{
+			Source:    &spb.VName{Corpus: "corpus", Path: path},
+			FactName:  facts.NodeKind,
+			FactValue: []byte(kind),
+		}
+	}
+	entries := func(es ...*spb.Entry) stream.EntryReader {
+		return func(f func(*spb.Entry) error) error {
+			for _, e := range es {
+				if err := f(e); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	if err := m.UpdateFromEntries(ctx, entries(
+		kind("a/b.go", ""),
+		kind("d/e.go", nodes.File),
+		kind("a/c.go", nodes.Anchor),
+		&spb.Entry{Source: &spb.VName{Corpus: "corpus", Path: "a/c.go"}, EdgeKind: "/kythe/edge/childof", Target: &spb.VName{Corpus: "corpus"}, FactName: "/"},
+	)); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"corpus::":  {"a", "d"},
+		"corpus::a": {"c.go"},
+		"corpus::d": {"e.go"},
+	}
+	if diff := cmp.Diff(want, treeContents(t, m)); diff != "" {
+		t.Errorf("Directories after update (-want +got):\n%s", diff)
+	}
+
+	// None of the changes of a failed update are applied.
+	if err := m.UpdateFromEntries(ctx, func(f func(*spb.Entry) error) error {
+		if err := f(kind("a/c.go", "")); err != nil {
+			return err
+		}
+		return errors.New("read failed")
+	}); err == nil {
+		t.Error("UpdateFromEntries with a failing reader: got no error")
+	}
+	if diff := cmp.Diff(want, treeContents(t, m)); diff != "" {
+		t.Errorf("Directories after failed update (-want +got):\n%s", diff)
+	}
+
+	spilling := NewSpillingMap(inmemory.NewKeyValueDB(), 1024)
+	defer spilling.Close(ctx)
+	if err := spilling.UpdateFromEntries(ctx, entries(kind("a.go", nodes.File))); err == nil {
+		t.Error("UpdateFromEntries of a spilling map: got no error")
+	}
+}
+
+func TestFileMetadata(t *testing.T) {
+	ctx := context.Background()
+	gs := new(inmemory.GraphStore)
+	write := func(v *spb.VName, fact, value string) {
+		if err := gs.Write(ctx, &spb.WriteRequest{
+			Source: v,
+			Update: []*spb.WriteRequest_Update{{FactName: fact, FactValue: []byte(value)}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	goFile := &spb.VName{Corpus: "corpus", Path: "a/b.go", Language: "go"}
+	write(goFile, facts.NodeKind, nodes.File)
+	write(goFile, facts.Text, "package a\n")
+	write(goFile, facts.TextEncoding, "UTF-8")
+	latin := &spb.VName{Corpus: "corpus", Path: "a/c.txt"}
+	write(latin, facts.NodeKind, nodes.File)
+	write(latin, facts.Text, "caf\xe9")
+	write(latin, facts.TextEncoding, "ISO-8859-1")
+	write(&spb.VName{Corpus: "corpus", Path: "a/d.go"}, facts.NodeKind, nodes.File)
+	// The text of a node that is not a file is ignored.
+	write(&spb.VName{Corpus: "corpus", Path: "a/e.go", Signature: "s"}, facts.Text, "ignored")
+
+	want := []*ftpb.DirectoryReply_Entry{
+		{Kind: ftpb.DirectoryReply_FILE, Name: "b.go", Language: "go", Encoding: "UTF-8", Size: 10},
+		{Kind: ftpb.DirectoryReply_FILE, Name: "c.txt", Encoding: "ISO-8859-1", Size: 4},
+		{Kind: ftpb.DirectoryReply_FILE, Name: "d.go"},
+	}
+	compressed := NewMap()
+	compressed.SetCompressed(true)
+	for name, m := range map[string]*Map{
+		"Map":           NewMap(),
+		"compressedMap": compressed,
+		"SpillingMap":   NewSpillingMap(inmemory.NewKeyValueDB(), 128),
+	} {
+		m.SetFileFacts(true)
+		if err := m.Populate(ctx, gs); err != nil {
+			t.Fatal(err)
+		}
+		got, err := m.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", Path: "a", PageSize: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got.Entry, protocmp.Transform()); diff != "" {
+			t.Errorf("%s: Directory (-want +got):\n%s", name, diff)
+		}
+		if m.db == nil {
+			// Updates change the metadata of files already present.
+			if err := m.UpdateFromEntries(ctx, func(f func(*spb.Entry) error) error {
+				return f(&spb.Entry{Source: latin, FactName: facts.Text, FactValue: []byte("caf\xe9s")})
+			}); err != nil {
+				t.Fatal(err)
+			}
+			got, err := m.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", Path: "a", PageSize: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size := got.Entry[1].GetSize(); size != 5 {
+				t.Errorf("%s: size of c.txt after update: got %d; want 5", name, size)
+			}
+		}
+		m.Close(ctx)
+	}
+
+	// By default, Populate scans only node kinds.
+	m := NewMap()
+	if err := m.Populate(ctx, gs); err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", Path: "a", PageSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []*ftpb.DirectoryReply_Entry{
+		{Kind: ftpb.DirectoryReply_FILE, Name: "b.go", Language: "go"},
+		{Kind: ftpb.DirectoryReply_FILE, Name: "c.txt"},
+		{Kind: ftpb.DirectoryReply_FILE, Name: "d.go"},
+	}
+	if diff := cmp.Diff(want, got.Entry, protocmp.Transform()); diff != "" {
+		t.Errorf("Directory without file facts (-want +got):\n%s", diff)
+	}
+	if m.expected != 3 {
+		t.Errorf("Entries scanned without file facts: got %d, want 3", m.expected)
+	}
+}
+
+func TestMapConcurrent(t *testing.T) {
+	ctx := context.Background()
+	m := NewMap()
+	var wg sync.WaitGroup
+	for c := 0; c < 4; c++ {
+		corpus := fmt.Sprintf("corpus%d", c)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				m.AddFile(&spb.VName{Corpus: corpus, Path: fmt.Sprintf("dir%d/file%d", i%10, i)})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if _, err := m.Directory(ctx, &ftpb.DirectoryRequest{Corpus: corpus, Path: "dir0"}); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for c := 0; c < 4; c++ {
+		d, err := m.Directory(ctx, &ftpb.DirectoryRequest{Corpus: fmt.Sprintf("corpus%d", c), Path: "dir3"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(d.Entry); got != 10 {
+			t.Errorf("corpus%d/dir3 has %d entries, want 10", c, got)
+		}
+	}
+}
+
+// benchFiles returns n file VNames in directories of 20 files each.  File
+// names repeat across directories, as is typical of source repositories.
+// Each VName has its own copy of its strings, as if it had been decoded from
+// a separate entry.
+func benchFiles(n int) []*spb.VName {
+	files := make([]*spb.VName, n)
+	for i := range files {
+		files[i] = &spb.VName{
+			Corpus: strings.Clone("kythe.io/benchmark/corpus"),
+			Path:   fmt.Sprintf("src/project%d/package%d/dir%d/source_file%d.go", i/10000, i/1000, i/20, i%20),
+		}
+		if i%5 == 0 {
+			files[i].Root = strings.Clone("bazel-out/k8-fastbuild/bin")
+		}
+	}
+	return files
+}
+
+func heapInUse() int64 {
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return int64(ms.HeapAlloc)
+}
+
+// BenchmarkAddFile reports the memory retained per file by a Map, with and
+// without interning.
+func TestMapConcurrentPopulate(t *testing.T) {
+	ctx := context.Background()
+	gs := new(inmemory.GraphStore)
+	var files []*spb.VName
+	for i := 0; i < 200; i++ {
+		f := &spb.VName{Corpus: "corpus", Path: fmt.Sprintf("dir%d/file%d.go", i%10, i)}
+		if err := gs.Write(ctx, &spb.WriteRequest{
+			Source: f,
+			Update: []*spb.WriteRequest_Update{{FactName: facts.NodeKind, FactValue: []byte(nodes.File)}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+
+	for name, m := range map[string]*Map{
+		"Map":         NewMap(),
+		"SpillingMap": NewSpillingMap(inmemory.NewKeyValueDB(), 2048),
+	} {
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		readers := []func() error{
+			func() error {
+				_, err := m.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", Path: "dir0"})
+				return err
+			},
+			func() error {
+				_, err := m.Tree(ctx, &ftpb.TreeRequest{Corpus: "corpus"})
+				return err
+			},
+			func() error {
+				_, err := m.Search(ctx, &ftpb.SearchRequest{Corpus: "corpus", Pattern: "file1"})
+				return err
+			},
+			func() error {
+				_, err := m.CorpusRoots(ctx, &ftpb.CorpusRootsRequest{})
+				return err
+			},
+			func() error {
+				return m.Walk(ctx, func(*ftpb.DirectoryReply) error { return nil })
+			},
+		}
+		for _, read := range readers {
+			wg.Add(1)
+			go func(read func() error) {
+				defer wg.Done()
+				for {
+					select {
+					case <-done:
+						return
+					default:
+					}
+					if err := read(); err != nil {
+						t.Errorf("%s: %v", name, err)
+						return
+					}
+				}
+			}(read)
+		}
+
+		if err := m.Populate(ctx, gs); err != nil {
+			t.Fatal(err)
+		}
+		if m.db == nil {
+			for _, f := range files[:100] {
+				m.RemoveFile(f)
+			}
+		}
+		close(done)
+		wg.Wait()
+
+		d, err := m.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", Path: "dir3"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := 20
+		if m.db == nil {
+			want = 10
+		}
+		if got := len(d.Entry); got != want {
+			t.Errorf("%s: dir3 has %d entries, want %d", name, got, want)
+		}
+		m.Close(ctx)
+	}
+}
+
+func TestPopulateProgress(t *testing.T) {
+	ctx := context.Background()
+	gs := new(inmemory.GraphStore)
+	for _, n := range []struct{ path, kind string }{
+		{"a.go", nodes.File},
+		{"b/c.go", nodes.File},
+		{"", nodes.Package},
+	} {
+		if err := gs.Write(ctx, &spb.WriteRequest{
+			Source: &spb.VName{Corpus: "corpus", Path: n.path, Signature: n.kind},
+			Update: []*spb.WriteRequest_Update{{FactName: facts.NodeKind, FactValue: []byte(n.kind)}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewMap()
+	if err := m.Populate(ctx, gs); err != nil {
+		t.Fatal(err)
+	}
+	var last progress.Report
+	for _, r := range progress.Reports() {
+		if r.Name == "filetree.Populate" {
+			last = r
+		}
+	}
+	if !last.Done || last.Processed != 3 {
+		t.Errorf("Populate progress: got %+v, want 3 entries done", last)
+	}
+	// The next Populate expects to scan as many entries.
+	if m.expected != 3 {
+		t.Errorf("Expected entries after Populate: got %d, want 3", m.expected)
+	}
+}
+
+// TestPopulateConcurrent is meant to be run with -race.
+func TestPopulateConcurrent(t *testing.T) {
+	ctx := context.Background()
+	gs := new(inmemory.GraphStore)
+	for i := 0; i < 20; i++ {
+		if err := gs.Write(ctx, &spb.WriteRequest{
+			Source: &spb.VName{Corpus: "corpus", Path: fmt.Sprintf("dir%d/file%d.go", i%3, i)},
+			Update: []*spb.WriteRequest_Update{{FactName: facts.NodeKind, FactValue: []byte(nodes.File)}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewSpillingMap(inmemory.NewKeyValueDB(), 512)
+	defer m.Close(ctx)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := m.Populate(ctx, gs); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func(n int64) {
+			defer wg.Done()
+			m.SetExpectedEntries(n)
+		}(int64(i))
+	}
+	wg.Wait()
+	if got := len(treeContents(t, m)["corpus::dir0"]); got != 7 {
+		t.Errorf("Files in dir0 after Populate: got %d, want 7", got)
+	}
+}
+
+// failingScan is a GraphStore whose scans fail after n entries.
+type failingScan struct {
+	*inmemory.GraphStore
+	n int
+}
+
+func (f failingScan) Scan(ctx context.Context, req *spb.ScanRequest, g graphstore.EntryFunc) error {
+	var n int
+	if err := f.GraphStore.Scan(ctx, req, func(e *spb.Entry) error {
+		if n++; n > f.n {
+			return errors.New("scan failed")
+		}
+		return g(e)
+	}); err != nil {
+		return err
+	}
+	return errors.New("scan failed")
+}
+
+func TestPopulateFailure(t *testing.T) {
+	ctx := context.Background()
+	gs := new(inmemory.GraphStore)
+	for i := 0; i < 40; i++ {
+		if err := gs.Write(ctx, &spb.WriteRequest{
+			Source: &spb.VName{Corpus: "corpus", Path: fmt.Sprintf("dir%d/file%d.go", i%5, i)},
+			Update: []*spb.WriteRequest_Update{{FactName: facts.NodeKind, FactValue: []byte(nodes.File)}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, spilling := range []bool{false, true} {
+		m := NewMap()
+		if spilling {
+			// A budget this small spills every few files.
+			m = NewSpillingMap(inmemory.NewKeyValueDB(), 512)
+			defer m.Close(ctx)
+		}
+		m.AddFile(&spb.VName{Corpus: "corpus", Path: "old.go"})
+		if err := m.Populate(ctx, failingScan{gs, 30}); err == nil {
+			t.Errorf("Spilling %v: Populate with a failing scan: got no error", spilling)
+		}
+		if spilling && m.spills == 0 {
+			t.Errorf("Spilling %v: Populate did not spill", spilling)
+		}
+		want := map[string][]string{"corpus::": {"old.go"}}
+		if diff := cmp.Diff(want, treeContents(t, m)); diff != "" {
+			t.Errorf("Spilling %v: directories after failed Populate (-want +got):\n%s", spilling, diff)
+		}
+
+		// Populate succeeds after failures.
+		if err := m.Populate(ctx, failingScan{gs, 3}); err == nil {
+			t.Errorf("Spilling %v: Populate with a failing scan: got no error", spilling)
+		}
+		if err := m.Populate(ctx, gs); err != nil {
+			t.Fatal(err)
+		}
+		if got := len(treeContents(t, m)["corpus::dir0"]); got != 8 {
+			t.Errorf("Spilling %v: files in dir0 after Populate: got %d, want 8", spilling, got)
+		}
+	}
+}
+
+func BenchmarkAddFile(b *testing.B) {
+	const numFiles = 100000
+	for _, compressed := range []bool{false, true} {
+		name := "Plain"
+		if compressed {
+			name = "Compressed"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			var retained int64
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				before := heapInUse()
+				files := benchFiles(numFiles)
+				b.StartTimer()
+
+				m := NewMap()
+				m.SetCompressed(compressed)
+				u := m.Update()
+				for _, f := range files {
+					u.AddFile(f)
+				}
+				u.Commit()
+
+				b.StopTimer()
+				files = nil
+				retained += heapInUse() - before
+				runtime.KeepAlive(m)
+				b.StartTimer()
+			}
+			b.ReportMetric(float64(retained)/float64(b.N*numFiles), "retained-B/file")
+		})
+	}
+}
+
+// BenchmarkGC measures the time of a garbage collection while a large Map is
+// live, which is dominated by scanning the Map.
+func BenchmarkGC(b *testing.B) {
+	m := NewMap()
+	u := m.Update()
+	for _, f := range benchFiles(100000) {
+		u.AddFile(f)
+	}
+	u.Commit()
+	runtime.GC()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+	}
+	runtime.KeepAlive(m)
+}
+
+// benchCorpus returns a GraphStore holding the file nodes of a synthetic
+// corpus, and the distinct directories of those files.
+func benchCorpus(b *testing.B) (*inmemory.GraphStore, []string) {
+	ctx := context.Background()
+	gs := new(inmemory.GraphStore)
+	dirs := make(map[string]bool)
+	var paths []string
+	for _, file := range (&synthetic.Options{Files: 5000}).FileVNames() {
+		if err := gs.Write(ctx, &spb.WriteRequest{
+			Source: file,
+			Update: []*spb.WriteRequest_Update{{FactName: facts.NodeKind, FactValue: []byte(nodes.File)}},
+		}); err != nil {
+			b.Fatal(err)
+		}
+		if dir := CleanDirPath(path.Dir(file.Path)); !dirs[dir] {
+			dirs[dir] = true
+			paths = append(paths, dir)
+		}
+	}
+	return gs, paths
+}
+
+var benchMaps = []struct {
+	name   string
+	newMap func() *Map
+}{
+	{"InMemory", NewMap},
+	{"Compressed", func() *Map {
+		m := NewMap()
+		m.SetCompressed(true)
+		return m
+	}},
+	{"Spilling", func() *Map { return NewSpillingMap(inmemory.NewKeyValueDB(), 16*datasize.Kibibyte) }},
+}
+
+func BenchmarkPopulate(b *testing.B) {
+	ctx := context.Background()
+	gs, _ := benchCorpus(b)
+	for _, test := range benchMaps {
+		b.Run(test.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m := test.newMap()
+				if err := m.Populate(ctx, gs); err != nil {
+					b.Fatal(err)
+				}
+				m.Close(ctx)
+			}
+		})
+	}
+}
+
+func BenchmarkDirectory(b *testing.B) {
+	ctx := context.Background()
+	gs, dirs := benchCorpus(b)
+	for _, test := range benchMaps {
+		b.Run(test.name, func(b *testing.B) {
+			m := test.newMap()
+			defer m.Close(ctx)
+			if err := m.Populate(ctx, gs); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				req := &ftpb.DirectoryRequest{Corpus: "synthetic", Path: dirs[i%len(dirs)]}
+				if _, err := m.Directory(ctx, req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestCompressedMap(t *testing.T) {
+	ctx := context.Background()
+	plain, compressed := NewMap(), NewMap()
+	compressed.SetCompressed(true)
+	files := benchFiles(1000)
+	for _, m := range []*Map{plain, compressed} {
+		// Add the files in two updates, so that the second adds to
+		// directories already compressed, some of them files already present.
+		u := m.Update()
+		for _, f := range files[:600] {
+			u.AddFile(f)
+		}
+		u.Commit()
+		u = m.Update()
+		for _, f := range files[400:] {
+			u.AddFile(f)
+		}
+		u.Commit()
+	}
+
+	sortEntries := protocmp.SortRepeated(func(a, b *ftpb.DirectoryReply_Entry) bool {
+		return a.GetName() < b.GetName() || (a.GetName() == b.GetName() && a.GetGenerated() && !b.GetGenerated())
+	})
+	var dirs int
+	if err := plain.Walk(ctx, func(want *ftpb.DirectoryReply) error {
+		dirs++
+		got, err := compressed.Directory(ctx, &ftpb.DirectoryRequest{Corpus: want.Corpus, Root: want.Root, Path: want.Path})
+		if err != nil {
+			return err
+		}
+		if !sort.SliceIsSorted(got.Entry, func(i, j int) bool { return got.Entry[i].Name < got.Entry[j].Name }) {
+			t.Errorf("Compressed directory %q is not sorted", want.Path)
+		}
+		if diff := cmp.Diff(want, got, protocmp.Transform(), sortEntries); diff != "" {
+			t.Errorf("Directory %q (-plain +compressed):\n%s", want.Path, diff)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if dirs == 0 {
+		t.Fatal("No directories")
+	}
+
+	p, c := plain.shard(files[0].Corpus), compressed.shard(files[0].Corpus)
+	if c.size() >= p.size() {
+		t.Errorf("Compressed shard size %v, want less than %v", c.size(), p.size())
+	}
+	if len(c.entries) != 0 {
+		t.Errorf("Compressed shard has %d uncompressed entries", len(c.entries))
+	}
+}
+
+func TestBoundedRequests(t *testing.T) {
+	ctx := context.Background()
+	m := NewMap()
+	m.AddFile(&spb.VName{Corpus: "corpus", Path: "a/b/c/d.go"})
+	b := BoundedRequests{MaxPathDepth: 2, Service: m}
+
+	for _, test := range []struct {
+		path string
+		ok   bool
+	}{
+		{"", true},
+		{"/a/b/", true},
+		{"a/b/../b", true},
+		{"a/b/c", false},
+	} {
+		_, err := b.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", Path: test.path})
+		if test.ok && err != nil {
+			t.Errorf("Directory(%q): unexpected error: %v", test.path, err)
+		} else if !test.ok && err == nil {
+			t.Errorf("Directory(%q): got no error; want path too deep", test.path)
+		}
+	}
+}
+
+func TestDirectoryPages(t *testing.T) {
+	ctx := context.Background()
+	m := NewMap()
+	for _, path := range []string{"d/c.go", "d/a.go", "d/b/x.go", "d/b.go", "d/e/y.go"} {
+		m.AddFile(&spb.VName{Corpus: "corpus", Path: path})
+	}
+
+	// Pages of two entries visit every entry once, in order of their tokens.
+	var got []string
+	var tokens []string
+	req := &ftpb.DirectoryRequest{Corpus: "corpus", Path: "d", PageSize: 2}
+	for {
+		reply, err := m.Directory(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(reply.Entry) > 2 {
+			t.Errorf("Directory(%v): got %d entries; want at most 2", req, len(reply.Entry))
+		}
+		for _, e := range reply.Entry {
+			got = append(got, entryToken(e))
+		}
+		if reply.NextPageToken == "" {
+			break
+		}
+		tokens = append(tokens, reply.NextPageToken)
+		req.PageToken = reply.NextPageToken
+	}
+	if diff := cmp.Diff([]string{"a.go", "b.go", "b/", "c.go", "e/"}, got); diff != "" {
+		t.Errorf("Paged entries (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"b.go", "c.go"}, tokens); diff != "" {
+		t.Errorf("Page tokens (-want +got):\n%s", diff)
+	}
+
+	// A token need not name an entry still present.
+	reply, err := m.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", Path: "d", PageToken: "bb"})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(reply.Entry) != 2 || reply.Entry[0].Name != "c.go" || reply.NextPageToken != "" {
+		t.Errorf("Directory after %q: got %v", "bb", reply)
+	}
+
+	if _, err := m.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", Path: "d", PageSize: -1}); err == nil {
+		t.Error("Directory with negative page_size: got no error")
+	}
+	b := BoundedRequests{MaxPageSize: 2, Service: m}
+	if _, err := b.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", Path: "d", PageSize: 3}); err == nil {
+		t.Error("BoundedRequests.Directory with page_size 3: got no error; want page_size too large")
+	}
+
+	// The HTTP handler takes paging parameters from the query.
+	mux := http.NewServeMux()
+	RegisterHTTPHandlers(ctx, m, mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/dir?page_size=1&page_token=b.go", strings.NewReader(`{"corpus":"corpus","path":"d"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/dir: status %d: %s", rec.Code, rec.Body)
+	}
+	var hreply ftpb.DirectoryReply
+	if err := protojson.Unmarshal(rec.Body.Bytes(), &hreply); err != nil {
+		t.Fatal(err)
+	}
+	if len(hreply.Entry) != 1 || entryToken(hreply.Entry[0]) != "b/" || hreply.NextPageToken != "b/" {
+		t.Errorf("/dir: got %v; want entry b/ and next_page_token b/", &hreply)
+	}
+}
+
+func TestTree(t *testing.T) {
+	ctx := context.Background()
+	m := NewMap()
+	for _, path := range []string{"a/b/c/d.go", "a/b/e.go", "a/f.go", "g.go"} {
+		m.AddFile(&spb.VName{Corpus: "corpus", Path: path})
+	}
+	file := func(name string) *ftpb.DirectoryReply_Entry {
+		return &ftpb.DirectoryReply_Entry{Kind: ftpb.DirectoryReply_FILE, Name: name}
+	}
+	dir := func(name string, entries ...*ftpb.DirectoryReply_Entry) *ftpb.DirectoryReply_Entry {
+		return &ftpb.DirectoryReply_Entry{Kind: ftpb.DirectoryReply_DIRECTORY, Name: name, Entry: entries}
+	}
+
+	for _, test := range []struct {
+		path  string
+		depth int32
+		want  []*ftpb.DirectoryReply_Entry
+	}{
+		{"a", 1, []*ftpb.DirectoryReply_Entry{dir("b"), file("f.go")}},
+		{"a", 2, []*ftpb.DirectoryReply_Entry{dir("b", dir("c"), file("e.go")), file("f.go")}},
+		{"a", 0, []*ftpb.DirectoryReply_Entry{dir("b", dir("c", file("d.go")), file("e.go")), file("f.go")}},
+		{"", -1, []*ftpb.DirectoryReply_Entry{dir("a", dir("b", dir("c", file("d.go")), file("e.go")), file("f.go")), file("g.go")}},
+		{"a/b/c", 3, []*ftpb.DirectoryReply_Entry{file("d.go")}},
+	} {
+		got, err := m.Tree(ctx, &ftpb.TreeRequest{Corpus: "corpus", Path: test.path, MaxDepth: test.depth})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Path != test.path {
+			t.Errorf("Tree(%q, %d): got path %q", test.path, test.depth, got.Path)
+		}
+		if diff := cmp.Diff(test.want, got.Entry, protocmp.Transform()); diff != "" {
+			t.Errorf("Tree(%q, %d) (-want +got):\n%s", test.path, test.depth, diff)
+		}
+	}
+
+	// Trees do not change the directories of the map.
+	d, err := m.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", Path: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range d.Entry {
+		if len(e.Entry) != 0 {
+			t.Errorf("Directory entry %q has nested entries after Tree", e.Name)
+		}
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := m.Tree(cancelled, &ftpb.TreeRequest{Corpus: "corpus"}); err != context.Canceled {
+		t.Errorf("Tree with a cancelled context: got %v; want %v", err, context.Canceled)
+	}
+
+	b := BoundedRequests{MaxTreeDepth: 2, Service: m}
+	for _, depth := range []int32{0, 3} {
+		if _, err := b.Tree(ctx, &ftpb.TreeRequest{Corpus: "corpus", MaxDepth: depth}); err == nil {
+			t.Errorf("BoundedRequests.Tree with max_depth %d: got no error", depth)
+		}
+	}
+	if _, err := b.Tree(ctx, &ftpb.TreeRequest{Corpus: "corpus", MaxDepth: 2}); err != nil {
+		t.Errorf("BoundedRequests.Tree with max_depth 2: %v", err)
+	}
+
+	// The HTTP handler takes max_depth from the query.
+	mux := http.NewServeMux()
+	RegisterHTTPHandlers(ctx, m, mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/tree?max_depth=2", strings.NewReader(`{"corpus":"corpus","path":"a","max_depth":1}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/tree: status %d: %s", rec.Code, rec.Body)
+	}
+	var hreply ftpb.DirectoryReply
+	if err := protojson.Unmarshal(rec.Body.Bytes(), &hreply); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]*ftpb.DirectoryReply_Entry{dir("b", dir("c"), file("e.go")), file("f.go")}, hreply.Entry, protocmp.Transform()); diff != "" {
+		t.Errorf("/tree (-want +got):\n%s", diff)
+	}
+}
+
+func TestPathDepth(t *testing.T) {
+	for path, want := range map[string]int{"": 0, "/": 0, "a": 1, "/a/b/": 2, "a/./b/../c/d": 3, "ディレクトリ/ファイル": 2} {
+		if got := PathDepth(path); got != want {
+			t.Errorf("PathDepth(%q): got %d; want %d", path, got, want)
+		}
+	}
+}
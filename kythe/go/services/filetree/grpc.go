@@ -0,0 +1,106 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filetree
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+)
+
+// GRPCServer returns a FileTreeServiceServer serving ft, to be registered
+// with a gRPC server by ftpb.RegisterFileTreeServiceServer.
+func GRPCServer(ft Service) ftpb.FileTreeServiceServer { return &grpcServer{ft: ft} }
+
+type grpcServer struct {
+	ftpb.UnimplementedFileTreeServiceServer
+	ft Service
+}
+
+// CorpusRoots implements part of the ftpb.FileTreeServiceServer interface.
+func (s *grpcServer) CorpusRoots(ctx context.Context, req *ftpb.CorpusRootsRequest) (*ftpb.CorpusRootsReply, error) {
+	reply, err := s.ft.CorpusRoots(ctx, req)
+	return reply, statusError(err)
+}
+
+// Directory implements part of the ftpb.FileTreeServiceServer interface.
+func (s *grpcServer) Directory(ctx context.Context, req *ftpb.DirectoryRequest) (*ftpb.DirectoryReply, error) {
+	reply, err := s.ft.Directory(ctx, req)
+	return reply, statusError(err)
+}
+
+// Tree implements part of the ftpb.FileTreeServiceServer interface.
+func (s *grpcServer) Tree(ctx context.Context, req *ftpb.TreeRequest) (*ftpb.DirectoryReply, error) {
+	reply, err := s.ft.Tree(ctx, req)
+	return reply, statusError(err)
+}
+
+// Search implements part of the ftpb.FileTreeServiceServer interface.
+func (s *grpcServer) Search(ctx context.Context, req *ftpb.SearchRequest) (*ftpb.SearchReply, error) {
+	reply, err := s.ft.Search(ctx, req)
+	return reply, statusError(err)
+}
+
+// statusError returns err as a gRPC status error, so that clients see its
+// code rather than codes.Unknown.  Errors that carry no status are reported
+// as codes.Internal, except those of a done context.
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	} else if _, ok := status.FromError(err); ok {
+		return err
+	} else if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// GRPCClient returns a filetree Service calling the FileTreeService served
+// on cc.  Closing the Service does not close cc.
+func GRPCClient(cc grpc.ClientConnInterface) Service {
+	return &grpcClient{ftpb.NewFileTreeServiceClient(cc)}
+}
+
+type grpcClient struct{ client ftpb.FileTreeServiceClient }
+
+// CorpusRoots implements part of the Service interface.
+func (c *grpcClient) CorpusRoots(ctx context.Context, req *ftpb.CorpusRootsRequest) (*ftpb.CorpusRootsReply, error) {
+	return c.client.CorpusRoots(ctx, req)
+}
+
+// Directory implements part of the Service interface.
+func (c *grpcClient) Directory(ctx context.Context, req *ftpb.DirectoryRequest) (*ftpb.DirectoryReply, error) {
+	return c.client.Directory(ctx, req)
+}
+
+// Tree implements part of the Service interface.
+func (c *grpcClient) Tree(ctx context.Context, req *ftpb.TreeRequest) (*ftpb.DirectoryReply, error) {
+	return c.client.Tree(ctx, req)
+}
+
+// Search implements part of the Service interface.
+func (c *grpcClient) Search(ctx context.Context, req *ftpb.SearchRequest) (*ftpb.SearchReply, error) {
+	return c.client.Search(ctx, req)
+}
+
+// Close implements part of the Service interface.
+func (*grpcClient) Close(context.Context) error { return nil }
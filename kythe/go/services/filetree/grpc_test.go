@@ -0,0 +1,122 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filetree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestGRPC(t *testing.T) {
+	ctx := context.Background()
+	m := NewMap()
+	for _, path := range []string{"a/b/c.go", "a/d.go"} {
+		m.AddFile(&spb.VName{Corpus: "corpus", Path: path, Language: "go"})
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	ftpb.RegisterFileTreeServiceServer(srv, GRPCServer(BoundedRequests{MaxPageSize: 10, Service: m}))
+	go srv.Serve(lis)
+	defer srv.Stop()
+	conn, err := grpc.Dial("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := GRPCClient(conn)
+	defer client.Close(ctx)
+
+	check := func(method string, want, got any, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+			t.Errorf("%s (-want +got):\n%s", method, diff)
+		}
+	}
+	dirReq := &ftpb.DirectoryRequest{Corpus: "corpus", Path: "a", PageSize: 1}
+	want, err := m.Directory(ctx, dirReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.Directory(ctx, dirReq)
+	check("Directory", want, got, err)
+
+	treeReq := &ftpb.TreeRequest{Corpus: "corpus"}
+	want, err = m.Tree(ctx, treeReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = client.Tree(ctx, treeReq)
+	check("Tree", want, got, err)
+
+	crWant, err := m.CorpusRoots(ctx, &ftpb.CorpusRootsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	crGot, err := client.CorpusRoots(ctx, &ftpb.CorpusRootsRequest{})
+	check("CorpusRoots", crWant, crGot, err)
+
+	searchReq := &ftpb.SearchRequest{Corpus: "corpus", Pattern: "**/*.go", Syntax: ftpb.SearchRequest_GLOB, MaxResults: 10}
+	sWant, err := m.Search(ctx, searchReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sGot, err := client.Search(ctx, searchReq)
+	check("Search", sWant, sGot, err)
+
+	// The status of an error is passed to the client.
+	if _, err := client.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", PageSize: 11}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Directory with a page_size too large: got %v; want InvalidArgument", err)
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{nil, codes.OK},
+		{status.Error(codes.InvalidArgument, "bad request"), codes.InvalidArgument},
+		{context.Canceled, codes.Canceled},
+		{fmt.Errorf("reading spilled directory: %w", context.DeadlineExceeded), codes.DeadlineExceeded},
+		{errors.New("reading spilled directory: corrupt record"), codes.Internal},
+	}
+	for _, test := range tests {
+		if got := status.Code(statusError(test.err)); got != test.want {
+			t.Errorf("statusError(%v): got code %v; want %v", test.err, got, test.want)
+		}
+	}
+}
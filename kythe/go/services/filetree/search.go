@@ -0,0 +1,254 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filetree
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"kythe.io/kythe/go/util/kytheuri"
+
+	"golang.org/x/text/unicode/norm"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+)
+
+// Search implements part of the filetree.Service interface.  It serves from
+// a sorted index of the files of each root, built when a corpus is first
+// searched.  A map that has spilled directories to disk is searched by
+// walking its directories instead.
+func (m *Map) Search(ctx context.Context, req *ftpb.SearchRequest) (*ftpb.SearchReply, error) {
+	if m.db != nil {
+		return Search(ctx, m.Directory, req)
+	}
+	pm, err := compilePattern(req)
+	if err != nil {
+		return nil, err
+	}
+	reply := &ftpb.SearchReply{}
+	s := m.shard(req.GetCorpus())
+	if s == nil {
+		return reply, nil
+	}
+	root := norm.NFC.String(req.GetRoot())
+	paths := s.pathIndex()[root]
+	for i := sort.SearchStrings(paths, pm.prefix); i < len(paths) && strings.HasPrefix(paths[i], pm.prefix); i++ {
+		if i%4096 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		if pm.matches(paths[i]) && !addMatch(reply, req, root, paths[i]) {
+			break
+		}
+	}
+	return reply, nil
+}
+
+// Search returns the files matching req, found by walking with dir each
+// directory beneath the longest directory named by the start of the
+// pattern.  It implements the Search method of a Service from its Directory
+// method.
+func Search(ctx context.Context, dir func(context.Context, *ftpb.DirectoryRequest) (*ftpb.DirectoryReply, error), req *ftpb.SearchRequest) (*ftpb.SearchReply, error) {
+	pm, err := compilePattern(req)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	var walk func(string) error
+	walk = func(dirPath string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		d, err := dir(ctx, &ftpb.DirectoryRequest{Corpus: req.GetCorpus(), Root: req.GetRoot(), Path: dirPath})
+		if err != nil {
+			return err
+		}
+		for _, e := range d.GetEntry() {
+			p := path.Join(dirPath, e.GetName())
+			if e.GetKind() == ftpb.DirectoryReply_DIRECTORY {
+				if err := walk(p); err != nil {
+					return err
+				}
+			} else if pm.matches(p) {
+				paths = append(paths, p)
+			}
+		}
+		return nil
+	}
+	if err := walk(strings.TrimSuffix(pm.prefix, "/")); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(paths)
+	reply := &ftpb.SearchReply{}
+	for _, p := range paths {
+		if !addMatch(reply, req, req.GetRoot(), p) {
+			break
+		}
+	}
+	return reply, nil
+}
+
+// addMatch adds the ticket of the file at path to reply, unless reply holds
+// the most results requested by req, in which case it marks reply truncated
+// and returns false.
+func addMatch(reply *ftpb.SearchReply, req *ftpb.SearchRequest, root, path string) bool {
+	if max := int(req.GetMaxResults()); max > 0 && len(reply.Ticket) >= max {
+		reply.Truncated = true
+		return false
+	}
+	reply.Ticket = append(reply.Ticket, (&kytheuri.URI{
+		Corpus: req.GetCorpus(),
+		Root:   root,
+		Path:   path,
+	}).String())
+	return true
+}
+
+// A pathMatcher matches file paths against the pattern of a SearchRequest.
+type pathMatcher struct {
+	prefix string            // the directory, ending in "/", holding every match, or ""
+	match  func(string) bool // reports whether an NFC path matches
+}
+
+func (pm *pathMatcher) matches(path string) bool { return pm.match(norm.NFC.String(path)) }
+
+func compilePattern(req *ftpb.SearchRequest) (*pathMatcher, error) {
+	pattern := norm.NFC.String(req.GetPattern())
+	switch req.GetSyntax() {
+	case ftpb.SearchRequest_SUBSTRING:
+		pattern = strings.ToLower(pattern)
+		return &pathMatcher{match: func(p string) bool {
+			return strings.Contains(strings.ToLower(p), pattern)
+		}}, nil
+	case ftpb.SearchRequest_GLOB:
+		re, err := globRegexp(pattern)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid glob %q: %v", pattern, err)
+		}
+		prefix := pattern
+		if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+			prefix = pattern[:i]
+		}
+		return &pathMatcher{prefix: prefix[:strings.LastIndex(prefix, "/")+1], match: re.MatchString}, nil
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown pattern syntax: %v", req.GetSyntax())
+	}
+}
+
+// globRegexp returns a regular expression matching the paths matched by
+// glob, whose syntax is that of a GLOB SearchRequest.  A backslash quotes
+// the character following it.
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); {
+		switch glob[i] {
+		case '*':
+			if strings.HasPrefix(glob[i:], "**") && (i == 0 || glob[i-1] == '/') {
+				if rest := glob[i+2:]; rest == "" {
+					b.WriteString(".*")
+					i += 2
+					continue
+				} else if rest[0] == '/' {
+					b.WriteString("(?:.*/)?")
+					i += 3
+					continue
+				}
+			}
+			b.WriteString("[^/]*")
+			for i < len(glob) && glob[i] == '*' {
+				i++
+			}
+		case '?':
+			b.WriteString("[^/]")
+			i++
+		case '[':
+			j := i + 1
+			negate := j < len(glob) && (glob[j] == '!' || glob[j] == '^')
+			if negate {
+				j++
+			}
+			start := j
+			if j < len(glob) && glob[j] == ']' {
+				j++ // a leading ] is part of the class
+			}
+			end := strings.IndexByte(glob[j:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class at offset %d", i)
+			}
+			class := strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`).Replace(glob[start : j+end])
+			if negate {
+				b.WriteString("[^/" + class + "]")
+			} else {
+				b.WriteString("[" + class + "]")
+			}
+			i = j + end + 1
+		case '\\':
+			i++
+			if i == len(glob) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			fallthrough
+		default:
+			_, size := utf8.DecodeRuneInString(glob[i:])
+			b.WriteString(regexp.QuoteMeta(glob[i : i+size]))
+			i += size
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// A pathIndex holds the paths of the files of each root of a shard, sorted.
+type pathIndex map[string][]string
+
+// pathIndex returns the index of the files of s, building it on first use.
+// It must only be called once s is published.
+func (s *shard) pathIndex() pathIndex {
+	s.index.once.Do(func() {
+		idx := make(pathIndex)
+		for d := range s.dirList {
+			r := s.reply(int32(d))
+			for _, e := range r.Entry {
+				if e.Kind == ftpb.DirectoryReply_FILE {
+					idx[r.Root] = append(idx[r.Root], path.Join(r.Path, e.Name))
+				}
+			}
+		}
+		for _, paths := range idx {
+			sort.Strings(paths)
+		}
+		s.index.paths = idx
+	})
+	return s.index.paths
+}
+
+// A lazyIndex is the pathIndex of a shard, built when it is first needed.
+type lazyIndex struct {
+	once  sync.Once
+	paths pathIndex
+}
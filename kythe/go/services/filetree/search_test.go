@@ -0,0 +1,172 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filetree
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/storage/inmemory"
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+var searchFiles = []*spb.VName{
+	{Corpus: "corpus", Path: "a/b/c/d.go"},
+	{Corpus: "corpus", Path: "a/b/e.cc"},
+	{Corpus: "corpus", Path: "a/f.cc"},
+	{Corpus: "corpus", Path: "g.go"},
+	{Corpus: "corpus", Path: "src/Foo/Bar.cc"},
+	{Corpus: "corpus", Path: "src/foo/baz.h"},
+	{Corpus: "corpus", Path: "src/café.go"},
+	{Corpus: "corpus", Root: "gen", Path: "a/f.cc"},
+}
+
+func searchTicket(root, path string) string {
+	return (&kytheuri.URI{Corpus: "corpus", Root: root, Path: path}).String()
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	m := NewMap()
+	for _, f := range searchFiles {
+		m.AddFile(f)
+	}
+
+	const glob, substr = ftpb.SearchRequest_GLOB, ftpb.SearchRequest_SUBSTRING
+	for _, test := range []struct {
+		root    string
+		pattern string
+		syntax  ftpb.SearchRequest_Syntax
+		max     int32
+		want    []string
+		trunc   bool
+	}{
+		{"", "**/*.cc", glob, 0, []string{"a/b/e.cc", "a/f.cc", "src/Foo/Bar.cc"}, false},
+		{"", "**/*.cc", glob, 2, []string{"a/b/e.cc", "a/f.cc"}, true},
+		{"", "**/*.cc", glob, 3, []string{"a/b/e.cc", "a/f.cc", "src/Foo/Bar.cc"}, false},
+		{"", "a/*.cc", glob, 0, []string{"a/f.cc"}, false},
+		{"", "a/**", glob, 0, []string{"a/b/c/d.go", "a/b/e.cc", "a/f.cc"}, false},
+		{"", "a/b/*/d.go", glob, 0, []string{"a/b/c/d.go"}, false},
+		{"", "**/foo/*", glob, 0, []string{"src/foo/baz.h"}, false},
+		{"", "?.go", glob, 0, []string{"g.go"}, false},
+		{"", "*.go", glob, 0, []string{"g.go"}, false},
+		{"", "src/[Ff]oo/*", glob, 0, []string{"src/Foo/Bar.cc", "src/foo/baz.h"}, false},
+		{"", "src/[!F]oo/*", glob, 0, []string{"src/foo/baz.h"}, false},
+		{"", "src/café.go", glob, 0, []string{"src/café.go"}, false},
+		{"", "missing/**", glob, 0, nil, false},
+		{"gen", "**/*.cc", glob, 0, []string{"a/f.cc"}, false},
+		{"", "FOO", substr, 0, []string{"src/Foo/Bar.cc", "src/foo/baz.h"}, false},
+		{"", "b/e", substr, 0, []string{"a/b/e.cc"}, false},
+		{"", "CAFÉ", substr, 0, []string{"src/café.go"}, false},
+		{"", ".go", substr, 1, []string{"a/b/c/d.go"}, true},
+	} {
+		req := &ftpb.SearchRequest{Corpus: "corpus", Root: test.root, Pattern: test.pattern, Syntax: test.syntax, MaxResults: test.max}
+		var want []string
+		for _, p := range test.want {
+			want = append(want, searchTicket(test.root, p))
+		}
+		for name, search := range map[string]func(context.Context, *ftpb.SearchRequest) (*ftpb.SearchReply, error){
+			"Map.Search": m.Search,
+			"Search": func(ctx context.Context, req *ftpb.SearchRequest) (*ftpb.SearchReply, error) {
+				return Search(ctx, m.Directory, req)
+			},
+		} {
+			got, err := search(ctx, req)
+			if err != nil {
+				t.Fatalf("%s(%v): %v", name, req, err)
+			}
+			if diff := cmp.Diff(want, got.Ticket); diff != "" {
+				t.Errorf("%s(%q, %v) (-want +got):\n%s", name, test.pattern, test.syntax, diff)
+			}
+			if got.Truncated != test.trunc {
+				t.Errorf("%s(%q, %v): got truncated %v; want %v", name, test.pattern, test.syntax, got.Truncated, test.trunc)
+			}
+		}
+	}
+
+	for _, pattern := range []string{"[a", `a\`} {
+		_, err := m.Search(ctx, &ftpb.SearchRequest{Corpus: "corpus", Pattern: pattern, Syntax: glob})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Search(%q): got %v; want InvalidArgument", pattern, err)
+		}
+	}
+
+	b := BoundedRequests{MaxPageSize: 2, Service: m}
+	for _, max := range []int32{0, 3} {
+		if _, err := b.Search(ctx, &ftpb.SearchRequest{Corpus: "corpus", Pattern: "a", MaxResults: max}); err == nil {
+			t.Errorf("BoundedRequests.Search with max_results %d: got no error", max)
+		}
+	}
+	if _, err := b.Search(ctx, &ftpb.SearchRequest{Corpus: "corpus", Pattern: "a", MaxResults: 2}); err != nil {
+		t.Errorf("BoundedRequests.Search with max_results 2: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterHTTPHandlers(ctx, m, mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/files", strings.NewReader(`{"corpus":"corpus","pattern":"**/*.h","syntax":"GLOB"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/files: status %d: %s", rec.Code, rec.Body)
+	}
+	var hreply ftpb.SearchReply
+	if err := protojson.Unmarshal(rec.Body.Bytes(), &hreply); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{searchTicket("", "src/foo/baz.h")}, hreply.Ticket); diff != "" {
+		t.Errorf("/files (-want +got):\n%s", diff)
+	}
+}
+
+func TestSearchSpillingMap(t *testing.T) {
+	ctx := context.Background()
+	gs := new(inmemory.GraphStore)
+	for _, f := range searchFiles {
+		if err := gs.Write(ctx, &spb.WriteRequest{
+			Source: f,
+			Update: []*spb.WriteRequest_Update{{FactName: facts.NodeKind, FactValue: []byte(nodes.File)}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := NewSpillingMap(inmemory.NewKeyValueDB(), 128)
+	defer m.Close(ctx)
+	if err := m.Populate(ctx, gs); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := m.Search(ctx, &ftpb.SearchRequest{Corpus: "corpus", Pattern: "src/**", Syntax: ftpb.SearchRequest_GLOB})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{searchTicket("", "src/Foo/Bar.cc"), searchTicket("", "src/café.go"), searchTicket("", "src/foo/baz.h")}
+	if diff := cmp.Diff(want, got.Ticket); diff != "" {
+		t.Errorf("Search (-want +got):\n%s", diff)
+	}
+}
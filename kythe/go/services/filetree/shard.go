@@ -0,0 +1,450 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filetree
+
+import (
+	"hash/maphash"
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"kythe.io/kythe/go/util/datasize"
+
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+)
+
+// A shard holds the directories of one corpus.  Apart from a handful of
+// slices, its storage holds no pointers: names are offsets into a shared
+// arena, and directories and entries are indices into flat arrays.  The
+// garbage collector need not scan any of it, however many files it holds.
+//
+// A shard is changed only by the Update that created or copied it, and is
+// immutable once published.
+type shard struct {
+	corpus string
+	roots  []string // every root added, retained when the shard is spilled
+
+	names   nameTable
+	dirs    map[dirKey]int32 // index of each directory in dirList
+	dirList []dir
+	entries []entry
+
+	// The compressed entries of every directory, for a compacted shard; see
+	// compact.  Each compaction builds a new array, so it is shared by copies.
+	packed []byte
+
+	infos []fileInfo // the metadata of file entries
+
+	index lazyIndex // the files of each root, for a published shard; see pathIndex
+}
+
+// A dirKey identifies a directory of a shard by its root and path.
+type dirKey struct{ root, path name }
+
+// A dir is a directory, whose entries form a list threaded through the
+// entries of its shard.
+type dir struct {
+	key         dirKey
+	first, last int32 // indices of the first and last entries, or -1
+	packed      span  // compressed entries preceding the first, in packed
+}
+
+// An entry is a directory entry.
+type entry struct {
+	name  name
+	next  int32 // index of the next entry of the directory, or -1
+	info  int32 // 1 + the index of the entry's metadata in infos, or 0
+	flags uint8
+}
+
+// A fileInfo is the metadata of a file entry.  Its zero value, whose names
+// are the empty string, is that of a file with no metadata.
+type fileInfo struct {
+	language, encoding name
+	size               int64
+}
+
+// Flags of an entry.
+const (
+	entryDirectory uint8 = 1 << iota
+	entryGenerated
+)
+
+func entryFlags(kind ftpb.DirectoryReply_Kind, generated bool) uint8 {
+	var flags uint8
+	if kind == ftpb.DirectoryReply_DIRECTORY {
+		flags |= entryDirectory
+	}
+	if generated {
+		flags |= entryGenerated
+	}
+	return flags
+}
+
+func newShard(corpus string) *shard {
+	s := &shard{
+		corpus: corpus,
+		names:  newNameTable(),
+		dirs:   make(map[dirKey]int32),
+	}
+	s.names.intern("") // name 0, so that the names of a zero fileInfo are empty
+	return s
+}
+
+// clone returns a copy of s that can be changed without affecting s.
+func (s *shard) clone() *shard {
+	return &shard{
+		corpus:  s.corpus,
+		roots:   slices.Clone(s.roots),
+		names:   s.names.clone(),
+		dirs:    maps.Clone(s.dirs),
+		dirList: slices.Clone(s.dirList),
+		entries: slices.Clone(s.entries),
+		packed:  s.packed,
+		infos:   slices.Clone(s.infos),
+	}
+}
+
+// Approximate sizes of the elements of a shard, including their share of the
+// maps that index them.
+const (
+	dirSize   = 8 + 4 + 8 + 24 // key, value, and overhead in dirs; dirList element
+	entrySize = 16
+	infoSize  = 16
+	nameSize  = 8 + 4 + 8 + 8 + 4 // key, value, and overhead in index; span; chain
+)
+
+// size returns the approximate memory used by s.
+func (s *shard) size() datasize.Size {
+	return s.names.size() + datasize.Size(len(s.dirList)*dirSize+len(s.entries)*entrySize+len(s.infos)*infoSize+len(s.packed))
+}
+
+// ensureDir returns the index of the given directory of s, adding it and its
+// ancestors if necessary.
+func (s *shard) ensureDir(root, path string) int32 {
+	if path == "." {
+		path = ""
+	}
+	key := dirKey{s.names.intern(root), s.names.intern(path)}
+	if i, ok := s.dirs[key]; ok {
+		return i
+	}
+	i := s.newDir(key)
+	s.addRoot(root)
+
+	if path != "" {
+		parent := s.ensureDir(root, filepath.Dir(path))
+		s.addEntry(parent, filepath.Base(path), entryFlags(ftpb.DirectoryReply_DIRECTORY, root != ""))
+	}
+	return i
+}
+
+// newDir adds an empty directory to s and returns its index.
+func (s *shard) newDir(key dirKey) int32 {
+	i := int32(len(s.dirList))
+	s.dirList = append(s.dirList, dir{key: key, first: -1, last: -1})
+	s.dirs[key] = i
+	return i
+}
+
+func (s *shard) addRoot(root string) {
+	for _, r := range s.roots {
+		if r == root {
+			return
+		}
+	}
+	s.roots = append(s.roots, s.names.str(s.names.intern(root)))
+}
+
+// addEntry adds an entry to directory d of s, if it is not already present.
+func (s *shard) addEntry(d int32, nm string, flags uint8) {
+	if s.hasEntry(d, nm, flags) {
+		return
+	}
+	n := s.names.intern(nm)
+	i := int32(len(s.entries))
+	s.entries = append(s.entries, entry{name: n, next: -1, flags: flags})
+	if last := s.dirList[d].last; last >= 0 {
+		s.entries[last].next = i
+	} else {
+		s.dirList[d].first = i
+	}
+	s.dirList[d].last = i
+}
+
+// hasEntry reports whether directory d of s has an entry with the given name
+// and flags.  Unlike findEntry, it does not change s.
+func (s *shard) hasEntry(d int32, nm string, flags uint8) bool {
+	present := false
+	s.eachPacked(d, func(name []byte, fl uint8, _ int32) bool {
+		present = fl == flags && string(name) == nm
+		return !present
+	})
+	if present {
+		return true
+	}
+	n, ok := s.names.lookup(nm)
+	if !ok {
+		return false
+	}
+	for i := s.dirList[d].first; i >= 0; i = s.entries[i].next {
+		if e := s.entries[i]; e.name == n && e.flags == flags {
+			return true
+		}
+	}
+	return false
+}
+
+// findEntry returns the index of the entry of directory d of s with the
+// given name and flags, and of the entry preceding it, or -1.  It unpacks the
+// compressed entries of d; see unpack.
+func (s *shard) findEntry(d int32, nm string, flags uint8) (i, prev int32, ok bool) {
+	s.unpack(d)
+	n, ok := s.names.lookup(nm)
+	if !ok {
+		return -1, -1, false
+	}
+	prev = -1
+	for i := s.dirList[d].first; i >= 0; prev, i = i, s.entries[i].next {
+		if e := s.entries[i]; e.name == n && e.flags == flags {
+			return i, prev, true
+		}
+	}
+	return -1, -1, false
+}
+
+// removeEntry removes the entry of directory d of s with the given name and
+// flags, and reports whether it was present.
+func (s *shard) removeEntry(d int32, nm string, flags uint8) bool {
+	i, prev, ok := s.findEntry(d, nm, flags)
+	if !ok {
+		return false
+	}
+	dir := &s.dirList[d]
+	if prev >= 0 {
+		s.entries[prev].next = s.entries[i].next
+	} else {
+		dir.first = s.entries[i].next
+	}
+	if dir.last == i {
+		dir.last = prev
+	}
+	return true
+}
+
+// updateInfo calls f with the metadata of the entry of directory d of s with
+// the given name and flags, and reports whether the entry was present.
+func (s *shard) updateInfo(d int32, nm string, flags uint8, f func(*fileInfo)) bool {
+	i, _, ok := s.findEntry(d, nm, flags)
+	if !ok {
+		return false
+	}
+	e := &s.entries[i]
+	if e.info == 0 {
+		s.infos = append(s.infos, fileInfo{})
+		e.info = int32(len(s.infos))
+	}
+	f(&s.infos[e.info-1])
+	return true
+}
+
+// unpack moves the compressed entries of directory d of s to the front of
+// its list of entries, so that they can be removed or changed.
+func (s *shard) unpack(d int32) {
+	if s.dirList[d].packed.len == 0 {
+		return
+	}
+	first, last := int32(-1), int32(-1)
+	s.eachPacked(d, func(name []byte, flags uint8, info int32) bool {
+		i := int32(len(s.entries))
+		s.entries = append(s.entries, entry{name: s.names.intern(string(name)), next: -1, info: info, flags: flags})
+		if last >= 0 {
+			s.entries[last].next = i
+		} else {
+			first = i
+		}
+		last = i
+		return true
+	})
+	dir := &s.dirList[d]
+	if dir.first >= 0 {
+		s.entries[last].next = dir.first
+	} else {
+		dir.last = last
+	}
+	dir.first = first
+	dir.packed = span{}
+}
+
+// isEmpty reports whether directory d of s has no entries.
+func (s *shard) isEmpty(d int32) bool {
+	return s.dirList[d].first < 0 && s.dirList[d].packed.len == 0
+}
+
+// removeDir removes directory d from s, moving the last directory of s into
+// its place.
+func (s *shard) removeDir(d int32) {
+	delete(s.dirs, s.dirList[d].key)
+	last := int32(len(s.dirList) - 1)
+	if d != last {
+		s.dirList[d] = s.dirList[last]
+		s.dirs[s.dirList[d].key] = d
+	}
+	s.dirList = s.dirList[:last]
+}
+
+// lookupDir returns the index of the given directory of s, if it has one.
+func (s *shard) lookupDir(root, path string) (int32, bool) {
+	r, ok := s.names.lookup(root)
+	if !ok {
+		return 0, false
+	}
+	p, ok := s.names.lookup(path)
+	if !ok {
+		return 0, false
+	}
+	d, ok := s.dirs[dirKey{r, p}]
+	return d, ok
+}
+
+// directory returns the given directory of s, or nil if it has none.
+func (s *shard) directory(root, path string) *ftpb.DirectoryReply {
+	d, ok := s.lookupDir(root, path)
+	if !ok {
+		return nil
+	}
+	return s.reply(d)
+}
+
+// reply returns directory d of s as a DirectoryReply.
+func (s *shard) reply(d int32) *ftpb.DirectoryReply {
+	dir := s.dirList[d]
+	reply := &ftpb.DirectoryReply{
+		Corpus: s.corpus,
+		Root:   s.names.str(dir.key.root),
+		Path:   s.names.str(dir.key.path),
+	}
+	s.eachPacked(d, func(name []byte, flags uint8, info int32) bool {
+		reply.Entry = append(reply.Entry, s.replyEntry(string(name), flags, info))
+		return true
+	})
+	for i := dir.first; i >= 0; i = s.entries[i].next {
+		e := s.entries[i]
+		reply.Entry = append(reply.Entry, s.replyEntry(s.names.str(e.name), e.flags, e.info))
+	}
+	return reply
+}
+
+// replyEntry returns an entry of s as a DirectoryReply entry.
+func (s *shard) replyEntry(name string, flags uint8, info int32) *ftpb.DirectoryReply_Entry {
+	kind := ftpb.DirectoryReply_FILE
+	if flags&entryDirectory != 0 {
+		kind = ftpb.DirectoryReply_DIRECTORY
+	}
+	e := &ftpb.DirectoryReply_Entry{
+		Kind:      kind,
+		Name:      name,
+		Generated: flags&entryGenerated != 0,
+	}
+	if info > 0 {
+		fi := s.infos[info-1]
+		e.Language = s.names.str(fi.language)
+		e.Encoding = s.names.str(fi.encoding)
+		e.Size = fi.size
+	}
+	return e
+}
+
+// A name is the index of a distinct string in a nameTable.
+type name int32
+
+// A nameTable interns strings in a single arena.  Its index is a hash table
+// chained through an array, so that it too is free of pointers.
+type nameTable struct {
+	seed  maphash.Seed
+	arena []byte
+	spans []span           // the bytes of each name in arena
+	index map[uint64]int32 // the first name with each hash
+	chain []int32          // the next name with the same hash as each name, or -1
+}
+
+type span struct{ off, len uint32 }
+
+func newNameTable() nameTable {
+	return nameTable{seed: maphash.MakeSeed(), index: make(map[uint64]int32)}
+}
+
+func (t *nameTable) clone() nameTable {
+	return nameTable{
+		seed:  t.seed,
+		arena: slices.Clone(t.arena),
+		spans: slices.Clone(t.spans),
+		index: maps.Clone(t.index),
+		chain: slices.Clone(t.chain),
+	}
+}
+
+func (t *nameTable) bytes(n name) []byte {
+	sp := t.spans[n]
+	return t.arena[sp.off : sp.off+sp.len]
+}
+
+// str returns the string of name n.
+func (t *nameTable) str(n name) string { return string(t.bytes(n)) }
+
+// lookup returns the name of s, if s has been interned.
+func (t *nameTable) lookup(s string) (name, bool) {
+	h := maphash.String(t.seed, s)
+	i, ok := t.index[h]
+	if !ok {
+		return 0, false
+	}
+	for ; i >= 0; i = t.chain[i] {
+		if string(t.bytes(name(i))) == s {
+			return name(i), true
+		}
+	}
+	return 0, false
+}
+
+// intern returns the name of s, adding s to t if necessary.
+func (t *nameTable) intern(s string) name {
+	h := maphash.String(t.seed, s)
+	head, ok := t.index[h]
+	if !ok {
+		head = -1
+	}
+	for i := head; i >= 0; i = t.chain[i] {
+		if string(t.bytes(name(i))) == s {
+			return name(i)
+		}
+	}
+	n := name(len(t.spans))
+	t.spans = append(t.spans, span{off: uint32(len(t.arena)), len: uint32(len(s))})
+	t.arena = append(t.arena, s...)
+	t.chain = append(t.chain, head)
+	t.index[h] = int32(n)
+	return n
+}
+
+// len returns the number of distinct names in t.
+func (t *nameTable) len() int { return len(t.spans) }
+
+// size returns the approximate memory used by t.
+func (t *nameTable) size() datasize.Size {
+	return datasize.Size(len(t.arena) + len(t.spans)*nameSize)
+}
@@ -0,0 +1,212 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filetree
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"slices"
+
+	"kythe.io/kythe/go/storage/keyvalue"
+	"kythe.io/kythe/go/util/datasize"
+	"kythe.io/kythe/go/util/log"
+
+	"google.golang.org/protobuf/proto"
+
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+)
+
+// NewSpillingMap returns an empty filetree map that spills to disk for hosts
+// with too little memory to hold a large tree.  Whenever the estimated size
+// of its directories exceeds budget during Populate, they are moved into db,
+// where each spill of a directory is kept as a separate copy.  Directory then
+// serves the merge of the spilled and in-memory copies of a directory, which
+// is slower than serving from memory alone.  AddFile alone does not spill.
+//
+// The db should be a scratch store, such as one returned by leveldb.OpenTemp.
+// It is closed when the map is closed.
+func NewSpillingMap(db keyvalue.DB, budget datasize.Size) *Map {
+	m := NewMap()
+	m.db = db
+	m.budget = budget
+	return m
+}
+
+// spillPrefix returns the prefix of the keys of the spilled copies of a
+// directory.
+func spillPrefix(corpus, root, path string) []byte {
+	return []byte(corpus + "\n" + root + "\n" + path + "\n")
+}
+
+// spillKey returns the key of the copy of a directory written by the given
+// spill, which sorts after those of earlier spills.
+func spillKey(corpus, root, path string, spill int) []byte {
+	return binary.BigEndian.AppendUint32(spillPrefix(corpus, root, path), uint32(spill))
+}
+
+// spillNumber returns the number of the spill that wrote the copy with the
+// given key.
+func spillNumber(key []byte) int {
+	return int(binary.BigEndian.Uint32(key[len(key)-4:]))
+}
+
+// visible reports whether readers of snap see the copy written by the given
+// spill.
+func (snap *snapshot) visible(spill int) bool {
+	return spill < snap.spills && !snap.discarded[spill]
+}
+
+// spilled returns the merge of the copies of the given directory spilled
+// before snap was published, or nil if it had not been spilled.
+func (m *Map) spilled(ctx context.Context, snap *snapshot, corpus, root, path string) (*ftpb.DirectoryReply, error) {
+	it, err := m.db.ScanPrefix(ctx, spillPrefix(corpus, root, path), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var d *ftpb.DirectoryReply
+	for {
+		key, rec, err := it.Next()
+		if err == io.EOF {
+			return d, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if !snap.visible(spillNumber(key)) {
+			continue
+		}
+		if d, err = mergeSpilled(d, rec); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// mergeSpilled returns the merge of d, if non-nil, with the spilled copy rec
+// of the same directory.
+func mergeSpilled(d *ftpb.DirectoryReply, rec []byte) (*ftpb.DirectoryReply, error) {
+	var c ftpb.DirectoryReply
+	if err := proto.Unmarshal(rec, &c); err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return &c, nil
+	}
+	d.Entry = mergeEntries(d.Entry, c.Entry)
+	return d, nil
+}
+
+// spill moves the directories of u into the store of its map, replacing each
+// shard of u with an empty one for the same corpus and roots.
+func (u *Update) spill(ctx context.Context) error {
+	size := u.size()
+	var n int
+	for corpus, s := range u.shards {
+		spilled, err := u.m.spillShard(ctx, s)
+		if err != nil {
+			return err
+		}
+		n += spilled
+		empty := newShard(s.corpus)
+		empty.roots = slices.Clone(s.roots)
+		u.shards[corpus] = empty
+		u.copied[corpus] = true
+	}
+	log.InfoContextf(ctx, "Spilled %d directories (~%s) to disk", n, size)
+	u.m.spills++
+	return nil
+}
+
+// spillShard moves the directories of s into the store of m, as the copies
+// of spill number m.spills, and returns the number moved.  Each directory is
+// written as it is marshaled, so that spilling needs little memory.
+func (m *Map) spillShard(ctx context.Context, s *shard) (int, error) {
+	wr, err := m.db.Writer(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for i := range s.dirList {
+		dir := s.reply(int32(i))
+		rec, err := proto.Marshal(dir)
+		if err != nil {
+			wr.Close()
+			return 0, err
+		}
+		if err := wr.Write(spillKey(dir.Corpus, dir.Root, dir.Path, m.spills), rec); err != nil {
+			wr.Close()
+			return 0, err
+		}
+	}
+	if err := wr.Close(); err != nil {
+		return 0, err
+	}
+	return len(s.dirList), nil
+}
+
+// walkSpilled calls f with the merged copies of each directory spilled before
+// snap was published whose key prefix is not in skip.
+func (m *Map) walkSpilled(ctx context.Context, snap *snapshot, skip map[string]bool, f func(*ftpb.DirectoryReply) error) error {
+	it, err := m.db.ScanPrefix(ctx, nil, &keyvalue.Options{LargeRead: true})
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	// The copies of a directory are adjacent, since their keys share a prefix
+	// followed by a fixed-length spill number.
+	var prefix string
+	var d *ftpb.DirectoryReply
+	for {
+		key, rec, err := it.Next()
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if err == io.EOF || string(key[:len(key)-4]) != prefix {
+			if d != nil {
+				if err := f(d); err != nil {
+					return err
+				}
+				d = nil
+			}
+			if err == io.EOF {
+				return nil
+			}
+			prefix = string(key[:len(key)-4])
+		}
+		if skip[prefix] || !snap.visible(spillNumber(key)) {
+			continue
+		}
+		if d, err = mergeSpilled(d, rec); err != nil {
+			return err
+		}
+	}
+}
+
+// mergeEntries adds to entries each of the given additions not already
+// present, identified by kind and name.
+func mergeEntries(entries, additions []*ftpb.DirectoryReply_Entry) []*ftpb.DirectoryReply_Entry {
+	present := make(map[string]bool, len(entries)+len(additions))
+	for _, e := range entries {
+		present[entryToken(e)] = true
+	}
+	for _, e := range additions {
+		if tok := entryToken(e); !present[tok] {
+			present[tok] = true
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filetree
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"kythe.io/kythe/go/storage/inmemory"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestSpillingMap(t *testing.T) {
+	ctx := context.Background()
+	gs := new(inmemory.GraphStore)
+	var paths []string
+	for i := 0; i < 50; i++ {
+		paths = append(paths, fmt.Sprintf("src/dir%d/file%d.go", i%7, i))
+	}
+	paths = append(paths, "README.md")
+	for _, path := range paths {
+		for _, root := range []string{"", "gen"} {
+			if err := gs.Write(ctx, &spb.WriteRequest{
+				Source: &spb.VName{Corpus: "corpus", Root: root, Path: path},
+				Update: []*spb.WriteRequest_Update{{FactName: facts.NodeKind, FactValue: []byte(nodes.File)}},
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	want := NewMap()
+	if err := want.Populate(ctx, gs); err != nil {
+		t.Fatal(err)
+	}
+	// A budget this small spills every few files.
+	m := NewSpillingMap(inmemory.NewKeyValueDB(), 512)
+	defer m.Close(ctx)
+	if err := m.Populate(ctx, gs); err != nil {
+		t.Fatal(err)
+	}
+	if m.spills < 10 {
+		t.Errorf("Map spilled %d times, want at least 10", m.spills)
+	}
+
+	sortEntries := protocmp.SortRepeated(func(a, b *ftpb.DirectoryReply_Entry) bool {
+		return a.GetName() < b.GetName() || (a.GetName() == b.GetName() && a.GetGenerated() && !b.GetGenerated())
+	})
+	for _, root := range []string{"", "gen"} {
+		for _, path := range []string{"", "src", "src/dir0", "src/dir6", "missing"} {
+			req := &ftpb.DirectoryRequest{Corpus: "corpus", Root: root, Path: path}
+			wantDir, err := want.Directory(ctx, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := m.Directory(ctx, req)
+			if err != nil {
+				t.Fatalf("Directory(%v): %v", req, err)
+			}
+			if diff := cmp.Diff(wantDir, got, protocmp.Transform(), sortEntries); diff != "" {
+				t.Errorf("Directory(%v) (-want +got):\n%s", req, diff)
+			}
+		}
+	}
+
+	cr, err := m.CorpusRoots(ctx, &ftpb.CorpusRootsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCR, err := want.CorpusRoots(ctx, &ftpb.CorpusRootsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(wantCR, cr, protocmp.Transform(), protocmp.SortRepeated(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("CorpusRoots (-want +got):\n%s", diff)
+	}
+
+	// Walk visits each directory once, merging its spilled copies.
+	walk := func(m *Map) map[string]*ftpb.DirectoryReply {
+		dirs := make(map[string]*ftpb.DirectoryReply)
+		if err := m.Walk(ctx, func(d *ftpb.DirectoryReply) error {
+			key := d.Root + ":" + d.Path
+			if dirs[key] != nil {
+				t.Errorf("Walk visited %q twice", key)
+			}
+			dirs[key] = d
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return dirs
+	}
+	if diff := cmp.Diff(walk(want), walk(m), protocmp.Transform(), sortEntries); diff != "" {
+		t.Errorf("Walk (-want +got):\n%s", diff)
+	}
+}
@@ -20,7 +20,6 @@ package graph // import "kythe.io/kythe/go/services/graph"
 
 import (
 	"context"
-	"fmt"
 	"math"
 	"net/http"
 	"sort"
@@ -29,6 +28,9 @@ import (
 	"kythe.io/kythe/go/services/web"
 	"kythe.io/kythe/go/util/log"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	cpb "kythe.io/kythe/proto/common_go_proto"
 	gpb "kythe.io/kythe/proto/graph_go_proto"
 )
@@ -103,45 +105,58 @@ func AllEdges(ctx context.Context, es Service, req *gpb.EdgesRequest) (*gpb.Edge
 }
 
 // BoundedRequests guards against requests for more tickets than allowed per
-// the MaxTickets configuration.
+// the MaxTickets configuration, and for pages larger than MaxPageSize.  A
+// zero limit is not enforced.
 type BoundedRequests struct {
-	MaxTickets int
+	MaxTickets  int
+	MaxPageSize int
 	Service
 }
 
 // Nodes implements part of the Service interface.
 func (b BoundedRequests) Nodes(ctx context.Context, req *gpb.NodesRequest) (*gpb.NodesReply, error) {
-	if len(req.Ticket) > b.MaxTickets {
-		return nil, fmt.Errorf("too many tickets requested: %d (max %d)", len(req.Ticket), b.MaxTickets)
+	if b.MaxTickets > 0 && len(req.Ticket) > b.MaxTickets {
+		return nil, status.Errorf(codes.InvalidArgument, "too many tickets requested: %d (max %d)", len(req.Ticket), b.MaxTickets)
 	}
 	return b.Service.Nodes(ctx, req)
 }
 
 // Edges implements part of the Service interface.
 func (b BoundedRequests) Edges(ctx context.Context, req *gpb.EdgesRequest) (*gpb.EdgesReply, error) {
-	if len(req.Ticket) > b.MaxTickets {
-		return nil, fmt.Errorf("too many tickets requested: %d (max %d)", len(req.Ticket), b.MaxTickets)
+	if b.MaxTickets > 0 && len(req.Ticket) > b.MaxTickets {
+		return nil, status.Errorf(codes.InvalidArgument, "too many tickets requested: %d (max %d)", len(req.Ticket), b.MaxTickets)
+	} else if b.MaxPageSize > 0 && int(req.PageSize) > b.MaxPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size too large: %d (max %d)", req.PageSize, b.MaxPageSize)
 	}
 	return b.Service.Edges(ctx, req)
 }
 
-type webClient struct{ addr string }
+type webClient struct {
+	addr   string
+	client *web.Client
+}
 
 // Nodes implements part of the Service interface.
 func (w *webClient) Nodes(ctx context.Context, q *gpb.NodesRequest) (*gpb.NodesReply, error) {
 	var reply gpb.NodesReply
-	return &reply, web.Call(w.addr, "nodes", q, &reply)
+	return &reply, w.client.CallContext(ctx, w.addr, "nodes", q, &reply)
 }
 
 // Edges implements part of the Service interface.
 func (w *webClient) Edges(ctx context.Context, q *gpb.EdgesRequest) (*gpb.EdgesReply, error) {
 	var reply gpb.EdgesReply
-	return &reply, web.Call(w.addr, "edges", q, &reply)
+	return &reply, w.client.CallContext(ctx, w.addr, "edges", q, &reply)
 }
 
 // WebClient returns a graph Service based on a remote web server.
 func WebClient(addr string) Service {
-	return &webClient{addr}
+	return WebClientWithOptions(addr, nil)
+}
+
+// WebClientWithOptions returns a graph Service based on a remote web server,
+// called with the given options.  If opts == nil, default options are used.
+func WebClientWithOptions(addr string, opts *web.Options) Service {
+	return &webClient{addr, web.NewClient(opts)}
 }
 
 // RegisterHTTPHandlers registers JSON HTTP handlers with mux using the given
@@ -165,12 +180,12 @@ func RegisterHTTPHandlers(ctx context.Context, gs Service, mux *http.ServeMux) {
 
 		var req gpb.NodesRequest
 		if err := web.ReadJSONBody(r, &req); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			web.WriteError(w, err, http.StatusBadRequest)
 			return
 		}
-		reply, err := gs.Nodes(ctx, &req)
+		reply, err := gs.Nodes(r.Context(), &req)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			web.WriteError(w, err, http.StatusInternalServerError)
 			return
 		}
 		if err := web.WriteResponse(w, r, reply); err != nil {
@@ -185,12 +200,12 @@ func RegisterHTTPHandlers(ctx context.Context, gs Service, mux *http.ServeMux) {
 
 		var req gpb.EdgesRequest
 		if err := web.ReadJSONBody(r, &req); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			web.WriteError(w, err, http.StatusBadRequest)
 			return
 		}
-		reply, err := gs.Edges(ctx, &req)
+		reply, err := gs.Edges(r.Context(), &req)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			web.WriteError(w, err, http.StatusInternalServerError)
 			return
 		}
 		if err := web.WriteResponse(w, r, reply); err != nil {
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graphstore
+
+import (
+	"context"
+	"sync"
+
+	"kythe.io/kythe/go/util/audit"
+	"kythe.io/kythe/go/util/log"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// Audit returns a Service that records the writes made through gs, as
+// actions on target, in l.  Recording every write would swamp the log, so it
+// records a "graphstore.write.start" event when the first write is made and a
+// "graphstore.write" event summarizing all of them, with the first failure,
+// when the Service is closed.  Writes are attributed to the actor of the
+// first write's context (see audit.WithActor).  A process that exits without
+// closing the Service leaves only the start event.
+func Audit(gs Service, l *audit.Log, target string) Service {
+	return &auditedStore{Service: gs, log: l, target: target}
+}
+
+type auditedStore struct {
+	Service
+	log    *audit.Log
+	target string
+
+	mu       sync.Mutex
+	actor    string
+	requests int
+	updates  int
+	failed   error
+}
+
+// Write implements part of the Service interface.
+func (s *auditedStore) Write(ctx context.Context, req *spb.WriteRequest) error {
+	err := s.Service.Write(ctx, req)
+
+	s.mu.Lock()
+	first := s.actor == ""
+	if first {
+		s.actor = audit.Actor(ctx)
+	}
+	s.requests++
+	s.updates += len(req.GetUpdate())
+	if err != nil && s.failed == nil {
+		s.failed = err
+	}
+	s.mu.Unlock()
+
+	if first {
+		if aerr := s.log.RecordContext(ctx, "graphstore.write.start", s.target, nil, nil); aerr != nil {
+			log.ErrorContextf(ctx, "Recording GraphStore write: %v", aerr)
+		}
+	}
+	return err
+}
+
+// Close implements part of the Service interface.
+func (s *auditedStore) Close(ctx context.Context) error {
+	err := s.Service.Close(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.actor != "" {
+		details := map[string]any{"requests": s.requests, "updates": s.updates}
+		if aerr := s.log.RecordContext(audit.WithActor(ctx, s.actor), "graphstore.write", s.target, details, s.failed); aerr != nil {
+			log.ErrorContextf(ctx, "Recording GraphStore writes: %v", aerr)
+		}
+	}
+	return err
+}
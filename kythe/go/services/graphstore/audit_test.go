@@ -0,0 +1,81 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graphstore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"kythe.io/kythe/go/util/audit"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// writeStore is a Service that accepts writes of updates to nodes other than
+// "bad".
+type writeStore struct{ Service }
+
+func (writeStore) Write(_ context.Context, req *spb.WriteRequest) error {
+	if req.GetSource().GetSignature() == "bad" {
+		return errors.New("bad write")
+	}
+	return nil
+}
+
+func (writeStore) Close(context.Context) error { return nil }
+
+func TestAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := audit.Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := audit.WithActor(context.Background(), "alice")
+	gs := Audit(writeStore{}, l, "gs/leveldb")
+	update := &spb.WriteRequest_Update{FactName: "/kythe/node/kind"}
+	for _, sig := range []string{"a", "bad", "c"} {
+		gs.Write(ctx, &spb.WriteRequest{
+			Source: &spb.VName{Signature: sig},
+			Update: []*spb.WriteRequest_Update{update, update},
+		})
+	}
+	if err := gs.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	var events []*audit.Event
+	if err := audit.Read(path, nil, func(e *audit.Event) error {
+		events = append(events, e)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Audit events: got %v; want 2", events)
+	}
+	if e := events[0]; e.Action != "graphstore.write.start" || e.Actor != "alice" || e.Target != "gs/leveldb" {
+		t.Errorf("First event: got %v", e)
+	}
+	e := events[1]
+	if e.Action != "graphstore.write" || e.Actor != "alice" || e.Error != "bad write" ||
+		e.Details["requests"] != float64(3) || e.Details["updates"] != float64(6) {
+		t.Errorf("Summary event: got %v", e)
+	}
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graphstore
+
+import (
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	"kythe.io/kythe/go/util/datasize"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// BatchOptions control the sizing of the batches produced by a BatchSizer.
+// A zero field takes the default value given for it.
+type BatchOptions struct {
+	MinSize int // minimum batch size (default 16)
+	MaxSize int // maximum batch size (default 16384)
+
+	// The write latency the batch size is adjusted to achieve (default 50ms).
+	TargetLatency time.Duration
+
+	// If positive, batches shrink to the minimum size while the live heap
+	// exceeds this size, so that the writer does not hold large batches in
+	// memory while the process is under pressure.
+	MemoryLimit datasize.Size
+}
+
+// A BatchSizer adapts the size of write batches to the observed latency of a
+// Service.  A batch whose entries were written faster than the target latency
+// allows the next batches to grow; a slow batch shrinks them.  Batches cut
+// short, e.g. by a change of source, are counted together until they fill the
+// current size.  A BatchSizer is safe for concurrent use by multiple writers.
+type BatchSizer struct {
+	min, max int
+	target   time.Duration
+	limit    uint64
+
+	heap func() uint64 // reports the size of the live heap
+
+	mu       sync.Mutex
+	size     int
+	observed int
+
+	// The updates and latency of short batches not yet counted.
+	shortN       int
+	shortLatency time.Duration
+}
+
+// memoryCheckInterval is the number of observations between checks of the
+// size of the heap.
+const memoryCheckInterval = 64
+
+// NewBatchSizer returns a BatchSizer with the given options, which may be
+// nil.  Its initial size is the minimum batch size.
+func NewBatchSizer(opts *BatchOptions) *BatchSizer {
+	if opts == nil {
+		opts = new(BatchOptions)
+	}
+	s := &BatchSizer{
+		min:    opts.MinSize,
+		max:    opts.MaxSize,
+		target: opts.TargetLatency,
+		limit:  uint64(opts.MemoryLimit),
+		heap:   liveHeap,
+	}
+	if s.min <= 0 {
+		s.min = 16
+	}
+	if s.max <= 0 {
+		s.max = 16384
+	}
+	if s.max < s.min {
+		s.max = s.min
+	}
+	if s.target <= 0 {
+		s.target = 50 * time.Millisecond
+	}
+	s.size = s.min
+	return s
+}
+
+// Size returns the current maximum number of updates per batch.
+func (s *BatchSizer) Size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Observe records that a batch of n updates was written in the given time,
+// and adjusts the batch size accordingly.
+func (s *BatchSizer) Observe(n int, latency time.Duration) {
+	if n <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observed++
+	if s.limit > 0 && s.observed%memoryCheckInterval == 0 && s.heap() > s.limit {
+		s.size = s.min
+		return
+	}
+
+	// A batch smaller than the current size gives no evidence that a larger
+	// one would be fast, so short batches are counted together as though
+	// they were written as one.
+	if n < s.size {
+		s.shortN += n
+		s.shortLatency += latency
+		if s.shortN < s.size {
+			return
+		}
+		n, latency = s.shortN, s.shortLatency
+		s.shortN, s.shortLatency = 0, 0
+	}
+
+	// Estimate the number of updates that can be written in the target
+	// latency, and move halfway towards it.  Growth is limited to doubling.
+	ideal := s.max
+	if latency > 0 {
+		ideal = int(int64(n) * int64(s.target) / int64(latency))
+	}
+	next := (s.size + ideal) / 2
+	if next > 2*s.size {
+		next = 2 * s.size
+	}
+	s.size = clamp(next, s.min, s.max)
+}
+
+func clamp(n, lo, hi int) int {
+	if n < lo {
+		return lo
+	} else if n > hi {
+		return hi
+	}
+	return n
+}
+
+// liveHeap returns the number of bytes occupied by live and unswept objects
+// in the heap.
+func liveHeap() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+// AdaptiveBatchWrites returns a channel of WriteRequests for the given
+// entries, like BatchWrites, except that the maximum size of each request is
+// the size of s when the request is started.  Writers should report the
+// latency of each request to s.Observe.
+func AdaptiveBatchWrites(entries <-chan *spb.Entry, s *BatchSizer) <-chan *spb.WriteRequest {
+	return batchWrites(entries, s.Size)
+}
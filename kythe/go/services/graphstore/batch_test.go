@@ -0,0 +1,125 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graphstore
+
+import (
+	"testing"
+	"time"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestBatchSizer(t *testing.T) {
+	s := NewBatchSizer(&BatchOptions{MinSize: 10, MaxSize: 1000, TargetLatency: 100 * time.Millisecond})
+	if got := s.Size(); got != 10 {
+		t.Fatalf("Initial size: got %d, want 10", got)
+	}
+
+	// A fast backend grows batches, at most doubling each time, up to the
+	// maximum.
+	want := []int{20, 40, 80, 160, 320, 640, 1000, 1000}
+	for i, w := range want {
+		s.Observe(s.Size(), time.Millisecond)
+		if got := s.Size(); got != w {
+			t.Errorf("Size after fast write #%d: got %d, want %d", i+1, got, w)
+		}
+	}
+
+	// A short batch is no evidence that larger batches would be fast, but
+	// short batches that together fill the current size are.
+	s = NewBatchSizer(&BatchOptions{MinSize: 10, MaxSize: 1000, TargetLatency: 100 * time.Millisecond})
+	s.Observe(1, time.Microsecond)
+	if got := s.Size(); got != 10 {
+		t.Errorf("Size after short batch: got %d, want 10", got)
+	}
+	for i := 0; i < 8; i++ {
+		s.Observe(1, time.Microsecond)
+	}
+	if got := s.Size(); got != 10 {
+		t.Errorf("Size after 9 short batches: got %d, want 10", got)
+	}
+	s.Observe(1, time.Microsecond)
+	if got := s.Size(); got != 20 {
+		t.Errorf("Size after 10 short batches: got %d, want 20", got)
+	}
+
+	// A slow backend shrinks batches towards the size written in the target
+	// latency, but no further than the minimum.
+	s = NewBatchSizer(&BatchOptions{MinSize: 10, MaxSize: 1000, TargetLatency: 100 * time.Millisecond})
+	s.size = 1000
+	s.Observe(1000, time.Second) // 100 updates in the target
+	if got := s.Size(); got != 550 {
+		t.Errorf("Size after slow write: got %d, want 550", got)
+	}
+	for i := 0; i < 20; i++ {
+		s.Observe(s.Size(), time.Hour)
+	}
+	if got := s.Size(); got != 10 {
+		t.Errorf("Size after very slow writes: got %d, want 10", got)
+	}
+}
+
+func TestBatchSizerMemoryLimit(t *testing.T) {
+	s := NewBatchSizer(&BatchOptions{MinSize: 10, MaxSize: 1000, MemoryLimit: 1024})
+	var heap uint64
+	s.heap = func() uint64 { return heap }
+	s.size = 500
+	for i := 0; i < memoryCheckInterval; i++ {
+		s.Observe(s.Size(), time.Millisecond)
+	}
+	if got := s.Size(); got != 1000 {
+		t.Fatalf("Size below memory limit: got %d, want 1000", got)
+	}
+
+	heap = 2048
+	for i := 0; i < memoryCheckInterval; i++ {
+		s.Observe(s.Size(), time.Millisecond)
+	}
+	if got := s.Size(); got != 10 {
+		t.Errorf("Size above memory limit: got %d, want 10", got)
+	}
+}
+
+func TestAdaptiveBatchWrites(t *testing.T) {
+	src := &spb.VName{Signature: "a"}
+	entries := make(chan *spb.Entry)
+	go func() {
+		defer close(entries)
+		for i := 0; i < 50; i++ {
+			entries <- &spb.Entry{Source: src, FactName: "/f"}
+		}
+	}()
+
+	s := NewBatchSizer(&BatchOptions{MinSize: 4, MaxSize: 16})
+	var sizes []int
+	for req := range AdaptiveBatchWrites(entries, s) {
+		sizes = append(sizes, len(req.Update))
+		s.Observe(len(req.Update), time.Microsecond)
+	}
+	// Each request is sized when it is started, which may be before the
+	// previous request is observed, so growth can lag by one request.
+	var total, largest int
+	for _, n := range sizes {
+		total += n
+		if n > largest {
+			largest = n
+		}
+	}
+	if total != 50 || sizes[0] != 4 || largest != 16 {
+		t.Errorf("Batch sizes: got %v, want 50 updates starting at 4 and growing to 16", sizes)
+	}
+}
@@ -34,6 +34,29 @@ import (
 // error value from the callback.
 type EntryFunc func(*spb.Entry) error
 
+type entryReuseKey struct{}
+
+// WithEntryReuse returns a copy of ctx that permits (or forbids) a Service to
+// reuse the Entry messages it passes to an EntryFunc.  When reuse is
+// permitted, an Entry and its Source and Target VNames may be overwritten
+// with a later entry once the EntryFunc returns, so the EntryFunc must not
+// retain them; an entry that must be kept should be copied with proto.Clone.
+// The strings and FactValue held by an entry are never overwritten and may be
+// retained.
+//
+// Reuse avoids allocating messages for each entry of a large Read or Scan.
+// Services that do not support it ignore the setting.
+func WithEntryReuse(ctx context.Context, reuse bool) context.Context {
+	return context.WithValue(ctx, entryReuseKey{}, reuse)
+}
+
+// EntryReuse reports whether ctx permits a Service to reuse the Entry messages
+// it passes to an EntryFunc.
+func EntryReuse(ctx context.Context) bool {
+	reuse, _ := ctx.Value(entryReuseKey{}).(bool)
+	return reuse
+}
+
 // Service refers to an open Kythe graph store.
 type Service interface {
 	// Read calls f with each entry having the ReadRequest's given source
@@ -97,10 +120,17 @@ func EntryMatchesScan(req *spb.ScanRequest, entry *spb.Entry) bool {
 // Consecutive entries with the same Source will be collected in the same
 // WriteRequest, with each request containing up to maxSize updates.
 func BatchWrites(entries <-chan *spb.Entry, maxSize int) <-chan *spb.WriteRequest {
+	return batchWrites(entries, func() int { return maxSize })
+}
+
+// batchWrites implements BatchWrites, calling maxSize for the maximum size of
+// each request as it is started.
+func batchWrites(entries <-chan *spb.Entry, maxSize func() int) <-chan *spb.WriteRequest {
 	ch := make(chan *spb.WriteRequest)
 	go func() {
 		defer close(ch)
 		var req *spb.WriteRequest
+		var size int
 		for entry := range entries {
 			update := &spb.WriteRequest_Update{
 				EdgeKind:  entry.EdgeKind,
@@ -109,12 +139,13 @@ func BatchWrites(entries <-chan *spb.Entry, maxSize int) <-chan *spb.WriteReques
 				FactValue: entry.FactValue,
 			}
 
-			if req != nil && (!compare.VNamesEqual(req.Source, entry.Source) || len(req.Update) >= maxSize) {
+			if req != nil && (!compare.VNamesEqual(req.Source, entry.Source) || len(req.Update) >= size) {
 				ch <- req
 				req = nil
 			}
 
 			if req == nil {
+				size = maxSize()
 				req = &spb.WriteRequest{
 					Source: entry.Source,
 					Update: []*spb.WriteRequest_Update{update},
@@ -63,6 +63,8 @@ func New(stores ...graphstore.Service) graphstore.Service { return &proxyService
 
 // Read implements graphstore.Service and forwards the request to the proxied stores.
 func (p *proxyService) Read(ctx context.Context, req *spb.ReadRequest, f graphstore.EntryFunc) error {
+	// Entries are held while merging, so the proxied stores may not reuse them.
+	ctx = graphstore.WithEntryReuse(ctx, false)
 	return p.invoke(func(svc graphstore.Service, cb graphstore.EntryFunc) error {
 		return svc.Read(ctx, req, cb)
 	}, f)
@@ -71,6 +73,7 @@ func (p *proxyService) Read(ctx context.Context, req *spb.ReadRequest, f graphst
 // Scan implements part of graphstore.Service by forwarding the request to the
 // proxied stores.
 func (p *proxyService) Scan(ctx context.Context, req *spb.ScanRequest, f graphstore.EntryFunc) error {
+	ctx = graphstore.WithEntryReuse(ctx, false)
 	return p.invoke(func(svc graphstore.Service, cb graphstore.EntryFunc) error {
 		return svc.Scan(ctx, req, cb)
 	}, f)
@@ -0,0 +1,414 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package nav implements a compact code navigation API for code review and
+// source hosting frontends.
+//
+// Given a position in a file of a repository at a commit, the API returns
+// the symbol referenced there, its hover text, and the location of its
+// definition, in a small JSON shape that is stable across releases: fields
+// may be added to it, but are never renamed or removed.  Positions are given
+// as 1-based lines and 1-based byte columns.
+//
+// A request names its file either with query parameters,
+//
+//	GET /nav?repo=<repo>&commit=<commit>&path=<path>&line=<line>&col=<col>
+//
+// or in the style of a permalink,
+//
+//	GET /nav/<repo>/<commit>/<path>?line=<line>&col=<col>
+//
+// Repository names are mapped to Kythe corpora by the Service's Repos; an
+// unknown repository name is taken to be the name of a corpus.  If the index
+// holds the file at a commit other than the one requested, the reply is
+// marked stale.  A frontend may instead POST the text of the file at the
+// requested commit, in which case the indexed positions are patched to match
+// it.
+//
+// For code review, the symbols touched by the changes to a file are reported
+// with the counts of their cross-references across the index:
+//
+//	POST /review
+//	{"repo": <repo>, "commit": <commit>, "path": <path>, "diff": <unified diff>}
+//
+// The changed lines may be given as "hunks", a list of {"line", "end_line"}
+// ranges, instead of or as well as a diff.  See ReviewRequest and ReviewReply.
+package nav // import "kythe.io/kythe/go/services/nav"
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"kythe.io/kythe/go/services/web"
+	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/log"
+	"kythe.io/kythe/go/util/markedsource"
+	"kythe.io/kythe/go/util/md"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+// maxTextSize bounds the size of a file text posted with a request.
+const maxTextSize = 16 << 20
+
+// A Repo describes where the files of a repository are found in the index.
+type Repo struct {
+	Corpus string `json:"corpus"`
+	Root   string `json:"root,omitempty"`
+
+	// PathPrefix is the directory of the corpus holding the repository,
+	// if it is not the whole corpus.
+	PathPrefix string `json:"path_prefix,omitempty"`
+}
+
+// A Request is a position in a file of a repository at a commit.
+type Request struct {
+	Repo   string
+	Commit string // optional
+	Path   string
+	Line   int // 1-based
+	Col    int // 1-based, in bytes
+
+	// Text is the optional text of the file at Commit.  If set, positions
+	// are patched between it and the indexed text.
+	Text []byte
+}
+
+// A Reply is the answer to a Request.
+type Reply struct {
+	Repo          string  `json:"repo"`
+	Commit        string  `json:"commit,omitempty"`
+	IndexedCommit string  `json:"indexed_commit,omitempty"`
+	Stale         bool    `json:"stale,omitempty"` // the indexed commit differs from the requested one
+	Path          string  `json:"path"`
+	Line          int     `json:"line"`
+	Col           int     `json:"col"`
+	Symbol        *Symbol `json:"symbol,omitempty"` // nil if there is no symbol at the position
+}
+
+// A Symbol is the target of the reference at a position.
+type Symbol struct {
+	Ticket     string    `json:"ticket"`
+	Kind       string    `json:"kind,omitempty"`
+	Range      Range     `json:"range"` // the span of the reference
+	Hover      *Hover    `json:"hover,omitempty"`
+	Definition *Location `json:"definition,omitempty"`
+}
+
+// Hover is the text describing a symbol.
+type Hover struct {
+	Signature string `json:"signature,omitempty"`
+	Doc       string `json:"doc,omitempty"`
+}
+
+// A Range is a span of a file.  The end is exclusive.
+type Range struct {
+	Line    int `json:"line"`
+	Col     int `json:"col"`
+	EndLine int `json:"end_line"`
+	EndCol  int `json:"end_col"`
+}
+
+// A Location is a span of a file of a repository.
+type Location struct {
+	Repo   string `json:"repo"`
+	Commit string `json:"commit,omitempty"`
+	Path   string `json:"path"`
+	Range
+}
+
+// A Service answers navigation requests from an xrefs service.
+type Service struct {
+	XRefs xrefs.Service
+
+	// Repos maps repository names to their place in the index.
+	Repos map[string]*Repo
+}
+
+// repo returns the description of the named repository.
+func (s *Service) repo(name string) *Repo {
+	if r := s.Repos[name]; r != nil {
+		return r
+	}
+	return &Repo{Corpus: name}
+}
+
+// repoPath returns the name of the repository holding the file with the
+// given corpus, root and path, and the path of the file within it.
+func (s *Service) repoPath(corpus, root, path string) (string, string) {
+	var name, rel string
+	for n, r := range s.Repos {
+		if r.Corpus != corpus || r.Root != root {
+			continue
+		}
+		p, ok := trimDir(path, r.PathPrefix)
+		// Prefer the most specific repository, then the first name.
+		if ok && (name == "" || len(p) < len(rel) || len(p) == len(rel) && n < name) {
+			name, rel = n, p
+		}
+	}
+	if name == "" {
+		return corpus, path
+	}
+	return name, rel
+}
+
+func trimDir(path, dir string) (string, bool) {
+	dir = strings.Trim(dir, "/")
+	if dir == "" {
+		return path, true
+	}
+	if rest := strings.TrimPrefix(path, dir+"/"); rest != path {
+		return rest, true
+	}
+	return "", false
+}
+
+// ParsePermalink parses the repository, commit and path of a permalink of
+// the form <repo>/<commit>/<path>.  A repository name may contain slashes
+// if it is one of the Service's Repos; otherwise it is the first segment.
+func (s *Service) ParsePermalink(link string) (*Request, error) {
+	link = strings.Trim(link, "/")
+	repo, rest, _ := strings.Cut(link, "/")
+	for name := range s.Repos {
+		if r, ok := trimDir(link, name); ok && len(name) > len(repo) && strings.Contains(r, "/") {
+			repo, rest = name, r
+		}
+	}
+	commit, path, ok := strings.Cut(rest, "/")
+	if repo == "" || commit == "" || !ok || path == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "malformed permalink %q: want <repo>/<commit>/<path>", link)
+	}
+	return &Request{Repo: repo, Commit: commit, Path: path}, nil
+}
+
+// Navigate returns the symbol at the position of req.
+func (s *Service) Navigate(ctx context.Context, req *Request) (*Reply, error) {
+	if req.Repo == "" || req.Path == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing repo or path")
+	} else if req.Line < 1 || req.Col < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid position %d:%d", req.Line, req.Col)
+	}
+	r := s.repo(req.Repo)
+	file := &kytheuri.URI{Corpus: r.Corpus, Root: r.Root, Path: req.Path}
+	if r.PathPrefix != "" {
+		file.Path = strings.Trim(r.PathPrefix, "/") + "/" + req.Path
+	}
+
+	point := &cpb.Point{LineNumber: int32(req.Line), ColumnOffset: int32(req.Col - 1)}
+	decor, err := s.XRefs.Decorations(ctx, &xpb.DecorationsRequest{
+		Location: &xpb.Location{
+			Ticket: file.String(),
+			Kind:   xpb.Location_SPAN,
+			Span:   &cpb.Span{Start: point, End: point},
+		},
+		SpanKind:          xpb.DecorationsRequest_AROUND_SPAN,
+		DirtyBuffer:       req.Text,
+		References:        true,
+		TargetDefinitions: true,
+		Filter:            []string{facts.NodeKind},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &Reply{
+		Repo:          req.Repo,
+		Commit:        req.Commit,
+		IndexedCommit: decor.Revision,
+		Stale:         len(req.Text) == 0 && req.Commit != "" && decor.Revision != "" && !sameCommit(req.Commit, decor.Revision),
+		Path:          req.Path,
+		Line:          req.Line,
+		Col:           req.Col,
+	}
+	ref := Narrowest(decor.Reference, decor.GetLocation().GetSpan().GetStart().GetByteOffset())
+	if ref == nil {
+		return reply, nil
+	}
+	reply.Symbol = &Symbol{
+		Ticket: ref.TargetTicket,
+		Kind:   string(decor.Nodes[ref.TargetTicket].GetFacts()[facts.NodeKind]),
+		Range:  spanRange(ref.Span),
+	}
+	if def := decor.DefinitionLocations[ref.TargetDefinition]; def != nil {
+		reply.Symbol.Definition, err = s.location(def)
+		if err != nil {
+			return nil, err
+		}
+	}
+	reply.Symbol.Hover, err = FindHover(ctx, s.XRefs, ref.TargetTicket)
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// Narrowest returns the reference with the shortest span around offset,
+// preferring definitions to other references of the same length.
+func Narrowest(refs []*xpb.DecorationsReply_Reference, offset int32) *xpb.DecorationsReply_Reference {
+	var best *xpb.DecorationsReply_Reference
+	length := func(r *xpb.DecorationsReply_Reference) int32 {
+		return r.Span.GetEnd().GetByteOffset() - r.Span.GetStart().GetByteOffset()
+	}
+	for _, r := range refs {
+		if r.Span.GetStart().GetByteOffset() > offset || r.Span.GetEnd().GetByteOffset() <= offset {
+			continue
+		}
+		if best == nil || length(r) < length(best) || length(r) == length(best) && isDefinition(r) && !isDefinition(best) {
+			best = r
+		}
+	}
+	return best
+}
+
+func isDefinition(r *xpb.DecorationsReply_Reference) bool {
+	return strings.HasPrefix(r.Kind, "/kythe/edge/defines")
+}
+
+// location returns the location of the given definition anchor.
+func (s *Service) location(a *xpb.Anchor) (*Location, error) {
+	file, err := kytheuri.Parse(a.Parent)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "definition file %q: %v", a.Parent, err)
+	}
+	repo, path := s.repoPath(file.Corpus, file.Root, file.Path)
+	return &Location{
+		Repo:   repo,
+		Commit: a.Revision,
+		Path:   path,
+		Range:  spanRange(a.Span),
+	}, nil
+}
+
+// FindHover returns the signature and documentation of ticket, or nil if it
+// has neither.
+func FindHover(ctx context.Context, xs xrefs.Service, ticket string) (*Hover, error) {
+	docs, err := xs.Documentation(ctx, &xpb.DocumentationRequest{Ticket: []string{ticket}})
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, doc := range docs.Document {
+		h := &Hover{Doc: strings.TrimSpace(md.PlainText(doc.GetText().GetRawText()))}
+		if ms := doc.MarkedSource; ms != nil {
+			h.Signature = markedsource.RenderSignature(ms, markedsource.PlaintextContent, nil)
+		}
+		if h.Signature != "" || h.Doc != "" {
+			return h, nil
+		}
+	}
+	return nil, nil
+}
+
+func spanRange(s *cpb.Span) Range {
+	return Range{
+		Line:    int(s.GetStart().GetLineNumber()),
+		Col:     int(s.GetStart().GetColumnOffset()) + 1,
+		EndLine: int(s.GetEnd().GetLineNumber()),
+		EndCol:  int(s.GetEnd().GetColumnOffset()) + 1,
+	}
+}
+
+// sameCommit reports whether a and b name the same commit, allowing either
+// to be abbreviated.
+func sameCommit(a, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return a == b || len(a) >= 7 && strings.HasPrefix(b, a)
+}
+
+// ParseRequest returns the navigation request of an HTTP request, given as
+// query parameters or as a permalink following the /nav/ prefix.
+func (s *Service) ParseRequest(r *http.Request) (*Request, error) {
+	req := &Request{
+		Repo:   web.Arg(r, "repo"),
+		Commit: web.Arg(r, "commit"),
+		Path:   web.Arg(r, "path"),
+	}
+	if link := strings.TrimPrefix(r.URL.Path, "/nav/"); link != r.URL.Path {
+		var err error
+		if req, err = s.ParsePermalink(link); err != nil {
+			return nil, err
+		}
+	}
+	for _, arg := range []struct {
+		name string
+		val  *int
+	}{{"line", &req.Line}, {"col", &req.Col}} {
+		n, err := strconv.Atoi(web.Arg(r, arg.name))
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid %s: %v", arg.name, err)
+		}
+		*arg.val = n
+	}
+	if r.Method == http.MethodPost {
+		text, err := io.ReadAll(io.LimitReader(r.Body, maxTextSize+1))
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "reading file text: %v", err)
+		} else if len(text) > maxTextSize {
+			return nil, status.Errorf(codes.InvalidArgument, "file text exceeds %d bytes", maxTextSize)
+		}
+		req.Text = text
+	}
+	return req, nil
+}
+
+// httpStatus returns the HTTP status for an error of the Service.
+func httpStatus(err error) int { return web.HTTPStatus(err, http.StatusInternalServerError) }
+
+// RegisterHTTPHandlers registers JSON HTTP handlers with mux for the
+// navigation requests of the /nav and /nav/<permalink> paths, and for the
+// review requests of the /review path.
+func RegisterHTTPHandlers(ctx context.Context, s *Service, mux *http.ServeMux) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		defer func() {
+			log.InfoContextf(ctx, "nav.Navigate:\t%s", time.Since(start))
+		}()
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		req, err := s.ParseRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), httpStatus(err))
+			return
+		}
+		reply, err := s.Navigate(r.Context(), req)
+		if err != nil {
+			http.Error(w, err.Error(), httpStatus(err))
+			return
+		}
+		if err := web.WriteJSONResponse(w, r, reply); err != nil {
+			log.ErrorContextf(ctx, "Navigate error: %v", err)
+		}
+	}
+	mux.HandleFunc("/nav", handler)
+	mux.HandleFunc("/nav/", handler)
+	mux.HandleFunc("/review", reviewHandler(ctx, s))
+}
@@ -0,0 +1,263 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nav
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	"github.com/google/go-cmp/cmp"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+const (
+	fileTicket = "kythe://corpus?path=src/a.go"
+	defTicket  = "kythe://corpus?path=src/b.go"
+	funcTicket = "kythe://corpus?lang=go#f"
+	argTicket  = "kythe://corpus?lang=go#x"
+)
+
+func point(off, line, col int32) *cpb.Point {
+	return &cpb.Point{ByteOffset: off, LineNumber: line, ColumnOffset: col}
+}
+
+// fakeXRefs serves a file src/a.go, whose second line "\tf(x)" calls f with
+// x, at revision "abcdef0123".  f is defined in src/b.go.
+type fakeXRefs struct {
+	xrefs.Service
+	req *xpb.DecorationsRequest
+	ctx context.Context
+}
+
+func (f *fakeXRefs) Decorations(ctx context.Context, req *xpb.DecorationsRequest) (*xpb.DecorationsReply, error) {
+	f.req, f.ctx = req, ctx
+	if req.GetLocation().GetTicket() != fileTicket {
+		return nil, xrefs.ErrDecorationsNotFound
+	}
+	// The fake only serves line 2, which starts at offset 10.
+	start := req.Location.Span.Start
+	loc := &xpb.Location{
+		Ticket: fileTicket,
+		Kind:   xpb.Location_SPAN,
+		Span: &cpb.Span{
+			Start: point(10+start.ColumnOffset, 2, start.ColumnOffset),
+			End:   point(10+start.ColumnOffset, 2, start.ColumnOffset),
+		},
+	}
+	return &xpb.DecorationsReply{
+		Location: loc,
+		Revision: "abcdef0123",
+		Reference: []*xpb.DecorationsReply_Reference{{
+			TargetTicket:     funcTicket,
+			Kind:             "/kythe/edge/ref/call",
+			Span:             &cpb.Span{Start: point(11, 2, 1), End: point(15, 2, 5)},
+			TargetDefinition: "def",
+		}, {
+			TargetTicket:     funcTicket,
+			Kind:             "/kythe/edge/ref",
+			Span:             &cpb.Span{Start: point(11, 2, 1), End: point(12, 2, 2)},
+			TargetDefinition: "def",
+		}, {
+			TargetTicket: argTicket,
+			Kind:         "/kythe/edge/ref",
+			Span:         &cpb.Span{Start: point(13, 2, 3), End: point(14, 2, 4)},
+		}},
+		Nodes: map[string]*cpb.NodeInfo{
+			funcTicket: {Facts: map[string][]byte{facts.NodeKind: []byte("function")}},
+		},
+		DefinitionLocations: map[string]*xpb.Anchor{
+			"def": {
+				Parent:   defTicket,
+				Span:     &cpb.Span{Start: point(5, 1, 5), End: point(6, 1, 6)},
+				Revision: "0123456789",
+			},
+		},
+	}, nil
+}
+
+func (f *fakeXRefs) Documentation(_ context.Context, req *xpb.DocumentationRequest) (*xpb.DocumentationReply, error) {
+	if req.Ticket[0] != funcTicket {
+		return &xpb.DocumentationReply{}, nil
+	}
+	return &xpb.DocumentationReply{Document: []*xpb.DocumentationReply_Document{{
+		Ticket: funcTicket,
+		Text:   &xpb.Printable{RawText: "f does [nothing]."},
+		MarkedSource: &cpb.MarkedSource{
+			Kind: cpb.MarkedSource_BOX,
+			Child: []*cpb.MarkedSource{
+				{Kind: cpb.MarkedSource_IDENTIFIER, PreText: "f"},
+				{Kind: cpb.MarkedSource_PARAMETER, PreText: "(", PostText: ")"},
+			},
+		},
+	}}}, nil
+}
+
+func get(t *testing.T, s *Service, method, url, body string) (int, *Reply) {
+	t.Helper()
+	mux := http.NewServeMux()
+	RegisterHTTPHandlers(context.Background(), s, mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(method, url, strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		return rec.Code, nil
+	}
+	var reply Reply
+	if err := json.Unmarshal(rec.Body.Bytes(), &reply); err != nil {
+		t.Fatalf("Decoding %q: %v", rec.Body, err)
+	}
+	return rec.Code, &reply
+}
+
+func TestNavigate(t *testing.T) {
+	xs := new(fakeXRefs)
+	s := &Service{XRefs: xs, Repos: map[string]*Repo{
+		"github.com/org/repo": {Corpus: "corpus", PathPrefix: "src"},
+	}}
+	want := &Reply{
+		Repo:          "github.com/org/repo",
+		Commit:        "abcdef0",
+		IndexedCommit: "abcdef0123",
+		Path:          "a.go",
+		Line:          2,
+		Col:           2,
+		Symbol: &Symbol{
+			Ticket: funcTicket,
+			Kind:   "function",
+			Range:  Range{Line: 2, Col: 2, EndLine: 2, EndCol: 3},
+			Hover:  &Hover{Signature: "f()", Doc: "f does nothing."},
+			Definition: &Location{
+				Repo:   "github.com/org/repo",
+				Commit: "0123456789",
+				Path:   "b.go",
+				Range:  Range{Line: 1, Col: 6, EndLine: 1, EndCol: 7},
+			},
+		},
+	}
+
+	for _, url := range []string{
+		"/nav?repo=github.com/org/repo&commit=abcdef0&path=a.go&line=2&col=2",
+		"/nav/github.com/org/repo/abcdef0/a.go?line=2&col=2",
+	} {
+		code, got := get(t, s, "GET", url, "")
+		if code != http.StatusOK {
+			t.Fatalf("GET %s: status %d", url, code)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("GET %s (-want +got):\n%s", url, diff)
+		}
+	}
+	if got := xs.req.Location.Span.Start; got.LineNumber != 2 || got.ColumnOffset != 1 {
+		t.Errorf("Decorations requested at %v, want 2:1", got)
+	}
+
+	// The narrowest reference wins; x has no definition or documentation.
+	_, got := get(t, s, "GET", "/nav/github.com/org/repo/abcdef0/a.go?line=2&col=4", "")
+	if diff := cmp.Diff(&Symbol{
+		Ticket: argTicket,
+		Range:  Range{Line: 2, Col: 4, EndLine: 2, EndCol: 5},
+	}, got.Symbol); diff != "" {
+		t.Errorf("Symbol at 2:4 (-want +got):\n%s", diff)
+	}
+
+	// Outside every reference there is no symbol.
+	if _, got := get(t, s, "GET", "/nav/github.com/org/repo/abcdef0/a.go?line=2&col=1", ""); got.Symbol != nil {
+		t.Errorf("Symbol at 2:1: got %+v, want none", got.Symbol)
+	}
+}
+
+func TestNavigateCommit(t *testing.T) {
+	xs := new(fakeXRefs)
+	s := &Service{XRefs: xs}
+
+	// An unknown repository is a corpus of the same name.
+	_, got := get(t, s, "GET", "/nav/corpus/fedcba9/src/a.go?line=2&col=2", "")
+	if !got.Stale || got.IndexedCommit != "abcdef0123" {
+		t.Errorf("Other commit: got stale %v at %q, want stale at abcdef0123", got.Stale, got.IndexedCommit)
+	}
+	if got.Symbol.Definition.Repo != "corpus" || got.Symbol.Definition.Path != "src/b.go" {
+		t.Errorf("Definition: got %+v, want corpus src/b.go", got.Symbol.Definition)
+	}
+
+	// Posting the text at the requested commit patches it instead.
+	_, got = get(t, s, "POST", "/nav/corpus/fedcba9/src/a.go?line=2&col=2", "package a\n\tf(x)\n")
+	if got.Stale {
+		t.Error("Posted text: got stale reply")
+	}
+	if string(xs.req.DirtyBuffer) != "package a\n\tf(x)\n" {
+		t.Errorf("DirtyBuffer: got %q", xs.req.DirtyBuffer)
+	}
+}
+
+func TestNavigateRequestContext(t *testing.T) {
+	xs := new(fakeXRefs)
+	mux := http.NewServeMux()
+	RegisterHTTPHandlers(context.Background(), &Service{XRefs: xs}, mux)
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "request")
+	req := httptest.NewRequest("GET", "/nav/corpus/abcdef0/src/a.go?line=2&col=2", nil).WithContext(ctx)
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+	if xs.ctx == nil || xs.ctx.Value(key{}) != "request" {
+		t.Error("Decorations was not called with the request context")
+	}
+}
+
+func TestNavigateErrors(t *testing.T) {
+	s := &Service{XRefs: new(fakeXRefs)}
+	tests := []struct {
+		method, url string
+		want        int
+	}{
+		{"GET", "/nav?repo=corpus&path=src/a.go&line=2", http.StatusBadRequest},
+		{"GET", "/nav?repo=corpus&path=src/a.go&line=0&col=1", http.StatusBadRequest},
+		{"GET", "/nav/corpus/abcdef0?line=1&col=1", http.StatusBadRequest},
+		{"GET", "/nav/corpus/abcdef0/missing.go?line=1&col=1", http.StatusNotFound},
+		{"DELETE", "/nav/corpus/abcdef0/src/a.go?line=1&col=1", http.StatusMethodNotAllowed},
+	}
+	for _, test := range tests {
+		if code, _ := get(t, s, test.method, test.url, ""); code != test.want {
+			t.Errorf("%s %s: got status %d, want %d", test.method, test.url, code, test.want)
+		}
+	}
+}
+
+func TestSameCommit(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"abc", "abc", true},
+		{"abcdef0", "abcdef0123", true},
+		{"abcdef0123", "abcdef0", true},
+		{"abc", "abcdef0123", false},
+		{"abcdef1", "abcdef0123", false},
+	}
+	for _, test := range tests {
+		if got := sameCommit(test.a, test.b); got != test.want {
+			t.Errorf("sameCommit(%q, %q): got %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
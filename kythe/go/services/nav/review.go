@@ -0,0 +1,392 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nav
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kythe.io/kythe/go/services/web"
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/log"
+	"kythe.io/kythe/go/util/markedsource"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+// maxReviewSymbols bounds the number of symbols in a ReviewReply, since the
+// cross-references of each are counted separately.
+const maxReviewSymbols = 100
+
+// A Hunk is a range of changed lines in the new version of a file.  Lines are
+// 1-based and the range is inclusive.
+type Hunk struct {
+	Line    int `json:"line"`
+	EndLine int `json:"end_line"`
+}
+
+// A ReviewRequest names the changed lines of a file of a repository at a
+// commit, given as hunks, as a unified diff of the file, or both.
+type ReviewRequest struct {
+	Repo   string `json:"repo"`
+	Commit string `json:"commit,omitempty"`
+	Path   string `json:"path"`
+	Hunks  []Hunk `json:"hunks,omitempty"`
+	Diff   string `json:"diff,omitempty"`
+
+	// Text is the optional text of the file at Commit.  If set, positions
+	// are patched between it and the indexed text.
+	Text string `json:"text,omitempty"`
+}
+
+// A ReviewReply lists the symbols touched by the changes of a ReviewRequest.
+// Each symbol appears in only one list, the first that applies.
+type ReviewReply struct {
+	Repo          string `json:"repo"`
+	Commit        string `json:"commit,omitempty"`
+	IndexedCommit string `json:"indexed_commit,omitempty"`
+	Stale         bool   `json:"stale,omitempty"`
+	Path          string `json:"path"`
+
+	Defined    []*ReviewSymbol `json:"defined"`    // symbols defined in the changed lines
+	Enclosing  []*ReviewSymbol `json:"enclosing"`  // symbols whose bodies contain changed lines
+	Referenced []*ReviewSymbol `json:"referenced"` // symbols referenced in the changed lines
+
+	// Truncated is set if symbols were omitted to bound the cost of a reply.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// A ReviewSymbol is a symbol touched by a change, with counts of its
+// cross-references across the index.
+type ReviewSymbol struct {
+	Ticket     string    `json:"ticket"`
+	Kind       string    `json:"kind,omitempty"`
+	Signature  string    `json:"signature,omitempty"`
+	Ranges     []Range   `json:"ranges,omitempty"` // its spans in the changed lines, or its definition for an enclosing symbol
+	Definition *Location `json:"definition,omitempty"`
+	Counts     *Counts   `json:"counts,omitempty"`
+}
+
+// Counts are the numbers of cross-references of a symbol.
+type Counts struct {
+	Definitions  int64 `json:"definitions"`
+	Declarations int64 `json:"declarations"`
+	References   int64 `json:"references"`
+	Callers      int64 `json:"callers"`
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// ParseHunks returns the changed lines of the new version of a file given a
+// unified diff of it.  Added lines are changed, and a deletion changes the
+// lines on either side of it; context lines are not changed.
+func ParseHunks(diff string) ([]Hunk, error) {
+	var hunks []Hunk
+	add := func(line, end int) {
+		if line < 1 {
+			line = 1
+		}
+		if n := len(hunks); n > 0 && hunks[n-1].EndLine >= line-1 {
+			hunks[n-1].EndLine = max(hunks[n-1].EndLine, end)
+			return
+		}
+		hunks = append(hunks, Hunk{Line: line, EndLine: end})
+	}
+	var line, oldLeft, newLeft int
+	for _, text := range strings.Split(diff, "\n") {
+		if oldLeft <= 0 && newLeft <= 0 {
+			// Outside a hunk, skip everything but a hunk header.
+			if !strings.HasPrefix(text, "@@ ") {
+				continue
+			}
+			m := hunkHeader.FindStringSubmatch(text)
+			if m == nil {
+				return nil, status.Errorf(codes.InvalidArgument, "malformed hunk header %q", text)
+			}
+			oldLeft, newLeft = count(m[1]), count(m[3])
+			line, _ = strconv.Atoi(m[2])
+			if newLeft == 0 {
+				line++ // an empty range starts before the named line
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(text, "+"):
+			add(line, line)
+			line++
+			newLeft--
+		case strings.HasPrefix(text, "-"):
+			add(line-1, line)
+			oldLeft--
+		case strings.HasPrefix(text, `\`):
+			// "\ No newline at end of file"
+		default:
+			line++
+			oldLeft--
+			newLeft--
+		}
+	}
+	return hunks, nil
+}
+
+// count returns the line count of a hunk header field, which is 1 if omitted.
+func count(s string) int {
+	if s == "" {
+		return 1
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// overlaps reports whether the lines from line to end overlap any of hunks.
+func overlaps(hunks []Hunk, line, end int) bool {
+	for _, h := range hunks {
+		if line <= h.EndLine && end >= h.Line {
+			return true
+		}
+	}
+	return false
+}
+
+// Review returns the symbols defined, enclosing, or referenced in the
+// changed lines of req, with the counts of their cross-references.
+func (s *Service) Review(ctx context.Context, req *ReviewRequest) (*ReviewReply, error) {
+	if req.Repo == "" || req.Path == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing repo or path")
+	}
+	hunks := append([]Hunk(nil), req.Hunks...)
+	if req.Diff != "" {
+		hs, err := ParseHunks(req.Diff)
+		if err != nil {
+			return nil, err
+		}
+		hunks = append(hunks, hs...)
+	}
+	if len(hunks) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no changed lines given")
+	}
+	for _, h := range hunks {
+		if h.Line < 1 || h.EndLine < h.Line {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid hunk %d-%d", h.Line, h.EndLine)
+		}
+	}
+
+	r := s.repo(req.Repo)
+	file := &kytheuri.URI{Corpus: r.Corpus, Root: r.Root, Path: req.Path}
+	if r.PathPrefix != "" {
+		file.Path = strings.Trim(r.PathPrefix, "/") + "/" + req.Path
+	}
+	decor, err := s.XRefs.Decorations(ctx, &xpb.DecorationsRequest{
+		Location:          &xpb.Location{Ticket: file.String()},
+		DirtyBuffer:       []byte(req.Text),
+		References:        true,
+		TargetDefinitions: true,
+		SemanticScopes:    true,
+		Filter:            []string{facts.NodeKind},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &ReviewReply{
+		Repo:          req.Repo,
+		Commit:        req.Commit,
+		IndexedCommit: decor.Revision,
+		Stale:         req.Text == "" && req.Commit != "" && decor.Revision != "" && !sameCommit(req.Commit, decor.Revision),
+		Path:          req.Path,
+		Defined:       []*ReviewSymbol{},
+		Enclosing:     []*ReviewSymbol{},
+		Referenced:    []*ReviewSymbol{},
+	}
+
+	// Classify each symbol by its most significant role in the changes.
+	defined := make(map[string]*ReviewSymbol)
+	referenced := make(map[string]*ReviewSymbol)
+	enclosing := make(map[string]*ReviewSymbol)
+	defSpans := make(map[string]Range) // definition spans in the file, by target
+	refs := append([]*xpb.DecorationsReply_Reference(nil), decor.Reference...)
+	sort.SliceStable(refs, func(i, j int) bool {
+		return refs[i].Span.GetStart().GetByteOffset() < refs[j].Span.GetStart().GetByteOffset()
+	})
+	for _, ref := range refs {
+		rng := spanRange(ref.Span)
+		if _, ok := defSpans[ref.TargetTicket]; !ok && isDefinition(ref) {
+			defSpans[ref.TargetTicket] = rng
+		}
+		if !overlaps(hunks, rng.Line, rng.EndLine) {
+			continue
+		}
+		isDef := isDefinition(ref)
+		role := referenced
+		if isDef {
+			role = defined
+		}
+		sym := role[ref.TargetTicket]
+		if sym == nil {
+			sym = &ReviewSymbol{
+				Ticket: ref.TargetTicket,
+				Kind:   string(decor.Nodes[ref.TargetTicket].GetFacts()[facts.NodeKind]),
+			}
+			if def := decor.DefinitionLocations[ref.TargetDefinition]; def != nil && !isDef {
+				if sym.Definition, err = s.location(def); err != nil {
+					return nil, err
+				}
+			}
+			role[ref.TargetTicket] = sym
+			if isDef {
+				reply.Defined = append(reply.Defined, sym)
+			} else {
+				reply.Referenced = append(reply.Referenced, sym)
+			}
+		}
+		sym.Ranges = append(sym.Ranges, rng)
+		if scope := ref.SemanticScope; scope != "" && enclosing[scope] == nil {
+			sym := &ReviewSymbol{
+				Ticket: scope,
+				Kind:   string(decor.Nodes[scope].GetFacts()[facts.NodeKind]),
+			}
+			enclosing[scope] = sym
+			reply.Enclosing = append(reply.Enclosing, sym)
+		}
+	}
+	reply.Enclosing = without(reply.Enclosing, defined)
+	for _, sym := range reply.Enclosing {
+		if rng, ok := defSpans[sym.Ticket]; ok {
+			sym.Ranges = []Range{rng}
+		}
+	}
+	reply.Referenced = without(reply.Referenced, defined, enclosing)
+
+	// Bound the number of symbols, preferring those defined or modified.
+	limit := maxReviewSymbols
+	for _, syms := range []*[]*ReviewSymbol{&reply.Defined, &reply.Enclosing, &reply.Referenced} {
+		if len(*syms) > limit {
+			*syms = (*syms)[:limit]
+			reply.Truncated = true
+		}
+		limit -= len(*syms)
+	}
+
+	all := append(append(append([]*ReviewSymbol(nil), reply.Defined...), reply.Enclosing...), reply.Referenced...)
+	if err := s.annotate(ctx, all); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// without returns the symbols of syms not present in any of the given maps.
+func without(syms []*ReviewSymbol, exclude ...map[string]*ReviewSymbol) []*ReviewSymbol {
+	out := syms[:0]
+outer:
+	for _, sym := range syms {
+		for _, m := range exclude {
+			if m[sym.Ticket] != nil {
+				continue outer
+			}
+		}
+		out = append(out, sym)
+	}
+	return out
+}
+
+// annotate adds the signatures and cross-reference counts of syms.
+func (s *Service) annotate(ctx context.Context, syms []*ReviewSymbol) error {
+	if len(syms) == 0 {
+		return nil
+	}
+	bySym := make(map[string]*ReviewSymbol, len(syms))
+	tickets := make([]string, len(syms))
+	for i, sym := range syms {
+		bySym[sym.Ticket] = sym
+		tickets[i] = sym.Ticket
+	}
+	docs, err := s.XRefs.Documentation(ctx, &xpb.DocumentationRequest{Ticket: tickets})
+	if err != nil && status.Code(err) != codes.NotFound {
+		return err
+	}
+	for _, doc := range docs.GetDocument() {
+		if sym := bySym[doc.Ticket]; sym != nil && doc.MarkedSource != nil {
+			sym.Signature = markedsource.RenderSignature(doc.MarkedSource, markedsource.PlaintextContent, nil)
+		}
+	}
+
+	// The totals of a reply cover all its tickets, so each symbol is counted
+	// by a separate request.
+	for _, sym := range syms {
+		xr, err := s.XRefs.CrossReferences(ctx, &xpb.CrossReferencesRequest{
+			Ticket:          []string{sym.Ticket},
+			DefinitionKind:  xpb.CrossReferencesRequest_ALL_DEFINITIONS,
+			DeclarationKind: xpb.CrossReferencesRequest_ALL_DECLARATIONS,
+			ReferenceKind:   xpb.CrossReferencesRequest_ALL_REFERENCES,
+			CallerKind:      xpb.CrossReferencesRequest_DIRECT_CALLERS,
+			PageSize:        1,
+		})
+		if status.Code(err) == codes.NotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if t := xr.GetTotal(); t != nil {
+			sym.Counts = &Counts{
+				Definitions:  t.Definitions,
+				Declarations: t.Declarations,
+				References:   t.References,
+				Callers:      t.Callers,
+			}
+		}
+	}
+	return nil
+}
+
+// reviewHandler returns a JSON HTTP handler for review requests, which must be
+// POSTed as JSON.
+func reviewHandler(ctx context.Context, s *Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		defer func() {
+			log.InfoContextf(ctx, "nav.Review:\t%s", time.Since(start))
+		}()
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req ReviewRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, 2*maxTextSize)).Decode(&req); err != nil {
+			http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		reply, err := s.Review(r.Context(), &req)
+		if err != nil {
+			http.Error(w, err.Error(), httpStatus(err))
+			return
+		}
+		if err := web.WriteJSONResponse(w, r, reply); err != nil {
+			log.ErrorContextf(ctx, "Review error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nav
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	"github.com/google/go-cmp/cmp"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+const (
+	reviewFile = "kythe://corpus?path=src/r.go"
+	fTicket    = "kythe://corpus?lang=go#f"
+	gTicket    = "kythe://corpus?lang=go#g"
+	hTicket    = "kythe://corpus?lang=go#h"
+	xTicket    = "kythe://corpus?lang=go#x"
+)
+
+// reviewXRefs serves a file src/r.go with the lines
+//
+//	func f(x int) {
+//		g(x)
+//	}
+//	func h() {
+//		g(1)
+//	}
+//
+// where g is defined in src/b.go and has 847 callers.
+type reviewXRefs struct {
+	xrefs.Service
+	req *xpb.DecorationsRequest
+}
+
+func (f *reviewXRefs) Decorations(_ context.Context, req *xpb.DecorationsRequest) (*xpb.DecorationsReply, error) {
+	f.req = req
+	if req.GetLocation().GetTicket() != reviewFile {
+		return nil, xrefs.ErrDecorationsNotFound
+	}
+	ref := func(target, kind, scope string, start, line, col, length int32) *xpb.DecorationsReply_Reference {
+		r := &xpb.DecorationsReply_Reference{
+			TargetTicket:  target,
+			Kind:          kind,
+			Span:          &cpb.Span{Start: point(start, line, col), End: point(start+length, line, col+length)},
+			SemanticScope: scope,
+		}
+		if target == gTicket {
+			r.TargetDefinition = "gdef"
+		}
+		return r
+	}
+	return &xpb.DecorationsReply{
+		Location: req.Location,
+		Revision: "abcdef0123",
+		Reference: []*xpb.DecorationsReply_Reference{
+			ref(hTicket, "/kythe/edge/defines/binding", "", 39, 4, 5, 1),
+			ref(fTicket, "/kythe/edge/defines/binding", "", 5, 1, 5, 1),
+			ref(xTicket, "/kythe/edge/defines/binding", fTicket, 7, 1, 7, 1),
+			ref(gTicket, "/kythe/edge/ref/call", fTicket, 17, 2, 1, 4),
+			ref(xTicket, "/kythe/edge/ref", fTicket, 19, 2, 3, 1),
+			ref(gTicket, "/kythe/edge/ref/call", hTicket, 49, 5, 1, 4),
+		},
+		Nodes: map[string]*cpb.NodeInfo{
+			fTicket: {Facts: map[string][]byte{facts.NodeKind: []byte("function")}},
+			gTicket: {Facts: map[string][]byte{facts.NodeKind: []byte("function")}},
+			hTicket: {Facts: map[string][]byte{facts.NodeKind: []byte("function")}},
+			xTicket: {Facts: map[string][]byte{facts.NodeKind: []byte("variable")}},
+		},
+		DefinitionLocations: map[string]*xpb.Anchor{
+			"gdef": {
+				Parent:   "kythe://corpus?path=src/b.go",
+				Span:     &cpb.Span{Start: point(5, 1, 5), End: point(6, 1, 6)},
+				Revision: "0123456789",
+			},
+		},
+	}, nil
+}
+
+func (f *reviewXRefs) Documentation(_ context.Context, req *xpb.DocumentationRequest) (*xpb.DocumentationReply, error) {
+	reply := new(xpb.DocumentationReply)
+	for _, ticket := range req.Ticket {
+		if ticket == gTicket {
+			reply.Document = append(reply.Document, &xpb.DocumentationReply_Document{
+				Ticket: gTicket,
+				MarkedSource: &cpb.MarkedSource{
+					Kind: cpb.MarkedSource_BOX,
+					Child: []*cpb.MarkedSource{
+						{Kind: cpb.MarkedSource_IDENTIFIER, PreText: "g"},
+						{Kind: cpb.MarkedSource_PARAMETER, PreText: "(", PostText: ")"},
+					},
+				},
+			})
+		}
+	}
+	return reply, nil
+}
+
+func (f *reviewXRefs) CrossReferences(_ context.Context, req *xpb.CrossReferencesRequest) (*xpb.CrossReferencesReply, error) {
+	if len(req.Ticket) != 1 {
+		return nil, xrefs.ErrDecorationsNotFound
+	}
+	total := &xpb.CrossReferencesReply_Total{Definitions: 1, References: 1}
+	if req.Ticket[0] == gTicket {
+		total.Callers = 847
+		total.References = 850
+	}
+	return &xpb.CrossReferencesReply{Total: total}, nil
+}
+
+func review(t *testing.T, s *Service, body string) (int, *ReviewReply) {
+	t.Helper()
+	mux := http.NewServeMux()
+	RegisterHTTPHandlers(context.Background(), s, mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/review", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		return rec.Code, nil
+	}
+	var reply ReviewReply
+	if err := json.Unmarshal(rec.Body.Bytes(), &reply); err != nil {
+		t.Fatalf("Decoding %q: %v", rec.Body, err)
+	}
+	return rec.Code, &reply
+}
+
+func TestReview(t *testing.T) {
+	xs := new(reviewXRefs)
+	s := &Service{XRefs: xs, Repos: map[string]*Repo{
+		"github.com/org/repo": {Corpus: "corpus", PathPrefix: "src"},
+	}}
+	one := &Counts{Definitions: 1, References: 1}
+	g := &ReviewSymbol{
+		Ticket:    gTicket,
+		Kind:      "function",
+		Signature: "g()",
+		Ranges:    []Range{{Line: 2, Col: 2, EndLine: 2, EndCol: 6}},
+		Definition: &Location{
+			Repo:   "github.com/org/repo",
+			Commit: "0123456789",
+			Path:   "b.go",
+			Range:  Range{Line: 1, Col: 6, EndLine: 1, EndCol: 7},
+		},
+		Counts: &Counts{Definitions: 1, References: 850, Callers: 847},
+	}
+
+	// A change to the body of f touches f, g and x.
+	code, got := review(t, s, `{"repo": "github.com/org/repo", "commit": "abcdef0", "path": "r.go", "hunks": [{"line": 2, "end_line": 2}]}`)
+	if code != http.StatusOK {
+		t.Fatalf("Review: status %d", code)
+	}
+	want := &ReviewReply{
+		Repo:          "github.com/org/repo",
+		Commit:        "abcdef0",
+		IndexedCommit: "abcdef0123",
+		Path:          "r.go",
+		Defined:       []*ReviewSymbol{},
+		Enclosing: []*ReviewSymbol{{
+			Ticket: fTicket,
+			Kind:   "function",
+			Ranges: []Range{{Line: 1, Col: 6, EndLine: 1, EndCol: 7}},
+			Counts: one,
+		}},
+		Referenced: []*ReviewSymbol{g, {
+			Ticket: xTicket,
+			Kind:   "variable",
+			Ranges: []Range{{Line: 2, Col: 4, EndLine: 2, EndCol: 5}},
+			Counts: one,
+		}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Review of line 2 (-want +got):\n%s", diff)
+	}
+	if !xs.req.SemanticScopes || xs.req.Location.GetKind() != xpb.Location_FILE {
+		t.Errorf("Decorations request: got %v, want semantic scopes of the file", xs.req)
+	}
+
+	// A change to the signature of f defines f and x; f is not also enclosing.
+	_, got = review(t, s, `{"repo": "github.com/org/repo", "path": "r.go", "diff": "@@ -1,2 +1,2 @@\n-func f(y int) {\n+func f(x int) {\n \tg(x)\n"}`)
+	var defined []string
+	for _, sym := range got.Defined {
+		defined = append(defined, sym.Ticket)
+	}
+	if diff := cmp.Diff([]string{fTicket, xTicket}, defined); diff != "" {
+		t.Errorf("Defined symbols (-want +got):\n%s", diff)
+	}
+	if len(got.Enclosing) != 0 || len(got.Referenced) != 0 {
+		t.Errorf("Review of line 1: got enclosing %v, referenced %v; want none", got.Enclosing, got.Referenced)
+	}
+}
+
+func TestReviewErrors(t *testing.T) {
+	s := &Service{XRefs: new(reviewXRefs)}
+	for _, body := range []string{
+		`{"repo": "corpus", "path": "src/r.go"}`,
+		`{"repo": "corpus", "path": "src/r.go", "hunks": [{"line": 3, "end_line": 2}]}`,
+		`{"repo": "corpus", "path": "src/r.go", "diff": "@@ bogus @@"}`,
+		`{"repo": "corpus", "hunks": [{"line": 1, "end_line": 1}]}`,
+		`not json`,
+	} {
+		if code, _ := review(t, s, body); code != http.StatusBadRequest {
+			t.Errorf("Review(%s): got status %d, want %d", body, code, http.StatusBadRequest)
+		}
+	}
+	if code, _ := review(t, s, `{"repo": "corpus", "path": "missing.go", "hunks": [{"line": 1, "end_line": 1}]}`); code != http.StatusNotFound {
+		t.Errorf("Review of missing file: got status %d, want %d", code, http.StatusNotFound)
+	}
+}
+
+func TestParseHunks(t *testing.T) {
+	tests := []struct {
+		diff string
+		want []Hunk
+	}{
+		{"", nil},
+		{
+			"--- a/x.go\n+++ b/x.go\n@@ -1,3 +1,4 @@\n a\n-b\n+B\n+C\n d\n@@ -10,2 +11,0 @@\n-y\n-z\n",
+			[]Hunk{{1, 3}, {11, 12}},
+		},
+		// Removed and added lines may look like file headers.
+		{"@@ -1 +1 @@\n--- old\n+++ new\n", []Hunk{{1, 1}}},
+		{"@@ -5,2 +5,2 @@ func f() {\n \tx\n-\ty\n+\tz\n\\ No newline at end of file\n", []Hunk{{5, 6}}},
+	}
+	for _, test := range tests {
+		got, err := ParseHunks(test.diff)
+		if err != nil {
+			t.Errorf("ParseHunks(%q): %v", test.diff, err)
+		} else if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("ParseHunks(%q) (-want +got):\n%s", test.diff, diff)
+		}
+	}
+	if _, err := ParseHunks("@@ bogus @@"); err == nil {
+		t.Error("ParseHunks of malformed header: unexpectedly succeeded")
+	}
+}
@@ -0,0 +1,413 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package opengrok implements a subset of the OpenGrok REST API on top of
+// the Kythe services, so that frontends written against OpenGrok can use a
+// Kythe index without change.
+//
+// The following methods of the OpenGrok v1 API are served:
+//
+//	GET /api/v1/projects
+//	  The names of the projects; each Kythe corpus is a project.
+//	GET /api/v1/search?def=<name>&symbol=<name>&path=<substring>&projects=<names>&maxresults=<n>&start=<n>
+//	  The definitions of, or references to, the named symbol, grouped by
+//	  file.  Names are Kythe identifiers, such as qualified names.  Full-text,
+//	  history and file type searches are not supported.
+//	GET /api/v1/file/content?path=/<project>/<path>
+//	  The text of a file.
+//	GET /api/v1/file/defs?path=/<project>/<path>
+//	  The definitions in a file.
+//
+// OpenGrok has no notion of a Kythe root, so files are named by their corpus
+// and path alone, and only files with an empty root can be opened.
+package opengrok // import "kythe.io/kythe/go/services/opengrok"
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kythe.io/kythe/go/services/filetree"
+	"kythe.io/kythe/go/services/web"
+	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/serving/identifiers"
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/log"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+	ipb "kythe.io/kythe/proto/identifier_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+// defaultMaxResults is the number of files in a page of search results if a
+// request does not give maxresults; it matches OpenGrok's default.
+const defaultMaxResults = 1000
+
+// A Server answers OpenGrok API requests from the Kythe services.
+type Server struct {
+	XRefs       xrefs.Service
+	Identifiers identifiers.Service
+	FileTree    filetree.Service
+}
+
+// SearchResult is a matching line of a file.
+type SearchResult struct {
+	Line       string  `json:"line"` // HTML, with the match in bold
+	LineNumber string  `json:"lineNumber"`
+	Tag        *string `json:"tag"`
+}
+
+// SearchReply is the reply to a search.
+type SearchReply struct {
+	Time          int64                      `json:"time"` // in milliseconds
+	ResultCount   int                        `json:"resultCount"`
+	StartDocument int                        `json:"startDocument"`
+	EndDocument   int                        `json:"endDocument"`
+	Results       map[string][]*SearchResult `json:"results"`
+}
+
+// Definition is a definition within a file.
+type Definition struct {
+	Type      string  `json:"type"`
+	Signature *string `json:"signature"`
+	Text      string  `json:"text"` // the line holding the definition
+	Symbol    string  `json:"symbol"`
+	LineStart int     `json:"lineStart"` // 0-based column of the symbol
+	LineEnd   int     `json:"lineEnd"`
+	Line      int     `json:"line"` // 1-based
+}
+
+// Projects returns the names of the projects.
+func (s *Server) Projects(ctx context.Context) ([]string, error) {
+	roots, err := s.FileTree.CorpusRoots(ctx, &ftpb.CorpusRootsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, c := range roots.Corpus {
+		names = append(names, c.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// A SearchRequest holds the supported parameters of a search.
+type SearchRequest struct {
+	Def, Symbol string   // the name to search for; exactly one is set
+	Path        string   // if set, a substring of the matching files
+	Projects    []string // if set, the projects to search
+	MaxResults  int      // maximum number of files to return
+	Start       int      // index of the first file to return
+}
+
+// Search returns the definitions of, or references to, the symbol of req.
+func (s *Server) Search(ctx context.Context, req *SearchRequest) (*SearchReply, error) {
+	start := time.Now()
+	name := req.Def + req.Symbol
+	if name == "" || req.Def != "" && req.Symbol != "" {
+		return nil, status.Error(codes.InvalidArgument, "exactly one of def or symbol is required")
+	}
+	found, err := s.Identifiers.Find(ctx, &ipb.FindRequest{
+		Identifier:         name,
+		Corpus:             req.Projects,
+		PickCanonicalNodes: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	kinds := make(map[string]string)
+	var tickets []string
+	for _, m := range found.Matches {
+		tickets = append(tickets, m.Ticket)
+		kinds[m.Ticket] = m.NodeKind
+	}
+
+	results := make(map[string][]*SearchResult)
+	if len(tickets) > 0 {
+		xreq := &xpb.CrossReferencesRequest{
+			Ticket:         tickets,
+			DefinitionKind: xpb.CrossReferencesRequest_BINDING_DEFINITIONS,
+			Snippets:       xpb.SnippetsKind_DEFAULT,
+		}
+		if req.Symbol != "" {
+			xreq.ReferenceKind = xpb.CrossReferencesRequest_ALL_REFERENCES
+		}
+		for {
+			reply, err := s.XRefs.CrossReferences(ctx, xreq)
+			if err != nil {
+				return nil, err
+			}
+			for _, set := range reply.CrossReferences {
+				var tag *string
+				if req.Def != "" {
+					kind := kinds[set.Ticket]
+					tag = &kind
+				}
+				for _, a := range append(set.Definition, set.Reference...) {
+					file, ok := filePath(a.Anchor.GetParent())
+					if !ok || req.Path != "" && !strings.Contains(file, req.Path) {
+						continue
+					}
+					results[file] = append(results[file], &SearchResult{
+						Line:       highlight(a.Anchor),
+						LineNumber: strconv.Itoa(int(a.Anchor.GetSpan().GetStart().GetLineNumber())),
+						Tag:        tag,
+					})
+				}
+			}
+			if reply.NextPageToken == "" {
+				break
+			}
+			xreq.PageToken = reply.NextPageToken
+		}
+	}
+
+	files := make([]string, 0, len(results))
+	for file, lines := range results {
+		files = append(files, file)
+		results[file] = uniqueLines(lines)
+	}
+	sort.Strings(files)
+	reply := &SearchReply{
+		ResultCount:   len(files),
+		StartDocument: req.Start,
+		Results:       make(map[string][]*SearchResult),
+	}
+	end := req.Start + req.MaxResults
+	if end > len(files) {
+		end = len(files)
+	}
+	for i := req.Start; i < end; i++ {
+		reply.Results[files[i]] = results[files[i]]
+	}
+	reply.EndDocument = end - 1
+	reply.Time = time.Since(start).Milliseconds()
+	return reply, nil
+}
+
+// uniqueLines sorts lines by line number and removes duplicate lines.
+func uniqueLines(lines []*SearchResult) []*SearchResult {
+	sort.SliceStable(lines, func(i, j int) bool {
+		a, _ := strconv.Atoi(lines[i].LineNumber)
+		b, _ := strconv.Atoi(lines[j].LineNumber)
+		return a < b
+	})
+	out := lines[:0]
+	for i, l := range lines {
+		if i == 0 || l.LineNumber != lines[i-1].LineNumber {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// highlight returns the snippet of a as HTML, with a's span in bold.
+func highlight(a *xpb.Anchor) string {
+	snip := a.Snippet
+	base := a.GetSnippetSpan().GetStart().GetByteOffset()
+	start := int(a.GetSpan().GetStart().GetByteOffset() - base)
+	end := int(a.GetSpan().GetEnd().GetByteOffset() - base)
+	if a.SnippetSpan == nil || start < 0 || start > end || end > len(snip) {
+		return html.EscapeString(snip)
+	}
+	return html.EscapeString(snip[:start]) + "<b>" + html.EscapeString(snip[start:end]) + "</b>" + html.EscapeString(snip[end:])
+}
+
+// filePath returns the OpenGrok path of the file with the given ticket.
+func filePath(ticket string) (string, bool) {
+	u, err := kytheuri.Parse(ticket)
+	if err != nil {
+		return "", false
+	}
+	return "/" + u.Corpus + "/" + u.Path, true
+}
+
+// fileTicket returns the ticket of the file with the given OpenGrok path.
+func fileTicket(path string) (string, error) {
+	corpus, rest, ok := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if !ok || corpus == "" || rest == "" {
+		return "", status.Errorf(codes.InvalidArgument, "invalid path %q: want /<project>/<path>", path)
+	}
+	return (&kytheuri.URI{Corpus: corpus, Path: rest}).String(), nil
+}
+
+// FileContent returns the text of the file with the given OpenGrok path.
+func (s *Server) FileContent(ctx context.Context, path string) ([]byte, error) {
+	ticket, err := fileTicket(path)
+	if err != nil {
+		return nil, err
+	}
+	decor, err := s.XRefs.Decorations(ctx, &xpb.DecorationsRequest{
+		Location:   &xpb.Location{Ticket: ticket},
+		SourceText: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decor.SourceText, nil
+}
+
+// FileDefinitions returns the definitions in the file with the given
+// OpenGrok path, in order of position.
+func (s *Server) FileDefinitions(ctx context.Context, path string) ([]*Definition, error) {
+	ticket, err := fileTicket(path)
+	if err != nil {
+		return nil, err
+	}
+	decor, err := s.XRefs.Decorations(ctx, &xpb.DecorationsRequest{
+		Location:   &xpb.Location{Ticket: ticket},
+		SourceText: true,
+		References: true,
+		Filter:     []string{facts.NodeKind, facts.Subkind},
+	})
+	if err != nil {
+		return nil, err
+	}
+	text := decor.SourceText
+	lines := strings.SplitAfter(string(text), "\n")
+	defs := []*Definition{}
+	for _, r := range decor.Reference {
+		if r.Kind != edges.DefinesBinding {
+			continue
+		}
+		start, end := r.Span.GetStart(), r.Span.GetEnd()
+		if end.GetByteOffset() > int32(len(text)) || start.GetLineNumber() < 1 || int(start.GetLineNumber()) > len(lines) {
+			continue
+		}
+		info := decor.Nodes[r.TargetTicket].GetFacts()
+		kind := string(info[facts.Subkind])
+		if kind == "" {
+			kind = string(info[facts.NodeKind])
+		}
+		lineEnd := int(start.GetColumnOffset() + end.GetByteOffset() - start.GetByteOffset())
+		defs = append(defs, &Definition{
+			Type:      kind,
+			Text:      strings.TrimRight(lines[start.GetLineNumber()-1], "\r\n"),
+			Symbol:    string(text[start.GetByteOffset():end.GetByteOffset()]),
+			LineStart: int(start.GetColumnOffset()),
+			LineEnd:   lineEnd,
+			Line:      int(start.GetLineNumber()),
+		})
+	}
+	sort.SliceStable(defs, func(i, j int) bool {
+		if defs[i].Line != defs[j].Line {
+			return defs[i].Line < defs[j].Line
+		}
+		return defs[i].LineStart < defs[j].LineStart
+	})
+	return defs, nil
+}
+
+// httpStatus returns the HTTP status for an error of the Server.
+func httpStatus(err error) int { return web.HTTPStatus(err, http.StatusInternalServerError) }
+
+// intArg returns the value of the named integer query parameter, or def if
+// it is not set.
+func intArg(r *http.Request, name string, def int) (int, error) {
+	arg := web.Arg(r, name)
+	if arg == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid %s %q", name, arg)
+	}
+	return n, nil
+}
+
+// parseSearch returns the search request of r.
+func parseSearch(r *http.Request) (*SearchRequest, error) {
+	for _, unsupported := range []string{"full", "hist", "type"} {
+		if web.Arg(r, unsupported) != "" {
+			return nil, status.Errorf(codes.Unimplemented, "%s search is not supported", unsupported)
+		}
+	}
+	req := &SearchRequest{
+		Def:    web.Arg(r, "def"),
+		Symbol: web.Arg(r, "symbol"),
+		Path:   web.Arg(r, "path"),
+	}
+	for _, p := range r.URL.Query()["projects"] {
+		for _, name := range strings.Split(p, ",") {
+			if name != "" {
+				req.Projects = append(req.Projects, name)
+			}
+		}
+	}
+	var err error
+	if req.MaxResults, err = intArg(r, "maxresults", defaultMaxResults); err != nil {
+		return nil, err
+	}
+	if req.Start, err = intArg(r, "start", 0); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// RegisterHTTPHandlers registers the OpenGrok API methods of s with mux.
+func RegisterHTTPHandlers(ctx context.Context, s *Server, mux *http.ServeMux) {
+	handle := func(path string, f func(r *http.Request) (any, error)) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			defer func() {
+				log.InfoContextf(ctx, "opengrok %s:\t%s", path, time.Since(start))
+			}()
+			if r.Method != http.MethodGet {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			reply, err := f(r)
+			if err != nil {
+				http.Error(w, err.Error(), httpStatus(err))
+				return
+			}
+			if text, ok := reply.([]byte); ok {
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+				_, err = w.Write(text)
+			} else {
+				err = web.WriteJSONResponse(w, r, reply)
+			}
+			if err != nil {
+				log.ErrorContextf(ctx, "OpenGrok %s error: %v", path, err)
+			}
+		})
+	}
+	handle("/api/v1/projects", func(r *http.Request) (any, error) {
+		return s.Projects(r.Context())
+	})
+	handle("/api/v1/search", func(r *http.Request) (any, error) {
+		req, err := parseSearch(r)
+		if err != nil {
+			return nil, err
+		}
+		return s.Search(r.Context(), req)
+	})
+	handle("/api/v1/file/content", func(r *http.Request) (any, error) {
+		return s.FileContent(r.Context(), web.Arg(r, "path"))
+	})
+	handle("/api/v1/file/defs", func(r *http.Request) (any, error) {
+		return s.FileDefinitions(r.Context(), web.Arg(r, "path"))
+	})
+}
@@ -0,0 +1,213 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opengrok
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"kythe.io/kythe/go/services/filetree"
+	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/serving/identifiers"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	"github.com/google/go-cmp/cmp"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+	ipb "kythe.io/kythe/proto/identifier_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+const (
+	mainFile   = "kythe://proj?path=src/main.c"
+	mainTicket = "kythe://proj?lang=c#main"
+	fileText   = "int x;\nint main() {\n  return main();\n}\n"
+)
+
+func span(start, end, line, col int32) *cpb.Span {
+	return &cpb.Span{
+		Start: &cpb.Point{ByteOffset: start, LineNumber: line, ColumnOffset: col},
+		End:   &cpb.Point{ByteOffset: end, LineNumber: line, ColumnOffset: col + end - start},
+	}
+}
+
+type (
+	fakeXRefs       struct{ xrefs.Service }
+	fakeIdentifiers struct{ identifiers.Service }
+	fakeFileTree    struct{ filetree.Service }
+)
+
+func (fakeFileTree) CorpusRoots(context.Context, *ftpb.CorpusRootsRequest) (*ftpb.CorpusRootsReply, error) {
+	return &ftpb.CorpusRootsReply{Corpus: []*ftpb.CorpusRootsReply_Corpus{{Name: "proj"}, {Name: "lib"}}}, nil
+}
+
+func (fakeIdentifiers) Find(_ context.Context, req *ipb.FindRequest) (*ipb.FindReply, error) {
+	if req.Identifier != "main" {
+		return &ipb.FindReply{}, nil
+	}
+	return &ipb.FindReply{Matches: []*ipb.FindReply_Match{{Ticket: mainTicket, NodeKind: "function"}}}, nil
+}
+
+func (fakeXRefs) CrossReferences(_ context.Context, req *xpb.CrossReferencesRequest) (*xpb.CrossReferencesReply, error) {
+	def := &xpb.Anchor{
+		Parent:      mainFile,
+		Span:        span(11, 15, 2, 4),
+		Snippet:     "int main() {",
+		SnippetSpan: span(7, 19, 2, 0),
+	}
+	ref := &xpb.Anchor{
+		Parent:      mainFile,
+		Span:        span(29, 33, 3, 9),
+		Snippet:     "  return main();",
+		SnippetSpan: span(20, 36, 3, 0),
+	}
+	// The reference is returned on a second page.
+	set := &xpb.CrossReferencesReply_CrossReferenceSet{Ticket: mainTicket}
+	reply := &xpb.CrossReferencesReply{CrossReferences: map[string]*xpb.CrossReferencesReply_CrossReferenceSet{mainTicket: set}}
+	if req.PageToken == "" {
+		set.Definition = []*xpb.CrossReferencesReply_RelatedAnchor{{Anchor: def}}
+		if req.ReferenceKind != xpb.CrossReferencesRequest_NO_REFERENCES {
+			reply.NextPageToken = "2"
+		}
+	} else {
+		set.Reference = []*xpb.CrossReferencesReply_RelatedAnchor{{Anchor: ref}}
+	}
+	return reply, nil
+}
+
+func (fakeXRefs) Decorations(_ context.Context, req *xpb.DecorationsRequest) (*xpb.DecorationsReply, error) {
+	if req.Location.Ticket != mainFile {
+		return nil, xrefs.ErrDecorationsNotFound
+	}
+	return &xpb.DecorationsReply{
+		SourceText: []byte(fileText),
+		Reference: []*xpb.DecorationsReply_Reference{{
+			TargetTicket: mainTicket,
+			Kind:         edges.Ref,
+			Span:         span(29, 33, 3, 9),
+		}, {
+			TargetTicket: mainTicket,
+			Kind:         edges.DefinesBinding,
+			Span:         span(11, 15, 2, 4),
+		}, {
+			TargetTicket: "kythe://proj?lang=c#x",
+			Kind:         edges.DefinesBinding,
+			Span:         span(4, 5, 1, 4),
+		}},
+		Nodes: map[string]*cpb.NodeInfo{
+			mainTicket:              {Facts: map[string][]byte{facts.NodeKind: []byte("function")}},
+			"kythe://proj?lang=c#x": {Facts: map[string][]byte{facts.NodeKind: []byte("variable")}},
+		},
+	}, nil
+}
+
+func get(t *testing.T, url string) (int, []byte) {
+	t.Helper()
+	mux := http.NewServeMux()
+	s := &Server{XRefs: fakeXRefs{}, Identifiers: fakeIdentifiers{}, FileTree: fakeFileTree{}}
+	RegisterHTTPHandlers(context.Background(), s, mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+	return rec.Code, rec.Body.Bytes()
+}
+
+func getJSON(t *testing.T, url string, v any) {
+	t.Helper()
+	code, body := get(t, url)
+	if code != http.StatusOK {
+		t.Fatalf("GET %s: status %d: %s", url, code, body)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		t.Fatalf("GET %s: decoding %q: %v", url, body, err)
+	}
+}
+
+func TestProjects(t *testing.T) {
+	var got []string
+	getJSON(t, "/api/v1/projects", &got)
+	if diff := cmp.Diff([]string{"lib", "proj"}, got); diff != "" {
+		t.Errorf("Projects (-want +got):\n%s", diff)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	function := "function"
+	tests := []struct {
+		url  string
+		want map[string][]*SearchResult
+	}{
+		{"/api/v1/search?def=main", map[string][]*SearchResult{
+			"/proj/src/main.c": {{Line: "int <b>main</b>() {", LineNumber: "2", Tag: &function}},
+		}},
+		{"/api/v1/search?symbol=main&path=main.c", map[string][]*SearchResult{
+			"/proj/src/main.c": {
+				{Line: "int <b>main</b>() {", LineNumber: "2"},
+				{Line: "  return <b>main</b>();", LineNumber: "3"},
+			},
+		}},
+		{"/api/v1/search?symbol=main&path=other", map[string][]*SearchResult{}},
+		{"/api/v1/search?def=main&start=1", map[string][]*SearchResult{}},
+		{"/api/v1/search?def=missing", map[string][]*SearchResult{}},
+	}
+	for _, test := range tests {
+		var got SearchReply
+		getJSON(t, test.url, &got)
+		if diff := cmp.Diff(test.want, got.Results); diff != "" {
+			t.Errorf("GET %s (-want +got):\n%s", test.url, diff)
+		}
+	}
+
+	var got SearchReply
+	getJSON(t, "/api/v1/search?def=main&maxresults=0", &got)
+	if got.ResultCount != 1 || len(got.Results) != 0 || got.EndDocument != -1 {
+		t.Errorf("Search with maxresults=0: got %+v", got)
+	}
+
+	for url, want := range map[string]int{
+		"/api/v1/search":                            http.StatusBadRequest,
+		"/api/v1/search?def=main&symbol=main":       http.StatusBadRequest,
+		"/api/v1/search?def=main&maxresults=x":      http.StatusBadRequest,
+		"/api/v1/search?full=main":                  http.StatusNotImplemented,
+		"/api/v1/file/content?path=/proj/missing.c": http.StatusNotFound,
+		"/api/v1/file/defs?path=proj":               http.StatusBadRequest,
+	} {
+		if code, _ := get(t, url); code != want {
+			t.Errorf("GET %s: status %d, want %d", url, code, want)
+		}
+	}
+}
+
+func TestFiles(t *testing.T) {
+	if code, body := get(t, "/api/v1/file/content?path=/proj/src/main.c"); code != http.StatusOK || string(body) != fileText {
+		t.Errorf("File content: got %d %q, want %q", code, body, fileText)
+	}
+
+	var got []*Definition
+	getJSON(t, "/api/v1/file/defs?path=/proj/src/main.c", &got)
+	want := []*Definition{
+		{Type: "variable", Text: "int x;", Symbol: "x", LineStart: 4, LineEnd: 5, Line: 1},
+		{Type: "function", Text: "int main() {", Symbol: "main", LineStart: 4, LineEnd: 8, Line: 2},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("File definitions (-want +got):\n%s", diff)
+	}
+}
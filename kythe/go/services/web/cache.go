@@ -0,0 +1,119 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// A Cache holds the replies to recent calls made by a Client, so that
+// repeated requests for the same directories and nodes are not sent to the
+// server again.  Replies are kept for a fixed time; a stale reply that came
+// with an ETag is revalidated by a conditional request rather than fetched
+// again.  The least recently used replies are evicted once the cache is
+// full.  A Cache is safe for concurrent use, and may be shared by Clients.
+type Cache struct {
+	maxEntries int
+	ttl        time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	lru     *list.List // of *cacheEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+// NewCache returns a Cache holding at most maxEntries replies, each for at
+// most ttl before it is revalidated.  If ttl ≤ 0, replies with an ETag are
+// revalidated on every call and other replies are not cached.
+func NewCache(maxEntries int, ttl time.Duration) *Cache {
+	return &Cache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		now:        time.Now,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Len returns the number of replies held by c.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// cacheKey returns a key identifying a call of method on server with req.
+func cacheKey(server, method string, req proto.Message) (string, error) {
+	rec, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling %T: %v", req, err)
+	}
+	return fmt.Sprintf("%s\x00%s\x00%T\x00%s", server, method, req, rec), nil
+}
+
+// lookup returns the entry for key, if any, and whether it is still fresh.
+func (c *Cache) lookup(key string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elt, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elt)
+	e := elt.Value.(*cacheEntry)
+	return e, c.now().Before(e.expires)
+}
+
+// store adds or replaces the entry for e.key, unless it can be neither
+// reused nor revalidated.
+func (c *Cache) store(e *cacheEntry) {
+	e.expires = c.now().Add(c.ttl)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elt, ok := c.entries[e.key]; ok {
+		c.lru.Remove(elt)
+		delete(c.entries, e.key)
+	}
+	if c.ttl <= 0 && e.etag == "" {
+		return
+	}
+	c.entries[e.key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.maxEntries {
+		old := c.lru.Remove(c.lru.Back()).(*cacheEntry)
+		delete(c.entries, old.key)
+	}
+}
+
+func unmarshalReply(rec []byte, reply proto.Message) error {
+	if err := protojson.Unmarshal(rec, reply); err != nil {
+		return fmt.Errorf("error unmarshaling %T: %v", reply, err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// echoServer replies to each request with its VName, reporting the number
+// of requests served and how many of them were answered with 304.
+func echoServer(t *testing.T) (*httptest.Server, *int32, *int32) {
+	var calls, unmodified int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		var req spb.VName
+		if err := ReadJSONBody(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rec := &recorder{ResponseWriter: w}
+		if err := WriteResponse(rec, r, &req); err != nil {
+			t.Errorf("WriteResponse: %v", err)
+		}
+		if rec.code == http.StatusNotModified {
+			atomic.AddInt32(&unmodified, 1)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls, &unmodified
+}
+
+type recorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *recorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func TestCache(t *testing.T) {
+	srv, calls, unmodified := echoServer(t)
+	now := time.Unix(0, 0)
+	c := NewCache(2, time.Minute)
+	c.now = func() time.Time { return now }
+	client := NewClient(&Options{Cache: c})
+
+	call := func(path string) {
+		t.Helper()
+		var reply spb.VName
+		if err := client.Call(srv.URL, "echo", &spb.VName{Path: path}, &reply); err != nil {
+			t.Fatalf("Call(%q): %v", path, err)
+		} else if reply.Path != path {
+			t.Fatalf("Call(%q): got reply %v", path, &reply)
+		}
+	}
+	expect := func(wantCalls, wantUnmodified int32) {
+		t.Helper()
+		if got := atomic.LoadInt32(calls); got != wantCalls {
+			t.Errorf("Server calls: got %d, want %d", got, wantCalls)
+		}
+		if got := atomic.LoadInt32(unmodified); got != wantUnmodified {
+			t.Errorf("Unmodified replies: got %d, want %d", got, wantUnmodified)
+		}
+	}
+
+	call("a")
+	call("a")
+	expect(1, 0)
+
+	// A stale reply is revalidated, not fetched again.
+	now = now.Add(2 * time.Minute)
+	call("a")
+	call("a")
+	expect(2, 1)
+
+	// The least recently used reply is evicted.
+	call("b")
+	call("c")
+	expect(4, 1)
+	call("c")
+	call("a")
+	expect(5, 1)
+	if n := c.Len(); n != 2 {
+		t.Errorf("Len: got %d, want 2", n)
+	}
+}
@@ -0,0 +1,243 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// sharedTransport is used by every Client without its own http.Client, so
+// that connections to a server are kept alive and reused across clients.
+var sharedTransport = func() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = 16
+	return t
+}()
+
+// Options control how a Client calls a server.  A zero field takes the
+// default value given for it.
+type Options struct {
+	// HTTPClient sends requests (default a client sharing keep-alive
+	// connections with other Clients).
+	HTTPClient *http.Client
+
+	// Timeout bounds each attempt at a call (default none).
+	Timeout time.Duration
+
+	// MaxRetries is the number of times a failed call is retried (default
+	// 2).  A negative value disables retries.
+	MaxRetries int
+
+	// Backoff is the delay before the first retry (default 100ms).  Each
+	// further retry waits twice as long as the one before, with jitter.
+	Backoff time.Duration
+
+	// Cache, if non-nil, holds replies so that repeated calls need not be
+	// sent to the server.
+	Cache *Cache
+
+	// Header holds fields added to every request, such as credentials.
+	Header http.Header
+}
+
+func (o *Options) httpClient() *http.Client {
+	if o != nil && o.HTTPClient != nil {
+		return o.HTTPClient
+	}
+	return &http.Client{Transport: sharedTransport}
+}
+
+func (o *Options) timeout() time.Duration {
+	if o == nil {
+		return 0
+	}
+	return o.Timeout
+}
+
+func (o *Options) maxRetries() int {
+	if o == nil || o.MaxRetries == 0 {
+		return 2
+	} else if o.MaxRetries < 0 {
+		return 0
+	}
+	return o.MaxRetries
+}
+
+func (o *Options) backoff() time.Duration {
+	if o == nil || o.Backoff <= 0 {
+		return 100 * time.Millisecond
+	}
+	return o.Backoff
+}
+
+// A Client calls the methods of JSON web APIs.  The methods of the Kythe
+// APIs are read-only queries, so a call that fails with a network error or a
+// server error that may be transient is retried.  A Client is safe for
+// concurrent use.
+type Client struct {
+	http       *http.Client
+	timeout    time.Duration
+	maxRetries int
+	backoff    time.Duration
+	cache      *Cache
+	header     http.Header
+}
+
+// NewClient returns a Client with the given options.  If opts == nil,
+// default options are used.
+func NewClient(opts *Options) *Client {
+	c := &Client{
+		http:       opts.httpClient(),
+		timeout:    opts.timeout(),
+		maxRetries: opts.maxRetries(),
+		backoff:    opts.backoff(),
+	}
+	if opts != nil {
+		c.cache = opts.Cache
+		c.header = opts.Header
+	}
+	return c
+}
+
+// defaultClient is used by the package-level Call.
+var defaultClient = NewClient(nil)
+
+// Call sends req to the given server method as a JSON-encoded body and
+// unmarshals the response body as JSON into reply.
+func (c *Client) Call(server, method string, req, reply proto.Message) error {
+	return c.CallContext(context.Background(), server, method, req, reply)
+}
+
+// CallContext is as Call, but the call and its retries are abandoned once ctx
+// is done.
+func (c *Client) CallContext(ctx context.Context, server, method string, req, reply proto.Message) error {
+	if c.cache == nil || c.cache.maxEntries <= 0 {
+		code, rec, _, err := c.post(ctx, server, method, req, "")
+		if err != nil {
+			return err
+		} else if code != http.StatusOK {
+			return fmt.Errorf("remote method error (code %d): %s", code, string(rec))
+		}
+		return unmarshalReply(rec, reply)
+	}
+
+	key, err := cacheKey(server, method, req)
+	if err != nil {
+		return err
+	}
+	cached, fresh := c.cache.lookup(key)
+	if cached != nil && fresh {
+		return unmarshalReply(cached.body, reply)
+	}
+	var etag string
+	if cached != nil {
+		etag = cached.etag
+	}
+	code, body, newTag, err := c.post(ctx, server, method, req, etag)
+	if err != nil {
+		return err
+	}
+	if code == http.StatusNotModified && cached != nil {
+		body, newTag = cached.body, cached.etag
+	} else if code != http.StatusOK {
+		return fmt.Errorf("remote method error (code %d): %s", code, string(body))
+	}
+	if err := unmarshalReply(body, reply); err != nil {
+		return err
+	}
+	c.cache.store(&cacheEntry{key: key, body: body, etag: newTag})
+	return nil
+}
+
+// post sends req to the given server method as a JSON-encoded body and
+// returns the status code, body, and ETag of the response, retrying failed
+// attempts.  If etag != "", the request is made conditional on it.
+func (c *Client) post(ctx context.Context, server, method string, req proto.Message, etag string) (int, []byte, string, error) {
+	body := new(bytes.Buffer)
+	if err := JSONMarshaler.Marshal(body, req); err != nil {
+		return 0, nil, "", fmt.Errorf("error marshaling %T: %v", req, err)
+	}
+	url := strings.TrimSuffix(server, "/") + "/" + strings.Trim(method, "/")
+
+	delay := c.backoff
+	for attempt := 0; ; attempt++ {
+		code, rec, tag, err := c.attempt(ctx, url, body.Bytes(), etag)
+		if attempt == c.maxRetries || !retryable(code, err) || ctx.Err() != nil {
+			return code, rec, tag, err
+		}
+		timer := time.NewTimer(delay/2 + time.Duration(rand.Int63n(int64(delay))))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, nil, "", fmt.Errorf("http error: %w", ctx.Err())
+		}
+		delay *= 2
+	}
+}
+
+// attempt makes a single request for post.
+func (c *Client) attempt(ctx context.Context, url string, body []byte, etag string) (int, []byte, string, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, "", fmt.Errorf("http error: %v", err)
+	}
+	for key, vals := range c.header {
+		hreq.Header[key] = vals
+	}
+	hreq.Header.Set("Content-Type", jsonBodyType)
+	if etag != "" {
+		hreq.Header.Set("If-None-Match", etag)
+	}
+	resp, err := c.http.Do(hreq)
+	if err != nil {
+		return 0, nil, "", fmt.Errorf("http error: %v", err)
+	}
+	rec, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return 0, nil, "", fmt.Errorf("error reading response body: %v", err)
+	}
+	return resp.StatusCode, rec, resp.Header.Get("ETag"), nil
+}
+
+// retryable reports whether an attempt that ended with the given status code
+// and error may succeed if repeated.
+func retryable(code int, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// flakyServer fails the first n requests it receives with the given status
+// code, and echoes the requests after that.
+func flakyServer(t *testing.T, n int32, code int) (*httptest.Server, *int32) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= n {
+			http.Error(w, "failed", code)
+			return
+		}
+		var req spb.VName
+		if err := ReadJSONBody(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		WriteResponse(w, r, &req)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		fail      int32
+		code      int
+		opts      Options
+		wantCalls int32
+		wantErr   bool
+	}{
+		{0, 0, Options{}, 1, false},
+		{2, http.StatusServiceUnavailable, Options{}, 3, false},
+		{3, http.StatusServiceUnavailable, Options{}, 3, true},
+		{3, http.StatusBadGateway, Options{MaxRetries: 3}, 4, false},
+		{1, http.StatusServiceUnavailable, Options{MaxRetries: -1}, 1, true},
+		// Client errors are not retried.
+		{1, http.StatusNotFound, Options{}, 1, true},
+	}
+	for _, test := range tests {
+		srv, calls := flakyServer(t, test.fail, test.code)
+		test.opts.Backoff = time.Millisecond
+		var reply spb.VName
+		err := NewClient(&test.opts).Call(srv.URL, "echo", &spb.VName{Path: "a"}, &reply)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("Call failing %d times with %d: got error %v, want error %v", test.fail, test.code, err, test.wantErr)
+		} else if err == nil && reply.Path != "a" {
+			t.Errorf("Call failing %d times with %d: got reply %v", test.fail, test.code, &reply)
+		}
+		if got := atomic.LoadInt32(calls); got != test.wantCalls {
+			t.Errorf("Call failing %d times with %d: server called %d times, want %d", test.fail, test.code, got, test.wantCalls)
+		}
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := NewClient(&Options{Timeout: 10 * time.Millisecond, MaxRetries: -1})
+	var reply spb.VName
+	err := c.Call(srv.URL, "echo", &spb.VName{}, &reply)
+	if err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Errorf("Call to a stalled server: got error %v, want deadline exceeded", err)
+	}
+}
+
+func TestClientContext(t *testing.T) {
+	srv, calls := flakyServer(t, 100, http.StatusServiceUnavailable)
+	c := NewClient(&Options{Backoff: time.Hour, MaxRetries: 5})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// The call is abandoned while it waits to retry.
+	start := time.Now()
+	err := c.CallContext(ctx, srv.URL, "echo", &spb.VName{}, new(spb.VName))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CallContext: got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Minute {
+		t.Errorf("CallContext took %v after its deadline", elapsed)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("Server called %d times, want 1", got)
+	}
+}
+
+func TestClientHeader(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		WriteResponse(w, r, &spb.VName{})
+	}))
+	defer srv.Close()
+
+	c := NewClient(&Options{Header: http.Header{"Authorization": {"Bearer xyzzy"}}})
+	if err := c.Call(srv.URL, "echo", &spb.VName{}, new(spb.VName)); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Bearer xyzzy"; auth != want {
+		t.Errorf("Authorization header: got %q, want %q", auth, want)
+	}
+}
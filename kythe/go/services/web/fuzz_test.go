@@ -0,0 +1,74 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	gpb "kythe.io/kythe/proto/graph_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+// requestTypes are the request messages decoded by the JSON web handlers.
+var requestTypes = []func() proto.Message{
+	func() proto.Message { return new(xpb.CrossReferencesRequest) },
+	func() proto.Message { return new(xpb.DecorationsRequest) },
+	func() proto.Message { return new(xpb.DocumentationRequest) },
+	func() proto.Message { return new(gpb.NodesRequest) },
+	func() proto.Message { return new(gpb.EdgesRequest) },
+	func() proto.Message { return new(spb.ReadRequest) },
+}
+
+func FuzzReadJSONBody(f *testing.F) {
+	for i, body := range []string{
+		`{"ticket": ["kythe://corpus?path=a.go#F"], "definition_kind": "ALL_DEFINITIONS", "page_size": 10}`,
+		`{"location": {"ticket": "kythe://corpus?path=a.go", "kind": "SPAN", "span": {"start": {"byte_offset": 1}}}, "references": true}`,
+		`{"ticket": ["kythe:#x"], "page_token": "dG9rZW4="}`,
+		`{"ticket": ["kythe:#x"], "filter": ["/kythe/node/kind"]}`,
+		`{"ticket": ["kythe:#x"], "kind": ["%/kythe/edge/childof"], "page_size": -1}`,
+		`{"source": {"signature": "s", "corpus": "c"}, "edge_kind": "*"}`,
+		``,
+		`null`,
+		`{"unknown": 1}`,
+	} {
+		f.Add(uint8(i), []byte(body))
+	}
+	f.Fuzz(func(t *testing.T, which uint8, body []byte) {
+		msg := requestTypes[int(which)%len(requestTypes)]()
+		if err := ReadJSONBody(httptest.NewRequest("POST", "/", bytes.NewReader(body)), msg); err != nil {
+			return
+		}
+
+		// A decoded request survives being encoded and decoded again.
+		rec, err := JSONMarshaler.MarshalToString(msg)
+		if err != nil {
+			t.Fatalf("Encoding %v: %v", msg, err)
+		}
+		again := msg.ProtoReflect().Type().New().Interface()
+		if err := ReadJSONBody(httptest.NewRequest("POST", "/", bytes.NewReader(rec)), again); err != nil {
+			t.Fatalf("Decoding %s: %v", rec, err)
+		}
+		if !proto.Equal(again, msg) {
+			t.Errorf("Decoding %s: got %v, want %v", rec, again, msg)
+		}
+	})
+}
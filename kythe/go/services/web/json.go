@@ -0,0 +1,179 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"sort"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// parallelMinElements is the number of elements a repeated or map field of a
+// message must have for Marshaler to encode it in parallel.
+const parallelMinElements = 256
+
+// bufPool holds the buffers used to encode parts of large fields.
+var bufPool = sync.Pool{New: func() any { return new([]byte) }}
+
+// MarshalAppend appends the JSON encoding of msg to b and returns the
+// result.  The large repeated and map fields of msg, which dominate the
+// cost of encoding replies such as decorations, are encoded in parallel and
+// written after its other fields.
+func (m Marshaler) MarshalAppend(b []byte, msg proto.Message) ([]byte, error) {
+	o := m.Options
+	if o.Multiline || o.Indent != "" || o.EmitUnpopulated {
+		return o.MarshalAppend(b, msg)
+	}
+	rm := msg.ProtoReflect()
+	var large []protoreflect.FieldDescriptor
+	rm.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if isLarge(fd, v) {
+			large = append(large, fd)
+		}
+		return true
+	})
+	if len(large) == 0 {
+		return o.MarshalAppend(b, msg)
+	}
+
+	// Encode the message without its large fields, then splice them in
+	// before the closing brace.
+	rest := rm.New()
+	rm.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if !isLarge(fd, v) {
+			rest.Set(fd, v)
+		}
+		return true
+	})
+	start := len(b)
+	b, err := o.MarshalAppend(b, rest.Interface())
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimRight(b, " ")
+	b = bytes.TrimRight(b[:len(b)-1], " ") // remove "}"
+	empty := len(b) == start+1
+	for _, fd := range large {
+		if !empty {
+			b = append(b, ',')
+		}
+		empty = false
+		name := fd.JSONName()
+		if o.UseProtoNames {
+			name = string(fd.Name())
+		}
+		b = append(append(append(b, '"'), name...), `":`...)
+		if b, err = m.appendLarge(b, rm.Get(fd), fd); err != nil {
+			return nil, err
+		}
+	}
+	return append(b, '}'), nil
+}
+
+// isLarge reports whether the field fd with value v is a list or string-keyed
+// map of messages with enough elements to be worth encoding in parallel.
+func isLarge(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+	switch {
+	case fd.IsList():
+		return fd.Kind() == protoreflect.MessageKind && v.List().Len() >= parallelMinElements
+	case fd.IsMap():
+		return fd.MapKey().Kind() == protoreflect.StringKind &&
+			fd.MapValue().Kind() == protoreflect.MessageKind &&
+			v.Map().Len() >= parallelMinElements
+	}
+	return false
+}
+
+// appendLarge appends the JSON encoding of the large field fd with value v
+// to b.
+func (m Marshaler) appendLarge(b []byte, v protoreflect.Value, fd protoreflect.FieldDescriptor) ([]byte, error) {
+	if fd.IsList() {
+		list := v.List()
+		b = append(b, '[')
+		b, err := m.appendParallel(b, list.Len(), func(b []byte, i int) ([]byte, error) {
+			return m.Options.MarshalAppend(b, list.Get(i).Message().Interface())
+		})
+		return append(b, ']'), err
+	}
+
+	// Map keys are written in sorted order, as protojson does.
+	mv := v.Map()
+	keys := make([]string, 0, mv.Len())
+	mv.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+		keys = append(keys, k.String())
+		return true
+	})
+	sort.Strings(keys)
+	b = append(b, '{')
+	b, err := m.appendParallel(b, len(keys), func(b []byte, i int) ([]byte, error) {
+		key, err := json.Marshal(keys[i])
+		if err != nil {
+			return nil, err
+		}
+		b = append(append(b, key...), ':')
+		val := mv.Get(protoreflect.ValueOfString(keys[i]).MapKey())
+		return m.Options.MarshalAppend(b, val.Message().Interface())
+	})
+	return append(b, '}'), err
+}
+
+// appendParallel appends the comma-separated encodings of elements 0 to n-1
+// to b, where appendElt appends the encoding of a single element.  The
+// elements are encoded in contiguous chunks, one chunk per CPU.
+func (m Marshaler) appendParallel(b []byte, n int, appendElt func([]byte, int) ([]byte, error)) ([]byte, error) {
+	chunks := runtime.GOMAXPROCS(0)
+	if chunks > n {
+		chunks = n
+	}
+	bufs := make([]*[]byte, chunks)
+	errs := make([]error, chunks)
+	var wg sync.WaitGroup
+	for c := 0; c < chunks; c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			buf := bufPool.Get().(*[]byte)
+			out := (*buf)[:0]
+			for i := c * n / chunks; i < (c+1)*n/chunks; i++ {
+				if i > 0 {
+					out = append(out, ',')
+				}
+				if out, errs[c] = appendElt(out, i); errs[c] != nil {
+					break
+				}
+			}
+			*buf = out
+			bufs[c] = buf
+		}(c)
+	}
+	wg.Wait()
+
+	var err error
+	for c, buf := range bufs {
+		if err == nil {
+			err = errs[c]
+			b = append(b, *buf...)
+		}
+		bufPool.Put(buf)
+	}
+	return b, err
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+// decorations returns a DecorationsReply with n references and nodes.
+func decorations(n int) *xpb.DecorationsReply {
+	reply := &xpb.DecorationsReply{
+		Location:   &xpb.Location{Ticket: "kythe://corpus?path=file.go"},
+		SourceText: []byte("package main\n"),
+		Nodes:      make(map[string]*cpb.NodeInfo),
+	}
+	for i := 0; i < n; i++ {
+		ticket := fmt.Sprintf("kythe://corpus?lang=go#node%d<&>", i)
+		reply.Reference = append(reply.Reference, &xpb.DecorationsReply_Reference{
+			TargetTicket: ticket,
+			Kind:         "/kythe/edge/ref",
+			Span: &cpb.Span{
+				Start: &cpb.Point{ByteOffset: int32(i), LineNumber: int32(i / 10)},
+				End:   &cpb.Point{ByteOffset: int32(i + 4), LineNumber: int32(i / 10)},
+			},
+		})
+		reply.Nodes[ticket] = &cpb.NodeInfo{Facts: map[string][]byte{"/kythe/node/kind": []byte("function")}}
+	}
+	return reply
+}
+
+func TestMarshalAppend(t *testing.T) {
+	tests := []*xpb.DecorationsReply{
+		decorations(0),
+		decorations(parallelMinElements - 1),
+		decorations(parallelMinElements),
+		decorations(3 * parallelMinElements),
+		// Only large fields are set.
+		{Reference: decorations(parallelMinElements).Reference},
+	}
+	for _, m := range []Marshaler{JSONMarshaler, {}} {
+		for _, msg := range tests {
+			rec, err := m.MarshalAppend([]byte("prefix"), msg)
+			if err != nil {
+				t.Fatalf("MarshalAppend: %v", err)
+			}
+			if got := string(rec[:6]); got != "prefix" {
+				t.Fatalf("MarshalAppend overwrote its argument: %q", got)
+			}
+			rec = rec[6:]
+
+			// The encoding must match protojson's, apart from the order of
+			// fields.
+			want, err := m.Options.Marshal(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gotJSON, wantJSON any
+			if err := json.Unmarshal(rec, &gotJSON); err != nil {
+				t.Fatalf("Invalid JSON for %d references: %v\n%s", len(msg.Reference), err, rec)
+			}
+			if err := json.Unmarshal(want, &wantJSON); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(wantJSON, gotJSON); diff != "" {
+				t.Errorf("JSON for %d references (-protojson +got):\n%s", len(msg.Reference), diff)
+			}
+
+			var back xpb.DecorationsReply
+			if err := protojson.Unmarshal(rec, &back); err != nil {
+				t.Fatal(err)
+			} else if !proto.Equal(msg, &back) {
+				t.Errorf("Round trip of %d references changed the message", len(msg.Reference))
+			}
+		}
+	}
+}
+
+func BenchmarkMarshalDecorations(b *testing.B) {
+	msg := decorations(20000)
+	b.Run("protojson", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := JSONMarshaler.Options.Marshal(msg); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Marshaler", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := JSONMarshaler.MarshalToString(msg); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
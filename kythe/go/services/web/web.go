@@ -18,17 +18,20 @@
 package web // import "kythe.io/kythe/go/services/web"
 
 import (
-	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
-	"strings"
 
 	"kythe.io/kythe/go/util/httpencoding"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
@@ -57,7 +60,7 @@ func (m Marshaler) Marshal(w io.Writer, msg proto.Message) error {
 
 // MarshalToString returns msg as a JSON-encoded string.
 func (m Marshaler) MarshalToString(msg proto.Message) ([]byte, error) {
-	return m.Options.Marshal(msg)
+	return m.MarshalAppend(nil, msg)
 }
 
 // RegisterQuitHandler adds a handler for /quitquitquit that call os.Exit(0).
@@ -68,28 +71,10 @@ func RegisterQuitHandler(mux *http.ServeMux) {
 }
 
 // Call sends req to the given server method as a JSON-encoded body and
-// unmarshals the response body as JSON into reply.
+// unmarshals the response body as JSON into reply, using a Client with
+// default options.
 func Call(server, method string, req, reply proto.Message) error {
-	body := new(bytes.Buffer)
-	if err := JSONMarshaler.Marshal(body, req); err != nil {
-		return fmt.Errorf("error marshaling %T: %v", req, err)
-	}
-	resp, err := http.Post(strings.TrimSuffix(server, "/")+"/"+strings.Trim(method, "/"),
-		jsonBodyType, body)
-	if err != nil {
-		return fmt.Errorf("http error: %v", err)
-	}
-	rec, err := ioutil.ReadAll(resp.Body)
-	resp.Body.Close()
-	if err != nil {
-		return fmt.Errorf("error reading response body: %v", err)
-	} else if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("remote method error (code %d): %s", resp.StatusCode, string(rec))
-	}
-	if err := protojson.Unmarshal(rec, reply); err != nil {
-		return fmt.Errorf("error unmarshaling %T: %v", reply, err)
-	}
-	return nil
+	return defaultClient.Call(server, method, req, reply)
 }
 
 // ReadJSONBody reads the entire body of r and unmarshals it from JSON into msg.
@@ -97,7 +82,7 @@ func Call(server, method string, req, reply proto.Message) error {
 func ReadJSONBody(r *http.Request, msg proto.Message) error {
 	rec, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		return fmt.Errorf("body read error: %v", err)
+		return fmt.Errorf("body read error: %w", err)
 	}
 	if len(rec) == 0 {
 		return nil
@@ -105,6 +90,49 @@ func ReadJSONBody(r *http.Request, msg proto.Message) error {
 	return protojson.Unmarshal(rec, msg)
 }
 
+// LimitBody returns a handler that passes requests to next with their bodies
+// limited to maxBytes.  Reading beyond the limit fails with an
+// *http.MaxBytesError, which HTTPStatus reports as
+// http.StatusRequestEntityTooLarge.
+func LimitBody(next http.Handler, maxBytes int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > maxBytes {
+			http.Error(w, fmt.Sprintf("request body exceeds %d bytes", maxBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// HTTPStatus returns the HTTP status with which to reply to a request that
+// failed with err: a client error for a body that is too large or for a
+// status error (see google.golang.org/grpc/status) rejecting the request, and
+// otherwise def.
+func HTTPStatus(err error, def int) int {
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		return http.StatusRequestEntityTooLarge
+	}
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	}
+	return def
+}
+
+// WriteError replies to a request that failed with err, with the status
+// returned by HTTPStatus(err, def).
+func WriteError(w http.ResponseWriter, err error, def int) {
+	http.Error(w, err.Error(), HTTPStatus(err, def))
+}
+
 // WriteResponse writes msg to w as a serialized protobuf if the "proto" query
 // parameter is set; otherwise as JSON.
 func WriteResponse(w http.ResponseWriter, r *http.Request, msg proto.Message) error {
@@ -114,27 +142,47 @@ func WriteResponse(w http.ResponseWriter, r *http.Request, msg proto.Message) er
 	return WriteJSONResponse(w, r, msg)
 }
 
-// WriteJSONResponse encodes v as JSON and writes it to w.
+// WriteJSONResponse encodes v as JSON and writes it to w.  If v is a
+// protobuf message, the response carries an ETag for its encoding.
 func WriteJSONResponse(w http.ResponseWriter, r *http.Request, v any) error {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	cw := httpencoding.CompressData(w, r)
-	defer cw.Close()
 	if msg, ok := v.(proto.Message); ok {
-		return JSONMarshaler.Marshal(cw, msg)
+		rec, err := JSONMarshaler.MarshalToString(msg)
+		if err != nil {
+			return err
+		}
+		return writeTagged(w, r, rec)
 	}
+	cw := httpencoding.CompressData(w, r)
+	defer cw.Close()
 	return json.NewEncoder(cw).Encode(v)
 }
 
-// WriteProtoResponse serializes msg to w.
+// WriteProtoResponse serializes msg to w.  The response carries an ETag for
+// the serialized message.
 func WriteProtoResponse(w http.ResponseWriter, r *http.Request, msg proto.Message) error {
 	w.Header().Set("Content-Type", "application/x-protobuf")
-	cw := httpencoding.CompressData(w, r)
-	defer cw.Close()
-	rec, err := proto.Marshal(msg)
+	rec, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("error marshaling proto: %v", err)
 	}
-	_, err = cw.Write(rec)
+	return writeTagged(w, r, rec)
+}
+
+// writeTagged writes rec to w with an ETag derived from its contents.  If
+// the request is conditional on the same ETag, only the status
+// http.StatusNotModified is written.
+func writeTagged(w http.ResponseWriter, r *http.Request, rec []byte) error {
+	sum := sha256.Sum256(rec)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	cw := httpencoding.CompressData(w, r)
+	defer cw.Close()
+	_, err := cw.Write(rec)
 	return err
 }
 
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+func TestLimitBody(t *testing.T) {
+	h := LimitBody(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req xpb.CrossReferencesRequest
+		if err := ReadJSONBody(r, &req); err != nil {
+			WriteError(w, err, http.StatusBadRequest)
+		}
+	}), 32)
+
+	tests := []struct {
+		body    string
+		chunked bool // whether the body has no declared length
+		code    int
+	}{
+		{`{"ticket":["kythe:#a"]}`, false, http.StatusOK},
+		{`{"ticket":["kythe:#a"]}`, true, http.StatusOK},
+		{`{"ticket":["kythe:#` + strings.Repeat("a", 32) + `"]}`, false, http.StatusRequestEntityTooLarge},
+		{`{"ticket":["kythe:#` + strings.Repeat("a", 32) + `"]}`, true, http.StatusRequestEntityTooLarge},
+		{`{"ticket":`, false, http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("POST", "/xrefs", strings.NewReader(test.body))
+		if test.chunked {
+			req.ContentLength = -1
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != test.code {
+			t.Errorf("Body %q (chunked: %v): got status %d; want %d", test.body, test.chunked, rec.Code, test.code)
+		}
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{errors.New("failure"), http.StatusInternalServerError},
+		{status.Error(codes.InvalidArgument, "too many tickets"), http.StatusBadRequest},
+		{status.Error(codes.OutOfRange, "page_size too large"), http.StatusBadRequest},
+		{status.Error(codes.NotFound, "no such file"), http.StatusNotFound},
+		{status.Error(codes.PermissionDenied, "access denied"), http.StatusForbidden},
+		{status.Error(codes.Internal, "failure"), http.StatusInternalServerError},
+		{&http.MaxBytesError{Limit: 10}, http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		if got := HTTPStatus(test.err, http.StatusInternalServerError); got != test.want {
+			t.Errorf("HTTPStatus(%v): got %d; want %d", test.err, got, test.want)
+		}
+	}
+}
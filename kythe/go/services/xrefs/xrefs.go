@@ -33,6 +33,7 @@ import (
 	"bitbucket.org/creachadair/stringset"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	cpb "kythe.io/kythe/proto/common_go_proto"
 	xpb "kythe.io/kythe/proto/xref_go_proto"
@@ -243,53 +244,104 @@ func MatchesAny(str string, patterns []*regexp.Regexp) bool {
 }
 
 // BoundedRequests guards against requests for more tickets than allowed per
-// the MaxTickets configuration.
+// the MaxTickets configuration, and against requests for too much data.  A
+// zero limit is not enforced.
 type BoundedRequests struct {
 	MaxTickets int
+
+	// MaxPageSize bounds the page_size of CrossReferences requests.
+	MaxPageSize int
+
+	// MaxDecorationsSpan bounds the bytes of a file spanned by Decorations
+	// requests.  Requests for a whole file cannot be bounded before they are
+	// served; they are served with the file's text, whether or not it was
+	// requested, and their replies are rejected if it is larger.
+	MaxDecorationsSpan int
+
 	Service
 }
 
+// Decorations implements part of the Service interface.
+func (b BoundedRequests) Decorations(ctx context.Context, req *xpb.DecorationsRequest) (*xpb.DecorationsReply, error) {
+	if b.MaxDecorationsSpan <= 0 {
+		return b.Service.Decorations(ctx, req)
+	}
+	if req.GetLocation().GetKind() == xpb.Location_SPAN {
+		span := req.GetLocation().GetSpan()
+		if n := span.GetEnd().GetByteOffset() - span.GetStart().GetByteOffset(); n > int32(b.MaxDecorationsSpan) {
+			return nil, status.Errorf(codes.InvalidArgument, "decorations span too large: %d bytes (max %d)", n, b.MaxDecorationsSpan)
+		}
+		return b.Service.Decorations(ctx, req)
+	}
+	withText := req
+	if !req.GetSourceText() {
+		withText = proto.Clone(req).(*xpb.DecorationsRequest)
+		withText.SourceText = true
+	}
+	reply, err := b.Service.Decorations(ctx, withText)
+	if err != nil {
+		return nil, err
+	} else if len(reply.GetSourceText()) > b.MaxDecorationsSpan {
+		return nil, status.Errorf(codes.InvalidArgument, "file too large for decorations: %d bytes (max %d); request a span of it", len(reply.GetSourceText()), b.MaxDecorationsSpan)
+	}
+	if !req.GetSourceText() {
+		reply.SourceText, reply.Encoding = nil, ""
+	}
+	return reply, nil
+}
+
 // CrossReferences implements part of the Service interface.
 func (b BoundedRequests) CrossReferences(ctx context.Context, req *xpb.CrossReferencesRequest) (*xpb.CrossReferencesReply, error) {
-	if len(req.Ticket) > b.MaxTickets {
+	if b.MaxTickets > 0 && len(req.Ticket) > b.MaxTickets {
 		return nil, status.Errorf(codes.InvalidArgument, "too many tickets requested: %d (max %d)", len(req.Ticket), b.MaxTickets)
+	} else if b.MaxPageSize > 0 && int(req.PageSize) > b.MaxPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size too large: %d (max %d)", req.PageSize, b.MaxPageSize)
 	}
 	return b.Service.CrossReferences(ctx, req)
 }
 
 // Documentation implements part of the Service interface.
 func (b BoundedRequests) Documentation(ctx context.Context, req *xpb.DocumentationRequest) (*xpb.DocumentationReply, error) {
-	if len(req.Ticket) > b.MaxTickets {
+	if b.MaxTickets > 0 && len(req.Ticket) > b.MaxTickets {
 		return nil, status.Errorf(codes.InvalidArgument, "too many tickets requested: %d (max %d)", len(req.Ticket), b.MaxTickets)
 	}
 	return b.Service.Documentation(ctx, req)
 }
 
-type webClient struct{ addr string }
+type webClient struct {
+	addr   string
+	client *web.Client
+}
 
 func (webClient) Close(context.Context) error { return nil }
 
 // Decorations implements part of the Service interface.
 func (w *webClient) Decorations(ctx context.Context, q *xpb.DecorationsRequest) (*xpb.DecorationsReply, error) {
 	var reply xpb.DecorationsReply
-	return &reply, web.Call(w.addr, "decorations", q, &reply)
+	return &reply, w.client.CallContext(ctx, w.addr, "decorations", q, &reply)
 }
 
 // CrossReferences implements part of the Service interface.
 func (w *webClient) CrossReferences(ctx context.Context, q *xpb.CrossReferencesRequest) (*xpb.CrossReferencesReply, error) {
 	var reply xpb.CrossReferencesReply
-	return &reply, web.Call(w.addr, "xrefs", q, &reply)
+	return &reply, w.client.CallContext(ctx, w.addr, "xrefs", q, &reply)
 }
 
 // Documentation implements part of the Service interface.
 func (w *webClient) Documentation(ctx context.Context, q *xpb.DocumentationRequest) (*xpb.DocumentationReply, error) {
 	var reply xpb.DocumentationReply
-	return &reply, web.Call(w.addr, "documentation", q, &reply)
+	return &reply, w.client.CallContext(ctx, w.addr, "documentation", q, &reply)
 }
 
 // WebClient returns an xrefs Service based on a remote web server.
 func WebClient(addr string) Service {
-	return &webClient{addr}
+	return WebClientWithOptions(addr, nil)
+}
+
+// WebClientWithOptions returns an xrefs Service based on a remote web server,
+// called with the given options.  If opts == nil, default options are used.
+func WebClientWithOptions(addr string, opts *web.Options) Service {
+	return &webClient{addr, web.NewClient(opts)}
 }
 
 // RegisterHTTPHandlers registers JSON HTTP handlers with mux using the given
@@ -315,12 +367,12 @@ func RegisterHTTPHandlers(ctx context.Context, xs Service, mux *http.ServeMux) {
 		}()
 		var req xpb.CrossReferencesRequest
 		if err := web.ReadJSONBody(r, &req); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			web.WriteError(w, err, http.StatusBadRequest)
 			return
 		}
-		reply, err := xs.CrossReferences(ctx, &req)
+		reply, err := xs.CrossReferences(r.Context(), &req)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			web.WriteError(w, err, http.StatusInternalServerError)
 			return
 		}
 
@@ -335,12 +387,12 @@ func RegisterHTTPHandlers(ctx context.Context, xs Service, mux *http.ServeMux) {
 		}()
 		var req xpb.DecorationsRequest
 		if err := web.ReadJSONBody(r, &req); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			web.WriteError(w, err, http.StatusBadRequest)
 			return
 		}
-		reply, err := xs.Decorations(ctx, &req)
+		reply, err := xs.Decorations(r.Context(), &req)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			web.WriteError(w, err, http.StatusInternalServerError)
 			return
 		}
 
@@ -355,12 +407,12 @@ func RegisterHTTPHandlers(ctx context.Context, xs Service, mux *http.ServeMux) {
 		}()
 		var req xpb.DocumentationRequest
 		if err := web.ReadJSONBody(r, &req); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			web.WriteError(w, err, http.StatusBadRequest)
 			return
 		}
-		reply, err := xs.Documentation(ctx, &req)
+		reply, err := xs.Documentation(r.Context(), &req)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			web.WriteError(w, err, http.StatusInternalServerError)
 			return
 		}
 
@@ -17,10 +17,18 @@
 package xrefs
 
 import (
+	"context"
+	"fmt"
 	"regexp"
 	"testing"
 
 	"kythe.io/kythe/go/util/schema/facts"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
 )
 
 func TestFilterRegexp(t *testing.T) {
@@ -54,3 +62,85 @@ func TestFilterRegexp(t *testing.T) {
 		}
 	}
 }
+
+// textService serves decorations of a file with the given text.
+type textService struct {
+	Service
+	text string
+}
+
+func (s textService) Decorations(_ context.Context, req *xpb.DecorationsRequest) (*xpb.DecorationsReply, error) {
+	if !req.GetSourceText() {
+		return &xpb.DecorationsReply{}, nil
+	}
+	return &xpb.DecorationsReply{SourceText: []byte(s.text), Encoding: "UTF-8"}, nil
+}
+
+func (textService) CrossReferences(context.Context, *xpb.CrossReferencesRequest) (*xpb.CrossReferencesReply, error) {
+	return &xpb.CrossReferencesReply{}, nil
+}
+
+func TestBoundedRequests(t *testing.T) {
+	ctx := context.Background()
+	b := BoundedRequests{
+		MaxTickets:         2,
+		MaxPageSize:        100,
+		MaxDecorationsSpan: 10,
+		Service:            textService{text: "0123456789abcdef"},
+	}
+	span := func(start, end int32) *xpb.DecorationsRequest {
+		return &xpb.DecorationsRequest{Location: &xpb.Location{
+			Kind: xpb.Location_SPAN,
+			Span: &cpb.Span{Start: &cpb.Point{ByteOffset: start}, End: &cpb.Point{ByteOffset: end}},
+		}}
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		ok   bool
+	}{
+		{"small span", func() error { _, err := b.Decorations(ctx, span(2, 12)); return err }, true},
+		{"large span", func() error { _, err := b.Decorations(ctx, span(2, 13)); return err }, false},
+		{"large file", func() error {
+			_, err := b.Decorations(ctx, &xpb.DecorationsRequest{Location: &xpb.Location{}, SourceText: true})
+			return err
+		}, false},
+		{"large file without text", func() error {
+			_, err := b.Decorations(ctx, &xpb.DecorationsRequest{Location: &xpb.Location{}})
+			return err
+		}, false},
+		{"small file without text", func() error {
+			small := BoundedRequests{MaxDecorationsSpan: 100, Service: b.Service}
+			reply, err := small.Decorations(ctx, &xpb.DecorationsRequest{Location: &xpb.Location{}})
+			if err == nil && (reply.SourceText != nil || reply.Encoding != "") {
+				return fmt.Errorf("got unrequested text %q (%s)", reply.SourceText, reply.Encoding)
+			}
+			return err
+		}, true},
+		{"small page", func() error {
+			_, err := b.CrossReferences(ctx, &xpb.CrossReferencesRequest{Ticket: []string{"a"}, PageSize: 100})
+			return err
+		}, true},
+		{"large page", func() error {
+			_, err := b.CrossReferences(ctx, &xpb.CrossReferencesRequest{Ticket: []string{"a"}, PageSize: 101})
+			return err
+		}, false},
+		{"many tickets", func() error {
+			_, err := b.CrossReferences(ctx, &xpb.CrossReferencesRequest{Ticket: []string{"a", "b", "c"}})
+			return err
+		}, false},
+		{"unbounded", func() error {
+			_, err := BoundedRequests{Service: b.Service}.CrossReferences(ctx, &xpb.CrossReferencesRequest{Ticket: []string{"a", "b", "c"}, PageSize: 1000})
+			return err
+		}, true},
+	}
+	for _, test := range tests {
+		err := test.call()
+		if test.ok && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		} else if !test.ok && status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: got error %v; want InvalidArgument", test.name, err)
+		}
+	}
+}
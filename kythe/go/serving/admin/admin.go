@@ -0,0 +1,285 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package admin serves authenticated HTTP endpoints with which operators
+// manage the data a server serves, so that it need not be restarted when the
+// data are updated:
+//
+//	POST /admin/reload      re-opens the serving tables
+//	POST /admin/repopulate  rebuilds in-memory data, such as a file tree
+//	GET  /admin/status      reports the state of the serving data as JSON
+//
+// Each request must carry one of the configured tokens in an
+// "Authorization: Bearer <token>" header.  A reload or repopulation runs to
+// completion before its request is answered; a request for one that is
+// already running fails with http.StatusConflict.
+//
+// If the Handler has an audit log, every request to an endpoint is recorded
+// in it, including those rejected.  Callers are identified by a fingerprint
+// of the token they present (see TokenActor) and their remote address.
+package admin // import "kythe.io/kythe/go/serving/admin"
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"kythe.io/kythe/go/services/web"
+	"kythe.io/kythe/go/util/audit"
+	"kythe.io/kythe/go/util/log"
+)
+
+// An Action performs an administrative operation.
+type Action func(ctx context.Context) error
+
+// A Handler serves the admin endpoints.  Endpoints whose Action is nil are
+// not registered.
+type Handler struct {
+	// Tokens are the bearer tokens accepted.  If empty, every request is
+	// rejected.
+	Tokens []string
+
+	Reload     Action
+	Repopulate Action
+
+	// Status, if set, returns a JSON-encodable description of the data
+	// currently served, reported as the "serving" field of the status.
+	Status func(ctx context.Context) any
+
+	// Audit, if set, records each request to the endpoints.
+	Audit *audit.Log
+
+	started    time.Time
+	reload     operation
+	repopulate operation
+}
+
+// A Status is the reply of /admin/status.
+type Status struct {
+	Started    time.Time        `json:"started"`
+	Reload     *OperationStatus `json:"reload,omitempty"`
+	Repopulate *OperationStatus `json:"repopulate,omitempty"`
+	Serving    any              `json:"serving,omitempty"`
+}
+
+// An OperationStatus reports the history of an operation.
+type OperationStatus struct {
+	Running  bool      `json:"running"`
+	Count    int       `json:"count"`              // completed runs, including failures
+	Last     time.Time `json:"last,omitempty"`     // start of the last completed run
+	Duration string    `json:"duration,omitempty"` // of the last completed run
+	Error    string    `json:"error,omitempty"`    // of the last completed run
+}
+
+// An operation serializes the runs of an Action and records their outcome.
+type operation struct {
+	run sync.Mutex // held while the operation runs
+
+	mu     sync.Mutex
+	status OperationStatus
+}
+
+var errRunning = errors.New("operation already running")
+
+func (o *operation) do(ctx context.Context, act Action) error {
+	if !o.run.TryLock() {
+		return errRunning
+	}
+	defer o.run.Unlock()
+	o.mu.Lock()
+	o.status.Running = true
+	o.mu.Unlock()
+
+	start := time.Now()
+	err := act(ctx)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.status = OperationStatus{
+		Count:    o.status.Count + 1,
+		Last:     start.UTC(),
+		Duration: time.Since(start).Round(time.Millisecond).String(),
+	}
+	if err != nil {
+		o.status.Error = err.Error()
+	}
+	return err
+}
+
+func (o *operation) get() *OperationStatus {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	s := o.status
+	return &s
+}
+
+// RegisterHTTPHandlers registers the endpoints of h with mux.
+func (h *Handler) RegisterHTTPHandlers(ctx context.Context, mux *http.ServeMux) {
+	h.started = time.Now().UTC()
+	if h.Reload != nil {
+		mux.Handle("/admin/reload", h.authorized(h.action(ctx, "reload", &h.reload, h.Reload)))
+	}
+	if h.Repopulate != nil {
+		mux.Handle("/admin/repopulate", h.authorized(h.action(ctx, "repopulate", &h.repopulate, h.Repopulate)))
+	}
+	mux.Handle("/admin/status", h.authorized(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		status := &Status{Started: h.started}
+		if h.Reload != nil {
+			status.Reload = h.reload.get()
+		}
+		if h.Repopulate != nil {
+			status.Repopulate = h.repopulate.get()
+		}
+		if h.Status != nil {
+			status.Serving = h.Status(r.Context())
+		}
+		if err := web.WriteJSONResponse(w, r, status); err != nil {
+			log.ErrorContextf(ctx, "Writing admin status: %v", err)
+		}
+	})))
+}
+
+func (h *Handler) action(ctx context.Context, name string, op *operation, act Action) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		actor := audit.Actor(r.Context())
+		log.InfoContextf(ctx, "Admin %s requested by %s from %s", name, actor, r.RemoteAddr)
+		// The operation outlives a canceled request, so that it is not left
+		// half done.
+		if err := op.do(audit.WithActor(ctx, actor), act); errors.Is(err, errRunning) {
+			http.Error(w, fmt.Sprintf("%s already running", name), http.StatusConflict)
+			return
+		} else if err != nil {
+			log.ErrorContextf(ctx, "Admin %s failed: %v", name, err)
+			http.Error(w, fmt.Sprintf("%s failed: %v", name, err), http.StatusInternalServerError)
+			return
+		}
+		if err := web.WriteJSONResponse(w, r, op.get()); err != nil {
+			log.ErrorContextf(ctx, "Writing admin %s status: %v", name, err)
+		}
+	})
+}
+
+// authorized returns a handler that passes requests bearing one of the tokens
+// of h to next, attributed to the token's actor, and rejects all others.
+// Both are recorded in the audit log.
+func (h *Handler) authorized(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		token, ok := strings.CutPrefix(auth, "Bearer ")
+		actor := "anonymous"
+		if ok && token != "" {
+			actor = TokenActor(token)
+		}
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		defer func() { h.record(r, actor, sw.status) }()
+		if !ok || !h.validToken(token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="kythe-admin"`)
+			http.Error(sw, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(sw, r.WithContext(audit.WithActor(r.Context(), actor)))
+	})
+}
+
+// record records a request to the audit log of h.
+func (h *Handler) record(r *http.Request, actor string, status int) {
+	e := &audit.Event{
+		Actor:   actor,
+		Remote:  r.RemoteAddr,
+		Action:  "admin." + strings.TrimPrefix(r.URL.Path, "/admin/"),
+		Details: map[string]any{"method": r.Method, "status": status},
+	}
+	if status >= http.StatusBadRequest {
+		e.Error = http.StatusText(status)
+	}
+	if err := h.Audit.Record(e); err != nil {
+		log.ErrorContextf(r.Context(), "Recording admin request: %v", err)
+	}
+}
+
+// TokenActor returns the actor to which the operations authorized by token
+// are attributed: a fingerprint of the token, which identifies it without
+// revealing it.
+func TokenActor(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(sum[:4])
+}
+
+// A statusWriter records the status of the response written through it.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (h *Handler) validToken(token string) bool {
+	valid := false
+	for _, t := range h.Tokens {
+		// Compare with every token, in constant time, so that timing reveals
+		// nothing about them.
+		if t != "" && subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// ReadTokens returns the tokens listed in the named file, one per line.
+// Blank lines and lines beginning with "#" are ignored.
+func ReadTokens(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var tokens []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			tokens = append(tokens, line)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("no tokens in %s", path)
+	}
+	return tokens, nil
+}
@@ -0,0 +1,225 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kythe.io/kythe/go/util/audit"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const token = "s3cret"
+
+func serve(h *Handler, method, path, auth string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	h.RegisterHTTPHandlers(context.Background(), mux)
+	req := httptest.NewRequest(method, path, nil)
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthorization(t *testing.T) {
+	h := &Handler{Tokens: []string{"other", token}}
+	tests := []struct {
+		auth string
+		code int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"Basic " + token, http.StatusUnauthorized},
+		{"Bearer " + token, http.StatusOK},
+		{"Bearer other", http.StatusOK},
+	}
+	for _, test := range tests {
+		if rec := serve(h, "GET", "/admin/status", test.auth); rec.Code != test.code {
+			t.Errorf("Authorization %q: got status %d, want %d", test.auth, rec.Code, test.code)
+		}
+	}
+	if rec := serve(&Handler{Tokens: []string{""}}, "GET", "/admin/status", "Bearer "); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Empty token: got status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestActions(t *testing.T) {
+	var reloads, repopulations int
+	fail := errors.New("no table")
+	h := &Handler{
+		Tokens:     []string{token},
+		Reload:     func(context.Context) error { reloads++; return nil },
+		Repopulate: func(context.Context) error { repopulations++; return fail },
+		Status:     func(context.Context) any { return map[string]string{"table": "t1"} },
+	}
+	mux := http.NewServeMux()
+	h.RegisterHTTPHandlers(context.Background(), mux)
+	do := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do("GET", "/admin/reload"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /admin/reload: got status %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if rec := do("POST", "/admin/reload"); rec.Code != http.StatusOK {
+		t.Errorf("POST /admin/reload: got status %d: %s", rec.Code, rec.Body)
+	}
+	if rec := do("POST", "/admin/repopulate"); rec.Code != http.StatusInternalServerError {
+		t.Errorf("POST /admin/repopulate: got status %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if reloads != 1 || repopulations != 1 {
+		t.Errorf("Got %d reloads and %d repopulations, want 1 of each", reloads, repopulations)
+	}
+
+	rec := do("GET", "/admin/status")
+	var status struct {
+		Reload, Repopulate *OperationStatus
+		Serving            map[string]string
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Decoding status %s: %v", rec.Body, err)
+	}
+	if status.Reload == nil || status.Reload.Count != 1 || status.Reload.Error != "" {
+		t.Errorf("Reload status: got %+v", status.Reload)
+	}
+	if status.Repopulate == nil || status.Repopulate.Count != 1 || status.Repopulate.Error != fail.Error() {
+		t.Errorf("Repopulate status: got %+v", status.Repopulate)
+	}
+	if diff := cmp.Diff(map[string]string{"table": "t1"}, status.Serving); diff != "" {
+		t.Errorf("Serving status: (-want +got)\n%s", diff)
+	}
+}
+
+func TestUnsetActions(t *testing.T) {
+	h := &Handler{Tokens: []string{token}}
+	if rec := serve(h, "POST", "/admin/reload", "Bearer "+token); rec.Code != http.StatusNotFound {
+		t.Errorf("POST /admin/reload: got status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestConflict(t *testing.T) {
+	started, done := make(chan struct{}), make(chan struct{})
+	h := &Handler{
+		Tokens: []string{token},
+		Reload: func(context.Context) error {
+			close(started)
+			<-done
+			return nil
+		},
+	}
+	mux := http.NewServeMux()
+	h.RegisterHTTPHandlers(context.Background(), mux)
+	post := func() int {
+		req := httptest.NewRequest("POST", "/admin/reload", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	first := make(chan int)
+	go func() { first <- post() }()
+	<-started
+	if code := post(); code != http.StatusConflict {
+		t.Errorf("Concurrent reload: got status %d, want %d", code, http.StatusConflict)
+	}
+	if s := h.reload.get(); !s.Running {
+		t.Errorf("Reload status while running: got %+v", s)
+	}
+	close(done)
+	if code := <-first; code != http.StatusOK {
+		t.Errorf("First reload: got status %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := audit.Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reloadActor string
+	h := &Handler{
+		Tokens: []string{token},
+		Reload: func(ctx context.Context) error { reloadActor = audit.Actor(ctx); return nil },
+		Audit:  l,
+	}
+	serve(h, "POST", "/admin/reload", "Bearer "+token)
+	serve(h, "GET", "/admin/status", "Bearer wrong")
+	serve(h, "GET", "/admin/status", "")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := TokenActor(token); reloadActor != want {
+		t.Errorf("Reload actor: got %q, want %q", reloadActor, want)
+	}
+	type event struct{ Actor, Action, Error string }
+	var got []event
+	if err := audit.Read(path, nil, func(e *audit.Event) error {
+		if e.Remote == "" {
+			t.Errorf("Event %v has no remote address", e)
+		}
+		got = append(got, event{e.Actor, e.Action, e.Error})
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []event{
+		{TokenActor(token), "admin.reload", ""},
+		{TokenActor("wrong"), "admin.status", "Unauthorized"},
+		{"anonymous", "admin.status", "Unauthorized"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Audit events: (-want +got)\n%s", diff)
+	}
+}
+
+func TestReadTokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens")
+	if err := os.WriteFile(path, []byte("# admin tokens\n\n  abc  \ndef\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := ReadTokens(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"abc", "def"}, tokens); diff != "" {
+		t.Errorf("ReadTokens: (-want +got)\n%s", diff)
+	}
+
+	if err := os.WriteFile(path, []byte("# none\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadTokens(path); err == nil {
+		t.Error("ReadTokens of a file without tokens succeeded")
+	}
+}
@@ -27,6 +27,7 @@ import (
 
 	"kythe.io/kythe/go/services/filetree"
 	"kythe.io/kythe/go/services/graph"
+	"kythe.io/kythe/go/services/web"
 	"kythe.io/kythe/go/services/xrefs"
 	ftsrv "kythe.io/kythe/go/serving/filetree"
 	gsrv "kythe.io/kythe/go/serving/graph"
@@ -75,13 +76,17 @@ func Flag(name, value, usage string) *Interface {
 //   - http:// URL pointed at a JSON web API
 //   - https:// URL pointed at a JSON web API
 //   - local path to a LevelDB serving table
-func ParseSpec(apiSpec string) (Interface, error) {
+func ParseSpec(apiSpec string) (Interface, error) { return ParseSpecWithOptions(apiSpec, nil) }
+
+// ParseSpecWithOptions is as ParseSpec, but a JSON web API is called with the
+// given options.  If opts == nil, default options are used.
+func ParseSpecWithOptions(apiSpec string, opts *web.Options) (Interface, error) {
 	api := &apiCloser{}
 	if strings.HasPrefix(apiSpec, "http://") || strings.HasPrefix(apiSpec, "https://") {
-		api.xs = xrefs.WebClient(apiSpec)
-		api.gs = graph.WebClient(apiSpec)
-		api.ft = filetree.WebClient(apiSpec)
-		api.id = identifiers.WebClient(apiSpec)
+		api.xs = xrefs.WebClientWithOptions(apiSpec, opts)
+		api.gs = graph.WebClientWithOptions(apiSpec, opts)
+		api.ft = filetree.WebClientWithOptions(apiSpec, opts)
+		api.id = identifiers.WebClientWithOptions(apiSpec, opts)
 	} else if _, err := os.Stat(apiSpec); err == nil {
 		db, err := leveldb.Open(apiSpec, nil)
 		if err != nil {
@@ -168,6 +173,16 @@ func (api apiCloser) Directory(ctx context.Context, req *ftpb.DirectoryRequest)
 	return api.ft.Directory(ctx, req)
 }
 
+// Tree implements part of the filetree Service interface.
+func (api apiCloser) Tree(ctx context.Context, req *ftpb.TreeRequest) (*ftpb.DirectoryReply, error) {
+	return api.ft.Tree(ctx, req)
+}
+
+// Search implements part of the filetree Service interface.
+func (api apiCloser) Search(ctx context.Context, req *ftpb.SearchRequest) (*ftpb.SearchReply, error) {
+	return api.ft.Search(ctx, req)
+}
+
 // CorpusRoots implements part of the filetree Service interface.
 func (api apiCloser) CorpusRoots(ctx context.Context, req *ftpb.CorpusRootsRequest) (*ftpb.CorpusRootsReply, error) {
 	return api.ft.CorpusRoots(ctx, req)
@@ -0,0 +1,289 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package consistency checks that the file tree, decorations, and
+// cross-references served for an index agree with one another, so that a
+// newly published index can be verified before it is relied upon.
+//
+// A Checker walks the file tree of each corpus and, for every file listed,
+// checks that it has decorations and text, that the spans of its references
+// lie within its text, and that each target of its references has
+// cross-references.  Discrepancies are reported per corpus.
+package consistency // import "kythe.io/kythe/go/serving/consistency"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+
+	"kythe.io/kythe/go/services/filetree"
+	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/util/kytheuri"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+// A Kind classifies a discrepancy.
+type Kind string
+
+// Kinds of discrepancies.
+const (
+	MissingDecorations        Kind = "missing_decorations"         // a listed file has no decorations
+	MissingText               Kind = "missing_text"                // a listed file has no text, and is not marked missing_text
+	SpanOutOfBounds           Kind = "span_out_of_bounds"          // a reference lies outside the text of its file
+	MissingDefinitionLocation Kind = "missing_definition_location" // a reference's target definition has no location
+	MissingCrossReferences    Kind = "missing_cross_references"    // a reference target has no cross-references
+)
+
+// A Discrepancy is a disagreement between services.
+type Discrepancy struct {
+	Kind   Kind   `json:"kind"`
+	File   string `json:"file"`             // ticket of the file in which it was found
+	Ticket string `json:"ticket,omitempty"` // ticket of the node concerned, if not the file
+	Detail string `json:"detail,omitempty"`
+}
+
+func (d *Discrepancy) String() string {
+	s := fmt.Sprintf("%s: %s", d.Kind, d.File)
+	if d.Ticket != "" {
+		s += " -> " + d.Ticket
+	}
+	if d.Detail != "" {
+		s += " (" + d.Detail + ")"
+	}
+	return s
+}
+
+// A CorpusReport is the result of checking one corpus.
+type CorpusReport struct {
+	Corpus  string `json:"corpus"`
+	Files   int    `json:"files"`   // number of files checked
+	Targets int    `json:"targets"` // number of distinct reference targets checked
+
+	// Counts are the numbers of discrepancies found, by kind.
+	Counts map[Kind]int `json:"counts,omitempty"`
+
+	// Discrepancies are the discrepancies found, up to
+	// Checker.MaxDiscrepancies.
+	Discrepancies []*Discrepancy `json:"discrepancies,omitempty"`
+}
+
+// OK reports whether no discrepancies were found in the corpus.
+func (r *CorpusReport) OK() bool { return len(r.Counts) == 0 }
+
+// A Report is the result of a check, with one CorpusReport per corpus, in
+// order by corpus.
+type Report struct {
+	Corpora []*CorpusReport `json:"corpora"`
+}
+
+// OK reports whether no discrepancies were found.
+func (r *Report) OK() bool {
+	for _, c := range r.Corpora {
+		if !c.OK() {
+			return false
+		}
+	}
+	return true
+}
+
+// A Checker checks the consistency of the data served by its services.
+type Checker struct {
+	FileTree filetree.Service
+	XRefs    xrefs.Service
+
+	// Corpora, if set, restricts the check to the named corpora.
+	Corpora []string
+
+	// MaxTicketsPerRequest bounds the tickets in each CrossReferences
+	// request.  If zero, DefaultMaxTicketsPerRequest is used.
+	MaxTicketsPerRequest int
+
+	// MaxDiscrepancies bounds the discrepancies recorded per corpus; all are
+	// counted.  If zero, DefaultMaxDiscrepancies is used.
+	MaxDiscrepancies int
+}
+
+// Defaults for the options of a Checker.
+const (
+	DefaultMaxTicketsPerRequest = 20
+	DefaultMaxDiscrepancies     = 100
+)
+
+// Check checks every file listed in the file tree of each corpus.  It returns
+// an error only if a service fails other than by reporting missing data.
+func (c *Checker) Check(ctx context.Context) (*Report, error) {
+	roots, err := c.FileTree.CorpusRoots(ctx, &ftpb.CorpusRootsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("listing corpora: %v", err)
+	}
+	wanted := make(map[string]bool)
+	for _, corpus := range c.Corpora {
+		wanted[corpus] = true
+	}
+
+	report := new(Report)
+	for _, corpus := range roots.Corpus {
+		if len(wanted) > 0 && !wanted[corpus.Name] {
+			continue
+		}
+		cr := &corpusCheck{
+			Checker: c,
+			report:  &CorpusReport{Corpus: corpus.Name},
+			targets: make(map[string]string),
+		}
+		for _, root := range corpus.Root {
+			if err := cr.walk(ctx, corpus.Name, root, ""); err != nil {
+				return nil, err
+			}
+		}
+		if err := cr.checkTargets(ctx); err != nil {
+			return nil, err
+		}
+		report.Corpora = append(report.Corpora, cr.report)
+	}
+	sort.Slice(report.Corpora, func(i, j int) bool { return report.Corpora[i].Corpus < report.Corpora[j].Corpus })
+	return report, nil
+}
+
+// A corpusCheck is the state of the check of one corpus.
+type corpusCheck struct {
+	*Checker
+	report  *CorpusReport
+	targets map[string]string // reference target → a file referring to it
+}
+
+func (c *corpusCheck) add(d *Discrepancy) {
+	r := c.report
+	if r.Counts == nil {
+		r.Counts = make(map[Kind]int)
+	}
+	r.Counts[d.Kind]++
+	max := c.MaxDiscrepancies
+	if max <= 0 {
+		max = DefaultMaxDiscrepancies
+	}
+	if len(r.Discrepancies) < max {
+		r.Discrepancies = append(r.Discrepancies, d)
+	}
+}
+
+// walk checks the files in the directory at dir, and its subdirectories.
+func (c *corpusCheck) walk(ctx context.Context, corpus, root, dir string) error {
+	reply, err := c.FileTree.Directory(ctx, &ftpb.DirectoryRequest{Corpus: corpus, Root: root, Path: dir})
+	if err != nil {
+		return fmt.Errorf("reading directory %q in corpus %q root %q: %v", dir, corpus, root, err)
+	}
+	for _, e := range reply.Entry {
+		p := path.Join(dir, e.Name)
+		switch e.Kind {
+		case ftpb.DirectoryReply_DIRECTORY:
+			if err := c.walk(ctx, corpus, root, p); err != nil {
+				return err
+			}
+		case ftpb.DirectoryReply_FILE:
+			file := (&kytheuri.URI{Corpus: corpus, Root: root, Path: p}).String()
+			if err := c.checkFile(ctx, file, e.MissingText); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkFile checks the decorations of a file.
+func (c *corpusCheck) checkFile(ctx context.Context, file string, missingText bool) error {
+	c.report.Files++
+	reply, err := c.XRefs.Decorations(ctx, &xpb.DecorationsRequest{
+		Location:          &xpb.Location{Ticket: file},
+		SourceText:        true,
+		References:        true,
+		TargetDefinitions: true,
+	})
+	if isNotFound(err) {
+		c.add(&Discrepancy{Kind: MissingDecorations, File: file})
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("reading decorations of %q: %v", file, err)
+	}
+
+	if len(reply.SourceText) == 0 && !missingText {
+		c.add(&Discrepancy{Kind: MissingText, File: file})
+	}
+	size := int32(len(reply.SourceText))
+	for _, ref := range reply.Reference {
+		if start, end := ref.GetSpan().GetStart().GetByteOffset(), ref.GetSpan().GetEnd().GetByteOffset(); start < 0 || end < start || end > size {
+			c.add(&Discrepancy{
+				Kind:   SpanOutOfBounds,
+				File:   file,
+				Ticket: ref.TargetTicket,
+				Detail: fmt.Sprintf("%s span [%d, %d) of %d bytes", ref.Kind, start, end, size),
+			})
+		}
+		if def := ref.TargetDefinition; def != "" && reply.DefinitionLocations[def] == nil {
+			c.add(&Discrepancy{Kind: MissingDefinitionLocation, File: file, Ticket: def})
+		}
+		if _, ok := c.targets[ref.TargetTicket]; !ok {
+			c.targets[ref.TargetTicket] = file
+		}
+	}
+	return nil
+}
+
+// checkTargets checks that each reference target has cross-references.
+func (c *corpusCheck) checkTargets(ctx context.Context) error {
+	tickets := make([]string, 0, len(c.targets))
+	for t := range c.targets {
+		tickets = append(tickets, t)
+	}
+	sort.Strings(tickets)
+	c.report.Targets = len(tickets)
+
+	batch := c.MaxTicketsPerRequest
+	if batch <= 0 {
+		batch = DefaultMaxTicketsPerRequest
+	}
+	for len(tickets) > 0 {
+		n := min(batch, len(tickets))
+		reply, err := c.XRefs.CrossReferences(ctx, &xpb.CrossReferencesRequest{
+			Ticket:          tickets[:n],
+			DefinitionKind:  xpb.CrossReferencesRequest_ALL_DEFINITIONS,
+			DeclarationKind: xpb.CrossReferencesRequest_ALL_DECLARATIONS,
+			ReferenceKind:   xpb.CrossReferencesRequest_ALL_REFERENCES,
+			PageSize:        1,
+		})
+		if err != nil && !isNotFound(err) {
+			return fmt.Errorf("reading cross-references: %v", err)
+		}
+		for _, t := range tickets[:n] {
+			if reply.GetCrossReferences()[t] == nil {
+				c.add(&Discrepancy{Kind: MissingCrossReferences, File: c.targets[t], Ticket: t})
+			}
+		}
+		tickets = tickets[n:]
+	}
+	return nil
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, xrefs.ErrDecorationsNotFound) || status.Code(err) == codes.NotFound
+}
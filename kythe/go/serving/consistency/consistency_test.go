@@ -0,0 +1,179 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consistency
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/services/filetree"
+	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/test/testutil/fakes"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+const (
+	fileTicket = "kythe://corpus?path=src/a.go"
+	fTicket    = "kythe://corpus?lang=go?path=src/a.go#F"
+	gTicket    = "kythe://corpus?lang=go?path=src/a.go#G"
+	otherFile  = "kythe://other?path=b.go"
+	text       = "package a\n\nfunc F() {}\n\nfunc G() { F() }\n"
+)
+
+func testIndex() *fakes.Index {
+	ix := fakes.NewIndex()
+	ix.Node(fTicket, nodes.Function)
+	ix.Node(gTicket, nodes.Function)
+	call := strings.LastIndex(text, "F()")
+	ix.File(fileTicket, text).
+		AnchorText(edges.DefinesBinding, fTicket, "F").
+		AnchorText(edges.DefinesBinding, gTicket, "G").
+		Anchor(edges.RefCall, fTicket, call, call+3)
+	ix.File(otherFile, "package b\n")
+	return ix
+}
+
+// brokenXRefs alters the replies of an xrefs.Service.
+type brokenXRefs struct {
+	xrefs.Service
+	text    string // if set, replaces the text of each file
+	dropped string // a ticket dropped from cross-references
+}
+
+func (b *brokenXRefs) Decorations(ctx context.Context, req *xpb.DecorationsRequest) (*xpb.DecorationsReply, error) {
+	reply, err := b.Service.Decorations(ctx, req)
+	if err == nil && b.text != "" {
+		reply.SourceText = []byte(b.text)
+	}
+	return reply, err
+}
+
+func (b *brokenXRefs) CrossReferences(ctx context.Context, req *xpb.CrossReferencesRequest) (*xpb.CrossReferencesReply, error) {
+	reply, err := b.Service.CrossReferences(ctx, req)
+	if err == nil {
+		delete(reply.CrossReferences, b.dropped)
+	}
+	return reply, err
+}
+
+// treeWithExtraFile returns a file tree listing the files of testIndex, and
+// a file with no decorations.
+func treeWithExtraFile() filetree.Service {
+	m := filetree.NewMap()
+	u := m.Update()
+	u.AddFile(&spb.VName{Corpus: "corpus", Path: "src/a.go"})
+	u.AddFile(&spb.VName{Corpus: "corpus", Path: "src/lib/gone.go"})
+	u.AddFile(&spb.VName{Corpus: "other", Path: "b.go"})
+	u.Commit()
+	return m
+}
+
+func TestConsistent(t *testing.T) {
+	ix := testIndex()
+	c := &Checker{FileTree: ix.FileTree(), XRefs: ix.XRefs(), MaxTicketsPerRequest: 1}
+	report, err := c.Check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() {
+		t.Errorf("Check: got discrepancies %+v", report.Corpora)
+	}
+	if len(report.Corpora) != 2 {
+		t.Fatalf("Check: got %d corpora, want 2", len(report.Corpora))
+	}
+	if r := report.Corpora[0]; r.Corpus != "corpus" || r.Files != 1 || r.Targets != 2 {
+		t.Errorf("Check of corpus: got %+v, want 1 file and 2 targets", r)
+	}
+	if r := report.Corpora[1]; r.Corpus != "other" || r.Files != 1 || r.Targets != 0 {
+		t.Errorf("Check of other: got %+v, want 1 file and no targets", r)
+	}
+}
+
+func TestDiscrepancies(t *testing.T) {
+	ix := testIndex()
+	tests := []struct {
+		name    string
+		checker *Checker
+		want    map[Kind]int
+	}{{
+		name:    "missing decorations",
+		checker: &Checker{FileTree: treeWithExtraFile(), XRefs: ix.XRefs()},
+		want:    map[Kind]int{MissingDecorations: 1},
+	}, {
+		name:    "missing cross-references",
+		checker: &Checker{FileTree: ix.FileTree(), XRefs: &brokenXRefs{Service: ix.XRefs(), dropped: gTicket}},
+		want:    map[Kind]int{MissingCrossReferences: 1},
+	}, {
+		name:    "truncated text",
+		checker: &Checker{FileTree: ix.FileTree(), XRefs: &brokenXRefs{Service: ix.XRefs(), text: "package a\n"}},
+		// Each of the three anchors lies beyond the truncated text.
+		want: map[Kind]int{SpanOutOfBounds: 3},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := test.checker.Check(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.OK() {
+				t.Fatal("Check: got OK, want discrepancies")
+			}
+			r := report.Corpora[0]
+			if len(r.Counts) != len(test.want) {
+				t.Errorf("Counts: got %v, want %v", r.Counts, test.want)
+			}
+			for k, n := range test.want {
+				if r.Counts[k] != n {
+					t.Errorf("Counts[%s]: got %d, want %d", k, r.Counts[k], n)
+				}
+			}
+			for _, d := range r.Discrepancies {
+				if !strings.HasPrefix(d.File, "kythe://corpus") {
+					t.Errorf("Discrepancy %v: reported in the wrong corpus", d)
+				}
+			}
+			if !report.Corpora[1].OK() {
+				t.Errorf("Check of other: got %+v, want no discrepancies", report.Corpora[1])
+			}
+		})
+	}
+}
+
+func TestOptions(t *testing.T) {
+	ix := testIndex()
+	c := &Checker{
+		FileTree:         ix.FileTree(),
+		XRefs:            &brokenXRefs{Service: ix.XRefs(), text: "package a\n"},
+		Corpora:          []string{"corpus"},
+		MaxDiscrepancies: 1,
+	}
+	report, err := c.Check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Corpora) != 1 || report.Corpora[0].Corpus != "corpus" {
+		t.Fatalf("Check: got %+v, want only corpus", report.Corpora)
+	}
+	if r := report.Corpora[0]; len(r.Discrepancies) != 1 || r.Counts[SpanOutOfBounds] != 3 {
+		t.Errorf("Check: got %d discrepancies of %v, want 1 of 3", len(r.Discrepancies), r.Counts)
+	}
+}
@@ -20,6 +20,8 @@
 //
 //	dirs:<corpus>\n<root>\n<path> -> srvpb.FileDirectory
 //	dirs:corpusRoots              -> srvpb.CorpusRoots
+//
+// The root and path of each key are in Unicode normalization form C.
 package filetree // import "kythe.io/kythe/go/serving/filetree"
 
 import (
@@ -29,10 +31,13 @@ import (
 	"path/filepath"
 	"strings"
 
+	"kythe.io/kythe/go/services/filetree"
 	"kythe.io/kythe/go/storage/table"
 	"kythe.io/kythe/go/util/kytheuri"
 	"kythe.io/kythe/go/util/log"
 
+	"golang.org/x/text/unicode/norm"
+
 	ftpb "kythe.io/kythe/proto/filetree_go_proto"
 	srvpb "kythe.io/kythe/proto/serving_go_proto"
 )
@@ -64,14 +69,18 @@ type Table struct {
 
 // Directory implements part of the filetree Service interface.
 func (t *Table) Directory(ctx context.Context, req *ftpb.DirectoryRequest) (*ftpb.DirectoryReply, error) {
-	var key []byte
+	var prefix string
 	if t.PrefixedKeys {
-		key = PrefixedDirKey(req.Corpus, req.Root, req.Path)
-	} else {
-		key = DirKey(req.Corpus, req.Root, req.Path)
+		prefix = DirTablePrefix
 	}
 	var d srvpb.FileDirectory
-	if err := t.Lookup(ctx, key, &d); err == table.ErrNoSuchKey {
+	err := t.Lookup(ctx, dirKey(prefix, req.Corpus, norm.NFC.String(req.Root), norm.NFC.String(req.Path)), &d)
+	if err == table.ErrNoSuchKey && !(norm.NFC.IsNormalString(req.Root) && norm.NFC.IsNormalString(req.Path)) {
+		// Tables built before their keys were normalized hold paths as they
+		// were indexed.
+		err = t.Lookup(ctx, dirKey(prefix, req.Corpus, req.Root, req.Path), &d)
+	}
+	if err == table.ErrNoSuchKey {
 		return &ftpb.DirectoryReply{}, nil
 	} else if err != nil {
 		return nil, fmt.Errorf("lookup error: %v", err)
@@ -86,6 +95,9 @@ func (t *Table) Directory(ctx context.Context, req *ftpb.DirectoryRequest) (*ftp
 			BuildConfig: e.BuildConfig,
 			Generated:   e.GetGenerated(),
 			MissingText: e.GetMissingText(),
+			Language:    e.GetLanguage(),
+			Encoding:    e.GetEncoding(),
+			Size:        e.GetSize(),
 		}
 		switch e.Kind {
 		case srvpb.FileDirectory_FILE:
@@ -98,7 +110,7 @@ func (t *Table) Directory(ctx context.Context, req *ftpb.DirectoryRequest) (*ftp
 		}
 		entries = append(entries, re)
 	}
-	entries, err := parseLegacyEntries(entries, ftpb.DirectoryReply_FILE, d.FileTicket)
+	entries, err = parseLegacyEntries(entries, ftpb.DirectoryReply_FILE, d.FileTicket)
 	if err != nil {
 		return nil, err
 	}
@@ -106,12 +118,12 @@ func (t *Table) Directory(ctx context.Context, req *ftpb.DirectoryRequest) (*ftp
 	if err != nil {
 		return nil, err
 	}
-	return &ftpb.DirectoryReply{
+	return filetree.PageEntries(req, &ftpb.DirectoryReply{
 		Corpus: req.Corpus,
 		Root:   req.Root,
 		Path:   req.Path,
 		Entry:  entries,
-	}, nil
+	})
 }
 
 func parseLegacyEntries(entries []*ftpb.DirectoryReply_Entry, kind ftpb.DirectoryReply_Kind, tickets []string) ([]*ftpb.DirectoryReply_Entry, error) {
@@ -129,6 +141,16 @@ func parseLegacyEntries(entries []*ftpb.DirectoryReply_Entry, kind ftpb.Director
 	return entries, nil
 }
 
+// Tree implements part of the filetree Service interface.
+func (t *Table) Tree(ctx context.Context, req *ftpb.TreeRequest) (*ftpb.DirectoryReply, error) {
+	return filetree.Tree(ctx, t.Directory, req)
+}
+
+// Search implements part of the filetree Service interface.
+func (t *Table) Search(ctx context.Context, req *ftpb.SearchRequest) (*ftpb.SearchReply, error) {
+	return filetree.Search(ctx, t.Directory, req)
+}
+
 // CorpusRoots implements part of the filetree Service interface.
 func (t *Table) CorpusRoots(ctx context.Context, req *ftpb.CorpusRootsRequest) (*ftpb.CorpusRootsReply, error) {
 	key := CorpusRootsKey
@@ -157,13 +179,55 @@ func (t *Table) CorpusRoots(ctx context.Context, req *ftpb.CorpusRootsRequest) (
 	return reply, nil
 }
 
+// Write writes the directories and corpus roots of tree to out, with keys
+// prefixed by DirTablePrefix as in a combined serving table.  A Table with
+// PrefixedKeys serves them.
+func Write(ctx context.Context, tree *filetree.Map, out table.Proto) error {
+	buffer := out.Buffered()
+	if err := tree.Walk(ctx, func(dir *ftpb.DirectoryReply) error {
+		fd := &srvpb.FileDirectory{}
+		for _, e := range dir.Entry {
+			kind := srvpb.FileDirectory_UNKNOWN
+			switch e.Kind {
+			case ftpb.DirectoryReply_FILE:
+				kind = srvpb.FileDirectory_FILE
+			case ftpb.DirectoryReply_DIRECTORY:
+				kind = srvpb.FileDirectory_DIRECTORY
+			}
+			fd.Entry = append(fd.Entry, &srvpb.FileDirectory_Entry{
+				Kind:      kind,
+				Name:      e.Name,
+				Generated: e.Generated,
+				Language:  e.Language,
+				Encoding:  e.Encoding,
+				Size:      e.Size,
+			})
+		}
+		return buffer.Put(ctx, PrefixedDirKey(dir.Corpus, dir.Root, dir.Path), fd)
+	}); err != nil {
+		return err
+	}
+	cr, err := tree.CorpusRoots(ctx, &ftpb.CorpusRootsRequest{})
+	if err != nil {
+		return err
+	}
+	if err := buffer.Put(ctx, CorpusRootsPrefixedKey, cr); err != nil {
+		return err
+	}
+	return buffer.Flush(ctx)
+}
+
 // DirKey returns the filetree lookup table key for the given corpus path.
 func DirKey(corpus, root, path string) []byte {
-	return []byte(strings.Join([]string{corpus, root, path}, dirKeySep))
+	return dirKey("", corpus, norm.NFC.String(root), norm.NFC.String(path))
 }
 
 // PrefixedDirKey returns the filetree lookup table key for the given corpus
 // path, prefixed by DirTablePrefix.
 func PrefixedDirKey(corpus, root, path string) []byte {
-	return []byte(DirTablePrefix + strings.Join([]string{corpus, root, path}, dirKeySep))
+	return dirKey(DirTablePrefix, corpus, norm.NFC.String(root), norm.NFC.String(path))
+}
+
+func dirKey(prefix, corpus, root, path string) []byte {
+	return []byte(prefix + strings.Join([]string{corpus, root, path}, dirKeySep))
 }
@@ -0,0 +1,188 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filetree
+
+import (
+	"context"
+	"testing"
+
+	"kythe.io/kythe/go/services/filetree"
+	"kythe.io/kythe/go/storage/inmemory"
+	"kythe.io/kythe/go/storage/table"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+	srvpb "kythe.io/kythe/proto/serving_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestWrite(t *testing.T) {
+	ctx := context.Background()
+	m := filetree.NewMap()
+	for _, path := range []string{"a/b/c.go", "a/d.go"} {
+		m.AddFile(&spb.VName{Corpus: "corpus", Path: path})
+	}
+	m.AddFile(&spb.VName{Corpus: "corpus", Root: "gen", Path: "a/e.go"})
+
+	tbl := &table.KVProto{DB: inmemory.NewKeyValueDB()}
+	if err := Write(ctx, m, tbl); err != nil {
+		t.Fatal(err)
+	}
+	ft := &Table{Proto: tbl, PrefixedKeys: true}
+
+	// The table serves the same replies as the map it was written from.
+	for _, req := range []*ftpb.DirectoryRequest{
+		{Corpus: "corpus"},
+		{Corpus: "corpus", Path: "a"},
+		{Corpus: "corpus", Path: "a/b"},
+		{Corpus: "corpus", Root: "gen", Path: "a"},
+		{Corpus: "corpus", Path: "a", PageSize: 1},
+		{Corpus: "corpus", Path: "a", PageToken: "b/"},
+	} {
+		want, err := m.Directory(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ft.Directory(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+			t.Errorf("Directory(%v) (-want +got):\n%s", req, diff)
+		}
+	}
+	treeReq := &ftpb.TreeRequest{Corpus: "corpus"}
+	wantTree, err := m.Tree(ctx, treeReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotTree, err := ft.Tree(ctx, treeReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(wantTree, gotTree, protocmp.Transform()); diff != "" {
+		t.Errorf("Tree(%v) (-want +got):\n%s", treeReq, diff)
+	}
+	searchReq := &ftpb.SearchRequest{Corpus: "corpus", Pattern: "**/*.go", Syntax: ftpb.SearchRequest_GLOB}
+	wantSearch, err := m.Search(ctx, searchReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotSearch, err := ft.Search(ctx, searchReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wantSearch.Ticket) == 0 {
+		t.Errorf("Search(%v): no results", searchReq)
+	}
+	if diff := cmp.Diff(wantSearch, gotSearch, protocmp.Transform()); diff != "" {
+		t.Errorf("Search(%v) (-want +got):\n%s", searchReq, diff)
+	}
+
+	want, err := m.CorpusRoots(ctx, &ftpb.CorpusRootsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ft.CorpusRoots(ctx, &ftpb.CorpusRootsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("CorpusRoots (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteFileFacts(t *testing.T) {
+	ctx := context.Background()
+	m := filetree.NewMap()
+	goFile := &spb.VName{Corpus: "corpus", Path: "a/b.go", Language: "go"}
+	u := m.Update()
+	u.AddFile(goFile)
+	u.SetFileFact(goFile, facts.Text, []byte("package a\n"))
+	u.SetFileFact(goFile, facts.TextEncoding, []byte("UTF-8"))
+	u.AddFile(&spb.VName{Corpus: "corpus", Path: "a/c.txt"})
+	u.Commit()
+
+	tbl := &table.KVProto{DB: inmemory.NewKeyValueDB()}
+	if err := Write(ctx, m, tbl); err != nil {
+		t.Fatal(err)
+	}
+	ft := &Table{Proto: tbl, PrefixedKeys: true}
+
+	// The language, encoding, and size of each file survive the table.
+	got, err := ft.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", Path: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*ftpb.DirectoryReply_Entry{
+		{Kind: ftpb.DirectoryReply_FILE, Name: "b.go", Language: "go", Encoding: "UTF-8", Size: 10},
+		{Kind: ftpb.DirectoryReply_FILE, Name: "c.txt"},
+	}
+	if diff := cmp.Diff(want, got.Entry, protocmp.Transform()); diff != "" {
+		t.Errorf("Directory (-want +got):\n%s", diff)
+	}
+}
+
+func TestDirectoryUnicode(t *testing.T) {
+	ctx := context.Background()
+	const (
+		nfc = "ソース/caf\u00e9"  // é precomposed
+		nfd = "ソース/cafe\u0301" // e and a combining acute accent
+	)
+	for _, prefixed := range []bool{false, true} {
+		tbl := &table.KVProto{DB: inmemory.NewKeyValueDB()}
+		put := func(key []byte, name string) {
+			t.Helper()
+			fd := &srvpb.FileDirectory{Entry: []*srvpb.FileDirectory_Entry{{Kind: srvpb.FileDirectory_FILE, Name: name}}}
+			if err := tbl.Put(ctx, key, fd); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if prefixed {
+			put(PrefixedDirKey("corpus", "", nfd), "新.go")
+			put([]byte(DirTablePrefix+"corpus\n\nold/cafe\u0301"), "旧.go")
+		} else {
+			put(DirKey("corpus", "", nfd), "新.go")
+			put([]byte("corpus\n\nold/cafe\u0301"), "旧.go")
+		}
+		ft := &Table{Proto: tbl, PrefixedKeys: prefixed}
+
+		for _, test := range []struct{ path, want string }{
+			{nfc, "新.go"},
+			{nfd, "新.go"},
+			// A key written before keys were normalized is found by its
+			// original spelling.
+			{"old/cafe\u0301", "旧.go"},
+		} {
+			got, err := ft.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", Path: test.path})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := &ftpb.DirectoryReply{
+				Corpus: "corpus",
+				Path:   test.path,
+				Entry:  []*ftpb.DirectoryReply_Entry{{Kind: ftpb.DirectoryReply_FILE, Name: test.want}},
+			}
+			if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Directory(%q) prefixed=%v (-want +got):\n%s", test.path, prefixed, diff)
+			}
+		}
+	}
+}
@@ -105,6 +105,8 @@ var (
 			Ticket: "kythe://c?path=/a/path",
 			Fact: makeFactList(
 				"/kythe/node/kind", "file",
+				"/kythe/provenance/commit", "abc123",
+				"/kythe/provenance/toolchain", "go1.0",
 				"/kythe/text/encoding", "utf-8",
 				"/kythe/text", "some random text\nhere and  \n  there\nsome random text\nhere and  \n  there\n",
 			),
@@ -294,6 +296,25 @@ func TestNodes(t *testing.T) {
 	}
 }
 
+func TestNodesFilter(t *testing.T) {
+	st := tbl.Construct(t)
+	reply, err := st.Nodes(ctx, &gpb.NodesRequest{
+		Ticket: []string{"kythe://c?path=/a/path", "kythe://c?lang=otpl?path=/a/path#map"},
+		Filter: []string{"/kythe/provenance/*"},
+	})
+	testutil.Fatalf(t, "NodesRequest error: %v", err)
+
+	expected := map[string]*cpb.NodeInfo{
+		"kythe://c?path=/a/path": {Facts: map[string][]byte{
+			"/kythe/provenance/commit":    []byte("abc123"),
+			"/kythe/provenance/toolchain": []byte("go1.0"),
+		}},
+	}
+	if err := testutil.DeepEqual(expected, reply.Nodes); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestNodesMissing(t *testing.T) {
 	st := tbl.Construct(t)
 	reply, err := st.Nodes(ctx, &gpb.NodesRequest{
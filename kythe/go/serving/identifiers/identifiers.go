@@ -159,12 +159,12 @@ func RegisterHTTPHandlers(ctx context.Context, id Service, mux *http.ServeMux) {
 		}()
 		var req ipb.FindRequest
 		if err := web.ReadJSONBody(r, &req); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			web.WriteError(w, err, http.StatusBadRequest)
 			return
 		}
-		reply, err := id.Find(ctx, &req)
+		reply, err := id.Find(r.Context(), &req)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			web.WriteError(w, err, http.StatusInternalServerError)
 			return
 		}
 
@@ -174,17 +174,27 @@ func RegisterHTTPHandlers(ctx context.Context, id Service, mux *http.ServeMux) {
 	})
 }
 
-type webClient struct{ addr string }
+type webClient struct {
+	addr   string
+	client *web.Client
+}
 
 func (webClient) Close(context.Context) error { return nil }
 
 // Find implements part of the Service interface.
 func (w *webClient) Find(ctx context.Context, q *ipb.FindRequest) (*ipb.FindReply, error) {
 	var reply ipb.FindReply
-	return &reply, web.Call(w.addr, "find_identifier", q, &reply)
+	return &reply, w.client.CallContext(ctx, w.addr, "find_identifier", q, &reply)
 }
 
 // WebClient returns an identifiers Service based on a remote web server.
 func WebClient(addr string) Service {
-	return &webClient{addr}
+	return WebClientWithOptions(addr, nil)
+}
+
+// WebClientWithOptions returns an identifiers Service based on a remote web
+// server, called with the given options.  If opts == nil, default options are
+// used.
+func WebClientWithOptions(addr string, opts *web.Options) Service {
+	return &webClient{addr, web.NewClient(opts)}
 }
@@ -45,7 +45,6 @@ import (
 
 	"google.golang.org/protobuf/proto"
 
-	ftpb "kythe.io/kythe/proto/filetree_go_proto"
 	ipb "kythe.io/kythe/proto/internal_go_proto"
 	srvpb "kythe.io/kythe/proto/serving_go_proto"
 	spb "kythe.io/kythe/proto/storage_go_proto"
@@ -155,10 +154,11 @@ func combineNodesAndEdges(ctx context.Context, opts *Options, out *servingOutput
 	log.InfoContext(ctx, "Writing partial edges")
 
 	tree := filetree.NewMap()
+	update := tree.Update()
 	rd := func(f func(*spb.Entry) error) error {
 		return rdIn(func(e *spb.Entry) error {
 			if e.FactName == facts.NodeKind && string(e.FactValue) == nodes.File {
-				tree.AddFile(e.Source)
+				update.AddFile(e.Source)
 				// TODO(schroederc): evict finished directories (based on GraphStore order)
 			}
 			return f(e)
@@ -173,10 +173,12 @@ func combineNodesAndEdges(ctx context.Context, opts *Options, out *servingOutput
 	if err := assemble.Sources(rd, func(src *ipb.Source) error {
 		return writePartialEdges(ctx, partialSorter, src)
 	}); err != nil {
+		update.Commit()
 		return nil, err
 	}
+	update.Commit()
 
-	if err := writeFileTree(ctx, tree, out.xs); err != nil {
+	if err := ftsrv.Write(ctx, tree, out.xs); err != nil {
 		return nil, fmt.Errorf("error writing file tree: %v", err)
 	}
 	tree = nil
@@ -220,41 +222,6 @@ func combineNodesAndEdges(ctx context.Context, opts *Options, out *servingOutput
 	return cSorter, nil
 }
 
-func writeFileTree(ctx context.Context, tree *filetree.Map, out table.Proto) error {
-	buffer := out.Buffered()
-	for corpus, roots := range tree.M {
-		for root, dirs := range roots {
-			for path, dir := range dirs {
-				fd := &srvpb.FileDirectory{}
-				for _, e := range dir.Entry {
-					kind := srvpb.FileDirectory_UNKNOWN
-					switch e.Kind {
-					case ftpb.DirectoryReply_FILE:
-						kind = srvpb.FileDirectory_FILE
-					case ftpb.DirectoryReply_DIRECTORY:
-						kind = srvpb.FileDirectory_DIRECTORY
-					}
-					fd.Entry = append(fd.Entry, &srvpb.FileDirectory_Entry{
-						Kind: kind,
-						Name: e.Name,
-					})
-				}
-				if err := buffer.Put(ctx, ftsrv.PrefixedDirKey(corpus, root, path), fd); err != nil {
-					return err
-				}
-			}
-		}
-	}
-	cr, err := tree.CorpusRoots(ctx, &ftpb.CorpusRootsRequest{})
-	if err != nil {
-		return err
-	}
-	if err := buffer.Put(ctx, ftsrv.CorpusRootsPrefixedKey, cr); err != nil {
-		return err
-	}
-	return buffer.Flush(ctx)
-}
-
 func filterReverses(rd stream.EntryReader) stream.EntryReader {
 	return func(f func(*spb.Entry) error) error {
 		return rd(func(e *spb.Entry) error {
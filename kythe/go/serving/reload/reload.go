@@ -0,0 +1,256 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package reload swaps serving data for a newer version without dropping
+// in-flight requests.
+//
+// A Handle holds the current version of a resource, such as the services
+// backed by a serving table.  Requests acquire the current version and release
+// it when done.  A reload loads the new version next to the old one, publishes
+// it to new requests atomically, and closes the old version once the last
+// request using it has released it.
+package reload // import "kythe.io/kythe/go/serving/reload"
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"kythe.io/kythe/go/util/audit"
+	"kythe.io/kythe/go/util/log"
+)
+
+// A LoadFunc loads a new version of a resource.  It returns the resource and
+// a function to release its underlying resources once it is no longer used.
+type LoadFunc[T any] func(ctx context.Context) (T, func() error, error)
+
+// A Handle holds the current version of a reloadable resource.
+type Handle[T any] struct {
+	load LoadFunc[T]
+	cur  atomic.Pointer[version[T]]
+
+	mu        sync.Mutex // serializes reloads
+	onPublish []func(context.Context, T)
+
+	// The number of successful reloads, read without mu so that it can be
+	// reported while a reload waits for requests to the old version.
+	reloads atomic.Int64
+
+	audit       *audit.Log
+	auditTarget string
+}
+
+// A version is one loaded version of a resource.  Requests hold a read lock
+// on mu while they use it; retiring the version takes the write lock, so it
+// waits for every request to finish.
+type version[T any] struct {
+	val   T
+	close func() error
+
+	mu      sync.RWMutex
+	retired bool
+}
+
+// New loads the initial version of a resource with load, and returns a Handle
+// to it.
+func New[T any](ctx context.Context, load LoadFunc[T]) (*Handle[T], error) {
+	h := &Handle[T]{load: load}
+	v, err := h.loadVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	h.cur.Store(v)
+	return h, nil
+}
+
+func (h *Handle[T]) loadVersion(ctx context.Context) (*version[T], error) {
+	val, closeFunc, err := h.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if closeFunc == nil {
+		closeFunc = func() error { return nil }
+	}
+	return &version[T]{val: val, close: closeFunc}, nil
+}
+
+// Acquire returns the current version of the resource, and a function that
+// must be called once the caller has finished using it.  A version remains
+// open until every caller that acquired it has released it.
+func (h *Handle[T]) Acquire() (T, func()) {
+	for {
+		v := h.cur.Load()
+		v.mu.RLock()
+		if !v.retired {
+			return v.val, v.mu.RUnlock
+		}
+		// v was replaced and retired after it was loaded; use its successor.
+		v.mu.RUnlock()
+	}
+}
+
+// Reload loads a new version of the resource and makes it current.  The
+// previous version is closed once it has been released by every request that
+// acquired it; Reload waits for this.  If loading fails, the current version
+// is kept.  Concurrent reloads are serialized.
+func (h *Handle[T]) Reload(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	start := time.Now()
+	err := h.reload(ctx, start)
+	if aerr := h.audit.RecordContext(ctx, "reload", h.auditTarget, map[string]any{
+		"duration": time.Since(start).Round(time.Millisecond).String(),
+	}, err); aerr != nil {
+		log.ErrorContextf(ctx, "Recording reload: %v", aerr)
+	}
+	return err
+}
+
+func (h *Handle[T]) reload(ctx context.Context, start time.Time) error {
+	v, err := h.loadVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("loading new version: %v", err)
+	}
+	old := h.cur.Swap(v)
+	h.reloads.Add(1)
+	for _, f := range h.onPublish {
+		f(ctx, v.val)
+	}
+	log.InfoContextf(ctx, "Loaded new version in %s; waiting for requests to the old version", time.Since(start))
+	return old.retire()
+}
+
+// Audit records each later reload of h, and who requested it (see
+// audit.WithActor), in l as an action on target.
+func (h *Handle[T]) Audit(l *audit.Log, target string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.audit, h.auditTarget = l, target
+}
+
+// OnPublish registers f to be called with each version of the resource made
+// current by a later reload, as soon as it is current and before the previous
+// version is closed.  Since f is called while reloads are serialized, it must
+// not reload h, and should return promptly.
+func (h *Handle[T]) OnPublish(f func(ctx context.Context, val T)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onPublish = append(h.onPublish, f)
+}
+
+// Reloads returns the number of successful reloads of h.  It does not wait
+// for a reload in progress, so it may be called by a request that holds a
+// version of the resource.
+func (h *Handle[T]) Reloads() int { return int(h.reloads.Load()) }
+
+// Close closes the current version of the resource, once it has been
+// released.  The Handle must not be used afterwards.
+func (h *Handle[T]) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.cur.Load().retire()
+}
+
+func (v *version[T]) retire() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.retired = true
+	return v.close()
+}
+
+// OnSignal reloads h each time the process receives SIGHUP, until ctx is
+// done.  Failures are logged.
+func (h *Handle[T]) OnSignal(ctx context.Context) {
+	ctx = audit.WithActor(ctx, "signal:SIGHUP")
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				log.InfoContext(ctx, "Reloading on SIGHUP")
+				if err := h.Reload(ctx); err != nil {
+					log.ErrorContextf(ctx, "Reload failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Watch polls path at the given interval until ctx is done, and reloads h
+// whenever path changes: when the file or directory it names (following
+// symlinks) changes, or when a regular file is modified.  Since a directory
+// (such as a LevelDB table) may change while it is served, data is best
+// published by writing it to a new location and then repointing a symlink at
+// path to it.  Failures are logged, and the reload retried at the next poll.
+func (h *Handle[T]) Watch(ctx context.Context, path string, interval time.Duration) {
+	ctx = audit.WithActor(ctx, "watch:"+path)
+	last, _ := stamp(path)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			cur, err := stamp(path)
+			if err != nil {
+				log.WarningContextf(ctx, "Watching %s: %v", path, err)
+				continue
+			} else if cur.target == last.target && cur.modTime.Equal(last.modTime) {
+				continue
+			}
+			log.InfoContextf(ctx, "Reloading after change to %s", path)
+			if err := h.Reload(ctx); err != nil {
+				log.ErrorContextf(ctx, "Reload failed: %v", err)
+				continue
+			}
+			last = cur
+		}
+	}()
+}
+
+// A fileStamp identifies a version of a file.
+type fileStamp struct {
+	target  string
+	modTime time.Time // zero for a directory
+}
+
+func stamp(path string) (fileStamp, error) {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	fi, err := os.Stat(target)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	if fi.IsDir() {
+		return fileStamp{target: target}, nil
+	}
+	return fileStamp{target, fi.ModTime()}, nil
+}
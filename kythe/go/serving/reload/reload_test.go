@@ -0,0 +1,260 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reload
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kythe.io/kythe/go/util/audit"
+)
+
+type resource struct {
+	gen    int
+	closed atomic.Bool
+}
+
+// loader returns a LoadFunc that loads successive generations of resource.
+func loader(fail *bool) LoadFunc[*resource] {
+	var gen int
+	return func(context.Context) (*resource, func() error, error) {
+		if *fail {
+			return nil, nil, errors.New("load failed")
+		}
+		gen++
+		r := &resource{gen: gen}
+		return r, func() error {
+			r.closed.Store(true)
+			return nil
+		}, nil
+	}
+}
+
+func TestReload(t *testing.T) {
+	ctx := context.Background()
+	var fail bool
+	load := loader(&fail)
+	h, err := New(ctx, load)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A request in flight keeps using its version across a reload, which
+	// waits for the request to finish before closing it.
+	old, release := h.Acquire()
+	reloaded := make(chan error)
+	go func() { reloaded <- h.Reload(ctx) }()
+	for {
+		if cur, rel := h.Acquire(); cur.gen == 2 {
+			rel()
+			break
+		} else {
+			rel()
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if old.closed.Load() {
+		t.Error("Old version closed while in use")
+	}
+	release()
+	if err := <-reloaded; err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if !old.closed.Load() {
+		t.Error("Old version not closed after release")
+	}
+
+	// A failed load keeps the current version.
+	fail = true
+	if err := h.Reload(ctx); err == nil {
+		t.Error("Reload with failing load: got nil error")
+	}
+	cur, rel := h.Acquire()
+	if cur.gen != 2 || cur.closed.Load() {
+		t.Errorf("Version after failed reload: got gen %d (closed %v), want open gen 2", cur.gen, cur.closed.Load())
+	}
+	rel()
+	if got := h.Reloads(); got != 1 {
+		t.Errorf("Reloads: got %d, want 1", got)
+	}
+
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !cur.closed.Load() {
+		t.Error("Current version not closed by Close")
+	}
+}
+
+func TestReloadsDuringReload(t *testing.T) {
+	ctx := context.Background()
+	var fail bool
+	h, err := New(ctx, loader(&fail))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	// A status request holds the current version while it reads the number
+	// of reloads, so a reload waiting for it to finish must not block it.
+	_, release := h.Acquire()
+	reloaded := make(chan error)
+	go func() { reloaded <- h.Reload(ctx) }()
+	counted := make(chan int)
+	go func() {
+		for h.Reloads() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		counted <- h.Reloads()
+	}()
+	select {
+	case n := <-counted:
+		if n != 1 {
+			t.Errorf("Reloads during reload: got %d, want 1", n)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Reloads blocked by a reload waiting for the caller")
+	}
+	release()
+	if err := <-reloaded; err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+}
+
+func TestOnPublish(t *testing.T) {
+	ctx := context.Background()
+	var fail bool
+	h, err := New(ctx, loader(&fail))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	var published []int
+	h.OnPublish(func(_ context.Context, r *resource) {
+		cur, release := h.Acquire()
+		release()
+		if cur != r {
+			t.Errorf("Published gen %d while gen %d is current", r.gen, cur.gen)
+		}
+		published = append(published, r.gen)
+	})
+	for i := 0; i < 2; i++ {
+		if err := h.Reload(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fail = true
+	h.Reload(ctx)
+	if want := []int{2, 3}; len(published) != 2 || published[0] != want[0] || published[1] != want[1] {
+		t.Errorf("Published versions: got %v, want %v", published, want)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dir := t.TempDir()
+	for _, name := range []string{"v1", "v2"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := filepath.Join(dir, "current")
+	if err := os.Symlink("v1", link); err != nil {
+		t.Fatal(err)
+	}
+
+	var fail bool
+	load := loader(&fail)
+	h, err := New(ctx, load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Watch(ctx, link, time.Millisecond)
+
+	// Changes within the served directory do not cause a reload.
+	if err := os.WriteFile(filepath.Join(dir, "v1", "LOG"), []byte("log"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := h.Reloads(); got != 0 {
+		t.Fatalf("Reloads after write to served directory: got %d, want 0", got)
+	}
+
+	// Repointing the symlink does.
+	next := filepath.Join(dir, "next")
+	if err := os.Symlink("v2", next); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(next, link); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for h.Reloads() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("No reload after symlink change")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	ctx := context.Background()
+	var fail bool
+	h, err := New(ctx, loader(&fail))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := audit.Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Audit(l, "/srv/table")
+
+	if err := h.Reload(audit.WithActor(ctx, "alice")); err != nil {
+		t.Fatal(err)
+	}
+	fail = true
+	if err := h.Reload(audit.WithActor(ctx, "bob")); err == nil {
+		t.Fatal("Reload succeeded; want failure")
+	}
+	l.Close()
+
+	var events []*audit.Event
+	if err := audit.Read(path, nil, func(e *audit.Event) error {
+		events = append(events, e)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Audit events: got %v; want 2", events)
+	}
+	if e := events[0]; e.Actor != "alice" || e.Action != "reload" || e.Target != "/srv/table" || e.Error != "" {
+		t.Errorf("First event: got %v", e)
+	}
+	if e := events[1]; e.Actor != "bob" || e.Error == "" {
+		t.Errorf("Second event: got %v; want a failure by bob", e)
+	}
+}
@@ -0,0 +1,212 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package replay records the requests made to a server and replays them
+// against another, to load test a new release with the shape of production
+// traffic.
+//
+// A Recorder is HTTP middleware that writes each request it passes on as a
+// JSON Record, one per line.  Records are sanitized: they keep no headers
+// but the Content-Type, and may omit query parameters and whole paths (by
+// default, the /admin/ endpoints).  Replay reissues recorded requests at a
+// fixed rate or at the pace at which they were recorded, and summarizes the
+// status and latency of the replies.
+package replay // import "kythe.io/kythe/go/serving/replay"
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"kythe.io/kythe/go/util/log"
+)
+
+// A Record is a recorded request.
+type Record struct {
+	Time        time.Time       `json:"time"`
+	Method      string          `json:"method"`
+	URL         string          `json:"url"` // the path and query of the request
+	ContentType string          `json:"content_type,omitempty"`
+	JSON        json.RawMessage `json:"json,omitempty"` // the body, if it is JSON
+	Body        []byte          `json:"body,omitempty"` // any other body
+}
+
+// body returns the body of the request.
+func (r *Record) body() []byte {
+	if len(r.JSON) > 0 {
+		return r.JSON
+	}
+	return r.Body
+}
+
+// DefaultExclude are the path prefixes of the requests that a Recorder does
+// not record by default.
+var DefaultExclude = []string{"/admin/"}
+
+// DefaultMaxBodySize is the default for RecordOptions.MaxBodySize.
+const DefaultMaxBodySize = 1 << 20
+
+// RecordOptions control a Recorder.
+type RecordOptions struct {
+	// SampleRate is the fraction of requests recorded.  If zero, every
+	// request is recorded.
+	SampleRate float64
+
+	// Exclude are the path prefixes of requests not to record.  If nil,
+	// DefaultExclude is used.
+	Exclude []string
+
+	// RedactParams are the names of query parameters removed from recorded
+	// URLs.
+	RedactParams []string
+
+	// MaxBodySize bounds the body of recorded requests; larger requests are
+	// not recorded.  If zero, DefaultMaxBodySize is used.
+	MaxBodySize int64
+
+	// Sanitize, if set, is applied to each record before it is written, and
+	// may modify it.  If it returns false, the record is dropped.
+	Sanitize func(*Record) bool
+}
+
+// A Recorder is middleware that records requests.
+type Recorder struct {
+	opts RecordOptions
+
+	mu     sync.Mutex
+	enc    *json.Encoder
+	failed bool
+
+	recorded, skipped atomic.Int64
+}
+
+// NewRecorder returns a Recorder writing records to w.  The options may be
+// nil.
+func NewRecorder(w io.Writer, opts *RecordOptions) *Recorder {
+	r := &Recorder{enc: json.NewEncoder(w)}
+	if opts != nil {
+		r.opts = *opts
+	}
+	if r.opts.Exclude == nil {
+		r.opts.Exclude = DefaultExclude
+	}
+	if r.opts.MaxBodySize <= 0 {
+		r.opts.MaxBodySize = DefaultMaxBodySize
+	}
+	return r
+}
+
+// Recorded returns the number of requests recorded.
+func (r *Recorder) Recorded() int64 { return r.recorded.Load() }
+
+// Skipped returns the number of requests eligible for recording that were
+// not recorded, because they were too large or could not be written.
+func (r *Recorder) Skipped() int64 { return r.skipped.Load() }
+
+// Wrap returns a handler that records the requests it passes to next.
+func (r *Recorder) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if r.eligible(req) {
+			r.record(req)
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (r *Recorder) eligible(req *http.Request) bool {
+	for _, prefix := range r.opts.Exclude {
+		if strings.HasPrefix(req.URL.Path, prefix) {
+			return false
+		}
+	}
+	return r.opts.SampleRate <= 0 || rand.Float64() < r.opts.SampleRate
+}
+
+// record writes a record of req, restoring its body for the next handler.
+func (r *Recorder) record(req *http.Request) {
+	rec := &Record{
+		Time:        time.Now().UTC(),
+		Method:      req.Method,
+		ContentType: req.Header.Get("Content-Type"),
+	}
+	u := *req.URL
+	if len(r.opts.RedactParams) > 0 {
+		q := u.Query()
+		for _, p := range r.opts.RedactParams {
+			q.Del(p)
+		}
+		u.RawQuery = q.Encode()
+	}
+	rec.URL = u.RequestURI()
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(io.LimitReader(req.Body, r.opts.MaxBodySize+1))
+		// Whatever was read is passed on, followed by the rest of the body.
+		req.Body = readCloser{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+		if err != nil || int64(len(body)) > r.opts.MaxBodySize {
+			r.skipped.Add(1)
+			return
+		}
+		if json.Valid(body) {
+			rec.JSON = body
+		} else {
+			rec.Body = body
+		}
+	}
+	if r.opts.Sanitize != nil && !r.opts.Sanitize(rec) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failed {
+		r.skipped.Add(1)
+		return
+	}
+	if err := r.enc.Encode(rec); err != nil {
+		log.Errorf("Recording requests failed; no more will be recorded: %v", err)
+		r.failed = true
+		r.skipped.Add(1)
+		return
+	}
+	r.recorded.Add(1)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// A Reader reads the records written by a Recorder.
+type Reader struct{ dec *json.Decoder }
+
+// NewReader returns a Reader of the records in r.
+func NewReader(r io.Reader) *Reader { return &Reader{json.NewDecoder(r)} }
+
+// Next returns the next record, or io.EOF once there are none.
+func (r *Reader) Next() (*Record, error) {
+	var rec Record
+	if err := r.dec.Decode(&rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
@@ -0,0 +1,216 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultConcurrency is the default for ReplayOptions.Concurrency.
+const DefaultConcurrency = 16
+
+// ReplayOptions control Replay.
+type ReplayOptions struct {
+	// Rate is the number of requests issued per second.  If zero, requests
+	// are issued at the pace at which they were recorded, scaled by Speed.
+	Rate float64
+
+	// Speed scales the recorded pace of requests when Rate is zero; 2
+	// replays requests twice as fast as they were recorded.  If zero, 1 is
+	// used.
+	Speed float64
+
+	// Concurrency bounds the number of requests in flight.  If zero,
+	// DefaultConcurrency is used.  Requests that cannot be issued at their
+	// time because of the bound are issued late and counted in Summary.Late.
+	Concurrency int
+
+	// Client issues the requests.  If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// A Summary describes the replies to replayed requests.
+type Summary struct {
+	Requests int         `json:"requests"`
+	Errors   int         `json:"errors"`         // requests that received no reply
+	Late     int         `json:"late,omitempty"` // requests issued late
+	Status   map[int]int `json:"status"`         // counts by reply status code
+
+	Elapsed time.Duration `json:"elapsed"`
+	P50     time.Duration `json:"p50"`
+	P90     time.Duration `json:"p90"`
+	P99     time.Duration `json:"p99"`
+	Max     time.Duration `json:"max"`
+}
+
+// String returns a human-readable summary.
+func (s *Summary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d requests in %v (%d errors, %d late)\n", s.Requests, s.Elapsed, s.Errors, s.Late)
+	codes := make([]int, 0, len(s.Status))
+	for code := range s.Status {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	for _, code := range codes {
+		fmt.Fprintf(&b, "  %d %s: %d\n", code, http.StatusText(code), s.Status[code])
+	}
+	fmt.Fprintf(&b, "latency: p50 %v, p90 %v, p99 %v, max %v", s.P50, s.P90, s.P99, s.Max)
+	return b.String()
+}
+
+// Replay issues the requests read from rd against the server at the given
+// base URL and summarizes their replies.  Replay stops early if ctx is
+// cancelled, returning a summary of the requests issued so far along with
+// the context's error.
+func Replay(ctx context.Context, server string, rd *Reader, opts *ReplayOptions) (*Summary, error) {
+	var o ReplayOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Speed <= 0 {
+		o.Speed = 1
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultConcurrency
+	}
+	if o.Client == nil {
+		o.Client = http.DefaultClient
+	}
+	server = strings.TrimSuffix(server, "/")
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		latencies []time.Duration
+		sum       = &Summary{Status: make(map[int]int)}
+		sem       = make(chan struct{}, o.Concurrency)
+		start     = time.Now()
+		first     time.Time
+		err       error
+	)
+	for i := 0; ; i++ {
+		var rec *Record
+		rec, err = rd.Next()
+		if err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			err = fmt.Errorf("reading record %d: %v", i, err)
+			break
+		}
+		if i == 0 {
+			first = rec.Time
+		}
+
+		var due time.Time
+		if o.Rate > 0 {
+			due = start.Add(time.Duration(float64(i) / o.Rate * float64(time.Second)))
+		} else {
+			due = start.Add(time.Duration(float64(rec.Time.Sub(first)) / o.Speed))
+		}
+		if err = sleepUntil(ctx, due); err != nil {
+			break
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		if err != nil {
+			break
+		}
+		late := time.Since(due) > 100*time.Millisecond
+
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			status, latency, err := issue(ctx, o.Client, server, rec)
+			mu.Lock()
+			defer mu.Unlock()
+			sum.Requests++
+			if late {
+				sum.Late++
+			}
+			if err != nil {
+				sum.Errors++
+				return
+			}
+			sum.Status[status]++
+			latencies = append(latencies, latency)
+		}()
+	}
+	wg.Wait()
+	sum.Elapsed = time.Since(start)
+
+	if n := len(latencies); n > 0 {
+		slices.Sort(latencies)
+		at := func(p int) time.Duration { return latencies[(n-1)*p/100] }
+		sum.P50, sum.P90, sum.P99, sum.Max = at(50), at(90), at(99), latencies[n-1]
+	}
+	return sum, err
+}
+
+// issue sends rec to the server and returns the status and latency of the
+// reply.
+func issue(ctx context.Context, c *http.Client, server string, rec *Record) (int, time.Duration, error) {
+	var body io.Reader
+	if b := rec.body(); len(b) > 0 {
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, rec.Method, server+rec.URL, body)
+	if err != nil {
+		return 0, 0, err
+	}
+	if rec.ContentType != "" {
+		req.Header.Set("Content-Type", rec.ContentType)
+	}
+	start := time.Now()
+	resp, err := c.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	// The latency includes reading the whole reply.
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return 0, 0, err
+	}
+	return resp.StatusCode, time.Since(start), nil
+}
+
+func sleepUntil(ctx context.Context, t time.Time) error {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
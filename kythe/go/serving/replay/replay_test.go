@@ -0,0 +1,199 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf, &RecordOptions{
+		RedactParams: []string{"token"},
+		MaxBodySize:  32,
+	})
+	var bodies []string
+	h := rec.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bodies = append(bodies, string(b))
+	}))
+
+	large := strings.Repeat("x", 64)
+	for _, r := range []*http.Request{
+		httptest.NewRequest("POST", "/xrefs?proto=1&token=secret", strings.NewReader(`{"ticket":["kythe:#a"]}`)),
+		httptest.NewRequest("POST", "/decorations", strings.NewReader("not json")),
+		httptest.NewRequest("POST", "/nodes", strings.NewReader(large)),
+		httptest.NewRequest("GET", "/admin/status", nil),
+	} {
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Authorization", "Bearer secret")
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	// Handlers see each body whole, whether or not it was recorded.
+	if want := []string{`{"ticket":["kythe:#a"]}`, "not json", large, ""}; strings.Join(bodies, "|") != strings.Join(want, "|") {
+		t.Errorf("Handler bodies: got %q; want %q", bodies, want)
+	}
+	if got, want := rec.Recorded(), int64(2); got != want {
+		t.Errorf("Recorded: got %d; want %d", got, want)
+	}
+	if got, want := rec.Skipped(), int64(1); got != want {
+		t.Errorf("Skipped: got %d; want %d", got, want)
+	}
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("Recording is not sanitized: %s", buf.String())
+	}
+
+	rd := NewReader(&buf)
+	r1, err := rd.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r1.Method != "POST" || r1.URL != "/xrefs?proto=1" || string(r1.JSON) != `{"ticket":["kythe:#a"]}` || r1.ContentType != "application/json" {
+		t.Errorf("First record: got %+v", r1)
+	}
+	r2, err := rd.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r2.URL != "/decorations" || r2.JSON != nil || string(r2.Body) != "not json" {
+		t.Errorf("Second record: got %+v", r2)
+	}
+	if _, err := rd.Next(); err != io.EOF {
+		t.Errorf("Next: got %v; want io.EOF", err)
+	}
+}
+
+func TestRecorderSanitize(t *testing.T) {
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf, &RecordOptions{
+		Exclude: []string{},
+		Sanitize: func(r *Record) bool {
+			r.URL = strings.ToUpper(r.URL)
+			return r.Method == "GET"
+		},
+	})
+	h := rec.Wrap(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/admin/status", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/xrefs", nil))
+
+	r, err := NewReader(&buf).Next()
+	if err != nil {
+		t.Fatal(err)
+	} else if r.URL != "/ADMIN/STATUS" {
+		t.Errorf("URL: got %q; want %q", r.URL, "/ADMIN/STATUS")
+	}
+	if got := rec.Recorded(); got != 1 {
+		t.Errorf("Recorded: got %d; want 1", got)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		seen []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		seen = append(seen, r.Method+" "+r.URL.RequestURI()+" "+string(b))
+		mu.Unlock()
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf, nil)
+	h := rec.Wrap(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	for _, r := range []*http.Request{
+		httptest.NewRequest("POST", "/xrefs?proto=1", strings.NewReader(`{"ticket":["kythe:#a"]}`)),
+		httptest.NewRequest("POST", "/decorations", strings.NewReader(`{}`)),
+		httptest.NewRequest("GET", "/missing", nil),
+	} {
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	sum, err := Replay(context.Background(), srv.URL+"/", NewReader(&buf), &ReplayOptions{
+		Rate:        1000,
+		Concurrency: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Requests != 3 || sum.Errors != 0 || sum.Status[http.StatusOK] != 2 || sum.Status[http.StatusNotFound] != 1 {
+		t.Errorf("Summary: got %+v", sum)
+	}
+	if sum.Max < sum.P50 {
+		t.Errorf("Max latency %v is less than p50 %v", sum.Max, sum.P50)
+	}
+	want := []string{
+		`POST /xrefs?proto=1 {"ticket":["kythe:#a"]}`,
+		`POST /decorations {}`,
+		`GET /missing `,
+	}
+	if strings.Join(seen, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Server saw:\n%s\nwant:\n%s", strings.Join(seen, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestReplayRecordedPace(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		buf.WriteString(`{"time":"` + start.Add(time.Duration(i)*100*time.Millisecond).Format(time.RFC3339Nano) + `","method":"GET","url":"/"}` + "\n")
+	}
+
+	// At twice the recorded pace, the last request is issued after 100ms.
+	sum, err := Replay(context.Background(), srv.URL, NewReader(&buf), &ReplayOptions{Speed: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Requests != 3 || sum.Elapsed < 100*time.Millisecond {
+		t.Errorf("Summary: got %+v; want 3 requests over at least 100ms", sum)
+	}
+}
+
+func TestReplayCancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	for i := 0; i < 3; i++ {
+		buf.WriteString(`{"method":"GET","url":"/"}` + "\n")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Replay(ctx, srv.URL, NewReader(&buf), &ReplayOptions{Rate: 1}); err != context.Canceled {
+		t.Errorf("Replay: got %v; want %v", err, context.Canceled)
+	}
+}
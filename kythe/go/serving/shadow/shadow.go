@@ -0,0 +1,397 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package shadow implements shadow reads, to de-risk a migration between
+// serving backends (e.g., from one table format to another).
+//
+// A service returned by a Comparer issues each read to both a primary and a
+// shadow backend.  It serves the reply of the primary as soon as it is ready,
+// and compares it with the reply of the shadow in the background, logging any
+// difference and the latency of each backend.  Shadow reads are bounded in
+// time and number, so that a slow shadow backend cannot slow the primary.
+package shadow // import "kythe.io/kythe/go/serving/shadow"
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"kythe.io/kythe/go/services/filetree"
+	"kythe.io/kythe/go/services/graph"
+	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/serving/identifiers"
+	"kythe.io/kythe/go/util/log"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+	gpb "kythe.io/kythe/proto/graph_go_proto"
+	ipb "kythe.io/kythe/proto/identifier_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+// Defaults for Options.
+const (
+	DefaultTimeout       = 30 * time.Second
+	DefaultMaxInFlight   = 16
+	DefaultLogEvery      = 1000
+	DefaultMaxDiffLength = 2048
+)
+
+// Options control a Comparer.
+type Options struct {
+	// Timeout bounds each shadow read, which is not canceled with the request
+	// it shadows.  If zero, DefaultTimeout is used.
+	Timeout time.Duration
+
+	// MaxInFlight bounds the number of concurrent shadow reads; reads beyond
+	// it are not shadowed.  If zero, DefaultMaxInFlight is used.
+	MaxInFlight int
+
+	// LogEvery is the number of reads of each method between the summaries
+	// of their latencies that are logged.  If zero, DefaultLogEvery is used;
+	// if negative, no summaries are logged.
+	LogEvery int
+
+	// MaxDiffLength bounds the length of each difference logged.  If zero,
+	// DefaultMaxDiffLength is used.
+	MaxDiffLength int
+
+	// OnResult, if set, is called with the result of each shadow read.
+	OnResult func(*Result)
+}
+
+// A Result is the outcome of a shadow read.
+type Result struct {
+	Method  string        // the method read, e.g. "xrefs.Decorations"
+	Request proto.Message // the request read
+
+	Primary, Shadow       time.Duration // the latency of each backend
+	PrimaryErr, ShadowErr error
+
+	// Diff describes the difference between the replies of the backends, or
+	// is empty if they agree.
+	Diff string
+}
+
+// Delta returns the latency of the shadow less that of the primary.
+func (r *Result) Delta() time.Duration { return r.Shadow - r.Primary }
+
+// MethodStats are the cumulative results of the shadow reads of a method.
+type MethodStats struct {
+	Reads        int `json:"reads"`
+	Skipped      int `json:"skipped"` // reads not shadowed, over MaxInFlight
+	Diffs        int `json:"diffs"`
+	ShadowErrors int `json:"shadow_errors"` // errors of the shadow alone
+
+	Primary time.Duration `json:"primary_latency"` // total latency of the primary
+	Shadow  time.Duration `json:"shadow_latency"`  // total latency of the shadow
+}
+
+// A Comparer issues and compares shadow reads.
+type Comparer struct {
+	opts     Options
+	inFlight chan struct{}
+	wg       sync.WaitGroup
+
+	mu    sync.Mutex
+	stats map[string]*MethodStats
+}
+
+// New returns a Comparer with the given options, which may be nil.
+func New(opts *Options) *Comparer {
+	c := &Comparer{stats: make(map[string]*MethodStats)}
+	if opts != nil {
+		c.opts = *opts
+	}
+	if c.opts.Timeout <= 0 {
+		c.opts.Timeout = DefaultTimeout
+	}
+	if c.opts.MaxInFlight <= 0 {
+		c.opts.MaxInFlight = DefaultMaxInFlight
+	}
+	if c.opts.LogEvery == 0 {
+		c.opts.LogEvery = DefaultLogEvery
+	}
+	if c.opts.MaxDiffLength <= 0 {
+		c.opts.MaxDiffLength = DefaultMaxDiffLength
+	}
+	c.inFlight = make(chan struct{}, c.opts.MaxInFlight)
+	return c
+}
+
+// Stats returns the cumulative results of the shadow reads of each method.
+func (c *Comparer) Stats() map[string]MethodStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]MethodStats, len(c.stats))
+	for method, s := range c.stats {
+		out[method] = *s
+	}
+	return out
+}
+
+// Wait waits for the shadow reads in flight to finish.
+func (c *Comparer) Wait() { c.wg.Wait() }
+
+func (c *Comparer) methodStats(method string) *MethodStats {
+	s := c.stats[method]
+	if s == nil {
+		s = new(MethodStats)
+		c.stats[method] = s
+	}
+	return s
+}
+
+// shadowReply is the outcome of a read of the shadow backend.
+type shadowReply[Reply proto.Message] struct {
+	reply   Reply
+	err     error
+	latency time.Duration
+}
+
+// read serves req from primary, and compares its reply with that of shadow
+// in the background.
+func read[Req, Reply proto.Message](c *Comparer, ctx context.Context, method string, req Req, primary, shadow func(context.Context, Req) (Reply, error)) (Reply, error) {
+	select {
+	case c.inFlight <- struct{}{}:
+	default:
+		c.mu.Lock()
+		c.methodStats(method).Skipped++
+		c.mu.Unlock()
+		return primary(ctx, req)
+	}
+
+	// The backends get their own copies of the request, since either may
+	// modify it.
+	shadowReq := proto.Clone(req).(Req)
+	done := make(chan shadowReply[Reply], 1)
+	sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.opts.Timeout)
+	go func() {
+		defer cancel()
+		start := time.Now()
+		reply, err := shadow(sctx, shadowReq)
+		done <- shadowReply[Reply]{reply, err, time.Since(start)}
+	}()
+
+	start := time.Now()
+	reply, err := primary(ctx, req)
+	res := &Result{
+		Method:     method,
+		Request:    shadowReq,
+		Primary:    time.Since(start),
+		PrimaryErr: err,
+	}
+	// The caller owns the reply, so compare a copy of it.
+	var primaryReply proto.Message
+	if err == nil && reply.ProtoReflect().IsValid() {
+		primaryReply = proto.Clone(reply)
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer func() { <-c.inFlight }()
+		s := <-done
+		res.Shadow, res.ShadowErr = s.latency, s.err
+		var shadowReply proto.Message
+		if s.err == nil && s.reply.ProtoReflect().IsValid() {
+			shadowReply = s.reply
+		}
+		res.Diff = diff(primaryReply, shadowReply, res.PrimaryErr, res.ShadowErr)
+		c.report(res)
+	}()
+	return reply, err
+}
+
+// diff describes the difference between the outcomes of two reads.
+func diff(primary, shadow proto.Message, primaryErr, shadowErr error) string {
+	switch {
+	case primaryErr != nil || shadowErr != nil:
+		if status.Code(primaryErr) == status.Code(shadowErr) {
+			return ""
+		}
+		return fmt.Sprintf("primary error: %v; shadow error: %v", primaryErr, shadowErr)
+	case primary == nil || shadow == nil:
+		if primary == nil && shadow == nil {
+			return ""
+		}
+		return fmt.Sprintf("primary reply: %v; shadow reply: %v", primary, shadow)
+	}
+	return cmp.Diff(primary, shadow, protocmp.Transform())
+}
+
+// report records res, and logs it as needed.
+func (c *Comparer) report(res *Result) {
+	c.mu.Lock()
+	s := c.methodStats(res.Method)
+	s.Reads++
+	s.Primary += res.Primary
+	s.Shadow += res.Shadow
+	if res.Diff != "" {
+		s.Diffs++
+	}
+	if res.ShadowErr != nil && res.PrimaryErr == nil {
+		s.ShadowErrors++
+	}
+	summary := *s
+	c.mu.Unlock()
+
+	if res.Diff != "" {
+		d := res.Diff
+		if len(d) > c.opts.MaxDiffLength {
+			d = d[:c.opts.MaxDiffLength] + "..."
+		}
+		log.Warningf("Shadow %s differs (primary %v, shadow %v) for %v:\n%s", res.Method, res.Primary, res.Shadow, res.Request, d)
+	}
+	if c.opts.LogEvery > 0 && summary.Reads%c.opts.LogEvery == 0 {
+		n := time.Duration(summary.Reads)
+		log.Infof("Shadow %s: %d reads, %d diffs, %d shadow errors, %d skipped; mean latency primary %v, shadow %v (%+v)",
+			res.Method, summary.Reads, summary.Diffs, summary.ShadowErrors, summary.Skipped,
+			summary.Primary/n, summary.Shadow/n, (summary.Shadow-summary.Primary)/n)
+	}
+	if c.opts.OnResult != nil {
+		c.opts.OnResult(res)
+	}
+}
+
+// XRefs returns an xrefs.Service serving primary, shadowed by shadow.
+func (c *Comparer) XRefs(primary, shadow xrefs.Service) xrefs.Service {
+	return &shadowXRefs{c, primary, shadow}
+}
+
+type shadowXRefs struct {
+	c               *Comparer
+	primary, shadow xrefs.Service
+}
+
+// Decorations implements part of the xrefs.Service interface.
+func (s *shadowXRefs) Decorations(ctx context.Context, req *xpb.DecorationsRequest) (*xpb.DecorationsReply, error) {
+	return read(s.c, ctx, "xrefs.Decorations", req, s.primary.Decorations, s.shadow.Decorations)
+}
+
+// CrossReferences implements part of the xrefs.Service interface.
+func (s *shadowXRefs) CrossReferences(ctx context.Context, req *xpb.CrossReferencesRequest) (*xpb.CrossReferencesReply, error) {
+	return read(s.c, ctx, "xrefs.CrossReferences", req, s.primary.CrossReferences, s.shadow.CrossReferences)
+}
+
+// Documentation implements part of the xrefs.Service interface.
+func (s *shadowXRefs) Documentation(ctx context.Context, req *xpb.DocumentationRequest) (*xpb.DocumentationReply, error) {
+	return read(s.c, ctx, "xrefs.Documentation", req, s.primary.Documentation, s.shadow.Documentation)
+}
+
+// Close implements part of the xrefs.Service interface.  It closes both
+// backends, after the shadow reads in flight.
+func (s *shadowXRefs) Close(ctx context.Context) error {
+	s.c.Wait()
+	err := s.primary.Close(ctx)
+	if serr := s.shadow.Close(ctx); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// Graph returns a graph.Service serving primary, shadowed by shadow.
+func (c *Comparer) Graph(primary, shadow graph.Service) graph.Service {
+	return &shadowGraph{c, primary, shadow}
+}
+
+type shadowGraph struct {
+	c               *Comparer
+	primary, shadow graph.Service
+}
+
+// Nodes implements part of the graph.Service interface.
+func (s *shadowGraph) Nodes(ctx context.Context, req *gpb.NodesRequest) (*gpb.NodesReply, error) {
+	return read(s.c, ctx, "graph.Nodes", req, s.primary.Nodes, s.shadow.Nodes)
+}
+
+// Edges implements part of the graph.Service interface.
+func (s *shadowGraph) Edges(ctx context.Context, req *gpb.EdgesRequest) (*gpb.EdgesReply, error) {
+	return read(s.c, ctx, "graph.Edges", req, s.primary.Edges, s.shadow.Edges)
+}
+
+// FileTree returns a filetree.Service serving primary, shadowed by shadow.
+func (c *Comparer) FileTree(primary, shadow filetree.Service) filetree.Service {
+	return &shadowFileTree{c, primary, shadow}
+}
+
+type shadowFileTree struct {
+	c               *Comparer
+	primary, shadow filetree.Service
+}
+
+// Directory implements part of the filetree.Service interface.
+func (s *shadowFileTree) Directory(ctx context.Context, req *ftpb.DirectoryRequest) (*ftpb.DirectoryReply, error) {
+	return read(s.c, ctx, "filetree.Directory", req, s.primary.Directory, s.shadow.Directory)
+}
+
+// Tree implements part of the filetree.Service interface.
+func (s *shadowFileTree) Tree(ctx context.Context, req *ftpb.TreeRequest) (*ftpb.DirectoryReply, error) {
+	return read(s.c, ctx, "filetree.Tree", req, s.primary.Tree, s.shadow.Tree)
+}
+
+// Search implements part of the filetree.Service interface.
+func (s *shadowFileTree) Search(ctx context.Context, req *ftpb.SearchRequest) (*ftpb.SearchReply, error) {
+	return read(s.c, ctx, "filetree.Search", req, s.primary.Search, s.shadow.Search)
+}
+
+// CorpusRoots implements part of the filetree.Service interface.
+func (s *shadowFileTree) CorpusRoots(ctx context.Context, req *ftpb.CorpusRootsRequest) (*ftpb.CorpusRootsReply, error) {
+	return read(s.c, ctx, "filetree.CorpusRoots", req, s.primary.CorpusRoots, s.shadow.CorpusRoots)
+}
+
+// Close implements part of the filetree.Service interface.  It closes both
+// backends, after the shadow reads in flight.
+func (s *shadowFileTree) Close(ctx context.Context) error {
+	s.c.Wait()
+	err := s.primary.Close(ctx)
+	if serr := s.shadow.Close(ctx); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// Identifiers returns an identifiers.Service serving primary, shadowed by
+// shadow.
+func (c *Comparer) Identifiers(primary, shadow identifiers.Service) identifiers.Service {
+	return &shadowIdentifiers{c, primary, shadow}
+}
+
+type shadowIdentifiers struct {
+	c               *Comparer
+	primary, shadow identifiers.Service
+}
+
+// Find implements part of the identifiers.Service interface.
+func (s *shadowIdentifiers) Find(ctx context.Context, req *ipb.FindRequest) (*ipb.FindReply, error) {
+	return read(s.c, ctx, "identifiers.Find", req, s.primary.Find, s.shadow.Find)
+}
+
+// Close implements part of the identifiers.Service interface.  It closes both
+// backends, after the shadow reads in flight.
+func (s *shadowIdentifiers) Close(ctx context.Context) error {
+	s.c.Wait()
+	err := s.primary.Close(ctx)
+	if serr := s.shadow.Close(ctx); err == nil {
+		err = serr
+	}
+	return err
+}
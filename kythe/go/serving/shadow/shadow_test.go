@@ -0,0 +1,155 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shadow
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/test/testutil/fakes"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	gpb "kythe.io/kythe/proto/graph_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+const (
+	fileTicket = "kythe://corpus?path=a.go"
+	fTicket    = "kythe://corpus?lang=go?path=a.go#F"
+)
+
+// testIndex returns an index of a file whose text is text.
+func testIndex(text string) *fakes.Index {
+	ix := fakes.NewIndex()
+	ix.Node(fTicket, nodes.Function)
+	ix.File(fileTicket, text).AnchorText(edges.DefinesBinding, fTicket, "F")
+	return ix
+}
+
+// results collects the results of a Comparer.
+type results struct {
+	mu  sync.Mutex
+	all []*Result
+}
+
+func (r *results) add(res *Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.all = append(r.all, res)
+}
+
+func TestXRefs(t *testing.T) {
+	ctx := context.Background()
+	var rs results
+	c := New(&Options{OnResult: rs.add, LogEvery: -1})
+	xs := c.XRefs(testIndex("func F() {}\n").XRefs(), testIndex("func F() { return }\n").XRefs())
+
+	req := &xpb.DecorationsRequest{Location: &xpb.Location{Ticket: fileTicket}, SourceText: true}
+	reply, err := xs.Decorations(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(reply.SourceText); got != "func F() {}\n" {
+		t.Errorf("Decorations: got text %q, want the primary's", got)
+	}
+	c.Wait()
+	if _, err := xs.Decorations(ctx, &xpb.DecorationsRequest{Location: &xpb.Location{Ticket: "kythe://corpus?path=missing"}}); err != xrefs.ErrDecorationsNotFound {
+		t.Errorf("Decorations of a missing file: got error %v, want %v", err, xrefs.ErrDecorationsNotFound)
+	}
+	c.Wait()
+
+	if len(rs.all) != 2 {
+		t.Fatalf("Got %d results, want 2", len(rs.all))
+	}
+	if d := rs.all[0].Diff; !strings.Contains(d, "source_text") {
+		t.Errorf("Diff of the texts: got %q", d)
+	}
+	if d := rs.all[1].Diff; d != "" {
+		t.Errorf("Diff of the same errors: got %q, want none", d)
+	}
+	want := MethodStats{Reads: 2, Diffs: 1}
+	got := c.Stats()["xrefs.Decorations"]
+	got.Primary, got.Shadow = 0, 0
+	if got != want {
+		t.Errorf("Stats: got %+v, want %+v", got, want)
+	}
+}
+
+// slowGraph is a graph.Service that blocks until it is released.
+type slowGraph struct {
+	release chan struct{}
+}
+
+func (s *slowGraph) Nodes(ctx context.Context, req *gpb.NodesRequest) (*gpb.NodesReply, error) {
+	select {
+	case <-s.release:
+		return &gpb.NodesReply{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *slowGraph) Edges(ctx context.Context, req *gpb.EdgesRequest) (*gpb.EdgesReply, error) {
+	return &gpb.EdgesReply{}, nil
+}
+
+func TestSlowShadow(t *testing.T) {
+	ctx := context.Background()
+	var rs results
+	c := New(&Options{MaxInFlight: 1, Timeout: time.Minute, OnResult: rs.add, LogEvery: -1})
+	slow := &slowGraph{release: make(chan struct{})}
+	gs := c.Graph(testIndex("func F() {}\n").Graph(), slow)
+
+	// The primary is served without waiting for the shadow, and the second
+	// read is not shadowed while the first is in flight.
+	req := &gpb.NodesRequest{Ticket: []string{fTicket}}
+	for i := 0; i < 2; i++ {
+		reply, err := gs.Nodes(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(reply.Nodes) != 1 {
+			t.Errorf("Nodes: got %v, want the primary's reply", reply)
+		}
+	}
+	close(slow.release)
+	c.Wait()
+
+	s := c.Stats()["graph.Nodes"]
+	if s.Reads != 1 || s.Skipped != 1 || s.Diffs != 1 {
+		t.Errorf("Stats: got %+v, want 1 read with a diff and 1 skipped", s)
+	}
+	if len(rs.all) != 1 || rs.all[0].Request.(*gpb.NodesRequest).Ticket[0] != fTicket {
+		t.Errorf("Results: got %+v, want 1 for the request", rs.all)
+	}
+}
+
+func TestShadowTimeout(t *testing.T) {
+	c := New(&Options{Timeout: time.Millisecond, LogEvery: -1})
+	gs := c.Graph(testIndex("func F() {}\n").Graph(), &slowGraph{release: make(chan struct{})})
+	if _, err := gs.Nodes(context.Background(), &gpb.NodesRequest{Ticket: []string{fTicket}}); err != nil {
+		t.Fatal(err)
+	}
+	c.Wait()
+	if s := c.Stats()["graph.Nodes"]; s.ShadowErrors != 1 || s.Diffs != 1 {
+		t.Errorf("Stats: got %+v, want a shadow error", s)
+	}
+}
@@ -0,0 +1,338 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package stats computes index-level statistics of a serving table and
+// serves them as JSON for dashboards.
+//
+// The statistics of the entries from which a table is built are computed by
+// a Collector as the table is written, and stored in the table under Key.  A
+// server reports them at /stats together with the size of the table on disk:
+//
+//	{
+//	  "schema_version": 1,
+//	  "table": {"path": ..., "disk_bytes": ..., "modified": ..., "loaded": ...},
+//	  "index": {
+//	    "written": ..., "last_build_time": ...,
+//	    "entries": ..., "files": ..., "nodes": ..., "edges": ...,
+//	    "languages": {"go": {"files": ..., "nodes": ..., "edges": ...}},
+//	    "tables": {"decor": {"keys": ..., "bytes": ...}}
+//	  }
+//	}
+//
+// The schema is stable: fields may be added, but are never renamed or
+// removed without a change of schema_version.  Times are in RFC 3339 format,
+// and "index" is null for a table written without statistics.
+package stats // import "kythe.io/kythe/go/serving/stats"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"kythe.io/kythe/go/services/web"
+	"kythe.io/kythe/go/storage/keyvalue"
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/util/log"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// Key is the key of the statistics in a serving table.
+const Key = "kythe:stats"
+
+// SchemaVersion is the version of the JSON schema of a Reply.
+const SchemaVersion = 1
+
+// Stats are the statistics of the entries of an index.
+type Stats struct {
+	Written       time.Time                 `json:"written"`
+	LastBuildTime *time.Time                `json:"last_build_time,omitempty"` // the latest provenance build time
+	Entries       int64                     `json:"entries"`
+	Files         int64                     `json:"files"`
+	Nodes         int64                     `json:"nodes"`
+	Edges         int64                     `json:"edges"`
+	Languages     map[string]*LanguageStats `json:"languages"`
+	Tables        map[string]*TableSize     `json:"tables,omitempty"`
+}
+
+// LanguageStats are the statistics of the entries of one language.  A file
+// belongs to each language with an anchor in it.
+type LanguageStats struct {
+	Files int64 `json:"files"`
+	Nodes int64 `json:"nodes"`
+	Edges int64 `json:"edges"`
+}
+
+// TableSize is the size of the keys and values of a table, as written.
+type TableSize struct {
+	Keys  int64 `json:"keys"`
+	Bytes int64 `json:"bytes"`
+}
+
+// A Collector accumulates the Stats of a stream of entries.  Its fields are
+// exported so that it may be encoded, as the accumulator of a distributed
+// computation; they should otherwise be treated as private.
+type Collector struct {
+	Entries, Files, Nodes, Edges int64
+
+	// NodesByLanguage and EdgesByLanguage are counts by language of the
+	// source of the node or edge.
+	NodesByLanguage map[string]int64
+	EdgesByLanguage map[string]int64
+
+	// FilesByLanguage holds, for each language, the set of files containing
+	// anchors of the language.
+	FilesByLanguage map[string]map[string]bool
+
+	LastBuildTime time.Time
+}
+
+// NewCollector returns an empty Collector.
+func NewCollector() *Collector {
+	return &Collector{
+		NodesByLanguage: make(map[string]int64),
+		EdgesByLanguage: make(map[string]int64),
+		FilesByLanguage: make(map[string]map[string]bool),
+	}
+}
+
+// Add adds e to the statistics of c.  Reverse edges are not counted.
+func (c *Collector) Add(e *spb.Entry) {
+	c.Entries++
+	lang := e.Source.GetLanguage()
+	if e.EdgeKind != "" {
+		c.Edges++
+		if lang != "" {
+			c.EdgesByLanguage[lang]++
+		}
+		return
+	}
+	switch e.FactName {
+	case facts.NodeKind:
+		c.Nodes++
+		if lang != "" {
+			c.NodesByLanguage[lang]++
+		}
+		switch string(e.FactValue) {
+		case nodes.File:
+			c.Files++
+		case nodes.Anchor:
+			if lang == "" {
+				break
+			}
+			files := c.FilesByLanguage[lang]
+			if files == nil {
+				files = make(map[string]bool)
+				c.FilesByLanguage[lang] = files
+			}
+			src := e.Source
+			files[src.Corpus+"\x00"+src.Root+"\x00"+src.Path] = true
+		}
+	case facts.ProvBuildTime:
+		if t, err := time.Parse(time.RFC3339, string(e.FactValue)); err == nil && t.After(c.LastBuildTime) {
+			c.LastBuildTime = t
+		}
+	}
+}
+
+// Reader returns an EntryReader that adds each entry read by rd to c.
+func (c *Collector) Reader(rd stream.EntryReader) stream.EntryReader {
+	return func(f func(*spb.Entry) error) error {
+		return rd(func(e *spb.Entry) error {
+			c.Add(e)
+			return f(e)
+		})
+	}
+}
+
+// Merge adds the statistics of o to c.  Entries must not be counted by both.
+func (c *Collector) Merge(o *Collector) {
+	c.Entries += o.Entries
+	c.Files += o.Files
+	c.Nodes += o.Nodes
+	c.Edges += o.Edges
+	for lang, n := range o.NodesByLanguage {
+		c.NodesByLanguage[lang] += n
+	}
+	for lang, n := range o.EdgesByLanguage {
+		c.EdgesByLanguage[lang] += n
+	}
+	for lang, files := range o.FilesByLanguage {
+		if c.FilesByLanguage[lang] == nil {
+			c.FilesByLanguage[lang] = make(map[string]bool)
+		}
+		for f := range files {
+			c.FilesByLanguage[lang][f] = true
+		}
+	}
+	if o.LastBuildTime.After(c.LastBuildTime) {
+		c.LastBuildTime = o.LastBuildTime
+	}
+}
+
+// Stats returns the statistics accumulated by c, written now.
+func (c *Collector) Stats() *Stats {
+	s := &Stats{
+		Written:   time.Now().UTC().Truncate(time.Second),
+		Entries:   c.Entries,
+		Files:     c.Files,
+		Nodes:     c.Nodes,
+		Edges:     c.Edges,
+		Languages: make(map[string]*LanguageStats),
+	}
+	if !c.LastBuildTime.IsZero() {
+		t := c.LastBuildTime.UTC()
+		s.LastBuildTime = &t
+	}
+	lang := func(name string) *LanguageStats {
+		if s.Languages[name] == nil {
+			s.Languages[name] = new(LanguageStats)
+		}
+		return s.Languages[name]
+	}
+	for name, n := range c.NodesByLanguage {
+		lang(name).Nodes = n
+	}
+	for name, n := range c.EdgesByLanguage {
+		lang(name).Edges = n
+	}
+	for name, files := range c.FilesByLanguage {
+		lang(name).Files = int64(len(files))
+	}
+	return s
+}
+
+// TableSizes returns the sizes of the tables of db.  The table of a key is
+// its prefix up to the first colon.
+func TableSizes(ctx context.Context, db keyvalue.DB) (map[string]*TableSize, error) {
+	it, err := db.ScanPrefix(ctx, nil, &keyvalue.Options{LargeRead: true})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	sizes := make(map[string]*TableSize)
+	for {
+		key, val, err := it.Next()
+		if err == io.EOF {
+			return sizes, nil
+		} else if err != nil {
+			return nil, err
+		}
+		name := "other"
+		if i := bytes.IndexByte(key, ':'); i > 0 {
+			name = string(key[:i])
+		}
+		size := sizes[name]
+		if size == nil {
+			size = new(TableSize)
+			sizes[name] = size
+		}
+		size.Keys++
+		size.Bytes += int64(len(key) + len(val))
+	}
+}
+
+// Write stores s in db under Key.
+func Write(ctx context.Context, db keyvalue.DB, s *Stats) error {
+	rec, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	w, err := db.Writer(ctx)
+	if err != nil {
+		return err
+	}
+	if err := w.Write([]byte(Key), rec); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// Read returns the Stats stored in db, or nil if there are none.
+func Read(ctx context.Context, db keyvalue.DB) (*Stats, error) {
+	rec, err := db.Get(ctx, []byte(Key), nil)
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var s Stats
+	if err := json.Unmarshal(rec, &s); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", Key, err)
+	}
+	return &s, nil
+}
+
+// TableInfo describes a serving table on disk.
+type TableInfo struct {
+	Path      string    `json:"path"`
+	DiskBytes int64     `json:"disk_bytes"`
+	Modified  time.Time `json:"modified"` // the latest modification of its files
+	Loaded    time.Time `json:"loaded"`   // when the server opened it
+}
+
+// A Reply is the JSON reply of a /stats request.
+type Reply struct {
+	SchemaVersion int        `json:"schema_version"`
+	Table         *TableInfo `json:"table"`
+	Index         *Stats     `json:"index"`
+}
+
+// Load returns the Reply for the table of db, whose files are in the
+// directory at path.
+func Load(ctx context.Context, db keyvalue.DB, path string) (*Reply, error) {
+	s, err := Read(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	info := &TableInfo{Path: path, Loaded: time.Now().UTC().Truncate(time.Second)}
+	err = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		info.DiskBytes += fi.Size()
+		if t := fi.ModTime().UTC().Truncate(time.Second); t.After(info.Modified) {
+			info.Modified = t
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Reply{SchemaVersion: SchemaVersion, Table: info, Index: s}, nil
+}
+
+// RegisterHTTPHandlers registers a /stats handler with mux, serving reply.
+func RegisterHTTPHandlers(ctx context.Context, reply *Reply, mux *http.ServeMux) {
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		if err := web.WriteJSONResponse(w, r, reply); err != nil {
+			log.ErrorContextf(ctx, "Stats error: %v", err)
+		}
+	})
+}
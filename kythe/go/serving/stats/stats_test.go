@@ -0,0 +1,183 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"kythe.io/kythe/go/storage/inmemory"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	"github.com/google/go-cmp/cmp"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func fact(v *spb.VName, name, value string) *spb.Entry {
+	return &spb.Entry{Source: v, FactName: name, FactValue: []byte(value)}
+}
+
+func edge(src *spb.VName, kind string, tgt *spb.VName) *spb.Entry {
+	return &spb.Entry{Source: src, EdgeKind: kind, Target: tgt, FactName: "/"}
+}
+
+var (
+	fileA   = &spb.VName{Corpus: "c", Path: "a.go"}
+	fileB   = &spb.VName{Corpus: "c", Path: "b.cc"}
+	anchorA = &spb.VName{Corpus: "c", Path: "a.go", Language: "go", Signature: "@0:1"}
+	anchorB = &spb.VName{Corpus: "c", Path: "a.go", Language: "go", Signature: "@2:3"}
+	anchorC = &spb.VName{Corpus: "c", Path: "b.cc", Language: "c++", Signature: "@0:1"}
+	funcF   = &spb.VName{Corpus: "c", Language: "go", Signature: "f"}
+)
+
+func entries() []*spb.Entry {
+	return []*spb.Entry{
+		fact(fileA, facts.NodeKind, nodes.File),
+		fact(fileA, facts.Text, "f f"),
+		fact(fileA, facts.ProvBuildTime, "2026-01-02T03:04:05Z"),
+		fact(fileB, facts.NodeKind, nodes.File),
+		fact(fileB, facts.ProvBuildTime, "2026-03-01T00:00:00+01:00"),
+		fact(fileB, facts.ProvBuildTime, "not a time"),
+		fact(anchorA, facts.NodeKind, nodes.Anchor),
+		edge(anchorA, edges.DefinesBinding, funcF),
+		fact(anchorB, facts.NodeKind, nodes.Anchor),
+		edge(anchorB, edges.Ref, funcF),
+		fact(anchorC, facts.NodeKind, nodes.Anchor),
+		edge(anchorC, edges.Ref, funcF),
+		fact(funcF, facts.NodeKind, nodes.Function),
+	}
+}
+
+func TestCollector(t *testing.T) {
+	es := entries()
+	// Collect the entries in two parts, to check merging.
+	c, other := NewCollector(), NewCollector()
+	for _, e := range es[:5] {
+		c.Add(e)
+	}
+	rd := other.Reader(func(f func(*spb.Entry) error) error {
+		for _, e := range es[5:] {
+			if err := f(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	var n int
+	if err := rd(func(*spb.Entry) error { n++; return nil }); err != nil || n != len(es)-5 {
+		t.Fatalf("Reader: read %d entries, %v", n, err)
+	}
+	c.Merge(other)
+
+	got := c.Stats()
+	if time.Since(got.Written) > time.Minute {
+		t.Errorf("Written: got %v, want now", got.Written)
+	}
+	got.Written = time.Time{}
+	last := time.Date(2026, 2, 28, 23, 0, 0, 0, time.UTC)
+	want := &Stats{
+		LastBuildTime: &last,
+		Entries:       int64(len(es)),
+		Files:         2,
+		Nodes:         6,
+		Edges:         3,
+		Languages: map[string]*LanguageStats{
+			"go":  {Files: 1, Nodes: 3, Edges: 2},
+			"c++": {Files: 1, Nodes: 1, Edges: 1},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Stats (-want +got):\n%s", diff)
+	}
+}
+
+func TestTable(t *testing.T) {
+	ctx := context.Background()
+	db := inmemory.NewKeyValueDB()
+	if s, err := Read(ctx, db); s != nil || err != nil {
+		t.Fatalf("Read of empty table: got %v, %v; want nil", s, err)
+	}
+
+	w, err := db.Writer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kv := range [][2]string{{"decor:a", "123"}, {"decor:b", "4"}, {"xrefs:c", ""}, {"marker", "x"}} {
+		if err := w.Write([]byte(kv[0]), []byte(kv[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCollector()
+	for _, e := range entries() {
+		c.Add(e)
+	}
+	want := c.Stats()
+	if want.Tables, err = TableSizes(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]*TableSize{
+		"decor": {Keys: 2, Bytes: 18},
+		"xrefs": {Keys: 1, Bytes: 7},
+		"other": {Keys: 1, Bytes: 7},
+	}, want.Tables); diff != "" {
+		t.Errorf("TableSizes (-want +got):\n%s", diff)
+	}
+	if err := Write(ctx, db, want); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "000001.ldb"), make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := Load(ctx, db, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, reply.Index); diff != "" {
+		t.Errorf("Stored stats (-want +got):\n%s", diff)
+	}
+	if reply.Table.DiskBytes != 100 || reply.Table.Modified.IsZero() {
+		t.Errorf("Table: got %+v, want 100 bytes with a modification time", reply.Table)
+	}
+
+	mux := http.NewServeMux()
+	RegisterHTTPHandlers(ctx, reply, mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/stats", nil))
+	var got map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Decoding %q: %v", rec.Body, err)
+	}
+	index, _ := got["index"].(map[string]any)
+	if got["schema_version"] != 1.0 || index["files"] != 2.0 || index["last_build_time"] != "2026-02-28T23:00:00Z" {
+		t.Errorf("GET /stats: got %v", got)
+	}
+}
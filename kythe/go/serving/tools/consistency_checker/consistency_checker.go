@@ -0,0 +1,132 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary consistency_checker checks that the file tree, decorations, and
+// cross-references of a serving table agree, and exits with a nonzero status
+// if they do not.  It is meant to be run as a gate after a table is written
+// and before it is published:
+//
+//	write_tables --out /tmp/table ... &&
+//	  consistency_checker --serving_table /tmp/table &&
+//	  ln -sfn /tmp/table /srv/table
+//
+// With --server, it instead checks the data served by a running http_server.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"kythe.io/kythe/go/services/filetree"
+	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/serving/consistency"
+	ftsrv "kythe.io/kythe/go/serving/filetree"
+	xsrv "kythe.io/kythe/go/serving/xrefs"
+	"kythe.io/kythe/go/storage/leveldb"
+	"kythe.io/kythe/go/storage/table"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+)
+
+var (
+	servingTable = flag.String("serving_table", "", "LevelDB serving table to check")
+	server       = flag.String("server", "", "Address of a Kythe HTTP server to check, in place of --serving_table")
+
+	maxTickets       = flag.Int("max_tickets_per_request", consistency.DefaultMaxTicketsPerRequest, "Maximum number of tickets in each cross-references request")
+	maxDiscrepancies = flag.Int("max_discrepancies", consistency.DefaultMaxDiscrepancies, "Maximum number of discrepancies to print per corpus (all are counted)")
+	jsonOutput       = flag.Bool("json", false, "Print the report as JSON")
+
+	corpora flagutil.StringList
+)
+
+func init() {
+	flag.Var(&corpora, "corpora", "If set, the comma-separated corpora to check; by default every corpus is checked")
+	flag.Usage = flagutil.SimpleUsage("Checks that the file tree, decorations, and cross-references of a serving table agree",
+		"(--serving_table path | --server addr) [--corpora c1,c2] [--json]")
+}
+
+func main() {
+	flag.Parse()
+	if (*servingTable == "") == (*server == "") {
+		flagutil.UsageError("exactly one of --serving_table or --server is required")
+	} else if flag.NArg() > 0 {
+		flagutil.UsageErrorf("unknown non-flag arguments given: %v", flag.Args())
+	}
+
+	ctx := context.Background()
+	c := &consistency.Checker{
+		Corpora:              corpora,
+		MaxTicketsPerRequest: *maxTickets,
+		MaxDiscrepancies:     *maxDiscrepancies,
+	}
+	if *server != "" {
+		c.FileTree = filetree.WebClient(*server)
+		c.XRefs = xrefs.WebClient(*server)
+	} else {
+		path, opts, err := leveldb.ParseSpec(*servingTable)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts.MustExist = true
+		db, err := leveldb.Open(path, opts)
+		if err != nil {
+			log.Fatalf("Error opening db at %q: %v", path, err)
+		}
+		defer db.Close(ctx)
+		c.FileTree = &ftsrv.Table{Proto: &table.KVProto{DB: db}, PrefixedKeys: true}
+		c.XRefs = xsrv.NewService(ctx, db)
+	}
+
+	report, err := c.Check(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *jsonOutput {
+		if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		printReport(report)
+	}
+	if !report.OK() {
+		os.Exit(1)
+	}
+}
+
+func printReport(r *consistency.Report) {
+	for _, c := range r.Corpora {
+		status := "OK"
+		if !c.OK() {
+			status = "FAILED"
+		}
+		fmt.Printf("%s: %s (%d files, %d targets)\n", c.Corpus, status, c.Files, c.Targets)
+		kinds := make([]string, 0, len(c.Counts))
+		for k := range c.Counts {
+			kinds = append(kinds, string(k))
+		}
+		sort.Strings(kinds)
+		for _, k := range kinds {
+			fmt.Printf("  %s: %d\n", k, c.Counts[consistency.Kind(k)])
+		}
+		for _, d := range c.Discrepancies {
+			fmt.Printf("    %s\n", d)
+		}
+	}
+}
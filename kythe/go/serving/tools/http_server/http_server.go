@@ -20,45 +20,98 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
+	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"kythe.io/kythe/go/languageserver"
+	"kythe.io/kythe/go/services/editor"
 	"kythe.io/kythe/go/services/filetree"
 	"kythe.io/kythe/go/services/graph"
+	"kythe.io/kythe/go/services/graphstore"
+	"kythe.io/kythe/go/services/nav"
+	"kythe.io/kythe/go/services/web"
 	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/serving/admin"
 	ftsrv "kythe.io/kythe/go/serving/filetree"
 	gsrv "kythe.io/kythe/go/serving/graph"
 	"kythe.io/kythe/go/serving/identifiers"
+	"kythe.io/kythe/go/serving/reload"
+	"kythe.io/kythe/go/serving/replay"
+	"kythe.io/kythe/go/serving/shadow"
+	"kythe.io/kythe/go/serving/stats"
+	"kythe.io/kythe/go/serving/webhook"
+	"kythe.io/kythe/go/serving/webui"
 	xsrv "kythe.io/kythe/go/serving/xrefs"
+	"kythe.io/kythe/go/storage/gsutil"
+	"kythe.io/kythe/go/storage/keyvalue"
 	"kythe.io/kythe/go/storage/leveldb"
 	"kythe.io/kythe/go/storage/table"
+	"kythe.io/kythe/go/util/audit"
+	"kythe.io/kythe/go/util/datasize"
 	"kythe.io/kythe/go/util/flagutil"
 	"kythe.io/kythe/go/util/log"
+	"kythe.io/kythe/go/util/progress"
 
 	"golang.org/x/net/http2"
 
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+
 	_ "kythe.io/kythe/go/services/graphstore/proxy"
 )
 
 var (
-	servingTable = flag.String("serving_table", "", "LevelDB serving table")
+	servingTable = flag.String("serving_table", "", "LevelDB serving table, optionally with tuning options (e.g., path?cache_size=16GiB&bloom_bits=10)")
 
 	httpListeningAddr = flag.String("listen", "localhost:8080", "Listening address for HTTP server (\":<port>\" allows access from any machine)")
 	httpAllowOrigin   = flag.String("http_allow_origin", "", "If set, each HTTP response will contain a Access-Control-Allow-Origin header with the given value")
 	publicResources   = flag.String("public_resources", "", "Path to directory of static resources to serve")
+	embeddedUI        = flag.Bool("embedded_ui", false, "Whether to serve the web UI bundled into the binary")
+	uiPrefix          = flag.String("ui_prefix", "/", "URL path under which to serve the bundled web UI")
 
 	tlsListeningAddr = flag.String("tls_listen", "", "Listening address for TLS HTTP server")
 	tlsCertFile      = flag.String("tls_cert_file", "", "Path to file with concatenation of TLS certificates")
 	tlsKeyFile       = flag.String("tls_key_file", "", "Path to file with TLS private key")
 
 	maxTicketsPerRequest = flag.Int("max_tickets_per_request", 20, "Maximum number of tickets allowed per request")
+	maxPageSize          = flag.Int("max_page_size", 10000, "Maximum page_size allowed in cross-references, edges, and directory requests, and max_results allowed in file searches (0 for no limit)")
+	maxPathDepth         = flag.Int("max_path_depth", 256, "Maximum number of path components allowed in directory requests (0 for no limit)")
+	maxTreeDepth         = flag.Int("max_tree_depth", 16, "Maximum max_depth allowed in tree requests (0 for no limit, which also allows requests for whole subtrees)")
+	maxDecorationsSpan   = datasize.Flag("max_decorations_span", "64MiB", "Maximum span of a file, or size of a whole file, allowed in decorations requests (0 for no limit)")
+	maxRequestBodySize   = datasize.Flag("max_request_body_size", "16MiB", "Maximum size of the body of API requests (0 for no limit)")
+
+	navRepos = flag.String("nav_repos", "", "Path to a JSON file mapping repository names to the corpus, root and path_prefix holding them, for the /nav API")
+
+	editorSettings = flag.String("editor_settings", "", "Path to a JSON file holding a list of language server workspace settings; if set, the /editor JSON-RPC endpoint maps local paths with them")
+
+	watchInterval = flag.Duration("watch_interval", 0, "If positive, poll --serving_table at this interval and reload it when it is repointed to a new table (it is also reloaded on SIGHUP)")
+
+	webhooks = flag.String("webhooks", "", `Path to a JSON file holding a list of webhooks ({"url", "headers", "secret"}) to notify each time a serving table is loaded`)
+
+	shadowTable = flag.String("shadow_serving_table", "", "If set, a LevelDB serving table (like --serving_table) to which each read is also issued in the background, logging any difference from the reply served and the latencies of both, e.g. to try a new table before migrating to it")
+
+	recordRequests   = flag.String("record_requests", "", "If set, a file to which API requests are appended, sanitized of headers and the /admin/ endpoints, for replay by replay_requests")
+	recordSampleRate = flag.Float64("record_sample_rate", 1, "Fraction of API requests recorded to --record_requests")
+
+	auditLog = flag.String("audit_log", "", "If set, the path of an audit log in which to record the requests to the /admin/ endpoints and each reload of the serving table and file tree, with who made them")
+
+	adminTokenFile = flag.String("admin_token_file", "", "Path to a file of bearer tokens, one per line, that authorize requests to the /admin/reload, /admin/repopulate, and /admin/status endpoints; if unset, the endpoints are disabled")
+
+	filetreeTable     = flag.String("filetree_table", "", "If set, a LevelDB table written by write_filetree whose file tree is served in place of the serving table's (and reopened by /admin/repopulate)")
+	filetreeFileFacts = flag.Bool("filetree_file_facts", false, "Whether the file tree scanned from --graphstore reports the size and encoding of each file; this reads the text of every file")
+
+	gs graphstore.Service
 )
 
 func init() {
+	gsutil.Flag(&gs, "graphstore", "If set, GraphStore from which to build an in-memory file tree, served in place of the serving table's (and rebuilt by /admin/repopulate)")
 	flag.Usage = flagutil.SimpleUsage("Exposes HTTP interfaces for the xrefs and filetree services",
-		"(--graphstore spec | --serving_table path) [--listen addr] [--public_resources dir]")
+		"--serving_table path [--shadow_serving_table path] [--graphstore spec | --filetree_table path] [--listen addr] [--public_resources dir] [--embedded_ui [--ui_prefix path]] [--admin_token_file path [--audit_log path]] [--record_requests path]")
 }
 
 func main() {
@@ -69,64 +122,142 @@ func main() {
 		flagutil.UsageError("missing either --listen or --tls_listen argument")
 	} else if *tlsListeningAddr != "" && (*tlsCertFile == "" || *tlsKeyFile == "") {
 		flagutil.UsageError("--tls_cert_file and --tls_key_file are required if given --tls_listen")
+	} else if *embeddedUI && *publicResources != "" && strings.Trim(*uiPrefix, "/") == "" {
+		flagutil.UsageError("--embedded_ui at the root path conflicts with --public_resources; set --ui_prefix")
+	} else if gs != nil && *filetreeTable != "" {
+		flagutil.UsageError("--graphstore and --filetree_table are mutually exclusive")
 	} else if flag.NArg() > 0 {
 		flagutil.UsageErrorf("unknown non-flag arguments given: %v", flag.Args())
 	}
 
-	var (
-		xs xrefs.Service
-		gs graph.Service
-		it identifiers.Service
-		ft filetree.Service
-	)
+	if *publicResources != "" {
+		log.Info("Serving public resources at", *publicResources)
+		if s, err := os.Stat(*publicResources); err != nil {
+			log.Fatalf("ERROR: could not get FileInfo for %q: %v", *publicResources, err)
+		} else if !s.IsDir() {
+			log.Fatalf("ERROR: %q is not a directory", *publicResources)
+		}
+	}
+
+	if *navRepos != "" {
+		data, err := os.ReadFile(*navRepos)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &repos); err != nil {
+			log.Fatalf("ERROR: reading %q: %v", *navRepos, err)
+		}
+	}
+
+	if *editorSettings != "" {
+		data, err := os.ReadFile(*editorSettings)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &workspaces); err != nil {
+			log.Fatalf("ERROR: reading %q: %v", *editorSettings, err)
+		}
+	}
+
+	if *webhooks != "" {
+		data, err := os.ReadFile(*webhooks)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &hooks); err != nil {
+			log.Fatalf("ERROR: reading %q: %v", *webhooks, err)
+		}
+	}
+
+	var auditor *audit.Log
+	if *auditLog != "" {
+		var err error
+		auditor, err = audit.Open(*auditLog, nil)
+		if err != nil {
+			log.Fatalf("ERROR: opening --audit_log: %v", err)
+		}
+		defer auditor.Close()
+	}
 
 	ctx := context.Background()
-	db, err := leveldb.Open(*servingTable, &leveldb.Options{MustExist: true})
+	if gs != nil || *filetreeTable != "" {
+		var err error
+		trees, err = reload.New(ctx, loadTree)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer trees.Close()
+		trees.Audit(auditor, "file tree")
+	}
+	if *shadowTable != "" {
+		comparer = shadow.New(nil)
+	}
+	api, err := reload.New(ctx, loadAPI)
 	if err != nil {
-		log.Fatalf("Error opening db at %q: %v", *servingTable, err)
+		log.Fatal(err)
+	}
+	defer api.Close()
+	api.Audit(auditor, *servingTable)
+	if len(hooks) > 0 {
+		n := &webhook.Notifier{Hooks: hooks}
+		notify := func(ctx context.Context, a *servedAPI) {
+			go func() {
+				if err := n.Notify(ctx, a.event); err != nil {
+					log.WarningContextf(ctx, "Webhook notification failed: %v", err)
+				}
+			}()
+		}
+		cur, release := api.Acquire()
+		release()
+		notify(ctx, cur)
+		api.OnPublish(notify)
 	}
-	defer db.Close(ctx)
-	xs = xsrv.NewService(ctx, db)
-	gs = gsrv.NewService(ctx, db)
-	if *maxTicketsPerRequest > 0 {
-		xs = xrefs.BoundedRequests{
-			Service:    xs,
-			MaxTickets: *maxTicketsPerRequest,
+	api.OnSignal(ctx)
+	if *watchInterval > 0 {
+		path, _, _ := leveldb.ParseSpec(*servingTable)
+		api.Watch(ctx, path, *watchInterval)
+	}
+
+	if *adminTokenFile != "" {
+		tokens, err := admin.ReadTokens(*adminTokenFile)
+		if err != nil {
+			log.Fatalf("ERROR: reading --admin_token_file: %v", err)
 		}
-		gs = graph.BoundedRequests{
-			Service:    gs,
-			MaxTickets: *maxTicketsPerRequest,
+		h := &admin.Handler{
+			Tokens: tokens,
+			Audit:  auditor,
+			Reload: api.Reload,
+			Status: func(ctx context.Context) any { return currentStatus(api) },
 		}
+		if trees != nil {
+			h.Repopulate = trees.Reload
+		}
+		h.RegisterHTTPHandlers(ctx, http.DefaultServeMux)
 	}
-	tbl := &table.KVProto{db}
-	ft = &ftsrv.Table{Proto: tbl, PrefixedKeys: true}
-	it = &identifiers.Table{tbl}
-
-	if *httpListeningAddr != "" || *tlsListeningAddr != "" {
-		apiMux := http.NewServeMux()
-		http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-			if *httpAllowOrigin != "" {
-				w.Header().Set("Access-Control-Allow-Origin", *httpAllowOrigin)
-			}
-			apiMux.ServeHTTP(w, r)
-		})
 
-		xrefs.RegisterHTTPHandlers(ctx, xs, apiMux)
-		graph.RegisterHTTPHandlers(ctx, gs, apiMux)
-		identifiers.RegisterHTTPHandlers(ctx, it, apiMux)
-		filetree.RegisterHTTPHandlers(ctx, ft, apiMux)
-		if *publicResources != "" {
-			log.Info("Serving public resources at", *publicResources)
-			if s, err := os.Stat(*publicResources); err != nil {
-				log.Fatalf("ERROR: could not get FileInfo for %q: %v", *publicResources, err)
-			} else if !s.IsDir() {
-				log.Fatalf("ERROR: %q is not a directory", *publicResources)
-			}
-			apiMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-				http.ServeFile(w, r, filepath.Join(*publicResources, filepath.Clean(r.URL.Path)))
-			})
+	var apiHandler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if *httpAllowOrigin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", *httpAllowOrigin)
 		}
+		// Requests hold the API they started with until they finish, so a
+		// reload does not close the table beneath them.
+		apiMux, release := api.Acquire()
+		defer release()
+		apiMux.ServeHTTP(w, r)
+	})
+	if *recordRequests != "" {
+		f, err := os.OpenFile(*recordRequests, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			log.Fatalf("ERROR: opening --record_requests: %v", err)
+		}
+		log.Infof("Recording %v of requests to %s", *recordSampleRate, *recordRequests)
+		apiHandler = replay.NewRecorder(f, &replay.RecordOptions{SampleRate: *recordSampleRate}).Wrap(apiHandler)
+	}
+	if *maxRequestBodySize > 0 {
+		apiHandler = web.LimitBody(apiHandler, int64(*maxRequestBodySize))
 	}
+	http.Handle("/", apiHandler)
+	progress.RegisterHTTPHandler(http.DefaultServeMux)
 	if *httpListeningAddr != "" {
 		go startHTTP()
 	}
@@ -137,6 +268,250 @@ func main() {
 	select {} // block forever
 }
 
+// repos are the repositories of the /nav API, read from --nav_repos.
+var repos map[string]*nav.Repo
+
+// workspaces are the workspace settings of the /editor API, read from
+// --editor_settings.
+var workspaces []languageserver.Settings
+
+// hooks are the webhooks notified of each table loaded, read from --webhooks.
+var hooks []*webhook.Hook
+
+// trees holds the file tree read from --filetree_table or built from
+// --graphstore, if either is set.
+var trees *reload.Handle[filetree.Service]
+
+// loadTree opens --filetree_table, if set, or else builds an in-memory file
+// tree of the files in --graphstore.
+func loadTree(ctx context.Context) (filetree.Service, func() error, error) {
+	if *filetreeTable != "" {
+		db, path, err := openTable(*filetreeTable)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.InfoContextf(ctx, "Serving file tree from table %q", path)
+		return &ftsrv.Table{Proto: &table.KVProto{DB: db}, PrefixedKeys: true}, func() error { return db.Close(ctx) }, nil
+	}
+	m := filetree.NewMap()
+	m.SetFileFacts(*filetreeFileFacts)
+	if err := m.Populate(ctx, gs); err != nil {
+		m.Close(ctx)
+		return nil, nil, err
+	}
+	return m, func() error { return m.Close(ctx) }, nil
+}
+
+// heldTree is a filetree.Service serving the current version of trees, so
+// that a repopulated tree is served without reloading the serving table.
+type heldTree struct{}
+
+// Directory implements part of the filetree.Service interface.
+func (heldTree) Directory(ctx context.Context, req *ftpb.DirectoryRequest) (*ftpb.DirectoryReply, error) {
+	m, release := trees.Acquire()
+	defer release()
+	return m.Directory(ctx, req)
+}
+
+// CorpusRoots implements part of the filetree.Service interface.
+func (heldTree) CorpusRoots(ctx context.Context, req *ftpb.CorpusRootsRequest) (*ftpb.CorpusRootsReply, error) {
+	m, release := trees.Acquire()
+	defer release()
+	return m.CorpusRoots(ctx, req)
+}
+
+// Tree implements part of the filetree.Service interface.
+func (heldTree) Tree(ctx context.Context, req *ftpb.TreeRequest) (*ftpb.DirectoryReply, error) {
+	m, release := trees.Acquire()
+	defer release()
+	return m.Tree(ctx, req)
+}
+
+// Search implements part of the filetree.Service interface.
+func (heldTree) Search(ctx context.Context, req *ftpb.SearchRequest) (*ftpb.SearchReply, error) {
+	m, release := trees.Acquire()
+	defer release()
+	return m.Search(ctx, req)
+}
+
+// Close implements part of the filetree.Service interface.  The tree is
+// closed by trees.
+func (heldTree) Close(context.Context) error { return nil }
+
+// comparer compares the replies of the serving table with those of
+// --shadow_serving_table, if set.
+var comparer *shadow.Comparer
+
+// servingStatus is the state of the serving data reported by /admin/status.
+type servingStatus struct {
+	Snapshot      string                        `json:"snapshot"`
+	Loaded        time.Time                     `json:"loaded"`
+	Reloads       int                           `json:"reloads"`
+	FileTree      string                        `json:"filetree"`
+	Repopulations int                           `json:"repopulations,omitempty"`
+	Shadow        map[string]shadow.MethodStats `json:"shadow,omitempty"`
+}
+
+func currentStatus(api *reload.Handle[*servedAPI]) *servingStatus {
+	// Read the counts before acquiring the current version, which a reload
+	// may be waiting to retire.
+	s := &servingStatus{
+		Reloads:  api.Reloads(),
+		FileTree: "serving_table",
+	}
+	if trees != nil {
+		s.FileTree = "graphstore"
+		if *filetreeTable != "" {
+			s.FileTree = "filetree_table"
+		}
+		s.Repopulations = trees.Reloads()
+	}
+	cur, release := api.Acquire()
+	defer release()
+	s.Snapshot, s.Loaded = cur.event.Snapshot, cur.event.Time
+	if comparer != nil {
+		s.Shadow = comparer.Stats()
+	}
+	return s
+}
+
+// A servedAPI is the API served from one serving table, with the event
+// announcing it to webhooks.
+type servedAPI struct {
+	http.Handler
+	event *webhook.Event
+}
+
+// loadAPI opens the serving table and returns a handler for the API it
+// serves, with a function to close the table.  Each call opens the table
+// currently named by --serving_table, resolving any symlink.
+func loadAPI(ctx context.Context) (*servedAPI, func() error, error) {
+	db, path, err := openTable(*servingTable)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.InfoContextf(ctx, "Serving table %q", path)
+
+	var (
+		xs xrefs.Service = xsrv.NewService(ctx, db)
+		gs graph.Service = gsrv.NewService(ctx, db)
+	)
+	tbl := &table.KVProto{db}
+	var ft filetree.Service = &ftsrv.Table{Proto: tbl, PrefixedKeys: true}
+	var it identifiers.Service = &identifiers.Table{tbl}
+	closeDB := func() error { return db.Close(ctx) }
+	if comparer != nil {
+		sdb, spath, err := openTable(*shadowTable)
+		if err != nil {
+			db.Close(ctx)
+			return nil, nil, err
+		}
+		log.InfoContextf(ctx, "Shadowing reads with table %q", spath)
+		stbl := &table.KVProto{DB: sdb}
+		xs = comparer.XRefs(xs, xsrv.NewService(ctx, sdb))
+		gs = comparer.Graph(gs, gsrv.NewService(ctx, sdb))
+		ft = comparer.FileTree(ft, &ftsrv.Table{Proto: stbl, PrefixedKeys: true})
+		it = comparer.Identifiers(it, &identifiers.Table{Proto: stbl})
+		closeDB = func() error {
+			// Shadow reads outlive the requests they shadow.
+			comparer.Wait()
+			err := db.Close(ctx)
+			if serr := sdb.Close(ctx); err == nil {
+				err = serr
+			}
+			return err
+		}
+	}
+	xs = xrefs.BoundedRequests{
+		Service:            xs,
+		MaxTickets:         *maxTicketsPerRequest,
+		MaxPageSize:        *maxPageSize,
+		MaxDecorationsSpan: int(*maxDecorationsSpan),
+	}
+	gs = graph.BoundedRequests{
+		Service:     gs,
+		MaxTickets:  *maxTicketsPerRequest,
+		MaxPageSize: *maxPageSize,
+	}
+	if trees != nil {
+		ft = heldTree{}
+	}
+	ft = filetree.BoundedRequests{
+		Service:      ft,
+		MaxPathDepth: *maxPathDepth,
+		MaxPageSize:  *maxPageSize,
+		MaxTreeDepth: *maxTreeDepth,
+	}
+
+	reply, err := stats.Load(ctx, db, path)
+	if err != nil {
+		closeDB()
+		return nil, nil, fmt.Errorf("loading stats from %q: %v", path, err)
+	}
+	event := &webhook.Event{
+		Event:    webhook.IndexLoaded,
+		Time:     time.Now().UTC().Truncate(time.Second),
+		Snapshot: filepath.Base(path),
+		Stats:    reply,
+	}
+	if len(hooks) > 0 {
+		roots, err := ft.CorpusRoots(ctx, &ftpb.CorpusRootsRequest{})
+		if err != nil {
+			closeDB()
+			return nil, nil, fmt.Errorf("reading corpora from %q: %v", path, err)
+		}
+		for _, c := range roots.Corpus {
+			event.Corpora = append(event.Corpora, c.Name)
+		}
+	}
+
+	mux := http.NewServeMux()
+	xrefs.RegisterHTTPHandlers(ctx, xs, mux)
+	graph.RegisterHTTPHandlers(ctx, gs, mux)
+	identifiers.RegisterHTTPHandlers(ctx, it, mux)
+	filetree.RegisterHTTPHandlers(ctx, ft, mux)
+	nav.RegisterHTTPHandlers(ctx, &nav.Service{XRefs: xs, Repos: repos}, mux)
+	stats.RegisterHTTPHandlers(ctx, reply, mux)
+	if workspaces != nil {
+		es, err := editor.NewServer(xs, workspaces)
+		if err != nil {
+			closeDB()
+			return nil, nil, err
+		}
+		editor.RegisterHTTPHandlers(ctx, es, mux)
+	}
+	if *embeddedUI {
+		if err := webui.Register(mux, &webui.Options{Prefix: *uiPrefix}); err != nil {
+			closeDB()
+			return nil, nil, err
+		}
+	}
+	if *publicResources != "" {
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, filepath.Join(*publicResources, filepath.Clean(r.URL.Path)))
+		})
+	}
+	return &servedAPI{mux, event}, closeDB, nil
+}
+
+// openTable opens the serving table given by spec, resolving any symlink,
+// and returns it with its path.
+func openTable(spec string) (keyvalue.DB, string, error) {
+	path, opts, err := leveldb.ParseSpec(spec)
+	if err != nil {
+		return nil, "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	opts.MustExist = true
+	db, err := leveldb.Open(path, opts)
+	if err != nil {
+		return nil, "", fmt.Errorf("opening db at %q: %v", path, err)
+	}
+	return db, path, nil
+}
+
 func startHTTP() {
 	log.Infof("HTTP server listening on %q", *httpListeningAddr)
 	log.Fatal(http.ListenAndServe(*httpListeningAddr, nil))
@@ -17,6 +17,10 @@
 // Binary kythe exposes a CLI interface to the xrefs and filetree
 // services backed by a combined serving table.
 //
+// Server profiles may be defined in a JSON configuration file (by default
+// kythe/cli.json in the user's configuration directory) and selected with
+// --profile; see cli.Config for its format.
+//
 // Examples:
 //
 //	# Show complete command listing
@@ -25,12 +29,34 @@
 //	# List all corpus root uris
 //	kythe --api /path/to/table ls --uris
 //
+//	# List the corpus roots of the server configured as the "staging" profile
+//	kythe --profile staging ls
+//
+//	# Check that the staging server answers each kind of query, and how quickly
+//	kythe --profile staging doctor
+//
 //	# List root directory contents for corpus named 'somecorpus'
 //	kythe --api /path/to/table ls kythe://somecorpus
 //
 //	# List Kythe's kythe/cxx/common directory (as URIs)
 //	kythe --api /path/to/table ls --uris kythe://kythe?path=kythe/cxx/common
 //
+//	# Recursively list kythe/go/util with each entry's kind and build configs
+//	kythe --api /path/to/table ls -R -l kythe://kythe?path=kythe/go/util
+//
+//	# Load the references to the symbol at line 42, column 7 into vim
+//	kythe --api /path/to/table refs --corpus kythe kythe/go/util/log/log.go:42:7 > refs.txt
+//	vim -q refs.txt
+//
+//	# Show the definition of the same symbol
+//	kythe --api /path/to/table defs --corpus kythe kythe/go/util/log/log.go:42:7
+//
+//	# Show lines 10-19 of the indexed text of a file, with line numbers
+//	kythe --api /path/to/table source -n --span 10-20 --corpus kythe kythe/go/util/log/log.go
+//
+//	# Show the revision and digest of the indexed text, to compare with a working tree
+//	kythe --api /path/to/table source --info --corpus kythe kythe/go/util/log/log.go
+//
 //	# Display all file anchor decorations for kythe/cxx/common/CommandLineUtils.cc
 //	kythe --api /path/to/table decor kythe://kythe?lang=c%2B%2B?path=kythe/cxx/common/CommandLineUtils.cc
 //
@@ -40,30 +66,94 @@
 //	# Show reverse /kythe/edge/defines edges for a node
 //	kythe --api /path/to/table edges --kinds '%/kythe/edge/defines' kythe://kythe?lang=java?path=kythe/java/com/google/devtools/kythe/analyzers/base/EntrySet.java#1887f665ee4c77287d1022c151000a489e17147215309818cf4150c601442cc5
 //
+//	# Print the nodes within 3 ref or childof edges of a node as a dot graph
+//	kythe --api /path/to/table walk --edges=ref,childof --depth=3 --graphviz kythe:?lang=java#java.util.List | dot -Tsvg > list.svg
+//
 //	# Show all facts (except /kythe/text) for a node
 //	kythe --api /path/to/table node kythe:?lang=c%2B%2B#StripPrefix%3Acommon%3Akythe%23n%23D%40kythe%2Fcxx%2Fcommon%2FCommandLineUtils.cc%3A167%3A1
+//
+//	# Explore the graph interactively, starting from a file
+//	kythe --api /path/to/table shell
+//	kythe /> cd kythe://kythe?path=kythe/go/util/log
+//	kythe kythe/kythe/go/util/log> open log.go
+//	kythe kythe/kythe/go/util/log [kythe://kythe?path=kythe/go/util/log/log.go]> follow %childof
+//
+//	# Resolve the definitions of many locations in one process, one JSON line each
+//	kythe --api /path/to/table --batch defs --corpus kythe < locations.txt > defs.jsonl
+//
+//	# Count the references in each file, using tab-separated output
+//	kythe --api /path/to/table --format=tsv refs --corpus kythe kythe/go/util/log/log.go:42:7 | cut -f1 | sort | uniq -c
 package main
 
 import (
 	"context"
 	"flag"
 	"os"
+	"strings"
 
 	"kythe.io/kythe/go/services/cli"
 	"kythe.io/kythe/go/serving/api"
+	"kythe.io/kythe/go/util/log"
+)
+
+var (
+	configPath  = flag.String("config", cli.DefaultConfigPath(), "Path of the JSON file defining --profile names")
+	profileName = flag.String("profile", "", "Name of the configured server profile to use (default: the config's default_profile)")
 )
 
 func main() {
 	apiFlag := api.Flag("api", api.CommonDefault, api.CommonFlagUsage)
 	flag.Parse()
 
+	svc, err := openAPI(*apiFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
 	ctx := context.Background()
 	status := cli.Execute(ctx, cli.API{
-		XRefService:       *apiFlag,
-		GraphService:      *apiFlag,
-		FileTreeService:   *apiFlag,
-		IdentifierService: *apiFlag,
+		XRefService:       svc,
+		GraphService:      svc,
+		FileTreeService:   svc,
+		IdentifierService: svc,
 	})
-	(*apiFlag).Close(ctx)
+	svc.Close(ctx)
 	os.Exit(int(status))
 }
+
+// openAPI returns the API selected by the --api and --profile flags.  An
+// explicit --api overrides the server of the profile, but its other
+// settings still apply to a web API.
+func openAPI(flagAPI api.Interface) (api.Interface, error) {
+	cfg, err := cli.LoadConfig(*configPath)
+	if err != nil {
+		return nil, err
+	}
+	p, err := cfg.Profile(*profileName)
+	if err != nil || p == nil {
+		return flagAPI, err
+	}
+	p.Apply()
+
+	spec := p.API
+	if apiSet() || spec == "" {
+		spec = flag.Lookup("api").Value.String()
+	}
+	if !strings.HasPrefix(spec, "http://") && !strings.HasPrefix(spec, "https://") {
+		if apiSet() {
+			return flagAPI, nil
+		}
+		return api.ParseSpec(spec)
+	}
+	opts, err := p.WebOptions()
+	if err != nil {
+		return nil, err
+	}
+	return api.ParseSpecWithOptions(spec, opts)
+}
+
+// apiSet reports whether the --api flag was given.
+func apiSet() bool {
+	var set bool
+	flag.Visit(func(f *flag.Flag) { set = set || f.Name == "api" })
+	return set
+}
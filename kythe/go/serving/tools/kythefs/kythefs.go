@@ -33,7 +33,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"kythe.io/kythe/go/services/web"
 	"kythe.io/kythe/go/serving/api"
 	"kythe.io/kythe/go/util/flagutil"
 	"kythe.io/kythe/go/util/kytheuri"
@@ -54,13 +56,22 @@ var (
 		"The address of the Kythe service to use. For example http://localhost:8080.")
 	mountPoint = flag.String("mountpoint", "",
 		"Path to existing directory to mount KytheFS at.")
+	cacheSize = flag.Int("cache_size", 0,
+		"If positive, the number of server replies to cache.")
+	cacheTTL = flag.Duration("cache_ttl", time.Minute,
+		"How long a cached server reply is used before it is revalidated.")
+	timeout = flag.Duration("timeout", 30*time.Second,
+		"Timeout for each request to the server, or 0 for none.")
+	maxRetries = flag.Int("max_retries", 2,
+		"Number of times a failed request to the server is retried.")
 )
 
 func init() {
 	flag.Usage = flagutil.SimpleUsage("Mounts file content stored in Kythe as a virtual filesystem.",
 		"The files are laid out on a path <corpus>/<root>/<path>.",
 		"(--mountpoint MOUNT_PATH)",
-		"[--server SERVER_ADDRESS]")
+		"[--server SERVER_ADDRESS]",
+		"[--cache_size N]")
 }
 
 type kytheFS struct {
@@ -413,7 +424,14 @@ func main() {
 		log.Fatal("You must provide --mountpoint")
 	}
 
-	kytheAPI, err := api.ParseSpec(*serverAddr)
+	opts := &web.Options{
+		Timeout:    *timeout,
+		MaxRetries: *maxRetries,
+	}
+	if *cacheSize > 0 {
+		opts.Cache = web.NewCache(*cacheSize, *cacheTTL)
+	}
+	kytheAPI, err := api.ParseSpecWithOptions(*serverAddr, opts)
 	if err != nil {
 		log.Fatal("Failed to parse server address!", *serverAddr)
 	}
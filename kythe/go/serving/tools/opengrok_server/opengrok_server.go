@@ -0,0 +1,63 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary opengrok_server serves a subset of the OpenGrok REST API from the
+// Kythe services, so that frontends written for OpenGrok can use a Kythe
+// index.  See package opengrok for the methods served.
+//
+// Example:
+//
+//	opengrok_server --api http://localhost:8080 --listen localhost:8081
+//	curl 'localhost:8081/api/v1/search?def=kythe.io/kythe/go/util/kytheuri.Parse'
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+
+	"kythe.io/kythe/go/services/opengrok"
+	"kythe.io/kythe/go/serving/api"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+)
+
+var listen = flag.String("listen", "localhost:8081", "Listening address for the HTTP server")
+
+func init() {
+	flag.Usage = flagutil.SimpleUsage("Serves the OpenGrok REST API from the Kythe services",
+		"[--api spec] [--listen addr]")
+}
+
+func main() {
+	apiFlag := api.Flag("api", api.CommonDefault, api.CommonFlagUsage)
+	flag.Parse()
+	if flag.NArg() > 0 {
+		flagutil.UsageErrorf("unknown non-flag arguments given: %v", flag.Args())
+	}
+
+	ctx := context.Background()
+	svc := *apiFlag
+
+	mux := http.NewServeMux()
+	opengrok.RegisterHTTPHandlers(ctx, &opengrok.Server{
+		XRefs:       svc,
+		Identifiers: svc,
+		FileTree:    svc,
+	}, mux)
+	log.Infof("OpenGrok API listening on %q", *listen)
+	log.Fatal(http.ListenAndServe(*listen, mux))
+}
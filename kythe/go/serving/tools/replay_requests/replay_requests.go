@@ -0,0 +1,89 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary replay_requests reissues the requests recorded by an http_server
+// run with --record_requests against another server, and summarizes the
+// replies, to load test a new release with production-shaped traffic:
+//
+//	http_server --serving_table /srv/table --record_requests /tmp/requests.json ...
+//	replay_requests --server http://canary:8080 --rate 200 /tmp/requests.json
+//
+// Without --rate, requests are issued at the pace at which they were
+// recorded, scaled by --speed.  Interrupting the replay prints a summary of
+// the requests issued so far.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"kythe.io/kythe/go/serving/replay"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+)
+
+var (
+	server      = flag.String("server", "", "Base URL of the server to which requests are issued (e.g. http://localhost:8080)")
+	rate        = flag.Float64("rate", 0, "If positive, the number of requests issued per second; otherwise requests are issued at their recorded pace")
+	speed       = flag.Float64("speed", 1, "Factor by which to speed up the recorded pace of requests, if --rate is unset")
+	concurrency = flag.Int("concurrency", replay.DefaultConcurrency, "Maximum number of requests in flight")
+	jsonOutput  = flag.Bool("json", false, "Print the summary as JSON")
+)
+
+func init() {
+	flag.Usage = flagutil.SimpleUsage("Replays recorded requests against a Kythe HTTP server",
+		"--server url [--rate qps | --speed factor] [--concurrency n] [--json] requests-file")
+}
+
+func main() {
+	flag.Parse()
+	if *server == "" {
+		flagutil.UsageError("missing --server")
+	} else if flag.NArg() != 1 {
+		flagutil.UsageError("expected exactly one requests file")
+	} else if *rate < 0 || *speed <= 0 {
+		flagutil.UsageError("--rate must not be negative and --speed must be positive")
+	}
+
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	sum, err := replay.Replay(ctx, *server, replay.NewReader(f), &replay.ReplayOptions{
+		Rate:        *rate,
+		Speed:       *speed,
+		Concurrency: *concurrency,
+	})
+	if err != nil && err != context.Canceled {
+		log.Errorf("Replay stopped: %v", err)
+	}
+	if *jsonOutput {
+		if err := json.NewEncoder(os.Stdout).Encode(sum); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		fmt.Println(sum)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary write_filetree writes the file tree of a GraphStore to a LevelDB
+// table, so that http_server can serve it with --filetree_table rather than
+// scanning the GraphStore each time it starts:
+//
+//	write_filetree --graphstore /srv/gs --out /srv/filetree
+//	http_server --serving_table /srv/table --filetree_table /srv/filetree ...
+//
+// The table holds the same records as the file tree of a combined serving
+// table written by write_tables.  With --spill_budget, directories beyond the
+// budget are kept in a temporary table while the tree is built, so that a
+// large tree needs no more than about that much memory.  With --file_facts,
+// the table records the size and encoding of each file as well, at the cost of
+// reading the text of every file.
+package main
+
+import (
+	"context"
+	"flag"
+
+	"kythe.io/kythe/go/services/filetree"
+	"kythe.io/kythe/go/services/graphstore"
+	ftsrv "kythe.io/kythe/go/serving/filetree"
+	"kythe.io/kythe/go/storage/gsutil"
+	"kythe.io/kythe/go/storage/leveldb"
+	"kythe.io/kythe/go/storage/table"
+	"kythe.io/kythe/go/util/datasize"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+
+	_ "kythe.io/kythe/go/services/graphstore/proxy"
+)
+
+var (
+	gs graphstore.Service
+
+	tablePath   = flag.String("out", "", "Directory path of the LevelDB table to write")
+	spillBudget = datasize.Flag("spill_budget", "0", "If positive, the memory to use for the tree's directories while it is built, beyond which they are spilled to a temporary table")
+	fileFacts   = flag.Bool("file_facts", false, "Whether to record the size and encoding of each file, which reads the text of every file")
+)
+
+func init() {
+	gsutil.Flag(&gs, "graphstore", "GraphStore whose files form the tree")
+	flag.Usage = flagutil.SimpleUsage("Writes the file tree of a GraphStore to a LevelDB table served by http_server --filetree_table",
+		"--graphstore spec --out path [--spill_budget size] [--file_facts]")
+}
+
+func main() {
+	flag.Parse()
+	if gs == nil {
+		flagutil.UsageError("missing --graphstore")
+	} else if *tablePath == "" {
+		flagutil.UsageError("missing --out")
+	} else if flag.NArg() > 0 {
+		flagutil.UsageErrorf("unknown non-flag arguments given: %v", flag.Args())
+	}
+	ctx := context.Background()
+	defer gs.Close(ctx)
+
+	m := filetree.NewMap()
+	if *spillBudget > 0 {
+		scratch, err := leveldb.OpenTemp(nil)
+		if err != nil {
+			log.Fatalf("Error opening spill table: %v", err)
+		}
+		m = filetree.NewSpillingMap(scratch, *spillBudget)
+	}
+	defer m.Close(ctx)
+	m.SetFileFacts(*fileFacts)
+	if err := m.Populate(ctx, gs); err != nil {
+		log.Fatalf("Error reading file tree: %v", err)
+	}
+
+	db, err := leveldb.Open(*tablePath, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ftsrv.Write(ctx, m, &table.KVProto{DB: db}); err != nil {
+		log.Fatalf("Error writing file tree: %v", err)
+	}
+	if err := db.Close(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -16,22 +16,49 @@
 
 // Binary write_tables creates a combined xrefs/filetree/search serving table
 // based on a given GraphStore.
+//
+// In non-beam mode, --entries may name several GraphStore-ordered entry
+// streams (e.g., the outputs of different indexers), which are merged.
+// Entries for the same node and fact with different values are conflicts,
+// resolved by --conflict_policy:
+//
+//	first_input      keep the value from the first stream (the default)
+//	last_write       keep the value from the last stream
+//	prefer_language  keep the value from the first stream whose indexer's
+//	                 language (see --input_languages) is that of the node
+//	error            fail
+//
+// Conflicts are counted in the log, and with --conflicts, written as a JSON
+// stream for inspection.  A GraphStore holds one value for each fact, so it
+// has no conflicts.
+//
+// Example:
+//
+//	write_tables --entries go.entries,java.entries --input_languages go,java \
+//	  --conflict_policy prefer_language --conflicts conflicts.json --out table
 package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
+	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
 	"kythe.io/kythe/go/platform/vfs"
 	"kythe.io/kythe/go/services/graphstore"
 	"kythe.io/kythe/go/serving/pipeline"
 	"kythe.io/kythe/go/serving/pipeline/beamio"
+	tablestats "kythe.io/kythe/go/serving/stats"
 	"kythe.io/kythe/go/serving/xrefs"
 	"kythe.io/kythe/go/storage/gsutil"
+	"kythe.io/kythe/go/storage/keyvalue"
 	"kythe.io/kythe/go/storage/leveldb"
 	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/storage/stream/merge"
 	"kythe.io/kythe/go/util/flagutil"
 	"kythe.io/kythe/go/util/log"
 	"kythe.io/kythe/go/util/profile"
@@ -49,11 +76,15 @@ import (
 var (
 	gs          graphstore.Service
 	entriesFile = flag.String("entries", "",
-		"In non-beam mode: path to GraphStore-ordered entries file (mutually exclusive with --graphstore).\n"+
+		"In non-beam mode: comma-separated paths to GraphStore-ordered entries files (mutually exclusive with --graphstore).\n"+
 			"In beam mode: path to an unordered entries file, or if ending with slash, a directory containing such files.")
 
 	tablePath = flag.String("out", "", "Directory path to output serving table")
 
+	conflictPolicy = flag.String("conflict_policy", string(merge.FirstInput), "In non-beam mode: policy resolving --entries that give different values for the same fact (first_input, last_write, prefer_language, or error)")
+	inputLanguages = flag.String("input_languages", "", "In non-beam mode: comma-separated languages of the indexers that produced each of --entries, for --conflict_policy=prefer_language")
+	conflictsPath  = flag.String("conflicts", "", "In non-beam mode: if set, write a JSON stream describing each conflicting entry to this path")
+
 	maxPageSize = flag.Int("max_page_size", 4000,
 		"If positive, edge/cross-reference pages are restricted to under this number of edges/references")
 	compressShards = flag.Bool("compress_shards", false,
@@ -76,7 +107,7 @@ func init() {
 	gsutil.Flag(&gs, "graphstore", "GraphStore to read (mutually exclusive with --entries)")
 	flag.Usage = flagutil.SimpleUsage(
 		"Creates a combined xrefs/filetree/search serving table based on a given GraphStore or stream of GraphStore-ordered entries",
-		"(--graphstore spec | --entries path) --out path")
+		"(--graphstore spec | --entries path[,path...] [--conflict_policy p] [--conflicts path]) --out path")
 }
 
 func main() {
@@ -87,6 +118,9 @@ func main() {
 		if err := runExperimentalBeamPipeline(ctx); err != nil {
 			log.Fatalf("Pipeline error: %v", err)
 		}
+		if err := addTableSizes(ctx, *tablePath); err != nil {
+			log.Fatalf("Error writing table statistics: %v", err)
+		}
 		if *compactTable {
 			if err := compactLevelDB(*tablePath); err != nil {
 				log.Fatalf("Error compacting LevelDB: %v", err)
@@ -121,15 +155,30 @@ func main() {
 			return gs.Scan(ctx, &spb.ScanRequest{}, f)
 		}
 	} else {
-		f, err := vfs.Open(ctx, *entriesFile)
+		paths := strings.Split(*entriesFile, ",")
+		var inputs []stream.EntryReader
+		for _, path := range paths {
+			f, err := vfs.Open(ctx, path)
+			if err != nil {
+				log.Fatalf("Error opening %q: %v", path, err)
+			}
+			defer f.Close()
+			inputs = append(inputs, stream.NewReader(f))
+		}
+		opts, done, err := mergeOptions(ctx, paths)
 		if err != nil {
-			log.Fatalf("Error opening %q: %v", *entriesFile, err)
+			log.Fatal(err)
 		}
-		defer f.Close()
-		rd = stream.NewReader(f)
+		defer done()
+		var mstats merge.Stats
+		defer func() {
+			log.Infof("Merged %d entries (%d duplicates, %d conflicts dropped)", mstats.Read, mstats.Duplicates, mstats.Conflicts)
+		}()
+		rd = merge.Merge(opts, &mstats, inputs...)
 	}
 
-	if err := pipeline.Run(ctx, rd, db, &pipeline.Options{
+	collector := tablestats.NewCollector()
+	if err := pipeline.Run(ctx, collector.Reader(rd), db, &pipeline.Options{
 		Verbose:        *verbose,
 		MaxPageSize:    *maxPageSize,
 		CompressShards: *compressShards,
@@ -137,6 +186,9 @@ func main() {
 	}); err != nil {
 		log.Fatal("FATAL ERROR: ", err)
 	}
+	if err := writeStats(ctx, db, collector.Stats()); err != nil {
+		log.Fatalf("Error writing table statistics: %v", err)
+	}
 
 	if *compactTable {
 		if err := compactLevelDB(*tablePath); err != nil {
@@ -145,11 +197,86 @@ func main() {
 	}
 }
 
+// conflict is the JSON representation of a merge.Conflict.
+type conflict struct {
+	Kept         *spb.Entry `json:"kept"`
+	KeptInput    string     `json:"kept_input"`
+	Dropped      *spb.Entry `json:"dropped"`
+	DroppedInput string     `json:"dropped_input"`
+}
+
+// mergeOptions returns the options for merging the entries at paths, as given
+// by the flags, with a function to call once the merge is done.
+func mergeOptions(ctx context.Context, paths []string) (*merge.Options, func(), error) {
+	policy, err := merge.ParsePolicy(*conflictPolicy)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := &merge.Options{Policy: policy}
+	if *inputLanguages != "" {
+		opts.Languages = strings.Split(*inputLanguages, ",")
+		if len(opts.Languages) != len(paths) {
+			return nil, nil, fmt.Errorf("--input_languages gives %d languages for %d --entries", len(opts.Languages), len(paths))
+		}
+	} else if policy == merge.PreferLanguage {
+		return nil, nil, errors.New("--conflict_policy=prefer_language requires --input_languages")
+	}
+	if *conflictsPath == "" {
+		return opts, func() {}, nil
+	}
+
+	f, err := vfs.Create(ctx, *conflictsPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating conflicts file: %v", err)
+	}
+	enc := json.NewEncoder(f)
+	opts.OnConflict = func(c *merge.Conflict) error {
+		return enc.Encode(conflict{
+			Kept:         c.Kept,
+			KeptInput:    paths[c.KeptStream],
+			Dropped:      c.Dropped,
+			DroppedInput: paths[c.DroppedStream],
+		})
+	}
+	return opts, func() {
+		if err := f.Close(); err != nil {
+			log.Errorf("closing conflicts file: %v", err)
+		}
+	}, nil
+}
+
 func compactLevelDB(path string) error {
 	defer func(start time.Time) { log.Infof("Compaction completed in %s", time.Since(start)) }(time.Now())
 	return leveldb.CompactRange(*tablePath, nil)
 }
 
+// writeStats stores s in db, with the sizes of the tables of db.
+func writeStats(ctx context.Context, db keyvalue.DB, s *tablestats.Stats) error {
+	sizes, err := tablestats.TableSizes(ctx, db)
+	if err != nil {
+		return err
+	}
+	s.Tables = sizes
+	return tablestats.Write(ctx, db, s)
+}
+
+// addTableSizes adds the sizes of the tables of the table at path to the
+// statistics written by the Beam pipeline.
+func addTableSizes(ctx context.Context, path string) error {
+	db, err := leveldb.Open(path, nil)
+	if err != nil {
+		return err
+	}
+	defer db.Close(ctx)
+	s, err := tablestats.Read(ctx, db)
+	if err != nil {
+		return err
+	} else if s == nil {
+		s = tablestats.NewCollector().Stats()
+	}
+	return writeStats(ctx, db, s)
+}
+
 func runExperimentalBeamPipeline(ctx context.Context) error {
 	if runnerFlag := flag.Lookup("runner"); runnerFlag.Value.String() == "direct" {
 		runnerFlag.Value.Set("disksort")
@@ -186,6 +313,7 @@ func runExperimentalBeamPipeline(ctx context.Context) error {
 	if *experimentalColumnarData {
 		beamio.WriteLevelDB(s, *tablePath, opts,
 			createColumnarMetadata(s),
+			collectStats(s, entries),
 			k.SplitCrossReferences(),
 			k.SplitDecorations(),
 			k.CorpusRoots(),
@@ -197,6 +325,7 @@ func runExperimentalBeamPipeline(ctx context.Context) error {
 		edgeSets, edgePages := k.Edges()
 		xrefSets, xrefPages := k.CrossReferences()
 		beamio.WriteLevelDB(s, *tablePath, opts,
+			collectStats(s, entries),
 			k.CorpusRoots(),
 			k.Decorations(),
 			k.Directories(),
@@ -211,6 +340,9 @@ func runExperimentalBeamPipeline(ctx context.Context) error {
 
 func init() {
 	beam.RegisterFunction(emitColumnarMetadata)
+	beam.RegisterFunction(emitStats)
+	beam.RegisterType(reflect.TypeOf((*combineStats)(nil)).Elem())
+	beam.RegisterType(reflect.TypeOf((*tablestats.Collector)(nil)).Elem())
 }
 
 func createColumnarMetadata(s beam.Scope) beam.PCollection {
@@ -218,3 +350,30 @@ func createColumnarMetadata(s beam.Scope) beam.PCollection {
 }
 
 func emitColumnarMetadata(_ []byte) (string, string) { return xrefs.ColumnarTableKeyMarker, "v1" }
+
+// collectStats returns the table statistics of the given entries, keyed for
+// the table.
+func collectStats(s beam.Scope, entries beam.PCollection) beam.PCollection {
+	return beam.ParDo(s, emitStats, beam.Combine(s, &combineStats{}, entries))
+}
+
+func emitStats(c *tablestats.Collector) (string, string, error) {
+	rec, err := json.Marshal(c.Stats())
+	return tablestats.Key, string(rec), err
+}
+
+type combineStats struct{}
+
+func (combineStats) CreateAccumulator() *tablestats.Collector { return tablestats.NewCollector() }
+
+func (combineStats) AddInput(c *tablestats.Collector, e *spb.Entry) *tablestats.Collector {
+	c.Add(e)
+	return c
+}
+
+func (combineStats) MergeAccumulators(c, o *tablestats.Collector) *tablestats.Collector {
+	c.Merge(o)
+	return c
+}
+
+func (combineStats) ExtractOutput(c *tablestats.Collector) *tablestats.Collector { return c }
@@ -0,0 +1,204 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package webhook notifies other systems, such as search frontends and bots,
+// when a server loads a new index.
+//
+// A Notifier POSTs an Event as JSON to the URL of each of its hooks:
+//
+//	{
+//	  "event": "index.loaded",
+//	  "time": "2026-10-16T12:00:00Z",
+//	  "corpora": ["github.com/org/repo"],
+//	  "snapshot": "20261016",
+//	  "stats": {"schema_version": 1, "table": {...}, "index": {...}}
+//	}
+//
+// where "stats" is the reply of the server's /stats endpoint.  The request
+// carries the event type in its X-Kythe-Event header and, if the hook has a
+// secret, the hex-encoded HMAC-SHA256 of the body keyed by the secret in its
+// X-Kythe-Signature header, as "sha256=<hex>", so that a receiver can check
+// that the notification is genuine.
+package webhook // import "kythe.io/kythe/go/serving/webhook"
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"kythe.io/kythe/go/serving/stats"
+)
+
+// IndexLoaded is the type of the Event sent when a server loads an index.
+const IndexLoaded = "index.loaded"
+
+// Headers of a notification request.
+const (
+	EventHeader     = "X-Kythe-Event"
+	SignatureHeader = "X-Kythe-Signature"
+)
+
+// A Hook is a URL to notify of events.
+type Hook struct {
+	URL string `json:"url"`
+
+	// Headers are added to each request, as for authorization.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Secret, if set, is the key with which requests are signed.
+	Secret string `json:"secret,omitempty"`
+}
+
+// An Event is the payload of a notification.
+type Event struct {
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+
+	// Corpora are the corpora with files in the index.
+	Corpora []string `json:"corpora"`
+
+	// Snapshot identifies the version of the index: the name of the table
+	// directory, to which the served path is typically repointed for each
+	// new version.
+	Snapshot string `json:"snapshot"`
+
+	Stats *stats.Reply `json:"stats"`
+}
+
+// Defaults for a Notifier.
+const (
+	DefaultAttempts = 3
+	DefaultBackoff  = time.Second
+	DefaultTimeout  = 30 * time.Second
+)
+
+// A Notifier sends events to a set of hooks.
+type Notifier struct {
+	Hooks []*Hook
+
+	// Client sends the requests.  If nil, a client with DefaultTimeout is
+	// used.
+	Client *http.Client
+
+	// Attempts is the number of times a request to a hook is tried before
+	// giving up.  If zero, DefaultAttempts is used.
+	Attempts int
+
+	// Backoff is the delay before the first retry, which doubles with each
+	// retry thereafter.  If zero, DefaultBackoff is used.
+	Backoff time.Duration
+}
+
+var defaultClient = &http.Client{Timeout: DefaultTimeout}
+
+// Notify sends ev to each hook of n concurrently, retrying requests that fail
+// for want of a response or with a 408, 429 or 5xx status.  It returns once
+// each hook has been notified or has failed, with the errors of the failures.
+func (n *Notifier) Notify(ctx context.Context, ev *Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	errs := make([]error, len(n.Hooks))
+	var wg sync.WaitGroup
+	for i, h := range n.Hooks {
+		wg.Add(1)
+		go func(i int, h *Hook) {
+			defer wg.Done()
+			if err := n.send(ctx, h, ev.Event, body); err != nil {
+				errs[i] = fmt.Errorf("notifying %s: %w", h.URL, err)
+			}
+		}(i, h)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// send posts body to h, retrying as described by Notify.
+func (n *Notifier) send(ctx context.Context, h *Hook, event string, body []byte) error {
+	attempts, backoff := n.Attempts, n.Backoff
+	if attempts <= 0 {
+		attempts = DefaultAttempts
+	}
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+	var err error
+	for i := 0; ; i++ {
+		var retry bool
+		if retry, err = n.post(ctx, h, event, body); err == nil || !retry || i+1 == attempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post makes one request to h, reporting whether a failure may be retried.
+func (n *Notifier) post(ctx context.Context, h *Hook, event string, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for k, v := range h.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	if h.Secret != "" {
+		req.Header.Set(SignatureHeader, Signature(h.Secret, body))
+	}
+
+	client := n.Client
+	if client == nil {
+		client = defaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+	switch c := res.StatusCode; {
+	case c >= 200 && c <= 299:
+		return false, nil
+	case c == http.StatusRequestTimeout, c == http.StatusTooManyRequests, c >= 500:
+		return true, errors.New(res.Status)
+	default:
+		return false, errors.New(res.Status)
+	}
+}
+
+// Signature returns the value of the X-Kythe-Signature header of a request
+// with the given body, signed with secret.
+func Signature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
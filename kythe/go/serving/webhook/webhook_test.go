@@ -0,0 +1,105 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kythe.io/kythe/go/serving/stats"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNotify(t *testing.T) {
+	ev := &Event{
+		Event:    IndexLoaded,
+		Time:     time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+		Corpora:  []string{"kythe"},
+		Snapshot: "20261016",
+		Stats:    &stats.Reply{SchemaVersion: stats.SchemaVersion, Table: &stats.TableInfo{Path: "/srv/20261016"}},
+	}
+
+	var (
+		got   []*Event
+		calls atomic.Int32
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/flaky":
+			// Fails once before succeeding.
+			if calls.Add(1) == 1 {
+				http.Error(w, "try again", http.StatusServiceUnavailable)
+				return
+			}
+		case "/rejecting":
+			http.Error(w, "no", http.StatusForbidden)
+			return
+		}
+		if r.Header.Get(EventHeader) != IndexLoaded || r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("%s: unexpected headers %v", r.URL.Path, r.Header)
+		}
+		if sig := r.Header.Get(SignatureHeader); sig != Signature("secret", body) {
+			t.Errorf("%s: got signature %q, want %q", r.URL.Path, sig, Signature("secret", body))
+		}
+		var e Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			t.Errorf("%s: %v", r.URL.Path, err)
+		}
+		got = append(got, &e)
+	}))
+	defer srv.Close()
+
+	hook := func(path string) *Hook {
+		return &Hook{
+			URL:     srv.URL + path,
+			Headers: map[string]string{"Authorization": "Bearer token"},
+			Secret:  "secret",
+		}
+	}
+	n := &Notifier{Hooks: []*Hook{hook("/flaky")}, Backoff: time.Millisecond}
+	if err := n.Notify(context.Background(), ev); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("Flaky hook called %d times, want 2", calls.Load())
+	}
+	if diff := cmp.Diff([]*Event{ev}, got); diff != "" {
+		t.Errorf("Events received (-want +got):\n%s", diff)
+	}
+
+	n.Hooks = []*Hook{hook("/rejecting")}
+	if err := n.Notify(context.Background(), ev); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Notify of rejecting hook: got error %v, want 403", err)
+	}
+}
+
+func TestSignature(t *testing.T) {
+	// From the HMAC-SHA256 test vectors of RFC 4231, test case 2.
+	const want = "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if got := Signature("Jefe", []byte("what do ya want for nothing?")); got != want {
+		t.Errorf("Signature: got %q, want %q", got, want)
+	}
+}
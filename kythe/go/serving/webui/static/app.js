@@ -0,0 +1,248 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// A small browser for a Kythe index, using the JSON HTTP APIs of the server.
+// Views are selected by the location hash:
+//
+//   #                                  the corpora and their roots
+//   #dir?corpus=c&root=r&path=p        a directory
+//   #file?corpus=c&root=r&path=p&line=n  a file, with a line selected
+'use strict';
+
+const API = document.querySelector('meta[name="kythe-api"]').content;
+const view = document.getElementById('view');
+const xrefsPanel = document.getElementById('xrefs');
+const crumbs = document.getElementById('crumbs');
+
+// api calls the named method of the Kythe HTTP API with a JSON request.
+async function api(method, req) {
+  const resp = await fetch(API + method, {method: 'POST', body: JSON.stringify(req)});
+  if (!resp.ok) {
+    throw new Error(method + ': ' + (await resp.text()));
+  }
+  return resp.json();
+}
+
+// el returns a new element with the given attributes and children.
+function el(tag, attrs, ...children) {
+  const e = document.createElement(tag);
+  for (const [k, v] of Object.entries(attrs || {})) {
+    if (k === 'class') {
+      e.className = v;
+    } else if (k.startsWith('on')) {
+      e.addEventListener(k.slice(2), v);
+    } else {
+      e.setAttribute(k, v);
+    }
+  }
+  e.append(...children);
+  return e;
+}
+
+function escapeComponent(s) {
+  return encodeURIComponent(s).replace(/%2F/g, '/');
+}
+
+// fileTicket returns the Kythe URI of a file.
+function fileTicket(f) {
+  let t = 'kythe://' + escapeComponent(f.corpus);
+  if (f.root) {
+    t += '?root=' + escapeComponent(f.root);
+  }
+  return t + '?path=' + escapeComponent(f.path);
+}
+
+// parseTicket returns the corpus, root and path of a Kythe URI.
+function parseTicket(ticket) {
+  const s = ticket.replace(/^kythe:(\/\/)?/, '').split('#')[0];
+  const [corpus, ...params] = s.split('?');
+  const u = {corpus: decodeURIComponent(corpus), root: '', path: ''};
+  for (const p of params) {
+    const i = p.indexOf('=');
+    if (i > 0) {
+      u[p.slice(0, i)] = decodeURIComponent(p.slice(i + 1));
+    }
+  }
+  return u;
+}
+
+function link(kind, f, line) {
+  const q = new URLSearchParams({corpus: f.corpus, root: f.root || '', path: f.path || ''});
+  if (line) {
+    q.set('line', line);
+  }
+  return '#' + kind + '?' + q;
+}
+
+function setCrumbs(f) {
+  crumbs.replaceChildren();
+  if (!f) {
+    return;
+  }
+  crumbs.append(' / ', el('a', {href: link('dir', {corpus: f.corpus, root: f.root})}, f.corpus + (f.root ? ':' + f.root : '')));
+  const parts = (f.path || '').split('/').filter((p) => p);
+  parts.forEach((p, i) => {
+    crumbs.append(' / ', el('a', {href: link('dir', {corpus: f.corpus, root: f.root, path: parts.slice(0, i + 1).join('/')})}, p));
+  });
+}
+
+async function showCorpora() {
+  setCrumbs(null);
+  const reply = await api('corpusRoots', {});
+  const list = el('ul', {class: 'listing'});
+  for (const c of reply.corpus || []) {
+    for (const root of c.root || ['']) {
+      list.append(el('li', {}, el('a', {href: link('dir', {corpus: c.name, root})}, c.name + (root ? ':' + root : ''))));
+    }
+  }
+  view.replaceChildren(el('h3', {}, 'Corpora'), list);
+}
+
+async function showDir(d) {
+  setCrumbs(d);
+  const reply = await api('dir', {corpus: d.corpus, root: d.root, path: d.path});
+  const entries = (reply.entry || []).sort((a, b) => a.name.localeCompare(b.name));
+  const list = el('ul', {class: 'listing'});
+  for (const e of entries) {
+    const f = {corpus: d.corpus, root: d.root, path: d.path ? d.path + '/' + e.name : e.name};
+    const isDir = e.kind === 'DIRECTORY';
+    list.append(el('li', {}, el('a', {href: link(isDir ? 'dir' : 'file', f), class: isDir ? 'dir' : ''}, e.name)));
+  }
+  view.replaceChildren(list);
+}
+
+// tokens splits text into runs, each of which is linked to the target of the
+// reference covering it, if any.  Nested references are dropped in favour
+// of the first and narrowest.
+function tokens(text, refs) {
+  const decoder = new TextDecoder();
+  const offset = (p) => (p && p.byte_offset) || 0;
+  refs = refs.slice().sort((a, b) =>
+    offset(a.span.start) - offset(b.span.start) || offset(a.span.end) - offset(b.span.end));
+  const out = [];
+  let pos = 0;
+  for (const r of refs) {
+    const start = offset(r.span.start);
+    const end = offset(r.span.end);
+    if (start < pos || end <= start || end > text.length) {
+      continue;
+    }
+    out.push({text: decoder.decode(text.subarray(pos, start))});
+    out.push({text: decoder.decode(text.subarray(start, end)), ref: r});
+    pos = end;
+  }
+  out.push({text: decoder.decode(text.subarray(pos))});
+  return out;
+}
+
+async function showFile(f, line) {
+  setCrumbs(f);
+  const reply = await api('decorations', {
+    location: {ticket: fileTicket(f)},
+    source_text: true,
+    references: true,
+  });
+  const text = Uint8Array.from(atob(reply.source_text || ''), (c) => c.charCodeAt(0));
+
+  const table = el('table', {class: 'source'});
+  let n = 0;
+  let code;
+  const newLine = () => {
+    n++;
+    code = el('td');
+    table.append(el('tr', {id: 'L' + n, class: n === line ? 'selected' : ''},
+        el('td', {class: 'line'}, el('a', {href: link('file', f, n)}, String(n))), code));
+  };
+  newLine();
+  for (const t of tokens(text, reply.reference || [])) {
+    t.text.split('\n').forEach((s, i) => {
+      if (i > 0) {
+        newLine();
+      }
+      if (!t.ref) {
+        code.append(s);
+        return;
+      }
+      const target = t.ref.target_ticket;
+      code.append(el('a', {href: 'javascript:void 0', class: 'ref', title: t.ref.kind, onclick: () => showXRefs(target)}, s));
+    });
+  }
+  view.replaceChildren(table);
+  const selected = document.getElementById('L' + line);
+  if (selected) {
+    selected.scrollIntoView({block: 'center'});
+  }
+}
+
+async function showXRefs(ticket) {
+  xrefsPanel.hidden = false;
+  xrefsPanel.replaceChildren('Loading…');
+  const [xrefs, docs] = await Promise.all([
+    api('xrefs', {
+      ticket: [ticket],
+      definition_kind: 'BINDING_DEFINITIONS',
+      declaration_kind: 'ALL_DECLARATIONS',
+      reference_kind: 'ALL_REFERENCES',
+      snippets: 'DEFAULT',
+      page_size: 200,
+    }),
+    api('documentation', {ticket: [ticket]}).catch(() => ({})),
+  ]);
+  const children = [el('h3', {}, ticket)];
+  const doc = ((docs.document || [])[0] || {}).text;
+  if (doc && doc.raw_text) {
+    children.push(el('div', {class: 'doc'}, doc.raw_text.replace(/\\(.)|[\[\]]/g, (m, c) => c || '')));
+  }
+  const set = (xrefs.cross_references || {})[ticket] || {};
+  for (const [title, anchors] of [['Definitions', set.definition], ['Declarations', set.declaration], ['References', set.reference]]) {
+    if (!anchors || !anchors.length) {
+      continue;
+    }
+    const list = el('ul', {class: 'listing'});
+    for (const {anchor} of anchors) {
+      const f = parseTicket(anchor.parent);
+      const line = (anchor.span.start || {}).line_number || 1;
+      list.append(el('li', {},
+          el('a', {href: link('file', f, line)}, f.path + ':' + line),
+          el('div', {class: 'snippet'}, anchor.snippet || '')));
+    }
+    children.push(el('h3', {}, title + ' (' + anchors.length + ')'), list);
+  }
+  if (xrefs.next_page_token) {
+    children.push(el('p', {}, 'Only the first page of cross-references is shown.'));
+  }
+  xrefsPanel.replaceChildren(...children);
+}
+
+async function route() {
+  const [kind, query] = location.hash.slice(1).split(/\?(.*)/s);
+  const q = new URLSearchParams(query || '');
+  const f = {corpus: q.get('corpus') || '', root: q.get('root') || '', path: q.get('path') || ''};
+  try {
+    if (kind === 'dir') {
+      await showDir(f);
+    } else if (kind === 'file') {
+      await showFile(f, Number(q.get('line')) || 0);
+    } else {
+      await showCorpora();
+    }
+  } catch (err) {
+    view.replaceChildren(el('p', {class: 'error'}, String(err.message || err)));
+  }
+}
+
+window.addEventListener('hashchange', route);
+route();
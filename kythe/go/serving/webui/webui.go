@@ -0,0 +1,168 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package webui serves a small web UI for browsing a Kythe index, bundled
+// into the binary, from the same server as the Kythe HTTP APIs.
+//
+// The UI lists the corpora and directories of the filetree service, shows
+// files with their references linked, and lists the cross-references of a
+// chosen symbol.  Every response carries an ETag.  The index page is always
+// revalidated, and refers to the other assets by a URL that includes a digest
+// of their contents, so they can be cached indefinitely.
+package webui // import "kythe.io/kythe/go/serving/webui"
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+//go:embed static
+var static embed.FS
+
+const indexPage = "index.html"
+
+// Options configure the UI.
+type Options struct {
+	// Prefix is the URL path under which the UI is served (default "/").
+	Prefix string
+
+	// APIBase is the URL path at which the Kythe HTTP APIs are served
+	// (default "/").
+	APIBase string
+}
+
+func (o *Options) prefix() string  { return dir(o.Prefix) }
+func (o *Options) apiBase() string { return dir(o.APIBase) }
+
+// dir returns p as an absolute path ending in a slash.
+func dir(p string) string {
+	return strings.TrimSuffix("/"+strings.Trim(p, "/"), "/") + "/"
+}
+
+// asset is a file of the UI.
+type asset struct {
+	data []byte
+	hash string
+}
+
+// A Handler serves the UI.
+type Handler struct {
+	prefix string
+	assets map[string]*asset
+}
+
+// NewHandler returns a Handler serving the UI with the given options.
+// Requests are expected to have paths beginning with opts.Prefix.  If opts ==
+// nil, default options are used.
+func NewHandler(opts *Options) (*Handler, error) {
+	if opts == nil {
+		opts = new(Options)
+	}
+	h := &Handler{prefix: opts.prefix(), assets: make(map[string]*asset)}
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		return nil, err
+	}
+	err = fs.WalkDir(files, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || name == indexPage {
+			return err
+		}
+		data, err := fs.ReadFile(files, name)
+		if err != nil {
+			return err
+		}
+		h.assets[name] = newAsset(data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The index page links to the other assets by digest.
+	index, err := template.New(indexPage).Funcs(template.FuncMap{
+		"asset": func(name string) (string, error) {
+			a, ok := h.assets[name]
+			if !ok {
+				return "", fmt.Errorf("unknown asset %q", name)
+			}
+			return h.prefix + name + "?v=" + a.hash, nil
+		},
+	}).ParseFS(files, indexPage)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := index.Execute(&buf, struct{ APIBase string }{opts.apiBase()}); err != nil {
+		return nil, err
+	}
+	h.assets[indexPage] = newAsset(buf.Bytes())
+	return h, nil
+}
+
+func newAsset(data []byte) *asset {
+	sum := sha256.Sum256(data)
+	return &asset{data: data, hash: hex.EncodeToString(sum[:8])}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var name string
+	if p := path.Clean("/" + r.URL.Path); strings.HasPrefix(p, h.prefix) {
+		name = p[len(h.prefix):]
+	} else if p+"/" != h.prefix {
+		http.NotFound(w, r)
+		return
+	}
+	if name == "" {
+		name = indexPage
+	}
+	a, ok := h.assets[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if name != indexPage && r.URL.Query().Get("v") == a.hash {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	w.Header().Set("ETag", `"`+a.hash+`"`)
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(a.data))
+}
+
+// Register registers a Handler for the UI with mux, under opts.Prefix.
+func Register(mux *http.ServeMux, opts *Options) error {
+	h, err := NewHandler(opts)
+	if err != nil {
+		return err
+	}
+	mux.Handle(h.prefix, h)
+	return nil
+}
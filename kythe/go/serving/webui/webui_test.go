@@ -0,0 +1,106 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func serve(t *testing.T, h http.Handler, method, url string, header ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, url, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	if err := Register(mux, &Options{Prefix: "ui", APIBase: "/api"}); err != nil {
+		t.Fatal(err)
+	}
+
+	index := serve(t, mux, "GET", "/ui/")
+	if index.Code != http.StatusOK {
+		t.Fatalf("GET /ui/: status %d", index.Code)
+	}
+	if got := index.Header().Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("Index Cache-Control: got %q, want no-cache", got)
+	}
+	if got := index.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/html") {
+		t.Errorf("Index Content-Type: got %q", got)
+	}
+	body := index.Body.String()
+	if !strings.Contains(body, `content="/api/"`) {
+		t.Errorf("Index does not name the API base /api/:\n%s", body)
+	}
+	script := regexp.MustCompile(`src="(/ui/app\.js\?v=[0-9a-f]+)"`).FindStringSubmatch(body)
+	if script == nil {
+		t.Fatalf("Index has no versioned script:\n%s", body)
+	}
+
+	// A versioned asset can be cached indefinitely, an unversioned one must
+	// be revalidated.
+	rec := serve(t, mux, "GET", script[1])
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Header().Get("Cache-Control"), "immutable") {
+		t.Errorf("GET %s: got %d with Cache-Control %q, want immutable", script[1], rec.Code, rec.Header().Get("Cache-Control"))
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.Contains(got, "javascript") {
+		t.Errorf("Script Content-Type: got %q", got)
+	}
+	etag := rec.Header().Get("ETag")
+	rec = serve(t, mux, "GET", "/ui/app.js?v=old")
+	if got := rec.Header().Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("Stale version Cache-Control: got %q, want no-cache", got)
+	}
+	if rec := serve(t, mux, "GET", "/ui/app.js", "If-None-Match", etag); rec.Code != http.StatusNotModified {
+		t.Errorf("Conditional GET: status %d, want %d", rec.Code, http.StatusNotModified)
+	}
+
+	for url, want := range map[string]int{
+		"/ui":             http.StatusMovedPermanently,
+		"/ui/index.html":  http.StatusOK,
+		"/ui/missing.js":  http.StatusNotFound,
+		"/other/app.js":   http.StatusNotFound,
+		"/ui/static/a.js": http.StatusNotFound,
+	} {
+		if rec := serve(t, mux, "GET", url); rec.Code != want {
+			t.Errorf("GET %s: status %d, want %d", url, rec.Code, want)
+		}
+	}
+	if rec := serve(t, mux, "POST", "/ui/"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /ui/: status %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestDefaultPrefix(t *testing.T) {
+	h, err := NewHandler(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := serve(t, h, "GET", "/")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `src="/app.js?v=`) || !strings.Contains(rec.Body.String(), `content="/"`) {
+		t.Errorf("GET /: status %d\n%s", rec.Code, rec.Body)
+	}
+}
@@ -18,9 +18,14 @@ package assemble
 
 import (
 	"context"
+	"sort"
 	"testing"
 
+	"kythe.io/kythe/go/test/entrygen"
 	"kythe.io/kythe/go/test/testutil"
+	"kythe.io/kythe/go/util/compare"
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/schema/edges"
 
 	"google.golang.org/protobuf/proto"
 
@@ -160,6 +165,93 @@ func getNode(t string) *srvpb.Node {
 	return n
 }
 
+func TestSourcesProperties(t *testing.T) {
+	entrygen.Run(t, 20, nil, func(t *testing.T, g *entrygen.Generator) {
+		graph := g.Graph()
+		entries := g.Duplicate(graph)
+		sort.Sort(compare.ByEntries(entries))
+
+		type edge struct {
+			src, kind, tgt string
+			ordinal        int32
+		}
+		facts := make(map[string]map[string]string)
+		wantEdges := make(map[edge]bool)
+		for _, e := range graph {
+			src := kytheuri.ToString(e.Source)
+			if e.EdgeKind == "" {
+				if facts[src] == nil {
+					facts[src] = make(map[string]string)
+				}
+				facts[src][e.FactName] = string(e.FactValue)
+				continue
+			}
+			kind, ord, _ := edges.ParseOrdinal(e.EdgeKind)
+			wantEdges[edge{src, kind, kytheuri.ToString(e.Target), int32(ord)}] = true
+		}
+
+		sources := make(map[string]bool)
+		reverse := make(map[edge]bool)
+		if err := Sources(func(f func(*spb.Entry) error) error {
+			for _, e := range entries {
+				if err := f(e); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, func(src *ipb.Source) error {
+			if sources[src.Ticket] {
+				t.Errorf("Source %q is assembled more than once", src.Ticket)
+			}
+			sources[src.Ticket] = true
+			if len(src.Facts) != len(facts[src.Ticket]) {
+				t.Errorf("Source %q has %d facts, want %d", src.Ticket, len(src.Facts), len(facts[src.Ticket]))
+			}
+			for name, value := range src.Facts {
+				if want := facts[src.Ticket][name]; want != string(value) {
+					t.Errorf("Source %q fact %q: got %q, want %q", src.Ticket, name, value, want)
+				}
+			}
+			n := 0
+			for kind, group := range src.EdgeGroups {
+				for _, e := range group.Edges {
+					n++
+					if !wantEdges[edge{src.Ticket, kind, e.Ticket, e.Ordinal}] {
+						t.Errorf("Source %q has unexpected %s edge to %q", src.Ticket, kind, e.Ticket)
+					}
+				}
+			}
+
+			// Every edge is reversed, after a self-edge.
+			rev := PartialReverseEdges(src)
+			if len(rev) != n+1 || rev[0].Source.Ticket != src.Ticket {
+				t.Fatalf("Source %q: got %d partial reverse edges, want %d", src.Ticket, len(rev), n+1)
+			}
+			for _, e := range rev[1:] {
+				reverse[edge{e.Target.Ticket, edges.Mirror(e.Kind), e.Source.Ticket, e.Ordinal}] = true
+			}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		// Every edge of the graph is in a Source, once.
+		if len(reverse) != len(wantEdges) {
+			t.Errorf("Got %d distinct edges, want %d", len(reverse), len(wantEdges))
+		}
+		for e := range wantEdges {
+			if !reverse[e] {
+				t.Errorf("Missing edge %v", e)
+			}
+		}
+		for ticket := range facts {
+			if !sources[ticket] {
+				t.Errorf("Missing source %q", ticket)
+			}
+		}
+	})
+}
+
 func TestEdgeSetBuilder(t *testing.T) {
 	tests := []struct {
 		src       *srvpb.Node
@@ -0,0 +1,506 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package cscope converts Kythe entries into a cscope cross-reference
+// database, so that cscope and the editors integrated with it can find the
+// definitions, callers and callees of symbols using the Kythe index.
+//
+// The database is written in the uncompressed format of cscope version 15
+// (as by "cscope -c"), without an inverted index.  It lists each file with
+// text, and each line of the file holding an anchor, with the symbols of the
+// line marked by kind:
+//
+//	defines/binding    $ for functions, s, u, c, e, t, # or m for types,
+//	                   macros and members, p and l for parameters and
+//	                   locals, and g for other definitions
+//	ref/call           ` on the reference to the callee within the call
+//	ref/writes         =
+//	ref/includes       ~
+//	ref                (unmarked)
+//
+// Each function defined in a file is ended by a } mark at the end of its
+// definition, taken to be the furthest anchor that is a childof it or the
+// end of an anchor that defines it, so that cscope attributes the calls
+// between a function's definition and its end mark to it.
+//
+// Use the database with "cscope -d" (so that cscope does not rebuild it from
+// the sources), from the directory recorded in its header.
+//
+// Like the ctags exporter, an Exporter keeps its input in memory, so the
+// entries need not be sorted.
+package cscope // import "kythe.io/kythe/go/storage/cscope"
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// Marks of the cscope database, which precede a symbol (after a tab) to give
+// its kind.
+const (
+	markFile       = '@'
+	markFunction   = '$'
+	markCall       = '`'
+	markFuncEnd    = '}'
+	markDefine     = '#'
+	markInclude    = '~'
+	markAssignment = '='
+	markClass      = 'c'
+	markEnum       = 'e'
+	markGlobal     = 'g'
+	markLocal      = 'l'
+	markMember     = 'm'
+	markParameter  = 'p'
+	markStruct     = 's'
+	markTypedef    = 't'
+	markUnion      = 'u'
+)
+
+// DefinitionMark returns the cscope mark for the definition of a node with
+// the given Kythe kind and subkind.
+func DefinitionMark(kind, subkind string) byte {
+	switch kind {
+	case nodes.Function:
+		return markFunction
+	case nodes.Record:
+		switch subkind {
+		case nodes.Struct:
+			return markStruct
+		case nodes.Union:
+			return markUnion
+		}
+		return markClass
+	case nodes.Interface:
+		return markClass
+	case "sum":
+		return markEnum
+	case nodes.TAlias:
+		return markTypedef
+	case "macro":
+		return markDefine
+	case nodes.Constant:
+		return markMember // an enumerator, or a global constant
+	case nodes.Variable:
+		switch subkind {
+		case nodes.Field:
+			return markMember
+		case nodes.Local:
+			return markLocal
+		case nodes.LocalParameter:
+			return markParameter
+		}
+	}
+	return markGlobal
+}
+
+// Options control the output of an Exporter.
+type Options struct {
+	// The directory recorded in the database header, relative to which
+	// cscope resolves file names.  The default is ".".
+	Dir string
+
+	// If set, only files in this corpus are included.
+	Corpus string
+
+	// If set, only files in this directory (or this file) are included.
+	Path string
+
+	// If set, file names are written relative to Path.  Otherwise they
+	// include the root and path of each file.
+	Relative bool
+}
+
+// An Exporter converts entries to a cscope database.
+type Exporter struct {
+	w     io.Writer
+	opts  Options
+	nodes map[string]*node // by ticket
+
+	// The number of files, lines and symbols written, and the number of
+	// anchors dropped because their file or offsets are missing.
+	NumFiles, NumLines, NumSymbols, NumDropped int
+}
+
+// node records the facts and edges of a node of interest.
+type node struct {
+	vname         *spb.VName
+	kind, subkind string
+	text          []byte
+	start, end    string
+	links         []link
+	parents       []string // targets of childof edges
+}
+
+type link struct{ kind, target string }
+
+// NewExporter returns an Exporter that writes a database to w.  If
+// opts == nil, default options are used.  Close must be called to write the
+// output.
+func NewExporter(w io.Writer, opts *Options) *Exporter {
+	x := &Exporter{w: w, nodes: make(map[string]*node)}
+	if opts != nil {
+		x.opts = *opts
+	}
+	if x.opts.Dir == "" {
+		x.opts.Dir = "."
+	}
+	x.opts.Path = strings.Trim(x.opts.Path, "/")
+	return x
+}
+
+func (x *Exporter) node(v *spb.VName) *node {
+	ticket := kytheuri.ToString(v)
+	n := x.nodes[ticket]
+	if n == nil {
+		n = &node{vname: v}
+		x.nodes[ticket] = n
+	}
+	return n
+}
+
+// keepEdge reports whether edges of the given kind are used by the exporter.
+func keepEdge(kind string) bool {
+	return kind == edges.DefinesBinding || kind == edges.Defines || edges.IsVariant(kind, edges.Ref)
+}
+
+// Add adds a single entry to the output.
+func (x *Exporter) Add(e *spb.Entry) error {
+	if kind := e.GetEdgeKind(); kind == edges.ChildOf {
+		n := x.node(e.GetSource())
+		n.parents = append(n.parents, kytheuri.ToString(e.GetTarget()))
+		return nil
+	} else if kind != "" {
+		if keepEdge(kind) {
+			n := x.node(e.GetSource())
+			n.links = append(n.links, link{kind, kytheuri.ToString(e.GetTarget())})
+		}
+		return nil
+	}
+	switch value := e.GetFactValue(); e.GetFactName() {
+	case facts.NodeKind:
+		x.node(e.GetSource()).kind = string(value)
+	case facts.Subkind:
+		x.node(e.GetSource()).subkind = string(value)
+	case facts.Text:
+		x.node(e.GetSource()).text = value
+	case facts.AnchorStart:
+		x.node(e.GetSource()).start = string(value)
+	case facts.AnchorEnd:
+		x.node(e.GetSource()).end = string(value)
+	}
+	return nil
+}
+
+// fileName returns the name under which the file with the given VName is
+// written, and whether it is selected by the options.
+func (x *Exporter) fileName(v *spb.VName) (string, bool) {
+	if x.opts.Corpus != "" && v.GetCorpus() != x.opts.Corpus {
+		return "", false
+	}
+	p := v.GetPath()
+	if dir := x.opts.Path; dir != "" {
+		rel := strings.TrimPrefix(p, dir+"/")
+		if p != dir && rel == p {
+			return "", false
+		}
+		if x.opts.Relative {
+			if p == dir {
+				return path.Base(p), true
+			}
+			return rel, true
+		}
+	}
+	if v.GetRoot() != "" {
+		p = v.GetRoot() + "/" + p
+	}
+	return p, true
+}
+
+// An anchor is a span of a file with its edges.
+type anchor struct {
+	start, end int
+	*node
+}
+
+// A symbol is a marked or unmarked symbol of a line.  A mark of 0 is an
+// unmarked reference.
+type symbol struct {
+	start, end int
+	mark       byte
+	target     string
+}
+
+// A file is a selected file with the anchors in it.
+type file struct {
+	name    string
+	text    []byte
+	anchors []anchor
+}
+
+// Close writes the database.  It does not close the underlying writer.
+func (x *Exporter) Close() error {
+	files := x.files()
+	var body bytes.Buffer
+	var size int
+	for _, f := range files {
+		x.writeFile(&body, f)
+		size += len(f.name) + 1
+	}
+	fmt.Fprintf(&body, "\t%c\n", markFile)
+
+	// The header gives the offset of the trailer, which lists the source
+	// directories, include directories and files.
+	header := fmt.Sprintf("cscope 15 %s -c", x.opts.Dir)
+	header += fmt.Sprintf(" %010d\n", len(header)+12+body.Len())
+	fmt.Fprintf(&body, "1\n.\n0\n%d\n%d\n", len(files), size)
+	for _, f := range files {
+		fmt.Fprintf(&body, "%s\n", f.name)
+	}
+	if _, err := io.WriteString(x.w, header); err != nil {
+		return err
+	}
+	_, err := body.WriteTo(x.w)
+	return err
+}
+
+// files returns the selected files with text that contain anchors, sorted
+// by name.
+func (x *Exporter) files() []*file {
+	byTicket := make(map[string]*file)
+	for _, n := range x.nodes {
+		if n.kind != nodes.Anchor || len(n.links) == 0 {
+			continue
+		}
+		name, ok := x.fileName(n.vname)
+		if !ok {
+			continue
+		}
+		ticket := kytheuri.ToString(&spb.VName{
+			Corpus: n.vname.GetCorpus(),
+			Root:   n.vname.GetRoot(),
+			Path:   n.vname.GetPath(),
+		})
+		fn := x.nodes[ticket]
+		start, serr := strconv.Atoi(n.start)
+		end, eerr := strconv.Atoi(n.end)
+		if fn == nil || fn.kind != nodes.File || serr != nil || eerr != nil ||
+			start < 0 || end < start || end > len(fn.text) {
+			x.NumDropped++
+			continue
+		}
+		f := byTicket[ticket]
+		if f == nil {
+			f = &file{name: name, text: fn.text}
+			byTicket[ticket] = f
+		}
+		f.anchors = append(f.anchors, anchor{start, end, n})
+	}
+	var files []*file
+	for _, f := range byTicket {
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+	return files
+}
+
+// symbols returns the symbols of f in order, without overlaps.
+func (x *Exporter) symbols(f *file) []symbol {
+	var syms []symbol
+	var calls []symbol
+	defs := make(map[string]int) // the end of the binding of each function
+	ends := make(map[string]int) // the end of the definition of each function
+	for _, a := range f.anchors {
+		for _, p := range a.parents {
+			if x.kindOf(p) == nodes.Function {
+				ends[p] = max(ends[p], a.end)
+			}
+		}
+		for _, l := range a.links {
+			s := symbol{start: a.start, end: a.end, target: l.target}
+			switch {
+			case l.kind == edges.DefinesBinding:
+				t := x.nodes[l.target]
+				if t == nil {
+					s.mark = markGlobal
+				} else {
+					s.mark = DefinitionMark(t.kind, t.subkind)
+				}
+				if s.mark == markFunction {
+					defs[l.target] = a.end
+				}
+			case l.kind == edges.Defines:
+				if x.kindOf(l.target) == nodes.Function {
+					ends[l.target] = max(ends[l.target], a.end)
+				}
+				continue
+			case edges.IsVariant(l.kind, edges.RefCall):
+				calls = append(calls, s)
+				continue
+			case edges.IsVariant(l.kind, edges.RefIncludes):
+				// The closing quote or bracket of an include follows the symbol.
+				if s.end-s.start > 2 {
+					s.end--
+				}
+				s.mark = markInclude
+			case edges.IsVariant(l.kind, edges.RefWrites):
+				s.mark = markAssignment
+			}
+			if bytes.IndexByte(f.text[s.start:s.end], '\n') >= 0 || s.start == s.end {
+				continue
+			}
+			syms = append(syms, s)
+		}
+	}
+
+	// Mark the reference to the callee within each call, or the call itself
+	// if it is a single identifier.
+	for _, c := range calls {
+		marked := false
+		for i, s := range syms {
+			if s.mark == 0 && s.target == c.target && s.start >= c.start && s.end <= c.end {
+				syms[i].mark = markCall
+				marked = true
+				break
+			}
+		}
+		if !marked && isIdentifier(f.text[c.start:c.end]) {
+			c.mark = markCall
+			syms = append(syms, c)
+		}
+	}
+
+	// End each function defined here after its last anchor, on the line of
+	// its closing brace.
+	for fn, def := range defs {
+		end := ends[fn]
+		for end > def && (f.text[end-1] == '\n' || f.text[end-1] == '\r') {
+			end--
+		}
+		if end > def {
+			syms = append(syms, symbol{start: end, end: end, mark: markFuncEnd, target: fn})
+		}
+	}
+
+	sort.Slice(syms, func(i, j int) bool {
+		a, b := syms[i], syms[j]
+		if a.start != b.start {
+			return a.start < b.start
+		} else if (a.mark != 0) != (b.mark != 0) {
+			return a.mark != 0 // prefer marked symbols
+		} else if a.end != b.end {
+			return a.end > b.end
+		}
+		return a.target < b.target
+	})
+	out := syms[:0]
+	last := -1
+	for _, s := range syms {
+		if s.start < last {
+			continue
+		}
+		out = append(out, s)
+		last = s.end
+	}
+	return out
+}
+
+func (x *Exporter) kindOf(ticket string) string {
+	if n := x.nodes[ticket]; n != nil {
+		return n.kind
+	}
+	return ""
+}
+
+// isIdentifier reports whether text is a plausible identifier.
+func isIdentifier(text []byte) bool {
+	if len(text) == 0 {
+		return false
+	}
+	for _, c := range text {
+		if !(c == '_' || c == '$' || c >= 0x80 || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// writeFile writes the record of f: its name, and each line holding symbols
+// as its number followed by alternating text and symbols, one per line.
+func (x *Exporter) writeFile(w *bytes.Buffer, f *file) {
+	fmt.Fprintf(w, "\t%c%s\n\n", markFile, f.name)
+	x.NumFiles++
+	syms := x.symbols(f)
+	for i := 0; i < len(syms); {
+		lineStart := bytes.LastIndexByte(f.text[:syms[i].start], '\n') + 1
+		lineEnd := bytes.IndexByte(f.text[syms[i].start:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(f.text)
+		} else {
+			lineEnd += syms[i].start
+		}
+		fmt.Fprintf(w, "%d ", bytes.Count(f.text[:lineStart], []byte("\n"))+1)
+		pos := lineStart
+		for ; i < len(syms) && syms[i].start <= lineEnd; i++ {
+			s := syms[i]
+			w.WriteString(clean(f.text[pos:s.start], pos == lineStart))
+			w.WriteByte('\n')
+			if s.mark != 0 {
+				w.WriteByte('\t')
+				w.WriteByte(s.mark)
+			}
+			w.Write(f.text[s.start:s.end])
+			w.WriteByte('\n')
+			pos = s.end
+			x.NumSymbols++
+		}
+		w.WriteString(clean(f.text[pos:lineEnd], pos == lineStart))
+		w.WriteString("\n\n")
+		x.NumLines++
+	}
+}
+
+// clean returns text with each run of whitespace replaced by a single space,
+// and leading whitespace removed if it starts a line, as cscope writes the
+// text between symbols.
+func clean(text []byte, first bool) string {
+	var sb strings.Builder
+	space := first
+	for _, c := range text {
+		switch c {
+		case ' ', '\t', '\r', '\f', '\v':
+			if !space {
+				sb.WriteByte(' ')
+			}
+			space = true
+		default:
+			sb.WriteByte(c)
+			space = false
+		}
+	}
+	return sb.String()
+}
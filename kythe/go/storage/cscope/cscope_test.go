@@ -0,0 +1,149 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cscope
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	"github.com/google/go-cmp/cmp"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func testEntries() []*spb.Entry {
+	const text = "#include \"a.h\"\nint g(int p) {\n\tint l = p;\n\treturn h(l);\n}\n"
+	file := &spb.VName{Corpus: "c", Path: "src/a.c"}
+	header := &spb.VName{Corpus: "c", Path: "src/a.h"}
+	sem := func(sig string) *spb.VName { return &spb.VName{Corpus: "c", Language: "c++", Signature: sig} }
+	g, h, p, l := sem("g"), sem("h"), sem("p"), sem("l")
+	fact := func(v *spb.VName, name, value string) *spb.Entry {
+		return &spb.Entry{Source: v, FactName: name, FactValue: []byte(value)}
+	}
+	edge := func(src *spb.VName, kind string, tgt *spb.VName) *spb.Entry {
+		return &spb.Entry{Source: src, EdgeKind: kind, Target: tgt, FactName: "/"}
+	}
+	var entries []*spb.Entry
+	addAnchor := func(start, end int, kind string, target, parent *spb.VName) {
+		a := &spb.VName{Corpus: "c", Path: "src/a.c", Language: "c++", Signature: fmt.Sprintf("@%d:%d", start, end)}
+		entries = append(entries,
+			fact(a, facts.NodeKind, nodes.Anchor),
+			fact(a, facts.AnchorStart, fmt.Sprint(start)),
+			fact(a, facts.AnchorEnd, fmt.Sprint(end)),
+			edge(a, edges.ChildOf, file),
+			edge(a, kind, target))
+		if parent != nil {
+			entries = append(entries, edge(a, edges.ChildOf, parent))
+		}
+	}
+	addAnchor(9, 14, edges.RefIncludes, header, nil)
+	addAnchor(19, 20, edges.DefinesBinding, g, nil)
+	addAnchor(15, 57, edges.Defines, g, nil)
+	addAnchor(25, 26, edges.DefinesBinding, p, g)
+	addAnchor(35, 36, edges.DefinesBinding, l, g)
+	addAnchor(39, 40, edges.Ref, p, g)
+	addAnchor(50, 54, edges.RefCall, h, g)
+	addAnchor(50, 51, edges.Ref, h, g)
+	addAnchor(52, 53, edges.Ref, l, g)
+	entries = append(entries,
+		fact(file, facts.NodeKind, nodes.File),
+		fact(file, facts.Text, text),
+		fact(g, facts.NodeKind, nodes.Function),
+		fact(h, facts.NodeKind, nodes.Function),
+		fact(p, facts.NodeKind, nodes.Variable),
+		fact(p, facts.Subkind, nodes.LocalParameter),
+		fact(l, facts.NodeKind, nodes.Variable),
+		fact(l, facts.Subkind, nodes.Local),
+		// An anchor whose file has no text.
+		fact(sem("@dropped"), facts.NodeKind, nodes.Anchor),
+		edge(sem("@dropped"), edges.Ref, g),
+	)
+	return entries
+}
+
+func export(t *testing.T, opts *Options) (string, *Exporter) {
+	t.Helper()
+	var buf bytes.Buffer
+	x := NewExporter(&buf, opts)
+	for _, e := range testEntries() {
+		if err := x.Add(e); err != nil {
+			t.Fatalf("Add(%v): %v", e, err)
+		}
+	}
+	if err := x.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.String(), x
+}
+
+func TestExporter(t *testing.T) {
+	got, x := export(t, nil)
+	body := "\t@src/a.c\n\n" +
+		"1 #include \n\t~\"a.h\n\"\n\n" +
+		"2 int \n\t$g\n(int \n\tpp\n) {\n\n" +
+		"3 int \n\tll\n = \np\n;\n\n" +
+		"4 return \n\t`h\n(\nl\n);\n\n" +
+		"5 }\n\t}\n\n\n" +
+		"\t@\n"
+	header := fmt.Sprintf("cscope 15 . -c %010d\n", len("cscope 15 . -c 0000000000\n")+len(body))
+	want := header + body + "1\n.\n0\n1\n8\nsrc/a.c\n"
+	if diff := cmp.Diff(strings.Split(want, "\n"), strings.Split(got, "\n")); diff != "" {
+		t.Errorf("Database (-want +got):\n%s", diff)
+	}
+	if x.NumFiles != 1 || x.NumLines != 5 || x.NumSymbols != 8 || x.NumDropped != 1 {
+		t.Errorf("Counts: got %d files, %d lines, %d symbols, %d dropped; want 1, 5, 8, 1",
+			x.NumFiles, x.NumLines, x.NumSymbols, x.NumDropped)
+	}
+}
+
+func TestExporterOptions(t *testing.T) {
+	got, _ := export(t, &Options{Dir: "/home/me/src", Path: "src", Relative: true})
+	if !strings.HasPrefix(got, "cscope 15 /home/me/src -c ") || !strings.Contains(got, "\t@a.c\n\n") || !strings.HasSuffix(got, "\n1\n4\na.c\n") {
+		t.Errorf("Relative database:\n%s", got)
+	}
+	if got, x := export(t, &Options{Corpus: "other"}); x.NumFiles != 0 || !strings.HasSuffix(got, "\t@\n1\n.\n0\n0\n0\n") {
+		t.Errorf("Database of another corpus:\n%s", got)
+	}
+}
+
+func TestDefinitionMark(t *testing.T) {
+	tests := []struct {
+		kind, subkind string
+		want          byte
+	}{
+		{nodes.Function, "", '$'},
+		{nodes.Record, nodes.Struct, 's'},
+		{nodes.Record, nodes.Union, 'u'},
+		{nodes.Record, nodes.Class, 'c'},
+		{"sum", nodes.Enum, 'e'},
+		{nodes.TAlias, "", 't'},
+		{"macro", "", '#'},
+		{nodes.Variable, nodes.Field, 'm'},
+		{nodes.Variable, "", 'g'},
+	}
+	for _, test := range tests {
+		if got := DefinitionMark(test.kind, test.subkind); got != test.want {
+			t.Errorf("DefinitionMark(%q, %q): got %c, want %c", test.kind, test.subkind, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package ctags converts Kythe entries into a tags file in the extended
+// format of Exuberant and Universal Ctags, for editors that read tags files
+// but do not speak LSP.
+//
+// Each defines/binding edge from an anchor in a file with text becomes a tag,
+// named by the text of the anchor and addressed by a search pattern for its
+// line (or by its line number).  The extension fields of a tag give its kind,
+// line and language, and the kind and name of the node it is a childof, if
+// that node is also tagged:
+//
+//	Add	pkg/set.go	/^func (s *Set) Add(v int) {$/;"	kind:method	line:12	language:Go	struct:Set
+//
+// Kinds are derived from the Kythe node kind and subkind of each target; local
+// variables and parameters, and nodes of kinds without a ctags counterpart,
+// are not tagged.  Tags are sorted by name, so editors can search the file by
+// bisection.
+//
+// Like the SCIP exporter, an Exporter keeps its input in memory, so the
+// entries need not be sorted.
+package ctags // import "kythe.io/kythe/go/storage/ctags"
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// languages maps Kythe language names to the language names of ctags.
+var languages = map[string]string{
+	"c":          "C",
+	"c++":        "C++",
+	"go":         "Go",
+	"java":       "Java",
+	"javascript": "JavaScript",
+	"kotlin":     "Kotlin",
+	"objc":       "ObjectiveC",
+	"protobuf":   "Protobuf",
+	"python":     "Python",
+	"rust":       "Rust",
+	"typescript": "TypeScript",
+}
+
+// Language returns the ctags name of a Kythe language.  Languages unknown to
+// ctags are returned unchanged.
+func Language(lang string) string {
+	if name, ok := languages[lang]; ok {
+		return name
+	}
+	return lang
+}
+
+// Kind returns the ctags kind for a node with the given Kythe kind and
+// subkind, whose parent (the target of its childof edge) has the given Kythe
+// kind.  It returns "" for nodes that should not be tagged.
+func Kind(kind, subkind, parent string) string {
+	switch kind {
+	case nodes.Function:
+		if parent == nodes.Record || parent == nodes.Interface {
+			return "method"
+		}
+		return "function"
+	case nodes.Record:
+		switch subkind {
+		case nodes.Struct, nodes.Union:
+			return subkind
+		}
+		return "class"
+	case nodes.Interface:
+		return "interface"
+	case "sum":
+		return "enum"
+	case nodes.Constant:
+		if parent == "sum" {
+			return "enumerator"
+		}
+		return "constant"
+	case nodes.Variable:
+		switch subkind {
+		case nodes.Field:
+			return "member"
+		case "", nodes.Implicit:
+			return "variable"
+		}
+		return "" // locals and parameters
+	case nodes.TAlias:
+		return "typedef"
+	case "macro":
+		return "macro"
+	case nodes.Package:
+		return "package"
+	}
+	return ""
+}
+
+// Options control the output of an Exporter.
+type Options struct {
+	// If set, only files in this corpus are tagged.
+	Corpus string
+
+	// If set, only files in this directory (or this file) are tagged.
+	Path string
+
+	// If set, file names are written relative to Path, for a tags file
+	// stored in that directory.  Otherwise they include the root and path of
+	// each file.
+	Relative bool
+
+	// If set, tags are addressed by line number rather than by a search
+	// pattern.  Line numbers are exact but become stale when a file is edited.
+	LineNumbers bool
+
+	// The program version recorded in the header of the tags file.
+	ProgramVersion string
+}
+
+// An Exporter converts entries to a tags file.
+type Exporter struct {
+	w     io.Writer
+	opts  Options
+	nodes map[string]*node // by ticket
+
+	// The number of tags written, and the number of bindings dropped because
+	// their file or offsets are missing.
+	NumTags, NumDropped int
+}
+
+// node records the facts and edges of a node of interest.
+type node struct {
+	vname         *spb.VName
+	kind, subkind string
+	text          []byte
+	start, end    string
+	binds         []string // targets of defines/binding edges
+	parent        string   // target of a childof edge
+}
+
+// NewExporter returns an Exporter that writes a tags file to w.  If
+// opts == nil, default options are used.  Close must be called to write the
+// output.
+func NewExporter(w io.Writer, opts *Options) *Exporter {
+	x := &Exporter{w: w, nodes: make(map[string]*node)}
+	if opts != nil {
+		x.opts = *opts
+	}
+	x.opts.Path = strings.Trim(x.opts.Path, "/")
+	return x
+}
+
+func (x *Exporter) node(v *spb.VName) *node {
+	ticket := kytheuri.ToString(v)
+	n := x.nodes[ticket]
+	if n == nil {
+		n = &node{vname: v}
+		x.nodes[ticket] = n
+	}
+	return n
+}
+
+// Add adds a single entry to the output.
+func (x *Exporter) Add(e *spb.Entry) error {
+	switch kind := e.GetEdgeKind(); kind {
+	case "":
+	case edges.DefinesBinding:
+		n := x.node(e.GetSource())
+		n.binds = append(n.binds, kytheuri.ToString(e.GetTarget()))
+		return nil
+	case edges.ChildOf:
+		x.node(e.GetSource()).parent = kytheuri.ToString(e.GetTarget())
+		return nil
+	default:
+		return nil
+	}
+	switch value := e.GetFactValue(); e.GetFactName() {
+	case facts.NodeKind:
+		x.node(e.GetSource()).kind = string(value)
+	case facts.Subkind:
+		x.node(e.GetSource()).subkind = string(value)
+	case facts.Text:
+		x.node(e.GetSource()).text = value
+	case facts.AnchorStart:
+		x.node(e.GetSource()).start = string(value)
+	case facts.AnchorEnd:
+		x.node(e.GetSource()).end = string(value)
+	}
+	return nil
+}
+
+// fileName returns the name under which the file with the given VName is
+// written, and whether it is selected by the options.
+func (x *Exporter) fileName(v *spb.VName) (string, bool) {
+	if x.opts.Corpus != "" && v.GetCorpus() != x.opts.Corpus {
+		return "", false
+	}
+	p := v.GetPath()
+	if dir := x.opts.Path; dir != "" {
+		rel := strings.TrimPrefix(p, dir+"/")
+		if p != dir && rel == p {
+			return "", false
+		}
+		if x.opts.Relative {
+			if p == dir {
+				return path.Base(p), true
+			}
+			return rel, true
+		}
+	}
+	if v.GetRoot() != "" {
+		p = v.GetRoot() + "/" + p
+	}
+	return p, true
+}
+
+// A tag is a single line of a tags file.
+type tag struct {
+	name, file, address string
+	line                int
+	kind, lang          string
+	target              string
+}
+
+// binding is the name and kind under which a node is tagged.
+type binding struct{ name, kind string }
+
+// Close writes the tags file.  It does not close the underlying writer.
+func (x *Exporter) Close() error {
+	tags := x.tags()
+	sort.Slice(tags, func(i, j int) bool {
+		a, b := tags[i], tags[j]
+		if a.name != b.name {
+			return a.name < b.name
+		} else if a.file != b.file {
+			return a.file < b.file
+		} else if a.line != b.line {
+			return a.line < b.line
+		}
+		return a.target < b.target
+	})
+
+	// Name each node by its first binding, for the scope fields of its
+	// children.
+	named := make(map[string]binding)
+	for _, t := range tags {
+		if _, ok := named[t.target]; !ok {
+			named[t.target] = binding{t.name, t.kind}
+		}
+	}
+
+	w := bufio.NewWriter(x.w)
+	fmt.Fprintf(w, "!_TAG_FILE_FORMAT\t2\t/extended format; --format=1 will not append ;\" to lines/\n")
+	fmt.Fprintf(w, "!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted, 2=foldcase/\n")
+	fmt.Fprintf(w, "!_TAG_PROGRAM_NAME\tkythe\t//\n")
+	fmt.Fprintf(w, "!_TAG_PROGRAM_URL\thttps://kythe.io\t//\n")
+	if v := x.opts.ProgramVersion; v != "" {
+		fmt.Fprintf(w, "!_TAG_PROGRAM_VERSION\t%s\t//\n", v)
+	}
+	for _, t := range tags {
+		fmt.Fprintf(w, "%s\t%s\t%s;\"\tkind:%s\tline:%d", t.name, t.file, t.address, t.kind, t.line)
+		if t.lang != "" {
+			fmt.Fprintf(w, "\tlanguage:%s", t.lang)
+		}
+		if n := x.nodes[t.target]; n != nil && n.parent != "" {
+			if b, ok := named[n.parent]; ok {
+				fmt.Fprintf(w, "\t%s:%s", b.kind, b.name)
+			}
+		}
+		w.WriteByte('\n')
+		x.NumTags++
+	}
+	return w.Flush()
+}
+
+// tags returns the tags of every binding in a selected file.
+func (x *Exporter) tags() []*tag {
+	var tags []*tag
+	for _, n := range x.nodes {
+		if n.kind != nodes.Anchor || len(n.binds) == 0 {
+			continue
+		}
+		name, ok := x.fileName(n.vname)
+		if !ok {
+			continue
+		}
+		file := x.nodes[kytheuri.ToString(&spb.VName{
+			Corpus: n.vname.GetCorpus(),
+			Root:   n.vname.GetRoot(),
+			Path:   n.vname.GetPath(),
+		})]
+		start, serr := strconv.Atoi(n.start)
+		end, eerr := strconv.Atoi(n.end)
+		if file == nil || file.kind != nodes.File || serr != nil || eerr != nil ||
+			start < 0 || end <= start || end > len(file.text) {
+			x.NumDropped += len(n.binds)
+			continue
+		}
+		text := string(file.text[start:end])
+		if strings.ContainsAny(text, "\t\r\n") {
+			continue // not a valid tag name
+		}
+		line, address := x.address(file.text, start)
+		for _, target := range n.binds {
+			t := x.nodes[target]
+			if t == nil {
+				continue
+			}
+			var parentKind string
+			if p := x.nodes[t.parent]; t.parent != "" && p != nil {
+				parentKind = p.kind
+			}
+			kind := Kind(t.kind, t.subkind, parentKind)
+			if kind == "" {
+				continue
+			}
+			tags = append(tags, &tag{
+				name:    text,
+				file:    name,
+				address: address,
+				line:    line,
+				kind:    kind,
+				lang:    Language(n.vname.GetLanguage()),
+				target:  target,
+			})
+		}
+	}
+	return tags
+}
+
+// address returns the 1-based line number of the given offset in text, and
+// the ctags address of that line.
+func (x *Exporter) address(text []byte, offset int) (int, string) {
+	line := bytes.Count(text[:offset], []byte("\n")) + 1
+	if x.opts.LineNumbers {
+		return line, strconv.Itoa(line)
+	}
+	start := bytes.LastIndexByte(text[:offset], '\n') + 1
+	end := bytes.IndexByte(text[offset:], '\n')
+	if end < 0 {
+		end = len(text)
+	} else {
+		end += offset
+	}
+	return line, Pattern(string(bytes.TrimSuffix(text[start:end], []byte("\r"))))
+}
+
+// Pattern returns a ctags search pattern matching the whole of the given line.
+func Pattern(line string) string {
+	r := strings.NewReplacer(`\`, `\\`, `/`, `\/`)
+	return "/^" + r.Replace(line) + "$/"
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ctags
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	"github.com/google/go-cmp/cmp"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func testEntries() []*spb.Entry {
+	const (
+		textA = "type T struct{ f int }\nfunc (T) M(p int) { x := `/\\` }\n"
+		textB = "const C = 1\n"
+	)
+	fileA := &spb.VName{Corpus: "c", Root: "r", Path: "p/a.go"}
+	fileB := &spb.VName{Corpus: "c", Root: "r", Path: "q/b.go"}
+	other := &spb.VName{Corpus: "other", Path: "p/a.go"}
+	sem := func(sig string) *spb.VName { return &spb.VName{Corpus: "c", Language: "go", Signature: sig} }
+	typ, field, method, param, local, cnst := sem("T"), sem("T.f"), sem("T.M"), sem("p"), sem("x"), sem("C")
+	fact := func(v *spb.VName, name, value string) *spb.Entry {
+		return &spb.Entry{Source: v, FactName: name, FactValue: []byte(value)}
+	}
+	edge := func(src *spb.VName, kind string, tgt *spb.VName) *spb.Entry {
+		return &spb.Entry{Source: src, EdgeKind: kind, Target: tgt, FactName: "/"}
+	}
+	var entries []*spb.Entry
+	addAnchor := func(file *spb.VName, start, end string, kind string, target *spb.VName) {
+		a := &spb.VName{Corpus: file.Corpus, Root: file.Root, Path: file.Path, Language: "go", Signature: "@" + start}
+		entries = append(entries,
+			fact(a, facts.NodeKind, nodes.Anchor),
+			fact(a, facts.AnchorStart, start),
+			fact(a, facts.AnchorEnd, end),
+			edge(a, edges.ChildOf, file),
+			edge(a, kind, target))
+	}
+	addAnchor(fileA, "5", "6", edges.DefinesBinding, typ)
+	addAnchor(fileA, "15", "16", edges.DefinesBinding, field)
+	addAnchor(fileA, "32", "33", edges.DefinesBinding, method)
+	addAnchor(fileA, "34", "35", edges.DefinesBinding, param)
+	addAnchor(fileA, "43", "44", edges.DefinesBinding, local)
+	addAnchor(fileA, "29", "30", edges.Ref, typ)
+	addAnchor(fileB, "6", "7", edges.DefinesBinding, cnst)
+	addAnchor(other, "5", "6", edges.DefinesBinding, typ)
+	entries = append(entries,
+		fact(fileA, facts.NodeKind, nodes.File),
+		fact(fileA, facts.Text, textA),
+		fact(fileB, facts.NodeKind, nodes.File),
+		fact(fileB, facts.Text, textB),
+		fact(other, facts.NodeKind, nodes.File),
+		fact(other, facts.Text, textA),
+		fact(typ, facts.NodeKind, nodes.Record),
+		fact(typ, facts.Subkind, nodes.Struct),
+		fact(field, facts.NodeKind, nodes.Variable),
+		fact(field, facts.Subkind, nodes.Field),
+		edge(field, edges.ChildOf, typ),
+		fact(method, facts.NodeKind, nodes.Function),
+		edge(method, edges.ChildOf, typ),
+		fact(param, facts.NodeKind, nodes.Variable),
+		fact(param, facts.Subkind, nodes.LocalParameter),
+		fact(local, facts.NodeKind, nodes.Variable),
+		fact(local, facts.Subkind, nodes.Local),
+		fact(cnst, facts.NodeKind, nodes.Constant),
+		// A binding whose file has no text.
+		fact(sem("@dropped"), facts.NodeKind, nodes.Anchor),
+		fact(sem("@dropped"), facts.AnchorStart, "0"),
+		fact(sem("@dropped"), facts.AnchorEnd, "1"),
+		edge(sem("@dropped"), edges.DefinesBinding, typ),
+	)
+	return entries
+}
+
+func export(t *testing.T, opts *Options) (string, *Exporter) {
+	t.Helper()
+	var buf bytes.Buffer
+	x := NewExporter(&buf, opts)
+	for _, e := range testEntries() {
+		if err := x.Add(e); err != nil {
+			t.Fatalf("Add(%v): %v", e, err)
+		}
+	}
+	if err := x.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.String(), x
+}
+
+const header = "!_TAG_FILE_FORMAT\t2\t/extended format; --format=1 will not append ;\" to lines/\n" +
+	"!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted, 2=foldcase/\n" +
+	"!_TAG_PROGRAM_NAME\tkythe\t//\n" +
+	"!_TAG_PROGRAM_URL\thttps://kythe.io\t//\n"
+
+func TestExporter(t *testing.T) {
+	got, x := export(t, &Options{Corpus: "c", ProgramVersion: "v1"})
+	want := header +
+		"!_TAG_PROGRAM_VERSION\tv1\t//\n" +
+		"C\tr/q/b.go\t/^const C = 1$/;\"\tkind:constant\tline:1\tlanguage:Go\n" +
+		"M\tr/p/a.go\t/^func (T) M(p int) { x := `\\/\\\\` }$/;\"\tkind:method\tline:2\tlanguage:Go\tstruct:T\n" +
+		"T\tr/p/a.go\t/^type T struct{ f int }$/;\"\tkind:struct\tline:1\tlanguage:Go\n" +
+		"f\tr/p/a.go\t/^type T struct{ f int }$/;\"\tkind:member\tline:1\tlanguage:Go\tstruct:T\n"
+	if diff := cmp.Diff(strings.Split(want, "\n"), strings.Split(got, "\n")); diff != "" {
+		t.Errorf("Tags (-want +got):\n%s", diff)
+	}
+	if x.NumTags != 4 || x.NumDropped != 1 {
+		t.Errorf("Counts: got %d tags, %d dropped; want 4, 1", x.NumTags, x.NumDropped)
+	}
+}
+
+func TestExporterSubtree(t *testing.T) {
+	got, _ := export(t, &Options{Corpus: "c", Path: "/q/", Relative: true, LineNumbers: true})
+	want := header + "C\tb.go\t1;\"\tkind:constant\tline:1\tlanguage:Go\n"
+	if got != want {
+		t.Errorf("Tags:\n got %q\nwant %q", got, want)
+	}
+
+	got, _ = export(t, &Options{Corpus: "c", Path: "p/a.go", Relative: true, LineNumbers: true})
+	if !strings.Contains(got, "T\ta.go\t1;\"\tkind:struct") || strings.Contains(got, "b.go") {
+		t.Errorf("Tags of a single file:\n%s", got)
+	}
+}
+
+func TestKind(t *testing.T) {
+	tests := []struct {
+		kind, subkind, parent, want string
+	}{
+		{nodes.Function, "", "", "function"},
+		{nodes.Function, "", nodes.Interface, "method"},
+		{nodes.Record, nodes.Class, "", "class"},
+		{nodes.Record, nodes.Union, "", "union"},
+		{"sum", nodes.EnumClass, "", "enum"},
+		{nodes.Constant, "", "sum", "enumerator"},
+		{nodes.Variable, "", "", "variable"},
+		{nodes.Variable, nodes.Local, "", ""},
+		{nodes.TAlias, "", "", "typedef"},
+		{nodes.Anchor, "", "", ""},
+	}
+	for _, test := range tests {
+		if got := Kind(test.kind, test.subkind, test.parent); got != test.want {
+			t.Errorf("Kind(%q, %q, %q): got %q, want %q", test.kind, test.subkind, test.parent, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package graphcheck finds structural problems in a Kythe graph, to measure
+// the quality of an index per language over time:
+//
+//   - dangling edges, whose targets have no facts;
+//   - orphan anchors, with no childof edge to a file;
+//   - files without text.
+//
+// The language of a node is the language of its VName, except that a file
+// (whose VName has none) takes the language of the anchors within it.
+//
+// Like the exporters, a Checker keeps the nodes it needs in memory, so the
+// entries need not be sorted.
+package graphcheck // import "kythe.io/kythe/go/storage/graphcheck"
+
+import (
+	"sort"
+
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// A Problem is a kind of structural problem in a graph.
+type Problem string
+
+// Problems found by a Checker.
+const (
+	DanglingEdge Problem = "dangling_edge" // an edge whose target has no facts
+	OrphanAnchor Problem = "orphan_anchor" // an anchor with no childof edge to a file
+	MissingText  Problem = "missing_text"  // a file with no text
+)
+
+// A Sample is an example of a problem.
+type Sample struct {
+	Problem Problem `json:"problem"`
+	Ticket  string  `json:"ticket"`           // the node with the problem, or the edge's source
+	Edge    string  `json:"edge,omitempty"`   // the kind of a dangling edge
+	Target  string  `json:"target,omitempty"` // the target of a dangling edge
+}
+
+// A LanguageReport summarizes the problems of the nodes of one language.
+type LanguageReport struct {
+	Language string `json:"language"`
+	Nodes    int    `json:"nodes"` // nodes with facts
+	Edges    int    `json:"edges"`
+
+	// Counts are the numbers of problems found, by kind.
+	Counts map[Problem]int `json:"counts,omitempty"`
+
+	// Samples are examples of the problems found, up to Options.MaxSamples
+	// of each kind, in order by ticket.
+	Samples []*Sample `json:"samples,omitempty"`
+}
+
+// A Report is the result of a check, with one LanguageReport per language,
+// in order by language.
+type Report struct {
+	Languages []*LanguageReport `json:"languages"`
+}
+
+// Options control a Checker.
+type Options struct {
+	// MaxSamples bounds the samples recorded per problem and language.  If
+	// zero, DefaultMaxSamples is used; if negative, none are recorded.
+	MaxSamples int
+}
+
+// DefaultMaxSamples is the default for Options.MaxSamples.
+const DefaultMaxSamples = 10
+
+func (o *Options) maxSamples() int {
+	if o == nil || o.MaxSamples == 0 {
+		return DefaultMaxSamples
+	}
+	return max(o.MaxSamples, 0)
+}
+
+// A Checker checks the entries added to it.
+type Checker struct {
+	opts  *Options
+	nodes map[string]*node // by ticket
+}
+
+// node records what a Checker needs to know of a node.
+type node struct {
+	lang     string
+	kind     string
+	hasFacts bool
+	hasText  bool
+	edges    int
+	parents  []string // tickets of the targets of childof edges
+	refs     []edge   // edges to this node, while it has no facts
+}
+
+type edge struct{ source, kind, target string }
+
+// New returns a Checker with the given options, which may be nil.
+func New(opts *Options) *Checker {
+	return &Checker{opts: opts, nodes: make(map[string]*node)}
+}
+
+func (c *Checker) node(v *spb.VName) *node {
+	ticket := kytheuri.ToString(v)
+	n := c.nodes[ticket]
+	if n == nil {
+		n = &node{lang: v.GetLanguage()}
+		c.nodes[ticket] = n
+	}
+	return n
+}
+
+// Add adds a single entry to the check.
+func (c *Checker) Add(e *spb.Entry) error {
+	src := c.node(e.GetSource())
+	if kind := e.GetEdgeKind(); kind != "" {
+		src.edges++
+		target := kytheuri.ToString(e.GetTarget())
+		if kind == edges.ChildOf {
+			src.parents = append(src.parents, target)
+		}
+		if t := c.node(e.GetTarget()); !t.hasFacts {
+			t.refs = append(t.refs, edge{kytheuri.ToString(e.GetSource()), kind, target})
+		}
+		return nil
+	}
+	if !src.hasFacts {
+		src.hasFacts = true
+		src.refs = nil
+	}
+	switch e.GetFactName() {
+	case facts.NodeKind:
+		src.kind = string(e.GetFactValue())
+	case facts.Text:
+		src.hasText = true
+	}
+	return nil
+}
+
+// Report returns the problems found in the entries added so far.
+func (c *Checker) Report() *Report {
+	// Files take the language of their anchors.
+	for _, n := range c.nodes {
+		if n.kind != nodes.Anchor || n.lang == "" {
+			continue
+		}
+		for _, p := range n.parents {
+			if f := c.nodes[p]; f != nil && f.kind == nodes.File && f.lang == "" {
+				f.lang = n.lang
+			}
+		}
+	}
+
+	langs := make(map[string]*LanguageReport)
+	report := func(lang string) *LanguageReport {
+		r := langs[lang]
+		if r == nil {
+			r = &LanguageReport{Language: lang}
+			langs[lang] = r
+		}
+		return r
+	}
+	var samples []*Sample
+	add := func(lang string, s *Sample) {
+		r := report(lang)
+		if r.Counts == nil {
+			r.Counts = make(map[Problem]int)
+		}
+		r.Counts[s.Problem]++
+		samples = append(samples, s)
+	}
+
+	for ticket, n := range c.nodes {
+		if n.hasFacts {
+			report(n.lang).Nodes++
+		}
+		if n.edges > 0 {
+			report(n.lang).Edges += n.edges
+		}
+		for _, e := range n.refs {
+			src := c.nodes[e.source]
+			add(src.lang, &Sample{Problem: DanglingEdge, Ticket: e.source, Edge: e.kind, Target: e.target})
+		}
+		switch n.kind {
+		case nodes.Anchor:
+			if !c.hasFileParent(n) {
+				add(n.lang, &Sample{Problem: OrphanAnchor, Ticket: ticket})
+			}
+		case nodes.File:
+			if !n.hasText {
+				add(n.lang, &Sample{Problem: MissingText, Ticket: ticket})
+			}
+		}
+	}
+
+	// Keep the first samples of each problem and language, in a
+	// deterministic order.
+	sort.Slice(samples, func(i, j int) bool {
+		a, b := samples[i], samples[j]
+		if a.Ticket != b.Ticket {
+			return a.Ticket < b.Ticket
+		} else if a.Edge != b.Edge {
+			return a.Edge < b.Edge
+		}
+		return a.Target < b.Target
+	})
+	limit := c.opts.maxSamples()
+	kept := make(map[*LanguageReport]map[Problem]int)
+	for _, s := range samples {
+		lang := c.nodes[s.Ticket].lang
+		r := langs[lang]
+		if kept[r] == nil {
+			kept[r] = make(map[Problem]int)
+		}
+		if kept[r][s.Problem] < limit {
+			kept[r][s.Problem]++
+			r.Samples = append(r.Samples, s)
+		}
+	}
+
+	out := &Report{Languages: make([]*LanguageReport, 0, len(langs))}
+	for _, r := range langs {
+		out.Languages = append(out.Languages, r)
+	}
+	sort.Slice(out.Languages, func(i, j int) bool { return out.Languages[i].Language < out.Languages[j].Language })
+	return out
+}
+
+// hasFileParent reports whether n has a childof edge to a file.
+func (c *Checker) hasFileParent(n *node) bool {
+	for _, p := range n.parents {
+		if f := c.nodes[p]; f != nil && f.kind == nodes.File {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graphcheck
+
+import (
+	"testing"
+
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	"github.com/google/go-cmp/cmp"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestChecker(t *testing.T) {
+	file := &spb.VName{Corpus: "c", Path: "a.go"}
+	noText := &spb.VName{Corpus: "c", Path: "b.go"}
+	anchor := &spb.VName{Corpus: "c", Path: "a.go", Language: "go", Signature: "@0:1"}
+	orphan := &spb.VName{Corpus: "c", Path: "b.go", Language: "go", Signature: "@2:3"}
+	javaOrphan := &spb.VName{Corpus: "c", Path: "A.java", Language: "java", Signature: "@0:1"}
+	fn := &spb.VName{Corpus: "c", Language: "go", Signature: "F"}
+	missing := &spb.VName{Corpus: "c", Language: "go", Signature: "G"}
+	fact := func(v *spb.VName, name, value string) *spb.Entry {
+		return &spb.Entry{Source: v, FactName: name, FactValue: []byte(value)}
+	}
+	edge := func(src *spb.VName, kind string, target *spb.VName) *spb.Entry {
+		return &spb.Entry{Source: src, EdgeKind: kind, Target: target, FactName: "/"}
+	}
+
+	c := New(nil)
+	for _, e := range []*spb.Entry{
+		// An edge to F precedes its facts, so it does not dangle.
+		edge(anchor, edges.DefinesBinding, fn),
+		edge(anchor, edges.ChildOf, file),
+		edge(anchor, edges.Ref, missing),
+		fact(anchor, facts.NodeKind, nodes.Anchor),
+		fact(file, facts.NodeKind, nodes.File),
+		fact(file, facts.Text, "package a"),
+		fact(fn, facts.NodeKind, nodes.Function),
+		fact(noText, facts.NodeKind, nodes.File),
+		fact(orphan, facts.NodeKind, nodes.Anchor),
+		edge(orphan, edges.ChildOf, fn),
+		fact(javaOrphan, facts.NodeKind, nodes.Anchor),
+	} {
+		if err := c.Add(e); err != nil {
+			t.Fatalf("Add(%v): %v", e, err)
+		}
+	}
+
+	want := &Report{Languages: []*LanguageReport{{
+		Language: "",
+		Nodes:    1,
+		Counts:   map[Problem]int{MissingText: 1},
+		Samples:  []*Sample{{Problem: MissingText, Ticket: "kythe://c?path=b.go"}},
+	}, {
+		Language: "go",
+		Nodes:    4, // including a.go, which takes the language of its anchor
+		Edges:    4,
+		Counts:   map[Problem]int{DanglingEdge: 1, OrphanAnchor: 1},
+		Samples: []*Sample{{
+			Problem: DanglingEdge,
+			Ticket:  "kythe://c?lang=go?path=a.go#%400%3A1",
+			Edge:    edges.Ref,
+			Target:  "kythe://c?lang=go#G",
+		}, {
+			Problem: OrphanAnchor,
+			Ticket:  "kythe://c?lang=go?path=b.go#%402%3A3",
+		}},
+	}, {
+		Language: "java",
+		Nodes:    1,
+		Counts:   map[Problem]int{OrphanAnchor: 1},
+		Samples:  []*Sample{{Problem: OrphanAnchor, Ticket: "kythe://c?lang=java?path=A.java#%400%3A1"}},
+	}}}
+	if diff := cmp.Diff(want, c.Report()); diff != "" {
+		t.Errorf("Report (-want +got):\n%s", diff)
+	}
+}
+
+func TestMaxSamples(t *testing.T) {
+	c := New(&Options{MaxSamples: 2})
+	for _, path := range []string{"a", "b", "c"} {
+		c.Add(&spb.Entry{Source: &spb.VName{Path: path}, FactName: facts.NodeKind, FactValue: []byte(nodes.File)})
+	}
+	r := c.Report().Languages[0]
+	if r.Counts[MissingText] != 3 || len(r.Samples) != 2 || r.Samples[0].Ticket != "kythe:?path=a" {
+		t.Errorf("Report: got %d samples of %v (first %v), want 2 of 3 starting with a", len(r.Samples), r.Counts, r.Samples[0])
+	}
+
+	c = New(&Options{MaxSamples: -1})
+	c.Add(&spb.Entry{Source: &spb.VName{Path: "a"}, FactName: facts.NodeKind, FactValue: []byte(nodes.File)})
+	if r := c.Report().Languages[0]; r.Counts[MissingText] != 1 || len(r.Samples) != 0 {
+		t.Errorf("Report without samples: got %+v", r)
+	}
+}
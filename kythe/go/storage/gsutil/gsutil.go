@@ -64,12 +64,16 @@ func RegisterDefault(kind string) {
 }
 
 type gsFlag struct {
-	gs *graphstore.Service
+	gs   *graphstore.Service
+	spec string
 }
 
-// String implements part of the flag.Value interface.
+// String implements part of the flag.Value interface.  Once the flag is set,
+// it returns the specification it was set to.
 func (f *gsFlag) String() string {
-	if f.gs == nil {
+	if f.spec != "" {
+		return f.spec
+	} else if f.gs == nil {
 		return "<graphstore>"
 	}
 	return fmt.Sprintf("%T", *f.gs)
@@ -83,6 +87,7 @@ func (f *gsFlag) Get() any {
 // Set implements part of the flag.Value interface.
 func (f *gsFlag) Set(str string) (err error) {
 	*f.gs, err = ParseGraphStore(str)
+	f.spec = str
 	return
 }
 
@@ -22,6 +22,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -61,18 +62,15 @@ func (s *GraphStore) Write(ctx context.Context, req *spb.WriteRequest) error {
 	return nil
 }
 
+// insert adds e to the entries of s, replacing any entry with the same key.
 func (s *GraphStore) insert(e *spb.Entry) {
 	i := sort.Search(len(s.entries), func(i int) bool {
-		return compare.Entries(e, s.entries[i]) == compare.LT
+		return compare.Entries(e, s.entries[i]) != compare.GT
 	})
-	if i == len(s.entries) {
-		s.entries = append(s.entries, e)
-	} else if i < len(s.entries) && compare.EntriesEqual(e, s.entries[i]) {
+	if i < len(s.entries) && compare.Entries(e, s.entries[i]) == compare.EQ {
 		s.entries[i] = e
-	} else if i == 0 {
-		s.entries = append([]*spb.Entry{e}, s.entries...)
 	} else {
-		s.entries = append(s.entries[:i], append([]*spb.Entry{e}, s.entries[i:]...)...)
+		s.entries = slices.Insert(s.entries, i, e)
 	}
 }
 
@@ -133,6 +131,9 @@ var _ keyvalue.DB = &KeyValueDB{}
 
 // Get implements part of the keyvalue.DB interface.
 func (k *KeyValueDB) Get(ctx context.Context, key []byte, opts *keyvalue.Options) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	k.mu.RLock()
 	defer k.mu.RUnlock()
 	val, ok := k.db[string(key)]
@@ -179,10 +180,13 @@ func (p *kvPrefixIterator) Close() error {
 
 // ScanPrefix implements part of the keyvalue.DB interface.
 func (k *KeyValueDB) ScanPrefix(ctx context.Context, prefix []byte, opts *keyvalue.Options) (keyvalue.Iterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	k.mu.RLock()
 	p := string(prefix)
 	i := sort.Search(len(k.keys), func(i int) bool { return strings.Compare(k.keys[i], p) >= 0 })
-	return &kvPrefixIterator{k, p, i}, nil
+	return keyvalue.ContextIterator(ctx, &kvPrefixIterator{k, p, i}), nil
 }
 
 type kvRangeIterator struct {
@@ -222,6 +226,9 @@ func (p *kvRangeIterator) Close() error {
 
 // ScanRange implements part of the keyvalue.DB interface.
 func (k *KeyValueDB) ScanRange(ctx context.Context, r *keyvalue.Range, opts *keyvalue.Options) (keyvalue.Iterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	k.mu.RLock()
 	var start int
 	if r != nil && len(r.Start) != 0 {
@@ -233,7 +240,7 @@ func (k *KeyValueDB) ScanRange(ctx context.Context, r *keyvalue.Range, opts *key
 		e := string(r.End)
 		end = &e
 	}
-	return &kvRangeIterator{k, end, start}, nil
+	return keyvalue.ContextIterator(ctx, &kvRangeIterator{k, end, start}), nil
 }
 
 type kvWriter struct{ db *KeyValueDB }
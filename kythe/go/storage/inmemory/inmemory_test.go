@@ -18,11 +18,15 @@ package inmemory
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"sort"
 	"testing"
 
 	"kythe.io/kythe/go/storage/keyvalue"
+	"kythe.io/kythe/go/test/services/graphstore"
+	"kythe.io/kythe/go/test/synthetic"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -122,6 +126,43 @@ func TestKeyValueDB_scanPrefix(t *testing.T) {
 	}
 }
 
+func TestKeyValueDB_scanCancelled(t *testing.T) {
+	db := NewKeyValueDB()
+	var entries []entry
+	for i := 0; i < 1000; i++ {
+		entries = append(entries, entry{fmt.Sprintf("k%04d", i), "val"})
+	}
+	writeEntries(t, db, entries)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	it, err := db.ScanPrefix(ctx, []byte("k"), nil)
+	if err != nil {
+		t.Fatalf("ScanPrefix error: %v", err)
+	}
+	defer it.Close()
+	var n int
+	for {
+		if n == 10 {
+			cancel()
+		}
+		if _, _, err = it.Next(); err != nil {
+			break
+		}
+		n++
+	}
+	if !errors.Is(err, context.Canceled) || n == len(entries) {
+		t.Errorf("Scan after %d of %d entries: got error %v, want %v", n, len(entries), err, context.Canceled)
+	}
+
+	if _, err := db.ScanPrefix(ctx, []byte("k"), nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("ScanPrefix with a cancelled context: got error %v, want %v", err, context.Canceled)
+	}
+	if _, err := db.Get(ctx, []byte("k0000"), nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Get with a cancelled context: got error %v, want %v", err, context.Canceled)
+	}
+}
+
 func TestKeyValueDB_scanRange(t *testing.T) {
 	db := NewKeyValueDB()
 
@@ -291,3 +332,24 @@ func write(t *testing.T, db *KeyValueDB, key, val string) {
 		t.Fatalf("Write close error: %v", err)
 	}
 }
+
+// benchCorpus is kept small, since both stores insert in O(n) time.
+var benchCorpus = &synthetic.Options{Files: 50}
+
+func tempGS() (graphstore.Service, graphstore.DestroyFunc, error) {
+	return new(GraphStore), graphstore.NullDestroy, nil
+}
+
+func tempKVGS() (graphstore.Service, graphstore.DestroyFunc, error) {
+	return keyvalue.NewGraphStore(NewKeyValueDB()), graphstore.NullDestroy, nil
+}
+
+func BenchmarkWrite(b *testing.B)   { graphstore.WriteBenchmark(b, tempGS, benchCorpus) }
+func BenchmarkRead(b *testing.B)    { graphstore.ReadBenchmark(b, tempGS, benchCorpus) }
+func BenchmarkScan(b *testing.B)    { graphstore.ScanBenchmark(b, tempGS, benchCorpus) }
+func BenchmarkKVWrite(b *testing.B) { graphstore.WriteBenchmark(b, tempKVGS, benchCorpus) }
+func BenchmarkKVRead(b *testing.B)  { graphstore.ReadBenchmark(b, tempKVGS, benchCorpus) }
+func BenchmarkKVScan(b *testing.B)  { graphstore.ScanBenchmark(b, tempKVGS, benchCorpus) }
+
+func TestProperties(t *testing.T)   { graphstore.PropertyTest(t, tempGS) }
+func TestKVProperties(t *testing.T) { graphstore.PropertyTest(t, tempKVGS) }
@@ -140,6 +140,37 @@ type Iterator interface {
 	Seek(key []byte) error
 }
 
+// ctxCheckInterval is the number of entries a ContextIterator returns between
+// checks of its context, which are not free.
+const ctxCheckInterval = 256
+
+// ContextIterator returns an Iterator that fails with the error of ctx once
+// ctx is done, so that a DB's scans can be cancelled or time out.  If ctx can
+// never be done, it returns iter.
+func ContextIterator(ctx context.Context, iter Iterator) Iterator {
+	if ctx.Done() == nil {
+		return iter
+	}
+	return &ctxIterator{Iterator: iter, ctx: ctx}
+}
+
+type ctxIterator struct {
+	Iterator
+	ctx context.Context
+	n   int
+}
+
+// Next implements part of the Iterator interface.
+func (i *ctxIterator) Next() (key, val []byte, err error) {
+	if i.n%ctxCheckInterval == 0 {
+		if err := i.ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+	}
+	i.n++
+	return i.Iterator.Next()
+}
+
 // Writer provides write access to a DB. Writes must be Closed when no longer
 // used to ensure that resources are not leaked.
 type Writer interface {
@@ -238,20 +269,54 @@ func (s *Store) Read(ctx context.Context, req *spb.ReadRequest, f graphstore.Ent
 	if err != nil {
 		return fmt.Errorf("db seek error: %v", err)
 	}
-	return streamEntries(iter, f)
+	return streamEntries(ctx, iter, f)
+}
+
+// entryBuf holds an Entry and its VNames for reuse across the entries of a
+// Read, Scan, or Shard.
+type entryBuf struct {
+	entry          spb.Entry
+	source, target spb.VName
+}
+
+var entryPool = sync.Pool{New: func() any { return new(entryBuf) }}
+
+// entryDecoder returns a function that decodes a key-value into an Entry, as
+// Entry does.  If ctx permits entry reuse, the function returns the same
+// pooled Entry for every key-value, and release must be called when it is no
+// longer in use.
+func entryDecoder(ctx context.Context) (decode func(key, val []byte) (*spb.Entry, error), release func()) {
+	if !graphstore.EntryReuse(ctx) {
+		return Entry, func() {}
+	}
+	buf := entryPool.Get().(*entryBuf)
+	decode = func(key, val []byte) (*spb.Entry, error) {
+		if err := decodeEntry(&buf.entry, &buf.source, &buf.target, key, val); err != nil {
+			return nil, err
+		}
+		return &buf.entry, nil
+	}
+	release = func() {
+		// Drop the decoded fields so the pool does not keep them live.
+		buf.entry, buf.source, buf.target = spb.Entry{}, spb.VName{}, spb.VName{}
+		entryPool.Put(buf)
+	}
+	return decode, release
 }
 
-func streamEntries(iter Iterator, f graphstore.EntryFunc) error {
+func streamEntries(ctx context.Context, iter Iterator, f graphstore.EntryFunc) error {
 	defer iter.Close()
+	decode, release := entryDecoder(ctx)
+	defer release()
 	for {
 		key, val, err := iter.Next()
 		if err == io.EOF {
 			break
 		} else if err != nil {
-			return fmt.Errorf("db iteration error: %v", err)
+			return fmt.Errorf("db iteration error: %w", err)
 		}
 
-		entry, err := Entry(key, val)
+		entry, err := decode(key, val)
 		if err != nil {
 			return fmt.Errorf("encoding error: %v", err)
 		}
@@ -300,14 +365,16 @@ func (s *Store) Scan(ctx context.Context, req *spb.ScanRequest, f graphstore.Ent
 		return fmt.Errorf("db seek error: %v", err)
 	}
 	defer iter.Close()
+	decode, release := entryDecoder(ctx)
+	defer release()
 	for {
 		key, val, err := iter.Next()
 		if err == io.EOF {
 			break
 		} else if err != nil {
-			return fmt.Errorf("db iteration error: %v", err)
+			return fmt.Errorf("db iteration error: %w", err)
 		}
-		entry, err := Entry(key, val)
+		entry, err := decode(key, val)
 		if err != nil {
 			return fmt.Errorf("invalid key/value entry: %v", err)
 		}
@@ -363,7 +430,7 @@ func (s *Store) Shard(ctx context.Context, req *spb.ShardRequest, f graphstore.E
 	if err != nil {
 		return err
 	}
-	return streamEntries(iter, f)
+	return streamEntries(ctx, iter, f)
 }
 
 func (s *Store) constructShards(ctx context.Context, num int64) ([]shard, Snapshot, error) {
@@ -556,31 +623,43 @@ func KeyPrefix(source *spb.VName, edgeKind string) ([]byte, error) {
 // Entry decodes the key (assuming it was encoded by EncodeKey) into an Entry
 // and populates its value field.
 func Entry(key []byte, val []byte) (*spb.Entry, error) {
+	e := new(spb.Entry)
+	if err := decodeEntry(e, nil, nil, key, val); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// decodeEntry decodes the key and value into e, as Entry does.  If they are
+// non-nil, src and tgt are overwritten to hold the decoded VNames of e rather
+// than allocating new ones.
+func decodeEntry(e *spb.Entry, src, tgt *spb.VName, key, val []byte) error {
 	if !bytes.HasPrefix(key, entryKeyPrefixBytes) {
-		return nil, fmt.Errorf("key is not prefixed with entry prefix %q", entryKeyPrefix)
+		return fmt.Errorf("key is not prefixed with entry prefix %q", entryKeyPrefix)
 	}
 	keyStr := string(bytes.TrimPrefix(key, entryKeyPrefixBytes))
 	keyParts := strings.SplitN(keyStr, entryKeySepStr, 4)
 	if len(keyParts) != 4 {
-		return nil, fmt.Errorf("invalid key[%d]: %q", len(keyParts), string(key))
+		return fmt.Errorf("invalid key[%d]: %q", len(keyParts), string(key))
 	}
 
-	srcVName, err := decodeVName(keyParts[0])
+	srcVName, err := decodeVNameInto(src, keyParts[0])
 	if err != nil {
-		return nil, fmt.Errorf("error decoding source VName: %v", err)
+		return fmt.Errorf("error decoding source VName: %v", err)
 	}
-	targetVName, err := decodeVName(keyParts[3])
+	targetVName, err := decodeVNameInto(tgt, keyParts[3])
 	if err != nil {
-		return nil, fmt.Errorf("error decoding target VName: %v", err)
+		return fmt.Errorf("error decoding target VName: %v", err)
 	}
 
-	return &spb.Entry{
+	*e = spb.Entry{
 		Source:    srcVName,
 		FactName:  keyParts[2],
 		EdgeKind:  keyParts[1],
 		Target:    targetVName,
 		FactValue: val,
-	}, nil
+	}
+	return nil
 }
 
 // encodeVName returns a canonical byte array for the given VName. Returns nil if given nil.
@@ -604,7 +683,11 @@ func encodeVName(v *spb.VName) ([]byte, error) {
 }
 
 // decodeVName returns the VName coded in the given string. Returns nil, if len(data) == 0.
-func decodeVName(data string) (*spb.VName, error) {
+func decodeVName(data string) (*spb.VName, error) { return decodeVNameInto(nil, data) }
+
+// decodeVNameInto decodes the VName coded in the given string into v, or into
+// a new VName if v == nil.  Returns nil, if len(data) == 0.
+func decodeVNameInto(v *spb.VName, data string) (*spb.VName, error) {
 	if len(data) == 0 {
 		return nil, nil
 	}
@@ -612,11 +695,15 @@ func decodeVName(data string) (*spb.VName, error) {
 	if len(parts) != 5 {
 		return nil, fmt.Errorf("invalid VName encoding: %q", data)
 	}
-	return &spb.VName{
+	if v == nil {
+		v = new(spb.VName)
+	}
+	*v = spb.VName{
 		Signature: parts[0],
 		Corpus:    parts[1],
 		Root:      parts[2],
 		Path:      parts[3],
 		Language:  parts[4],
-	}, nil
+	}
+	return v, nil
 }
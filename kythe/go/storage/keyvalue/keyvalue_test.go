@@ -18,9 +18,14 @@ package keyvalue
 
 import (
 	"bytes"
+	"context"
+	"fmt"
+	"io"
 	"strings"
 	"testing"
 
+	"kythe.io/kythe/go/services/graphstore"
+
 	"google.golang.org/protobuf/proto"
 
 	spb "kythe.io/kythe/proto/storage_go_proto"
@@ -136,7 +141,103 @@ func TestKeyEncoding(t *testing.T) {
 	}
 }
 
-func fatalOnErr(t *testing.T, msg string, err error) {
+// sliceDB is a DB of sorted key-values that supports only ScanPrefix.
+type sliceDB struct {
+	DB
+	keys, vals [][]byte
+}
+
+func (db *sliceDB) ScanPrefix(_ context.Context, prefix []byte, _ *Options) (Iterator, error) {
+	return &sliceIter{db: db, prefix: prefix}, nil
+}
+
+type sliceIter struct {
+	Iterator
+	db     *sliceDB
+	prefix []byte
+	i      int
+}
+
+func (it *sliceIter) Next() (key, val []byte, err error) {
+	for ; it.i < len(it.db.keys); it.i++ {
+		if bytes.HasPrefix(it.db.keys[it.i], it.prefix) {
+			it.i++
+			return it.db.keys[it.i-1], it.db.vals[it.i-1], nil
+		}
+	}
+	return nil, nil, io.EOF
+}
+
+func (it *sliceIter) Close() error { return nil }
+
+func newSliceDB(t testing.TB, entries ...*spb.Entry) *sliceDB {
+	db := new(sliceDB)
+	for _, e := range entries {
+		key, err := EncodeKey(e.Source, e.FactName, e.EdgeKind, e.Target)
+		fatalOnErr(t, "Error encoding key: %v", err)
+		db.keys = append(db.keys, key)
+		db.vals = append(db.vals, e.FactValue)
+	}
+	return db
+}
+
+func TestScanEntryReuse(t *testing.T) {
+	want := []*spb.Entry{
+		entry(vname("a", "corpus", "", "path", "go"), "", nil, "/kythe/node/kind", "file"),
+		entry(vname("a", "corpus", "", "path", "go"), "/kythe/edge/ref", vname("b", "", "", "", ""), "/", ""),
+		entry(vname("c", "corpus", "root", "", ""), "", nil, "/kythe/node/kind", "record"),
+	}
+	gs := NewGraphStore(newSliceDB(t, want...))
+
+	for _, reuse := range []bool{false, true} {
+		ctx := graphstore.WithEntryReuse(context.Background(), reuse)
+		var got []*spb.Entry
+		var last *spb.Entry
+		if err := gs.Scan(ctx, new(spb.ScanRequest), func(e *spb.Entry) error {
+			if last != nil && (e == last) != reuse {
+				t.Errorf("Scan (reuse=%v): entry %d reused: %v", reuse, len(got), e == last)
+			}
+			last = e
+			got = append(got, proto.Clone(e).(*spb.Entry))
+			return nil
+		}); err != nil {
+			t.Fatalf("Scan (reuse=%v): %v", reuse, err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("Scan (reuse=%v): got %d entries, want %d", reuse, len(got), len(want))
+		}
+		for i, e := range got {
+			if !proto.Equal(e, want[i]) {
+				t.Errorf("Scan (reuse=%v) entry %d: got {%+v}, want {%+v}", reuse, i, e, want[i])
+			}
+		}
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	var entries []*spb.Entry
+	for i := 0; i < 10000; i++ {
+		src := vname(fmt.Sprintf("sig%d", i), "corpus", "", fmt.Sprintf("path/file%d.go", i/10), "go")
+		entries = append(entries,
+			entry(src, "", nil, "/kythe/node/kind", "function"),
+			entry(src, "/kythe/edge/childof", vname("parent", "corpus", "", "path", "go"), "/", ""))
+	}
+	gs := NewGraphStore(newSliceDB(b, entries...))
+
+	for _, reuse := range []bool{false, true} {
+		b.Run(fmt.Sprintf("reuse=%v", reuse), func(b *testing.B) {
+			ctx := graphstore.WithEntryReuse(context.Background(), reuse)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := gs.Scan(ctx, new(spb.ScanRequest), func(*spb.Entry) error { return nil }); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func fatalOnErr(t testing.TB, msg string, err error) {
 	if err != nil {
 		t.Fatalf(msg, err)
 	}
@@ -161,3 +262,43 @@ func entry(src *spb.VName, edgeKind string, target *spb.VName, factName string,
 		FactValue: []byte(factValue),
 	}
 }
+
+// countIterator returns n entries.
+type countIterator struct{ n int }
+
+func (i *countIterator) Next() ([]byte, []byte, error) {
+	if i.n == 0 {
+		return nil, nil, io.EOF
+	}
+	i.n--
+	return []byte("k"), []byte("v"), nil
+}
+
+func (*countIterator) Seek([]byte) error { return nil }
+func (*countIterator) Close() error      { return nil }
+
+func TestContextIterator(t *testing.T) {
+	it := &countIterator{n: 10}
+	if got := ContextIterator(context.Background(), it); got != it {
+		t.Errorf("ContextIterator of a context that is never done: got %T, want the iterator", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cit := ContextIterator(ctx, &countIterator{n: 10 * ctxCheckInterval})
+	if _, _, err := cit.Next(); err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	cancel()
+	for i := 0; ; i++ {
+		_, _, err := cit.Next()
+		if err == nil {
+			if i >= ctxCheckInterval {
+				t.Fatalf("Next succeeded %d times after cancellation", i)
+			}
+			continue
+		} else if err != context.Canceled {
+			t.Fatalf("Next: got %v, want %v", err, context.Canceled)
+		}
+		break
+	}
+}
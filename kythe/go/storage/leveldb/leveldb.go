@@ -16,31 +16,58 @@
 
 // Package leveldb implements a graphstore.Service using a LevelDB backend
 // database.
+//
+// A LevelDB GraphStore is named by a spec of the form
+//
+//	leveldb:path[?option=value&...]
+//
+// where the options tune the database, overriding the DefaultOptions:
+//
+//	cache_size        -- size of the block cache (e.g., "8GiB")
+//	cache_large_reads -- whether large reads fill the cache ("true"/"false")
+//	write_buffer_size -- size of the in-memory write buffer (e.g., "256MiB")
+//	block_size        -- approximate size of table blocks (e.g., "16KiB")
+//	bloom_bits        -- bits per key of a bloom filter, or 0 for none
+//	compression       -- table compression: "snappy" or "none"
+//
+// For example, "leveldb:/data/gs?cache_size=16GiB&bloom_bits=10".
 package leveldb // import "kythe.io/kythe/go/storage/leveldb"
 
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 
 	"kythe.io/kythe/go/services/graphstore"
 	"kythe.io/kythe/go/storage/gsutil"
 	"kythe.io/kythe/go/storage/keyvalue"
+	"kythe.io/kythe/go/util/datasize"
 
 	"github.com/jmhodges/levigo"
 )
 
 func init() {
-	gsutil.Register("leveldb", func(spec string) (graphstore.Service, error) { return OpenGraphStore(spec, nil) })
+	gsutil.Register("leveldb", func(spec string) (graphstore.Service, error) {
+		path, opts, err := ParseSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		return OpenGraphStore(path, opts)
+	})
 	gsutil.RegisterDefault("leveldb")
 }
 
 // levelDB is a wrapper around a levigo.DB that implements keyvalue.DB
 type levelDB struct {
-	db    *levigo.DB
-	cache *levigo.Cache
+	db     *levigo.DB
+	cache  *levigo.Cache
+	filter *levigo.FilterPolicy // nil if there is no bloom filter
 
 	// save options to reduce number of allocations during high load
 	readOpts      *levigo.ReadOptions
@@ -88,11 +115,89 @@ type Options struct {
 	// (backed by a disk log) before writing to the on-disk table.
 	WriteBufferSize int
 
+	// BlockSize is the approximate size of the blocks of table data.  If zero,
+	// the LevelDB default (4KiB) is used.
+	BlockSize int
+
+	// BloomFilterBits is the number of bits per key of the bloom filter used to
+	// avoid reading tables that cannot hold a key.  If zero, no filter is used.
+	BloomFilterBits int
+
+	// Compression is the compression applied to table blocks: "snappy" or
+	// "none".  If empty, snappy is used.
+	Compression string
+
 	// MustExist ensures that the given database exists before opening it.  If
 	// false and the database does not exist, it will be created.
 	MustExist bool
 }
 
+// compression returns the levigo setting for o.Compression.
+func (o *Options) compression() (levigo.CompressionOpt, error) {
+	switch o.Compression {
+	case "", "snappy":
+		return levigo.SnappyCompression, nil
+	case "none":
+		return levigo.NoCompression, nil
+	default:
+		return 0, fmt.Errorf("unknown LevelDB compression %q", o.Compression)
+	}
+}
+
+// ParseSpec splits a LevelDB spec into its path and options.  Options given
+// in the query of the spec override the DefaultOptions; see the package
+// documentation for their names.
+func ParseSpec(spec string) (string, *Options, error) {
+	opts := *DefaultOptions
+	i := strings.LastIndex(spec, "?")
+	if i < 0 {
+		return spec, &opts, nil
+	}
+	path := spec[:i]
+	query, err := url.ParseQuery(spec[i+1:])
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid LevelDB options %q: %v", spec[i+1:], err)
+	}
+	for name, vals := range query {
+		val := vals[len(vals)-1]
+		var err error
+		switch name {
+		case "cache_size":
+			opts.CacheCapacity, err = parseSize(val)
+		case "cache_large_reads":
+			opts.CacheLargeReads, err = strconv.ParseBool(val)
+		case "write_buffer_size":
+			opts.WriteBufferSize, err = parseSize(val)
+		case "block_size":
+			opts.BlockSize, err = parseSize(val)
+		case "bloom_bits":
+			opts.BloomFilterBits, err = strconv.Atoi(val)
+			if err == nil && opts.BloomFilterBits < 0 {
+				err = errors.New("negative bits per key")
+			}
+		case "compression":
+			opts.Compression = val
+			_, err = opts.compression()
+		default:
+			err = errors.New("unknown option")
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid LevelDB option %s=%q: %v", name, val, err)
+		}
+	}
+	return path, &opts, nil
+}
+
+func parseSize(s string) (int, error) {
+	sz, err := datasize.Parse(s)
+	if err != nil {
+		return 0, err
+	} else if uint64(sz) > uint64(^uint(0)>>1) {
+		return 0, errors.New("size too large")
+	}
+	return int(sz), nil
+}
+
 // ValidDB determines if the given path could be a LevelDB database.
 func ValidDB(path string) bool {
 	stat, err := os.Stat(path)
@@ -115,17 +220,34 @@ func Open(path string, opts *Options) (keyvalue.DB, error) {
 	if opts == nil {
 		opts = DefaultOptions
 	}
+	compression, err := opts.compression()
+	if err != nil {
+		return nil, err
+	}
 
 	options := levigo.NewOptions()
 	defer options.Close()
 	cache := levigo.NewLRUCache(opts.CacheCapacity)
 	options.SetCache(cache)
 	options.SetCreateIfMissing(!opts.MustExist)
+	options.SetCompression(compression)
 	if opts.WriteBufferSize > 0 {
 		options.SetWriteBufferSize(opts.WriteBufferSize)
 	}
+	if opts.BlockSize > 0 {
+		options.SetBlockSize(opts.BlockSize)
+	}
+	var filter *levigo.FilterPolicy
+	if opts.BloomFilterBits > 0 {
+		filter = levigo.NewBloomFilter(opts.BloomFilterBits)
+		options.SetFilterPolicy(filter)
+	}
 	db, err := levigo.Open(path, options)
 	if err != nil {
+		cache.Close()
+		if filter != nil {
+			filter.Close()
+		}
 		return nil, fmt.Errorf("could not open LevelDB at %q: %v", path, err)
 	}
 	largeReadOpts := levigo.NewReadOptions()
@@ -133,16 +255,52 @@ func Open(path string, opts *Options) (keyvalue.DB, error) {
 	return &levelDB{
 		db:            db,
 		cache:         cache,
+		filter:        filter,
 		readOpts:      levigo.NewReadOptions(),
 		largeReadOpts: largeReadOpts,
 		writeOpts:     levigo.NewWriteOptions(),
 	}, nil
 }
 
+// OpenTemp returns a keyvalue DB backed by a new LevelDB database in a
+// temporary directory, which is removed when the DB is closed.  It suits
+// scratch data that need not outlive the process.  If opts==nil, the
+// DefaultOptions are used.
+func OpenTemp(opts *Options) (keyvalue.DB, error) {
+	dir, err := os.MkdirTemp("", "leveldb")
+	if err != nil {
+		return nil, err
+	}
+	db, err := Open(dir, opts)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return &scratchDB{db, dir}, nil
+}
+
+// scratchDB is a keyvalue.DB whose directory is removed when it is closed.
+type scratchDB struct {
+	keyvalue.DB
+	dir string
+}
+
+// Close implements part of the keyvalue.DB interface.
+func (t *scratchDB) Close(ctx context.Context) error {
+	err := t.DB.Close(ctx)
+	if rerr := os.RemoveAll(t.dir); err == nil {
+		err = rerr
+	}
+	return err
+}
+
 // Close will close the underlying LevelDB database.
 func (s *levelDB) Close(_ context.Context) error {
 	s.db.Close()
 	s.cache.Close()
+	if s.filter != nil {
+		s.filter.Close()
+	}
 	s.readOpts.Close()
 	s.largeReadOpts.Close()
 	s.writeOpts.Close()
@@ -171,7 +329,10 @@ func (s *levelDB) Writer(_ context.Context) (keyvalue.Writer, error) {
 }
 
 // Get implements part of the keyvalue.DB interface.
-func (s *levelDB) Get(_ context.Context, key []byte, opts *keyvalue.Options) ([]byte, error) {
+func (s *levelDB) Get(ctx context.Context, key []byte, opts *keyvalue.Options) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	ro := s.readOptions(opts)
 	if ro != s.largeReadOpts && ro != s.readOpts {
 		defer ro.Close()
@@ -186,21 +347,27 @@ func (s *levelDB) Get(_ context.Context, key []byte, opts *keyvalue.Options) ([]
 }
 
 // ScanPrefix implements part of the keyvalue.DB interface.
-func (s *levelDB) ScanPrefix(_ context.Context, prefix []byte, opts *keyvalue.Options) (keyvalue.Iterator, error) {
+func (s *levelDB) ScanPrefix(ctx context.Context, prefix []byte, opts *keyvalue.Options) (keyvalue.Iterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	iter, ro := s.iterator(opts)
 	if len(prefix) == 0 {
 		iter.SeekToFirst()
 	} else {
 		iter.Seek(prefix)
 	}
-	return &iterator{iter, ro, prefix, nil}, nil
+	return keyvalue.ContextIterator(ctx, &iterator{iter, ro, prefix, nil}), nil
 }
 
 // ScanRange implements part of the keyvalue.DB interface.
-func (s *levelDB) ScanRange(_ context.Context, r *keyvalue.Range, opts *keyvalue.Options) (keyvalue.Iterator, error) {
+func (s *levelDB) ScanRange(ctx context.Context, r *keyvalue.Range, opts *keyvalue.Options) (keyvalue.Iterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	iter, ro := s.iterator(opts)
 	iter.Seek(r.Start)
-	return &iterator{iter, ro, nil, r}, nil
+	return keyvalue.ContextIterator(ctx, &iterator{iter, ro, nil, r}), nil
 }
 
 func (s *levelDB) readOptions(opts *keyvalue.Options) *levigo.ReadOptions {
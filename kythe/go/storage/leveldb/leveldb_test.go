@@ -24,6 +24,9 @@ import (
 
 	"kythe.io/kythe/go/test/services/graphstore"
 	"kythe.io/kythe/go/test/storage/keyvalue"
+	"kythe.io/kythe/go/test/synthetic"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 const (
@@ -77,6 +80,60 @@ func BenchmarkGSWriteBatchLrg(b *testing.B) {
 	graphstore.BatchWriteBenchmark(b, tempGS, largeBatchSize)
 }
 
+var benchCorpus = &synthetic.Options{Files: 500}
+
+func BenchmarkGSWriteCorpus(b *testing.B) { graphstore.WriteBenchmark(b, tempGS, benchCorpus) }
+func BenchmarkGSRead(b *testing.B)        { graphstore.ReadBenchmark(b, tempGS, benchCorpus) }
+func BenchmarkGSScan(b *testing.B)        { graphstore.ScanBenchmark(b, tempGS, benchCorpus) }
+
 func TestOrder(t *testing.T) {
 	graphstore.OrderTest(t, tempGS, largeBatchSize)
 }
+
+func TestProperties(t *testing.T) { graphstore.PropertyTest(t, tempGS) }
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		path string
+		opts Options
+	}{
+		{"/tmp/gs", "/tmp/gs", *DefaultOptions},
+		{"/tmp/gs?cache_size=8GiB&bloom_bits=10", "/tmp/gs", Options{
+			CacheCapacity:   8 << 30,
+			WriteBufferSize: DefaultOptions.WriteBufferSize,
+			BloomFilterBits: 10,
+		}},
+		{"gs?write_buffer_size=256MiB&block_size=16KiB&compression=none&cache_large_reads=true", "gs", Options{
+			CacheCapacity:   DefaultOptions.CacheCapacity,
+			CacheLargeReads: true,
+			WriteBufferSize: 256 << 20,
+			BlockSize:       16 << 10,
+			Compression:     "none",
+		}},
+	}
+	for _, test := range tests {
+		path, opts, err := ParseSpec(test.spec)
+		if err != nil {
+			t.Errorf("ParseSpec(%q): unexpected error: %v", test.spec, err)
+			continue
+		}
+		if path != test.path {
+			t.Errorf("ParseSpec(%q) path: got %q, want %q", test.spec, path, test.path)
+		}
+		if diff := cmp.Diff(test.opts, *opts); diff != "" {
+			t.Errorf("ParseSpec(%q) options (-want +got):\n%s", test.spec, diff)
+		}
+	}
+
+	for _, spec := range []string{
+		"gs?cache_size=lots",
+		"gs?bloom_bits=-1",
+		"gs?compression=zstd",
+		"gs?unknown=1",
+	} {
+		if _, _, err := ParseSpec(spec); err == nil {
+			t.Errorf("ParseSpec(%q): got nil error", spec)
+		}
+	}
+}
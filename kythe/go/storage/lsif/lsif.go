@@ -0,0 +1,522 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package lsif converts Kythe entries into a dump in the Language Server Index
+// Format (LSIF), for tools that consume LSIF rather than Kythe serving data.
+// See https://microsoft.github.io/language-server-protocol/specifications/lsif/0.4.0/specification/.
+//
+// Kythe elements are mapped to LSIF elements as follows:
+//
+//	file node with text      document, at the file's path under the project root
+//	anchor                   range of the document of the anchor's file
+//	anchor target            resultSet shared by all the ranges of the target
+//	defines/binding edge     item of the target's definitionResult, and of its
+//	                         referenceResult with property "definitions"
+//	ref edge (or a variant   item of the target's referenceResult with property
+//	such as ref/call)        "references"
+//	doc node and its         hoverResult of the documented node
+//	documents edge
+//
+// Other nodes, facts and edges have no LSIF counterpart and are dropped.  The
+// byte offsets of anchors are converted to LSIF (UTF-16) positions using the
+// text of their file, so anchors in files without text are also dropped.  An
+// LSIF range belongs to a single resultSet, so where several anchors share a
+// span, the range goes to the node defined there or, failing that, to the
+// target with the least ticket.  Kythe language names are mapped to LSP
+// language identifiers by LanguageID.
+//
+// Unlike the other converters, the input need not be sorted: an Exporter keeps
+// the files, anchors and documentation of its input in memory, and writes the
+// whole dump when it is closed.
+package lsif // import "kythe.io/kythe/go/storage/lsif"
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/md"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// Version is the version of LSIF written by an Exporter.
+const Version = "0.4.3"
+
+// languageIDs maps Kythe language names to LSP language identifiers, where the
+// two differ.
+var languageIDs = map[string]string{
+	"c++":      "cpp",
+	"objc":     "objective-c",
+	"protobuf": "proto",
+}
+
+// LanguageID returns the LSP language identifier of a Kythe language.
+//
+//	LanguageID("c++") == "cpp"
+//	LanguageID("go") == "go"
+func LanguageID(lang string) string {
+	if id, ok := languageIDs[lang]; ok {
+		return id
+	}
+	return lang
+}
+
+// Options control the output of an Exporter.
+type Options struct {
+	// The URI under which the paths of files are resolved to give the URIs of
+	// documents.  If empty, "file:///" is used.
+	ProjectRoot string
+}
+
+func (o *Options) projectRoot() string {
+	if o == nil || o.ProjectRoot == "" {
+		return "file:///"
+	} else if !strings.HasSuffix(o.ProjectRoot, "/") {
+		return o.ProjectRoot + "/"
+	}
+	return o.ProjectRoot
+}
+
+// An Exporter converts entries to an LSIF dump.
+type Exporter struct {
+	enc    *json.Encoder
+	root   string
+	lastID int
+
+	nodes map[string]*node // nodes of interest, by ticket
+
+	// The number of elements of each kind written, and the number of anchors
+	// dropped because their file or offsets are missing.
+	NumDocuments, NumRanges, NumResultSets, NumDropped int
+}
+
+// node records the facts and edges of a file, anchor, or doc node.
+type node struct {
+	vname      *spb.VName
+	kind       string
+	text       []byte
+	start, end string
+	links      []link
+}
+
+type link struct{ kind, target string }
+
+// NewExporter returns an Exporter that writes an LSIF dump to w, as one JSON
+// element per line.  If opts == nil, default options are used.  Close must
+// be called to write the output.
+func NewExporter(w io.Writer, opts *Options) *Exporter {
+	return &Exporter{
+		enc:   json.NewEncoder(w),
+		root:  opts.projectRoot(),
+		nodes: make(map[string]*node),
+	}
+}
+
+func (x *Exporter) node(v *spb.VName) *node {
+	ticket := kytheuri.ToString(v)
+	n := x.nodes[ticket]
+	if n == nil {
+		n = &node{vname: v}
+		x.nodes[ticket] = n
+	}
+	return n
+}
+
+// Add adds a single entry to the output.
+func (x *Exporter) Add(e *spb.Entry) error {
+	if kind := e.GetEdgeKind(); kind != "" {
+		if kind == edges.DefinesBinding || kind == edges.Documents || edges.IsVariant(kind, edges.Ref) {
+			n := x.node(e.GetSource())
+			n.links = append(n.links, link{kind, kytheuri.ToString(e.GetTarget())})
+		}
+		return nil
+	}
+	switch value := e.GetFactValue(); e.GetFactName() {
+	case facts.NodeKind:
+		switch kind := string(value); kind {
+		case nodes.Anchor, nodes.Doc, nodes.File:
+			x.node(e.GetSource()).kind = kind
+		}
+	case facts.Text:
+		x.node(e.GetSource()).text = value
+	case facts.AnchorStart:
+		x.node(e.GetSource()).start = string(value)
+	case facts.AnchorEnd:
+		x.node(e.GetSource()).end = string(value)
+	}
+	return nil
+}
+
+// A span is the location of a range within a file.
+type span struct {
+	file       string
+	start, end int
+}
+
+// A rangeSpan is the single target chosen for the anchors at a span.
+type rangeSpan struct {
+	target string
+	def    bool   // whether the anchor defines the target
+	lang   string // language of the anchor
+	id     int    // ID of the range vertex, once written
+}
+
+// Close writes the dump.  It does not close the underlying writer.
+func (x *Exporter) Close() error {
+	ranges := x.ranges()
+	files := make(map[string][]span)
+	for s := range ranges {
+		files[s.file] = append(files[s.file], s)
+	}
+	var fileTickets []string
+	for t := range files {
+		fileTickets = append(fileTickets, t)
+	}
+	sort.Strings(fileTickets)
+
+	if err := x.emit(&element{
+		Type:             "vertex",
+		Label:            "metaData",
+		Version:          Version,
+		ProjectRoot:      x.root,
+		PositionEncoding: "utf-16",
+		ToolInfo:         &toolInfo{Name: "kythe"},
+	}); err != nil {
+		return err
+	}
+	project := &element{Type: "vertex", Label: "project"}
+	if err := x.emit(project); err != nil {
+		return err
+	}
+
+	// Documents and their ranges.
+	docIDs := make(map[string]int)
+	var projectDocs []int
+	for _, ticket := range fileTickets {
+		spans := files[ticket]
+		sort.Slice(spans, func(i, j int) bool {
+			if spans[i].start != spans[j].start {
+				return spans[i].start < spans[j].start
+			}
+			return spans[i].end < spans[j].end
+		})
+		file := x.nodes[ticket]
+		doc := &element{
+			Type:       "vertex",
+			Label:      "document",
+			URI:        x.root + strings.TrimPrefix(documentPath(file.vname), "/"),
+			LanguageID: LanguageID(ranges[spans[0]].lang),
+		}
+		if err := x.emit(doc); err != nil {
+			return err
+		}
+		x.NumDocuments++
+		docIDs[ticket] = doc.ID
+		projectDocs = append(projectDocs, doc.ID)
+
+		lines := newLineIndex(file.text)
+		var contained []int
+		for _, s := range spans {
+			start, end := lines.position(s.start), lines.position(s.end)
+			r := &element{Type: "vertex", Label: "range", Start: &start, End: &end}
+			if err := x.emit(r); err != nil {
+				return err
+			}
+			x.NumRanges++
+			ranges[s].id = r.ID
+			contained = append(contained, r.ID)
+		}
+		if err := x.emit(&element{Type: "edge", Label: "contains", OutV: doc.ID, InVs: contained}); err != nil {
+			return err
+		}
+	}
+	if len(projectDocs) > 0 {
+		if err := x.emit(&element{Type: "edge", Label: "contains", OutV: project.ID, InVs: projectDocs}); err != nil {
+			return err
+		}
+	}
+
+	// Result sets of the targets of the ranges.
+	targets := make(map[string][]span)
+	for _, ticket := range fileTickets {
+		for _, s := range files[ticket] {
+			t := ranges[s].target
+			targets[t] = append(targets[t], s)
+		}
+	}
+	var targetTickets []string
+	for t := range targets {
+		targetTickets = append(targetTickets, t)
+	}
+	sort.Strings(targetTickets)
+	docs := x.documentation()
+	for _, t := range targetTickets {
+		if err := x.emitResults(targets[t], ranges, docIDs, docs[t]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// emitResults writes the resultSet of the target of the given spans, with its
+// definition, reference and hover results.
+func (x *Exporter) emitResults(spans []span, ranges map[span]*rangeSpan, docIDs map[string]int, hover string) error {
+	set := &element{Type: "vertex", Label: "resultSet"}
+	if err := x.emit(set); err != nil {
+		return err
+	}
+	x.NumResultSets++
+	var defs, refs []span
+	for _, s := range spans {
+		if err := x.emit(&element{Type: "edge", Label: "next", OutV: ranges[s].id, InV: set.ID}); err != nil {
+			return err
+		}
+		if ranges[s].def {
+			defs = append(defs, s)
+		} else {
+			refs = append(refs, s)
+		}
+	}
+
+	// items writes an item edge from the result with the given id to the
+	// ranges of spans, one edge per document.
+	items := func(result int, spans []span, property string) error {
+		for i := 0; i < len(spans); {
+			j := i
+			var ids []int
+			for ; j < len(spans) && spans[j].file == spans[i].file; j++ {
+				ids = append(ids, ranges[spans[j]].id)
+			}
+			if err := x.emit(&element{
+				Type:     "edge",
+				Label:    "item",
+				OutV:     result,
+				InVs:     ids,
+				Document: docIDs[spans[i].file],
+				Property: property,
+			}); err != nil {
+				return err
+			}
+			i = j
+		}
+		return nil
+	}
+
+	if len(defs) > 0 {
+		def := &element{Type: "vertex", Label: "definitionResult"}
+		if err := x.emit(def); err != nil {
+			return err
+		} else if err := x.emit(&element{Type: "edge", Label: "textDocument/definition", OutV: set.ID, InV: def.ID}); err != nil {
+			return err
+		} else if err := items(def.ID, defs, ""); err != nil {
+			return err
+		}
+	}
+	ref := &element{Type: "vertex", Label: "referenceResult"}
+	if err := x.emit(ref); err != nil {
+		return err
+	} else if err := x.emit(&element{Type: "edge", Label: "textDocument/references", OutV: set.ID, InV: ref.ID}); err != nil {
+		return err
+	} else if err := items(ref.ID, defs, "definitions"); err != nil {
+		return err
+	} else if err := items(ref.ID, refs, "references"); err != nil {
+		return err
+	}
+
+	if hover == "" {
+		return nil
+	}
+	h := &element{Type: "vertex", Label: "hoverResult", Result: &hoverResult{
+		Contents: markupContent{Kind: "plaintext", Value: hover},
+	}}
+	if err := x.emit(h); err != nil {
+		return err
+	}
+	return x.emit(&element{Type: "edge", Label: "textDocument/hover", OutV: set.ID, InV: h.ID})
+}
+
+// ranges returns the target of each span of an anchor that can be placed in
+// the text of its file.
+func (x *Exporter) ranges() map[span]*rangeSpan {
+	ranges := make(map[span]*rangeSpan)
+	for _, n := range x.nodes {
+		if n.kind != nodes.Anchor || len(n.links) == 0 {
+			continue
+		}
+		file := kytheuri.ToString(&spb.VName{
+			Corpus: n.vname.GetCorpus(),
+			Root:   n.vname.GetRoot(),
+			Path:   n.vname.GetPath(),
+		})
+		start, serr := strconv.Atoi(n.start)
+		end, eerr := strconv.Atoi(n.end)
+		if f := x.nodes[file]; f == nil || f.kind != nodes.File || serr != nil || eerr != nil ||
+			start < 0 || end < start || end > len(f.text) {
+			x.NumDropped++
+			continue
+		}
+
+		var cand *rangeSpan
+		for _, l := range n.links {
+			if l.kind == edges.Documents {
+				continue
+			}
+			c := &rangeSpan{target: l.target, def: l.kind == edges.DefinesBinding, lang: n.vname.GetLanguage()}
+			if better(c, cand) {
+				cand = c
+			}
+		}
+		if cand == nil {
+			continue
+		}
+		s := span{file, start, end}
+		if better(cand, ranges[s]) {
+			ranges[s] = cand
+		}
+	}
+	return ranges
+}
+
+// better reports whether a is preferred to b as the target of a range.
+func better(a, b *rangeSpan) bool {
+	switch {
+	case b == nil:
+		return true
+	case a.def != b.def:
+		return a.def
+	}
+	return a.target < b.target
+}
+
+// documentation returns the text of the documentation of each documented
+// node, by ticket.
+func (x *Exporter) documentation() map[string]string {
+	docs := make(map[string]string)
+	var tickets []string
+	for t, n := range x.nodes {
+		if n.kind == nodes.Doc && len(n.text) > 0 {
+			tickets = append(tickets, t)
+		}
+	}
+	// Where a node has several docs, prefer the one with the least ticket.
+	sort.Sort(sort.Reverse(sort.StringSlice(tickets)))
+	for _, t := range tickets {
+		n := x.nodes[t]
+		for _, l := range n.links {
+			if l.kind == edges.Documents {
+				docs[l.target] = md.PlainText(string(n.text))
+			}
+		}
+	}
+	return docs
+}
+
+// documentPath returns the path of a file relative to the project root.
+func documentPath(v *spb.VName) string {
+	if v.GetRoot() == "" {
+		return v.GetPath()
+	}
+	return v.GetRoot() + "/" + v.GetPath()
+}
+
+// emit assigns the next ID to e and writes it.
+func (x *Exporter) emit(e *element) error {
+	x.lastID++
+	e.ID = x.lastID
+	return x.enc.Encode(e)
+}
+
+// An element is an LSIF vertex or edge.  IDs are assigned from 1, so zero IDs
+// are omitted.
+type element struct {
+	ID    int    `json:"id"`
+	Type  string `json:"type"`
+	Label string `json:"label"`
+
+	// Vertex properties.
+	Version          string       `json:"version,omitempty"`
+	ProjectRoot      string       `json:"projectRoot,omitempty"`
+	PositionEncoding string       `json:"positionEncoding,omitempty"`
+	ToolInfo         *toolInfo    `json:"toolInfo,omitempty"`
+	URI              string       `json:"uri,omitempty"`
+	LanguageID       string       `json:"languageId,omitempty"`
+	Start            *position    `json:"start,omitempty"`
+	End              *position    `json:"end,omitempty"`
+	Result           *hoverResult `json:"result,omitempty"`
+
+	// Edge properties.
+	OutV     int    `json:"outV,omitempty"`
+	InV      int    `json:"inV,omitempty"`
+	InVs     []int  `json:"inVs,omitempty"`
+	Document int    `json:"document,omitempty"`
+	Property string `json:"property,omitempty"`
+}
+
+type toolInfo struct {
+	Name string `json:"name"`
+}
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type hoverResult struct {
+	Contents markupContent `json:"contents"`
+}
+
+type markupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// A lineIndex converts byte offsets in a text to LSIF positions.
+type lineIndex struct {
+	text   []byte
+	starts []int // byte offsets of the start of each line
+}
+
+func newLineIndex(text []byte) *lineIndex {
+	starts := []int{0}
+	for i, b := range text {
+		if b == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return &lineIndex{text: text, starts: starts}
+}
+
+// position returns the position of offset, which must be within the text,
+// counting characters in UTF-16 code units.
+func (l *lineIndex) position(offset int) position {
+	line := sort.Search(len(l.starts), func(i int) bool { return l.starts[i] > offset }) - 1
+	var char int
+	for _, r := range string(l.text[l.starts[line]:offset]) {
+		if r >= 0x10000 {
+			char += 2
+		} else {
+			char++
+		}
+	}
+	return position{Line: line, Character: char}
+}
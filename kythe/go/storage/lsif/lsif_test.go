@@ -0,0 +1,165 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lsif
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	"github.com/google/go-cmp/cmp"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func fact(v *spb.VName, name, value string) *spb.Entry {
+	return &spb.Entry{Source: v, FactName: name, FactValue: []byte(value)}
+}
+
+func edge(src *spb.VName, kind string, tgt *spb.VName) *spb.Entry {
+	return &spb.Entry{Source: src, EdgeKind: kind, Target: tgt, FactName: "/"}
+}
+
+func anchor(file *spb.VName, start, end int) *spb.VName {
+	return &spb.VName{
+		Corpus:    file.Corpus,
+		Path:      file.Path,
+		Language:  "go",
+		Signature: "@" + strconv.Itoa(start) + ":" + strconv.Itoa(end),
+	}
+}
+
+func TestExporter(t *testing.T) {
+	// Line 1 contains a character outside the BMP, which is two UTF-16 code
+	// units wide.
+	const text = "package p\n/*😀*/ func f() { f() }\n"
+	file := &spb.VName{Corpus: "c", Path: "p/a.go"}
+	f := &spb.VName{Corpus: "c", Language: "go", Signature: "f"}
+	g := &spb.VName{Corpus: "c", Language: "go", Signature: "g"}
+	doc := &spb.VName{Corpus: "c", Language: "go", Signature: "f#doc"}
+	def, ref := anchor(file, 24, 25), anchor(file, 30, 31)
+	dup := anchor(file, 30, 31)
+	dup.Signature += "dup"
+	other := &spb.VName{Corpus: "c", Path: "missing.go", Language: "go", Signature: "@0:1"}
+
+	var entries []*spb.Entry
+	entries = append(entries,
+		// Out of order: the reference and the documentation precede the file.
+		fact(ref, facts.NodeKind, nodes.Anchor),
+		fact(ref, facts.AnchorStart, "30"),
+		fact(ref, facts.AnchorEnd, "31"),
+		edge(ref, edges.RefCall, f),
+		edge(ref, edges.ChildOf, file),
+		// A second anchor at the same span: the range goes to f < g.
+		fact(dup, facts.NodeKind, nodes.Anchor),
+		fact(dup, facts.AnchorStart, "30"),
+		fact(dup, facts.AnchorEnd, "31"),
+		edge(dup, edges.Ref, g),
+		fact(doc, facts.NodeKind, nodes.Doc),
+		fact(doc, facts.Text, `Function \[f\] calls [f].`),
+		edge(doc, edges.Documents, f),
+		fact(file, facts.NodeKind, nodes.File),
+		fact(file, facts.Text, text),
+		fact(def, facts.NodeKind, nodes.Anchor),
+		fact(def, facts.AnchorStart, "24"),
+		fact(def, facts.AnchorEnd, "25"),
+		edge(def, edges.DefinesBinding, f),
+		edge(f, edges.Mirror(edges.DefinesBinding), def),
+		fact(f, facts.NodeKind, nodes.Function),
+		// An anchor in a file without text is dropped.
+		fact(other, facts.NodeKind, nodes.Anchor),
+		fact(other, facts.AnchorStart, "0"),
+		fact(other, facts.AnchorEnd, "1"),
+		edge(other, edges.Ref, f),
+	)
+
+	var buf strings.Builder
+	x := NewExporter(&buf, &Options{ProjectRoot: "file:///src"})
+	for _, e := range entries {
+		if err := x.Add(e); err != nil {
+			t.Fatalf("Add(%v): %v", e, err)
+		}
+	}
+	if err := x.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	want := `{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///src/","positionEncoding":"utf-16","toolInfo":{"name":"kythe"}}
+{"id":2,"type":"vertex","label":"project"}
+{"id":3,"type":"vertex","label":"document","uri":"file:///src/p/a.go","languageId":"go"}
+{"id":4,"type":"vertex","label":"range","start":{"line":1,"character":12},"end":{"line":1,"character":13}}
+{"id":5,"type":"vertex","label":"range","start":{"line":1,"character":18},"end":{"line":1,"character":19}}
+{"id":6,"type":"edge","label":"contains","outV":3,"inVs":[4,5]}
+{"id":7,"type":"edge","label":"contains","outV":2,"inVs":[3]}
+{"id":8,"type":"vertex","label":"resultSet"}
+{"id":9,"type":"edge","label":"next","outV":4,"inV":8}
+{"id":10,"type":"edge","label":"next","outV":5,"inV":8}
+{"id":11,"type":"vertex","label":"definitionResult"}
+{"id":12,"type":"edge","label":"textDocument/definition","outV":8,"inV":11}
+{"id":13,"type":"edge","label":"item","outV":11,"inVs":[4],"document":3}
+{"id":14,"type":"vertex","label":"referenceResult"}
+{"id":15,"type":"edge","label":"textDocument/references","outV":8,"inV":14}
+{"id":16,"type":"edge","label":"item","outV":14,"inVs":[4],"document":3,"property":"definitions"}
+{"id":17,"type":"edge","label":"item","outV":14,"inVs":[5],"document":3,"property":"references"}
+{"id":18,"type":"vertex","label":"hoverResult","result":{"contents":{"kind":"plaintext","value":"Function [f] calls f."}}}
+{"id":19,"type":"edge","label":"textDocument/hover","outV":8,"inV":18}
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Dump: (-want +got)\n%s", diff)
+	}
+	if x.NumDocuments != 1 || x.NumRanges != 2 || x.NumResultSets != 1 || x.NumDropped != 1 {
+		t.Errorf("Got %d documents, %d ranges, %d result sets, %d dropped; want 1, 2, 1, 1",
+			x.NumDocuments, x.NumRanges, x.NumResultSets, x.NumDropped)
+	}
+}
+
+func TestLineIndex(t *testing.T) {
+	l := newLineIndex([]byte("ab\nçd\n\n😀x"))
+	for _, test := range []struct {
+		offset int
+		want   position
+	}{
+		{0, position{0, 0}},
+		{2, position{0, 2}},
+		{3, position{1, 0}},
+		{6, position{1, 2}}, // ç is two bytes but one code unit
+		{7, position{2, 0}},
+		{8, position{3, 0}},
+		{12, position{3, 2}}, // 😀 is four bytes and two code units
+		{13, position{3, 3}},
+	} {
+		if got := l.position(test.offset); got != test.want {
+			t.Errorf("position(%d): got %+v, want %+v", test.offset, got, test.want)
+		}
+	}
+}
+
+func TestLanguageID(t *testing.T) {
+	for lang, want := range map[string]string{
+		"c++":      "cpp",
+		"go":       "go",
+		"protobuf": "proto",
+	} {
+		if got := LanguageID(lang); got != want {
+			t.Errorf("LanguageID(%q): got %q, want %q", lang, got, want)
+		}
+	}
+}
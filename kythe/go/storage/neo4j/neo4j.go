@@ -0,0 +1,209 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package neo4j converts Kythe entries into the CSV files accepted by the
+// Neo4j bulk importer (neo4j-admin database import).
+//
+// Each node becomes a row of the nodes file, identified by its ticket and
+// labeled with "Node" plus its node kind.  Each forward edge becomes a row of
+// the relationships file whose type is derived from the edge kind (e.g.
+// /kythe/edge/ref/call becomes REF_CALL).  Reverse edges are dropped since
+// Neo4j relationships may be traversed in either direction.
+//
+// The input must be grouped by source VName, as produced by
+// entrystream --sort.  Edge targets without any facts of their own are
+// written as nodes with only the "Node" label.
+package neo4j // import "kythe.io/kythe/go/storage/neo4j"
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"kythe.io/kythe/go/util/compare"
+	"kythe.io/kythe/go/util/encoding/tabular"
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// NodesHeader and RelationshipsHeader are the header rows of the nodes and
+// relationships files.
+var (
+	NodesHeader = []string{
+		"ticket:ID", "corpus", "root", "path", "language", "signature",
+		"kind", "subkind", "start:int", "end:int", ":LABEL",
+	}
+	RelationshipsHeader = []string{":START_ID", ":END_ID", ":TYPE", "ordinal:int"}
+)
+
+// An Exporter writes entries as Neo4j bulk-import CSV files.
+type Exporter struct {
+	nodes, rels *tabular.Writer
+
+	cur     *spb.VName        // source of the current node
+	curFact map[string]string // facts of the current node
+
+	written map[string]bool // tickets with a node row
+	targets map[string]bool // edge targets without a node row (yet)
+
+	// NumNodes and NumRelationships are the number of rows written to each
+	// file, excluding headers.
+	NumNodes, NumRelationships int
+}
+
+// NewExporter returns an Exporter that writes node rows to nodes and
+// relationship rows to rels.  Close must be called to complete the output.
+func NewExporter(nodes, rels io.Writer) (*Exporter, error) {
+	x := &Exporter{
+		nodes:   tabular.NewWriter(nodes, tabular.CSV),
+		rels:    tabular.NewWriter(rels, tabular.CSV),
+		written: make(map[string]bool),
+		targets: make(map[string]bool),
+	}
+	if err := x.nodes.Write(NodesHeader); err != nil {
+		return nil, err
+	} else if err := x.rels.Write(RelationshipsHeader); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// Add adds a single entry to the output.
+func (x *Exporter) Add(e *spb.Entry) error {
+	if !compare.VNamesEqual(x.cur, e.GetSource()) {
+		if err := x.flushNode(); err != nil {
+			return err
+		}
+		ticket := kytheuri.ToString(e.GetSource())
+		if x.written[ticket] {
+			return fmt.Errorf("entries for %q are not contiguous; sort the input", ticket)
+		}
+		x.cur = e.GetSource()
+		x.curFact = make(map[string]string)
+	}
+
+	if e.GetEdgeKind() == "" {
+		switch name := e.GetFactName(); name {
+		case facts.NodeKind, facts.Subkind, facts.AnchorStart, facts.AnchorEnd:
+			x.curFact[name] = string(e.GetFactValue())
+		}
+		return nil
+	} else if edges.IsReverse(e.GetEdgeKind()) {
+		return nil
+	}
+
+	kind, ordinal, hasOrdinal := edges.ParseOrdinal(e.GetEdgeKind())
+	var ord string
+	if hasOrdinal {
+		ord = strconv.Itoa(ordinal)
+	}
+	target := kytheuri.ToString(e.GetTarget())
+	if !x.written[target] {
+		x.targets[target] = true
+	}
+	x.NumRelationships++
+	return x.rels.Write([]string{kytheuri.ToString(e.GetSource()), target, RelationshipType(kind), ord})
+}
+
+// flushNode writes the row for the current node, if any.
+func (x *Exporter) flushNode() error {
+	if x.cur == nil {
+		return nil
+	}
+	v, f := x.cur, x.curFact
+	ticket := kytheuri.ToString(v)
+	labels := "Node"
+	if kind := f[facts.NodeKind]; kind != "" {
+		labels += ";" + Label(kind)
+	}
+	x.cur, x.curFact = nil, nil
+	x.written[ticket] = true
+	delete(x.targets, ticket)
+	x.NumNodes++
+	return x.nodes.Write([]string{
+		ticket, v.GetCorpus(), v.GetRoot(), v.GetPath(), v.GetLanguage(), v.GetSignature(),
+		f[facts.NodeKind], f[facts.Subkind], f[facts.AnchorStart], f[facts.AnchorEnd], labels,
+	})
+}
+
+// Close writes any remaining nodes and flushes the output.  It does not close
+// the underlying writers.
+func (x *Exporter) Close() error {
+	if err := x.flushNode(); err != nil {
+		return err
+	}
+	var stubs []string
+	for t := range x.targets {
+		stubs = append(stubs, t)
+	}
+	sort.Strings(stubs)
+	for _, t := range stubs {
+		v, err := kytheuri.ToVName(t)
+		if err != nil {
+			return err
+		}
+		x.NumNodes++
+		if err := x.nodes.Write([]string{
+			t, v.GetCorpus(), v.GetRoot(), v.GetPath(), v.GetLanguage(), v.GetSignature(),
+			"", "", "", "", "Node",
+		}); err != nil {
+			return err
+		}
+	}
+	if err := x.nodes.Flush(); err != nil {
+		return err
+	}
+	return x.rels.Flush()
+}
+
+// RelationshipType returns the Neo4j relationship type for an edge kind.
+//
+//	RelationshipType("/kythe/edge/ref/call") == "REF_CALL"
+func RelationshipType(kind string) string {
+	kind = strings.TrimPrefix(edges.Canonical(kind), edges.Prefix)
+	return sanitize(strings.TrimPrefix(kind, "/"), unicode.ToUpper)
+}
+
+// Label returns the Neo4j label for a node kind.
+//
+//	Label("anchor") == "Anchor"
+//	Label("google/gflag") == "Google_gflag"
+func Label(kind string) string {
+	s := sanitize(kind, func(r rune) rune { return r })
+	if s == "" {
+		return s
+	}
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[n:]
+}
+
+// sanitize maps each rune of s through f, replacing characters that are not
+// letters or digits with underscores.
+func sanitize(s string, f func(rune) rune) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return f(r)
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package neo4j
+
+import (
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	"github.com/google/go-cmp/cmp"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestExporter(t *testing.T) {
+	a := &spb.VName{Corpus: "c", Path: "a.go", Signature: "a"}
+	f := &spb.VName{Corpus: "c", Language: "go", Signature: "f"}
+	p := &spb.VName{Corpus: "c", Language: "go", Signature: "p"}
+	entries := []*spb.Entry{
+		{Source: a, FactName: facts.NodeKind, FactValue: []byte("anchor")},
+		{Source: a, FactName: facts.AnchorStart, FactValue: []byte("3")},
+		{Source: a, FactName: facts.AnchorEnd, FactValue: []byte("7")},
+		{Source: a, FactName: facts.Text, FactValue: []byte("ignored")},
+		{Source: a, EdgeKind: edges.RefCall, Target: f, FactName: "/"},
+		{Source: f, FactName: facts.NodeKind, FactValue: []byte("function")},
+		{Source: f, EdgeKind: edges.ParamIndex(0), Target: p, FactName: "/"},
+		{Source: f, EdgeKind: edges.Mirror(edges.RefCall), Target: a, FactName: "/"},
+	}
+
+	var nodes, rels strings.Builder
+	x, err := NewExporter(&nodes, &rels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if err := x.Add(e); err != nil {
+			t.Fatalf("Add(%v): %v", e, err)
+		}
+	}
+	if err := x.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if diff := cmp.Diff(`ticket:ID,corpus,root,path,language,signature,kind,subkind,start:int,end:int,:LABEL
+kythe://c?path=a.go#a,c,,a.go,,a,anchor,,3,7,Node;Anchor
+kythe://c?lang=go#f,c,,,go,f,function,,,,Node;Function
+kythe://c?lang=go#p,c,,,go,p,,,,,Node
+`, nodes.String()); diff != "" {
+		t.Errorf("Nodes: (-want +got)\n%s", diff)
+	}
+	if diff := cmp.Diff(`:START_ID,:END_ID,:TYPE,ordinal:int
+kythe://c?path=a.go#a,kythe://c?lang=go#f,REF_CALL,
+kythe://c?lang=go#f,kythe://c?lang=go#p,PARAM,0
+`, rels.String()); diff != "" {
+		t.Errorf("Relationships: (-want +got)\n%s", diff)
+	}
+	if x.NumNodes != 3 || x.NumRelationships != 2 {
+		t.Errorf("Got %d nodes and %d relationships; want 3 and 2", x.NumNodes, x.NumRelationships)
+	}
+}
+
+func TestExporterUnsorted(t *testing.T) {
+	a, b := &spb.VName{Signature: "a"}, &spb.VName{Signature: "b"}
+	x, err := NewExporter(new(strings.Builder), new(strings.Builder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []*spb.VName{a, b} {
+		if err := x.Add(&spb.Entry{Source: v, FactName: facts.NodeKind, FactValue: []byte("record")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := x.Add(&spb.Entry{Source: a, FactName: facts.Subkind, FactValue: []byte("class")}); err == nil {
+		t.Error("Expected error for non-contiguous entries")
+	}
+}
+
+func TestNames(t *testing.T) {
+	for kind, want := range map[string]string{
+		edges.RefCall:               "REF_CALL",
+		edges.Mirror(edges.ChildOf): "CHILDOF",
+		"/custom/edge-kind":         "CUSTOM_EDGE_KIND",
+		edges.ExtendsPublicVirtual:  "EXTENDS_PUBLIC_VIRTUAL",
+	} {
+		if got := RelationshipType(kind); got != want {
+			t.Errorf("RelationshipType(%q): got %q, want %q", kind, got, want)
+		}
+	}
+	for kind, want := range map[string]string{
+		"anchor":       "Anchor",
+		"google/gflag": "Google_gflag",
+		"":             "",
+	} {
+		if got := Label(kind); got != want {
+			t.Errorf("Label(%q): got %q, want %q", kind, got, want)
+		}
+	}
+}
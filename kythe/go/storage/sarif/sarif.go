@@ -0,0 +1,328 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package sarif converts the diagnostics recorded in Kythe entries into a
+// SARIF 2.1.0 log (https://docs.oasis-open.org/sarif/sarif/v2.1.0/), so that
+// findings of indexers can be shown by tools that understand SARIF.
+//
+// Each diagnostic node becomes a result whose message is the diagnostic's
+// /kythe/message, followed by its /kythe/details if any.  Its
+// /kythe/context/url is recorded as the result property "contextUrl".  A
+// result is located at each anchor or file tagged with the diagnostic.
+// Anchor regions are given by line and column (counted in Unicode code
+// points, from 1) when the text of their file is known, and by byte offsets
+// otherwise.  Kythe diagnostics have no severity or rule, so every result has
+// the same level and no rule ID.
+//
+// Like the other exporters, an Exporter keeps the nodes it needs in memory,
+// so the entries need not be sorted.
+package sarif // import "kythe.io/kythe/go/storage/sarif"
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+	"kythe.io/kythe/go/util/span"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// Version is the version of SARIF written by an Exporter.
+const Version = "2.1.0"
+
+const schemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// Options control the output of an Exporter.
+type Options struct {
+	// The name of the tool reported as the producer of the results.  If
+	// empty, "kythe" is used.
+	ToolName string
+
+	// The level of every result: "error", "warning" or "note".  If empty,
+	// "warning" is used.
+	Level string
+
+	// If set, the URI of the directory to which the paths of files are
+	// relative, recorded as the base URI "%SRCROOT%".
+	SourceRoot string
+}
+
+func (o *Options) toolName() string {
+	if o == nil || o.ToolName == "" {
+		return "kythe"
+	}
+	return o.ToolName
+}
+
+func (o *Options) level() string {
+	if o == nil || o.Level == "" {
+		return "warning"
+	}
+	return o.Level
+}
+
+// An Exporter converts the diagnostics of entries to a SARIF log.
+type Exporter struct {
+	w     io.Writer
+	opts  *Options
+	nodes map[string]*node // by ticket
+
+	// The number of results written, and the number of their locations.
+	NumResults, NumLocations int
+}
+
+// node records the facts and tagged edges of a node of interest.
+type node struct {
+	vname                 *spb.VName
+	kind                  string
+	text                  []byte
+	start, end            string
+	message, details, url string
+	tags                  []string // tickets of the diagnostics tagging the node
+}
+
+// NewExporter returns an Exporter that writes a SARIF log to w.  If opts ==
+// nil, default options are used.  Close must be called to write the output.
+func NewExporter(w io.Writer, opts *Options) *Exporter {
+	return &Exporter{w: w, opts: opts, nodes: make(map[string]*node)}
+}
+
+func (x *Exporter) node(v *spb.VName) *node {
+	ticket := kytheuri.ToString(v)
+	n := x.nodes[ticket]
+	if n == nil {
+		n = &node{vname: v}
+		x.nodes[ticket] = n
+	}
+	return n
+}
+
+// Add adds a single entry to the output.
+func (x *Exporter) Add(e *spb.Entry) error {
+	if kind := e.GetEdgeKind(); kind == edges.Tagged {
+		n := x.node(e.GetSource())
+		n.tags = append(n.tags, kytheuri.ToString(e.GetTarget()))
+		return nil
+	} else if kind != "" {
+		return nil
+	}
+	switch value := string(e.GetFactValue()); e.GetFactName() {
+	case facts.NodeKind:
+		switch value {
+		case nodes.Anchor, nodes.Diagnostic, nodes.File:
+			x.node(e.GetSource()).kind = value
+		}
+	case facts.Text:
+		x.node(e.GetSource()).text = e.GetFactValue()
+	case facts.AnchorStart:
+		x.node(e.GetSource()).start = value
+	case facts.AnchorEnd:
+		x.node(e.GetSource()).end = value
+	case facts.Message:
+		x.node(e.GetSource()).message = value
+	case facts.Details:
+		x.node(e.GetSource()).details = value
+	case facts.ContextURL:
+		x.node(e.GetSource()).url = value
+	}
+	return nil
+}
+
+// Close writes the log.  It does not close the underlying writer.
+func (x *Exporter) Close() error {
+	locs := make(map[string][]*location) // by diagnostic ticket
+	norms := make(map[string]*span.Normalizer)
+	for _, n := range x.nodes {
+		if len(n.tags) == 0 || (n.kind != nodes.Anchor && n.kind != nodes.File) {
+			continue
+		}
+		loc := x.location(n, norms)
+		for _, diag := range n.tags {
+			locs[diag] = append(locs[diag], loc)
+		}
+	}
+
+	var results []*result
+	for ticket, n := range x.nodes {
+		if n.kind != nodes.Diagnostic {
+			continue
+		}
+		r := &result{Level: x.opts.level(), Message: message{Text: n.message}}
+		if n.details != "" {
+			r.Message.Text += "\n\n" + n.details
+		}
+		if n.url != "" {
+			r.Properties = map[string]string{"contextUrl": n.url}
+		}
+		ls := locs[ticket]
+		sort.Slice(ls, func(i, j int) bool { return ls[i].less(ls[j]) })
+		r.Locations = ls
+		results = append(results, r)
+		x.NumLocations += len(ls)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		switch {
+		case len(a.Locations) == 0 || len(b.Locations) == 0:
+			return len(a.Locations) > len(b.Locations)
+		case a.Locations[0].less(b.Locations[0]):
+			return true
+		case b.Locations[0].less(a.Locations[0]):
+			return false
+		}
+		return a.Message.Text < b.Message.Text
+	})
+	x.NumResults = len(results)
+
+	out := &run{
+		Tool:       tool{Driver: driver{Name: x.opts.toolName(), InformationURI: "https://kythe.io"}},
+		ColumnKind: "unicodeCodePoints",
+		Results:    results,
+	}
+	if x.opts != nil && x.opts.SourceRoot != "" {
+		out.OriginalURIBaseIDs = map[string]artifactLocation{srcRoot: {URI: x.opts.SourceRoot}}
+	}
+	if out.Results == nil {
+		out.Results = []*result{}
+	}
+	enc := json.NewEncoder(x.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&sarifLog{Schema: schemaURI, Version: Version, Runs: []*run{out}})
+}
+
+const srcRoot = "%SRCROOT%"
+
+// location returns the location of an anchor or file node, using norms to
+// cache the line structure of the text of each file.
+func (x *Exporter) location(n *node, norms map[string]*span.Normalizer) *location {
+	file := &spb.VName{Corpus: n.vname.GetCorpus(), Root: n.vname.GetRoot(), Path: n.vname.GetPath()}
+	path := file.GetPath()
+	if r := file.GetRoot(); r != "" {
+		path = r + "/" + path
+	}
+	loc := &location{PhysicalLocation: physicalLocation{ArtifactLocation: artifactLocation{URI: path}}}
+	if x.opts != nil && x.opts.SourceRoot != "" {
+		loc.PhysicalLocation.ArtifactLocation.URIBaseID = srcRoot
+	}
+	if n.kind == nodes.File {
+		return loc
+	}
+
+	start, serr := strconv.Atoi(n.start)
+	end, eerr := strconv.Atoi(n.end)
+	if serr != nil || eerr != nil || start < 0 || end < start {
+		return loc
+	}
+	loc.start = start
+	ticket := kytheuri.ToString(file)
+	f := x.nodes[ticket]
+	if f == nil || f.kind != nodes.File || end > len(f.text) {
+		loc.PhysicalLocation.Region = &region{ByteOffset: &start, ByteLength: end - start}
+		return loc
+	}
+	norm := norms[ticket]
+	if norm == nil {
+		norm = span.NewNormalizer(f.text)
+		norms[ticket] = norm
+	}
+	s := norm.SpanOffsets(int32(start), int32(end))
+	loc.PhysicalLocation.Region = &region{
+		StartLine:   int(s.Start.LineNumber),
+		StartColumn: int(norm.CharacterColumn(s.Start)) + 1,
+		EndLine:     int(s.End.LineNumber),
+		EndColumn:   int(norm.CharacterColumn(s.End)) + 1,
+	}
+	return loc
+}
+
+// The elements of a SARIF log written by an Exporter.
+type (
+	sarifLog struct {
+		Schema  string `json:"$schema"`
+		Version string `json:"version"`
+		Runs    []*run `json:"runs"`
+	}
+
+	run struct {
+		Tool               tool                        `json:"tool"`
+		OriginalURIBaseIDs map[string]artifactLocation `json:"originalUriBaseIds,omitempty"`
+		ColumnKind         string                      `json:"columnKind"`
+		Results            []*result                   `json:"results"`
+	}
+
+	tool struct {
+		Driver driver `json:"driver"`
+	}
+
+	driver struct {
+		Name           string `json:"name"`
+		InformationURI string `json:"informationUri,omitempty"`
+	}
+
+	result struct {
+		Level      string            `json:"level"`
+		Message    message           `json:"message"`
+		Locations  []*location       `json:"locations,omitempty"`
+		Properties map[string]string `json:"properties,omitempty"`
+	}
+
+	message struct {
+		Text string `json:"text"`
+	}
+
+	location struct {
+		PhysicalLocation physicalLocation `json:"physicalLocation"`
+
+		start int // byte offset of the start of the region, for sorting
+	}
+
+	physicalLocation struct {
+		ArtifactLocation artifactLocation `json:"artifactLocation"`
+		Region           *region          `json:"region,omitempty"`
+	}
+
+	artifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId,omitempty"`
+	}
+
+	region struct {
+		StartLine   int  `json:"startLine,omitempty"`
+		StartColumn int  `json:"startColumn,omitempty"`
+		EndLine     int  `json:"endLine,omitempty"`
+		EndColumn   int  `json:"endColumn,omitempty"`
+		ByteOffset  *int `json:"byteOffset,omitempty"`
+		ByteLength  int  `json:"byteLength,omitempty"`
+	}
+)
+
+// less orders locations by path and then by start offset, with whole files
+// first.
+func (l *location) less(o *location) bool {
+	a, b := l.PhysicalLocation, o.PhysicalLocation
+	if a.ArtifactLocation.URI != b.ArtifactLocation.URI {
+		return a.ArtifactLocation.URI < b.ArtifactLocation.URI
+	} else if (a.Region == nil) != (b.Region == nil) {
+		return a.Region == nil
+	}
+	return l.start < o.start
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sarif
+
+import (
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	"github.com/google/go-cmp/cmp"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestExporter(t *testing.T) {
+	file := &spb.VName{Corpus: "c", Root: "r", Path: "a.go"}
+	noText := &spb.VName{Corpus: "c", Path: "b.go"}
+	anchor := &spb.VName{Corpus: "c", Root: "r", Path: "a.go", Language: "go", Signature: "@15:16"}
+	other := &spb.VName{Corpus: "c", Path: "b.go", Language: "go", Signature: "@3:5"}
+	diag := func(sig string) *spb.VName { return &spb.VName{Corpus: "c", Language: "go", Signature: sig} }
+	fact := func(v *spb.VName, name, value string) *spb.Entry {
+		return &spb.Entry{Source: v, FactName: name, FactValue: []byte(value)}
+	}
+	tag := func(src, diag *spb.VName) *spb.Entry {
+		return &spb.Entry{Source: src, EdgeKind: edges.Tagged, Target: diag, FactName: "/"}
+	}
+	entries := []*spb.Entry{
+		tag(anchor, diag("unused")),
+		tag(other, diag("unused")),
+		fact(diag("unused"), facts.NodeKind, nodes.Diagnostic),
+		fact(diag("unused"), facts.Message, "unused variable"),
+		fact(anchor, facts.NodeKind, nodes.Anchor),
+		fact(anchor, facts.AnchorStart, "15"),
+		fact(anchor, facts.AnchorEnd, "16"),
+		fact(other, facts.NodeKind, nodes.Anchor),
+		fact(other, facts.AnchorStart, "3"),
+		fact(other, facts.AnchorEnd, "5"),
+		fact(file, facts.NodeKind, nodes.File),
+		// The second line starts with two-byte characters.
+		fact(file, facts.Text, "package p\nçç x := 1\n"),
+		tag(file, diag("generated")),
+		fact(diag("generated"), facts.NodeKind, nodes.Diagnostic),
+		fact(diag("generated"), facts.Message, "file is generated"),
+		fact(diag("generated"), facts.Details, "edit the source instead"),
+		fact(diag("generated"), facts.ContextURL, "https://example.com/gen"),
+		fact(diag("loose"), facts.NodeKind, nodes.Diagnostic),
+		fact(diag("loose"), facts.Message, "no location"),
+		fact(noText, facts.NodeKind, nodes.File),
+	}
+
+	var buf strings.Builder
+	x := NewExporter(&buf, &Options{Level: "note", SourceRoot: "file:///src/"})
+	for _, e := range entries {
+		if err := x.Add(e); err != nil {
+			t.Fatalf("Add(%v): %v", e, err)
+		}
+	}
+	if err := x.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	const want = `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "kythe",
+          "informationUri": "https://kythe.io"
+        }
+      },
+      "originalUriBaseIds": {
+        "%SRCROOT%": {
+          "uri": "file:///src/"
+        }
+      },
+      "columnKind": "unicodeCodePoints",
+      "results": [
+        {
+          "level": "note",
+          "message": {
+            "text": "unused variable"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "b.go",
+                  "uriBaseId": "%SRCROOT%"
+                },
+                "region": {
+                  "byteOffset": 3,
+                  "byteLength": 2
+                }
+              }
+            },
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "r/a.go",
+                  "uriBaseId": "%SRCROOT%"
+                },
+                "region": {
+                  "startLine": 2,
+                  "startColumn": 4,
+                  "endLine": 2,
+                  "endColumn": 5
+                }
+              }
+            }
+          ]
+        },
+        {
+          "level": "note",
+          "message": {
+            "text": "file is generated\n\nedit the source instead"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "r/a.go",
+                  "uriBaseId": "%SRCROOT%"
+                }
+              }
+            }
+          ],
+          "properties": {
+            "contextUrl": "https://example.com/gen"
+          }
+        },
+        {
+          "level": "note",
+          "message": {
+            "text": "no location"
+          }
+        }
+      ]
+    }
+  ]
+}
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Log: (-want +got)\n%s", diff)
+	}
+	if x.NumResults != 3 || x.NumLocations != 3 {
+		t.Errorf("Got %d results with %d locations; want 3 with 3", x.NumResults, x.NumLocations)
+	}
+}
+
+func TestExporterEmpty(t *testing.T) {
+	var buf strings.Builder
+	x := NewExporter(&buf, nil)
+	if err := x.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := buf.String(); !strings.Contains(got, `"results": []`) {
+		t.Errorf("Empty log has no results array:\n%s", got)
+	}
+}
@@ -0,0 +1,548 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package scip converts Kythe entries into an index in the SCIP Code
+// Intelligence Protocol format (https://github.com/sourcegraph/scip), for
+// tools that consume SCIP rather than Kythe serving data.
+//
+// Each file node with text becomes a document.  Each defines/binding or ref
+// edge (or variant of ref) of an anchor becomes an occurrence of the symbol of
+// its target, with the Definition role for bindings, Import for
+// ref/imports and ref/includes, and WriteAccess for ref/writes.  Each node
+// defined in a document has a SymbolInformation in that document, giving
+// the text of its binding as display name, the text of its doc nodes as
+// documentation, and relationships derived from its edges:
+//
+//	extends (and variants), satisfies    is_implementation
+//	overrides                            is_implementation, is_reference
+//	typed, to a record or interface      is_type_definition
+//
+// Documented nodes that are not defined in any document are listed as
+// external symbols.  Positions are byte offsets from the start of the line,
+// which SCIP calls UTF8CodeUnitOffsetFromLineStart.
+//
+// Like the LSIF exporter, an Exporter keeps its input in memory, so the
+// entries need not be sorted.
+package scip // import "kythe.io/kythe/go/storage/scip"
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/md"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+	"kythe.io/kythe/go/util/span"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// Symbol returns the SCIP symbol for the node with the given VName and node
+// kind.  The symbol has the scheme "kythe", a package whose manager, name and
+// version are the language, corpus and root of v, and descriptors for each
+// component of the path of v followed by its signature.  The suffix of the
+// signature descriptor reflects the kind of the node:
+//
+//	Symbol({Corpus: "c", Language: "go", Path: "p/a.go", Signature: "T"}, "record")
+//	  == "kythe go c . p/`a.go`/T#"
+//
+// Local variables are instead given document-local symbols by an Exporter.
+func Symbol(v *spb.VName, kind string) string {
+	var sb strings.Builder
+	sb.WriteString("kythe ")
+	for _, field := range []string{v.GetLanguage(), v.GetCorpus(), v.GetRoot()} {
+		if field == "" {
+			field = "."
+		}
+		sb.WriteString(strings.ReplaceAll(field, " ", "  "))
+		sb.WriteByte(' ')
+	}
+	if p := v.GetPath(); p != "" {
+		for _, dir := range strings.Split(p, "/") {
+			sb.WriteString(escape(dir))
+			sb.WriteByte('/')
+		}
+	}
+	sig := v.GetSignature()
+	if sig == "" {
+		// A file, or a node whose identity is entirely in its path.  Every
+		// symbol needs at least one descriptor.
+		sb.WriteString("`.`.")
+		return sb.String()
+	}
+	sb.WriteString(escape(sig))
+	switch kind {
+	case nodes.Package:
+		sb.WriteByte('/')
+	case nodes.Record, nodes.Interface, nodes.TAlias, "sum":
+		sb.WriteByte('#')
+	case nodes.Function:
+		sb.WriteString("().")
+	default:
+		sb.WriteByte('.')
+	}
+	return sb.String()
+}
+
+// escape returns name as a SCIP descriptor name, in backquotes unless it is
+// a simple identifier.
+func escape(name string) string {
+	simple := name != ""
+	for _, r := range name {
+		if !(r == '_' || r == '+' || r == '-' || r == '$' ||
+			'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9') {
+			simple = false
+			break
+		}
+	}
+	if simple {
+		return name
+	}
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// languages maps Kythe language names to the names of SCIP's Language enum.
+var languages = map[string]string{
+	"c":          "C",
+	"c++":        "CPP",
+	"go":         "Go",
+	"java":       "Java",
+	"javascript": "JavaScript",
+	"kotlin":     "Kotlin",
+	"objc":       "ObjectiveC",
+	"protobuf":   "Protobuf",
+	"python":     "Python",
+	"rust":       "Rust",
+	"typescript": "TypeScript",
+}
+
+// Language returns the SCIP name of a Kythe language.  Languages unknown to
+// SCIP are returned unchanged.
+func Language(lang string) string {
+	if name, ok := languages[lang]; ok {
+		return name
+	}
+	return lang
+}
+
+// Symbol roles, as defined by SCIP.
+const (
+	roleDefinition  = 0x1
+	roleImport      = 0x2
+	roleWriteAccess = 0x4
+)
+
+// role returns the SCIP symbol role for an anchor edge kind, and false if the
+// edge does not give rise to an occurrence.
+func role(kind string) (int, bool) {
+	switch {
+	case kind == edges.DefinesBinding:
+		return roleDefinition, true
+	case edges.IsVariant(kind, edges.RefImports), edges.IsVariant(kind, edges.RefIncludes):
+		return roleImport, true
+	case edges.IsVariant(kind, edges.RefWrites):
+		return roleWriteAccess, true
+	case edges.IsVariant(kind, edges.Ref):
+		return 0, true
+	}
+	return 0, false
+}
+
+// Options control the output of an Exporter.
+type Options struct {
+	// The URI of the directory to which the paths of documents are relative.
+	ProjectRoot string
+
+	// The version of the tool recorded in the index metadata.
+	ToolVersion string
+}
+
+// An Exporter converts entries to a SCIP index.
+type Exporter struct {
+	w     io.Writer
+	opts  Options
+	nodes map[string]*node // by ticket
+	local map[string]string
+
+	// The number of documents, occurrences and symbols written, and the
+	// number of anchors dropped because their file or offsets are missing.
+	NumDocuments, NumOccurrences, NumSymbols, NumDropped int
+}
+
+// node records the facts and edges of a node of interest.
+type node struct {
+	vname         *spb.VName
+	kind, subkind string
+	text          []byte
+	start, end    string
+	links         []link
+}
+
+type link struct{ kind, target string }
+
+// NewExporter returns an Exporter that writes an index to w.  If opts == nil,
+// default options are used.  Close must be called to write the output.
+func NewExporter(w io.Writer, opts *Options) *Exporter {
+	x := &Exporter{w: w, nodes: make(map[string]*node), local: make(map[string]string)}
+	if opts != nil {
+		x.opts = *opts
+	}
+	return x
+}
+
+func (x *Exporter) node(v *spb.VName) *node {
+	ticket := kytheuri.ToString(v)
+	n := x.nodes[ticket]
+	if n == nil {
+		n = &node{vname: v}
+		x.nodes[ticket] = n
+	}
+	return n
+}
+
+// keepEdge reports whether edges of the given kind are used by the exporter.
+func keepEdge(kind string) bool {
+	if _, ok := role(kind); ok {
+		return true
+	}
+	switch {
+	case kind == edges.Documents, kind == edges.Typed, kind == edges.Satisfies, kind == edges.Overrides,
+		edges.IsVariant(kind, edges.Extends):
+		return true
+	}
+	return false
+}
+
+// Add adds a single entry to the output.
+func (x *Exporter) Add(e *spb.Entry) error {
+	if kind := e.GetEdgeKind(); kind != "" {
+		if keepEdge(kind) {
+			n := x.node(e.GetSource())
+			n.links = append(n.links, link{kind, kytheuri.ToString(e.GetTarget())})
+		}
+		return nil
+	}
+	switch value := e.GetFactValue(); e.GetFactName() {
+	case facts.NodeKind:
+		x.node(e.GetSource()).kind = string(value)
+	case facts.Subkind:
+		x.node(e.GetSource()).subkind = string(value)
+	case facts.Text:
+		x.node(e.GetSource()).text = value
+	case facts.AnchorStart:
+		x.node(e.GetSource()).start = string(value)
+	case facts.AnchorEnd:
+		x.node(e.GetSource()).end = string(value)
+	}
+	return nil
+}
+
+// symbol returns the symbol of the node with the given ticket.
+func (x *Exporter) symbol(ticket string) string {
+	if s, ok := x.local[ticket]; ok {
+		return s
+	}
+	n := x.nodes[ticket]
+	if n == nil {
+		v, err := kytheuri.ToVName(ticket)
+		if err != nil {
+			return "local " + escape(ticket)
+		}
+		return Symbol(v, "")
+	}
+	return Symbol(n.vname, n.kind)
+}
+
+// An occurrence of a symbol within a document.
+type occurrence struct {
+	start, end int32
+	rng        []int32
+	target     string
+	roles      int
+}
+
+// A document is a file with occurrences.
+type document struct {
+	file        *node
+	lang        string
+	occurrences []*occurrence
+	defined     map[string]string // display name of each node defined, by ticket
+}
+
+// Close writes the index.  It does not close the underlying writer.
+func (x *Exporter) Close() error {
+	docs := x.documents()
+	var paths []string
+	byPath := make(map[string]*document)
+	for _, d := range docs {
+		p := documentPath(d.file.vname)
+		paths = append(paths, p)
+		byPath[p] = d
+	}
+	sort.Strings(paths)
+
+	x.assignLocals(paths, byPath)
+	docText := x.documentation()
+
+	var index []byte
+	var meta []byte
+	meta = appendMessage(meta, 2, appendString(appendString(nil, 1, "kythe"), 2, x.opts.ToolVersion))
+	meta = appendString(meta, 3, x.opts.ProjectRoot)
+	meta = appendVarint(meta, 4, 1) // UTF8
+	index = appendMessage(index, 1, meta)
+
+	defined := make(map[string]bool)
+	for _, p := range paths {
+		d := byPath[p]
+		var msg []byte
+		msg = appendString(msg, 1, p)
+		for _, o := range d.occurrences {
+			var occ []byte
+			occ = appendPacked(occ, 1, o.rng)
+			occ = appendString(occ, 2, x.symbol(o.target))
+			occ = appendVarint(occ, 3, uint64(o.roles))
+			msg = appendMessage(msg, 2, occ)
+			x.NumOccurrences++
+		}
+		var tickets []string
+		for t := range d.defined {
+			tickets = append(tickets, t)
+			defined[t] = true
+		}
+		sort.Strings(tickets)
+		for _, t := range tickets {
+			msg = appendMessage(msg, 3, x.symbolInformation(t, d.defined[t], docText[t]))
+			x.NumSymbols++
+		}
+		msg = appendString(msg, 4, Language(d.lang))
+		msg = appendVarint(msg, 6, 1) // UTF8CodeUnitOffsetFromLineStart
+		index = appendMessage(index, 2, msg)
+		x.NumDocuments++
+	}
+
+	var external []string
+	for t := range docText {
+		if !defined[t] {
+			external = append(external, t)
+		}
+	}
+	sort.Strings(external)
+	for _, t := range external {
+		index = appendMessage(index, 3, x.symbolInformation(t, "", docText[t]))
+		x.NumSymbols++
+	}
+
+	_, err := x.w.Write(index)
+	return err
+}
+
+// symbolInformation returns an encoded SymbolInformation message for the node
+// with the given ticket.
+func (x *Exporter) symbolInformation(ticket, name, doc string) []byte {
+	var msg []byte
+	msg = appendString(msg, 1, x.symbol(ticket))
+	if doc != "" {
+		msg = appendString(msg, 3, doc)
+	}
+	if n := x.nodes[ticket]; n != nil {
+		for _, l := range n.links {
+			var rel []byte
+			switch {
+			case l.kind == edges.Overrides:
+				rel = appendBool(appendBool(nil, 2, true), 3, true)
+			case l.kind == edges.Satisfies, edges.IsVariant(l.kind, edges.Extends):
+				rel = appendBool(nil, 3, true)
+			case l.kind == edges.Typed:
+				switch x.kindOf(l.target) {
+				case nodes.Record, nodes.Interface:
+					rel = appendBool(nil, 4, true)
+				}
+			}
+			if rel != nil {
+				msg = appendMessage(msg, 4, append(appendString(nil, 1, x.symbol(l.target)), rel...))
+			}
+		}
+	}
+	return appendString(msg, 6, name)
+}
+
+// kindOf returns the node kind of the node with the given ticket, if known.
+func (x *Exporter) kindOf(ticket string) string {
+	if n := x.nodes[ticket]; n != nil {
+		return n.kind
+	}
+	return ""
+}
+
+// documents returns the files with text that contain occurrences.
+func (x *Exporter) documents() []*document {
+	docs := make(map[string]*document)
+	for _, n := range x.nodes {
+		if n.kind != nodes.Anchor {
+			continue
+		}
+		var occs []link
+		for _, l := range n.links {
+			if _, ok := role(l.kind); ok {
+				occs = append(occs, l)
+			}
+		}
+		if len(occs) == 0 {
+			continue
+		}
+		fileTicket := kytheuri.ToString(&spb.VName{
+			Corpus: n.vname.GetCorpus(),
+			Root:   n.vname.GetRoot(),
+			Path:   n.vname.GetPath(),
+		})
+		file := x.nodes[fileTicket]
+		start, serr := strconv.Atoi(n.start)
+		end, eerr := strconv.Atoi(n.end)
+		if file == nil || file.kind != nodes.File || serr != nil || eerr != nil ||
+			start < 0 || end < start || end > len(file.text) {
+			x.NumDropped++
+			continue
+		}
+
+		d := docs[fileTicket]
+		if d == nil {
+			d = &document{file: file, lang: n.vname.GetLanguage(), defined: make(map[string]string)}
+			docs[fileTicket] = d
+		}
+		for _, l := range occs {
+			r, _ := role(l.kind)
+			d.occurrences = append(d.occurrences, &occurrence{
+				start:  int32(start),
+				end:    int32(end),
+				target: l.target,
+				roles:  r,
+			})
+			if r == roleDefinition {
+				d.defined[l.target] = string(file.text[start:end])
+			}
+		}
+	}
+
+	var out []*document
+	for _, d := range docs {
+		norm := span.NewNormalizer(d.file.text)
+		for _, o := range d.occurrences {
+			s := norm.SpanOffsets(o.start, o.end)
+			o.rng = []int32{s.Start.LineNumber - 1, s.Start.ColumnOffset}
+			if s.End.LineNumber != s.Start.LineNumber {
+				o.rng = append(o.rng, s.End.LineNumber-1)
+			}
+			o.rng = append(o.rng, s.End.ColumnOffset)
+		}
+		sort.Slice(d.occurrences, func(i, j int) bool {
+			a, b := d.occurrences[i], d.occurrences[j]
+			if a.start != b.start {
+				return a.start < b.start
+			} else if a.end != b.end {
+				return a.end < b.end
+			}
+			return a.target < b.target
+		})
+		out = append(out, d)
+	}
+	return out
+}
+
+// assignLocals gives each local variable referred to in the documents a
+// symbol "local N", numbering them in order of first occurrence.
+func (x *Exporter) assignLocals(paths []string, byPath map[string]*document) {
+	for _, p := range paths {
+		for _, o := range byPath[p].occurrences {
+			n := x.nodes[o.target]
+			if n == nil || (n.subkind != nodes.Local && n.subkind != nodes.LocalParameter) {
+				continue
+			}
+			if _, ok := x.local[o.target]; !ok {
+				x.local[o.target] = "local " + strconv.Itoa(len(x.local))
+			}
+		}
+	}
+}
+
+// documentation returns the text of the documentation of each documented
+// node, by ticket.  Where a node has several doc nodes, the one with the
+// least ticket is used.
+func (x *Exporter) documentation() map[string]string {
+	var tickets []string
+	for t, n := range x.nodes {
+		if n.kind == nodes.Doc && len(n.text) > 0 {
+			tickets = append(tickets, t)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(tickets)))
+	docs := make(map[string]string)
+	for _, t := range tickets {
+		n := x.nodes[t]
+		for _, l := range n.links {
+			if l.kind == edges.Documents {
+				docs[l.target] = md.PlainText(string(n.text))
+			}
+		}
+	}
+	return docs
+}
+
+// documentPath returns the path of a file relative to the project root.
+func documentPath(v *spb.VName) string {
+	if v.GetRoot() == "" {
+		return v.GetPath()
+	}
+	return v.GetRoot() + "/" + v.GetPath()
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendBool(b []byte, num protowire.Number, v bool) []byte {
+	return appendVarint(b, num, protowire.EncodeBool(v))
+}
+
+func appendPacked(b []byte, num protowire.Number, vs []int32) []byte {
+	var packed []byte
+	for _, v := range vs {
+		packed = protowire.AppendVarint(packed, uint64(v))
+	}
+	return appendMessage(b, num, packed)
+}
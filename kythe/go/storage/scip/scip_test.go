@@ -0,0 +1,228 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scip
+
+import (
+	"bytes"
+	"testing"
+
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// indexDescriptor returns the descriptor of the part of the SCIP Index
+// message written by an Exporter, with enums as plain integers.
+func indexDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	const (
+		str = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		i32 = descriptorpb.FieldDescriptorProto_TYPE_INT32
+		bln = descriptorpb.FieldDescriptorProto_TYPE_BOOL
+		msg = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	)
+	field := func(name string, num int32, typ descriptorpb.FieldDescriptorProto_Type, repeated bool, msgType string) *descriptorpb.FieldDescriptorProto {
+		label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		if repeated {
+			label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+		}
+		f := &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(num), Type: typ.Enum(), Label: label.Enum()}
+		if msgType != "" {
+			f.TypeName = proto.String(".scip." + msgType)
+		}
+		return f
+	}
+	message := func(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+	}
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("scip.proto"),
+		Package: proto.String("scip"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			message("Index",
+				field("metadata", 1, msg, false, "Metadata"),
+				field("documents", 2, msg, true, "Document"),
+				field("external_symbols", 3, msg, true, "SymbolInformation")),
+			message("Metadata",
+				field("version", 1, i32, false, ""),
+				field("tool_info", 2, msg, false, "ToolInfo"),
+				field("project_root", 3, str, false, ""),
+				field("text_document_encoding", 4, i32, false, "")),
+			message("ToolInfo",
+				field("name", 1, str, false, ""),
+				field("version", 2, str, false, "")),
+			message("Document",
+				field("relative_path", 1, str, false, ""),
+				field("occurrences", 2, msg, true, "Occurrence"),
+				field("symbols", 3, msg, true, "SymbolInformation"),
+				field("language", 4, str, false, ""),
+				field("position_encoding", 6, i32, false, "")),
+			message("Occurrence",
+				field("range", 1, i32, true, ""),
+				field("symbol", 2, str, false, ""),
+				field("symbol_roles", 3, i32, false, "")),
+			message("SymbolInformation",
+				field("symbol", 1, str, false, ""),
+				field("documentation", 3, str, true, ""),
+				field("relationships", 4, msg, true, "Relationship"),
+				field("display_name", 6, str, false, "")),
+			message("Relationship",
+				field("symbol", 1, str, false, ""),
+				field("is_reference", 2, bln, false, ""),
+				field("is_implementation", 3, bln, false, ""),
+				field("is_type_definition", 4, bln, false, "")),
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd.Messages().ByName("Index")
+}
+
+func TestExporter(t *testing.T) {
+	const text = "type T struct{}\nfunc (T) M() { x := T{}\n  x = x }\n"
+	file := &spb.VName{Corpus: "c", Root: "r", Path: "p/a.go"}
+	anchor := func(sig string) *spb.VName {
+		return &spb.VName{Corpus: "c", Root: "r", Path: "p/a.go", Language: "go", Signature: sig}
+	}
+	sem := func(sig string) *spb.VName { return &spb.VName{Corpus: "c", Language: "go", Signature: sig} }
+	typ, method, iface, local, doc := sem("T"), sem("T.M"), sem("I"), sem("x"), sem("T#doc")
+	fact := func(v *spb.VName, name, value string) *spb.Entry {
+		return &spb.Entry{Source: v, FactName: name, FactValue: []byte(value)}
+	}
+	edge := func(src *spb.VName, kind string, tgt *spb.VName) *spb.Entry {
+		return &spb.Entry{Source: src, EdgeKind: kind, Target: tgt, FactName: "/"}
+	}
+	var entries []*spb.Entry
+	addAnchor := func(sig string, start, end string, kind string, target *spb.VName) {
+		a := anchor(sig)
+		entries = append(entries,
+			fact(a, facts.NodeKind, nodes.Anchor),
+			fact(a, facts.AnchorStart, start),
+			fact(a, facts.AnchorEnd, end),
+			edge(a, edges.ChildOf, file),
+			edge(a, kind, target))
+	}
+	addAnchor("@42", "42", "43", edges.RefWrites, local)
+	addAnchor("@5", "5", "6", edges.DefinesBinding, typ)
+	addAnchor("@22", "22", "23", edges.Ref, typ)
+	addAnchor("@25", "25", "26", edges.DefinesBinding, method)
+	addAnchor("@31", "31", "32", edges.DefinesBinding, local)
+	addAnchor("@36", "36", "37", edges.Ref, typ)
+	entries = append(entries,
+		fact(file, facts.NodeKind, nodes.File),
+		fact(file, facts.Text, text),
+		fact(typ, facts.NodeKind, nodes.Record),
+		fact(iface, facts.NodeKind, nodes.Interface),
+		fact(method, facts.NodeKind, nodes.Function),
+		fact(local, facts.NodeKind, nodes.Variable),
+		fact(local, facts.Subkind, nodes.Local),
+		edge(typ, edges.Satisfies, iface),
+		edge(local, edges.Typed, typ),
+		edge(typ, edges.ChildOf, file),
+		fact(doc, facts.NodeKind, nodes.Doc),
+		fact(doc, facts.Text, "Type [T]."),
+		edge(doc, edges.Documents, typ),
+		fact(sem("Idoc"), facts.NodeKind, nodes.Doc),
+		fact(sem("Idoc"), facts.Text, "An interface."),
+		edge(sem("Idoc"), edges.Documents, iface),
+		// An anchor whose file has no text.
+		fact(sem("@dropped"), facts.NodeKind, nodes.Anchor),
+		edge(sem("@dropped"), edges.Ref, typ),
+	)
+
+	var buf bytes.Buffer
+	x := NewExporter(&buf, &Options{ProjectRoot: "file:///src", ToolVersion: "v1"})
+	for _, e := range entries {
+		if err := x.Add(e); err != nil {
+			t.Fatalf("Add(%v): %v", e, err)
+		}
+	}
+	if err := x.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	desc := indexDescriptor(t)
+	got := dynamicpb.NewMessage(desc)
+	if err := proto.Unmarshal(buf.Bytes(), got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	want := dynamicpb.NewMessage(desc)
+	if err := prototext.Unmarshal([]byte(`
+metadata { tool_info { name: "kythe" version: "v1" } project_root: "file:///src" text_document_encoding: 1 }
+documents {
+  relative_path: "r/p/a.go"
+  language: "Go"
+  position_encoding: 1
+  occurrences { range: [0, 5, 6] symbol: "kythe go c . T#" symbol_roles: 1 }
+  occurrences { range: [1, 6, 7] symbol: "kythe go c . T#" }
+  occurrences { range: [1, 9, 10] symbol: "kythe go c . `+"`T.M`"+`()." symbol_roles: 1 }
+  occurrences { range: [1, 15, 16] symbol: "local 0" symbol_roles: 1 }
+  occurrences { range: [1, 20, 21] symbol: "kythe go c . T#" }
+  occurrences { range: [2, 2, 3] symbol: "local 0" symbol_roles: 4 }
+  symbols {
+    symbol: "kythe go c . T#"
+    documentation: "Type T."
+    relationships { symbol: "kythe go c . I#" is_implementation: true }
+    display_name: "T"
+  }
+  symbols { symbol: "kythe go c . `+"`T.M`"+`()." display_name: "M" }
+  symbols {
+    symbol: "local 0"
+    relationships { symbol: "kythe go c . T#" is_type_definition: true }
+    display_name: "x"
+  }
+}
+external_symbols { symbol: "kythe go c . I#" documentation: "An interface." }
+`), want); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("Index:\ngot  %v\nwant %v", prototext.Format(got), prototext.Format(want))
+	}
+	if x.NumDocuments != 1 || x.NumOccurrences != 6 || x.NumSymbols != 4 || x.NumDropped != 1 {
+		t.Errorf("Got %d documents, %d occurrences, %d symbols, %d dropped; want 1, 6, 4, 1",
+			x.NumDocuments, x.NumOccurrences, x.NumSymbols, x.NumDropped)
+	}
+}
+
+func TestSymbol(t *testing.T) {
+	for _, test := range []struct {
+		v    *spb.VName
+		kind string
+		want string
+	}{
+		{&spb.VName{Corpus: "c", Language: "go", Path: "p/a.go", Signature: "T"}, nodes.Record, "kythe go c . p/`a.go`/T#"},
+		{&spb.VName{Corpus: "my corpus", Root: "r", Language: "c++", Signature: "f`1"}, nodes.Function, "kythe c++ my  corpus r `f``1`()."},
+		{&spb.VName{Language: "go", Signature: "fmt"}, nodes.Package, "kythe go . . fmt/"},
+		{&spb.VName{Corpus: "c", Path: "a.h"}, nodes.File, "kythe . c . `a.h`/`.`."},
+	} {
+		if got := Symbol(test.v, test.kind); got != test.want {
+			t.Errorf("Symbol(%v, %q): got %q, want %q", test.v, test.kind, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+/*
+ * Copyright 2014 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package follow reads entry streams that are still being written.
+//
+// A followed file is read like a normal file except that, at the end of the
+// available data, the reader waits for more to be appended rather than
+// reporting EOF.  A followed directory is treated as a sequence of shards
+// read in lexical order; a shard is considered complete once a shard with a
+// later name appears, and the last shard is followed like a single file.
+//
+// Following stops when the context is canceled or when no new data has
+// appeared for the configured idle timeout.  Both are treated as the end of
+// the stream.
+package follow // import "kythe.io/kythe/go/storage/stream/follow"
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"kythe.io/kythe/go/platform/delimited/manifest"
+	"kythe.io/kythe/go/storage/stream"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// DefaultPollInterval is the default interval between checks for new data.
+const DefaultPollInterval = time.Second
+
+// Options control how a stream is followed.
+type Options struct {
+	// PollInterval is the time to wait between checks for new data.  If
+	// zero, DefaultPollInterval is used.
+	PollInterval time.Duration
+
+	// IdleTimeout, if positive, ends the stream once no new data has
+	// appeared for this long.  Otherwise, the stream is followed until the
+	// context is canceled.
+	IdleTimeout time.Duration
+}
+
+func (o *Options) withDefaults() Options {
+	var opts Options
+	if o != nil {
+		opts = *o
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	return opts
+}
+
+// NewReader opens the file at path and returns a reader that follows it as
+// it grows.
+func NewReader(ctx context.Context, path string, opts *Options) (io.ReadCloser, error) {
+	lastData := time.Now()
+	return open(ctx, path, opts.withDefaults(), &lastData, func() (bool, error) { return false, nil })
+}
+
+func open(ctx context.Context, path string, opts Options, lastData *time.Time, complete func() (bool, error)) (*reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &reader{ctx: ctx, f: f, opts: opts, lastData: lastData, complete: complete}, nil
+}
+
+// A reader follows a single file.
+type reader struct {
+	ctx      context.Context
+	f        *os.File
+	opts     Options
+	lastData *time.Time // when new data last appeared; see wait
+
+	// complete reports whether the file will not be appended to further.
+	complete func() (bool, error)
+}
+
+// Read implements io.Reader, waiting for data at the end of the file.
+func (r *reader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	var n int
+	if err := wait(r.ctx, r.opts, r.lastData, func() (bool, error) {
+		// Check for completion before reading, so that data appended
+		// just before the file was completed is not lost.
+		complete, err := r.complete()
+		if err != nil {
+			return false, err
+		}
+		n, err = r.f.Read(p)
+		if n > 0 {
+			return true, nil
+		} else if err != nil && err != io.EOF {
+			return false, err
+		}
+		return complete, nil
+	}); err != nil {
+		return 0, err
+	} else if n == 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// Close implements io.Closer.
+func (r *reader) Close() error { return r.f.Close() }
+
+// errStop is returned by wait when following should end.
+var errStop = io.EOF
+
+// wait calls ready until it returns true, pausing opts.PollInterval between
+// calls, and then records the time in lastData.  It returns errStop if the
+// context is canceled or opts.IdleTimeout elapses since lastData first.  The
+// waits for the shards of a directory share lastData, so that the timeout
+// covers the stream as a whole.
+func wait(ctx context.Context, opts Options, lastData *time.Time, ready func() (bool, error)) error {
+	for {
+		if ctx.Err() != nil {
+			return errStop
+		}
+		if ok, err := ready(); err != nil {
+			return err
+		} else if ok {
+			*lastData = time.Now()
+			return nil
+		}
+		if opts.IdleTimeout > 0 && time.Since(*lastData) >= opts.IdleTimeout {
+			return errStop
+		}
+		select {
+		case <-ctx.Done():
+			return errStop
+		case <-time.After(opts.PollInterval):
+		}
+	}
+}
+
+// Entries returns an EntryReader that follows the delimited entry stream at
+// path, which may be a single file or a directory of shards.  Files in a
+// directory whose names begin with "." or "_", and integrity manifests, are
+// ignored.
+func Entries(ctx context.Context, path string, opts *Options) stream.EntryReader {
+	o := opts.withDefaults()
+	return func(f func(*spb.Entry) error) error {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		lastData := time.Now()
+		if !fi.IsDir() {
+			rd, err := open(ctx, path, o, &lastData, func() (bool, error) { return false, nil })
+			if err != nil {
+				return err
+			}
+			defer rd.Close()
+			return stream.NewReader(rd)(f)
+		}
+
+		var shard string
+		for {
+			var next string
+			if err := wait(ctx, o, &lastData, func() (bool, error) {
+				var err error
+				next, err = nextShard(path, shard)
+				return next != "", err
+			}); err == errStop {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			rd, err := open(ctx, filepath.Join(path, next), o, &lastData, func() (bool, error) {
+				later, err := nextShard(path, next)
+				return later != "", err
+			})
+			if err != nil {
+				return err
+			}
+			err = stream.NewReader(rd)(f)
+			rd.Close()
+			if err != nil {
+				return err
+			}
+			shard = next
+		}
+	}
+}
+
+// nextShard returns the name of the first shard in dir whose name sorts after
+// prev, or "" if there is none.
+func nextShard(dir, prev string) (string, error) {
+	des, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var names []string
+	for _, de := range des {
+		name := de.Name()
+		if de.IsDir() || name <= prev || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") ||
+			strings.HasSuffix(name, manifest.Extension) {
+			continue
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return "", nil
+	}
+	sort.Strings(names)
+	return names[0], nil
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright 2014 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package follow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"kythe.io/kythe/go/platform/delimited"
+	"kythe.io/kythe/go/storage/stream"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+var testOpts = &Options{PollInterval: 5 * time.Millisecond, IdleTimeout: 200 * time.Millisecond}
+
+func entry(i int) *spb.Entry {
+	return &spb.Entry{
+		Source:    &spb.VName{Signature: fmt.Sprintf("node%d", i)},
+		FactName:  "/kythe/node/kind",
+		FactValue: []byte("test"),
+	}
+}
+
+// appendEntries appends entries [from, to) to the file at path, optionally
+// preceded by a stream header.
+func appendEntries(t *testing.T, path string, header bool, from, to int) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wr := delimited.NewWriter(f)
+	if header {
+		if err := stream.WriteHeader(wr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := from; i < to; i++ {
+		if err := wr.PutProto(entry(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// follow reads rd in the background, sending each entry's signature to the
+// returned channel, which is closed when rd returns.
+func follow(t *testing.T, rd stream.EntryReader) <-chan string {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		if err := rd(func(e *spb.Entry) error {
+			ch <- e.GetSource().GetSignature()
+			return nil
+		}); err != nil {
+			t.Errorf("Unexpected read error: %v", err)
+		}
+	}()
+	return ch
+}
+
+func expect(t *testing.T, ch <-chan string, from, to int) {
+	t.Helper()
+	for i := from; i < to; i++ {
+		want := fmt.Sprintf("node%d", i)
+		if got, ok := <-ch; !ok {
+			t.Fatalf("Stream ended; want %q", want)
+		} else if got != want {
+			t.Fatalf("Got entry %q, want %q", got, want)
+		}
+	}
+}
+
+func expectEnd(t *testing.T, ch <-chan string) {
+	t.Helper()
+	if got, ok := <-ch; ok {
+		t.Fatalf("Got unexpected entry %q", got)
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "entries")
+	appendEntries(t, path, true, 0, 2)
+
+	ch := follow(t, Entries(context.Background(), path, testOpts))
+	expect(t, ch, 0, 2)
+	appendEntries(t, path, false, 2, 4)
+	expect(t, ch, 2, 4)
+	expectEnd(t, ch)
+}
+
+func TestDirectory(t *testing.T) {
+	dir := t.TempDir()
+	appendEntries(t, filepath.Join(dir, "shard-0"), false, 0, 2)
+	appendEntries(t, filepath.Join(dir, ".shard-1.tmp"), false, 100, 101)
+	if err := os.WriteFile(filepath.Join(dir, "shard-0.manifest"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ch := follow(t, Entries(context.Background(), dir, testOpts))
+	expect(t, ch, 0, 2)
+	appendEntries(t, filepath.Join(dir, "shard-0"), false, 2, 3)
+	expect(t, ch, 2, 3)
+	appendEntries(t, filepath.Join(dir, "shard-1"), true, 3, 5)
+	expect(t, ch, 3, 5)
+	expectEnd(t, ch)
+}
+
+func TestCancel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "entries")
+	appendEntries(t, path, false, 0, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := follow(t, Entries(ctx, path, &Options{PollInterval: 5 * time.Millisecond}))
+	expect(t, ch, 0, 1)
+	cancel()
+	expectEnd(t, ch)
+}
+
+func TestCanceled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "entries")
+	appendEntries(t, path, false, 0, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ch := follow(t, Entries(ctx, path, testOpts))
+	expectEnd(t, ch)
+}
+
+func TestDirectoryIdleTimeout(t *testing.T) {
+	dir := t.TempDir()
+	appendEntries(t, filepath.Join(dir, "shard-0"), false, 0, 1)
+
+	opts := &Options{PollInterval: 5 * time.Millisecond, IdleTimeout: 300 * time.Millisecond}
+	ch := follow(t, Entries(context.Background(), dir, opts))
+	expect(t, ch, 0, 1)
+	start := time.Now()
+	expectEnd(t, ch)
+	// The idle timeout covers the last shard and the wait for the next one
+	// together, rather than each in turn.
+	if elapsed := time.Since(start); elapsed >= 2*opts.IdleTimeout {
+		t.Errorf("Stream ended %v after its last entry; want less than %v", elapsed, 2*opts.IdleTimeout)
+	}
+}
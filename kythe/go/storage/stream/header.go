@@ -0,0 +1,158 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stream
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"kythe.io/kythe/go/platform/delimited"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// HeaderVersion is the current version of the delimited entry file format.
+const HeaderVersion = 1
+
+// headerMagic begins every header record.  A serialized Entry can never start
+// with a zero byte (field number 0 is invalid), so a header is unambiguous
+// and files without one remain readable.
+var headerMagic = []byte("\x00kythe.entries\x00")
+
+// A Header is an optional first record of a delimited entry file describing
+// the format of the records that follow.  The header records the descriptors
+// of the protos used to write the file, allowing entries written by older
+// versions of the pipeline to be decoded after the schema has changed.
+//
+// A header record is encoded as headerMagic, followed by the format version
+// as a uvarint, followed by a serialized FileDescriptorSet.
+type Header struct {
+	Version     int
+	Descriptors *descriptorpb.FileDescriptorSet
+}
+
+// CurrentHeader returns a Header describing the entries written by this
+// binary.
+func CurrentHeader() *Header {
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]bool)
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+	}
+	add(entryDescriptor.ParentFile())
+	return &Header{Version: HeaderVersion, Descriptors: set}
+}
+
+var entryDescriptor = (&spb.Entry{}).ProtoReflect().Descriptor()
+
+// Marshal returns the encoded header record.
+func (h *Header) Marshal() ([]byte, error) {
+	desc, err := proto.MarshalOptions{Deterministic: true}.Marshal(h.Descriptors)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding descriptors: %v", err)
+	}
+	rec := append([]byte(nil), headerMagic...)
+	rec = binary.AppendUvarint(rec, uint64(h.Version))
+	return append(rec, desc...), nil
+}
+
+// IsHeader reports whether rec is a header record.
+func IsHeader(rec []byte) bool { return bytes.HasPrefix(rec, headerMagic) }
+
+// ParseHeader decodes a header record.
+func ParseHeader(rec []byte) (*Header, error) {
+	if !IsHeader(rec) {
+		return nil, errors.New("missing header magic")
+	}
+	rec = rec[len(headerMagic):]
+	version, n := binary.Uvarint(rec)
+	if n <= 0 {
+		return nil, errors.New("invalid header version")
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(rec[n:], set); err != nil {
+		return nil, fmt.Errorf("error decoding header descriptors: %v", err)
+	}
+	return &Header{Version: int(version), Descriptors: set}, nil
+}
+
+// WriteHeader writes CurrentHeader to wr.  It should be called before any
+// entries are written.
+func WriteHeader(wr *delimited.Writer) error {
+	rec, err := CurrentHeader().Marshal()
+	if err != nil {
+		return err
+	}
+	return wr.Put(rec)
+}
+
+// entryDecoder returns a function that decodes records written with the
+// header's descriptors.  If the descriptors match those compiled into this
+// binary, records are decoded directly and current is true.  Otherwise, each
+// record is decoded with the embedded descriptors and converted to the
+// current Entry by field name.
+func (h *Header) entryDecoder() (decode func([]byte, *spb.Entry) error, current bool, _ error) {
+	if h.Version > HeaderVersion {
+		return nil, false, fmt.Errorf("unsupported entry file version %d (latest supported is %d)", h.Version, HeaderVersion)
+	}
+	files, err := protodesc.NewFiles(h.Descriptors)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid header descriptors: %v", err)
+	}
+	d, err := files.FindDescriptorByName(entryDescriptor.FullName())
+	if err != nil {
+		return nil, false, fmt.Errorf("header does not describe %s: %v", entryDescriptor.FullName(), err)
+	}
+	md, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, false, fmt.Errorf("header descriptor %s is not a message", d.FullName())
+	}
+
+	if proto.Equal(protodesc.ToFileDescriptorProto(md.ParentFile()), protodesc.ToFileDescriptorProto(entryDescriptor.ParentFile())) {
+		return func(rec []byte, e *spb.Entry) error { return proto.Unmarshal(rec, e) }, true, nil
+	}
+	unmarshal := protojson.UnmarshalOptions{DiscardUnknown: true}
+	return func(rec []byte, e *spb.Entry) error {
+		msg := dynamicpb.NewMessage(md)
+		if err := proto.Unmarshal(rec, msg); err != nil {
+			return err
+		}
+		js, err := protojson.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		return unmarshal.Unmarshal(js, e)
+	}, false, nil
+}
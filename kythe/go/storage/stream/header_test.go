@@ -0,0 +1,158 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stream
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/platform/delimited"
+	"kythe.io/kythe/go/util/compare"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// readAll reads the entries in buf, checking that a RawEntryReader yields
+// the same entries.
+func readAll(t *testing.T, buf *bytes.Buffer) []*spb.Entry {
+	t.Helper()
+	raw := readAllRaw(t, buf.Bytes())
+	var got []*spb.Entry
+	if err := NewReader(buf)(func(e *spb.Entry) error {
+		got = append(got, e)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := compare.ProtoDiff(got, raw); diff != "" {
+		t.Errorf("Raw entries differ: %s", diff)
+	}
+	return got
+}
+
+func TestHeaderRoundTrip(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	wr := delimited.NewWriter(buf)
+	if err := WriteHeader(wr); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range testEntries {
+		if err := wr.PutProto(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec, err := delimited.NewReader(bytes.NewReader(buf.Bytes())).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := ParseHeader(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Version != HeaderVersion {
+		t.Errorf("Version: got %d, want %d", h.Version, HeaderVersion)
+	}
+
+	if diff := compare.ProtoDiff(testEntries, readAll(t, buf)); diff != "" {
+		t.Errorf("Unexpected entries: %s", diff)
+	}
+}
+
+func TestHeaderOldDescriptors(t *testing.T) {
+	// Simulate a file written by a version of the pipeline in which Entry
+	// fields had different numbers.
+	h := CurrentHeader()
+	for _, f := range h.Descriptors.File {
+		for _, m := range f.MessageType {
+			if m.GetName() != "Entry" {
+				continue
+			}
+			for _, fd := range m.Field {
+				fd.Number = proto.Int32(fd.GetNumber() + 100)
+			}
+		}
+	}
+	files, err := protodesc.NewFiles(h.Descriptors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := files.FindDescriptorByName(entryDescriptor.FullName())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	wr := delimited.NewWriter(buf)
+	hdr, err := h.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Put(hdr); err != nil {
+		t.Fatal(err)
+	}
+	md := d.(protoreflect.MessageDescriptor)
+	for _, e := range testEntries {
+		js, err := protojson.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		old := dynamicpb.NewMessage(md)
+		if err := protojson.Unmarshal(js, old); err != nil {
+			t.Fatal(err)
+		}
+		rec, err := proto.Marshal(old)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cur spb.Entry
+		if err := proto.Unmarshal(rec, &cur); err == nil && proto.Equal(&cur, e) {
+			t.Fatalf("Old encoding of %v is readable without the header", e)
+		}
+		if err := wr.Put(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if diff := compare.ProtoDiff(testEntries, readAll(t, buf)); diff != "" {
+		t.Errorf("Unexpected entries: %s", diff)
+	}
+}
+
+func TestHeaderFutureVersion(t *testing.T) {
+	h := CurrentHeader()
+	h.Version = HeaderVersion + 1
+	rec, err := h.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.NewBuffer(nil)
+	if err := delimited.NewWriter(buf).Put(rec); err != nil {
+		t.Fatal(err)
+	}
+	err = NewReader(buf)(func(*spb.Entry) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "unsupported entry file version") {
+		t.Errorf("Expected version error; got %v", err)
+	}
+}
@@ -0,0 +1,297 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package merge implements a k-way merge of sorted entry streams.
+//
+// Each input stream must be sorted in GraphStore order (see compare.Entries).
+// The merged stream is also sorted, and contains each distinct entry only
+// once.  Entries that share the same source, edge kind, target, and fact name
+// but have different fact values are conflicts: a Policy chooses the entry
+// that is kept (by default, the one from the earliest input stream), and the
+// others are reported.
+package merge // import "kythe.io/kythe/go/storage/stream/merge"
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/util/compare"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// A Conflict describes an entry dropped from the merged stream because an
+// entry with the same key but a different fact value was already emitted.
+type Conflict struct {
+	// Kept is the entry emitted in the merged stream, read from input stream
+	// KeptStream.
+	Kept       *spb.Entry
+	KeptStream int
+
+	// Dropped is the conflicting entry, read from input stream DroppedStream.
+	Dropped       *spb.Entry
+	DroppedStream int
+}
+
+// A ConflictError is returned by a merge with the Error policy.
+type ConflictError struct{ *Conflict }
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflicting entries in inputs %d and %d: %v and %v", e.KeptStream, e.DroppedStream, e.Kept, e.Dropped)
+}
+
+// A Policy chooses which of a set of conflicting entries is kept.
+type Policy string
+
+// Policies for resolving conflicts.
+const (
+	// FirstInput keeps the entry from the earliest input stream.
+	FirstInput Policy = "first_input"
+
+	// LastWrite keeps the entry from the latest input stream, as a GraphStore
+	// would if the inputs were written to it in order.
+	LastWrite Policy = "last_write"
+
+	// PreferLanguage keeps the entry from the earliest input stream produced
+	// by an indexer for the language of the entry's source (see
+	// Options.Languages), or else from the earliest input stream.
+	PreferLanguage Policy = "prefer_language"
+
+	// Error stops the merge at the first conflict with a *ConflictError.
+	Error Policy = "error"
+)
+
+// Policies are the known policies.
+var Policies = []Policy{FirstInput, LastWrite, PreferLanguage, Error}
+
+// ParsePolicy returns the Policy named by s.
+func ParsePolicy(s string) (Policy, error) {
+	for _, p := range Policies {
+		if s == string(p) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown conflict policy %q", s)
+}
+
+// Options control the behavior of Merge.
+type Options struct {
+	// OnConflict, if non-nil, is called for each conflict found.  If it returns
+	// an error, the merge is stopped and the error is returned.
+	OnConflict func(*Conflict) error
+
+	// Policy chooses the entry kept of those in conflict.  If empty,
+	// FirstInput is used.
+	Policy Policy
+
+	// Languages are the languages of the indexers that produced each input
+	// stream, for the PreferLanguage policy.  An input stream with no (or an
+	// empty) language is not preferred for any entry.
+	Languages []string
+}
+
+// member is an entry read from the input stream with the given index.
+type member struct {
+	entry *spb.Entry
+	index int
+}
+
+// choose returns the index in group of the entry to keep.
+func (o *Options) choose(group []member) int {
+	switch o.Policy {
+	case LastWrite:
+		return len(group) - 1
+	case PreferLanguage:
+		lang := group[0].entry.GetSource().GetLanguage()
+		if lang == "" {
+			break
+		}
+		for i, m := range group {
+			if m.index < len(o.Languages) && o.Languages[m.index] == lang {
+				return i
+			}
+		}
+	}
+	return 0
+}
+
+// Stats records counts of the entries processed by a merge.
+type Stats struct {
+	Read       int // total entries read from all inputs
+	Written    int // entries emitted in the merged stream
+	Duplicates int // exact duplicates dropped
+	Conflicts  int // conflicting entries dropped
+}
+
+// Merge returns an EntryReader that merges the sorted entries of each of
+// inputs, removing duplicates.  If stats is non-nil, it is updated as the
+// merged stream is read.
+func Merge(opts *Options, stats *Stats, inputs ...stream.EntryReader) stream.EntryReader {
+	if opts == nil {
+		opts = new(Options)
+	}
+	if stats == nil {
+		stats = new(Stats)
+	}
+	return func(f func(*spb.Entry) error) error {
+		done := make(chan struct{})
+		defer close(done)
+
+		h := new(mergeHeap)
+		for i, rd := range inputs {
+			in := &input{index: i, next: pull(rd, done)}
+			if err := in.advance(); err != nil {
+				return err
+			} else if in.cur != nil {
+				heap.Push(h, in)
+			}
+		}
+
+		var group []member
+		for h.Len() > 0 {
+			// Gather the entries sharing the key of the least entry, which are
+			// adjacent in the heap order.
+			group = group[:0]
+			for h.Len() > 0 && (len(group) == 0 || compare.Entries(group[0].entry, (*h)[0].cur) == compare.EQ) {
+				in := (*h)[0]
+				group = append(group, member{in.cur, in.index})
+				stats.Read++
+				if err := in.advance(); err != nil {
+					return err
+				} else if in.cur == nil {
+					heap.Pop(h)
+				} else {
+					heap.Fix(h, 0)
+				}
+			}
+
+			// Conflicts are reported before the kept entry is emitted, so
+			// that a merge stopped by a conflict emits no entry of the group.
+			k := opts.choose(group)
+			kept := group[k]
+			for i, m := range group {
+				if i == k {
+					continue
+				} else if compare.Bytes(kept.entry.FactValue, m.entry.FactValue) == compare.EQ {
+					stats.Duplicates++
+					continue
+				}
+				stats.Conflicts++
+				c := &Conflict{
+					Kept:          kept.entry,
+					KeptStream:    kept.index,
+					Dropped:       m.entry,
+					DroppedStream: m.index,
+				}
+				if opts.OnConflict != nil {
+					if err := opts.OnConflict(c); err != nil {
+						return err
+					}
+				}
+				if opts.Policy == Error {
+					return &ConflictError{c}
+				}
+			}
+			if err := f(kept.entry); err != nil {
+				return err
+			}
+			stats.Written++
+		}
+		return nil
+	}
+}
+
+// errStopped is used to halt input readers when a merge ends early.
+var errStopped = errors.New("merge stopped")
+
+type result struct {
+	entry *spb.Entry
+	err   error
+}
+
+// pull converts rd into an iterator.  The returned function yields (nil, nil)
+// once rd is exhausted.  Closing done halts the underlying reader.
+func pull(rd stream.EntryReader, done <-chan struct{}) func() (*spb.Entry, error) {
+	ch := make(chan result, 64)
+	go func() {
+		defer close(ch)
+		err := rd(func(e *spb.Entry) error {
+			select {
+			case ch <- result{entry: e}:
+				return nil
+			case <-done:
+				return errStopped
+			}
+		})
+		if err != nil && err != errStopped {
+			select {
+			case ch <- result{err: err}:
+			case <-done:
+			}
+		}
+	}()
+	return func() (*spb.Entry, error) {
+		r, ok := <-ch
+		if !ok {
+			return nil, nil
+		}
+		return r.entry, r.err
+	}
+}
+
+type input struct {
+	index int
+	next  func() (*spb.Entry, error)
+	cur   *spb.Entry
+}
+
+// advance reads the next entry of the input, ensuring that it is sorted.
+func (in *input) advance() error {
+	e, err := in.next()
+	if err != nil {
+		return fmt.Errorf("reading input %d: %v", in.index, err)
+	}
+	if e != nil && in.cur != nil && compare.Entries(in.cur, e) == compare.GT {
+		return fmt.Errorf("input %d is not sorted: %v follows %v", in.index, e, in.cur)
+	}
+	in.cur = e
+	return nil
+}
+
+// mergeHeap is a min-heap of inputs ordered by their current entry and then
+// by input index, so that entries from earlier inputs take precedence.
+type mergeHeap []*input
+
+func (h mergeHeap) Len() int      { return len(h) }
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h mergeHeap) Less(i, j int) bool {
+	if o := compare.Entries(h[i].cur, h[j].cur); o != compare.EQ {
+		return o == compare.LT
+	}
+	return h[i].index < h[j].index
+}
+
+func (h *mergeHeap) Push(v any) { *h = append(*h, v.(*input)) }
+
+func (h *mergeHeap) Pop() any {
+	old := *h
+	n := len(old) - 1
+	out := old[n]
+	*h = old[:n]
+	return out
+}
@@ -0,0 +1,202 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package merge
+
+import (
+	"errors"
+	"testing"
+
+	"kythe.io/kythe/go/storage/stream"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func fact(sig, name, value string) *spb.Entry {
+	return &spb.Entry{
+		Source:    &spb.VName{Signature: sig},
+		FactName:  name,
+		FactValue: []byte(value),
+	}
+}
+
+func reader(es ...*spb.Entry) stream.EntryReader {
+	return func(f func(*spb.Entry) error) error {
+		for _, e := range es {
+			if err := f(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func readAll(rd stream.EntryReader) ([]*spb.Entry, error) {
+	var es []*spb.Entry
+	err := rd(func(e *spb.Entry) error {
+		es = append(es, e)
+		return nil
+	})
+	return es, err
+}
+
+func TestMerge(t *testing.T) {
+	var conflicts []*Conflict
+	var stats Stats
+	rd := Merge(&Options{
+		OnConflict: func(c *Conflict) error {
+			conflicts = append(conflicts, c)
+			return nil
+		},
+	}, &stats,
+		reader(fact("a", "/x", "1"), fact("c", "/x", "1")),
+		reader(fact("a", "/x", "1"), fact("b", "/x", "1"), fact("c", "/x", "2")),
+		reader(),
+		reader(fact("d", "/x", "1")),
+	)
+	got, err := readAll(rd)
+	if err != nil {
+		t.Fatalf("Merge: unexpected error: %v", err)
+	}
+	want := []*spb.Entry{
+		fact("a", "/x", "1"),
+		fact("b", "/x", "1"),
+		fact("c", "/x", "1"),
+		fact("d", "/x", "1"),
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Unexpected merged stream: (- want; + got)\n%s", diff)
+	}
+
+	wantConflicts := []*Conflict{{
+		Kept:          fact("c", "/x", "1"),
+		KeptStream:    0,
+		Dropped:       fact("c", "/x", "2"),
+		DroppedStream: 1,
+	}}
+	if diff := cmp.Diff(wantConflicts, conflicts, protocmp.Transform()); diff != "" {
+		t.Errorf("Unexpected conflicts: (- want; + got)\n%s", diff)
+	}
+
+	wantStats := Stats{Read: 6, Written: 4, Duplicates: 1, Conflicts: 1}
+	if stats != wantStats {
+		t.Errorf("Stats: got %+v, want %+v", stats, wantStats)
+	}
+}
+
+func TestMergeUnsorted(t *testing.T) {
+	rd := Merge(nil, nil, reader(fact("b", "/x", "1"), fact("a", "/x", "1")))
+	if es, err := readAll(rd); err == nil {
+		t.Errorf("Merge: got %v, want error", es)
+	}
+}
+
+func TestMergeStopsEarly(t *testing.T) {
+	stop := errors.New("stop")
+	rd := Merge(nil, nil,
+		reader(fact("a", "/x", "1"), fact("b", "/x", "1")),
+		reader(fact("c", "/x", "1"), fact("d", "/x", "1")),
+	)
+	var n int
+	if err := rd(func(*spb.Entry) error {
+		n++
+		return stop
+	}); err != stop {
+		t.Errorf("Merge: got error %v, want %v", err, stop)
+	}
+	if n != 1 {
+		t.Errorf("Merge: emitted %d entries, want 1", n)
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	goFact := func(value string) *spb.Entry {
+		e := fact("a", "/x", value)
+		e.Source.Language = "go"
+		return e
+	}
+	inputs := func() []stream.EntryReader {
+		return []stream.EntryReader{
+			reader(goFact("1"), fact("b", "/x", "1")),
+			reader(goFact("2"), fact("b", "/x", "2")),
+			reader(goFact("3"), fact("b", "/x", "3")),
+		}
+	}
+	tests := []struct {
+		policy Policy
+		want   []*spb.Entry
+	}{
+		{"", []*spb.Entry{goFact("1"), fact("b", "/x", "1")}},
+		{FirstInput, []*spb.Entry{goFact("1"), fact("b", "/x", "1")}},
+		{LastWrite, []*spb.Entry{goFact("3"), fact("b", "/x", "3")}},
+		// Only a has a language; b falls back to the first input.
+		{PreferLanguage, []*spb.Entry{goFact("2"), fact("b", "/x", "1")}},
+	}
+	for _, test := range tests {
+		var stats Stats
+		var dropped []string
+		got, err := readAll(Merge(&Options{
+			Policy:    test.policy,
+			Languages: []string{"java", "go"},
+			OnConflict: func(c *Conflict) error {
+				dropped = append(dropped, string(c.Dropped.FactValue))
+				return nil
+			},
+		}, &stats, inputs()...))
+		if err != nil {
+			t.Errorf("Merge with %q: unexpected error: %v", test.policy, err)
+			continue
+		}
+		if diff := cmp.Diff(test.want, got, protocmp.Transform()); diff != "" {
+			t.Errorf("Merge with %q: (- want; + got)\n%s", test.policy, diff)
+		}
+		if stats.Conflicts != 4 || len(dropped) != 4 {
+			t.Errorf("Merge with %q: got %d conflicts (%v), want 4", test.policy, stats.Conflicts, dropped)
+		}
+	}
+}
+
+func TestErrorPolicy(t *testing.T) {
+	got, err := readAll(Merge(&Options{Policy: Error}, nil,
+		reader(fact("a", "/x", "1"), fact("b", "/x", "1")),
+		reader(fact("a", "/x", "1"), fact("b", "/x", "2")),
+	))
+	var ce *ConflictError
+	if !errors.As(err, &ce) {
+		t.Fatalf("Merge: got error %v, want a *ConflictError", err)
+	}
+	if string(ce.Dropped.FactValue) != "2" || ce.DroppedStream != 1 {
+		t.Errorf("ConflictError: got %+v, want b=2 from input 1", ce.Conflict)
+	}
+	// Neither of the conflicting entries is emitted.
+	if diff := cmp.Diff([]*spb.Entry{fact("a", "/x", "1")}, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Entries before the conflict (-want +got):\n%s", diff)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	for _, p := range Policies {
+		if got, err := ParsePolicy(string(p)); err != nil || got != p {
+			t.Errorf("ParsePolicy(%q): got (%q, %v)", p, got, err)
+		}
+	}
+	if p, err := ParsePolicy("newest"); err == nil {
+		t.Errorf("ParsePolicy(newest): got %q, want error", p)
+	}
+}
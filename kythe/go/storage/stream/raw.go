@@ -0,0 +1,163 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stream
+
+import (
+	"fmt"
+	"io"
+
+	"kythe.io/kythe/go/platform/delimited"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// A RawEntry is the wire encoding of an Entry, whose fields are decoded only
+// when they are accessed.  Accessors return slices of the encoding itself, so
+// reading a field allocates nothing; the bytes of a RawEntry must not be
+// modified.  If a field occurs more than once in the encoding, the last
+// occurrence is used, and a field that is missing or malformed is empty.
+type RawEntry []byte
+
+// Source returns the source VName of e.
+func (e RawEntry) Source() RawVName { return RawVName(field(e, 1)) }
+
+// EdgeKind returns the edge kind of e.
+func (e RawEntry) EdgeKind() []byte { return field(e, 2) }
+
+// Target returns the target VName of e.
+func (e RawEntry) Target() RawVName { return RawVName(field(e, 3)) }
+
+// FactName returns the fact name of e.
+func (e RawEntry) FactName() []byte { return field(e, 4) }
+
+// FactValue returns the fact value of e.
+func (e RawEntry) FactValue() []byte { return field(e, 5) }
+
+// Proto decodes all of e into a new Entry.
+func (e RawEntry) Proto() (*spb.Entry, error) {
+	var entry spb.Entry
+	if err := proto.Unmarshal(e, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// A RawVName is the wire encoding of a VName, whose fields are decoded only
+// when they are accessed, as for a RawEntry.  An empty RawVName encodes the
+// empty VName, which is also what a missing VName field of an Entry yields.
+type RawVName []byte
+
+// Signature returns the signature of v.
+func (v RawVName) Signature() []byte { return field(v, 1) }
+
+// Corpus returns the corpus of v.
+func (v RawVName) Corpus() []byte { return field(v, 2) }
+
+// Root returns the root of v.
+func (v RawVName) Root() []byte { return field(v, 3) }
+
+// Path returns the path of v.
+func (v RawVName) Path() []byte { return field(v, 4) }
+
+// Language returns the language of v.
+func (v RawVName) Language() []byte { return field(v, 5) }
+
+// Proto decodes all of v into a new VName.
+func (v RawVName) Proto() (*spb.VName, error) {
+	var vname spb.VName
+	if err := proto.Unmarshal(v, &vname); err != nil {
+		return nil, err
+	}
+	return &vname, nil
+}
+
+// field returns the value of the last occurrence of the length-delimited
+// field num in the wire-encoded message b, or nil if there is none.  Parsing
+// stops at the first malformed field.
+func field(b []byte, num protowire.Number) []byte {
+	var val []byte
+	for len(b) > 0 {
+		n, typ, tagLen := protowire.ConsumeTag(b)
+		if tagLen < 0 {
+			return val
+		}
+		b = b[tagLen:]
+		if n == num && typ == protowire.BytesType {
+			v, m := protowire.ConsumeBytes(b)
+			if m < 0 {
+				return val
+			}
+			val, b = v, b[m:]
+			continue
+		}
+		m := protowire.ConsumeFieldValue(n, typ, b)
+		if m < 0 {
+			return val
+		}
+		b = b[m:]
+	}
+	return val
+}
+
+// A RawEntryReader reads a stream of entries, passing the encoding of each to
+// a handler function.  Each RawEntry is valid only until the handler returns;
+// a handler that keeps an entry must copy it.
+type RawEntryReader func(func(RawEntry) error) error
+
+// rawBufferSize is the input buffer size of a RawEntryReader.  Entries no
+// larger than this are not copied from the buffer.
+const rawBufferSize = 64 * 1024
+
+// NewRawReader reads a stream of delimited Entry protobufs from r like
+// NewReader, but without decoding them.  It suits tools that need only a few
+// fields of each entry.  If the stream begins with a Header whose descriptors
+// differ from the current ones, each entry is decoded and re-encoded in the
+// current format, losing the benefit of reading it raw.
+func NewRawReader(r io.Reader) RawEntryReader {
+	return func(f func(RawEntry) error) error {
+		rd := delimited.NewZeroCopyReader(r, rawBufferSize)
+		dec := newRecordDecoder()
+		for {
+			rec, err := rd.Next()
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("error reading Entry: %v", err)
+			}
+			if isHeader, err := dec.header(rec); err != nil {
+				return err
+			} else if isHeader {
+				continue
+			}
+			if !dec.current {
+				var entry spb.Entry
+				if err := dec.decode(rec, &entry); err != nil {
+					return fmt.Errorf("error decoding Entry: %v", err)
+				}
+				if rec, err = proto.Marshal(&entry); err != nil {
+					return fmt.Errorf("error encoding Entry: %v", err)
+				}
+			}
+			if err := f(RawEntry(rec)); err != nil {
+				return err
+			}
+		}
+	}
+}
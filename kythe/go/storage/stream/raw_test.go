@@ -0,0 +1,137 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stream
+
+import (
+	"bytes"
+	"testing"
+
+	"kythe.io/kythe/go/util/compare"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// readAllRaw reads the entries in data with a RawEntryReader, checking the
+// accessors of each against its decoded Entry.
+func readAllRaw(t *testing.T, data []byte) []*spb.Entry {
+	t.Helper()
+	var got []*spb.Entry
+	if err := NewRawReader(bytes.NewReader(data))(func(raw RawEntry) error {
+		e, err := raw.Proto()
+		if err != nil {
+			return err
+		}
+		checkRawVName(t, raw.Source(), e.GetSource())
+		checkRawVName(t, raw.Target(), e.GetTarget())
+		for _, f := range []struct {
+			name      string
+			raw, want string
+		}{
+			{"EdgeKind", string(raw.EdgeKind()), e.GetEdgeKind()},
+			{"FactName", string(raw.FactName()), e.GetFactName()},
+			{"FactValue", string(raw.FactValue()), string(e.GetFactValue())},
+		} {
+			if f.raw != f.want {
+				t.Errorf("%s: got %q, want %q", f.name, f.raw, f.want)
+			}
+		}
+		got = append(got, e)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func checkRawVName(t *testing.T, raw RawVName, want *spb.VName) {
+	t.Helper()
+	got := &spb.VName{
+		Signature: string(raw.Signature()),
+		Corpus:    string(raw.Corpus()),
+		Root:      string(raw.Root()),
+		Path:      string(raw.Path()),
+		Language:  string(raw.Language()),
+	}
+	if diff := compare.ProtoDiff(want, got); want != nil && diff != "" {
+		t.Errorf("Raw VName: %s", diff)
+	} else if want == nil && len(raw) != 0 {
+		t.Errorf("Raw VName: got %q, want empty", raw)
+	}
+}
+
+func TestRawReader(t *testing.T) {
+	entries := append([]*spb.Entry{{
+		Source:    &spb.VName{Signature: "s", Corpus: "c", Root: "r", Path: "p", Language: "l"},
+		EdgeKind:  "/kythe/edge/ref",
+		Target:    &spb.VName{Corpus: "c", Path: "q"},
+		FactName:  "/",
+		FactValue: []byte("value"),
+	}}, testEntries...)
+	if diff := compare.ProtoDiff(entries, readAllRaw(t, testBuffer(entries).Bytes())); diff != "" {
+		t.Errorf("Unexpected entries: %s", diff)
+	}
+}
+
+func TestRawEntryFields(t *testing.T) {
+	// The last occurrence of a field wins, and fields of other types are
+	// skipped.
+	var rec []byte
+	rec = protowire.AppendTag(rec, 4, protowire.BytesType)
+	rec = protowire.AppendString(rec, "first")
+	rec = protowire.AppendTag(rec, 7, protowire.VarintType)
+	rec = protowire.AppendVarint(rec, 42)
+	rec = protowire.AppendTag(rec, 4, protowire.BytesType)
+	rec = protowire.AppendString(rec, "second")
+	if got := string(RawEntry(rec).FactName()); got != "second" {
+		t.Errorf("FactName: got %q, want %q", got, "second")
+	}
+	if got := RawEntry(rec).EdgeKind(); got != nil {
+		t.Errorf("EdgeKind: got %q, want nil", got)
+	}
+
+	// A malformed field ends parsing, keeping what came before it.
+	bad := append(append([]byte(nil), rec...), protowire.AppendTag(nil, 2, protowire.BytesType)...)
+	bad = append(bad, 0x10, 'x')
+	if got := string(RawEntry(bad).FactName()); got != "second" {
+		t.Errorf("FactName of malformed entry: got %q, want %q", got, "second")
+	}
+	if got := RawEntry(bad).EdgeKind(); got != nil {
+		t.Errorf("EdgeKind of malformed entry: got %q, want nil", got)
+	}
+	if _, err := RawEntry(bad).Proto(); err == nil {
+		t.Error("Proto of malformed entry: got nil error")
+	}
+}
+
+func BenchmarkRawReader(b *testing.B) {
+	// Unlike BenchmarkReader, each op reads a fixed stream, since a stream of
+	// b.N entries grows too large at the rate raw entries are read.
+	data := testBuffer(genEntries(1000)).Bytes()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	var n int
+	for i := 0; i < b.N; i++ {
+		if err := NewRawReader(bytes.NewReader(data))(func(e RawEntry) error {
+			n += len(e.FactName())
+			return nil
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package redact rewrites entries to remove identifying information while
+// preserving the structure of the graph.
+//
+// VName fields are rewritten with a keyed hash, so equal inputs always map to
+// equal outputs and every edge still connects the same pair of nodes.  Paths
+// are hashed one component at a time, so files that shared a directory still
+// do.  File text and other facts that may contain source code can be dropped.
+package redact // import "kythe.io/kythe/go/storage/stream/redact"
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"strings"
+
+	"kythe.io/kythe/go/util/schema/facts"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// Options control which parts of each entry are redacted.
+type Options struct {
+	// Salt keys the hash applied to redacted values.  Outputs produced with
+	// different salts cannot be correlated with each other.  If empty, a
+	// random salt is used.
+	Salt string
+
+	// Corpora maps corpus names to their replacements.  Corpora not in the
+	// map are hashed if HashCorpora is set and are otherwise kept.
+	Corpora     map[string]string
+	HashCorpora bool
+
+	// HashRoots, HashPaths, and HashSignatures control whether the respective
+	// VName fields are hashed.  When hashing paths, the extension of the
+	// final component is kept if KeepExtensions is set.
+	HashRoots      bool
+	HashPaths      bool
+	KeepExtensions bool
+	HashSignatures bool
+
+	// DropFacts lists the names of facts to remove entirely.
+	DropFacts []string
+}
+
+// Default returns the default Options: corpora, roots, paths, and signatures
+// are hashed (keeping file extensions), and file text, code, and snippets are
+// dropped.
+func Default() *Options {
+	return &Options{
+		HashCorpora:    true,
+		HashRoots:      true,
+		HashPaths:      true,
+		KeepExtensions: true,
+		HashSignatures: true,
+		DropFacts:      []string{facts.Text, facts.Code, facts.SnippetStart, facts.SnippetEnd},
+	}
+}
+
+// A Redactor rewrites entries according to its Options.
+type Redactor struct {
+	opts Options
+	key  []byte
+	drop map[string]bool
+}
+
+// New returns a Redactor with the given options.  If opts == nil, Default()
+// is used.
+func New(opts *Options) *Redactor {
+	if opts == nil {
+		opts = Default()
+	}
+	r := &Redactor{
+		opts: *opts,
+		key:  []byte(opts.Salt),
+		drop: make(map[string]bool),
+	}
+	if len(r.key) == 0 {
+		r.key = make([]byte, 32)
+		if _, err := rand.Read(r.key); err != nil {
+			panic(err)
+		}
+	}
+	for _, f := range opts.DropFacts {
+		r.drop[f] = true
+	}
+	return r
+}
+
+// Entry returns the redacted form of e, or nil if e should be dropped.  The
+// input entry is not modified.
+func (r *Redactor) Entry(e *spb.Entry) *spb.Entry {
+	if e.GetEdgeKind() == "" && r.drop[e.GetFactName()] {
+		return nil
+	}
+	return &spb.Entry{
+		Source:    r.VName(e.GetSource()),
+		EdgeKind:  e.GetEdgeKind(),
+		Target:    r.VName(e.GetTarget()),
+		FactName:  e.GetFactName(),
+		FactValue: e.GetFactValue(),
+	}
+}
+
+// VName returns the redacted form of v.
+func (r *Redactor) VName(v *spb.VName) *spb.VName {
+	if v == nil {
+		return nil
+	}
+	out := &spb.VName{
+		Signature: v.GetSignature(),
+		Corpus:    v.GetCorpus(),
+		Root:      v.GetRoot(),
+		Path:      v.GetPath(),
+		Language:  v.GetLanguage(),
+	}
+	if c, ok := r.opts.Corpora[out.Corpus]; ok {
+		out.Corpus = c
+	} else if r.opts.HashCorpora {
+		out.Corpus = r.hash("corpus", out.Corpus)
+	}
+	if r.opts.HashRoots {
+		out.Root = r.hash("root", out.Root)
+	}
+	if r.opts.HashPaths {
+		out.Path = r.hashPath(out.Path)
+	}
+	if r.opts.HashSignatures {
+		out.Signature = r.hash("signature", out.Signature)
+	}
+	return out
+}
+
+// hashPath hashes each component of p separately.
+func (r *Redactor) hashPath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		ext := ""
+		if r.opts.KeepExtensions && i == len(parts)-1 {
+			ext = path.Ext(part)
+			part = strings.TrimSuffix(part, ext)
+		}
+		parts[i] = r.hash("path", part) + ext
+	}
+	return strings.Join(parts, "/")
+}
+
+// hash returns a short keyed hash of s, tagged by the kind of value.  Empty
+// and special path values are returned unchanged so that the shape of the
+// VName is preserved.
+func (r *Redactor) hash(kind, s string) string {
+	if s == "" || (kind == "path" && (s == "." || s == "..")) {
+		return s
+	}
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(kind + "\x00" + s))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
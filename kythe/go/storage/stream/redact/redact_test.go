@@ -0,0 +1,120 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package redact
+
+import (
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/util/schema/facts"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestEntry(t *testing.T) {
+	r := New(&Options{
+		Salt:           "salt",
+		Corpora:        map[string]string{"secret": "corpus"},
+		HashCorpora:    true,
+		HashRoots:      true,
+		HashPaths:      true,
+		KeepExtensions: true,
+		DropFacts:      []string{facts.Text},
+	})
+
+	file := &spb.VName{Corpus: "secret", Root: "root", Path: "some/dir/file.go"}
+	anchor := &spb.VName{Corpus: "secret", Root: "root", Path: "some/dir/file.go", Signature: "a", Language: "go"}
+
+	if got := r.Entry(&spb.Entry{Source: file, FactName: facts.Text, FactValue: []byte("package x")}); got != nil {
+		t.Errorf("Text fact was not dropped: %v", got)
+	}
+
+	kind := r.Entry(&spb.Entry{Source: file, FactName: facts.NodeKind, FactValue: []byte("file")})
+	if kind == nil {
+		t.Fatal("Node kind fact was dropped")
+	}
+	v := kind.GetSource()
+	if v.GetCorpus() != "corpus" {
+		t.Errorf("Corpus: got %q, want %q", v.GetCorpus(), "corpus")
+	}
+	if v.GetRoot() == "root" || v.GetRoot() == "" {
+		t.Errorf("Root was not hashed: %q", v.GetRoot())
+	}
+	parts := strings.Split(v.GetPath(), "/")
+	if len(parts) != 3 || !strings.HasSuffix(parts[2], ".go") || strings.Contains(v.GetPath(), "some") {
+		t.Errorf("Path was not hashed by component: %q", v.GetPath())
+	}
+	if string(kind.GetFactValue()) != "file" {
+		t.Errorf("Fact value: got %q, want %q", kind.GetFactValue(), "file")
+	}
+	if file.GetCorpus() != "secret" {
+		t.Error("Input VName was modified")
+	}
+
+	edge := r.Entry(&spb.Entry{Source: anchor, EdgeKind: "/kythe/edge/childof", Target: file, FactName: "/"})
+	if got, want := edge.GetTarget().String(), v.String(); got != want {
+		t.Errorf("Edge target: got %s, want %s", got, want)
+	}
+	if got := edge.GetSource(); got.GetSignature() != "a" || got.GetLanguage() != "go" || got.GetPath() != v.GetPath() {
+		t.Errorf("Edge source: got %v", got)
+	}
+}
+
+func TestSalt(t *testing.T) {
+	v := &spb.VName{Path: "a/b.cc"}
+	a := New(&Options{Salt: "a", HashPaths: true}).VName(v).GetPath()
+	b := New(&Options{Salt: "b", HashPaths: true}).VName(v).GetPath()
+	if a == b {
+		t.Errorf("Different salts produced the same path: %q", a)
+	}
+	if strings.HasSuffix(a, ".cc") {
+		t.Errorf("Extension kept without KeepExtensions: %q", a)
+	}
+}
+
+func TestHashSignatures(t *testing.T) {
+	r := New(&Options{HashSignatures: true})
+	v := r.VName(&spb.VName{Signature: "java.util.List", Corpus: "c", Path: "p"})
+	if v.GetSignature() == "java.util.List" {
+		t.Errorf("Signature was not hashed: %v", v)
+	}
+	if v.GetCorpus() != "c" || v.GetPath() != "p" {
+		t.Errorf("Unexpected rewrite: %v", v)
+	}
+	if r.VName(nil) != nil {
+		t.Error("VName(nil) != nil")
+	}
+}
+
+func TestDefault(t *testing.T) {
+	r := New(nil)
+	v := &spb.VName{Signature: "java.util.List", Corpus: "c", Path: "p"}
+	for _, name := range []string{facts.Text, facts.Code, facts.SnippetStart, facts.SnippetEnd} {
+		if got := r.Entry(&spb.Entry{Source: v, FactName: name}); got != nil {
+			t.Errorf("Fact %q was not dropped: %v", name, got)
+		}
+	}
+	got := r.VName(v)
+	if got.GetSignature() == v.GetSignature() {
+		t.Errorf("Signature was not hashed: %v", got)
+	}
+
+	// Without a salt, each Redactor uses a different random one.
+	if other := New(nil).VName(v); other.GetPath() == got.GetPath() {
+		t.Errorf("Redactors without a salt produced the same path: %q", got.GetPath())
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package sample selects representative subsets of entry streams.
+//
+// Sampling is done by node: either all or none of the entries with a given
+// source VName are selected, so each sampled node keeps its facts and
+// outgoing edges.  Edges may refer to target nodes that were not sampled.
+package sample // import "kythe.io/kythe/go/storage/stream/sample"
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/util/compare"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// Fraction returns a reader that passes along the entries of rd belonging to
+// roughly the given fraction (0-1) of nodes.  The selection is a deterministic
+// function of each node's VName and seed, so the input need not be sorted and
+// the same nodes are selected from different streams.
+func Fraction(rd stream.EntryReader, fraction float64, seed uint64) stream.EntryReader {
+	threshold := uint64(math.MaxUint64)
+	if fraction < 1 {
+		threshold = uint64(fraction * math.MaxUint64)
+	}
+	return func(f func(*spb.Entry) error) error {
+		return rd(func(e *spb.Entry) error {
+			if fraction > 0 && hashVName(e.GetSource(), seed) <= threshold {
+				return f(e)
+			}
+			return nil
+		})
+	}
+}
+
+func hashVName(v *spb.VName, seed uint64) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], seed)
+	h.Write(buf[:])
+	for _, s := range []string{v.GetSignature(), v.GetCorpus(), v.GetRoot(), v.GetPath(), v.GetLanguage()} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	// FNV mixes poorly into the high bits for short inputs; finish with a
+	// multiplicative hash so the threshold comparison is uniform.
+	return h.Sum64() * 0x9E3779B97F4A7C15
+}
+
+// PerKind returns a reader that passes along the entries of up to k nodes of
+// each node kind, chosen uniformly at random using seed.  Nodes without a
+// node kind fact are grouped together.  The input must be grouped by source
+// VName (e.g. sorted in GraphStore order).  Entries are buffered in memory
+// until rd is exhausted and are then emitted in their original order.
+func PerKind(rd stream.EntryReader, k int, seed int64) stream.EntryReader {
+	return func(f func(*spb.Entry) error) error {
+		rng := rand.New(rand.NewSource(seed))
+		type node struct {
+			index   int
+			entries []*spb.Entry
+		}
+		samples := make(map[string][]*node) // reservoirs by node kind
+		seen := make(map[string]int)        // nodes seen by node kind
+
+		var cur *node
+		var kind string
+		add := func() {
+			if cur == nil {
+				return
+			}
+			n := seen[kind]
+			seen[kind]++
+			if n < k {
+				samples[kind] = append(samples[kind], cur)
+			} else if j := rng.Intn(n + 1); j < k {
+				samples[kind][j] = cur
+			}
+		}
+		var index int
+		if err := rd(func(e *spb.Entry) error {
+			if cur == nil || !compare.VNamesEqual(cur.entries[0].GetSource(), e.GetSource()) {
+				add()
+				cur, kind = &node{index: index}, ""
+				index++
+			}
+			if e.GetEdgeKind() == "" && e.GetFactName() == facts.NodeKind {
+				kind = string(e.GetFactValue())
+			}
+			cur.entries = append(cur.entries, e)
+			return nil
+		}); err != nil {
+			return err
+		}
+		add()
+
+		var nodes []*node
+		for _, ns := range samples {
+			nodes = append(nodes, ns...)
+		}
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i].index < nodes[j].index })
+		for _, n := range nodes {
+			for _, e := range n.entries {
+				if err := f(e); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sample
+
+import (
+	"fmt"
+	"testing"
+
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// testNodes returns two entries (a kind and a label) for each of n nodes,
+// whose kinds cycle through kinds.
+func testNodes(n int, kinds ...string) stream.EntryReader {
+	return func(f func(*spb.Entry) error) error {
+		for i := 0; i < n; i++ {
+			v := &spb.VName{Signature: fmt.Sprint(i)}
+			if err := f(&spb.Entry{Source: v, FactName: facts.NodeKind, FactValue: []byte(kinds[i%len(kinds)])}); err != nil {
+				return err
+			} else if err := f(&spb.Entry{Source: v, FactName: "/label", FactValue: []byte(fmt.Sprint(i))}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// collect returns the number of entries read from rd per node signature and
+// per node kind, and the node signatures in order.
+func collect(t *testing.T, rd stream.EntryReader) (perNode, perKind map[string]int, order []string) {
+	t.Helper()
+	perNode, perKind = make(map[string]int), make(map[string]int)
+	if err := rd(func(e *spb.Entry) error {
+		sig := e.GetSource().GetSignature()
+		if perNode[sig] == 0 {
+			order = append(order, sig)
+		}
+		perNode[sig]++
+		if e.GetFactName() == facts.NodeKind {
+			perKind[string(e.GetFactValue())]++
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return perNode, perKind, order
+}
+
+func TestFraction(t *testing.T) {
+	const n = 10000
+	for _, fraction := range []float64{0, 0.1, 0.5, 1} {
+		perNode, _, _ := collect(t, Fraction(testNodes(n, "k"), fraction, 42))
+		for sig, count := range perNode {
+			if count != 2 {
+				t.Errorf("Fraction(%v): node %s has %d entries; want 2", fraction, sig, count)
+			}
+		}
+		if got, want := float64(len(perNode)), fraction*n; got < want-n/50 || got > want+n/50 {
+			t.Errorf("Fraction(%v): sampled %v nodes; want about %v", fraction, got, want)
+		}
+	}
+
+	a, _, _ := collect(t, Fraction(testNodes(100, "k"), 0.5, 1))
+	b, _, _ := collect(t, Fraction(testNodes(100, "k"), 0.5, 1))
+	c, _, _ := collect(t, Fraction(testNodes(100, "k"), 0.5, 2))
+	if fmt.Sprint(a) != fmt.Sprint(b) {
+		t.Error("Fraction is not deterministic for a fixed seed")
+	} else if fmt.Sprint(a) == fmt.Sprint(c) {
+		t.Error("Fraction selected the same nodes with different seeds")
+	}
+}
+
+func TestPerKind(t *testing.T) {
+	perNode, perKind, order := collect(t, PerKind(testNodes(100, "a", "b", "c"), 5, 1))
+	for _, kind := range []string{"a", "b", "c"} {
+		if perKind[kind] != 5 {
+			t.Errorf("Got %d nodes of kind %q; want 5", perKind[kind], kind)
+		}
+	}
+	for sig, count := range perNode {
+		if count != 2 {
+			t.Errorf("Node %s has %d entries; want 2", sig, count)
+		}
+	}
+	var last int
+	for _, sig := range order {
+		var i int
+		fmt.Sscan(sig, &i)
+		if i < last {
+			t.Errorf("Nodes out of order: %v", order)
+			break
+		}
+		last = i
+	}
+
+	_, perKind, _ = collect(t, PerKind(testNodes(4, "a", "b"), 5, 1))
+	if perKind["a"] != 2 || perKind["b"] != 2 {
+		t.Errorf("Got %v; want all nodes when fewer than k", perKind)
+	}
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package split partitions a stream of entries into multiple delimited
+// streams, keyed by a function of each entry.
+package split // import "kythe.io/kythe/go/storage/stream/split"
+
+import (
+	"bufio"
+	"container/list"
+	"fmt"
+	"io"
+	"sort"
+
+	"kythe.io/kythe/go/platform/delimited"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// A KeyFunc returns the partition key for an entry.  Entries with the same key
+// are written to the same output.
+type KeyFunc func(*spb.Entry) (string, error)
+
+// ByCorpus partitions entries by the corpus of their source.
+func ByCorpus(e *spb.Entry) (string, error) { return e.GetSource().GetCorpus(), nil }
+
+// ByRoot partitions entries by the root of their source.
+func ByRoot(e *spb.Entry) (string, error) { return e.GetSource().GetRoot(), nil }
+
+// ByCorpusRoot partitions entries by the corpus and root of their source.  The
+// key is the corpus and root joined by a "/".
+func ByCorpusRoot(e *spb.Entry) (string, error) {
+	return e.GetSource().GetCorpus() + "/" + e.GetSource().GetRoot(), nil
+}
+
+// ByLanguage partitions entries by the language of their source.
+func ByLanguage(e *spb.Entry) (string, error) { return e.GetSource().GetLanguage(), nil }
+
+// DefaultMaxOpen is the default number of outputs a Splitter keeps open at
+// once.
+const DefaultMaxOpen = 256
+
+// An OpenFunc opens the output for a key.  If appending is true, the output
+// was opened and closed before, and the new output must append to it.
+type OpenFunc func(key string, appending bool) (io.WriteCloser, error)
+
+// A Splitter writes each entry it is given to the output for its key.  Outputs
+// are opened lazily, the first time an entry with a new key is seen.  To stay
+// within the limit on open outputs, the least recently written output is
+// closed, and reopened for appending once it is written again.
+type Splitter struct {
+	key     KeyFunc
+	open    OpenFunc
+	maxOpen int
+
+	outs  map[string]*output
+	lru   *list.List // open outputs, most recently written first
+	total int
+}
+
+type output struct {
+	key    string
+	wc     io.WriteCloser // nil if closed
+	buf    *bufio.Writer
+	wr     *delimited.Writer
+	elem   *list.Element // position in the open outputs, if open
+	opened bool          // whether the output has been opened before
+	count  int
+}
+
+// New returns a Splitter that partitions entries using key, and calls open to
+// create the output for each distinct key.  At most maxOpen outputs are kept
+// open at once; if maxOpen <= 0, DefaultMaxOpen is used.
+func New(key KeyFunc, maxOpen int, open OpenFunc) *Splitter {
+	if maxOpen <= 0 {
+		maxOpen = DefaultMaxOpen
+	}
+	return &Splitter{
+		key:     key,
+		open:    open,
+		maxOpen: maxOpen,
+		outs:    make(map[string]*output),
+		lru:     list.New(),
+	}
+}
+
+// Write writes e to the output for its key.
+func (s *Splitter) Write(e *spb.Entry) error {
+	key, err := s.key(e)
+	if err != nil {
+		return fmt.Errorf("computing key: %v", err)
+	}
+	out, ok := s.outs[key]
+	if !ok {
+		out = &output{key: key}
+		s.outs[key] = out
+	}
+	if out.wc != nil {
+		s.lru.MoveToFront(out.elem)
+	} else if err := s.reopen(out); err != nil {
+		return err
+	}
+	if err := out.wr.PutProto(e); err != nil {
+		return fmt.Errorf("writing to output for %q: %v", key, err)
+	}
+	out.count++
+	s.total++
+	return nil
+}
+
+// reopen opens out, first closing the least recently written output if the
+// limit on open outputs has been reached.
+func (s *Splitter) reopen(out *output) error {
+	if s.lru.Len() >= s.maxOpen {
+		if err := s.closeOutput(s.lru.Back().Value.(*output)); err != nil {
+			return err
+		}
+	}
+	wc, err := s.open(out.key, out.opened)
+	if err != nil {
+		return fmt.Errorf("opening output for %q: %v", out.key, err)
+	}
+	out.wc, out.opened = wc, true
+	out.buf = bufio.NewWriter(wc)
+	out.wr = delimited.NewWriter(out.buf)
+	out.elem = s.lru.PushFront(out)
+	return nil
+}
+
+// closeOutput flushes and closes out.
+func (s *Splitter) closeOutput(out *output) error {
+	s.lru.Remove(out.elem)
+	wc, buf := out.wc, out.buf
+	out.wc, out.buf, out.wr, out.elem = nil, nil, nil, nil
+	if err := buf.Flush(); err != nil {
+		wc.Close()
+		return fmt.Errorf("flushing output for %q: %v", out.key, err)
+	}
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("closing output for %q: %v", out.key, err)
+	}
+	return nil
+}
+
+// Counts returns the number of entries written for each key.
+func (s *Splitter) Counts() map[string]int {
+	counts := make(map[string]int, len(s.outs))
+	for key, out := range s.outs {
+		counts[key] = out.count
+	}
+	return counts
+}
+
+// Keys returns the sorted set of keys seen so far.
+func (s *Splitter) Keys() []string {
+	keys := make([]string, 0, len(s.outs))
+	for key := range s.outs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Total returns the total number of entries written.
+func (s *Splitter) Total() int { return s.total }
+
+// Close flushes and closes all open outputs, returning the first error
+// encountered.
+func (s *Splitter) Close() error {
+	var firstErr error
+	for _, key := range s.Keys() {
+		if out := s.outs[key]; out.wc != nil {
+			if err := s.closeOutput(out); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
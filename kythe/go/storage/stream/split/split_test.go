@@ -0,0 +1,172 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package split
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"kythe.io/kythe/go/storage/stream"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+type buffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *buffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func entry(corpus, root, fact string) *spb.Entry {
+	return &spb.Entry{
+		Source:    &spb.VName{Corpus: corpus, Root: root, Signature: "sig"},
+		FactName:  fact,
+		FactValue: []byte("value"),
+	}
+}
+
+func TestSplitter(t *testing.T) {
+	tests := []struct {
+		key  KeyFunc
+		want map[string][]*spb.Entry
+	}{{
+		key: ByCorpus,
+		want: map[string][]*spb.Entry{
+			"a": {entry("a", "x", "/1"), entry("a", "y", "/2")},
+			"b": {entry("b", "x", "/3")},
+		},
+	}, {
+		key: ByCorpusRoot,
+		want: map[string][]*spb.Entry{
+			"a/x": {entry("a", "x", "/1")},
+			"a/y": {entry("a", "y", "/2")},
+			"b/x": {entry("b", "x", "/3")},
+		},
+	}, {
+		key: ByRoot,
+		want: map[string][]*spb.Entry{
+			"x": {entry("a", "x", "/1"), entry("b", "x", "/3")},
+			"y": {entry("a", "y", "/2")},
+		},
+	}}
+
+	for _, test := range tests {
+		outs := make(map[string]*buffer)
+		s := New(test.key, 0, func(key string, _ bool) (io.WriteCloser, error) {
+			buf := new(buffer)
+			outs[key] = buf
+			return buf, nil
+		})
+		for _, e := range []*spb.Entry{entry("a", "x", "/1"), entry("a", "y", "/2"), entry("b", "x", "/3")} {
+			if err := s.Write(e); err != nil {
+				t.Fatalf("Write: unexpected error: %v", err)
+			}
+		}
+		if err := s.Close(); err != nil {
+			t.Fatalf("Close: unexpected error: %v", err)
+		}
+		if s.Total() != 3 {
+			t.Errorf("Total: got %d, want 3", s.Total())
+		}
+
+		got := make(map[string][]*spb.Entry)
+		for key, buf := range outs {
+			if !buf.closed {
+				t.Errorf("Output %q was not closed", key)
+			}
+			if err := stream.NewReader(&buf.Buffer)(func(e *spb.Entry) error {
+				got[key] = append(got[key], e)
+				return nil
+			}); err != nil {
+				t.Fatalf("Reading output %q: %v", key, err)
+			}
+			if n := s.Counts()[key]; n != len(got[key]) {
+				t.Errorf("Counts()[%q]: got %d, want %d", key, n, len(got[key]))
+			}
+		}
+		if diff := cmp.Diff(test.want, got, protocmp.Transform()); diff != "" {
+			t.Errorf("Unexpected outputs: (- want; + got)\n%s", diff)
+		}
+	}
+}
+
+func TestSplitterMaxOpen(t *testing.T) {
+	const maxOpen = 2
+	outs := make(map[string]*bytes.Buffer)
+	var open int
+	s := New(ByCorpus, maxOpen, func(key string, appending bool) (io.WriteCloser, error) {
+		if open++; open > maxOpen {
+			t.Fatalf("Opening %q: %d outputs open, limit %d", key, open, maxOpen)
+		}
+		if _, ok := outs[key]; ok != appending {
+			t.Errorf("Opening %q: appending %v, want %v", key, appending, ok)
+		}
+		if !appending {
+			outs[key] = new(bytes.Buffer)
+		}
+		return closer{outs[key], func() { open-- }}, nil
+	})
+	want := make(map[string][]*spb.Entry)
+	for i := 0; i < 3; i++ {
+		for _, corpus := range []string{"a", "b", "c", "d", "e"} {
+			e := entry(corpus, "", fmt.Sprintf("/%d", i))
+			want[corpus] = append(want[corpus], e)
+			if err := s.Write(e); err != nil {
+				t.Fatalf("Write: unexpected error: %v", err)
+			}
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: unexpected error: %v", err)
+	}
+	if open != 0 {
+		t.Errorf("%d outputs open after Close", open)
+	}
+
+	got := make(map[string][]*spb.Entry)
+	for key, buf := range outs {
+		if err := stream.NewReader(buf)(func(e *spb.Entry) error {
+			got[key] = append(got[key], e)
+			return nil
+		}); err != nil {
+			t.Fatalf("Reading output %q: %v", key, err)
+		}
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Unexpected outputs: (- want; + got)\n%s", diff)
+	}
+}
+
+// closer is an io.WriteCloser that calls close when it is closed.
+type closer struct {
+	io.Writer
+	close func()
+}
+
+func (c closer) Close() error {
+	c.close()
+	return nil
+}
@@ -19,6 +19,7 @@ package stream // import "kythe.io/kythe/go/storage/stream"
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 
@@ -52,24 +53,109 @@ func ReadEntries(r io.Reader) <-chan *spb.Entry {
 	return ch
 }
 
-// NewReader reads a stream of Entry protobufs from r.
+// NewReader reads a stream of Entry protobufs from r.  If the stream begins
+// with a Header, it is used to decode the entries that follow.
 func NewReader(r io.Reader) EntryReader {
 	return func(f func(*spb.Entry) error) error {
-		rd := delimited.NewReader(r)
-		for {
-			var entry spb.Entry
-			if err := rd.NextProto(&entry); err == io.EOF {
-				return nil
-			} else if err != nil {
-				return fmt.Errorf("error decoding Entry: %v", err)
-			}
-			if err := f((*spb.Entry)(&entry)); err != nil {
-				return err
-			}
+		return readEntries(delimited.NewReader(r), newRecordDecoder(), f)
+	}
+}
+
+// NewRecoveringReader reads a stream of Entry protobufs from r like
+// NewReader, but skips over corrupt regions of the input rather than failing.
+// Records that do not decode to an Entry with a source and fact name are
+// treated as corrupt.  Each range of skipped input is passed to onSkip, if
+// non-nil.
+func NewRecoveringReader(r io.Reader, onSkip func(delimited.Skip)) EntryReader {
+	return func(f func(*spb.Entry) error) error {
+		dec := newRecordDecoder()
+		rd := delimited.NewRecoveringReader(r, &delimited.RecoveryOptions{
+			Valid:  dec.validate,
+			OnSkip: onSkip,
+		})
+		return readEntries(rd, dec, f)
+	}
+}
+
+func readEntries(rd *delimited.Reader, dec *recordDecoder, f func(*spb.Entry) error) error {
+	for {
+		rec, err := rd.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("error reading Entry: %v", err)
+		}
+		entry, err := dec.next(rec)
+		if err != nil {
+			return err
+		} else if entry == nil {
+			continue
+		}
+		if err := f(entry); err != nil {
+			return err
 		}
 	}
 }
 
+// A recordDecoder decodes delimited records into entries, handling an
+// optional leading Header.
+type recordDecoder struct {
+	decode  func([]byte, *spb.Entry) error
+	current bool // whether records are encoded with the current Entry descriptor
+	first   bool
+}
+
+func newRecordDecoder() *recordDecoder {
+	return &recordDecoder{
+		decode:  func(rec []byte, e *spb.Entry) error { return proto.Unmarshal(rec, e) },
+		current: true,
+		first:   true,
+	}
+}
+
+// header reports whether rec is the leading header of the stream, and if so
+// configures d to decode the records that follow it.
+func (d *recordDecoder) header(rec []byte) (bool, error) {
+	first := d.first
+	d.first = false
+	if !first || !IsHeader(rec) {
+		return false, nil
+	}
+	h, err := ParseHeader(rec)
+	if err != nil {
+		return true, err
+	}
+	d.decode, d.current, err = h.entryDecoder()
+	return true, err
+}
+
+// next decodes rec, returning nil if it was a header.
+func (d *recordDecoder) next(rec []byte) (*spb.Entry, error) {
+	if isHeader, err := d.header(rec); isHeader || err != nil {
+		return nil, err
+	}
+	var entry spb.Entry
+	if err := d.decode(rec, &entry); err != nil {
+		return nil, fmt.Errorf("error decoding Entry: %v", err)
+	}
+	return &entry, nil
+}
+
+// validate reports whether rec is a plausible entry or header record.
+func (d *recordDecoder) validate(rec []byte) error {
+	if IsHeader(rec) {
+		_, err := ParseHeader(rec)
+		return err
+	}
+	var entry spb.Entry
+	if err := d.decode(rec, &entry); err != nil {
+		return err
+	} else if entry.Source == nil || entry.FactName == "" {
+		return errors.New("entry missing source or fact name")
+	}
+	return nil
+}
+
 // ReadJSONEntries reads a JSON stream of Entry protobufs from r.
 func ReadJSONEntries(r io.Reader) <-chan *spb.Entry {
 	ch := make(chan *spb.Entry)
@@ -51,6 +51,29 @@ func TestReader(t *testing.T) {
 	}
 }
 
+func TestRecoveringReader(t *testing.T) {
+	good := testBuffer(testEntries[:2]).Bytes()
+	garbage := []byte("\xff\xff\xff\x0f\x02\x00\x00")
+	input := append(append(append([]byte(nil), good...), garbage...), testBuffer(testEntries[2:]).Bytes()...)
+
+	var skips []delimited.Skip
+	var got []*spb.Entry
+	if err := NewRecoveringReader(bytes.NewReader(input), func(s delimited.Skip) {
+		skips = append(skips, s)
+	})(func(e *spb.Entry) error {
+		got = append(got, e)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := compare.ProtoDiff(testEntries, got); diff != "" {
+		t.Errorf("Unexpected entries: %s", diff)
+	}
+	if len(skips) != 1 || skips[0].Offset != int64(len(good)) || skips[0].Length != int64(len(garbage)) {
+		t.Errorf("Skips: got %v, want %d bytes at offset %d", skips, len(garbage), len(good))
+	}
+}
+
 func TestJSONReader(t *testing.T) {
 	r := testJSONBuffer(testEntries)
 
@@ -0,0 +1,209 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package topic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Content types of the v2 API of the Confluent REST Proxy.
+const (
+	kafkaV2Type     = "application/vnd.kafka.v2+json"
+	kafkaBinaryType = "application/vnd.kafka.binary.v2+json"
+)
+
+// Limits of a produce request, and of each fetch of records.
+const (
+	kafkaMaxRecords     = 100
+	kafkaMaxBytes       = 4 << 20
+	kafkaFetchTimeoutMS = 1000
+)
+
+// kafkaProxy returns the base URL of the REST Proxy of a kafka:// URL.
+func kafkaProxy(u *url.URL) string {
+	scheme := "http"
+	if u.Scheme == "kafka+https" {
+		scheme = "https"
+	}
+	dir := u.Path[:strings.LastIndex(u.Path, "/")]
+	return scheme + "://" + u.Host + dir
+}
+
+// kafkaCall sends a request with a JSON body (if req != nil) to the REST
+// Proxy and decodes its JSON reply into res (if res != nil).
+func kafkaCall(ctx context.Context, method, url, contentType string, req, res any) error {
+	var body io.Reader
+	if req != nil {
+		data, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	hreq, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	if req != nil {
+		hreq.Header.Set("Content-Type", contentType)
+	}
+	hreq.Header.Set("Accept", contentType)
+	hres, err := http.DefaultClient.Do(hreq)
+	if err != nil {
+		return err
+	}
+	defer hres.Body.Close()
+	if hres.StatusCode < 200 || hres.StatusCode > 299 {
+		var e struct {
+			Message string `json:"message"`
+		}
+		if json.NewDecoder(hres.Body).Decode(&e) != nil || e.Message == "" {
+			e.Message = hres.Status
+		}
+		return fmt.Errorf("%s %s: %s", method, url, e.Message)
+	}
+	if res == nil {
+		return nil
+	}
+	if err := json.NewDecoder(hres.Body).Decode(res); err != nil {
+		return fmt.Errorf("decoding reply to %s %s: %v", method, url, err)
+	}
+	return nil
+}
+
+type kafkaRecord struct {
+	Value []byte `json:"value"`
+}
+
+type kafkaPublisher struct{ url string }
+
+func newKafkaPublisher(proxy, topic string) *kafkaPublisher {
+	return &kafkaPublisher{url: proxy + "/topics/" + url.PathEscape(topic)}
+}
+
+// Publish implements part of the Publisher interface.
+func (p *kafkaPublisher) Publish(ctx context.Context, msgs [][]byte) error {
+	for _, batch := range splitBatches(msgs, kafkaMaxRecords, kafkaMaxBytes) {
+		var req struct {
+			Records []kafkaRecord `json:"records"`
+		}
+		for _, msg := range batch {
+			req.Records = append(req.Records, kafkaRecord{Value: msg})
+		}
+		var res struct {
+			Offsets []struct {
+				Error *string `json:"error"`
+			} `json:"offsets"`
+		}
+		if err := kafkaCall(ctx, http.MethodPost, p.url, kafkaBinaryType, &req, &res); err != nil {
+			return err
+		}
+		for _, o := range res.Offsets {
+			if o.Error != nil {
+				return fmt.Errorf("publishing to %s: %s", p.url, *o.Error)
+			}
+		}
+	}
+	return nil
+}
+
+// Close implements part of the Publisher interface.
+func (*kafkaPublisher) Close(context.Context) error { return nil }
+
+// A kafkaSubscriber is a consumer instance of the REST Proxy, committing its
+// offsets explicitly as messages are acknowledged.
+type kafkaSubscriber struct {
+	topic    string
+	instance string // base URL of the consumer instance
+}
+
+func newKafkaSubscriber(ctx context.Context, proxy, topic, group string) (*kafkaSubscriber, error) {
+	req := map[string]string{
+		"format":             "binary",
+		"auto.offset.reset":  "earliest",
+		"auto.commit.enable": "false",
+	}
+	var res struct {
+		BaseURI string `json:"base_uri"`
+	}
+	if err := kafkaCall(ctx, http.MethodPost, proxy+"/consumers/"+url.PathEscape(group), kafkaV2Type, req, &res); err != nil {
+		return nil, fmt.Errorf("creating consumer: %v", err)
+	}
+	s := &kafkaSubscriber{topic: topic, instance: res.BaseURI}
+	sub := map[string][]string{"topics": {topic}}
+	if err := kafkaCall(ctx, http.MethodPost, s.instance+"/subscription", kafkaV2Type, sub, nil); err != nil {
+		s.Close(ctx)
+		return nil, fmt.Errorf("subscribing to %s: %v", topic, err)
+	}
+	return s, nil
+}
+
+// Receive implements part of the Subscriber interface.
+func (s *kafkaSubscriber) Receive(ctx context.Context) ([]*Message, error) {
+	var res []struct {
+		Value     []byte `json:"value"`
+		Partition int    `json:"partition"`
+		Offset    int64  `json:"offset"`
+	}
+	if err := kafkaCall(ctx, http.MethodGet, fmt.Sprintf("%s/records?timeout=%d", s.instance, kafkaFetchTimeoutMS), kafkaBinaryType, nil, &res); err != nil {
+		return nil, err
+	}
+	msgs := make([]*Message, len(res))
+	for i, r := range res {
+		msgs[i] = &Message{Data: r.Value, partition: r.Partition, offset: r.Offset}
+	}
+	return msgs, nil
+}
+
+// Ack implements part of the Subscriber interface.  It commits the greatest
+// offset of the messages in each partition, which the proxy records as the
+// offset after it.
+func (s *kafkaSubscriber) Ack(ctx context.Context, msgs []*Message) error {
+	type offset struct {
+		Topic     string `json:"topic"`
+		Partition int    `json:"partition"`
+		Offset    int64  `json:"offset"`
+	}
+	latest := make(map[int]int)
+	var req struct {
+		Offsets []offset `json:"offsets"`
+	}
+	for _, msg := range msgs {
+		if i, ok := latest[msg.partition]; ok {
+			if msg.offset > req.Offsets[i].Offset {
+				req.Offsets[i].Offset = msg.offset
+			}
+			continue
+		}
+		latest[msg.partition] = len(req.Offsets)
+		req.Offsets = append(req.Offsets, offset{s.topic, msg.partition, msg.offset})
+	}
+	return kafkaCall(ctx, http.MethodPost, s.instance+"/offsets", kafkaV2Type, &req, nil)
+}
+
+// Close implements part of the Subscriber interface.  It deletes the
+// consumer instance, so that its partitions are reassigned promptly.
+func (s *kafkaSubscriber) Close(ctx context.Context) error {
+	return kafkaCall(ctx, http.MethodDelete, s.instance, kafkaV2Type, nil, nil)
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package topic
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"google.golang.org/api/option"
+
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+// Limits of a Cloud Pub/Sub publish request, with room left for the base64
+// encoding of the messages.
+const (
+	pubsubMaxMessages = 1000
+	pubsubMaxBytes    = 7 << 20
+)
+
+// pubsubMaxPull is the maximum number of messages pulled at once.
+const pubsubMaxPull = 100
+
+func newPubSubService(ctx context.Context) (*pubsub.Service, error) {
+	if host := os.Getenv("PUBSUB_EMULATOR_HOST"); host != "" {
+		return pubsub.NewService(ctx, option.WithEndpoint("http://"+host+"/"), option.WithoutAuthentication())
+	}
+	return pubsub.NewService(ctx, option.WithScopes(pubsub.PubsubScope))
+}
+
+type pubsubPublisher struct {
+	topics *pubsub.ProjectsTopicsService
+	topic  string
+}
+
+func newPubSubPublisher(ctx context.Context, project, name string) (*pubsubPublisher, error) {
+	s, err := newPubSubService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating Cloud Pub/Sub client: %v", err)
+	}
+	return &pubsubPublisher{
+		topics: s.Projects.Topics,
+		topic:  fmt.Sprintf("projects/%s/topics/%s", project, name),
+	}, nil
+}
+
+// Publish implements part of the Publisher interface.
+func (p *pubsubPublisher) Publish(ctx context.Context, msgs [][]byte) error {
+	for _, batch := range splitBatches(msgs, pubsubMaxMessages, pubsubMaxBytes) {
+		req := &pubsub.PublishRequest{Messages: make([]*pubsub.PubsubMessage, len(batch))}
+		for i, msg := range batch {
+			req.Messages[i] = &pubsub.PubsubMessage{Data: base64.StdEncoding.EncodeToString(msg)}
+		}
+		if _, err := p.topics.Publish(p.topic, req).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("publishing to %s: %v", p.topic, err)
+		}
+	}
+	return nil
+}
+
+// Close implements part of the Publisher interface.
+func (*pubsubPublisher) Close(context.Context) error { return nil }
+
+type pubsubSubscriber struct {
+	subs *pubsub.ProjectsSubscriptionsService
+	sub  string
+}
+
+func newPubSubSubscriber(ctx context.Context, project, name string) (*pubsubSubscriber, error) {
+	s, err := newPubSubService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating Cloud Pub/Sub client: %v", err)
+	}
+	return &pubsubSubscriber{
+		subs: s.Projects.Subscriptions,
+		sub:  fmt.Sprintf("projects/%s/subscriptions/%s", project, name),
+	}, nil
+}
+
+// Receive implements part of the Subscriber interface.
+func (s *pubsubSubscriber) Receive(ctx context.Context) ([]*Message, error) {
+	res, err := s.subs.Pull(s.sub, &pubsub.PullRequest{MaxMessages: pubsubMaxPull}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("pulling from %s: %v", s.sub, err)
+	}
+	msgs := make([]*Message, len(res.ReceivedMessages))
+	for i, m := range res.ReceivedMessages {
+		data, err := base64.StdEncoding.DecodeString(m.Message.Data)
+		if err != nil {
+			return nil, fmt.Errorf("decoding message %s: %v", m.Message.MessageId, err)
+		}
+		msgs[i] = &Message{Data: data, ackID: m.AckId}
+	}
+	return msgs, nil
+}
+
+// Ack implements part of the Subscriber interface.
+func (s *pubsubSubscriber) Ack(ctx context.Context, msgs []*Message) error {
+	req := &pubsub.AcknowledgeRequest{AckIds: make([]string, len(msgs))}
+	for i, msg := range msgs {
+		req.AckIds[i] = msg.ackID
+	}
+	_, err := s.subs.Acknowledge(s.sub, req).Context(ctx).Do()
+	return err
+}
+
+// Close implements part of the Subscriber interface.
+func (*pubsubSubscriber) Close(context.Context) error { return nil }
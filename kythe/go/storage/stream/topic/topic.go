@@ -0,0 +1,372 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package topic carries entry streams through message topics, so that an
+// index can be updated continuously as indexers produce entries rather than
+// by loading complete streams in batch.
+//
+// A Sink publishes the entries given to it in messages, each holding a short
+// delimited entry stream.  Consume receives the messages of a subscription and
+// writes their entries to a GraphStore, acknowledging each message once its
+// entries are written.  Entries are thus written at least once; since writing
+// an entry again leaves a GraphStore unchanged, redelivery is harmless.
+//
+// Topics and subscriptions are named by URL:
+//
+//	pubsub://project/topic           a Cloud Pub/Sub topic
+//	pubsub://project/subscription    a Cloud Pub/Sub subscription
+//	kafka://host:port/topic          a Kafka topic, through the Confluent
+//	                                 REST Proxy at host:port (kafka+https
+//	                                 for a proxy served over HTTPS)
+//
+// Cloud Pub/Sub is accessed with Application Default Credentials, or through
+// the emulator at $PUBSUB_EMULATOR_HOST when that is set.  A Kafka topic is
+// consumed in the consumer group given by the "group" query parameter of its
+// URL (by default "kythe"), as in kafka://localhost:8082/entries?group=serving.
+package topic // import "kythe.io/kythe/go/storage/stream/topic"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"kythe.io/kythe/go/platform/delimited"
+	"kythe.io/kythe/go/services/graphstore"
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/util/compare"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// A Publisher publishes messages to a topic.
+type Publisher interface {
+	// Publish publishes each of the given messages, returning once all of
+	// them have been accepted by the topic.
+	Publish(ctx context.Context, msgs [][]byte) error
+
+	// Close releases the resources of the Publisher.
+	Close(ctx context.Context) error
+}
+
+// A Subscriber receives messages from a topic.
+type Subscriber interface {
+	// Receive returns the next messages available, or none if no messages
+	// arrived while it waited.
+	Receive(ctx context.Context) ([]*Message, error)
+
+	// Ack acknowledges the given messages, which were returned by Receive,
+	// so that they are not delivered again.
+	Ack(ctx context.Context, msgs []*Message) error
+
+	// Close releases the resources of the Subscriber.
+	Close(ctx context.Context) error
+}
+
+// A Message is a message received by a Subscriber.
+type Message struct {
+	Data []byte
+
+	ackID     string // Cloud Pub/Sub
+	partition int    // Kafka
+	offset    int64  // Kafka
+}
+
+// OpenPublisher returns a Publisher for the topic named by rawURL.
+func OpenPublisher(ctx context.Context, rawURL string) (Publisher, error) {
+	u, name, err := parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "pubsub":
+		return newPubSubPublisher(ctx, u.Host, name)
+	default:
+		return newKafkaPublisher(kafkaProxy(u), name), nil
+	}
+}
+
+// OpenSubscriber returns a Subscriber for the subscription named by rawURL.
+func OpenSubscriber(ctx context.Context, rawURL string) (Subscriber, error) {
+	u, name, err := parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "pubsub":
+		return newPubSubSubscriber(ctx, u.Host, name)
+	default:
+		group := u.Query().Get("group")
+		if group == "" {
+			group = "kythe"
+		}
+		return newKafkaSubscriber(ctx, kafkaProxy(u), name, group)
+	}
+}
+
+// parseURL parses rawURL, returning it with the name of its topic or
+// subscription.
+func parseURL(rawURL string) (*url.URL, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+	switch u.Scheme {
+	case "pubsub", "kafka", "kafka+https":
+	default:
+		return nil, "", fmt.Errorf("unsupported topic URL %q (want pubsub:// or kafka://)", rawURL)
+	}
+	i := strings.LastIndex(u.Path, "/")
+	if u.Host == "" || i < 0 || u.Path[i+1:] == "" || u.Scheme == "pubsub" && i > 0 {
+		return nil, "", fmt.Errorf("invalid topic URL %q", rawURL)
+	}
+	return u, u.Path[i+1:], nil
+}
+
+// splitBatches splits msgs into batches of at most maxCount messages and, if
+// possible, maxBytes bytes.
+func splitBatches(msgs [][]byte, maxCount, maxBytes int) [][][]byte {
+	var batches [][][]byte
+	start, size := 0, 0
+	for i, msg := range msgs {
+		if i > start && (i-start >= maxCount || size+len(msg) > maxBytes) {
+			batches = append(batches, msgs[start:i])
+			start, size = i, 0
+		}
+		size += len(msg)
+	}
+	if start < len(msgs) {
+		batches = append(batches, msgs[start:])
+	}
+	return batches
+}
+
+// SinkOptions control how a Sink batches entries into messages.
+type SinkOptions struct {
+	// MaxMessageSize is the size in bytes above which a message is complete.
+	// If zero, DefaultMaxMessageSize is used.
+	MaxMessageSize int
+
+	// MaxPendingMessages is the number of complete messages held before they
+	// are published together.  If zero, DefaultMaxPendingMessages is used.
+	MaxPendingMessages int
+}
+
+// Defaults for SinkOptions.  The message size is kept well below the default
+// 1MiB limit of Kafka brokers.
+const (
+	DefaultMaxMessageSize     = 256 << 10
+	DefaultMaxPendingMessages = 16
+)
+
+// A Sink publishes entries to a topic.  The entries are published in the
+// order given, but may be delivered to subscribers in a different order.
+type Sink struct {
+	// Counts of the entries and messages published.
+	NumEntries, NumMessages int
+
+	pub     Publisher
+	opts    SinkOptions
+	buf     bytes.Buffer
+	w       *delimited.Writer
+	entries int
+	pending [][]byte
+}
+
+// NewSink returns a Sink that publishes entries with pub.
+func NewSink(pub Publisher, opts *SinkOptions) *Sink {
+	s := &Sink{pub: pub}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.MaxMessageSize <= 0 {
+		s.opts.MaxMessageSize = DefaultMaxMessageSize
+	}
+	if s.opts.MaxPendingMessages <= 0 {
+		s.opts.MaxPendingMessages = DefaultMaxPendingMessages
+	}
+	s.w = delimited.NewWriter(&s.buf)
+	return s
+}
+
+// Put adds e to the current message of the Sink, publishing the pending
+// messages once enough are complete.
+func (s *Sink) Put(ctx context.Context, e *spb.Entry) error {
+	if err := s.w.PutProto(e); err != nil {
+		return err
+	}
+	s.entries++
+	if s.buf.Len() < s.opts.MaxMessageSize {
+		return nil
+	}
+	s.endMessage()
+	if len(s.pending) < s.opts.MaxPendingMessages {
+		return nil
+	}
+	return s.publish(ctx)
+}
+
+// Flush publishes the entries added to the Sink that have not yet been
+// published.
+func (s *Sink) Flush(ctx context.Context) error {
+	if s.buf.Len() > 0 {
+		s.endMessage()
+	}
+	if len(s.pending) == 0 {
+		return nil
+	}
+	return s.publish(ctx)
+}
+
+func (s *Sink) endMessage() {
+	s.pending = append(s.pending, bytes.Clone(s.buf.Bytes()))
+	s.buf.Reset()
+}
+
+func (s *Sink) publish(ctx context.Context) error {
+	if err := s.pub.Publish(ctx, s.pending); err != nil {
+		return err
+	}
+	s.NumEntries += s.entries
+	s.NumMessages += len(s.pending)
+	s.entries, s.pending = 0, nil
+	return nil
+}
+
+// ConsumeOptions control how Consume writes to a GraphStore.
+type ConsumeOptions struct {
+	// BatchSize is the maximum number of updates in each write to the
+	// GraphStore.  If zero, DefaultBatchSize is used.
+	BatchSize int
+
+	// PollInterval is the time to wait after receiving no messages before
+	// trying again.  If zero, DefaultPollInterval is used.
+	PollInterval time.Duration
+
+	// IdleTimeout, if positive, ends consumption once no messages have been
+	// received for this long.  Otherwise, messages are consumed until the
+	// context is canceled.
+	IdleTimeout time.Duration
+}
+
+// Defaults for ConsumeOptions.
+const (
+	DefaultBatchSize    = 1024
+	DefaultPollInterval = time.Second
+)
+
+// Stats are counts of the data consumed by Consume.
+type Stats struct {
+	Messages, Entries int
+}
+
+// Consume writes the entries of the messages received by sub to gs until the
+// context is canceled or the subscription has been idle for the configured
+// timeout, both of which end consumption without error.  A message that does
+// not hold a valid entry stream is an error, and is left unacknowledged.
+func Consume(ctx context.Context, sub Subscriber, gs graphstore.Service, opts *ConsumeOptions) (Stats, error) {
+	var o ConsumeOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultBatchSize
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = DefaultPollInterval
+	}
+
+	var stats Stats
+	last := time.Now()
+	for {
+		msgs, err := sub.Receive(ctx)
+		if ctx.Err() != nil {
+			return stats, nil
+		} else if err != nil {
+			return stats, err
+		}
+
+		if len(msgs) == 0 {
+			if o.IdleTimeout > 0 && time.Since(last) >= o.IdleTimeout {
+				return stats, nil
+			}
+			select {
+			case <-ctx.Done():
+				return stats, nil
+			case <-time.After(o.PollInterval):
+			}
+			continue
+		}
+		last = time.Now()
+
+		for _, msg := range msgs {
+			n, err := writeMessage(ctx, gs, msg.Data, o.BatchSize)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return stats, nil
+				}
+				return stats, err
+			}
+			stats.Entries += n
+		}
+		if err := sub.Ack(ctx, msgs); err != nil {
+			return stats, fmt.Errorf("acknowledging messages: %v", err)
+		}
+		stats.Messages += len(msgs)
+	}
+}
+
+// writeMessage writes the entries of the delimited stream data to gs, in
+// batches of consecutive entries with the same source, returning the number
+// of entries written.
+func writeMessage(ctx context.Context, gs graphstore.Service, data []byte, batchSize int) (int, error) {
+	var (
+		req *spb.WriteRequest
+		n   int
+	)
+	flush := func() error {
+		if req == nil {
+			return nil
+		}
+		err := gs.Write(ctx, req)
+		n += len(req.Update)
+		req = nil
+		return err
+	}
+	if err := stream.NewReader(bytes.NewReader(data))(func(e *spb.Entry) error {
+		if req != nil && (!compare.VNamesEqual(req.Source, e.Source) || len(req.Update) >= batchSize) {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if req == nil {
+			req = &spb.WriteRequest{Source: e.Source}
+		}
+		req.Update = append(req.Update, &spb.WriteRequest_Update{
+			EdgeKind:  e.EdgeKind,
+			Target:    e.Target,
+			FactName:  e.FactName,
+			FactValue: e.FactValue,
+		})
+		return nil
+	}); err != nil {
+		return n, err
+	}
+	return n, flush()
+}
@@ -0,0 +1,307 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package topic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"kythe.io/kythe/go/storage/inmemory"
+	"kythe.io/kythe/go/util/compare"
+
+	"github.com/google/go-cmp/cmp"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		url, name string
+		ok        bool
+	}{
+		{"pubsub://project/entries", "entries", true},
+		{"kafka://localhost:8082/entries?group=g", "entries", true},
+		{"kafka+https://proxy/kafka/entries", "entries", true},
+		{"pubsub://project/a/b", "", false},
+		{"pubsub:///entries", "", false},
+		{"kafka://localhost:8082/", "", false},
+		{"nats://localhost/entries", "", false},
+	}
+	for _, test := range tests {
+		_, name, err := parseURL(test.url)
+		if (err == nil) != test.ok || name != test.name {
+			t.Errorf("parseURL(%q): got (%q, %v); want (%q, ok=%v)", test.url, name, err, test.name, test.ok)
+		}
+	}
+}
+
+func TestSplitBatches(t *testing.T) {
+	msgs := [][]byte{[]byte("aaa"), []byte("bb"), []byte("c"), []byte("dddddd"), []byte("e")}
+	var got []int
+	for _, b := range splitBatches(msgs, 2, 5) {
+		got = append(got, len(b))
+	}
+	if want := []int{2, 1, 1, 1}; !cmp.Equal(got, want) {
+		t.Errorf("splitBatches: got batch sizes %v; want %v", got, want)
+	}
+}
+
+func testEntries() []*spb.Entry {
+	var entries []*spb.Entry
+	for i := 0; i < 50; i++ {
+		src := &spb.VName{Corpus: "corpus", Signature: fmt.Sprintf("node%02d", i/3)}
+		entries = append(entries, &spb.Entry{
+			Source:    src,
+			FactName:  fmt.Sprintf("/kythe/fact%d", i%3),
+			FactValue: []byte(strings.Repeat("x", i)),
+		})
+	}
+	return entries
+}
+
+// fakePubSub serves the publish, pull and acknowledge methods of the Cloud
+// Pub/Sub API for a topic with a single subscription.
+type fakePubSub struct {
+	mu      sync.Mutex
+	queue   []map[string]string // messages not yet pulled
+	pending map[string]bool     // ack IDs pulled but not acknowledged
+	nextID  int
+}
+
+func (f *fakePubSub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var res any
+	switch r.URL.Path {
+	case "/v1/projects/p/topics/t:publish":
+		var req struct{ Messages []map[string]string }
+		json.NewDecoder(r.Body).Decode(&req)
+		var ids []string
+		for _, msg := range req.Messages {
+			f.nextID++
+			msg["messageId"] = fmt.Sprint(f.nextID)
+			f.queue = append(f.queue, msg)
+			ids = append(ids, msg["messageId"])
+		}
+		res = map[string]any{"messageIds": ids}
+	case "/v1/projects/p/subscriptions/s:pull":
+		var req struct{ MaxMessages int }
+		json.NewDecoder(r.Body).Decode(&req)
+		var msgs []any
+		for len(f.queue) > 0 && len(msgs) < req.MaxMessages {
+			msg := f.queue[0]
+			f.queue = f.queue[1:]
+			ackID := "ack" + msg["messageId"]
+			f.pending[ackID] = true
+			msgs = append(msgs, map[string]any{"ackId": ackID, "message": msg})
+		}
+		res = map[string]any{"receivedMessages": msgs}
+	case "/v1/projects/p/subscriptions/s:acknowledge":
+		var req struct{ AckIDs []string }
+		json.NewDecoder(r.Body).Decode(&req)
+		for _, id := range req.AckIDs {
+			delete(f.pending, id)
+		}
+		res = map[string]any{}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+// fakeKafkaProxy serves the v2 API of the REST Proxy for a single-partition
+// topic with one consumer instance.
+type fakeKafkaProxy struct {
+	mu        sync.Mutex
+	base      string
+	records   [][]byte
+	fetched   int
+	committed int64
+	closed    bool
+}
+
+func (f *fakeKafkaProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	const instance = "/kafka/consumers/g/instances/i"
+	var res any
+	switch r.Method + " " + r.URL.Path {
+	case "POST /kafka/topics/t":
+		var req struct{ Records []kafkaRecord }
+		json.NewDecoder(r.Body).Decode(&req)
+		var offsets []any
+		for _, rec := range req.Records {
+			offsets = append(offsets, map[string]any{"partition": 0, "offset": len(f.records)})
+			f.records = append(f.records, rec.Value)
+		}
+		res = map[string]any{"offsets": offsets}
+	case "POST /kafka/consumers/g":
+		res = map[string]string{"instance_id": "i", "base_uri": f.base + instance}
+	case "POST " + instance + "/subscription":
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case "GET " + instance + "/records":
+		var recs []any
+		for ; f.fetched < len(f.records); f.fetched++ {
+			recs = append(recs, map[string]any{"topic": "t", "partition": 0, "offset": f.fetched, "value": f.records[f.fetched]})
+		}
+		res = recs
+	case "POST " + instance + "/offsets":
+		var req struct{ Offsets []struct{ Offset int64 } }
+		json.NewDecoder(r.Body).Decode(&req)
+		f.committed = req.Offsets[0].Offset
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case "DELETE " + instance:
+		f.closed = true
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]any{"error_code": 40401, "message": "not found"})
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+func TestRoundTrip(t *testing.T) {
+	ps := &fakePubSub{pending: make(map[string]bool)}
+	psServer := httptest.NewServer(ps)
+	defer psServer.Close()
+	t.Setenv("PUBSUB_EMULATOR_HOST", strings.TrimPrefix(psServer.URL, "http://"))
+
+	kafka := &fakeKafkaProxy{committed: -1}
+	kafkaServer := httptest.NewServer(kafka)
+	defer kafkaServer.Close()
+	kafka.base = kafkaServer.URL
+	kafkaURL := "kafka://" + strings.TrimPrefix(kafkaServer.URL, "http://") + "/kafka/"
+
+	tests := []struct {
+		topic, sub string
+		check      func(t *testing.T)
+	}{{
+		topic: "pubsub://p/t",
+		sub:   "pubsub://p/s",
+		check: func(t *testing.T) {
+			if len(ps.queue) != 0 || len(ps.pending) != 0 {
+				t.Errorf("Pub/Sub: %d messages not pulled, %d not acknowledged", len(ps.queue), len(ps.pending))
+			}
+		},
+	}, {
+		topic: kafkaURL + "t",
+		sub:   kafkaURL + "t?group=g",
+		check: func(t *testing.T) {
+			if want := int64(len(kafka.records) - 1); kafka.committed != want || !kafka.closed {
+				t.Errorf("Kafka: committed offset %d, closed %v; want %d, true", kafka.committed, kafka.closed, want)
+			}
+		},
+	}}
+	for _, test := range tests {
+		t.Run(test.topic, func(t *testing.T) {
+			ctx := context.Background()
+			entries := testEntries()
+
+			pub, err := OpenPublisher(ctx, test.topic)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sink := NewSink(pub, &SinkOptions{MaxMessageSize: 200, MaxPendingMessages: 3})
+			for _, e := range entries {
+				if err := sink.Put(ctx, e); err != nil {
+					t.Fatalf("Put: %v", err)
+				}
+			}
+			if err := sink.Flush(ctx); err != nil {
+				t.Fatalf("Flush: %v", err)
+			} else if err := pub.Close(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if sink.NumEntries != len(entries) || sink.NumMessages < 4 {
+				t.Errorf("Published %d entries in %d messages; want %d entries in several", sink.NumEntries, sink.NumMessages, len(entries))
+			}
+
+			sub, err := OpenSubscriber(ctx, test.sub)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gs := new(inmemory.GraphStore)
+			stats, err := Consume(ctx, sub, gs, &ConsumeOptions{
+				BatchSize:    2,
+				PollInterval: time.Millisecond,
+				IdleTimeout:  20 * time.Millisecond,
+			})
+			if err != nil {
+				t.Fatalf("Consume: %v", err)
+			} else if err := sub.Close(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if want := (Stats{Messages: sink.NumMessages, Entries: len(entries)}); stats != want {
+				t.Errorf("Consume: got %+v; want %+v", stats, want)
+			}
+
+			var got []*spb.Entry
+			if err := gs.Scan(ctx, new(spb.ScanRequest), func(e *spb.Entry) error {
+				got = append(got, e)
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			sort.Sort(compare.ByEntries(entries))
+			if diff := compare.ProtoDiff(entries, got); diff != "" {
+				t.Errorf("Entries written (-want +got):\n%s", diff)
+			}
+			test.check(t)
+		})
+	}
+}
+
+func TestConsumeInvalidMessage(t *testing.T) {
+	ctx := context.Background()
+	sub := &fakeSubscriber{msgs: []*Message{{Data: []byte{0x05, 'a'}}}}
+	if _, err := Consume(ctx, sub, new(inmemory.GraphStore), nil); err == nil {
+		t.Error("Consume of an invalid message: got nil error")
+	} else if sub.acked != 0 {
+		t.Errorf("Consume acknowledged %d invalid messages", sub.acked)
+	}
+}
+
+type fakeSubscriber struct {
+	msgs  []*Message
+	acked int
+}
+
+func (s *fakeSubscriber) Receive(context.Context) ([]*Message, error) {
+	msgs := s.msgs
+	s.msgs = nil
+	return msgs, nil
+}
+
+func (s *fakeSubscriber) Ack(_ context.Context, msgs []*Message) error {
+	s.acked += len(msgs)
+	return nil
+}
+
+func (*fakeSubscriber) Close(context.Context) error { return nil }
@@ -0,0 +1,146 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package subgraph
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"unicode/utf8"
+
+	"kythe.io/kythe/go/util/schema/facts"
+)
+
+// displayFacts returns the names of the facts of node that are rendered,
+// in sorted order.  Source text and binary values are omitted.
+func displayFacts(node map[string][]byte) []string {
+	var names []string
+	for name, value := range node {
+		if name == facts.Text || name == facts.Code || !utf8.Valid(value) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteDOT writes g to w in the Graphviz DOT language.  Each node is labeled
+// with a table of its facts and each edge with its kind.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	fmt.Fprintln(buf, "digraph kythe {")
+	for _, ticket := range g.Tickets() {
+		node := g.Nodes[ticket]
+		fmt.Fprintf(buf, `	%q [label=<<table><tr><td colspan="2">%s</td></tr>`, ticket, html.EscapeString(ticket))
+		for _, name := range displayFacts(node) {
+			fmt.Fprintf(buf, "<tr><td>%s</td><td>%s</td></tr>", html.EscapeString(name), html.EscapeString(string(node[name])))
+		}
+		fmt.Fprintln(buf, "</table>> shape=plaintext];")
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(buf, "\t%q -> %q [label=%q];\n", e.Source, e.Target, e.Kind)
+	}
+	fmt.Fprintln(buf, "}")
+	return buf.Flush()
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML writes g to w as a GraphML document.  Node IDs are tickets;
+// each rendered fact is declared as a string node attribute named by the fact
+// name, and each edge has a "kind" attribute.
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  []graphMLKey{{ID: "kind", For: "edge", Name: "kind", Type: "string"}},
+		Graph: graphMLGraph{EdgeDefault: "directed"},
+	}
+	keys := make(map[string]string) // fact name -> key ID
+	for _, ticket := range g.Tickets() {
+		node := g.Nodes[ticket]
+		n := graphMLNode{ID: ticket}
+		for _, name := range displayFacts(node) {
+			id, ok := keys[name]
+			if !ok {
+				id = fmt.Sprintf("f%d", len(keys))
+				keys[name] = id
+			}
+			n.Data = append(n.Data, graphMLData{Key: id, Value: string(node[name])})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, n)
+	}
+	for name, id := range keys {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: id, For: "node", Name: name, Type: "string"})
+	}
+	sort.Slice(doc.Keys[1:], func(i, j int) bool { return doc.Keys[i+1].Name < doc.Keys[j+1].Name })
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: e.Source,
+			Target: e.Target,
+			Data:   []graphMLData{{Key: "kind", Value: e.Kind}},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package subgraph extracts small subgraphs from a GraphStore and renders them
+// in formats suitable for visualization (Graphviz DOT and GraphML).
+package subgraph // import "kythe.io/kythe/go/storage/subgraph"
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"kythe.io/kythe/go/services/graphstore"
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/schema/edges"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// Options control which part of the graph is extracted.
+type Options struct {
+	// MaxDepth is the maximum number of edges followed from a root node.  If
+	// zero, only the roots themselves are extracted.  The edges between the
+	// nodes extracted are all kept, including those between nodes MaxDepth
+	// edges from a root.
+	MaxDepth int
+
+	// EdgeKinds restricts the edges followed to those whose kind is a variant
+	// of one of the given kinds (see edges.IsVariant).  If empty, all edges
+	// are followed.
+	EdgeKinds []string
+
+	// ReverseEdges causes reverse edges (e.g. %/kythe/edge/childof) to be
+	// followed in addition to forward edges.  EdgeKinds filters are applied
+	// to the forward form of each reverse edge.
+	ReverseEdges bool
+
+	// MaxNodes, if positive, limits the number of nodes extracted.  Nodes
+	// closer to the roots are extracted first.
+	MaxNodes int
+}
+
+// An Edge is a directed edge between two nodes, identified by ticket.
+type Edge struct {
+	Source, Target, Kind string
+}
+
+// A Graph is an extracted subgraph.
+type Graph struct {
+	// Nodes maps each node's ticket to its facts.
+	Nodes map[string]map[string][]byte
+
+	// Edges holds each edge between two extracted nodes, in sorted order.
+	Edges []Edge
+
+	// Truncated is set if extraction stopped early due to Options.MaxNodes.
+	Truncated bool
+}
+
+// Tickets returns the tickets of the nodes in g, in sorted order.
+func (g *Graph) Tickets() []string {
+	tickets := make([]string, 0, len(g.Nodes))
+	for t := range g.Nodes {
+		tickets = append(tickets, t)
+	}
+	sort.Strings(tickets)
+	return tickets
+}
+
+// Extract reads the subgraph reachable from roots in gs, by a breadth-first
+// traversal bounded by opts.
+func Extract(ctx context.Context, gs graphstore.Service, roots []*spb.VName, opts *Options) (*Graph, error) {
+	if opts == nil {
+		opts = new(Options)
+	}
+	g := &Graph{Nodes: make(map[string]map[string][]byte)}
+	var candidates []Edge
+
+	var frontier []*spb.VName
+	enqueue := func(v *spb.VName) bool {
+		ticket := kytheuri.ToString(v)
+		if _, ok := g.Nodes[ticket]; ok {
+			return true
+		} else if opts.MaxNodes > 0 && len(g.Nodes) >= opts.MaxNodes {
+			g.Truncated = true
+			return false
+		}
+		g.Nodes[ticket] = make(map[string][]byte)
+		frontier = append(frontier, v)
+		return true
+	}
+	for _, r := range roots {
+		enqueue(r)
+	}
+
+	for depth := 0; len(frontier) > 0; depth++ {
+		current := frontier
+		frontier = nil
+		for _, src := range current {
+			ticket := kytheuri.ToString(src)
+			var targets []*spb.VName
+			if err := gs.Read(ctx, &spb.ReadRequest{Source: src, EdgeKind: "*"}, func(e *spb.Entry) error {
+				if e.GetEdgeKind() == "" {
+					g.Nodes[ticket][e.GetFactName()] = e.GetFactValue()
+				} else if opts.follow(e.GetEdgeKind()) {
+					// Nodes at the depth limit are not expanded, but their
+					// edges to other extracted nodes are kept.
+					candidates = append(candidates, Edge{ticket, kytheuri.ToString(e.GetTarget()), e.GetEdgeKind()})
+					if depth < opts.MaxDepth {
+						targets = append(targets, e.GetTarget())
+					}
+				}
+				return nil
+			}); err != nil {
+				return nil, fmt.Errorf("reading %q: %v", ticket, err)
+			}
+			for _, t := range targets {
+				enqueue(t)
+			}
+		}
+	}
+
+	// Keep only edges with both ends in the graph, in their forward form.
+	seen := make(map[Edge]bool)
+	for _, e := range candidates {
+		if edges.IsReverse(e.Kind) {
+			e = Edge{e.Target, e.Source, edges.Mirror(e.Kind)}
+		}
+		_, hasSrc := g.Nodes[e.Source]
+		_, hasTgt := g.Nodes[e.Target]
+		if hasSrc && hasTgt && !seen[e] {
+			seen[e] = true
+			g.Edges = append(g.Edges, e)
+		}
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		} else if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Target < b.Target
+	})
+	return g, nil
+}
+
+// follow reports whether edges of the given kind should be traversed.
+func (o *Options) follow(kind string) bool {
+	if edges.IsReverse(kind) {
+		if !o.ReverseEdges {
+			return false
+		}
+		kind = edges.Canonical(kind)
+	}
+	if len(o.EdgeKinds) == 0 {
+		return true
+	}
+	base, _, _ := edges.ParseOrdinal(kind)
+	for _, k := range o.EdgeKinds {
+		if edges.IsVariant(base, k) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package subgraph
+
+import (
+	"context"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/storage/inmemory"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	"github.com/google/go-cmp/cmp"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func vname(sig string) *spb.VName { return &spb.VName{Signature: sig} }
+
+// testStore returns a GraphStore with the chain a -childof-> b -childof-> c
+// plus a -ref-> d and d -ref-> b, with reverse edges.
+func testStore(t *testing.T) *inmemory.GraphStore {
+	gs := new(inmemory.GraphStore)
+	write := func(src string, updates ...*spb.WriteRequest_Update) {
+		if err := gs.Write(context.Background(), &spb.WriteRequest{Source: vname(src), Update: updates}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fact := func(name, value string) *spb.WriteRequest_Update {
+		return &spb.WriteRequest_Update{FactName: name, FactValue: []byte(value)}
+	}
+	edge := func(kind, tgt string) *spb.WriteRequest_Update {
+		return &spb.WriteRequest_Update{EdgeKind: kind, Target: vname(tgt), FactName: "/"}
+	}
+	write("a", fact(facts.NodeKind, "anchor"), fact(facts.Text, "ignored"), edge(edges.ChildOf, "b"), edge(edges.Ref, "d"))
+	write("b", fact(facts.NodeKind, "record"), edge(edges.ChildOf, "c"), edge(edges.Mirror(edges.ChildOf), "a"), edge(edges.Mirror(edges.Ref), "d"))
+	write("c", fact(facts.NodeKind, "package"), edge(edges.Mirror(edges.ChildOf), "b"))
+	write("d", fact(facts.NodeKind, "function"), edge(edges.Mirror(edges.Ref), "a"), edge(edges.Ref, "b"))
+	return gs
+}
+
+func TestExtract(t *testing.T) {
+	gs := testStore(t)
+	tests := []struct {
+		root  string
+		opts  Options
+		nodes []string
+		edges []Edge
+	}{
+		{"a", Options{}, []string{"kythe:#a"}, nil},
+		// Edges between nodes at the depth limit are kept.
+		{"a", Options{MaxDepth: 1}, []string{"kythe:#a", "kythe:#b", "kythe:#d"}, []Edge{
+			{"kythe:#a", "kythe:#b", edges.ChildOf},
+			{"kythe:#a", "kythe:#d", edges.Ref},
+			{"kythe:#d", "kythe:#b", edges.Ref},
+		}},
+		{"a", Options{MaxDepth: 5, EdgeKinds: []string{edges.ChildOf}}, []string{"kythe:#a", "kythe:#b", "kythe:#c"}, []Edge{
+			{"kythe:#a", "kythe:#b", edges.ChildOf},
+			{"kythe:#b", "kythe:#c", edges.ChildOf},
+		}},
+		{"c", Options{MaxDepth: 5}, []string{"kythe:#c"}, nil},
+		{"c", Options{MaxDepth: 1, ReverseEdges: true}, []string{"kythe:#b", "kythe:#c"}, []Edge{
+			{"kythe:#b", "kythe:#c", edges.ChildOf},
+		}},
+		{"d", Options{MaxDepth: 5, ReverseEdges: true, EdgeKinds: []string{edges.ChildOf}}, []string{"kythe:#d"}, nil},
+	}
+	for _, test := range tests {
+		g, err := Extract(context.Background(), gs, []*spb.VName{vname(test.root)}, &test.opts)
+		if err != nil {
+			t.Fatalf("Extract(%q, %+v): %v", test.root, test.opts, err)
+		}
+		if diff := cmp.Diff(test.nodes, g.Tickets()); diff != "" {
+			t.Errorf("Extract(%q, %+v) nodes: (-want +got)\n%s", test.root, test.opts, diff)
+		}
+		if diff := cmp.Diff(test.edges, g.Edges); diff != "" {
+			t.Errorf("Extract(%q, %+v) edges: (-want +got)\n%s", test.root, test.opts, diff)
+		}
+	}
+}
+
+func TestExtractMaxNodes(t *testing.T) {
+	g, err := Extract(context.Background(), testStore(t), []*spb.VName{vname("a")}, &Options{MaxDepth: 5, MaxNodes: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Nodes) != 2 || !g.Truncated {
+		t.Errorf("Got %d nodes (truncated: %v); want 2 (truncated: true)", len(g.Nodes), g.Truncated)
+	}
+	if len(g.Edges) != 1 {
+		t.Errorf("Got edges %v; want only the edge between extracted nodes", g.Edges)
+	}
+}
+
+func TestRender(t *testing.T) {
+	g, err := Extract(context.Background(), testStore(t), []*spb.VName{vname("a")}, &Options{MaxDepth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dot strings.Builder
+	if err := g.WriteDOT(&dot); err != nil {
+		t.Fatalf("WriteDOT: %v", err)
+	}
+	for _, want := range []string{
+		`"kythe:#a" -> "kythe:#b" [label="/kythe/edge/childof"];`,
+		"<td>/kythe/node/kind</td><td>anchor</td>",
+	} {
+		if !strings.Contains(dot.String(), want) {
+			t.Errorf("DOT output missing %q:\n%s", want, dot.String())
+		}
+	}
+	if strings.Contains(dot.String(), "ignored") {
+		t.Errorf("DOT output contains /kythe/text:\n%s", dot.String())
+	}
+
+	var ml strings.Builder
+	if err := g.WriteGraphML(&ml); err != nil {
+		t.Fatalf("WriteGraphML: %v", err)
+	}
+	var doc graphML
+	if err := xml.Unmarshal([]byte(ml.String()), &doc); err != nil {
+		t.Fatalf("Invalid GraphML: %v\n%s", err, ml.String())
+	}
+	if len(doc.Graph.Nodes) != 3 || len(doc.Graph.Edges) != 3 {
+		t.Errorf("GraphML has %d nodes and %d edges; want 3 and 3", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+	}
+	if diff := cmp.Diff([]graphMLKey{
+		{ID: "kind", For: "edge", Name: "kind", Type: "string"},
+		{ID: "f0", For: "node", Name: facts.NodeKind, Type: "string"},
+	}, doc.Keys); diff != "" {
+		t.Errorf("GraphML keys: (-want +got)\n%s", diff)
+	}
+}
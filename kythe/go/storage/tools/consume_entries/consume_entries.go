@@ -0,0 +1,103 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary consume_entries writes the entries published to a Cloud Pub/Sub or
+// Kafka topic (as by publish_entries) to a GraphStore as they arrive.  Each
+// message is acknowledged once its entries are written, so that a restarted
+// consumer resumes where it stopped.  See package
+// kythe.io/kythe/go/storage/stream/topic for the URLs supported.
+//
+// Consumption continues until the process is interrupted or, with
+// --idle_timeout, until no messages have arrived for that long.
+//
+// Example:
+//
+//	consume_entries --subscription pubsub://my-project/kythe-entries-sub \
+//	  --graphstore gs/leveldb
+//
+// Example:
+//
+//	consume_entries --subscription kafka://localhost:8082/kythe-entries?group=serving \
+//	  --idle_timeout 5m --graphstore gs/leveldb
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"kythe.io/kythe/go/services/graphstore"
+	"kythe.io/kythe/go/storage/gsutil"
+	"kythe.io/kythe/go/storage/stream/topic"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+
+	_ "kythe.io/kythe/go/services/graphstore/proxy"
+	_ "kythe.io/kythe/go/storage/leveldb"
+)
+
+var (
+	subURL       = flag.String("subscription", "", "URL of the subscription (or Kafka topic) from which to consume entries")
+	batchSize    = flag.Int("batch_size", topic.DefaultBatchSize, "Maximum entries per write for consecutive entries with the same source")
+	pollInterval = flag.Duration("poll_interval", topic.DefaultPollInterval, "Interval between receives while no messages arrive")
+	idleTimeout  = flag.Duration("idle_timeout", 0, "If positive, stop once no messages have arrived for this long")
+
+	gs graphstore.Service
+)
+
+func init() {
+	flag.Usage = flagutil.SimpleUsage("Write the entries published to a topic to a GraphStore",
+		"--subscription url [--batch_size entries] [--idle_timeout duration] --graphstore spec")
+	gsutil.Flag(&gs, "graphstore", "GraphStore to which to write the entries")
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() > 0 {
+		flagutil.UsageErrorf("unknown arguments: %v", flag.Args())
+	} else if *subURL == "" {
+		flagutil.UsageError("missing --subscription")
+	} else if gs == nil {
+		flagutil.UsageError("missing --graphstore")
+	} else if *batchSize < 1 {
+		flagutil.UsageErrorf("Invalid --batch_size %d (must be ≥ 1)", *batchSize)
+	}
+
+	// Stop consuming on a signal, leaving the message being written to be
+	// delivered again, and close the GraphStore normally.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	defer gsutil.LogClose(context.Background(), gs)
+
+	sub, err := topic.OpenSubscriber(ctx, *subURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	stats, err := topic.Consume(ctx, sub, gs, &topic.ConsumeOptions{
+		BatchSize:    *batchSize,
+		PollInterval: *pollInterval,
+		IdleTimeout:  *idleTimeout,
+	})
+	if cerr := sub.Close(context.Background()); cerr != nil {
+		log.Errorf("closing subscription: %v", cerr)
+	}
+	if err != nil {
+		log.Fatalf("Failed to consume entries: %v", err)
+	}
+	log.Infof("Wrote %d entries from %d messages", stats.Entries, stats.Messages)
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary entries_to_cscope converts a delimited entry stream into a cscope
+// cross-reference database, so that cscope can find the definitions, callers
+// and callees of symbols from the Kythe index.  The input need not be sorted.
+// See package kythe.io/kythe/go/storage/cscope for how symbols are marked.
+//
+// With --corpus and --path, only the files of a corpus, or of a directory
+// within it, are written.  With --relative, file names are written relative to
+// --path.  Run cscope with -d from --dir, where the file names resolve.
+//
+// Example:
+//
+//	entries_to_cscope --corpus github.com/org/repo --path src/lib --relative \
+//	  --output src/lib/cscope.out entries
+//	cd src/lib && cscope -d
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"io"
+	"os"
+
+	"kythe.io/kythe/go/platform/vfs"
+	"kythe.io/kythe/go/storage/cscope"
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+)
+
+var (
+	output   = flag.String("output", "cscope.out", "Path of the database to write")
+	dir      = flag.String("dir", ".", "Directory recorded in the database, from which cscope resolves file names")
+	corpus   = flag.String("corpus", "", "If set, only write the files of this corpus")
+	path     = flag.String("path", "", "If set, only write the files in this directory (or this file)")
+	relative = flag.Bool("relative", false, "Write file names relative to --path")
+)
+
+func init() {
+	flag.Usage = flagutil.SimpleUsage("Convert an entry stream to a cscope database",
+		"[--corpus c] [--path p] [--relative] [--dir d] [--output path] [entries_file]")
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flagutil.UsageErrorf("too many arguments: %v", flag.Args())
+	} else if *relative && *path == "" {
+		flagutil.UsageError("--relative requires --path")
+	} else if *output == "" {
+		flagutil.UsageError("missing --output")
+	}
+	ctx := context.Background()
+
+	var in io.Reader = os.Stdin
+	if flag.NArg() == 1 {
+		f, err := vfs.Open(ctx, flag.Arg(0))
+		if err != nil {
+			log.Fatalf("Failed to open input file %q: %v", flag.Arg(0), err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	out, err := vfs.Create(ctx, *output)
+	if err != nil {
+		log.Fatalf("Failed to create output file: %v", err)
+	}
+
+	x := cscope.NewExporter(out, &cscope.Options{
+		Dir:      *dir,
+		Corpus:   *corpus,
+		Path:     *path,
+		Relative: *relative,
+	})
+	if err := stream.NewReader(bufio.NewReader(in))(x.Add); err != nil {
+		log.Fatalf("Failed to convert entries: %v", err)
+	}
+	if err := x.Close(); err != nil {
+		log.Fatal(err)
+	} else if err := out.Close(); err != nil {
+		log.Fatal(err)
+	}
+	log.Infof("Wrote %d symbols on %d lines of %d files (%d anchors dropped)", x.NumSymbols, x.NumLines, x.NumFiles, x.NumDropped)
+}
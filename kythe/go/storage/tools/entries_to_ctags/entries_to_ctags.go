@@ -0,0 +1,104 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary entries_to_ctags converts a delimited entry stream into a tags file
+// in the extended format of Exuberant and Universal Ctags, for editors without
+// LSP support.  The input need not be sorted.  See package
+// kythe.io/kythe/go/storage/ctags for which nodes are tagged.
+//
+// With --corpus and --path, only the files of a corpus, or of a directory
+// within it, are tagged.  With --relative, file names are written relative to
+// --path, so that the tags file can be stored in that directory.
+//
+// Example:
+//
+//	entries_to_ctags --corpus github.com/org/repo --path src/lib --relative \
+//	  --output src/lib/tags entries
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"io"
+	"os"
+
+	"kythe.io/kythe/go/platform/vfs"
+	"kythe.io/kythe/go/storage/ctags"
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+)
+
+var (
+	output      = flag.String("output", "", "Path of the tags file to write (default stdout)")
+	corpus      = flag.String("corpus", "", "If set, only tag the files of this corpus")
+	path        = flag.String("path", "", "If set, only tag the files in this directory (or this file)")
+	relative    = flag.Bool("relative", false, "Write file names relative to --path")
+	lineNumbers = flag.Bool("line_numbers", false, "Address tags by line number rather than by search pattern")
+	version     = flag.String("program_version", "", "Version recorded in the header of the tags file")
+)
+
+func init() {
+	flag.Usage = flagutil.SimpleUsage("Convert an entry stream to a ctags tags file",
+		"[--corpus c] [--path p] [--relative] [--line_numbers] [--output path] [entries_file]")
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flagutil.UsageErrorf("too many arguments: %v", flag.Args())
+	} else if *relative && *path == "" {
+		flagutil.UsageError("--relative requires --path")
+	}
+	ctx := context.Background()
+
+	var in io.Reader = os.Stdin
+	if flag.NArg() == 1 {
+		f, err := vfs.Open(ctx, flag.Arg(0))
+		if err != nil {
+			log.Fatalf("Failed to open input file %q: %v", flag.Arg(0), err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var out io.WriteCloser = os.Stdout
+	if *output != "" {
+		f, err := vfs.Create(ctx, *output)
+		if err != nil {
+			log.Fatalf("Failed to create output file: %v", err)
+		}
+		out = f
+	}
+
+	x := ctags.NewExporter(out, &ctags.Options{
+		Corpus:         *corpus,
+		Path:           *path,
+		Relative:       *relative,
+		LineNumbers:    *lineNumbers,
+		ProgramVersion: *version,
+	})
+	if err := stream.NewReader(bufio.NewReader(in))(x.Add); err != nil {
+		log.Fatalf("Failed to convert entries: %v", err)
+	}
+	if err := x.Close(); err != nil {
+		log.Fatal(err)
+	} else if err := out.Close(); err != nil {
+		log.Fatal(err)
+	}
+	log.Infof("Wrote %d tags (%d bindings dropped)", x.NumTags, x.NumDropped)
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary entries_to_lsif converts a delimited entry stream into an LSIF dump,
+// for tools that consume the Language Server Index Format.  The input need not
+// be sorted.  See package kythe.io/kythe/go/storage/lsif for how Kythe nodes
+// and edges are represented.
+//
+// Example:
+//
+//	entries_to_lsif --project_root file:///home/me/repo --output dump.lsif entries
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"io"
+	"os"
+
+	"kythe.io/kythe/go/platform/vfs"
+	"kythe.io/kythe/go/storage/lsif"
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+)
+
+var (
+	output      = flag.String("output", "", "Path of the LSIF dump to write (default stdout)")
+	projectRoot = flag.String("project_root", "file:///", "URI under which the paths of files are resolved")
+)
+
+func init() {
+	flag.Usage = flagutil.SimpleUsage("Convert an entry stream to an LSIF dump",
+		"[--project_root uri] [--output path] [entries_file]")
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flagutil.UsageErrorf("too many arguments: %v", flag.Args())
+	}
+	ctx := context.Background()
+
+	var in io.Reader = os.Stdin
+	if flag.NArg() == 1 {
+		f, err := vfs.Open(ctx, flag.Arg(0))
+		if err != nil {
+			log.Fatalf("Failed to open input file %q: %v", flag.Arg(0), err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var out io.WriteCloser = os.Stdout
+	if *output != "" {
+		f, err := vfs.Create(ctx, *output)
+		if err != nil {
+			log.Fatalf("Failed to create output file: %v", err)
+		}
+		out = f
+	}
+	w := bufio.NewWriter(out)
+
+	x := lsif.NewExporter(w, &lsif.Options{ProjectRoot: *projectRoot})
+	if err := stream.NewReader(bufio.NewReader(in))(x.Add); err != nil {
+		log.Fatalf("Failed to convert entries: %v", err)
+	}
+	if err := x.Close(); err != nil {
+		log.Fatal(err)
+	} else if err := w.Flush(); err != nil {
+		log.Fatal(err)
+	} else if err := out.Close(); err != nil {
+		log.Fatal(err)
+	}
+	log.Infof("Wrote %d documents, %d ranges and %d result sets (%d anchors dropped)",
+		x.NumDocuments, x.NumRanges, x.NumResultSets, x.NumDropped)
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary entries_to_neo4j converts a sorted delimited entry stream into the
+// nodes.csv and relationships.csv files accepted by the Neo4j bulk importer.
+//
+// Examples:
+//
+//	entrystream --sort < entries | entries_to_neo4j --output_dir out
+//	neo4j-admin database import full --nodes=out/nodes.csv \
+//	  --relationships=out/relationships.csv kythe
+//
+// Once imported, the graph may be queried with Cypher, for example:
+//
+//	MATCH p = shortestPath((a:Node {ticket: $from})-[:DEPENDS*]->(b:Node {ticket: $to}))
+//	RETURN p
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+
+	"kythe.io/kythe/go/platform/vfs"
+	"kythe.io/kythe/go/storage/neo4j"
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+)
+
+var outputDir = flag.String("output_dir", "", "Directory in which to write nodes.csv and relationships.csv (required)")
+
+func init() {
+	flag.Usage = flagutil.SimpleUsage("Convert a sorted entry stream to Neo4j bulk-import CSV files",
+		"--output_dir dir [entries_file]")
+}
+
+func main() {
+	flag.Parse()
+	if *outputDir == "" {
+		flagutil.UsageError("missing required --output_dir")
+	} else if flag.NArg() > 1 {
+		flagutil.UsageErrorf("too many arguments: %v", flag.Args())
+	}
+	ctx := context.Background()
+
+	var in io.Reader = os.Stdin
+	if flag.NArg() == 1 {
+		f, err := vfs.Open(ctx, flag.Arg(0))
+		if err != nil {
+			log.Fatalf("Failed to open input file %q: %v", flag.Arg(0), err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	if err := vfs.MkdirAll(ctx, *outputDir, 0755); err != nil {
+		log.Fatalf("Failed to create output directory: %v", err)
+	}
+	nodes, err := vfs.Create(ctx, filepath.Join(*outputDir, "nodes.csv"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	rels, err := vfs.Create(ctx, filepath.Join(*outputDir, "relationships.csv"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	x, err := neo4j.NewExporter(nodes, rels)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := stream.NewReader(bufio.NewReader(in))(x.Add); err != nil {
+		log.Fatalf("Failed to convert entries: %v", err)
+	}
+	if err := x.Close(); err != nil {
+		log.Fatal(err)
+	} else if err := nodes.Close(); err != nil {
+		log.Fatal(err)
+	} else if err := rels.Close(); err != nil {
+		log.Fatal(err)
+	}
+	log.Infof("Wrote %d nodes and %d relationships to %s", x.NumNodes, x.NumRelationships, *outputDir)
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary graph_checker scans a Kythe graph for dangling edges, orphan anchors,
+// and files without text, and prints the counts of each per language, with
+// samples.  With --json, it prints a single JSON object stamped with the time
+// of the check, so that the output of successive runs may be appended to a
+// log to track the quality of an index over time.
+//
+// Example:
+//
+//	graph_checker --graphstore gs/leveldb
+//
+// Example:
+//
+//	graph_checker --json --max_samples 0 entries >> quality.jsonl
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"kythe.io/kythe/go/platform/vfs"
+	"kythe.io/kythe/go/services/graphstore"
+	"kythe.io/kythe/go/storage/graphcheck"
+	"kythe.io/kythe/go/storage/gsutil"
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+
+	_ "kythe.io/kythe/go/services/graphstore/proxy"
+	_ "kythe.io/kythe/go/storage/leveldb"
+)
+
+var (
+	maxSamples = flag.Int("max_samples", graphcheck.DefaultMaxSamples, "Maximum number of samples of each problem per language (0 for none)")
+	jsonOutput = flag.Bool("json", false, "Print the report as a JSON object")
+
+	gs graphstore.Service
+)
+
+func init() {
+	gsutil.Flag(&gs, "graphstore", "GraphStore to check, in place of an entry stream")
+	flag.Usage = flagutil.SimpleUsage("Scans a graph for dangling edges, orphan anchors, and files without text",
+		"[--max_samples n] [--json] (--graphstore spec | [entries_file])")
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flagutil.UsageErrorf("too many arguments: %v", flag.Args())
+	} else if gs != nil && flag.NArg() > 0 {
+		flagutil.UsageError("--graphstore cannot be combined with an entries file")
+	}
+	ctx := context.Background()
+
+	opts := &graphcheck.Options{MaxSamples: *maxSamples}
+	if *maxSamples == 0 {
+		opts.MaxSamples = -1
+	}
+	c := graphcheck.New(opts)
+	start := time.Now()
+	if gs != nil {
+		defer gsutil.LogClose(ctx, gs)
+		if err := gs.Scan(ctx, new(spb.ScanRequest), c.Add); err != nil {
+			log.Fatalf("GraphStore Scan error: %v", err)
+		}
+	} else {
+		var in io.Reader = os.Stdin
+		if flag.NArg() == 1 {
+			f, err := vfs.Open(ctx, flag.Arg(0))
+			if err != nil {
+				log.Fatalf("Failed to open input file %q: %v", flag.Arg(0), err)
+			}
+			defer f.Close()
+			in = f
+		}
+		if err := stream.NewReader(bufio.NewReader(in))(c.Add); err != nil {
+			log.Fatalf("Failed to read entries: %v", err)
+		}
+	}
+	report := c.Report()
+
+	if *jsonOutput {
+		out := struct {
+			Time time.Time `json:"time"`
+			*graphcheck.Report
+		}{start.UTC(), report}
+		if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	printReport(report)
+}
+
+func printReport(r *graphcheck.Report) {
+	for _, l := range r.Languages {
+		lang := l.Language
+		if lang == "" {
+			lang = "(none)"
+		}
+		fmt.Printf("%s: %d nodes, %d edges\n", lang, l.Nodes, l.Edges)
+		problems := make([]string, 0, len(l.Counts))
+		for p := range l.Counts {
+			problems = append(problems, string(p))
+		}
+		sort.Strings(problems)
+		for _, p := range problems {
+			fmt.Printf("  %s: %d\n", p, l.Counts[graphcheck.Problem(p)])
+		}
+		for _, s := range l.Samples {
+			if s.Problem == graphcheck.DanglingEdge {
+				fmt.Printf("    %s: %s %s %s\n", s.Problem, s.Ticket, s.Edge, s.Target)
+			} else {
+				fmt.Printf("    %s: %s\n", s.Problem, s.Ticket)
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary merge_entries merges sorted delimited entry streams into a single
+// sorted, deduplicated entry stream written to stdout.  Each input must be
+// sorted in GraphStore order (e.g. by entrystream --sort).
+//
+// Entries that share a key but differ in fact value are conflicts; by default
+// the value from the earliest input is kept (see --conflict_policy).
+// Conflicts may be written as a JSON stream to a separate file for inspection.
+//
+// Examples:
+//
+//	merge_entries java.entries cxx.entries go.entries > merged.entries
+//	merge_entries --conflicts conflicts.json shard-*.entries > merged.entries
+//	merge_entries --conflict_policy prefer_language --input_languages java,c++ java.entries cxx.entries > merged.entries
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+
+	"kythe.io/kythe/go/platform/delimited"
+	"kythe.io/kythe/go/platform/vfs"
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/storage/stream/merge"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+var (
+	conflictsPath  = flag.String("conflicts", "", "If set, write a JSON stream describing each conflicting entry to this path")
+	conflictPolicy = flag.String("conflict_policy", string(merge.FirstInput), "Policy choosing the value kept of conflicting entries (first_input, last_write, prefer_language, or error to fail on any conflict)")
+
+	languages flagutil.StringList
+)
+
+func init() {
+	flag.Var(&languages, "input_languages", "Comma-separated languages of the indexers that produced each input, for --conflict_policy=prefer_language")
+	flag.Usage = flagutil.SimpleUsage("Merge sorted entry streams into a single sorted, deduplicated stream",
+		"[--conflicts path] [--conflict_policy p [--input_languages l1,l2,...]] entries_file+")
+}
+
+// conflict is the JSON representation of a merge.Conflict.
+type conflict struct {
+	Kept         *spb.Entry `json:"kept"`
+	KeptInput    string     `json:"kept_input"`
+	Dropped      *spb.Entry `json:"dropped"`
+	DroppedInput string     `json:"dropped_input"`
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flagutil.UsageError("no input entry streams given")
+	} else if len(languages) > 0 && len(languages) != flag.NArg() {
+		flagutil.UsageErrorf("--input_languages gives %d languages for %d inputs", len(languages), flag.NArg())
+	}
+	policy, err := merge.ParsePolicy(*conflictPolicy)
+	if err != nil {
+		flagutil.UsageError(err.Error())
+	} else if policy == merge.PreferLanguage && len(languages) == 0 {
+		flagutil.UsageError("--conflict_policy=prefer_language requires --input_languages")
+	}
+	ctx := context.Background()
+
+	var inputs []stream.EntryReader
+	for _, path := range flag.Args() {
+		f, err := vfs.Open(ctx, path)
+		if err != nil {
+			log.Fatalf("Failed to open input file %q: %v", path, err)
+		}
+		defer f.Close()
+		inputs = append(inputs, stream.NewReader(bufio.NewReader(f)))
+	}
+
+	var conflicts *json.Encoder
+	if *conflictsPath != "" {
+		f, err := vfs.Create(ctx, *conflictsPath)
+		if err != nil {
+			log.Fatalf("Failed to create conflicts file: %v", err)
+		}
+		defer func() {
+			if err := f.Close(); err != nil {
+				log.Fatalf("Failed to close conflicts file: %v", err)
+			}
+		}()
+		conflicts = json.NewEncoder(f)
+	}
+
+	opts := &merge.Options{
+		Policy:    policy,
+		Languages: languages,
+		OnConflict: func(c *merge.Conflict) error {
+			if conflicts == nil {
+				return nil
+			}
+			return conflicts.Encode(conflict{
+				Kept:         c.Kept,
+				KeptInput:    flag.Arg(c.KeptStream),
+				Dropped:      c.Dropped,
+				DroppedInput: flag.Arg(c.DroppedStream),
+			})
+		},
+	}
+
+	var stats merge.Stats
+	out := bufio.NewWriter(os.Stdout)
+	wr := delimited.NewWriter(out)
+	if err := merge.Merge(opts, &stats, inputs...)(func(e *spb.Entry) error {
+		return wr.PutProto(e)
+	}); err != nil {
+		log.Fatal(err)
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+	log.Infof("Read %d entries; wrote %d (%d duplicates, %d conflicts dropped)",
+		stats.Read, stats.Written, stats.Duplicates, stats.Conflicts)
+}
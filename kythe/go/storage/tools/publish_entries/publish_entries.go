@@ -0,0 +1,104 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary publish_entries reads a delimited entry stream and publishes it to a
+// Cloud Pub/Sub or Kafka topic, from which consume_entries writes it to a
+// GraphStore.  See package kythe.io/kythe/go/storage/stream/topic for the
+// topic URLs supported.
+//
+// Usage:
+//
+//	indexer ... | publish_entries --topic url
+//
+// Example:
+//
+//	java_indexer compilation.kzip | \
+//	  publish_entries --topic pubsub://my-project/kythe-entries
+//
+// Example:
+//
+//	publish_entries --topic kafka://localhost:8082/kythe-entries entries
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"io"
+	"os"
+
+	"kythe.io/kythe/go/platform/vfs"
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/storage/stream/topic"
+	"kythe.io/kythe/go/util/datasize"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+var (
+	topicURL       = flag.String("topic", "", "URL of the topic to which to publish entries")
+	maxMessageSize = datasize.Flag("max_message_size", "256KiB", "Size at which a message of entries is complete")
+	maxPending     = flag.Int("max_pending_messages", topic.DefaultMaxPendingMessages, "Number of complete messages to publish together")
+)
+
+func init() {
+	flag.Usage = flagutil.SimpleUsage("Publish a delimited entry stream to a topic",
+		"--topic url [--max_message_size size] [--max_pending_messages n] [entries_file]")
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flagutil.UsageErrorf("too many arguments: %v", flag.Args())
+	} else if *topicURL == "" {
+		flagutil.UsageError("missing --topic")
+	} else if *maxPending < 1 {
+		flagutil.UsageErrorf("Invalid --max_pending_messages %d (must be ≥ 1)", *maxPending)
+	}
+	ctx := context.Background()
+
+	var in io.Reader = os.Stdin
+	if flag.NArg() == 1 {
+		f, err := vfs.Open(ctx, flag.Arg(0))
+		if err != nil {
+			log.Fatalf("Failed to open input file %q: %v", flag.Arg(0), err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	pub, err := topic.OpenPublisher(ctx, *topicURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sink := topic.NewSink(pub, &topic.SinkOptions{
+		MaxMessageSize:     int(maxMessageSize.Bytes()),
+		MaxPendingMessages: *maxPending,
+	})
+	if err := stream.NewReader(bufio.NewReader(in))(func(e *spb.Entry) error {
+		return sink.Put(ctx, e)
+	}); err != nil {
+		log.Fatalf("Failed to publish entries: %v", err)
+	}
+	if err := sink.Flush(ctx); err != nil {
+		log.Fatalf("Failed to publish entries: %v", err)
+	} else if err := pub.Close(ctx); err != nil {
+		log.Fatal(err)
+	}
+	log.Infof("Published %d entries in %d messages", sink.NumEntries, sink.NumMessages)
+}
@@ -69,7 +69,9 @@ func main() {
 		flagutil.UsageError("--shards and giving tickets for reads are mutually exclusive")
 	}
 
-	ctx := context.Background()
+	// Each entry is written (or counted) before the next is read, so none are
+	// retained and the GraphStore may reuse them.
+	ctx := graphstore.WithEntryReuse(context.Background(), true)
 
 	wr := delimited.NewWriter(os.Stdout)
 	var total int64
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary redact_entries rewrites a delimited entry stream so that it can be
+// shared without revealing source code.  Corpus, root, and path names are
+// replaced with keyed hashes and file text is dropped; the shape of the graph
+// is preserved.
+//
+// Examples:
+//
+//	redact_entries --salt=$RANDOM < entries > redacted
+//	redact_entries --corpus_map=internal=example --drop_code entries > redacted
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"io"
+	"os"
+	"strings"
+
+	"kythe.io/kythe/go/platform/delimited"
+	"kythe.io/kythe/go/platform/vfs"
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/storage/stream/redact"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+var (
+	salt           = flag.String("salt", "", "Secret used to key the hash of redacted values (default: a random salt)")
+	hashCorpora    = flag.Bool("hash_corpora", true, "Hash corpus names not listed in --corpus_map")
+	hashRoots      = flag.Bool("hash_roots", true, "Hash VName roots")
+	hashPaths      = flag.Bool("hash_paths", true, "Hash each component of VName paths")
+	keepExtensions = flag.Bool("keep_extensions", true, "Keep file extensions when hashing paths")
+	hashSignatures = flag.Bool("hash_signatures", true, "Hash VName signatures")
+	dropText       = flag.Bool("drop_text", true, "Drop file text facts")
+	dropCode       = flag.Bool("drop_code", true, "Drop code and snippet facts, which may contain identifiers")
+
+	corpusMap flagutil.StringList
+	dropFacts flagutil.StringList
+)
+
+func init() {
+	flag.Var(&corpusMap, "corpus_map", "Comma-separated list of old=new corpus name replacements")
+	flag.Var(&dropFacts, "drop_facts", "Comma-separated list of additional fact names to drop")
+	flag.Usage = flagutil.SimpleUsage("Redact identifying information from an entry stream",
+		"[--salt s] [--corpus_map old=new,...] [--drop_facts name,...] [entries_file]")
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flagutil.UsageErrorf("too many arguments: %v", flag.Args())
+	}
+
+	opts := &redact.Options{
+		Salt:           *salt,
+		Corpora:        make(map[string]string),
+		HashCorpora:    *hashCorpora,
+		HashRoots:      *hashRoots,
+		HashPaths:      *hashPaths,
+		KeepExtensions: *keepExtensions,
+		HashSignatures: *hashSignatures,
+		DropFacts:      dropFacts,
+	}
+	for _, m := range corpusMap {
+		from, to, ok := strings.Cut(m, "=")
+		if !ok {
+			flagutil.UsageErrorf("invalid --corpus_map entry %q; expected old=new", m)
+		}
+		opts.Corpora[from] = to
+	}
+	if *salt == "" {
+		log.Info("No --salt given; using a random salt")
+	}
+	if *dropText {
+		opts.DropFacts = append(opts.DropFacts, facts.Text)
+	}
+	if *dropCode {
+		opts.DropFacts = append(opts.DropFacts, facts.Code, facts.SnippetStart, facts.SnippetEnd)
+	}
+
+	var in io.Reader = os.Stdin
+	if flag.NArg() == 1 {
+		f, err := vfs.Open(context.Background(), flag.Arg(0))
+		if err != nil {
+			log.Fatalf("Failed to open input file %q: %v", flag.Arg(0), err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	r := redact.New(opts)
+	out := bufio.NewWriter(os.Stdout)
+	wr := delimited.NewWriter(out)
+	var read, dropped int
+	if err := stream.NewReader(bufio.NewReader(in))(func(e *spb.Entry) error {
+		read++
+		if e = r.Entry(e); e == nil {
+			dropped++
+			return nil
+		}
+		return wr.PutProto(e)
+	}); err != nil {
+		log.Fatal(err)
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+	log.Infof("Redacted %d entries (%d dropped)", read, dropped)
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary split_entries partitions a delimited entry stream into multiple
+// delimited entry streams, one per distinct key.  By default, entries are
+// keyed by the corpus of their source VName.  Each output file is written to
+// --output_dir and named by the escaped key followed by ".entries"; the
+// entries with an empty key are written to "%empty.entries", a name no
+// escaped key can take.
+//
+// Examples:
+//
+//	split_entries --output_dir out < entries
+//	split_entries --output_dir out --key corpus_root entries
+//	split_entries --output_dir out --key_template '{{.Source.Language}}' < entries
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"kythe.io/kythe/go/platform/vfs"
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/storage/stream/split"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+var (
+	outputDir   = flag.String("output_dir", "", "Directory in which to write the split entry streams (required)")
+	key         = flag.String("key", "corpus", "Key by which to partition entries (one of: corpus, root, corpus_root, language)")
+	keyTemplate = flag.String("key_template", "", "Go text/template evaluated against each spb.Entry to compute its key (overrides --key)")
+	maxOpen     = flag.Int("max_open_files", split.DefaultMaxOpen, "Maximum number of output files kept open at once; others are closed and reopened for appending as needed")
+)
+
+var keyFuncs = map[string]split.KeyFunc{
+	"corpus":      split.ByCorpus,
+	"root":        split.ByRoot,
+	"corpus_root": split.ByCorpusRoot,
+	"language":    split.ByLanguage,
+}
+
+func init() {
+	flag.Usage = flagutil.SimpleUsage("Partition an entry stream into multiple streams by key",
+		"--output_dir dir [--key name | --key_template tmpl] [--max_open_files n] [entries_file]")
+}
+
+func main() {
+	flag.Parse()
+	if *outputDir == "" {
+		flagutil.UsageError("missing required --output_dir")
+	} else if flag.NArg() > 1 {
+		flagutil.UsageErrorf("too many arguments: %v", flag.Args())
+	}
+	ctx := context.Background()
+
+	keyFunc, err := parseKeyFunc()
+	if err != nil {
+		flagutil.UsageError(err.Error())
+	}
+
+	var in io.Reader = os.Stdin
+	if flag.NArg() == 1 {
+		f, err := vfs.Open(ctx, flag.Arg(0))
+		if err != nil {
+			log.Fatalf("Failed to open input file %q: %v", flag.Arg(0), err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	if err := vfs.MkdirAll(ctx, *outputDir, 0755); err != nil {
+		log.Fatalf("Failed to create output directory: %v", err)
+	}
+
+	s := split.New(keyFunc, *maxOpen, func(key string, appending bool) (io.WriteCloser, error) {
+		path := filepath.Join(*outputDir, fileName(key))
+		if appending {
+			// vfs does not support appending, so outputs that are reopened
+			// must be local files.
+			return os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		}
+		return vfs.Create(ctx, path)
+	})
+	if err := stream.NewReader(bufio.NewReaderSize(in, 2*4096))(s.Write); err != nil {
+		s.Close()
+		log.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		log.Fatal(err)
+	}
+
+	counts := s.Counts()
+	for _, k := range s.Keys() {
+		log.Infof("Wrote %d entries to %s", counts[k], fileName(k))
+	}
+	log.Infof("Split %d entries into %d streams", s.Total(), len(counts))
+}
+
+func parseKeyFunc() (split.KeyFunc, error) {
+	if *keyTemplate == "" {
+		f, ok := keyFuncs[*key]
+		if !ok {
+			return nil, fmt.Errorf("unknown --key %q", *key)
+		}
+		return f, nil
+	}
+	tmpl, err := template.New("key").Option("missingkey=error").Parse(*keyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --key_template: %v", err)
+	}
+	return func(e *spb.Entry) (string, error) {
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, e); err != nil {
+			return "", err
+		}
+		return sb.String(), nil
+	}, nil
+}
+
+// fileName returns the output file name for the given key.  Escaping
+// replaces each "%" of a key with "%25", so the name of the empty key cannot
+// collide with that of another.
+func fileName(key string) string {
+	if key == "" {
+		return "%empty.entries"
+	}
+	return url.PathEscape(key) + ".entries"
+}
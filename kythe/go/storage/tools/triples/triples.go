@@ -22,6 +22,17 @@
 //	triples entries > triples.nq.gz
 //	triples --graphstore path/to/gs > triples.nq.gz
 //	triples entries triples.nq
+//	triples --format turtle entries triples.ttl
+//	triples --format ntriples --iri_template 'https://cs.example.com/{{.Corpus}}/{{.Path}}#{{.Signature}}' entries
+//
+// By default, every term is written as a quoted string (the "legacy" format).
+// The ntriples and turtle formats instead write VNames as IRIs, fact names
+// and edge kinds as IRIs within --predicate_namespace, and fact values as
+// literals.  VName IRIs default to Kythe tickets but may be customized with
+// --iri_template, a Go text/template evaluated against each VName; the
+// template function "escape" path-escapes its argument.  The turtle format
+// abbreviates predicates with the prefixes kythe:, node: and edge:, as in
+// node:kind and edge:childof.
 //
 // Reference: http://en.wikipedia.org/wiki/N-Triples
 package main
@@ -32,7 +43,11 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
+	"strings"
+	"text/template"
+	"unicode/utf8"
 
 	"kythe.io/kythe/go/platform/vfs"
 	"kythe.io/kythe/go/services/graphstore"
@@ -42,6 +57,7 @@ import (
 	"kythe.io/kythe/go/util/flagutil"
 	"kythe.io/kythe/go/util/kytheuri"
 	"kythe.io/kythe/go/util/log"
+	"kythe.io/kythe/go/util/schema"
 	"kythe.io/kythe/go/util/schema/edges"
 	"kythe.io/kythe/go/util/schema/facts"
 
@@ -55,13 +71,17 @@ var (
 	keepReverseEdges = flag.Bool("keep_reverse_edges", false, "Do not filter reverse edges from triples output")
 	quiet            = flag.Bool("quiet", false, "Do not emit logging messages")
 
+	format             = flag.String("format", "legacy", "Output format (one of: legacy, ntriples, turtle)")
+	iriTemplate        = flag.String("iri_template", "", "Go text/template evaluated against each VName to produce its IRI (default: its Kythe ticket)")
+	predicateNamespace = flag.String("predicate_namespace", "http://kythe.io/schema", "IRI prefix for fact names and edge kinds in the ntriples and turtle formats")
+
 	gs graphstore.Service
 )
 
 func init() {
 	gsutil.Flag(&gs, "graphstore", "Path to GraphStore to convert to triples (instead of an entry stream)")
 	flag.Usage = flagutil.SimpleUsage("Converts an Entry stream to a stream of triples",
-		"[(--graphstore path | entries_file) [triples_out]]")
+		"[--format legacy|ntriples|turtle] [--iri_template tmpl] [(--graphstore path | entries_file) [triples_out]]")
 }
 
 func main() {
@@ -124,19 +144,24 @@ func main() {
 		}()
 	}
 
+	write, closeOutput, err := newWriter(out)
+	if err != nil {
+		flagutil.UsageError(err.Error())
+	}
 	for entry := range entries {
 		if edges.IsReverse(entry.EdgeKind) && !*keepReverseEdges {
 			reverseEdges++
 			continue
 		}
 
-		t, err := toTriple(entry)
-		if err != nil {
+		if err := write(entry); err != nil {
 			log.Fatal(err)
 		}
-		fmt.Fprintln(out, t)
 		triples++
 	}
+	if err := closeOutput(); err != nil {
+		log.Fatal(err)
+	}
 
 	if !*quiet {
 		if !*keepReverseEdges {
@@ -146,6 +171,93 @@ func main() {
 	}
 }
 
+// newWriter returns a function writing each entry to out in the selected
+// --format, and a function to be called after the last entry.
+func newWriter(out io.Writer) (write func(*spb.Entry) error, flush func() error, err error) {
+	noop := func() error { return nil }
+	if *format == "legacy" {
+		return func(entry *spb.Entry) error {
+			t, err := toTriple(entry)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(out, t)
+			return err
+		}, noop, nil
+	}
+
+	toIRI := kytheuri.ToString
+	if *iriTemplate != "" {
+		tmpl, err := template.New("iri").Funcs(template.FuncMap{"escape": url.PathEscape}).Parse(*iriTemplate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid --iri_template: %v", err)
+		}
+		toIRI = func(v *spb.VName) string {
+			var buf strings.Builder
+			if err := tmpl.Execute(&buf, v); err != nil {
+				log.Fatalf("Error evaluating --iri_template for %v: %v", v, err)
+			}
+			return buf.String()
+		}
+	}
+
+	switch *format {
+	case "ntriples":
+		return func(entry *spb.Entry) error {
+			s, err := toStatement(entry, toIRI)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(out, s)
+			return err
+		}, noop, nil
+	case "turtle":
+		tw := rdf.NewTurtleWriter(out, map[string]string{
+			"kythe": predicateIRI(schema.Prefix),
+			"edge":  predicateIRI(edges.Prefix),
+			"node":  predicateIRI(schema.Prefix + "node/"),
+			"xsd":   "http://www.w3.org/2001/XMLSchema#",
+		})
+		return func(entry *spb.Entry) error {
+			s, err := toStatement(entry, toIRI)
+			if err != nil {
+				return err
+			}
+			return tw.Write(s)
+		}, tw.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown --format %q", *format)
+	}
+}
+
+// predicateIRI returns the IRI of the given fact name or edge kind within
+// --predicate_namespace.  Names begin with a "/", so a trailing "/" of the
+// namespace is dropped.
+func predicateIRI(name string) string {
+	return strings.TrimSuffix(*predicateNamespace, "/") + name
+}
+
+// toStatement converts an Entry to an RDF statement, using toIRI to map
+// VNames to IRIs.  Returns an error if the entry is not valid.
+func toStatement(entry *spb.Entry, toIRI func(*spb.VName) string) (*rdf.Statement, error) {
+	if err := graphstore.ValidEntry(entry); err != nil {
+		return nil, fmt.Errorf("invalid entry {%+v}: %v", entry, err)
+	}
+
+	s := &rdf.Statement{Subject: rdf.IRI(toIRI(entry.Source))}
+	if graphstore.IsEdge(entry) {
+		s.Predicate = rdf.IRI(predicateIRI(entry.EdgeKind))
+		s.Object = rdf.IRI(toIRI(entry.Target))
+	} else if entry.FactName == facts.Code || !utf8.Valid(entry.FactValue) {
+		s.Predicate = rdf.IRI(predicateIRI(entry.FactName))
+		s.Object = rdf.TypedLiteral(base64.StdEncoding.EncodeToString(entry.FactValue), rdf.XSDBase64Binary)
+	} else {
+		s.Predicate = rdf.IRI(predicateIRI(entry.FactName))
+		s.Object = rdf.Literal(string(entry.FactValue))
+	}
+	return s, nil
+}
+
 // toTriple converts an Entry to the triple file format. Returns an error if
 // the entry is not valid.
 func toTriple(entry *spb.Entry) (*rdf.Triple, error) {
@@ -31,18 +31,49 @@
 // Example:
 //
 //	zcat entries.gz | write_entries --graphstore gs/leveldb
+//
+// Example:
+//
+//	# Verify entries against their manifest before loading any of them.
+//	write_entries --input entries --manifest entries.manifest --graphstore gs/leveldb
+//
+// Example:
+//
+//	# Stamp every node written with the unit and run that produced it, so
+//	# that bad data can be traced back (see "kythe nodes --provenance").
+//	indexer unit.kzip | write_entries --provenance_unit kythe://c#unit --provenance_invocation run-42 --graphstore gs/leveldb
+//
+// Example:
+//
+//	# Load shards as an extraction pipeline writes them into a directory,
+//	# stopping once no new entries have appeared for 5 minutes.
+//	write_entries --input shards/ --follow --follow_idle_timeout 5m --graphstore gs/leveldb
+//
+// Example:
+//
+//	# Record who wrote to the GraphStore, and how much, in an audit log
+//	# (see audit_log).
+//	write_entries --input entries --audit_log /var/log/kythe/audit.log --graphstore gs/leveldb
 package main
 
 import (
 	"context"
 	"flag"
+	"io"
 	"os"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"kythe.io/kythe/go/platform/analysis/provenance"
+	"kythe.io/kythe/go/platform/delimited/manifest"
+	"kythe.io/kythe/go/platform/vfs"
 	"kythe.io/kythe/go/services/graphstore"
 	"kythe.io/kythe/go/storage/gsutil"
 	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/storage/stream/follow"
+	"kythe.io/kythe/go/util/audit"
+	"kythe.io/kythe/go/util/datasize"
 	"kythe.io/kythe/go/util/flagutil"
 	"kythe.io/kythe/go/util/log"
 	"kythe.io/kythe/go/util/profile"
@@ -54,15 +85,32 @@ import (
 )
 
 var (
-	batchSize  = flag.Int("batch_size", 1024, "Maximum entries per write for consecutive entries with the same source")
-	numWorkers = flag.Int("workers", 1, "Number of concurrent workers writing to the GraphStore")
+	batchSize     = flag.Int("batch_size", 1024, "Maximum entries per write for consecutive entries with the same source")
+	adaptiveBatch = flag.Bool("adaptive_batch_size", false, "Adapt the batch size to the latency of the GraphStore instead of using --batch_size")
+	maxBatchSize  = flag.Int("max_batch_size", 16384, "Maximum entries per write with --adaptive_batch_size")
+	targetLatency = flag.Duration("target_write_latency", 50*time.Millisecond, "Write latency to aim for with --adaptive_batch_size")
+	batchMemLimit = datasize.Flag("batch_memory_limit", "0", "If positive with --adaptive_batch_size, shrink batches to the minimum while the heap exceeds this size")
+	numWorkers    = flag.Int("workers", 1, "Number of concurrent workers writing to the GraphStore")
+
+	inputPath    = flag.String("input", "", "Path of the delimited entry stream to write (default: stdin)")
+	manifestPath = flag.String("manifest", "", "If set, verify --input against this integrity manifest before writing any entries")
+
+	followInput = flag.Bool("follow", false, "Follow --input as it grows, writing new entries as they appear; --input may be a directory of shards")
+	followIdle  = flag.Duration("follow_idle_timeout", 0, "If positive, stop following --input once no new entries have appeared for this long")
+	followPoll  = flag.Duration("follow_poll_interval", follow.DefaultPollInterval, "Interval between checks for new data with --follow")
+
+	provUnit       = flag.String("provenance_unit", "", "If set, stamp every node written with this compilation unit ticket as its origin")
+	provInvocation = flag.String("provenance_invocation", "", "If set, stamp every node written with this indexer invocation identifier as its origin")
+
+	auditLog = flag.String("audit_log", "", "If set, the path of an audit log in which to record the writes made to the GraphStore")
 
 	gs graphstore.Service
 )
 
 func init() {
 	flag.Usage = flagutil.SimpleUsage("Write a delimited stream of entries from stdin to a GraphStore",
-		"[--batch_size entries] [--workers n] --graphstore spec")
+		"[--batch_size entries | --adaptive_batch_size [--max_batch_size entries]] [--workers n] [--input path [--manifest path | --follow]]",
+		"[--provenance_unit ticket] [--provenance_invocation id] [--audit_log path] --graphstore spec")
 	gsutil.Flag(&gs, "graphstore", "GraphStore to which to write the entry stream")
 }
 
@@ -72,12 +120,49 @@ func main() {
 		flagutil.UsageErrorf("Invalid number of --workers %d (must be ≥ 1)", *numWorkers)
 	} else if *batchSize < 1 {
 		flagutil.UsageErrorf("Invalid --batch_size %d (must be ≥ 1)", *batchSize)
+	} else if *maxBatchSize < 1 {
+		flagutil.UsageErrorf("Invalid --max_batch_size %d (must be ≥ 1)", *maxBatchSize)
 	} else if gs == nil {
 		flagutil.UsageError("Missing --graphstore")
+	} else if *manifestPath != "" && *inputPath == "" {
+		flagutil.UsageError("--manifest requires --input")
+	} else if *followInput && *inputPath == "" {
+		flagutil.UsageError("--follow requires --input")
+	} else if *followInput && *manifestPath != "" {
+		flagutil.UsageError("--follow cannot be combined with --manifest")
 	}
 
 	ctx := context.Background()
 
+	var in io.Reader = os.Stdin
+	var entries <-chan *spb.Entry
+	if *followInput {
+		entries = followEntries(ctx, *inputPath, &follow.Options{
+			PollInterval: *followPoll,
+			IdleTimeout:  *followIdle,
+		})
+	} else if *inputPath != "" {
+		if *manifestPath != "" {
+			if err := verifyManifest(ctx, *inputPath, *manifestPath); err != nil {
+				log.Fatalf("Refusing to write %s: %v", *inputPath, err)
+			}
+		}
+		f, err := vfs.Open(ctx, *inputPath)
+		if err != nil {
+			log.Fatalf("Failed to open input: %v", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	if *auditLog != "" {
+		l, err := audit.Open(*auditLog, nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer l.Close()
+		gs = graphstore.Audit(gs, l, flag.Lookup("graphstore").Value.String())
+	}
 	defer gsutil.LogClose(ctx, gs)
 	gsutil.EnsureGracefulExit(gs)
 
@@ -86,7 +171,29 @@ func main() {
 	}
 	defer profile.Stop()
 
-	writes := graphstore.BatchWrites(stream.ReadEntries(os.Stdin), *batchSize)
+	if entries == nil {
+		entries = stream.ReadEntries(in)
+	}
+	if *provUnit != "" || *provInvocation != "" {
+		entries = provenance.NewStamper(&provenance.Origin{
+			Unit:       *provUnit,
+			Invocation: *provInvocation,
+		}).Entries(entries)
+	}
+	var (
+		writes <-chan *spb.WriteRequest
+		sizer  *graphstore.BatchSizer
+	)
+	if !*adaptiveBatch {
+		writes = graphstore.BatchWrites(entries, *batchSize)
+	} else {
+		sizer = graphstore.NewBatchSizer(&graphstore.BatchOptions{
+			MaxSize:       *maxBatchSize,
+			TargetLatency: *targetLatency,
+			MemoryLimit:   *batchMemLimit,
+		})
+		writes = graphstore.AdaptiveBatchWrites(entries, sizer)
+	}
 
 	var (
 		wg         sync.WaitGroup
@@ -96,7 +203,7 @@ func main() {
 	for i := 0; i < *numWorkers; i++ {
 		go func() {
 			defer wg.Done()
-			num, err := writeEntries(ctx, gs, writes)
+			num, err := writeEntries(ctx, gs, writes, sizer)
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -106,17 +213,67 @@ func main() {
 	}
 	wg.Wait()
 
+	if sizer != nil {
+		log.InfoContextf(ctx, "Wrote %d entries (final batch size %d)", numEntries, sizer.Size())
+		return
+	}
 	log.InfoContextf(ctx, "Wrote %d entries", numEntries)
 }
 
-func writeEntries(ctx context.Context, s graphstore.Service, reqs <-chan *spb.WriteRequest) (uint64, error) {
+// followEntries returns a channel of the entries read by following path.
+func followEntries(ctx context.Context, path string, opts *follow.Options) <-chan *spb.Entry {
+	ch := make(chan *spb.Entry)
+	go func() {
+		defer close(ch)
+		if err := follow.Entries(ctx, path, opts)(func(e *spb.Entry) error {
+			ch <- e
+			return nil
+		}); err != nil {
+			log.Fatal(err)
+		}
+	}()
+	return ch
+}
+
+// verifyManifest checks the stream at path against the manifest at
+// manifestPath.
+func verifyManifest(ctx context.Context, path, manifestPath string) error {
+	mf, err := vfs.Open(ctx, manifestPath)
+	if err != nil {
+		return err
+	}
+	defer mf.Close()
+	m, err := manifest.Read(mf)
+	if err != nil {
+		return err
+	}
+
+	f, err := vfs.Open(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := manifest.Verify(f, m); err != nil {
+		return err
+	}
+	log.InfoContextf(ctx, "Verified %d entries against %s", m.Records, manifestPath)
+	return nil
+}
+
+// writeEntries writes each of reqs to s, reporting the latency of each write
+// to sizer if it is non-nil.
+func writeEntries(ctx context.Context, s graphstore.Service, reqs <-chan *spb.WriteRequest, sizer *graphstore.BatchSizer) (uint64, error) {
 	var num uint64
 
 	for req := range reqs {
 		num += uint64(len(req.Update))
+		start := time.Now()
 		if err := s.Write(ctx, req); err != nil {
 			return 0, err
 		}
+		if sizer != nil {
+			sizer.Observe(len(req.Update), time.Since(start))
+		}
 	}
 
 	return num, nil
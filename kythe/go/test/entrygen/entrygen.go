@@ -0,0 +1,319 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package entrygen generates random but well-formed Kythe graphs, for
+// property-based tests of the code that stores and serves them.
+//
+// A generated graph has files with text, semantic nodes with kinds, and
+// anchors whose locations lie within the text of the file they are children
+// of, defining and referring to the semantic nodes.  VNames are drawn from an
+// alphabet that includes the characters escaped in tickets.  Generation is
+// deterministic for a given seed, so a failing property can be reproduced
+// from the seed reported by Run.
+package entrygen // import "kythe.io/kythe/go/test/entrygen"
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	"google.golang.org/protobuf/proto"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+var firstSeed = flag.Int64("entrygen_seed", 1, "Seed of the first graph generated by entrygen.Run")
+
+// Run calls f in n subtests, each with a Generator seeded differently.  The
+// seed of each subtest is in its name, as "seed=N", and the first seed may be
+// set with --entrygen_seed to explore other graphs.
+func Run(t *testing.T, n int, opts *Options, f func(*testing.T, *Generator)) {
+	t.Helper()
+	for i := int64(0); i < int64(n); i++ {
+		seed := *firstSeed + i
+		t.Run(fmt.Sprintf("seed=%d", seed), func(t *testing.T) { f(t, New(seed, opts)) })
+	}
+}
+
+// Options describe the size of the graphs generated.  A zero field takes the
+// default value given for it.
+type Options struct {
+	MaxFiles        int // maximum number of files (default 4)
+	MaxNodesPerFile int // maximum number of semantic nodes per file (default 4)
+	MaxRefsPerFile  int // maximum number of references per file (default 8)
+
+	// ReverseEdges adds the mirror of each edge to the graph, as in a graph
+	// whose edges have been fully expanded.
+	ReverseEdges bool
+}
+
+func (o *Options) files() int { return positive(o.MaxFiles, 4) }
+func (o *Options) nodes() int { return positive(o.MaxNodesPerFile, 4) }
+func (o *Options) refs() int  { return positive(o.MaxRefsPerFile, 8) }
+
+func positive(n, def int) int {
+	if n <= 0 {
+		return def
+	}
+	return n
+}
+
+// A Generator generates random graphs.
+type Generator struct {
+	rng  *rand.Rand
+	opts Options
+	seen map[string]bool // names generated by unique
+}
+
+// New returns a Generator with the given seed.
+func New(seed int64, opts *Options) *Generator {
+	g := &Generator{rng: rand.New(rand.NewSource(seed)), seen: make(map[string]bool)}
+	if opts != nil {
+		g.opts = *opts
+	}
+	return g
+}
+
+// Rand returns the source of randomness of g, for choices made by a test.
+func (g *Generator) Rand() *rand.Rand { return g.rng }
+
+// alphabet is the set of characters drawn from for names, including those
+// with special meaning in tickets and paths.
+var alphabet = []rune("abcxyzAZ019_-.:/?#%@+= é")
+
+// Name returns a random non-empty string of at most n characters.
+func (g *Generator) Name(n int) string {
+	var sb strings.Builder
+	for i := g.rng.Intn(n) + 1; i > 0; i-- {
+		sb.WriteRune(alphabet[g.rng.Intn(len(alphabet))])
+	}
+	return sb.String()
+}
+
+// unique returns a name not previously returned by unique.
+func (g *Generator) unique(prefix string) string {
+	for {
+		if s := prefix + g.Name(6); !g.seen[s] {
+			g.seen[s] = true
+			return s
+		}
+	}
+}
+
+// VName returns a random VName with at least a signature or a path.
+func (g *Generator) VName() *spb.VName {
+	v := &spb.VName{Corpus: g.maybe(g.Name(6)), Root: g.maybe(g.Name(4)), Language: g.maybe(g.Name(4))}
+	if g.rng.Intn(2) == 0 {
+		v.Signature = g.Name(8)
+		v.Path = g.maybe(g.Name(8))
+	} else {
+		v.Path = g.Name(8)
+		v.Signature = g.maybe(g.Name(8))
+	}
+	return v
+}
+
+// maybe returns s or, with even chance, the empty string.
+func (g *Generator) maybe(s string) string {
+	if g.rng.Intn(2) == 0 {
+		return ""
+	}
+	return s
+}
+
+// Semantic node kinds and the kinds of edges between them and from anchors
+// to them, chosen from at random.
+var (
+	nodeKinds   = []string{nodes.Function, nodes.Variable, nodes.Record, nodes.Interface, nodes.Constant}
+	anchorEdges = []string{edges.Ref, edges.RefCall, edges.RefImports, edges.Ref}
+	semEdges    = []string{edges.ChildOf, edges.Typed, edges.Extends, edges.ParamIndex(0), edges.ParamIndex(1)}
+	tokens      = []string{"x", "foo", "Bar", "é", "α_β", "func", "0", "<T>"}
+	spaces      = []string{" ", "\n", "\t", "  ", "\r\n", ""}
+)
+
+type file struct {
+	vname *spb.VName
+	text  strings.Builder
+	nodes []*spb.VName
+}
+
+// Graph returns the entries of a random graph, file by file.  Entries are
+// neither sorted nor duplicated.
+func (g *Generator) Graph() []*spb.Entry {
+	corpus, root, lang := g.Name(6), g.maybe(g.Name(4)), g.Name(4)
+	files := make([]*file, g.rng.Intn(g.opts.files())+1)
+	for i := range files {
+		files[i] = &file{vname: &spb.VName{Corpus: corpus, Root: root, Path: g.unique("src/")}}
+		for j := g.rng.Intn(g.opts.nodes() + 1); j > 0; j-- {
+			files[i].nodes = append(files[i].nodes, &spb.VName{Signature: g.unique(""), Corpus: corpus, Language: lang})
+		}
+	}
+	var all []*spb.VName
+	for _, f := range files {
+		all = append(all, f.nodes...)
+	}
+
+	var entries []*spb.Entry
+	add := func(es ...*spb.Entry) {
+		for _, e := range es {
+			entries = append(entries, e)
+			if g.opts.ReverseEdges && e.EdgeKind != "" {
+				entries = append(entries, Edge(e.Target, edges.Mirror(e.EdgeKind), e.Source))
+			}
+		}
+	}
+	for _, f := range files {
+		anchor := func(kind string, target *spb.VName) {
+			f.text.WriteString(spaces[g.rng.Intn(len(spaces))])
+			start := f.text.Len()
+			f.text.WriteString(tokens[g.rng.Intn(len(tokens))])
+			end := f.text.Len()
+			a := &spb.VName{
+				Signature: fmt.Sprintf("@%d:%d", start, end),
+				Corpus:    f.vname.Corpus,
+				Root:      f.vname.Root,
+				Path:      f.vname.Path,
+				Language:  lang,
+			}
+			add(Fact(a, facts.NodeKind, nodes.Anchor),
+				Fact(a, facts.AnchorStart, strconv.Itoa(start)),
+				Fact(a, facts.AnchorEnd, strconv.Itoa(end)),
+				Edge(a, edges.ChildOf, f.vname),
+				Edge(a, kind, target))
+		}
+		for _, n := range f.nodes {
+			add(Fact(n, facts.NodeKind, nodeKinds[g.rng.Intn(len(nodeKinds))]))
+			anchor(edges.DefinesBinding, n)
+			if len(all) > 1 && g.rng.Intn(2) == 0 {
+				if t := all[g.rng.Intn(len(all))]; t != n {
+					add(Edge(n, semEdges[g.rng.Intn(len(semEdges))], t))
+				}
+			}
+		}
+		if len(all) > 0 {
+			for k := g.rng.Intn(g.opts.refs() + 1); k > 0; k-- {
+				anchor(anchorEdges[g.rng.Intn(len(anchorEdges))], all[g.rng.Intn(len(all))])
+			}
+		}
+		add(Fact(f.vname, facts.NodeKind, nodes.File),
+			Fact(f.vname, facts.Text, f.text.String()))
+	}
+	return entries
+}
+
+// Shuffle randomly permutes entries.
+func (g *Generator) Shuffle(entries []*spb.Entry) {
+	g.rng.Shuffle(len(entries), func(i, j int) { entries[i], entries[j] = entries[j], entries[i] })
+}
+
+// Duplicate returns entries with copies of some of them added, in a random
+// order.
+func (g *Generator) Duplicate(entries []*spb.Entry) []*spb.Entry {
+	out := append([]*spb.Entry(nil), entries...)
+	for _, e := range entries {
+		for g.rng.Intn(3) == 0 {
+			out = append(out, proto.Clone(e).(*spb.Entry))
+		}
+	}
+	g.Shuffle(out)
+	return out
+}
+
+// Fact returns a fact entry.
+func Fact(v *spb.VName, name, value string) *spb.Entry {
+	return &spb.Entry{Source: v, FactName: name, FactValue: []byte(value)}
+}
+
+// Edge returns an edge entry.
+func Edge(src *spb.VName, kind string, tgt *spb.VName) *spb.Entry {
+	return &spb.Entry{Source: src, EdgeKind: kind, Target: tgt, FactName: "/"}
+}
+
+// Validate reports whether entries are a well-formed graph, as generated by
+// Graph: every entry is a fact or an edge between nodes with kinds, every
+// anchor is the child of a file and lies within its text, and if the graph
+// has any reverse edges then every edge is mirrored.
+func Validate(entries []*spb.Entry) error {
+	nodeFacts := make(map[string]map[string]string)
+	type edge struct{ src, kind, tgt string }
+	edgeSet := make(map[edge]bool)
+	childOf := make(map[string][]string)
+	reverse := false
+	for _, e := range entries {
+		if e.Source.GetSignature() == "" && e.Source.GetPath() == "" {
+			return fmt.Errorf("entry without a signature or path: %v", e)
+		}
+		src := kytheuri.ToString(e.Source)
+		if nodeFacts[src] == nil {
+			nodeFacts[src] = make(map[string]string)
+		}
+		if e.EdgeKind == "" {
+			if e.Target != nil || e.FactName == "" || e.FactName == "/" {
+				return fmt.Errorf("malformed fact: %v", e)
+			}
+			nodeFacts[src][e.FactName] = string(e.FactValue)
+			continue
+		}
+		if e.Target == nil || e.FactName != "/" {
+			return fmt.Errorf("malformed edge: %v", e)
+		}
+		tgt := kytheuri.ToString(e.Target)
+		if k := (edge{src, e.EdgeKind, tgt}); !edgeSet[k] {
+			edgeSet[k] = true
+			if e.EdgeKind == edges.ChildOf {
+				childOf[src] = append(childOf[src], tgt)
+			}
+		}
+		reverse = reverse || edges.IsReverse(e.EdgeKind)
+	}
+
+	for e := range edgeSet {
+		for _, n := range []string{e.src, e.tgt} {
+			if nodeFacts[n][facts.NodeKind] == "" {
+				return fmt.Errorf("edge %s %s %s: node %s has no kind", e.src, e.kind, e.tgt, n)
+			}
+		}
+		if reverse && !edgeSet[edge{e.tgt, edges.Mirror(e.kind), e.src}] {
+			return fmt.Errorf("edge %s %s %s is not mirrored", e.src, e.kind, e.tgt)
+		}
+	}
+	for n, fs := range nodeFacts {
+		if fs[facts.NodeKind] != nodes.Anchor {
+			continue
+		}
+		start, err1 := strconv.Atoi(fs[facts.AnchorStart])
+		end, err2 := strconv.Atoi(fs[facts.AnchorEnd])
+		if err1 != nil || err2 != nil || start < 0 || end < start {
+			return fmt.Errorf("anchor %s has invalid location [%q, %q)", n, fs[facts.AnchorStart], fs[facts.AnchorEnd])
+		}
+		parents := childOf[n]
+		if len(parents) != 1 || nodeFacts[parents[0]][facts.NodeKind] != nodes.File {
+			return fmt.Errorf("anchor %s is not the child of one file: %v", n, parents)
+		}
+		if text := nodeFacts[parents[0]][facts.Text]; end > len(text) {
+			return fmt.Errorf("anchor %s ends at %d, beyond the %d bytes of %s", n, end, len(text), parents[0])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package entrygen
+
+import (
+	"testing"
+
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	"google.golang.org/protobuf/proto"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestGraph(t *testing.T) {
+	for _, opts := range []*Options{nil, {ReverseEdges: true}, {MaxFiles: 1, MaxNodesPerFile: 1, MaxRefsPerFile: 1}} {
+		Run(t, 50, opts, func(t *testing.T, g *Generator) {
+			entries := g.Graph()
+			if len(entries) == 0 {
+				t.Fatal("Empty graph")
+			}
+			if err := Validate(entries); err != nil {
+				t.Fatalf("Invalid graph: %v", err)
+			}
+			seen := make(map[string]bool)
+			for _, e := range entries {
+				key := key(e)
+				if seen[key] {
+					t.Errorf("Duplicate entry: %v", e)
+				}
+				seen[key] = true
+			}
+		})
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	a, b := New(7, nil), New(7, nil)
+	if x, y := a.Duplicate(a.Graph()), b.Duplicate(b.Graph()); !entriesEqual(x, y) {
+		t.Errorf("Generators with the same seed differ:\n%v\n%v", x, y)
+	}
+	if x, y := New(7, nil).Graph(), New(8, nil).Graph(); entriesEqual(x, y) {
+		t.Error("Generators with different seeds agree")
+	}
+}
+
+func TestDuplicate(t *testing.T) {
+	Run(t, 10, nil, func(t *testing.T, g *Generator) {
+		entries := g.Graph()
+		dups := g.Duplicate(entries)
+		count := make(map[string]int)
+		for _, e := range dups {
+			count[key(e)]++
+		}
+		if len(count) != len(entries) {
+			t.Errorf("Duplicated %d entries to %d distinct entries", len(entries), len(count))
+		}
+		if err := Validate(dups); err != nil {
+			t.Errorf("Invalid duplicated graph: %v", err)
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	file := &spb.VName{Corpus: "c", Path: "p"}
+	anchor := &spb.VName{Corpus: "c", Path: "p", Signature: "a"}
+	node := &spb.VName{Signature: "n"}
+	valid := []*spb.Entry{
+		Fact(file, facts.NodeKind, "file"),
+		Fact(file, facts.Text, "text"),
+		Fact(anchor, facts.NodeKind, "anchor"),
+		Fact(anchor, facts.AnchorStart, "1"),
+		Fact(anchor, facts.AnchorEnd, "4"),
+		Edge(anchor, edges.ChildOf, file),
+		Edge(anchor, edges.Ref, node),
+		Fact(node, facts.NodeKind, "function"),
+	}
+	if err := Validate(valid); err != nil {
+		t.Fatalf("Validate: unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		desc    string
+		entries []*spb.Entry
+	}{
+		{"empty VName", append(valid, Fact(&spb.VName{Corpus: "c"}, facts.NodeKind, "file"))},
+		{"fact with a target", append(valid, &spb.Entry{Source: node, FactName: "f", Target: file})},
+		{"edge without a target", append(valid, &spb.Entry{Source: node, EdgeKind: edges.Ref, FactName: "/"})},
+		{"node without a kind", append(valid, Edge(node, edges.Typed, &spb.VName{Signature: "t"}))},
+		{"anchor beyond text", append(valid, Fact(anchor, facts.AnchorEnd, "5"))},
+		{"anchor without parent", valid[:5]},
+		{"unmirrored edge", append(valid, Edge(file, edges.Mirror(edges.ChildOf), anchor))},
+	}
+	for _, test := range tests {
+		if err := Validate(test.entries); err == nil {
+			t.Errorf("Validate: %s: got no error", test.desc)
+		}
+	}
+}
+
+func entriesEqual(x, y []*spb.Entry) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if !proto.Equal(x[i], y[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// key returns a string identifying e, ignoring its fact value.
+func key(e *spb.Entry) string {
+	return kytheuri.ToString(e.Source) + "\x00" + e.EdgeKind + "\x00" + e.FactName + "\x00" + kytheuri.ToString(e.Target)
+}
@@ -21,12 +21,17 @@ package graphstore // import "kythe.io/kythe/go/test/services/graphstore"
 import (
 	"context"
 	"fmt"
+	"sort"
 	"testing"
 
 	"kythe.io/kythe/go/services/graphstore"
+	"kythe.io/kythe/go/test/entrygen"
+	"kythe.io/kythe/go/test/synthetic"
 	"kythe.io/kythe/go/test/testutil"
 	"kythe.io/kythe/go/util/compare"
 
+	"google.golang.org/protobuf/proto"
+
 	spb "kythe.io/kythe/proto/storage_go_proto"
 )
 
@@ -113,6 +118,80 @@ func OrderTest(t *testing.T, create CreateFunc, batchSize int) {
 		}))
 }
 
+// PropertyTest tests that the graphstore.Service created by create returns
+// each distinct entry written to it exactly once and in entry order, however
+// the entries are ordered, duplicated, and batched when written.
+func PropertyTest(t *testing.T, create CreateFunc) {
+	entrygen.Run(t, 20, nil, func(t *testing.T, g *entrygen.Generator) {
+		gs, destroy, err := create()
+		testutil.Fatalf(t, "CreateFunc error: %v", err)
+		defer func() {
+			testutil.Fatalf(t, "gs close error: %v", gs.Close(ctx))
+			testutil.Fatalf(t, "DestroyFunc error: %v", destroy())
+		}()
+
+		want := g.Graph()
+		written := g.Duplicate(want)
+		for i := 0; i < len(written); {
+			// Batch up to a few consecutive entries with the same source.
+			req := &spb.WriteRequest{Source: written[i].Source}
+			for n := g.Rand().Intn(4) + 1; i < len(written) && len(req.Update) < n && compare.VNamesEqual(req.Source, written[i].Source); i++ {
+				e := written[i]
+				req.Update = append(req.Update, &spb.WriteRequest_Update{
+					EdgeKind:  e.EdgeKind,
+					Target:    e.Target,
+					FactName:  e.FactName,
+					FactValue: e.FactValue,
+				})
+			}
+			testutil.Fatalf(t, "write error: %v", gs.Write(ctx, req))
+		}
+		sort.Sort(compare.ByEntries(want))
+
+		var got []*spb.Entry
+		testutil.Fatalf(t, "scan error: %v", gs.Scan(ctx, new(spb.ScanRequest), func(e *spb.Entry) error {
+			got = append(got, e)
+			return nil
+		}))
+		if err := entriesEqual(got, want); err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+
+		// Reading the edges of a source returns them in entry order.
+		src := want[g.Rand().Intn(len(want))].Source
+		var wantEdges []*spb.Entry
+		for _, e := range want {
+			if e.EdgeKind != "" && compare.VNamesEqual(e.Source, src) {
+				wantEdges = append(wantEdges, e)
+			}
+		}
+		got = nil
+		testutil.Fatalf(t, "read error: %v", gs.Read(ctx, &spb.ReadRequest{Source: src, EdgeKind: "*"}, func(e *spb.Entry) error {
+			if e.EdgeKind != "" {
+				got = append(got, e)
+			}
+			return nil
+		}))
+		if err := entriesEqual(got, wantEdges); err != nil {
+			t.Errorf("Read %v: %v", src, err)
+		}
+	})
+}
+
+// entriesEqual returns an error describing the first difference between got
+// and want.
+func entriesEqual(got, want []*spb.Entry) error {
+	for i := 0; i < len(got) && i < len(want); i++ {
+		if !proto.Equal(got[i], want[i]) {
+			return fmt.Errorf("entry %d: got {%v}, want {%v}", i, got[i], want[i])
+		}
+	}
+	if len(got) != len(want) {
+		return fmt.Errorf("got %d entries, want %d", len(got), len(want))
+	}
+	return nil
+}
+
 var factValue = []byte("factValue")
 
 func randUpdate(u *spb.WriteRequest_Update, size int) {
@@ -130,3 +209,107 @@ func randVName(v *spb.VName, size int) {
 	v.Path = testutil.RandStr(size)
 	v.Language = testutil.RandStr(size)
 }
+
+// writeCorpus writes the entries of the synthetic corpus described by opts to
+// gs, batching consecutive entries with the same source into one request.  It
+// returns the distinct sources written.
+func writeCorpus(gs Service, opts *synthetic.Options) ([]*spb.VName, error) {
+	var sources []*spb.VName
+	var req *spb.WriteRequest
+	flush := func() error {
+		if req == nil {
+			return nil
+		}
+		sources = append(sources, req.Source)
+		return gs.Write(ctx, req)
+	}
+	err := opts.Entries(func(e *spb.Entry) error {
+		if req == nil || !proto.Equal(req.Source, e.Source) {
+			if err := flush(); err != nil {
+				return err
+			}
+			req = &spb.WriteRequest{Source: e.Source}
+		}
+		req.Update = append(req.Update, &spb.WriteRequest_Update{
+			EdgeKind:  e.EdgeKind,
+			Target:    e.Target,
+			FactName:  e.FactName,
+			FactValue: e.FactValue,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sources, flush()
+}
+
+// WriteBenchmark benchmarks writing the synthetic corpus described by opts to
+// a new graphstore.Service.  Each iteration writes the whole corpus to a
+// fresh store.
+func WriteBenchmark(b *testing.B, create CreateFunc, opts *synthetic.Options) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		gs, destroy, err := create()
+		testutil.Fatalf(b, "CreateFunc error: %v", err)
+		b.StartTimer()
+
+		_, err = writeCorpus(gs, opts)
+		testutil.Fatalf(b, "write error: %v", err)
+
+		b.StopTimer()
+		testutil.Fatalf(b, "gs close error: %v", gs.Close(ctx))
+		testutil.Fatalf(b, "DestroyFunc error: %v", destroy())
+		b.StartTimer()
+	}
+	b.ReportMetric(float64(opts.Count()), "entries/op")
+}
+
+// ReadBenchmark benchmarks the Read method of the given graphstore.Service
+// over the synthetic corpus described by opts.  Each iteration reads all the
+// entries of one source node, taking the nodes of the corpus in turn.
+func ReadBenchmark(b *testing.B, create CreateFunc, opts *synthetic.Options) {
+	gs, destroy, err := create()
+	testutil.Fatalf(b, "CreateFunc error: %v", err)
+	defer func() {
+		testutil.Fatalf(b, "gs close error: %v", gs.Close(ctx))
+		testutil.Fatalf(b, "DestroyFunc error: %v", destroy())
+	}()
+	sources, err := writeCorpus(gs, opts)
+	testutil.Fatalf(b, "write error: %v", err)
+
+	var read int
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := &spb.ReadRequest{Source: sources[i%len(sources)], EdgeKind: "*"}
+		testutil.Fatalf(b, "read error: %v", gs.Read(ctx, req, func(*spb.Entry) error {
+			read++
+			return nil
+		}))
+	}
+	b.ReportMetric(float64(read)/float64(b.N), "entries/op")
+}
+
+// ScanBenchmark benchmarks the Scan method of the given graphstore.Service
+// over the synthetic corpus described by opts.  Each iteration scans the
+// whole store.
+func ScanBenchmark(b *testing.B, create CreateFunc, opts *synthetic.Options) {
+	gs, destroy, err := create()
+	testutil.Fatalf(b, "CreateFunc error: %v", err)
+	defer func() {
+		testutil.Fatalf(b, "gs close error: %v", gs.Close(ctx))
+		testutil.Fatalf(b, "DestroyFunc error: %v", destroy())
+	}()
+	_, err = writeCorpus(gs, opts)
+	testutil.Fatalf(b, "write error: %v", err)
+
+	var scanned int
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		testutil.Fatalf(b, "scan error: %v", gs.Scan(ctx, new(spb.ScanRequest), func(*spb.Entry) error {
+			scanned++
+			return nil
+		}))
+	}
+	b.ReportMetric(float64(scanned)/float64(b.N), "entries/op")
+}
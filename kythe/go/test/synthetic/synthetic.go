@@ -0,0 +1,182 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package synthetic generates synthetic Kythe graphs of a representative
+// shape, for benchmarking the services that store and serve them.
+//
+// A synthetic corpus is a tree of files, each defining a number of semantic
+// nodes and referring to nodes defined in other files.  Every file node has
+// a text fact, and every definition and reference is an anchor with a
+// location, so the graph exercises the same entries as an indexer's output.
+// Generation is deterministic for a given Options.
+package synthetic // import "kythe.io/kythe/go/test/synthetic"
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// Options describe the shape of a synthetic corpus.  A zero field takes the
+// default value given for it.
+type Options struct {
+	Corpus       string // corpus of every VName (default "synthetic")
+	Files        int    // number of files (default 100)
+	FilesPerDir  int    // number of files per directory (default 20)
+	DirsPerDir   int    // number of subdirectories per directory (default 8)
+	NodesPerFile int    // number of semantic nodes defined by each file (default 10)
+	RefsPerNode  int    // number of references made to each node (default 3)
+	Seed         int64  // seed for the choice of reference targets
+}
+
+func (o *Options) corpus() string { return orDefault(o.Corpus, "synthetic") }
+func (o *Options) files() int     { return positive(o.Files, 100) }
+func (o *Options) perDir() int    { return positive(o.FilesPerDir, 20) }
+func (o *Options) fanout() int    { return positive(o.DirsPerDir, 8) }
+func (o *Options) nodes() int     { return positive(o.NodesPerFile, 10) }
+func (o *Options) refs() int      { return positive(o.RefsPerNode, 3) }
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+func positive(n, def int) int {
+	if n <= 0 {
+		return def
+	}
+	return n
+}
+
+// File returns the VName of file i of the corpus.  Files are grouped in
+// directories of FilesPerDir, which are arranged in a tree of DirsPerDir
+// subdirectories per directory.  File names repeat across directories.
+func (o *Options) File(i int) *spb.VName {
+	var dirs []string
+	for d := i / o.perDir(); d > 0; d /= o.fanout() {
+		dirs = append(dirs, "dir"+strconv.Itoa(d%o.fanout()))
+	}
+	path := "src/"
+	for j := len(dirs) - 1; j >= 0; j-- {
+		path += dirs[j] + "/"
+	}
+	path += fmt.Sprintf("file%d.go", i%o.perDir())
+	return &spb.VName{Corpus: o.corpus(), Path: path}
+}
+
+// FileVNames returns the VNames of all the files of the corpus.
+func (o *Options) FileVNames() []*spb.VName {
+	files := make([]*spb.VName, o.files())
+	for i := range files {
+		files[i] = o.File(i)
+	}
+	return files
+}
+
+// Node returns the VName of semantic node j of file i.
+func (o *Options) Node(i, j int) *spb.VName {
+	return &spb.VName{
+		Signature: fmt.Sprintf("%s#node%d", o.File(i).Path, j),
+		Corpus:    o.corpus(),
+		Language:  "go",
+	}
+}
+
+// nodeKinds are the kinds assigned to semantic nodes, in rotation.
+var nodeKinds = []string{nodes.Function, nodes.Variable, nodes.Record, nodes.Function}
+
+// Entries calls f with each entry of the corpus, file by file.  If f returns
+// an error, Entries stops and returns it.  Entries are not sorted.
+func (o *Options) Entries(f func(*spb.Entry) error) error {
+	rng := rand.New(rand.NewSource(o.Seed))
+	for i := 0; i < o.files(); i++ {
+		if err := o.fileEntries(i, rng, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *Options) fileEntries(i int, rng *rand.Rand, f func(*spb.Entry) error) error {
+	file := o.File(i)
+	var text strings.Builder
+	var anchors []*spb.Entry
+	anchor := func(target *spb.VName, kind, name string) {
+		start := text.Len()
+		text.WriteString(name)
+		end := text.Len()
+		text.WriteString("\n")
+		a := &spb.VName{
+			Signature: fmt.Sprintf("@%d:%d", start, end),
+			Corpus:    file.Corpus,
+			Path:      file.Path,
+			Language:  "go",
+		}
+		anchors = append(anchors,
+			fact(a, facts.NodeKind, nodes.Anchor),
+			fact(a, facts.AnchorStart, strconv.Itoa(start)),
+			fact(a, facts.AnchorEnd, strconv.Itoa(end)),
+			edge(a, edges.ChildOf, file),
+			edge(a, kind, target))
+	}
+
+	var defs []*spb.Entry
+	for j := 0; j < o.nodes(); j++ {
+		n := o.Node(i, j)
+		defs = append(defs, fact(n, facts.NodeKind, nodeKinds[j%len(nodeKinds)]))
+		anchor(n, edges.DefinesBinding, fmt.Sprintf("func node%d() {}", j))
+	}
+	// Refer to nodes chosen at random from the whole corpus, so that on
+	// average each node is referred to RefsPerNode times.
+	for k := 0; k < o.nodes()*o.refs(); k++ {
+		target := o.Node(rng.Intn(o.files()), rng.Intn(o.nodes()))
+		anchor(target, edges.Ref, "node()")
+	}
+
+	entries := append([]*spb.Entry{
+		fact(file, facts.NodeKind, nodes.File),
+		fact(file, facts.Text, text.String()),
+	}, defs...)
+	for _, e := range append(entries, anchors...) {
+		if err := f(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Count returns the number of entries in the corpus.
+func (o *Options) Count() int {
+	const perAnchor = 5
+	return o.files() * (2 + o.nodes() + o.nodes()*(1+o.refs())*perAnchor)
+}
+
+func fact(v *spb.VName, name, value string) *spb.Entry {
+	return &spb.Entry{Source: v, FactName: name, FactValue: []byte(value)}
+}
+
+func edge(src *spb.VName, kind string, tgt *spb.VName) *spb.Entry {
+	return &spb.Entry{Source: src, EdgeKind: kind, Target: tgt, FactName: "/"}
+}
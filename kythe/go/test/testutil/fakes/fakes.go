@@ -0,0 +1,343 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package fakes provides in-memory fakes of the Kythe service interfaces, so
+// that tools built on them can be unit-tested without LevelDB or HTTP: a
+// filetree.Service, graphstore.Service, graph.Service, xrefs.Service and
+// identifiers.Service (the search service).
+//
+// The fakes serve a small graph described by an Index:
+//
+//	ix := fakes.NewIndex()
+//	ix.Node(fn, nodes.Function).Doc("F does nothing.").Identifier("pkg.F")
+//	ix.File(file, "package pkg\n\nfunc F() {}\n").
+//		Revision("abc123").
+//		AnchorText(edges.DefinesBinding, fn, "F")
+//	xs := ix.XRefs()
+//
+// The xrefs fake serves decorations, definitions, declarations, references
+// and documentation from the anchors and nodes of the Index; it does not
+// serve callers, related nodes or paging.
+//
+// Each fake records the requests made to it, which tests check with
+// matchers, and may be set to fail:
+//
+//	if err := xs.Called("Decorations", fakes.HasTicket(file)); err != nil {
+//		t.Error(err)
+//	}
+//	xs.Fail("CrossReferences", xrefs.ErrPermissionDenied)
+//
+// An Index must not be changed once a fake serves from it.
+package fakes // import "kythe.io/kythe/go/test/testutil/fakes"
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/util/compare"
+	"kythe.io/kythe/go/util/kytheuri"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+	"kythe.io/kythe/go/util/span"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+// An Index is a graph of nodes, files and anchors from which fakes serve.
+// Its builder methods panic on invalid tickets or spans, since these are
+// mistakes in the test that calls them.
+type Index struct {
+	nodes     map[string]*Node
+	order     []*Node // in order of creation
+	files     map[string]*File
+	fileOrder []*File
+}
+
+// NewIndex returns an empty Index.
+func NewIndex() *Index {
+	return &Index{
+		nodes: make(map[string]*Node),
+		files: make(map[string]*File),
+	}
+}
+
+// A Node is a node of an Index.
+type Node struct {
+	Ticket string
+
+	facts map[string][]byte
+	edges []edge
+	doc   string
+	ident string
+}
+
+type edge struct{ kind, target string }
+
+// Node returns the node with the given ticket, adding it to the index if
+// necessary.  If kind is not empty, it is set as the kind of the node.
+func (ix *Index) Node(ticket, kind string) *Node {
+	ticket = canonical(ticket)
+	n := ix.nodes[ticket]
+	if n == nil {
+		n = &Node{Ticket: ticket, facts: make(map[string][]byte)}
+		ix.nodes[ticket] = n
+		ix.order = append(ix.order, n)
+	}
+	if kind != "" {
+		n.facts[facts.NodeKind] = []byte(kind)
+	}
+	return n
+}
+
+// Fact sets a fact of n.
+func (n *Node) Fact(name, value string) *Node {
+	n.facts[name] = []byte(value)
+	return n
+}
+
+// Edge adds an edge of the given kind from n to target.
+func (n *Node) Edge(kind, target string) *Node {
+	n.edges = append(n.edges, edge{kind, canonical(target)})
+	return n
+}
+
+// Doc sets the documentation text of n.
+func (n *Node) Doc(text string) *Node {
+	n.doc = text
+	return n
+}
+
+// Identifier sets the qualified name by which n is found by the identifiers
+// fake.
+func (n *Node) Identifier(qualifiedName string) *Node {
+	n.ident = qualifiedName
+	return n
+}
+
+// A File is a file node of an Index, with the anchors in its text.
+type File struct {
+	*Node
+
+	vname    *spb.VName
+	text     []byte
+	revision string
+	norm     *span.Normalizer
+	anchors  []*anchor
+}
+
+// An anchor is a span of a file with an edge to a target node.
+type anchor struct {
+	ticket     string
+	file       *File
+	kind       string
+	target     string
+	start, end int32
+}
+
+// File adds a file with the given ticket and text to ix.
+func (ix *Index) File(ticket, text string) *File {
+	n := ix.Node(ticket, nodes.File).Fact(facts.Text, text)
+	if f := ix.files[n.Ticket]; f != nil {
+		f.text = []byte(text)
+		f.norm = span.NewNormalizer(f.text)
+		return f
+	}
+	f := &File{
+		Node:  n,
+		vname: mustVName(n.Ticket),
+		text:  []byte(text),
+		norm:  span.NewNormalizer([]byte(text)),
+	}
+	ix.files[n.Ticket] = f
+	ix.fileOrder = append(ix.fileOrder, f)
+	return f
+}
+
+// Revision sets the revision at which f is served.
+func (f *File) Revision(rev string) *File {
+	f.revision = rev
+	return f
+}
+
+// Anchor adds an anchor spanning the byte offsets [start, end) of f, with an
+// edge of the given kind to target.
+func (f *File) Anchor(kind, target string, start, end int) *File {
+	if start < 0 || end < start || end > len(f.text) {
+		panic(fmt.Sprintf("fakes: span [%d, %d) out of bounds of %s", start, end, f.Ticket))
+	}
+	target = canonical(target)
+	v := &spb.VName{
+		Corpus:    f.vname.Corpus,
+		Root:      f.vname.Root,
+		Path:      f.vname.Path,
+		Language:  mustVName(target).Language,
+		Signature: fmt.Sprintf("a%d-%d", start, end),
+	}
+	f.anchors = append(f.anchors, &anchor{
+		ticket: kytheuri.ToString(v),
+		file:   f,
+		kind:   kind,
+		target: target,
+		start:  int32(start),
+		end:    int32(end),
+	})
+	return f
+}
+
+// AnchorText adds an anchor spanning the first occurrence of text in f, as
+// by Anchor.
+func (f *File) AnchorText(kind, target, text string) *File {
+	i := strings.Index(string(f.text), text)
+	if i < 0 {
+		panic(fmt.Sprintf("fakes: %q not found in %s", text, f.Ticket))
+	}
+	return f.Anchor(kind, target, i, i+len(text))
+}
+
+// Entries returns the entries of the graph of ix, in sorted order.
+func (ix *Index) Entries() []*spb.Entry {
+	var entries []*spb.Entry
+	for _, n := range ix.order {
+		src := mustVName(n.Ticket)
+		for name, value := range n.facts {
+			entries = append(entries, &spb.Entry{Source: src, FactName: name, FactValue: value})
+		}
+		for _, e := range n.edges {
+			entries = append(entries, &spb.Entry{Source: src, EdgeKind: e.kind, Target: mustVName(e.target), FactName: "/"})
+		}
+	}
+	for _, f := range ix.fileOrder {
+		for _, a := range f.anchors {
+			src := mustVName(a.ticket)
+			entries = append(entries,
+				&spb.Entry{Source: src, FactName: facts.NodeKind, FactValue: []byte(nodes.Anchor)},
+				&spb.Entry{Source: src, FactName: facts.AnchorStart, FactValue: []byte(fmt.Sprint(a.start))},
+				&spb.Entry{Source: src, FactName: facts.AnchorEnd, FactValue: []byte(fmt.Sprint(a.end))},
+				&spb.Entry{Source: src, EdgeKind: edges.ChildOf, Target: f.vname, FactName: "/"},
+				&spb.Entry{Source: src, EdgeKind: a.kind, Target: mustVName(a.target), FactName: "/"})
+		}
+	}
+	sort.Sort(compare.ByEntries(entries))
+	return entries
+}
+
+// file returns the file with the given ticket, or nil.
+func (ix *Index) file(ticket string) *File { return ix.files[key(ticket)] }
+
+// node returns the node with the given ticket, or nil.
+func (ix *Index) node(ticket string) *Node { return ix.nodes[key(ticket)] }
+
+// anchorsTo returns the anchors with edges to target.
+func (ix *Index) anchorsTo(target string) []*anchor {
+	var as []*anchor
+	target = key(target)
+	for _, f := range ix.fileOrder {
+		for _, a := range f.anchors {
+			if a.target == target {
+				as = append(as, a)
+			}
+		}
+	}
+	return as
+}
+
+// definition returns the binding definition of target, or failing that any
+// definition of it, or nil.
+func (ix *Index) definition(target string) *anchor {
+	var def *anchor
+	for _, a := range ix.anchorsTo(target) {
+		if edges.Canonical(a.kind) == edges.DefinesBinding {
+			return a
+		} else if def == nil && xrefs.IsDefKind(xpb.CrossReferencesRequest_ALL_DEFINITIONS, a.kind, false) {
+			def = a
+		}
+	}
+	return def
+}
+
+// nodeInfo returns the facts of the node with the given ticket that match
+// filters, or nil if there are none.
+func (ix *Index) nodeInfo(ticket string, filters []*regexp.Regexp) *cpb.NodeInfo {
+	n := ix.node(ticket)
+	if n == nil || len(filters) == 0 {
+		return nil
+	}
+	info := &cpb.NodeInfo{Facts: make(map[string][]byte)}
+	for name, value := range n.facts {
+		if xrefs.MatchesAny(name, filters) {
+			info.Facts[name] = value
+		}
+	}
+	if len(info.Facts) == 0 {
+		return nil
+	}
+	return info
+}
+
+// xrefAnchor returns a as an xrefs Anchor, with its snippet (the text of its
+// line) if requested.
+func (a *anchor) xrefAnchor(snippet bool) *xpb.Anchor {
+	xa := &xpb.Anchor{
+		Ticket:   a.ticket,
+		Kind:     a.kind,
+		Parent:   a.file.Ticket,
+		Span:     a.file.norm.SpanOffsets(a.start, a.end),
+		Revision: a.file.revision,
+	}
+	if snippet {
+		text := string(a.file.text)
+		start := strings.LastIndex(text[:a.start], "\n") + 1
+		end := strings.IndexByte(text[a.end:], '\n')
+		if end < 0 {
+			end = len(text)
+		} else {
+			end += int(a.end)
+		}
+		xa.Snippet = text[start:end]
+		xa.SnippetSpan = a.file.norm.SpanOffsets(int32(start), int32(end))
+	}
+	return xa
+}
+
+// key returns the form of ticket by which ix holds its node.
+func key(ticket string) string {
+	if fixed, err := kytheuri.Fix(ticket); err == nil {
+		return fixed
+	}
+	return ticket
+}
+
+// canonical returns the canonical form of a ticket given to a builder.
+func canonical(ticket string) string {
+	if fixed, err := kytheuri.Fix(ticket); err == nil {
+		return fixed
+	}
+	panic(fmt.Sprintf("fakes: invalid ticket %q", ticket))
+}
+
+func mustVName(ticket string) *spb.VName {
+	v, err := kytheuri.ToVName(ticket)
+	if err != nil {
+		panic(fmt.Sprintf("fakes: invalid ticket %q: %v", ticket, err))
+	}
+	return v
+}
@@ -0,0 +1,311 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fakes
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/util/compare"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+	gpb "kythe.io/kythe/proto/graph_go_proto"
+	ipb "kythe.io/kythe/proto/identifier_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+const (
+	fileTicket = "kythe://corpus?path=src/a.go"
+	fTicket    = "kythe://corpus?lang=go?path=src/a.go#F"
+	gTicket    = "kythe://corpus?lang=go?path=src/a.go#G"
+	text       = "package a\n\nfunc F() {}\n\nfunc G() { F() }\n"
+
+	fDef  = "kythe://corpus?lang=go?path=src/a.go#a16-17"
+	fCall = "kythe://corpus?lang=go?path=src/a.go#a35-38"
+)
+
+func testIndex() *Index {
+	ix := NewIndex()
+	ix.Node(fTicket, nodes.Function).Doc("F does nothing.").Identifier("a.F")
+	ix.Node(gTicket, nodes.Function).Identifier("a.G").Edge(edges.ChildOf, fileTicket)
+	call := strings.Index(text, "F()")
+	call = call + 1 + strings.Index(text[call+1:], "F()")
+	ix.File(fileTicket, text).
+		Revision("abc").
+		AnchorText(edges.DefinesBinding, fTicket, "F").
+		AnchorText(edges.DefinesBinding, gTicket, "G").
+		Anchor(edges.RefCall, fTicket, call, call+3)
+	return ix
+}
+
+func sp(start, startLine, startCol, end, endLine, endCol int32) *cpb.Span {
+	return &cpb.Span{
+		Start: &cpb.Point{ByteOffset: start, LineNumber: startLine, ColumnOffset: startCol},
+		End:   &cpb.Point{ByteOffset: end, LineNumber: endLine, ColumnOffset: endCol},
+	}
+}
+
+func TestXRefs(t *testing.T) {
+	ctx := context.Background()
+	xs := testIndex().XRefs()
+
+	decor, err := xs.Decorations(ctx, &xpb.DecorationsRequest{
+		Location:          &xpb.Location{Ticket: fileTicket, Kind: xpb.Location_SPAN, Span: sp(24, 5, 0, 40, 5, 16)},
+		References:        true,
+		TargetDefinitions: true,
+		Filter:            []string{facts.NodeKind},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDecor := &xpb.DecorationsReply{
+		Location: &xpb.Location{Ticket: fileTicket, Kind: xpb.Location_SPAN, Span: sp(24, 5, 0, 40, 5, 16)},
+		Revision: "abc",
+		Reference: []*xpb.DecorationsReply_Reference{{
+			TargetTicket:     gTicket,
+			Kind:             edges.DefinesBinding,
+			Span:             sp(29, 5, 5, 30, 5, 6),
+			TargetDefinition: "kythe://corpus?lang=go?path=src/a.go#a29-30",
+		}, {
+			TargetTicket:     fTicket,
+			Kind:             edges.RefCall,
+			Span:             sp(35, 5, 11, 38, 5, 14),
+			TargetDefinition: fDef,
+		}},
+		Nodes: map[string]*cpb.NodeInfo{
+			gTicket: {Facts: map[string][]byte{facts.NodeKind: []byte(nodes.Function)}},
+			fTicket: {Facts: map[string][]byte{facts.NodeKind: []byte(nodes.Function)}},
+		},
+		DefinitionLocations: map[string]*xpb.Anchor{
+			"kythe://corpus?lang=go?path=src/a.go#a29-30": {
+				Ticket:   "kythe://corpus?lang=go?path=src/a.go#a29-30",
+				Kind:     edges.DefinesBinding,
+				Parent:   fileTicket,
+				Span:     sp(29, 5, 5, 30, 5, 6),
+				Revision: "abc",
+			},
+			fDef: {
+				Ticket:   fDef,
+				Kind:     edges.DefinesBinding,
+				Parent:   fileTicket,
+				Span:     sp(16, 3, 5, 17, 3, 6),
+				Revision: "abc",
+			},
+		},
+	}
+	if diff := compare.ProtoDiff(wantDecor, decor); diff != "" {
+		t.Errorf("Decorations (-want +got):\n%s", diff)
+	}
+	if _, err := xs.Decorations(ctx, &xpb.DecorationsRequest{Location: &xpb.Location{Ticket: "kythe://corpus?path=missing"}}); err == nil {
+		t.Error("Decorations of a missing file: got nil error")
+	}
+
+	xrefs, err := xs.CrossReferences(ctx, &xpb.CrossReferencesRequest{
+		Ticket:         []string{fTicket},
+		DefinitionKind: xpb.CrossReferencesRequest_ALL_DEFINITIONS,
+		ReferenceKind:  xpb.CrossReferencesRequest_CALL_REFERENCES,
+		Snippets:       xpb.SnippetsKind_DEFAULT,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantXRefs := &xpb.CrossReferencesReply{
+		CrossReferences: map[string]*xpb.CrossReferencesReply_CrossReferenceSet{
+			fTicket: {
+				Ticket: fTicket,
+				Definition: []*xpb.CrossReferencesReply_RelatedAnchor{{Anchor: &xpb.Anchor{
+					Ticket:      fDef,
+					Kind:        edges.DefinesBinding,
+					Parent:      fileTicket,
+					Span:        sp(16, 3, 5, 17, 3, 6),
+					Snippet:     "func F() {}",
+					SnippetSpan: sp(11, 3, 0, 22, 3, 11),
+					Revision:    "abc",
+				}}},
+				Reference: []*xpb.CrossReferencesReply_RelatedAnchor{{Anchor: &xpb.Anchor{
+					Ticket:      fCall,
+					Kind:        edges.RefCall,
+					Parent:      fileTicket,
+					Span:        sp(35, 5, 11, 38, 5, 14),
+					Snippet:     "func G() { F() }",
+					SnippetSpan: sp(24, 5, 0, 40, 5, 16),
+					Revision:    "abc",
+				}}},
+			},
+		},
+		Total: &xpb.CrossReferencesReply_Total{Definitions: 1, References: 1},
+	}
+	if diff := compare.ProtoDiff(wantXRefs, xrefs); diff != "" {
+		t.Errorf("CrossReferences (-want +got):\n%s", diff)
+	}
+
+	docs, err := xs.Documentation(ctx, &xpb.DocumentationRequest{Ticket: []string{fTicket, gTicket}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDocs := &xpb.DocumentationReply{Document: []*xpb.DocumentationReply_Document{{
+		Ticket: fTicket,
+		Text:   &xpb.Printable{RawText: "F does nothing."},
+	}}}
+	if diff := compare.ProtoDiff(wantDocs, docs); diff != "" {
+		t.Errorf("Documentation (-want +got):\n%s", diff)
+	}
+
+	errDenied := errors.New("denied")
+	xs.Fail("Documentation", errDenied)
+	if _, err := xs.Documentation(ctx, &xpb.DocumentationRequest{}); err != errDenied {
+		t.Errorf("Documentation after Fail: got error %v, want %v", err, errDenied)
+	}
+	if got := len(xs.Requests("Decorations")); got != 2 {
+		t.Errorf("Decorations requests: got %d, want 2", got)
+	}
+}
+
+func TestGraph(t *testing.T) {
+	ctx := context.Background()
+	g := testIndex().Graph()
+
+	ns, err := g.Nodes(ctx, &gpb.NodesRequest{Ticket: []string{fTicket, "kythe://corpus#missing"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantNodes := &gpb.NodesReply{Nodes: map[string]*cpb.NodeInfo{
+		fTicket: {Facts: map[string][]byte{facts.NodeKind: []byte(nodes.Function)}},
+	}}
+	if diff := compare.ProtoDiff(wantNodes, ns); diff != "" {
+		t.Errorf("Nodes (-want +got):\n%s", diff)
+	}
+
+	es, err := g.Edges(ctx, &gpb.EdgesRequest{Ticket: []string{fileTicket}, Kind: []string{"%" + edges.ChildOf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var targets []string
+	for _, e := range es.EdgeSets[fileTicket].GetGroups()["%"+edges.ChildOf].GetEdge() {
+		targets = append(targets, e.TargetTicket)
+	}
+	if want := []string{fDef, "kythe://corpus?lang=go?path=src/a.go#a29-30", fCall, gTicket}; strings.Join(targets, " ") != strings.Join(want, " ") {
+		t.Errorf("Children of file: got %v, want %v", targets, want)
+	}
+	if got := es.TotalEdgesByKind["%"+edges.ChildOf]; got != 4 {
+		t.Errorf("Total children: got %d, want 4", got)
+	}
+}
+
+func TestIdentifiers(t *testing.T) {
+	ids := testIndex().Identifiers()
+	reply, err := ids.Find(context.Background(), &ipb.FindRequest{Identifier: "a.F", Languages: []string{"go"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ipb.FindReply{Matches: []*ipb.FindReply_Match{{
+		Ticket:        fTicket,
+		NodeKind:      nodes.Function,
+		BaseName:      "F",
+		QualifiedName: "a.F",
+	}}}
+	if diff := compare.ProtoDiff(want, reply); diff != "" {
+		t.Errorf("Find (-want +got):\n%s", diff)
+	}
+	if reply, err := ids.Find(context.Background(), &ipb.FindRequest{Identifier: "a.F", Corpus: []string{"other"}}); err != nil || len(reply.Matches) != 0 {
+		t.Errorf("Find in another corpus: got (%v, %v), want no matches", reply, err)
+	}
+}
+
+func TestFileTree(t *testing.T) {
+	ctx := context.Background()
+	ft := testIndex().FileTree()
+	roots, err := ft.CorpusRoots(ctx, &ftpb.CorpusRootsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots.Corpus) != 1 || roots.Corpus[0].Name != "corpus" {
+		t.Errorf("CorpusRoots: got %v, want corpus", roots)
+	}
+	dir, err := ft.Directory(ctx, &ftpb.DirectoryRequest{Corpus: "corpus", Path: "src"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dir.Entry) != 1 || dir.Entry[0].Name != "a.go" || dir.Entry[0].Kind != ftpb.DirectoryReply_FILE {
+		t.Errorf("Directory of src: got %v, want a.go", dir)
+	}
+}
+
+func TestGraphStore(t *testing.T) {
+	ctx := context.Background()
+	ix := testIndex()
+	gs := ix.GraphStore()
+	var got []*spb.Entry
+	if err := gs.Scan(ctx, new(spb.ScanRequest), func(e *spb.Entry) error {
+		got = append(got, e)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := compare.ProtoDiff(ix.Entries(), got); diff != "" {
+		t.Errorf("Scan (-want +got):\n%s", diff)
+	}
+	// 3 facts and edges of F and G, 2 of the file, and 5 of each anchor.
+	if len(got) != 3+2+3*5 {
+		t.Errorf("Scan: got %d entries, want %d", len(got), 3+2+3*5)
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	ctx := context.Background()
+	xs := testIndex().XRefs()
+	if err := xs.NotCalled("CrossReferences"); err != nil {
+		t.Error(err)
+	}
+	req := &xpb.CrossReferencesRequest{
+		Ticket:         []string{fTicket},
+		DefinitionKind: xpb.CrossReferencesRequest_BINDING_DEFINITIONS,
+		PageSize:       10,
+	}
+	xs.CrossReferences(ctx, req)
+	xs.Decorations(ctx, &xpb.DecorationsRequest{Location: &xpb.Location{Ticket: fileTicket}})
+
+	for _, test := range []struct {
+		method string
+		ms     []Matcher
+		ok     bool
+	}{
+		{"CrossReferences", []Matcher{Equals(req)}, true},
+		{"CrossReferences", []Matcher{HasTicket(fTicket), Partial(&xpb.CrossReferencesRequest{PageSize: 10})}, true},
+		{"CrossReferences", []Matcher{Partial(&xpb.CrossReferencesRequest{PageSize: 20})}, false},
+		{"CrossReferences", []Matcher{HasTicket(gTicket)}, false},
+		{"CrossReferences", []Matcher{Partial(&xpb.DocumentationRequest{})}, false},
+		{"Decorations", []Matcher{HasTicket(fileTicket)}, true},
+		{"Documentation", nil, false},
+	} {
+		if err := xs.Called(test.method, test.ms...); (err == nil) != test.ok {
+			t.Errorf("Called(%q, %d matchers): got error %v, want ok=%v", test.method, len(test.ms), err, test.ok)
+		}
+	}
+
+	xs.Reset()
+	if len(xs.Calls()) != 0 {
+		t.Errorf("Calls after Reset: got %v", xs.Calls())
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fakes
+
+import (
+	"context"
+	"sort"
+
+	"kythe.io/kythe/go/services/filetree"
+
+	ftpb "kythe.io/kythe/proto/filetree_go_proto"
+)
+
+// FileTree is a fake filetree.Service serving the files of an Index.
+type FileTree struct {
+	Recorder
+	m *filetree.Map
+}
+
+var _ filetree.Service = (*FileTree)(nil)
+
+// FileTree returns a fake filetree.Service serving the files of ix.
+func (ix *Index) FileTree() *FileTree {
+	m := filetree.NewMap()
+	u := m.Update()
+	for _, f := range ix.fileOrder {
+		u.AddFile(f.vname)
+	}
+	u.Commit()
+	return &FileTree{m: m}
+}
+
+// Close implements part of the filetree.Service interface.
+func (*FileTree) Close(context.Context) error { return nil }
+
+// CorpusRoots implements part of the filetree.Service interface.  The
+// corpora are sorted by name.
+func (t *FileTree) CorpusRoots(ctx context.Context, req *ftpb.CorpusRootsRequest) (*ftpb.CorpusRootsReply, error) {
+	if err := t.record("CorpusRoots", req); err != nil {
+		return nil, err
+	}
+	reply, err := t.m.CorpusRoots(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(reply.Corpus, func(i, j int) bool { return reply.Corpus[i].Name < reply.Corpus[j].Name })
+	return reply, nil
+}
+
+// Directory implements part of the filetree.Service interface.
+func (t *FileTree) Directory(ctx context.Context, req *ftpb.DirectoryRequest) (*ftpb.DirectoryReply, error) {
+	if err := t.record("Directory", req); err != nil {
+		return nil, err
+	}
+	return t.m.Directory(ctx, req)
+}
+
+// Tree implements part of the filetree.Service interface.
+func (t *FileTree) Tree(ctx context.Context, req *ftpb.TreeRequest) (*ftpb.DirectoryReply, error) {
+	if err := t.record("Tree", req); err != nil {
+		return nil, err
+	}
+	return t.m.Tree(ctx, req)
+}
+
+// Search implements part of the filetree.Service interface.
+func (t *FileTree) Search(ctx context.Context, req *ftpb.SearchRequest) (*ftpb.SearchReply, error) {
+	if err := t.record("Search", req); err != nil {
+		return nil, err
+	}
+	return t.m.Search(ctx, req)
+}
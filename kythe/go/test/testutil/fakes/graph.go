@@ -0,0 +1,133 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fakes
+
+import (
+	"context"
+
+	"kythe.io/kythe/go/services/graph"
+	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/util/schema/edges"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	gpb "kythe.io/kythe/proto/graph_go_proto"
+)
+
+// Graph is a fake graph.Service serving an Index.
+type Graph struct {
+	Recorder
+	ix *Index
+}
+
+var _ graph.Service = (*Graph)(nil)
+
+// Graph returns a fake graph.Service serving ix.
+func (ix *Index) Graph() *Graph { return &Graph{ix: ix} }
+
+// Nodes implements part of the graph.Service interface.  With no filters,
+// each fact of the nodes is returned.
+func (g *Graph) Nodes(_ context.Context, req *gpb.NodesRequest) (*gpb.NodesReply, error) {
+	if err := g.record("Nodes", req); err != nil {
+		return nil, err
+	}
+	filters := req.Filter
+	if len(filters) == 0 {
+		filters = []string{"**"}
+	}
+	patterns := xrefs.ConvertFilters(filters)
+	reply := &gpb.NodesReply{Nodes: make(map[string]*cpb.NodeInfo)}
+	for _, ticket := range req.Ticket {
+		if info := g.ix.nodeInfo(ticket, patterns); info != nil {
+			reply.Nodes[ticket] = info
+		}
+	}
+	return reply, nil
+}
+
+// Edges implements part of the graph.Service interface.  It serves the
+// edges of the nodes of the index in both directions, including those of
+// anchors, in a single page.
+func (g *Graph) Edges(_ context.Context, req *gpb.EdgesRequest) (*gpb.EdgesReply, error) {
+	if err := g.record("Edges", req); err != nil {
+		return nil, err
+	}
+	kinds := make(map[string]bool)
+	for _, k := range req.Kind {
+		kinds[k] = true
+	}
+	filters := xrefs.ConvertFilters(req.Filter)
+	reply := &gpb.EdgesReply{
+		EdgeSets:         make(map[string]*gpb.EdgeSet),
+		TotalEdgesByKind: make(map[string]int64),
+	}
+	for _, ticket := range req.Ticket {
+		set := &gpb.EdgeSet{Groups: make(map[string]*gpb.EdgeSet_Group)}
+		for _, e := range g.ix.edgesOf(key(ticket)) {
+			kind, ordinal, _ := edges.ParseOrdinal(e.kind)
+			if len(kinds) > 0 && !kinds[kind] {
+				continue
+			}
+			grp := set.Groups[kind]
+			if grp == nil {
+				grp = new(gpb.EdgeSet_Group)
+				set.Groups[kind] = grp
+			}
+			grp.Edge = append(grp.Edge, &gpb.EdgeSet_Group_Edge{TargetTicket: e.target, Ordinal: int32(ordinal)})
+			reply.TotalEdgesByKind[kind]++
+			if info := g.ix.nodeInfo(e.target, filters); info != nil {
+				if reply.Nodes == nil {
+					reply.Nodes = make(map[string]*cpb.NodeInfo)
+				}
+				reply.Nodes[e.target] = info
+			}
+		}
+		if len(set.Groups) > 0 {
+			reply.EdgeSets[ticket] = set
+		}
+	}
+	return reply, nil
+}
+
+// edgesOf returns the edges of the node with the given ticket, followed by
+// the reverse of the edges to it.
+func (ix *Index) edgesOf(ticket string) []edge {
+	var es []edge
+	if n := ix.nodes[ticket]; n != nil {
+		es = append(es, n.edges...)
+	}
+	for _, f := range ix.fileOrder {
+		for _, a := range f.anchors {
+			switch ticket {
+			case a.ticket:
+				es = append(es, edge{edges.ChildOf, f.Ticket}, edge{a.kind, a.target})
+			case f.Ticket:
+				es = append(es, edge{edges.Mirror(edges.ChildOf), a.ticket})
+			}
+			if a.target == ticket {
+				es = append(es, edge{edges.Mirror(a.kind), a.ticket})
+			}
+		}
+	}
+	for _, n := range ix.order {
+		for _, e := range n.edges {
+			if e.target == ticket {
+				es = append(es, edge{edges.Mirror(e.kind), n.Ticket})
+			}
+		}
+	}
+	return es
+}
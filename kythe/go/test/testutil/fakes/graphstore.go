@@ -0,0 +1,79 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fakes
+
+import (
+	"context"
+
+	"kythe.io/kythe/go/services/graphstore"
+	"kythe.io/kythe/go/storage/inmemory"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// GraphStore is a fake graphstore.Service holding the entries of an Index.
+// Unlike the other fakes, it may be written to.
+type GraphStore struct {
+	Recorder
+	gs *inmemory.GraphStore
+}
+
+var _ graphstore.Service = (*GraphStore)(nil)
+
+// GraphStore returns a fake graphstore.Service holding the entries of ix.
+func (ix *Index) GraphStore() *GraphStore {
+	gs := new(inmemory.GraphStore)
+	for _, e := range ix.Entries() {
+		gs.Write(context.Background(), &spb.WriteRequest{
+			Source: e.Source,
+			Update: []*spb.WriteRequest_Update{{
+				EdgeKind:  e.EdgeKind,
+				Target:    e.Target,
+				FactName:  e.FactName,
+				FactValue: e.FactValue,
+			}},
+		})
+	}
+	return &GraphStore{gs: gs}
+}
+
+// Close implements part of the graphstore.Service interface.
+func (*GraphStore) Close(context.Context) error { return nil }
+
+// Read implements part of the graphstore.Service interface.
+func (s *GraphStore) Read(ctx context.Context, req *spb.ReadRequest, f graphstore.EntryFunc) error {
+	if err := s.record("Read", req); err != nil {
+		return err
+	}
+	return s.gs.Read(ctx, req, f)
+}
+
+// Scan implements part of the graphstore.Service interface.
+func (s *GraphStore) Scan(ctx context.Context, req *spb.ScanRequest, f graphstore.EntryFunc) error {
+	if err := s.record("Scan", req); err != nil {
+		return err
+	}
+	return s.gs.Scan(ctx, req, f)
+}
+
+// Write implements part of the graphstore.Service interface.
+func (s *GraphStore) Write(ctx context.Context, req *spb.WriteRequest) error {
+	if err := s.record("Write", req); err != nil {
+		return err
+	}
+	return s.gs.Write(ctx, req)
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fakes
+
+import (
+	"context"
+	"strings"
+
+	"kythe.io/kythe/go/serving/identifiers"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	ipb "kythe.io/kythe/proto/identifier_go_proto"
+)
+
+// Identifiers is a fake identifiers.Service serving an Index.
+type Identifiers struct {
+	Recorder
+	ix *Index
+}
+
+var _ identifiers.Service = (*Identifiers)(nil)
+
+// Identifiers returns a fake identifiers.Service serving ix.
+func (ix *Index) Identifiers() *Identifiers { return &Identifiers{ix: ix} }
+
+// Close implements part of the identifiers.Service interface.
+func (*Identifiers) Close(context.Context) error { return nil }
+
+// Find implements part of the identifiers.Service interface.  It matches the
+// nodes given the identifier by Node.Identifier, within the corpora and
+// languages requested.
+func (s *Identifiers) Find(_ context.Context, req *ipb.FindRequest) (*ipb.FindReply, error) {
+	if err := s.record("Find", req); err != nil {
+		return nil, err
+	}
+	reply := new(ipb.FindReply)
+	for _, n := range s.ix.order {
+		if n.ident == "" || n.ident != req.Identifier {
+			continue
+		}
+		v := mustVName(n.Ticket)
+		if !contains(req.Corpus, v.Corpus) || !contains(req.Languages, v.Language) {
+			continue
+		}
+		reply.Matches = append(reply.Matches, &ipb.FindReply_Match{
+			Ticket:        n.Ticket,
+			NodeKind:      string(n.facts[facts.NodeKind]),
+			NodeSubkind:   string(n.facts[facts.Subkind]),
+			BaseName:      baseName(n.ident),
+			QualifiedName: n.ident,
+		})
+	}
+	return reply, nil
+}
+
+// contains reports whether s is in set, or set is empty.
+func contains(set []string, s string) bool {
+	if len(set) == 0 {
+		return true
+	}
+	for _, x := range set {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// baseName returns the last component of a qualified name.
+func baseName(qname string) string {
+	return qname[strings.LastIndexAny(qname, ".:/#")+1:]
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fakes
+
+import (
+	"fmt"
+	"sync"
+
+	"kythe.io/kythe/go/util/compare"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// A Call is a request made to a fake.
+type Call struct {
+	Method  string
+	Request proto.Message
+}
+
+// A Recorder records the requests made to a fake, and holds the errors it
+// has been set to return.  It is embedded in each fake.
+type Recorder struct {
+	mu    sync.Mutex
+	calls []Call
+	fail  map[string]error
+}
+
+// record records a request to method, returning the error the method is set
+// to return.
+func (r *Recorder) record(method string, req proto.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, Call{method, proto.Clone(req)})
+	return r.fail[method]
+}
+
+// Calls returns the requests made to the fake, in order.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// Requests returns the requests made to the given method, in order.
+func (r *Recorder) Requests(method string) []proto.Message {
+	var reqs []proto.Message
+	for _, c := range r.Calls() {
+		if c.Method == method {
+			reqs = append(reqs, c.Request)
+		}
+	}
+	return reqs
+}
+
+// Fail sets method to return err rather than serving requests, or to serve
+// them again if err is nil.
+func (r *Recorder) Fail(method string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		delete(r.fail, method)
+		return
+	}
+	if r.fail == nil {
+		r.fail = make(map[string]error)
+	}
+	r.fail[method] = err
+}
+
+// Reset forgets the requests recorded and the errors set by Fail.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls, r.fail = nil, nil
+}
+
+// Called returns an error unless some request to method satisfies each of
+// the matchers.  The error describes the mismatch of the last request.
+func (r *Recorder) Called(method string, ms ...Matcher) error {
+	reqs := r.Requests(method)
+	if len(reqs) == 0 {
+		return fmt.Errorf("%s was not called", method)
+	}
+	var err error
+	for _, req := range reqs {
+		if err = matchAll(req, ms); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("no request to %s matched (of %d): %v", method, len(reqs), err)
+}
+
+// NotCalled returns an error if method was called.
+func (r *Recorder) NotCalled(method string) error {
+	if n := len(r.Requests(method)); n > 0 {
+		return fmt.Errorf("%s was called %d times", method, n)
+	}
+	return nil
+}
+
+// A Matcher checks a request, returning an error that describes how it
+// differs from what was expected.
+type Matcher func(req proto.Message) error
+
+func matchAll(req proto.Message, ms []Matcher) error {
+	for _, m := range ms {
+		if err := m(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Equals matches requests equal to want.
+func Equals(want proto.Message) Matcher {
+	return func(req proto.Message) error {
+		if diff := compare.ProtoDiff(want, req); diff != "" {
+			return fmt.Errorf("request differs (-want +got):\n%s", diff)
+		}
+		return nil
+	}
+}
+
+// Partial matches requests whose fields equal those set in want, ignoring
+// the fields want leaves unset.
+func Partial(want proto.Message) Matcher {
+	return func(req proto.Message) error {
+		if req.ProtoReflect().Descriptor() != want.ProtoReflect().Descriptor() {
+			return fmt.Errorf("request is a %s, want a %s", req.ProtoReflect().Descriptor().FullName(), want.ProtoReflect().Descriptor().FullName())
+		}
+		got := proto.Clone(req).ProtoReflect()
+		w := want.ProtoReflect()
+		got.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+			if !w.Has(fd) {
+				got.Clear(fd)
+			}
+			return true
+		})
+		return Equals(want)(got.Interface())
+	}
+}
+
+// HasTicket matches requests naming ticket in their ticket field (or, for a
+// decorations request, as the ticket of their location).
+func HasTicket(ticket string) Matcher {
+	return func(req proto.Message) error {
+		for _, t := range tickets(req.ProtoReflect()) {
+			if key(t) == key(ticket) {
+				return nil
+			}
+		}
+		return fmt.Errorf("request does not name %q: %v", ticket, req)
+	}
+}
+
+// tickets returns the values of the ticket field of m and of its location.
+func tickets(m protoreflect.Message) []string {
+	var ts []string
+	fields := m.Descriptor().Fields()
+	if fd := fields.ByName("ticket"); fd != nil && fd.Kind() == protoreflect.StringKind {
+		v := m.Get(fd)
+		if fd.IsList() {
+			for i := 0; i < v.List().Len(); i++ {
+				ts = append(ts, v.List().Get(i).String())
+			}
+		} else {
+			ts = append(ts, v.String())
+		}
+	}
+	if fd := fields.ByName("location"); fd != nil && fd.Kind() == protoreflect.MessageKind && m.Has(fd) {
+		ts = append(ts, tickets(m.Get(fd).Message())...)
+	}
+	return ts
+}
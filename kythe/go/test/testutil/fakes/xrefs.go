@@ -0,0 +1,168 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fakes
+
+import (
+	"context"
+
+	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/util/schema/facts"
+	"kythe.io/kythe/go/util/span"
+
+	cpb "kythe.io/kythe/proto/common_go_proto"
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+// XRefs is a fake xrefs.Service serving an Index.
+type XRefs struct {
+	Recorder
+	ix *Index
+}
+
+var _ xrefs.Service = (*XRefs)(nil)
+
+// XRefs returns a fake xrefs.Service serving ix.
+func (ix *Index) XRefs() *XRefs { return &XRefs{ix: ix} }
+
+// Close implements part of the xrefs.Service interface.
+func (*XRefs) Close(context.Context) error { return nil }
+
+// Decorations implements part of the xrefs.Service interface.  It serves
+// the references of the anchors of a file.
+func (x *XRefs) Decorations(_ context.Context, req *xpb.DecorationsRequest) (*xpb.DecorationsReply, error) {
+	if err := x.record("Decorations", req); err != nil {
+		return nil, err
+	}
+	f := x.ix.file(req.GetLocation().GetTicket())
+	if f == nil {
+		return nil, xrefs.ErrDecorationsNotFound
+	}
+	loc, err := f.norm.Location(req.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &xpb.DecorationsReply{Location: loc, Revision: f.revision}
+	if req.SourceText {
+		reply.SourceText = f.text
+		reply.Encoding = facts.DefaultTextEncoding
+	}
+	if !req.References {
+		return reply, nil
+	}
+	filters := xrefs.ConvertFilters(req.Filter)
+	for _, a := range f.anchors {
+		if loc.Kind == xpb.Location_SPAN && !span.InBounds(req.SpanKind, a.start, a.end, loc.Span.Start.ByteOffset, loc.Span.End.ByteOffset) {
+			continue
+		}
+		ref := &xpb.DecorationsReply_Reference{
+			TargetTicket: a.target,
+			Kind:         a.kind,
+			Span:         f.norm.SpanOffsets(a.start, a.end),
+		}
+		if req.TargetDefinitions {
+			if def := x.ix.definition(a.target); def != nil {
+				ref.TargetDefinition = def.ticket
+				if reply.DefinitionLocations == nil {
+					reply.DefinitionLocations = make(map[string]*xpb.Anchor)
+				}
+				reply.DefinitionLocations[def.ticket] = def.xrefAnchor(false)
+			}
+		}
+		reply.Reference = append(reply.Reference, ref)
+		if info := x.ix.nodeInfo(a.target, filters); info != nil {
+			if reply.Nodes == nil {
+				reply.Nodes = make(map[string]*cpb.NodeInfo)
+			}
+			reply.Nodes[a.target] = info
+		}
+	}
+	return reply, nil
+}
+
+// CrossReferences implements part of the xrefs.Service interface.  It
+// serves the definitions, declarations and references of each node from the
+// anchors with edges to it, in a single page.
+func (x *XRefs) CrossReferences(_ context.Context, req *xpb.CrossReferencesRequest) (*xpb.CrossReferencesReply, error) {
+	if err := x.record("CrossReferences", req); err != nil {
+		return nil, err
+	}
+	reply := &xpb.CrossReferencesReply{
+		CrossReferences: make(map[string]*xpb.CrossReferencesReply_CrossReferenceSet),
+		Total:           new(xpb.CrossReferencesReply_Total),
+	}
+	filters := xrefs.ConvertFilters(req.Filter)
+	snippets := req.Snippets != xpb.SnippetsKind_NONE
+	for _, ticket := range req.Ticket {
+		var incomplete bool
+		if n := x.ix.node(ticket); n != nil {
+			incomplete = string(n.facts[facts.Complete]) == "incomplete"
+		}
+		set := &xpb.CrossReferencesReply_CrossReferenceSet{Ticket: ticket}
+		for _, a := range x.ix.anchorsTo(ticket) {
+			ra := &xpb.CrossReferencesReply_RelatedAnchor{Anchor: a.xrefAnchor(snippets)}
+			switch {
+			case xrefs.IsDeclKind(req.DeclarationKind, a.kind, incomplete):
+				set.Declaration = append(set.Declaration, ra)
+				reply.Total.Declarations++
+			case xrefs.IsDefKind(req.DefinitionKind, a.kind, incomplete):
+				set.Definition = append(set.Definition, ra)
+				reply.Total.Definitions++
+			case xrefs.IsRefKind(req.ReferenceKind, a.kind):
+				set.Reference = append(set.Reference, ra)
+				reply.Total.References++
+			}
+		}
+		if len(set.Declaration)+len(set.Definition)+len(set.Reference) > 0 {
+			reply.CrossReferences[ticket] = set
+		}
+		if info := x.ix.nodeInfo(ticket, filters); info != nil {
+			if reply.Nodes == nil {
+				reply.Nodes = make(map[string]*cpb.NodeInfo)
+			}
+			reply.Nodes[ticket] = info
+		}
+	}
+	return reply, nil
+}
+
+// Documentation implements part of the xrefs.Service interface.  It serves
+// the documentation set by Node.Doc.
+func (x *XRefs) Documentation(_ context.Context, req *xpb.DocumentationRequest) (*xpb.DocumentationReply, error) {
+	if err := x.record("Documentation", req); err != nil {
+		return nil, err
+	}
+	reply := new(xpb.DocumentationReply)
+	filters := xrefs.ConvertFilters(req.Filter)
+	for _, ticket := range req.Ticket {
+		n := x.ix.node(ticket)
+		if n == nil || n.doc == "" {
+			continue
+		}
+		reply.Document = append(reply.Document, &xpb.DocumentationReply_Document{
+			Ticket: ticket,
+			Text:   &xpb.Printable{RawText: n.doc},
+		})
+		if info := x.ix.nodeInfo(ticket, filters); info != nil {
+			if reply.Nodes == nil {
+				reply.Nodes = make(map[string]*cpb.NodeInfo)
+			}
+			reply.Nodes[ticket] = info
+		}
+	}
+	return reply, nil
+}
@@ -0,0 +1,291 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package golden replays recorded requests against a service and compares
+// its replies with checked-in golden files, to lock in the behavior of the
+// service across refactorings.
+//
+// Each case of a test is a pair of files in a directory:
+//
+//	<name>.request.json   {"method": "CrossReferences", "request": {...}}
+//	<name>.golden.json    the expected reply
+//
+// Run calls the named method of a service value, which must have the form
+// func(context.Context, *Request) (*Reply, error), with the request decoded
+// from its proto JSON encoding.  RunHTTP instead sends the request to an
+// http.Handler, with "method" the HTTP method and "path" the URL path:
+//
+//	{"method": "POST", "path": "/xrefs", "request": {...}}
+//
+// A reply is encoded as JSON (as by the Kythe web API) and normalized before
+// it is compared: tickets are canonicalized, the fields named by
+// Options.Ignore are removed, the elements of the lists named by
+// Options.Sort are sorted, and the result is indented with sorted keys.  An
+// error is recorded as {"error": "..."}.
+//
+// Running the test with --update_goldens rewrites the golden files with the
+// normalized replies, for review.  Record writes new cases.
+package golden // import "kythe.io/kythe/go/test/testutil/golden"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/services/web"
+	"kythe.io/kythe/go/util/kytheuri"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+var update = flag.Bool("update_goldens", false, "Rewrite golden files with the replies of the service under test")
+
+// File name suffixes of the files of a case.
+const (
+	RequestSuffix = ".request.json"
+	GoldenSuffix  = ".golden.json"
+)
+
+// A Case is a recorded request.
+type Case struct {
+	Method  string          `json:"method"`
+	Path    string          `json:"path,omitempty"`
+	Request json.RawMessage `json:"request,omitempty"`
+}
+
+// Options control the normalization of replies.
+type Options struct {
+	// Sort names fields, at any depth, whose list elements are sorted (by
+	// their JSON encoding) since their order is unspecified.  "*" sorts
+	// every list.
+	Sort []string
+
+	// Ignore names fields, at any depth, that are removed, such as
+	// timestamps.
+	Ignore []string
+}
+
+// Run replays the cases in dir against the methods of service, each in a
+// subtest, and compares the replies with their goldens.
+func Run(t *testing.T, dir string, service any, opts *Options) {
+	t.Helper()
+	run(t, dir, opts, func(c *Case) ([]byte, error) { return Call(context.Background(), service, c) })
+}
+
+// RunHTTP replays the cases in dir against h, each in a subtest, and
+// compares the replies with their goldens.  The golden of a reply records
+// its status and its body, as JSON if it is JSON.
+func RunHTTP(t *testing.T, dir string, h http.Handler, opts *Options) {
+	t.Helper()
+	run(t, dir, opts, func(c *Case) ([]byte, error) {
+		if c.Path == "" {
+			return nil, errors.New("case has no path")
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(c.Method, c.Path, bytes.NewReader(c.Request)))
+		reply := map[string]any{"status": rec.Code}
+		var body any
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err == nil {
+			reply["body"] = body
+		} else if rec.Body.Len() > 0 {
+			reply["body"] = rec.Body.String()
+		}
+		return json.Marshal(reply)
+	})
+}
+
+func run(t *testing.T, dir string, opts *Options, call func(*Case) ([]byte, error)) {
+	t.Helper()
+	requests, err := filepath.Glob(filepath.Join(dir, "*"+RequestSuffix))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(requests) == 0 {
+		t.Fatalf("No cases in %s", dir)
+	}
+	for _, path := range requests {
+		path := path
+		name := strings.TrimSuffix(filepath.Base(path), RequestSuffix)
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var c Case
+			if err := json.Unmarshal(data, &c); err != nil {
+				t.Fatalf("Decoding %s: %v", path, err)
+			}
+			reply, err := call(&c)
+			if err != nil {
+				t.Fatalf("Replaying %s: %v", path, err)
+			}
+			got, err := Normalize(reply, opts)
+			if err != nil {
+				t.Fatalf("Normalizing reply: %v", err)
+			}
+			golden := filepath.Join(dir, name+GoldenSuffix)
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Reading golden (run with --update_goldens to create it): %v", err)
+			}
+			if diff := cmp.Diff(strings.Split(string(want), "\n"), strings.Split(string(got), "\n")); diff != "" {
+				t.Errorf("Reply differs from %s (-want +got; run with --update_goldens to accept it):\n%s", golden, diff)
+			}
+		})
+	}
+}
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	messageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Call calls the method of service named by c with its request, returning
+// the JSON encoding of the reply or of the error returned.
+func Call(ctx context.Context, service any, c *Case) ([]byte, error) {
+	m := reflect.ValueOf(service).MethodByName(c.Method)
+	if !m.IsValid() {
+		return nil, fmt.Errorf("%T has no method %q", service, c.Method)
+	}
+	mt := m.Type()
+	if mt.NumIn() != 2 || mt.In(0) != contextType || !mt.In(1).Implements(messageType) || mt.In(1).Kind() != reflect.Pointer ||
+		mt.NumOut() != 2 || !mt.Out(0).Implements(messageType) || mt.Out(1) != errorType {
+		return nil, fmt.Errorf("method %s of %T is not of the form func(context.Context, *Request) (*Reply, error)", c.Method, service)
+	}
+	req := reflect.New(mt.In(1).Elem()).Interface().(proto.Message)
+	if len(c.Request) > 0 {
+		if err := protojson.Unmarshal(c.Request, req); err != nil {
+			return nil, fmt.Errorf("decoding request: %v", err)
+		}
+	}
+	out := m.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(req)})
+	if err, _ := out[1].Interface().(error); err != nil {
+		return json.Marshal(map[string]string{"error": err.Error()})
+	}
+	reply, err := web.JSONMarshaler.MarshalToString(out[0].Interface().(proto.Message))
+	return []byte(reply), err
+}
+
+// Record writes the request file of a case to dir, for a call of method with
+// req (such as one recorded by a fake of package fakes).
+func Record(dir, name, method string, req proto.Message) error {
+	rec, err := web.JSONMarshaler.MarshalToString(req)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(&Case{Method: method, Request: json.RawMessage(rec)}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name+RequestSuffix), append(data, '\n'), 0644)
+}
+
+// Normalize returns the normalized form of the JSON value data.
+func Normalize(data []byte, opts *Options) ([]byte, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	n := &normalizer{sort: make(map[string]bool), ignore: make(map[string]bool)}
+	if opts != nil {
+		for _, f := range opts.Sort {
+			n.sort[f] = true
+		}
+		for _, f := range opts.Ignore {
+			n.ignore[f] = true
+		}
+	}
+	out, err := json.MarshalIndent(n.value("", v), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+type normalizer struct{ sort, ignore map[string]bool }
+
+// value returns the normalization of v, the value of the named field.
+func (n *normalizer) value(field string, v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, val := range v {
+			if !n.ignore[k] {
+				m[ticket(k)] = n.value(k, val)
+			}
+		}
+		return m
+	case []any:
+		l := make([]any, len(v))
+		for i, val := range v {
+			l[i] = n.value(field, val)
+		}
+		if n.sort[field] || n.sort["*"] {
+			keys := make([]string, len(l))
+			for i, val := range l {
+				data, _ := json.Marshal(val)
+				keys[i] = string(data)
+			}
+			sort.Sort(byKey{keys, l})
+		}
+		return l
+	case string:
+		return ticket(v)
+	default:
+		return v
+	}
+}
+
+// ticket returns the canonical form of s if it is a ticket, or else s.
+func ticket(s string) string {
+	if strings.HasPrefix(s, "kythe:") {
+		if fixed, err := kytheuri.Fix(s); err == nil {
+			return fixed
+		}
+	}
+	return s
+}
+
+type byKey struct {
+	keys []string
+	vals []any
+}
+
+func (b byKey) Len() int           { return len(b.keys) }
+func (b byKey) Less(i, j int) bool { return b.keys[i] < b.keys[j] }
+func (b byKey) Swap(i, j int) {
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+	b.vals[i], b.vals[j] = b.vals[j], b.vals[i]
+}
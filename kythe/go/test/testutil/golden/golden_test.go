@@ -0,0 +1,116 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package golden
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kythe.io/kythe/go/services/xrefs"
+	"kythe.io/kythe/go/test/testutil/fakes"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/nodes"
+
+	"github.com/google/go-cmp/cmp"
+
+	xpb "kythe.io/kythe/proto/xref_go_proto"
+)
+
+const (
+	fileTicket = "kythe://corpus?path=src/a.go"
+	fTicket    = "kythe://corpus?lang=go?path=src/a.go#F"
+	text       = "package a\n\nfunc F() {}\n\nfunc G() { F() }\n"
+)
+
+func testIndex() *fakes.Index {
+	ix := fakes.NewIndex()
+	ix.Node(fTicket, nodes.Function).Doc("F does nothing.")
+	ix.File(fileTicket, text).
+		AnchorText(edges.DefinesBinding, fTicket, "F").
+		Anchor(edges.RefCall, fTicket, 35, 38)
+	return ix
+}
+
+func TestRun(t *testing.T) {
+	Run(t, "testdata/xrefs", testIndex().XRefs(), &Options{Sort: []string{"*"}})
+}
+
+func TestRunHTTP(t *testing.T) {
+	mux := http.NewServeMux()
+	xrefs.RegisterHTTPHandlers(context.Background(), testIndex().XRefs(), mux)
+	RunHTTP(t, "testdata/http", mux, &Options{Sort: []string{"*"}})
+}
+
+func TestCall(t *testing.T) {
+	ctx := context.Background()
+	xs := testIndex().XRefs()
+	if _, err := Call(ctx, xs, &Case{Method: "NoSuchMethod"}); err == nil {
+		t.Error("Call of a missing method succeeded")
+	}
+	if _, err := Call(ctx, xs, &Case{Method: "Requests"}); err == nil {
+		t.Error("Call of a method of the wrong form succeeded")
+	}
+	if _, err := Call(ctx, xs, &Case{Method: "Decorations", Request: []byte(`{"bogus": 1}`)}); err == nil {
+		t.Error("Call with an invalid request succeeded")
+	}
+}
+
+func TestRecord(t *testing.T) {
+	dir := t.TempDir()
+	req := &xpb.DecorationsRequest{Location: &xpb.Location{Ticket: fileTicket}, References: true}
+	if err := Record(dir, "decor", "Decorations", req); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := Call(context.Background(), testIndex().XRefs(), &Case{Method: "Decorations", Request: []byte(`{"location": {"ticket": "kythe://corpus?path=src/a.go"}, "references": true}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := Normalize(reply, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "decor"+GoldenSuffix), golden, 0644); err != nil {
+		t.Fatal(err)
+	}
+	Run(t, dir, testIndex().XRefs(), nil)
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+		opts     *Options
+	}{
+		{in: `{"b": 1, "a": [2, 1]}`, want: "{\n  \"a\": [\n    2,\n    1\n  ],\n  \"b\": 1\n}\n"},
+		{in: `{"a": [2, 1], "b": [2, 1]}`, opts: &Options{Sort: []string{"a"}},
+			want: "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"b\": [\n    2,\n    1\n  ]\n}\n"},
+		{in: `[{"t": 1, "n": "x"}]`, opts: &Options{Ignore: []string{"t"}},
+			want: "[\n  {\n    \"n\": \"x\"\n  }\n]\n"},
+		{in: `{"kythe://c?path=p?lang=l#s": ["kythe://c?path=p?lang=l", "kythe:bogus", "plain"]}`,
+			want: "{\n  \"kythe://c?lang=l?path=p#s\": [\n    \"kythe://c?lang=l?path=p\",\n    \"kythe:bogus\",\n    \"plain\"\n  ]\n}\n"},
+	}
+	for _, test := range tests {
+		got, err := Normalize([]byte(test.in), test.opts)
+		if err != nil {
+			t.Errorf("Normalize(%s): %v", test.in, err)
+		} else if diff := cmp.Diff(test.want, string(got)); diff != "" {
+			t.Errorf("Normalize(%s): (-want +got)\n%s", test.in, diff)
+		}
+	}
+}
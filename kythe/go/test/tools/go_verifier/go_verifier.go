@@ -0,0 +1,114 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary go_verifier reads a stream of entries (in delimited binary proto
+// form) from stdin and checks that they satisfy the goals written in the
+// comments of the given files.  It accepts the assertion language and the
+// principal flags of the C++ verifier; see kythe/docs/kythe-verifier.txt.
+//
+// Example:
+//
+//	go_indexer unit.kzip | go_verifier --goal_regex '\s*//\s*-(.*)' foo.go
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+
+	"kythe.io/kythe/go/storage/stream"
+	"kythe.io/kythe/go/test/verifier"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+
+	"google.golang.org/protobuf/encoding/prototext"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+var (
+	goalPrefix      = flag.String("goal_prefix", "//-", "Denotes lines holding goals")
+	goalRegex       = flag.String("goal_regex", "", "If set, a regular expression matching entire goal lines, with one capture group for the goals (overrides --goal_prefix)")
+	checkSingletons = flag.Bool("check_for_singletons", false, "Fail if an evar is mentioned only once")
+	useFileNodes    = flag.Bool("use_file_nodes", false, "Read goals from the text of the file nodes in the entry stream")
+	showGoals       = flag.Bool("show_goals", false, "Print the goals after parsing them")
+	showProtos      = flag.Bool("show_protos", false, "Print the entries as they are read")
+)
+
+func init() {
+	flag.Usage = flagutil.SimpleUsage(
+		"Verify that the entries read from stdin satisfy the goals in the given files",
+		"[--goal_prefix p | --goal_regex re]", "[--use_file_nodes]", "[goal-file...]")
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() == 0 && !*useFileNodes {
+		flagutil.UsageError("no goal files given")
+	}
+
+	opts := &verifier.Options{
+		GoalRegexp:      verifier.GoalPrefixRegexp(*goalPrefix),
+		CheckSingletons: *checkSingletons,
+	}
+	if *goalRegex != "" {
+		re, err := regexp.Compile(*goalRegex)
+		if err != nil {
+			flagutil.UsageErrorf("invalid --goal_regex: %v", err)
+		}
+		opts.GoalRegexp = re
+	}
+	v := verifier.New(opts)
+
+	rd := stream.NewReader(bufio.NewReaderSize(os.Stdin, 2*4096))
+	if err := rd(func(e *spb.Entry) error {
+		if *showProtos {
+			fmt.Println(prototext.Format(e))
+		}
+		return v.AddEntry(e)
+	}); err != nil {
+		log.Fatalf("Reading entries: %v", err)
+	}
+
+	for _, path := range flag.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := v.AddFile(path, data); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *useFileNodes {
+		if err := v.AddFileNodes(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *showGoals {
+		for _, g := range v.Goals() {
+			fmt.Println(g)
+		}
+	}
+
+	if err := v.Verify(func(in verifier.Inspection) {
+		fmt.Printf("%s: %s\n", in.Label, in.Value)
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,529 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package verifier
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A Pos is a position in a goal file.
+type Pos struct {
+	File string
+	Line int // 1-based
+	Col  int // 1-based, in bytes
+}
+
+func (p Pos) String() string { return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Col) }
+
+// An Error reports a problem with a goal.
+type Error struct {
+	Pos     Pos
+	Message string
+}
+
+func (e *Error) Error() string { return e.Pos.String() + ": " + e.Message }
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokHashNumber // #N, selecting a match of a location specifier
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string // for tokString, the unescaped value
+	raw  string // the source text
+	pos  Pos
+}
+
+// A line of a goal file.
+type line struct {
+	text   string
+	offset int  // byte offset of the start of the line in the file
+	goal   bool // whether the line holds goals
+}
+
+// splitLines splits content into lines, marking those that match the goal
+// expression of v.
+func (v *Verifier) splitLines(content string) []line {
+	var lines []line
+	for off := 0; off < len(content) || off == 0; {
+		text := content[off:]
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			text = text[:i]
+		}
+		lines = append(lines, line{text: text, offset: off})
+		off += len(text) + 1
+	}
+	for i, ln := range lines {
+		if m := v.goalRE.FindStringSubmatchIndex(ln.text); m != nil && m[0] == 0 && m[1] == len(ln.text) {
+			lines[i].goal = true
+		}
+	}
+	return lines
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '/' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool { return isIdentStart(c) || isDigit(c) }
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+// lex appends the tokens of the goal text s, which begins at pos, to toks.
+// Text following "//" is a comment.
+func lex(s string, pos Pos, toks []token) ([]token, error) {
+	for i := 0; i < len(s); {
+		c := s[i]
+		p := pos
+		p.Col += i
+		if c == ' ' || c == '\t' || c == '\r' {
+			i++
+			continue
+		} else if strings.HasPrefix(s[i:], "//") {
+			break
+		}
+
+		// Find the kind, value, and end of the token at i.
+		var kind tokenKind
+		var text string
+		j := i + 1
+		switch {
+		case c == '"':
+			n, val, err := unquote(s[i:])
+			if err != nil {
+				return nil, &Error{p, err.Error()}
+			}
+			kind, text, j = tokString, val, i+n
+		case c == '@' && j < len(s) && (s[j] == '^' || s[j] == '$'):
+			kind, j = tokPunct, j+1
+		case c == '#' && j < len(s) && (isDigit(s[j]) || s[j] == ' ' || s[j] == '\t'):
+			for j < len(s) && (s[j] == ' ' || s[j] == '\t') {
+				j++
+			}
+			k := j
+			for j < len(s) && isDigit(s[j]) {
+				j++
+			}
+			if j == k {
+				return nil, &Error{p, "expected number after #"}
+			}
+			kind, text = tokHashNumber, s[k:j]
+		case isDigit(c):
+			for j < len(s) && isDigit(s[j]) {
+				j++
+			}
+			kind = tokNumber
+		case c == '_' && (j == len(s) || !isIdentChar(s[j])):
+			kind = tokPunct
+		case isIdentStart(c) || ((c == '%' || c == '#') && j < len(s) && isIdentStart(s[j])):
+			for j < len(s) && isIdentChar(s[j]) {
+				j++
+			}
+			kind = tokIdent
+		case strings.IndexByte("(),@.?={}!:+", c) >= 0:
+			kind = tokPunct
+		default:
+			return nil, &Error{p, fmt.Sprintf("invalid character %q", c)}
+		}
+		if text == "" && kind != tokString {
+			text = s[i:j]
+		}
+		toks = append(toks, token{kind: kind, text: text, raw: s[i:j], pos: p})
+		i = j
+	}
+	return toks, nil
+}
+
+// unquote parses the string literal at the start of s, which permits only
+// the escapes \" and \\, and returns its length and value.
+func unquote(s string) (int, string, error) {
+	var val strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return i + 1, val.String(), nil
+		case '\\':
+			if i+1 < len(s) && (s[i+1] == '"' || s[i+1] == '\\') {
+				i++
+				val.WriteByte(s[i])
+				continue
+			}
+			return 0, "", fmt.Errorf("invalid escape in string literal")
+		default:
+			val.WriteByte(s[i])
+		}
+	}
+	return 0, "", fmt.Errorf("unterminated string literal")
+}
+
+// A location records an unresolved location specifier (@tok, @^tok, @$tok).
+type location struct {
+	pos      Pos
+	token    string
+	line     int  // the 1-based line on which to match, if fixed
+	next     bool // match on the first non-goal line following pos
+	match    int  // the ordinal of the match to use, if selected
+	selected bool
+
+	// The evars to bind to the start and end offsets of the match, if any.
+	start, end *evar
+}
+
+// A parser holds the state of parsing the goals of one file.
+type parser struct {
+	v     *Verifier
+	lines []line
+	toks  []token
+	i     int
+	group *group
+	locs  []*location
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) isPunct(s string) bool {
+	t := p.peek()
+	return t.kind == tokPunct && t.text == s
+}
+
+func (p *parser) errorf(t token, msg string, args ...any) error {
+	if t.kind == tokEOF {
+		msg += " at end of goals"
+	}
+	return &Error{t.pos, fmt.Sprintf(msg, args...)}
+}
+
+// text returns the source text of the tokens from index i to the current
+// position, for use in messages.
+func (p *parser) text(i int) string {
+	var parts []string
+	for toks := p.toks[i:p.i]; len(toks) > 0; {
+		j := 1
+		for j < len(toks) && toks[j].pos.Line == toks[0].pos.Line {
+			j++
+		}
+		first, last := toks[0], toks[j-1]
+		ln := p.lines[first.pos.Line-1].text
+		parts = append(parts, ln[first.pos.Col-1:last.pos.Col-1+len(last.raw)])
+		toks = toks[j:]
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseGoals parses the goals until the end of input.
+func (p *parser) parseGoals() error {
+	for p.peek().kind != tokEOF {
+		negated := p.isPunct("!")
+		if negated || p.isPunct("{") {
+			start := p.next()
+			if negated {
+				if t := p.next(); t.kind != tokPunct || t.text != "{" {
+					return p.errorf(t, "expected { after !")
+				}
+			}
+			main := p.group
+			p.group = &group{pos: start.pos, negated: negated}
+			for !p.isPunct("}") {
+				if p.isPunct("{") || p.isPunct("!") {
+					return p.errorf(p.peek(), "goal groups may not be nested")
+				} else if p.peek().kind == tokEOF {
+					return p.errorf(p.peek(), "unterminated goal group")
+				}
+				if err := p.parseGoal(); err != nil {
+					return err
+				}
+			}
+			p.next()
+			if len(p.group.goals) == 0 {
+				return p.errorf(p.toks[p.i-1], "empty goal group")
+			}
+			p.v.groups = append(p.v.groups, p.group)
+			p.group = main
+			continue
+		}
+		if err := p.parseGoal(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parser) stringOrIdent() (token, bool) {
+	t := p.peek()
+	if t.kind == tokIdent || t.kind == tokString {
+		return p.next(), true
+	}
+	return t, false
+}
+
+// parseGoal parses an edge goal (exp kind[.ordinal] exp) or a fact goal
+// (exp.name exp) and adds it to the current group.
+func (p *parser) parseGoal() error {
+	start := p.i
+	pos := p.peek().pos
+	lhs, err := p.parseExp()
+	if err != nil {
+		return err
+	}
+	g := &goal{pos: pos}
+	if p.isPunct(".") {
+		p.next()
+		name, ok := p.stringOrIdent()
+		if !ok {
+			return p.errorf(name, "expected fact name")
+		}
+		val, err := p.parseExp()
+		if err != nil {
+			return err
+		}
+		g.args = []term{lhs, ident(""), ident(""), pathIdent(name.text, "/kythe/"), val}
+	} else {
+		kind, ok := p.stringOrIdent()
+		if !ok {
+			return p.errorf(kind, "expected edge kind or fact name")
+		}
+		edge := pathIdent(kind.text, "/kythe/edge/")
+		var ordinal term
+		if p.isPunct(".") {
+			p.next()
+			if ordinal, err = p.parseAtom(); err != nil {
+				return err
+			}
+		}
+		rhs, err := p.parseExp()
+		if err != nil {
+			return err
+		}
+		if ordinal != nil {
+			g.args = []term{lhs, edge, rhs, ident("/kythe/ordinal"), ordinal}
+		} else {
+			g.args = []term{lhs, edge, rhs, ident("/"), ident("")}
+		}
+	}
+	g.text = p.text(start)
+	p.group.goals = append(p.group.goals, g)
+	return nil
+}
+
+// pathIdent expands an edge kind or fact name to its full form, adding root
+// unless it begins with "/" (after an optional "%" or "#" sigil).
+func pathIdent(frag, root string) ident {
+	if frag == "" {
+		return "/"
+	}
+	var sigil string
+	if frag[0] == '%' || frag[0] == '#' {
+		sigil, frag = frag[:1], frag[1:]
+		if frag == "" {
+			return ident(sigil)
+		}
+	}
+	if !strings.HasPrefix(frag, "/") {
+		frag = root + frag
+	}
+	return ident(sigil + frag)
+}
+
+// parseExp parses an atom, optionally applied to a tuple or equated with
+// another expression.
+func (p *parser) parseExp() (term, error) {
+	start, pos := p.i, p.peek().pos
+	a, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.isPunct("("):
+		head, ok := a.(ident)
+		if !ok {
+			return nil, &Error{pos, "only identifiers may be applied"}
+		}
+		p.next()
+		app := &app{head: head}
+		for !p.isPunct(")") {
+			if len(app.args) > 0 {
+				if t := p.next(); t.kind != tokPunct || t.text != "," {
+					return nil, p.errorf(t, "expected , or )")
+				}
+			}
+			arg, err := p.parseExp()
+			if err != nil {
+				return nil, err
+			}
+			app.args = append(app.args, arg)
+		}
+		p.next()
+		return app, nil
+
+	case p.isPunct("="):
+		eq := p.next()
+		rhs, err := p.parseExp()
+		if err != nil {
+			return nil, err
+		}
+		p.group.goals = append(p.group.goals, &goal{pos: eq.pos, text: p.text(start), eq: true, args: []term{a, rhs}})
+		return a, nil
+	}
+	return a, nil
+}
+
+// parseAtom parses an identifier, literal, evar, or location specifier.
+func (p *parser) parseAtom() (term, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString, tokNumber:
+		return ident(t.text), nil
+	case tokIdent:
+		a := p.v.atom(t.text, t.pos)
+		if p.isPunct("?") {
+			p.next()
+			return p.v.inspect(t.text, a, t.pos)
+		}
+		return a, nil
+	case tokPunct:
+		switch t.text {
+		case "_":
+			e := p.v.newEVar("_", t.pos)
+			if p.isPunct("?") {
+				p.next()
+				return p.v.inspect("_", e, t.pos)
+			}
+			return e, nil
+		case "@", "@^", "@$":
+			return p.parseLocation(t)
+		}
+	}
+	return nil, p.errorf(t, "unexpected %q", t.text)
+}
+
+// parseLocation parses the location specifier following the @, @^, or @$
+// token at.  An anchor specifier generates goals constraining a fresh evar to
+// be an anchor spanning the match; an offset specifier is an evar bound to the
+// offset of the match once it is resolved.
+func (p *parser) parseLocation(at token) (term, error) {
+	loc := &location{pos: at.pos, next: true}
+	if t := p.peek(); t.kind == tokHashNumber {
+		p.next()
+		loc.match, _ = strconv.Atoi(t.text)
+		loc.selected = true
+	}
+	if p.isPunct(":") || p.isPunct("+") {
+		rel := p.next().text == "+"
+		n := p.next()
+		if n.kind != tokNumber {
+			return nil, p.errorf(n, "expected line number")
+		}
+		num, _ := strconv.Atoi(n.text)
+		loc.next = false
+		if loc.line = num; rel {
+			loc.line += at.pos.Line
+		}
+	}
+	tok, ok := p.stringOrIdent()
+	if !ok {
+		return nil, p.errorf(tok, "expected token to match")
+	}
+	loc.token = tok.text
+	p.locs = append(p.locs, loc)
+
+	switch at.text {
+	case "@^":
+		loc.start = p.v.newEVar("", at.pos)
+		return loc.start, nil
+	case "@$":
+		loc.end = p.v.newEVar("", at.pos)
+		return loc.end, nil
+	}
+	anchor := p.v.newEVar("", at.pos)
+	loc.start, loc.end = p.v.newEVar("", at.pos), p.v.newEVar("", at.pos)
+	p.group.goals = append(p.group.goals,
+		&goal{pos: at.pos, text: "@" + loc.token + " start", args: []term{anchor, ident(""), ident(""), ident("/kythe/loc/start"), loc.start}},
+		&goal{pos: at.pos, text: "@" + loc.token + " end", args: []term{anchor, ident(""), ident(""), ident("/kythe/loc/end"), loc.end}},
+		&goal{pos: at.pos, text: "@" + loc.token + " anchor", args: []term{anchor, ident(""), ident(""), ident("/kythe/node/kind"), ident("anchor")}},
+	)
+	return anchor, nil
+}
+
+// resolve binds the evars of each location specifier to the offsets of its
+// match in lines.
+func (p *parser) resolve() []error {
+	lines := p.lines
+	var errs []error
+	for _, loc := range p.locs {
+		n := loc.line
+		if loc.next {
+			for n = loc.pos.Line + 1; n <= len(lines) && lines[n-1].goal; n++ {
+			}
+		}
+		if n <= loc.pos.Line || n > len(lines) || lines[n-1].goal {
+			errs = append(errs, &Error{loc.pos, fmt.Sprintf("%q: no source line %d to match", loc.token, n)})
+			continue
+		}
+		ln := lines[n-1]
+		var cols []int
+		for i := 0; ; {
+			j := strings.Index(ln.text[i:], loc.token)
+			if j < 0 || loc.token == "" {
+				break
+			}
+			cols = append(cols, i+j)
+			i += j + 1
+		}
+		var col int
+		switch {
+		case len(cols) == 0:
+			errs = append(errs, &Error{loc.pos, fmt.Sprintf("%q not found on line %d", loc.token, n)})
+			continue
+		case loc.selected:
+			if loc.match >= len(cols) {
+				errs = append(errs, &Error{loc.pos, fmt.Sprintf("%q has no match #%d on line %d", loc.token, loc.match, n)})
+				continue
+			}
+			col = cols[loc.match]
+		case len(cols) > 1:
+			errs = append(errs, &Error{loc.pos, fmt.Sprintf("%q is ambiguous on line %d", loc.token, n)})
+			continue
+		default:
+			col = cols[0]
+		}
+		start := ln.offset + col
+		if loc.start != nil {
+			loc.start.value = ident(strconv.Itoa(start))
+		}
+		if loc.end != nil {
+			loc.end.value = ident(strconv.Itoa(start + len(loc.token)))
+		}
+	}
+	return errs
+}
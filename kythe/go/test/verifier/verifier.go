@@ -0,0 +1,522 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package verifier checks that a stream of Kythe entries supports the goals
+// written in the comments of source files.  It implements the assertion
+// language of the C++ verifier (see kythe/docs/kythe-verifier.txt), including
+// evars, anchor and offset specifiers, edge and fact goals, explicit
+// unification, inspections, and negated goal groups, so that indexers written
+// in Go can be tested end to end without the C++ toolchain.
+//
+// Example:
+//
+//	v := verifier.New(nil)
+//	if err := v.AddFile("test.go", src); err != nil { ... }
+//	for _, e := range entries {
+//	  if err := v.AddEntry(e); err != nil { ... }
+//	}
+//	if err := v.Verify(nil); err != nil { ... }
+package verifier // import "kythe.io/kythe/go/test/verifier"
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// DefaultGoalRegexp matches the lines holding goals by default: those whose
+// first non-blank text is "//-".
+var DefaultGoalRegexp = regexp.MustCompile(`\s*//-(.*)`)
+
+// GoalPrefixRegexp returns a goal expression matching lines whose first
+// non-blank text is prefix, for example "#-".
+func GoalPrefixRegexp(prefix string) *regexp.Regexp {
+	return regexp.MustCompile(`\s*` + regexp.QuoteMeta(prefix) + `(.*)`)
+}
+
+// Options control the behavior of a Verifier.
+type Options struct {
+	// An expression that matches each entire line holding goals, with a single
+	// capture group for the goal text.  If nil, DefaultGoalRegexp is used.
+	GoalRegexp *regexp.Regexp
+
+	// If set, it is an error for a named evar to be mentioned only once and
+	// never inspected, which usually indicates a typo.
+	CheckSingletons bool
+}
+
+// An Inspection reports the value of an evar inspected with "?".
+type Inspection struct {
+	Label string
+	Pos   Pos
+	Value string // the value of the evar, or its name if it is unbound
+}
+
+// A Verifier holds a database of entries and a set of goals.
+type Verifier struct {
+	goalRE          *regexp.Regexp
+	checkSingletons bool
+
+	evars       map[string]*evar // named evars, shared across files
+	mentions    map[*evar]int
+	main        *group // goals not in any group
+	groups      []*group
+	inspections []*inspection
+
+	facts  map[string][]*fact // by edge kind and fact name; see factKey
+	values map[string]string  // fact values by factID
+}
+
+// New returns an empty Verifier with the given options (which may be nil).
+func New(opts *Options) *Verifier {
+	v := &Verifier{
+		goalRE:   DefaultGoalRegexp,
+		evars:    make(map[string]*evar),
+		mentions: make(map[*evar]int),
+		main:     new(group),
+		facts:    make(map[string][]*fact),
+		values:   make(map[string]string),
+	}
+	if opts != nil {
+		if opts.GoalRegexp != nil {
+			v.goalRE = opts.GoalRegexp
+		}
+		v.checkSingletons = opts.CheckSingletons
+	}
+	return v
+}
+
+// A term of the assertion language.
+type term interface{ String() string }
+
+// An ident is a constant: an atom, a string literal, or a number.
+type ident string
+
+func (i ident) String() string { return strconv.Quote(string(i)) }
+
+// An evar is an existential variable.
+type evar struct {
+	name  string // empty for evars generated by the verifier
+	pos   Pos
+	value term // nil if unbound
+}
+
+func (e *evar) String() string {
+	if t := deref(e); t != term(e) {
+		return t.String()
+	} else if e.name != "" {
+		return e.name
+	}
+	return "_"
+}
+
+// An app is an identifier applied to a tuple, such as vname(...).
+type app struct {
+	head ident
+	args []term
+}
+
+func (a *app) String() string {
+	args := make([]string, len(a.args))
+	for i, arg := range a.args {
+		args[i] = arg.String()
+	}
+	return string(a.head) + "(" + strings.Join(args, ", ") + ")"
+}
+
+// deref returns the value of t, following bound evars.
+func deref(t term) term {
+	for {
+		e, ok := t.(*evar)
+		if !ok || e.value == nil {
+			return t
+		}
+		t = e.value
+	}
+}
+
+// A goal is a fact pattern fact(source, edge, target, name, value), or an
+// equality between two terms.
+type goal struct {
+	pos  Pos
+	text string
+	eq   bool
+	args []term
+}
+
+// A group of goals, solved after the ungrouped goals.  A negated group
+// succeeds if at least one of its goals cannot be satisfied.
+type group struct {
+	pos     Pos
+	negated bool
+	goals   []*goal
+}
+
+type inspection struct {
+	label string
+	evar  *evar
+	pos   Pos
+}
+
+// A fact of the database, in the same form as a fact goal.
+type fact [5]term
+
+func factKey(edge, name ident) string { return string(edge) + "\x00" + string(name) }
+
+// factID identifies the fact with the given source and target vname keys.
+func factID(source, edge, target, name string) string {
+	return strings.Join([]string{source, edge, target, name}, "\x00")
+}
+
+// atom returns the term for an identifier: an evar if it begins with a
+// capital letter (shared by all mentions) or an underscore (fresh), and
+// otherwise a constant.
+func (v *Verifier) atom(name string, pos Pos) term {
+	r, _ := utf8.DecodeRuneInString(name)
+	switch {
+	case r == '_':
+		return v.newEVar(name, pos)
+	case unicode.IsUpper(r):
+		e, ok := v.evars[name]
+		if !ok {
+			e = v.newEVar(name, pos)
+			v.evars[name] = e
+		}
+		v.mentions[e]++
+		return e
+	}
+	return ident(name)
+}
+
+func (v *Verifier) newEVar(name string, pos Pos) *evar { return &evar{name: name, pos: pos} }
+
+func (v *Verifier) inspect(label string, t term, pos Pos) (term, error) {
+	e, ok := t.(*evar)
+	if !ok {
+		return nil, &Error{pos, fmt.Sprintf("cannot inspect %s, which is not an evar", label)}
+	}
+	v.inspections = append(v.inspections, &inspection{label: label, evar: e, pos: pos})
+	return e, nil
+}
+
+// AddFile parses the goals in the content of the file at path.  Location
+// specifiers are matched against the non-goal lines of the same file.
+func (v *Verifier) AddFile(path string, content []byte) error {
+	if n := v.goalRE.NumSubexp(); n != 1 {
+		return fmt.Errorf("goal expression %q has %d capture groups, want 1", v.goalRE, n)
+	}
+	lines := v.splitLines(string(content))
+	var toks []token
+	for i, ln := range lines {
+		if !ln.goal {
+			continue
+		}
+		m := v.goalRE.FindStringSubmatchIndex(ln.text)
+		if m[2] < 0 {
+			continue
+		}
+		var err error
+		toks, err = lex(ln.text[m[2]:m[3]], Pos{File: path, Line: i + 1, Col: m[2] + 1}, toks)
+		if err != nil {
+			return err
+		}
+	}
+	toks = append(toks, token{kind: tokEOF, pos: Pos{File: path, Line: len(lines), Col: 1}})
+	p := &parser{v: v, lines: lines, toks: toks, group: v.main}
+	if err := p.parseGoals(); err != nil {
+		return err
+	}
+	return errors.Join(p.resolve()...)
+}
+
+// Goals returns a description of each goal, in the order they are solved.
+func (v *Verifier) Goals() []string {
+	var out []string
+	for _, g := range v.main.goals {
+		out = append(out, fmt.Sprintf("%v: %s", g.pos, g.text))
+	}
+	for _, grp := range v.groups {
+		prefix := "{"
+		if grp.negated {
+			prefix = "!{"
+		}
+		for _, g := range grp.goals {
+			out = append(out, fmt.Sprintf("%v: %s %s }", g.pos, prefix, g.text))
+		}
+	}
+	return out
+}
+
+func vnameTerm(v *spb.VName) term {
+	return &app{head: "vname", args: []term{
+		ident(v.GetSignature()),
+		ident(v.GetCorpus()),
+		ident(v.GetRoot()),
+		ident(v.GetPath()),
+		ident(v.GetLanguage()),
+	}}
+}
+
+func vnameKey(v *spb.VName) string {
+	return strings.Join([]string{v.GetSignature(), v.GetCorpus(), v.GetRoot(), v.GetPath(), v.GetLanguage()}, "\x00")
+}
+
+// AddEntry adds an entry to the database, after checking that it is well
+// formed.  An edge kind with an ordinal suffix ("param.0") is stored as an
+// edge with a /kythe/ordinal fact, to match goals of the form "param.N".
+func (v *Verifier) AddEntry(e *spb.Entry) error {
+	if vnameKey(e.GetSource()) == vnameKey(nil) {
+		return fmt.Errorf("entry has an empty source: %v", e)
+	}
+	edge, name, value := e.GetEdgeKind(), e.GetFactName(), string(e.GetFactValue())
+	target := term(ident(""))
+	if edge != "" {
+		if vnameKey(e.GetTarget()) == vnameKey(nil) {
+			return fmt.Errorf("edge has an empty target: %v", e)
+		} else if name != "/" || value != "" {
+			return fmt.Errorf("edge has a fact other than \"/\": %v", e)
+		}
+		target = vnameTerm(e.GetTarget())
+		if i := strings.LastIndexByte(edge, '.'); i > 0 && i < len(edge)-1 {
+			edge, name, value = edge[:i], "/kythe/ordinal", edge[i+1:]
+		}
+	} else if e.GetTarget() != nil && vnameKey(e.GetTarget()) != vnameKey(nil) {
+		return fmt.Errorf("fact has a target but no edge kind: %v", e)
+	} else if name == "" {
+		return fmt.Errorf("fact has an empty name: %v", e)
+	}
+
+	// Duplicate entries are ignored, but each node may have only one value for
+	// a fact.
+	key := factID(vnameKey(e.GetSource()), edge, vnameKey(e.GetTarget()), name)
+	if edge != "" {
+		key += "\x00" + value
+	}
+	if old, ok := v.values[key]; ok {
+		if old != value {
+			return fmt.Errorf("conflicting values for fact %s of %v", name, e.GetSource())
+		}
+		return nil
+	}
+	v.values[key] = value
+
+	fk := factKey(ident(edge), ident(name))
+	v.facts[fk] = append(v.facts[fk], &fact{vnameTerm(e.GetSource()), ident(edge), target, ident(name), ident(value)})
+	return nil
+}
+
+// AddFileNodes parses the goals in the text of each file node in the
+// database, as if each were passed to AddFile with the path of its VName.
+func (v *Verifier) AddFileNodes() error {
+	files := make(map[string]string) // path → text
+	for _, f := range v.facts[factKey("", "/kythe/node/kind")] {
+		if f[4] != ident("file") {
+			continue
+		}
+		src := f[0].(*app)
+		var key []string
+		for _, arg := range src.args {
+			key = append(key, string(arg.(ident)))
+		}
+		if text, ok := v.values[factID(strings.Join(key, "\x00"), "", vnameKey(nil), "/kythe/text")]; ok {
+			files[key[3]] = text
+		}
+	}
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := v.AddFile(path, []byte(files[path])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Verify attempts to satisfy all the goals against the database.  If it
+// succeeds, it returns nil; otherwise it returns an error describing the goal
+// that could not be satisfied.  If the ungrouped goals are satisfied, the
+// value of each inspected evar is passed to inspect, if it is non-nil.
+func (v *Verifier) Verify(inspect func(Inspection)) error {
+	if v.checkSingletons {
+		var errs []error
+		inspected := make(map[*evar]bool)
+		for _, in := range v.inspections {
+			inspected[in.evar] = true
+		}
+		for name, e := range v.evars {
+			if v.mentions[e] == 1 && !inspected[e] {
+				errs = append(errs, &Error{e.pos, fmt.Sprintf("%s is mentioned only once", name)})
+			}
+		}
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+	}
+
+	s := &solver{v: v}
+	defer s.undo(0)
+	var err error
+	if !s.solve(v.main.goals, 0, func() bool {
+		err = s.solveGroups(v.groups)
+		if inspect != nil {
+			for _, in := range v.inspections {
+				inspect(Inspection{Label: in.label, Pos: in.pos, Value: in.evar.String()})
+			}
+		}
+		return true
+	}) {
+		return s.failure(v.main.goals)
+	}
+	return err
+}
+
+// A solver searches for an assignment of evars satisfying a list of goals.
+type solver struct {
+	v       *Verifier
+	trail   []*evar // evars bound during the search, in order
+	reached int     // the index of the furthest goal attempted
+}
+
+func (s *solver) failure(goals []*goal) error {
+	g := goals[s.reached]
+	return &Error{g.pos, "could not satisfy goal: " + g.text}
+}
+
+// solve satisfies goals[i:] and then calls k, backtracking to find another
+// assignment if k returns false.  It reports whether k returned true, in
+// which case the bindings made are kept.
+func (s *solver) solve(goals []*goal, i int, k func() bool) bool {
+	if i == len(goals) {
+		return k()
+	}
+	if i > s.reached {
+		s.reached = i
+	}
+	g := goals[i]
+	mark := len(s.trail)
+	if g.eq {
+		if s.unify(g.args[0], g.args[1]) && s.solve(goals, i+1, k) {
+			return true
+		}
+		s.undo(mark)
+		return false
+	}
+	edge, _ := deref(g.args[1]).(ident)
+	name, _ := deref(g.args[3]).(ident)
+	for _, f := range s.v.facts[factKey(edge, name)] {
+		if s.unifyFact(g.args, f) && s.solve(goals, i+1, k) {
+			return true
+		}
+		s.undo(mark)
+	}
+	return false
+}
+
+func (s *solver) unifyFact(args []term, f *fact) bool {
+	for i, arg := range args {
+		if !s.unify(arg, f[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// solveGroups solves each group in turn, without undoing the bindings of the
+// ungrouped goals or of earlier groups.
+func (s *solver) solveGroups(groups []*group) error {
+	for _, grp := range groups {
+		s.reached = 0
+		mark := len(s.trail)
+		ok := s.solve(grp.goals, 0, func() bool { return true })
+		if grp.negated {
+			s.undo(mark)
+			if ok {
+				return &Error{grp.pos, "negated goal group was satisfied"}
+			}
+		} else if !ok {
+			return s.failure(grp.goals)
+		}
+	}
+	return nil
+}
+
+// unify attempts to make a and b equal by binding evars, and reports whether
+// it succeeded.  On failure, some bindings may remain; see undo.
+func (s *solver) unify(a, b term) bool {
+	a, b = deref(a), deref(b)
+	if a == b {
+		return true
+	}
+	if e, ok := a.(*evar); ok {
+		return s.bind(e, b)
+	} else if e, ok := b.(*evar); ok {
+		return s.bind(e, a)
+	}
+	x, ok1 := a.(*app)
+	y, ok2 := b.(*app)
+	if !ok1 || !ok2 || x.head != y.head || len(x.args) != len(y.args) {
+		return false
+	}
+	for i := range x.args {
+		if !s.unify(x.args[i], y.args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// bind sets the value of e to t, unless that would create a cycle.
+func (s *solver) bind(e *evar, t term) bool {
+	if occurs(e, t) {
+		return false
+	}
+	e.value = t
+	s.trail = append(s.trail, e)
+	return true
+}
+
+func occurs(e *evar, t term) bool {
+	switch t := deref(t).(type) {
+	case *evar:
+		return t == e
+	case *app:
+		for _, arg := range t.args {
+			if occurs(e, arg) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// undo unbinds the evars bound since the trail had length mark.
+func (s *solver) undo(mark int) {
+	for _, e := range s.trail[mark:] {
+		e.value = nil
+	}
+	s.trail = s.trail[:mark]
+}
@@ -0,0 +1,332 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package verifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"kythe.io/kythe/go/indexer"
+
+	"github.com/google/go-cmp/cmp"
+
+	apb "kythe.io/kythe/proto/analysis_go_proto"
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+const source = `//- @foo defines/binding Foo
+//- Foo.node/kind function
+//- Foo param.0 Arg?
+//- !{ Foo param.1 _ }
+def foo(bar):
+  //- Call=@#0"foo" ref/call Foo
+  //- Call.loc/end @$#0foo // a comment
+  return foo(bar) + foo
+`
+
+var (
+	fooV = &spb.VName{Corpus: "c", Signature: "foo"}
+	barV = &spb.VName{Corpus: "c", Signature: "bar"}
+)
+
+func nodeFact(src *spb.VName, name, value string) *spb.Entry {
+	return &spb.Entry{Source: src, FactName: name, FactValue: []byte(value)}
+}
+
+func edge(src *spb.VName, kind string, tgt *spb.VName) *spb.Entry {
+	return &spb.Entry{Source: src, EdgeKind: kind, Target: tgt, FactName: "/"}
+}
+
+// anchor returns the entries for an anchor spanning the first occurrence of
+// text in source, with an edge of the given kind to target.
+func anchor(text, kind string, target *spb.VName) []*spb.Entry {
+	start := strings.Index(source, text)
+	v := &spb.VName{Corpus: "c", Path: "a.py", Signature: "@" + strconv.Itoa(start)}
+	return []*spb.Entry{
+		nodeFact(v, "/kythe/node/kind", "anchor"),
+		nodeFact(v, "/kythe/loc/start", strconv.Itoa(start)),
+		nodeFact(v, "/kythe/loc/end", strconv.Itoa(start+3)),
+		edge(v, kind, target),
+	}
+}
+
+// entries returns the entries satisfying the goals of source.
+func entries() []*spb.Entry {
+	es := []*spb.Entry{
+		nodeFact(fooV, "/kythe/node/kind", "function"),
+		edge(fooV, "/kythe/edge/param.0", barV),
+	}
+	es = append(es, anchor("foo(bar):", "/kythe/edge/defines/binding", fooV)...)
+	return append(es, anchor("foo(bar) +", "/kythe/edge/ref/call", fooV)...)
+}
+
+func newVerifier(t *testing.T, opts *Options, es []*spb.Entry) *Verifier {
+	t.Helper()
+	v := New(opts)
+	for _, e := range es {
+		if err := v.AddEntry(e); err != nil {
+			t.Fatalf("AddEntry: %v", err)
+		}
+	}
+	return v
+}
+
+func TestVerify(t *testing.T) {
+	v := newVerifier(t, nil, entries())
+	if err := v.AddFile("a.py", []byte(source)); err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	var got []Inspection
+	if err := v.Verify(func(in Inspection) { got = append(got, in) }); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	want := []Inspection{{
+		Label: "Arg",
+		Pos:   Pos{File: "a.py", Line: 3, Col: 17},
+		Value: `vname("bar", "c", "", "", "")`,
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Inspections (-want +got):\n%s", diff)
+	}
+}
+
+func TestVerifyFailure(t *testing.T) {
+	tests := []struct {
+		desc  string
+		extra *spb.Entry
+		skip  string // an edge kind or node kind to drop
+		want  string
+	}{
+		{desc: "missing fact", skip: "function", want: "a.py:2:5: could not satisfy goal: Foo.node/kind function"},
+		{desc: "missing edge", skip: "/kythe/edge/ref/call", want: `a.py:6:7: could not satisfy goal: Call=@#0"foo" ref/call Foo`},
+		{desc: "negated group", extra: edge(fooV, "/kythe/edge/param.1", barV), want: "a.py:4:5: negated goal group was satisfied"},
+	}
+	for _, test := range tests {
+		es := entries()
+		if test.extra != nil {
+			es = append(es, test.extra)
+		}
+		v := New(nil)
+		for _, e := range es {
+			if test.skip != "" && (e.EdgeKind == test.skip || string(e.FactValue) == test.skip) {
+				continue
+			}
+			if err := v.AddEntry(e); err != nil {
+				t.Fatalf("AddEntry: %v", err)
+			}
+		}
+		if err := v.AddFile("a.py", []byte(source)); err != nil {
+			t.Fatalf("AddFile: %v", err)
+		}
+		if err := v.Verify(nil); err == nil || err.Error() != test.want {
+			t.Errorf("Verify (%s): got error %v, want %q", test.desc, err, test.want)
+		}
+	}
+}
+
+func TestUnification(t *testing.T) {
+	v := newVerifier(t, nil, entries())
+	goals := `#- Foo=vname("foo", Corpus, _, _, _) param.Ord? Bar
+#- Foo.node/kind function
+#- Bar=vname(_, Corpus, "", "", "") ref/call Foo?
+`
+	if err := v.AddFile("goals", []byte(goals)); err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	// The default expression does not match "#-" lines, so there are no goals.
+	if got := v.Goals(); len(got) != 0 {
+		t.Fatalf("Goals with the default expression: got %q, want none", got)
+	}
+
+	v = newVerifier(t, &Options{GoalRegexp: GoalPrefixRegexp("#-")}, entries())
+	if err := v.AddFile("goals", []byte(goals)); err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	// No call from bar to foo exists.
+	if err := v.Verify(nil); err == nil || !strings.Contains(err.Error(), "goals:3:4:") {
+		t.Errorf("Verify: got error %v, want failure at line 3", err)
+	}
+
+	// Each anchor in the corpus of foo calls it.
+	v = newVerifier(t, &Options{GoalRegexp: GoalPrefixRegexp("#-")}, entries())
+	goals = strings.Replace(goals, `Bar=vname(_, Corpus, "", "", "")`, `vname(_, Corpus, _, "a.py", _)`, 1)
+	if err := v.AddFile("goals", []byte(goals)); err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	var got []string
+	if err := v.Verify(func(in Inspection) { got = append(got, in.Label+"="+in.Value) }); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	want := []string{`Ord="0"`, `Foo=vname("foo", "c", "", "", "")`}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Inspections (-want +got):\n%s", diff)
+	}
+}
+
+func TestCycle(t *testing.T) {
+	v := newVerifier(t, &Options{GoalRegexp: GoalPrefixRegexp("#-")}, entries())
+	if err := v.AddFile("goals", []byte(`#- Foo param.0 X = vname(_, _, X, _, _)`)); err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	if err := v.Verify(nil); err == nil {
+		t.Error("Verify: got nil error for a cyclic unification")
+	}
+}
+
+func TestSingletons(t *testing.T) {
+	const goals = "//- Foo param.0 Typo\n//- Foo.node/kind function\n//- Foo param.0 _Ignored\n"
+	if err := newVerifier(t, nil, entries()).AddFile("a", []byte(goals)); err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	v := newVerifier(t, &Options{CheckSingletons: true}, entries())
+	if err := v.AddFile("a", []byte(goals)); err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	if err := v.Verify(nil); err == nil || err.Error() != "a:1:17: Typo is mentioned only once" {
+		t.Errorf("Verify: got error %v, want singleton Typo", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct{ goals, want string }{
+		{"//- Foo\nx\n", "a:2:1: expected edge kind or fact name at end of goals"},
+		{"//- @x defines Foo\nx x\n", `a:1:5: "x" is ambiguous on line 2`},
+		{"//- @#2x defines Foo\nx x\n", `a:1:5: "x" has no match #2 on line 2`},
+		{"//- @y defines Foo\nx\n", `a:1:5: "y" not found on line 2`},
+		{"//- @:1x defines Foo\nx\n", `a:1:5: "x": no source line 1 to match`},
+		{"//- @x defines Foo\n", `a:1:5: "x": no source line 2 to match`},
+		{"//- !{ A b C \n//- { D e F } }\n", "a:2:5: goal groups may not be nested"},
+		{"//- A b \"unterminated\n", "a:1:9: unterminated string literal"},
+		{"//- A b \"\\n\"\n", "a:1:9: invalid escape in string literal"},
+		{"//- A b C; D e F\n", "a:1:10: invalid character ';'"},
+		{"//- x? b C\n", `a:1:5: cannot inspect x, which is not an evar`},
+	}
+	for _, test := range tests {
+		err := New(nil).AddFile("a", []byte(test.goals))
+		if err == nil || err.Error() != test.want {
+			t.Errorf("AddFile(%q): got error %v, want %q", test.goals, err, test.want)
+		}
+	}
+}
+
+func TestLocations(t *testing.T) {
+	const src = "//- A b @^+2x\n//- A b @$:4\"y z\"\n" + // line 1, 2
+		"x\n" + // 3, offset 32
+		"  y z  \n" // 4, offset 34
+	v := New(nil)
+	if err := v.AddFile("a", []byte(src)); err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	want := []string{`a:1:5: A b @^+2x`, `a:2:5: A b @$:4"y z"`}
+	if diff := cmp.Diff(want, v.Goals()); diff != "" {
+		t.Errorf("Goals (-want +got):\n%s", diff)
+	}
+	var got []string
+	for _, g := range v.main.goals {
+		got = append(got, g.args[2].String())
+	}
+	if diff := cmp.Diff([]string{`"32"`, `"39"`}, got); diff != "" {
+		t.Errorf("Offsets (-want +got):\n%s", diff)
+	}
+}
+
+func TestAddEntry(t *testing.T) {
+	bad := []*spb.Entry{
+		{FactName: "/kythe/node/kind", FactValue: []byte("file")},
+		{Source: fooV},
+		{Source: fooV, EdgeKind: "/kythe/edge/ref", FactName: "/"},
+		{Source: fooV, EdgeKind: "/kythe/edge/ref", Target: barV, FactName: "/kythe/text"},
+		{Source: fooV, Target: barV, FactName: "/kythe/text"},
+	}
+	for _, e := range bad {
+		if err := New(nil).AddEntry(e); err == nil {
+			t.Errorf("AddEntry(%v): got nil error", e)
+		}
+	}
+
+	v := New(nil)
+	for _, e := range []*spb.Entry{
+		nodeFact(fooV, "/kythe/node/kind", "function"),
+		nodeFact(fooV, "/kythe/node/kind", "function"),
+		edge(fooV, "/kythe/edge/param.0", barV),
+		edge(fooV, "/kythe/edge/param.1", barV),
+	} {
+		if err := v.AddEntry(e); err != nil {
+			t.Errorf("AddEntry(%v): %v", e, err)
+		}
+	}
+	if n := len(v.facts[factKey("", "/kythe/node/kind")]); n != 1 {
+		t.Errorf("Duplicate fact was stored %d times, want 1", n)
+	}
+	if err := v.AddEntry(nodeFact(fooV, "/kythe/node/kind", "record")); err == nil {
+		t.Error("AddEntry with a conflicting fact value: got nil error")
+	}
+}
+
+type memFetcher map[string][]byte
+
+func (m memFetcher) Fetch(_, digest string) ([]byte, error) { return m[digest], nil }
+
+// TestGoIndexer verifies the output of the Go indexer for a small package,
+// reading the goals from its file node.
+func TestGoIndexer(t *testing.T) {
+	const src = `package p
+
+// - @F defines/binding Fn
+// - Fn.node/kind function
+// - @x defines/binding X
+// - X childof Fn
+func F(x int) int {
+	// - @x ref X
+	return x
+}
+`
+	sum := sha256.Sum256([]byte(src))
+	digest := hex.EncodeToString(sum[:])
+	unit := &apb.CompilationUnit{
+		VName: &spb.VName{Language: "go", Corpus: "test", Path: "p", Signature: "package"},
+		RequiredInput: []*apb.CompilationUnit_FileInput{{
+			VName: &spb.VName{Corpus: "test", Path: "p/p.go"},
+			Info:  &apb.FileInfo{Path: "p/p.go", Digest: digest},
+		}},
+		SourceFile: []string{"p/p.go"},
+	}
+	pi, err := indexer.Resolve(unit, memFetcher{digest: []byte(src)}, &indexer.ResolveOptions{Info: indexer.XRefTypeInfo()})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	v := New(&Options{GoalRegexp: regexp.MustCompile(`\s*//\s*-(.*)`), CheckSingletons: true})
+	if err := pi.Emit(context.Background(), func(_ context.Context, e *spb.Entry) error {
+		return v.AddEntry(e)
+	}, nil); err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+	if err := v.AddFileNodes(); err != nil {
+		t.Fatalf("AddFileNodes: %v", err)
+	}
+	if n := len(v.Goals()); n != 14 {
+		t.Errorf("Goals: got %d, want 14", n)
+	}
+	if err := v.Verify(nil); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+}
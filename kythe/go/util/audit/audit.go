@@ -0,0 +1,360 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package audit keeps an append-only log of who performed which
+// administrative and write operations, and when.
+//
+// A Log appends each Event as a line of JSON to a file, syncing it to disk
+// before Record returns.  Once the file reaches a size limit it is rotated:
+// renamed with the time of rotation appended to its name, and a new file
+// started in its place.  Rotated files are kept unless a limit on their
+// number is set.  Read returns the events of a log and its rotated files in
+// the order they were recorded.
+//
+// The actor performing an operation travels with its context: WithActor
+// attaches it, and Actor returns it, defaulting to the user and host running
+// the process.
+package audit // import "kythe.io/kythe/go/util/audit"
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// An Event records one operation.
+type Event struct {
+	Time   time.Time `json:"time"`
+	Actor  string    `json:"actor"`            // who performed the operation
+	Remote string    `json:"remote,omitempty"` // the address they performed it from
+	Action string    `json:"action"`           // e.g. "reload" or "graphstore.write"
+	Target string    `json:"target,omitempty"` // what the operation applied to
+
+	Details map[string]any `json:"details,omitempty"`
+	Error   string         `json:"error,omitempty"` // if the operation failed
+}
+
+// String returns a one-line description of e.
+func (e *Event) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s", e.Time.Format(time.RFC3339), e.Actor)
+	if e.Remote != "" {
+		fmt.Fprintf(&b, " (%s)", e.Remote)
+	}
+	fmt.Fprintf(&b, " %s", e.Action)
+	if e.Target != "" {
+		fmt.Fprintf(&b, " %s", e.Target)
+	}
+	keys := make([]string, 0, len(e.Details))
+	for k := range e.Details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%v", k, e.Details[k])
+	}
+	if e.Error != "" {
+		fmt.Fprintf(&b, " FAILED: %s", e.Error)
+	}
+	return b.String()
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx attributing the operations performed with
+// it to actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the actor attached to ctx by WithActor, or else the user and
+// host running the process.
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok {
+		return actor
+	}
+	return processActor()
+}
+
+var processActor = sync.OnceValue(func() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return name
+	}
+	return name + "@" + host
+})
+
+// DefaultMaxSize is the default for Options.MaxSize.
+const DefaultMaxSize = 64 << 20
+
+// Options control a Log.
+type Options struct {
+	// MaxSize is the size in bytes beyond which the log is rotated.  If zero,
+	// DefaultMaxSize is used.
+	MaxSize int64
+
+	// MaxFiles, if positive, is the number of rotated files kept; older
+	// files are removed.  By default, every rotated file is kept.
+	MaxFiles int
+}
+
+// A Log is an append-only audit log.  A log file should be written by only
+// one process at a time.  A nil *Log records nothing, so that auditing may be
+// optional.
+type Log struct {
+	path string
+	opts Options
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// Open opens the audit log at path for appending, creating it if needed.
+// The options may be nil.
+func Open(path string, opts *Options) (*Log, error) {
+	l := &Log{path: path}
+	if opts != nil {
+		l.opts = *opts
+	}
+	if l.opts.MaxSize <= 0 {
+		l.opts.MaxSize = DefaultMaxSize
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Log) open() error {
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %v", err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("opening audit log: %v", err)
+	}
+	l.f, l.size = f, fi.Size()
+	return nil
+}
+
+// Record appends e to the log, setting its Time if unset, and returns once it
+// is written to disk.
+func (l *Log) Record(e *Event) error {
+	if l == nil {
+		return nil
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = e.Time.UTC()
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding audit event: %v", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return errors.New("audit log is closed")
+	}
+	if l.size > 0 && l.size+int64(len(line)) > l.opts.MaxSize {
+		if err := l.rotate(e.Time); err != nil {
+			return err
+		}
+	}
+	n, err := l.f.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("writing audit log: %v", err)
+	}
+	if err := l.f.Sync(); err != nil {
+		return fmt.Errorf("syncing audit log: %v", err)
+	}
+	return nil
+}
+
+// RecordContext records an event for action on target by the actor of ctx.
+// If err is non-nil, the event records the failure.
+func (l *Log) RecordContext(ctx context.Context, action, target string, details map[string]any, err error) error {
+	e := &Event{
+		Actor:   Actor(ctx),
+		Action:  action,
+		Target:  target,
+		Details: details,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return l.Record(e)
+}
+
+// rotatedSuffix is the layout of the time appended to rotated files.  It
+// sorts in chronological order.
+const rotatedSuffix = "20060102T150405.000000000Z"
+
+func (l *Log) rotate(now time.Time) error {
+	if err := l.f.Close(); err != nil {
+		return fmt.Errorf("closing audit log for rotation: %v", err)
+	}
+	l.f = nil
+	rotated := l.path + "." + now.UTC().Format(rotatedSuffix)
+	if err := os.Rename(l.path, rotated); err != nil {
+		return fmt.Errorf("rotating audit log: %v", err)
+	}
+	if err := l.open(); err != nil {
+		return err
+	}
+	if l.opts.MaxFiles > 0 {
+		files, err := rotatedFiles(l.path)
+		if err != nil {
+			return err
+		}
+		for len(files) > l.opts.MaxFiles {
+			if err := os.Remove(files[0]); err != nil {
+				return fmt.Errorf("removing old audit log: %v", err)
+			}
+			files = files[1:]
+		}
+	}
+	return nil
+}
+
+// Close closes the log.
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
+
+// rotatedFiles returns the rotated files of the log at path, oldest first.
+func rotatedFiles(path string) ([]string, error) {
+	matches, err := filepath.Glob(globEscape(path) + ".*")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, m := range matches {
+		if _, err := time.Parse(rotatedSuffix, strings.TrimPrefix(m, path+".")); err == nil {
+			files = append(files, m)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func globEscape(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if strings.ContainsRune(`*?[\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// A Query selects events.  Its zero value selects every event.
+type Query struct {
+	Since, Until time.Time // if set, bounds the time of events: [Since, Until)
+	Actor        string    // if set, the actor of events
+	Action       string    // if set, a prefix of the action of events
+	FailedOnly   bool      // whether to select only failed operations
+}
+
+// Matches reports whether q selects e.
+func (q *Query) Matches(e *Event) bool {
+	return (q.Since.IsZero() || !e.Time.Before(q.Since)) &&
+		(q.Until.IsZero() || e.Time.Before(q.Until)) &&
+		(q.Actor == "" || e.Actor == q.Actor) &&
+		strings.HasPrefix(e.Action, q.Action) &&
+		(!q.FailedOnly || e.Error != "")
+}
+
+// Read calls f with each event of the log at path, including its rotated
+// files, that q selects, in the order they were recorded.  A nil Query
+// selects every event.  If f returns an error, Read stops and returns it.
+func Read(path string, q *Query, f func(*Event) error) error {
+	if q == nil {
+		q = &Query{}
+	}
+	files, err := rotatedFiles(path)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	} else if len(files) == 0 {
+		return err
+	}
+	for _, file := range files {
+		if err := readFile(file, q, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readFile(path string, q *Query, f func(*Event) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	r := bufio.NewReader(file)
+	for lineNum := 1; ; lineNum++ {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF && len(line) == 0 {
+			return nil
+		} else if err != nil && err != io.EOF {
+			return err
+		}
+		var e Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			return fmt.Errorf("%s:%d: %v", path, lineNum, err)
+		}
+		if q.Matches(&e) {
+			if err := f(&e); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func readAll(t *testing.T, path string, q *Query) []string {
+	t.Helper()
+	var actions []string
+	if err := Read(path, q, func(e *Event) error {
+		actions = append(actions, e.Action)
+		return nil
+	}); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	return actions
+}
+
+func TestRecordAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithActor(context.Background(), "alice")
+	if err := l.RecordContext(ctx, "reload", "/srv/table", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.RecordContext(ctx, "graphstore.write", "gs", map[string]any{"updates": 3}, errors.New("disk full")); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Record(&Event{Actor: "bob", Remote: "10.0.0.1:1234", Action: "admin.status"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening the log appends to it.
+	l, err = Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Record(&Event{Actor: "bob", Action: "reload"}); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	if got, want := readAll(t, path, nil), []string{"reload", "graphstore.write", "admin.status", "reload"}; !cmp.Equal(got, want) {
+		t.Errorf("Read: got %v; want %v", got, want)
+	}
+
+	var events []*Event
+	if err := Read(path, &Query{Actor: "alice", FailedOnly: true}, func(e *Event) error {
+		events = append(events, e)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Failed events of alice: got %v", events)
+	}
+	e := events[0]
+	if e.Target != "gs" || e.Error != "disk full" || e.Details["updates"] != float64(3) || e.Time.IsZero() {
+		t.Errorf("Event: got %+v", e)
+	}
+
+	if got, want := readAll(t, path, &Query{Action: "admin."}), []string{"admin.status"}; !cmp.Equal(got, want) {
+		t.Errorf("Read admin events: got %v; want %v", got, want)
+	}
+	if got := readAll(t, path, &Query{Since: time.Now().Add(time.Hour)}); len(got) != 0 {
+		t.Errorf("Read future events: got %v", got)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.log")
+	l, err := Open(path, &Options{MaxSize: 80, MaxFiles: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	// Each event is about 60 bytes, so each is written to a new file.
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var want []string
+	for i := 0; i < 5; i++ {
+		action := string(rune('a' + i))
+		if err := l.Record(&Event{Time: start.Add(time.Duration(i) * time.Second), Actor: "alice", Action: action}); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, action)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The current file, and the two newest rotated files.
+	if len(files) != 3 {
+		t.Errorf("Log files: got %v; want 3", files)
+	}
+	if got, want := readAll(t, path, nil), want[2:]; !cmp.Equal(got, want) {
+		t.Errorf("Read: got %v; want %v", got, want)
+	}
+
+	// The rotated files are read even if the current file is missing.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readAll(t, path, nil), want[2:4]; !cmp.Equal(got, want) {
+		t.Errorf("Read rotated: got %v; want %v", got, want)
+	}
+}
+
+func TestActor(t *testing.T) {
+	ctx := context.Background()
+	if Actor(ctx) == "" {
+		t.Error("Actor: got empty default")
+	}
+	if got := Actor(WithActor(ctx, "alice")); got != "alice" {
+		t.Errorf("Actor: got %q; want %q", got, "alice")
+	}
+}
+
+func TestNilLog(t *testing.T) {
+	var l *Log
+	if err := l.Record(&Event{Action: "reload"}); err != nil {
+		t.Errorf("Record: %v", err)
+	}
+	if err := l.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
@@ -0,0 +1,341 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package parquet implements a writer for flat Apache Parquet files.
+//
+// Only the subset of the format needed to export tabular data is supported:
+// every column is a required BYTE_ARRAY column (optionally annotated as a
+// UTF-8 string), values are PLAIN-encoded, and each column chunk holds a
+// single data page.  The output is readable by standard Parquet tools such as
+// DuckDB, BigQuery, and pyarrow.
+//
+// Reference: https://github.com/apache/parquet-format
+package parquet // import "kythe.io/kythe/go/util/encoding/parquet"
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+)
+
+const magic = "PAR1"
+
+// Codec is a Parquet page compression codec.
+type Codec int32
+
+// Supported compression codecs.  The values match the Parquet format.
+const (
+	Uncompressed Codec = 0
+	Snappy       Codec = 1
+	Gzip         Codec = 2
+)
+
+// ParseCodec returns the Codec with the given name.
+func ParseCodec(name string) (Codec, error) {
+	switch name {
+	case "none", "uncompressed":
+		return Uncompressed, nil
+	case "snappy":
+		return Snappy, nil
+	case "gzip":
+		return Gzip, nil
+	default:
+		return 0, fmt.Errorf("unknown parquet codec %q", name)
+	}
+}
+
+// A Column describes a single column of the output.
+type Column struct {
+	Name string
+
+	// String marks the column as containing UTF-8 text rather than opaque
+	// bytes.
+	String bool
+}
+
+// Options control the layout of a Parquet file.
+type Options struct {
+	// Codec is used to compress each data page.  The default is Snappy.
+	Codec *Codec
+
+	// RowGroupSize is the approximate number of uncompressed value bytes
+	// buffered before a row group is written.  If zero, a default of 64MiB is
+	// used.
+	RowGroupSize int
+
+	// CreatedBy is recorded in the file metadata, if set.
+	CreatedBy string
+}
+
+const defaultRowGroupSize = 64 << 20
+
+// A Writer writes rows to a Parquet file.  Rows are buffered in memory until
+// a full row group is available.  Close must be called to write the file
+// footer.
+type Writer struct {
+	w         io.Writer
+	offset    int64
+	cols      []Column
+	codec     Codec
+	groupSize int
+	createdBy string
+
+	pages     []bytes.Buffer // PLAIN-encoded values for the current row group
+	buffered  int            // total bytes in pages
+	rows      int64          // rows in the current row group
+	totalRows int64
+	groups    []rowGroup
+	closed    bool
+}
+
+type rowGroup struct {
+	rows    int64
+	columns []columnChunk
+}
+
+type columnChunk struct {
+	offset             int64
+	values             int64
+	uncompressed, size int64
+}
+
+// NewWriter returns a Writer that writes rows with the given columns to w.
+func NewWriter(w io.Writer, cols []Column, opts *Options) (*Writer, error) {
+	if len(cols) == 0 {
+		return nil, errors.New("parquet: no columns")
+	}
+	seen := make(map[string]bool)
+	for _, c := range cols {
+		if c.Name == "" {
+			return nil, errors.New("parquet: empty column name")
+		} else if seen[c.Name] {
+			return nil, fmt.Errorf("parquet: duplicate column %q", c.Name)
+		}
+		seen[c.Name] = true
+	}
+	if opts == nil {
+		opts = new(Options)
+	}
+	pw := &Writer{
+		w:         w,
+		cols:      cols,
+		codec:     Snappy,
+		groupSize: opts.RowGroupSize,
+		createdBy: opts.CreatedBy,
+		pages:     make([]bytes.Buffer, len(cols)),
+	}
+	if opts.Codec != nil {
+		switch *opts.Codec {
+		case Uncompressed, Snappy, Gzip:
+			pw.codec = *opts.Codec
+		default:
+			return nil, fmt.Errorf("parquet: unsupported codec %d", *opts.Codec)
+		}
+	}
+	if pw.groupSize <= 0 {
+		pw.groupSize = defaultRowGroupSize
+	}
+	if err := pw.write([]byte(magic)); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+// Write adds a row to the file.  The row must have one value per column, in
+// column order.  The values are copied and may be reused by the caller.
+func (w *Writer) Write(row ...[]byte) error {
+	if w.closed {
+		return errors.New("parquet: write to closed writer")
+	} else if len(row) != len(w.cols) {
+		return fmt.Errorf("parquet: got %d values for %d columns", len(row), len(w.cols))
+	}
+	var n [4]byte
+	for i, v := range row {
+		binary.LittleEndian.PutUint32(n[:], uint32(len(v)))
+		w.pages[i].Write(n[:])
+		w.pages[i].Write(v)
+		w.buffered += len(n) + len(v)
+	}
+	w.rows++
+	if w.buffered >= w.groupSize {
+		return w.flush()
+	}
+	return nil
+}
+
+// Rows returns the number of rows written so far.
+func (w *Writer) Rows() int64 { return w.totalRows + w.rows }
+
+// Close writes any buffered rows and the file footer.  It does not close the
+// underlying io.Writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return errors.New("parquet: writer already closed")
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	w.closed = true
+	footer := w.footer()
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(footer)))
+	if err := w.write(footer); err != nil {
+		return err
+	} else if err := w.write(n[:]); err != nil {
+		return err
+	}
+	return w.write([]byte(magic))
+}
+
+func (w *Writer) write(data []byte) error {
+	n, err := w.w.Write(data)
+	w.offset += int64(n)
+	return err
+}
+
+// flush writes the buffered rows as a row group, with one page per column.
+func (w *Writer) flush() error {
+	if w.rows == 0 {
+		return nil
+	}
+	g := rowGroup{rows: w.rows}
+	for i := range w.pages {
+		raw := w.pages[i].Bytes()
+		data, err := w.compress(raw)
+		if err != nil {
+			return err
+		}
+		var h compactWriter
+		h.beginStruct(0) // PageHeader
+		h.i32(1, 0)      // type: DATA_PAGE
+		h.i32(2, int32(len(raw)))
+		h.i32(3, int32(len(data)))
+		h.beginStruct(5) // data_page_header
+		h.i32(1, int32(w.rows))
+		h.i32(2, 0) // encoding: PLAIN
+		h.i32(3, 3) // definition_level_encoding: RLE
+		h.i32(4, 3) // repetition_level_encoding: RLE
+		h.endStruct()
+		h.endStruct()
+
+		c := columnChunk{
+			offset:       w.offset,
+			values:       w.rows,
+			uncompressed: int64(h.buf.Len() + len(raw)),
+			size:         int64(h.buf.Len() + len(data)),
+		}
+		if err := w.write(h.buf.Bytes()); err != nil {
+			return err
+		} else if err := w.write(data); err != nil {
+			return err
+		}
+		g.columns = append(g.columns, c)
+		w.pages[i].Reset()
+	}
+	w.groups = append(w.groups, g)
+	w.totalRows += w.rows
+	w.rows = 0
+	w.buffered = 0
+	return nil
+}
+
+func (w *Writer) compress(data []byte) ([]byte, error) {
+	switch w.codec {
+	case Snappy:
+		return snappy.Encode(nil, data), nil
+	case Gzip:
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(data); err != nil {
+			return nil, err
+		} else if err := gz.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return data, nil
+	}
+}
+
+// footer returns the encoded FileMetaData for the file.
+func (w *Writer) footer() []byte {
+	const byteArray = 6
+	var c compactWriter
+	c.beginStruct(0) // FileMetaData
+	c.i32(1, 1)      // version
+
+	c.beginList(2, tStruct, len(w.cols)+1) // schema
+	c.beginStruct(0)
+	c.binary(4, []byte("schema"))
+	c.i32(5, int32(len(w.cols)))
+	c.endStruct()
+	for _, col := range w.cols {
+		c.beginStruct(0)
+		c.i32(1, byteArray)
+		c.i32(3, 0) // repetition_type: REQUIRED
+		c.binary(4, []byte(col.Name))
+		if col.String {
+			c.i32(6, 0)       // converted_type: UTF8
+			c.beginStruct(10) // logicalType
+			c.beginStruct(1)  // STRING
+			c.endStruct()
+			c.endStruct()
+		}
+		c.endStruct()
+	}
+
+	c.i64(3, w.totalRows)
+	c.beginList(4, tStruct, len(w.groups)) // row_groups
+	for _, g := range w.groups {
+		c.beginStruct(0)
+		var total, compressed int64
+		c.beginList(1, tStruct, len(g.columns))
+		for i, col := range g.columns {
+			total += col.uncompressed
+			compressed += col.size
+			c.beginStruct(0) // ColumnChunk
+			c.i64(2, col.offset)
+			c.beginStruct(3) // ColumnMetaData
+			c.i32(1, byteArray)
+			c.beginList(2, tI32, 1)
+			c.varint(0) // PLAIN
+			c.beginList(3, tBinary, 1)
+			c.bytes([]byte(w.cols[i].Name))
+			c.i32(4, int32(w.codec))
+			c.i64(5, col.values)
+			c.i64(6, col.uncompressed)
+			c.i64(7, col.size)
+			c.i64(9, col.offset)
+			c.endStruct()
+			c.endStruct()
+		}
+		c.i64(2, total)
+		c.i64(3, g.rows)
+		c.i64(5, g.columns[0].offset)
+		c.i64(6, compressed)
+		c.endStruct()
+	}
+	if w.createdBy != "" {
+		c.binary(6, []byte(w.createdBy))
+	}
+	c.endStruct()
+	return c.buf.Bytes()
+}
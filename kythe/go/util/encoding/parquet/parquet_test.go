@@ -0,0 +1,239 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/google/go-cmp/cmp"
+)
+
+// readCompact decodes a compact protocol struct into a map from field ID to
+// value.  Values are int64, []byte, []any, or map[int16]any.
+func readCompact(r *bytes.Reader) (map[int16]any, error) {
+	fields := make(map[int16]any)
+	var last int16
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		} else if b == 0 {
+			return fields, nil
+		}
+		typ := b & 0x0F
+		if d := int16(b >> 4); d != 0 {
+			last += d
+		} else {
+			id, err := binary.ReadVarint(r)
+			if err != nil {
+				return nil, err
+			}
+			last = int16(id)
+		}
+		v, err := readValue(r, typ)
+		if err != nil {
+			return nil, err
+		}
+		fields[last] = v
+	}
+}
+
+func readValue(r *bytes.Reader, typ byte) (any, error) {
+	switch typ {
+	case tI32, tI64:
+		return binary.ReadVarint(r)
+	case tBinary:
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, n)
+		_, err = io.ReadFull(r, buf)
+		return buf, err
+	case tStruct:
+		return readCompact(r)
+	case tList:
+		h, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		n := uint64(h >> 4)
+		if n == 15 {
+			if n, err = binary.ReadUvarint(r); err != nil {
+				return nil, err
+			}
+		}
+		var list []any
+		for i := uint64(0); i < n; i++ {
+			v, err := readValue(r, h&0x0F)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %d", typ)
+	}
+}
+
+// readFile decodes the columns of a Parquet file produced by Writer.
+func readFile(t *testing.T, data []byte) (names []string, cols [][]string) {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte(magic)) || !bytes.HasSuffix(data, []byte(magic)) {
+		t.Fatal("Missing magic number")
+	}
+	n := binary.LittleEndian.Uint32(data[len(data)-8:])
+	meta, err := readCompact(bytes.NewReader(data[len(data)-8-int(n) : len(data)-8]))
+	if err != nil {
+		t.Fatalf("Error reading footer: %v", err)
+	}
+	schema := meta[2].([]any)
+	for _, elt := range schema[1:] {
+		names = append(names, string(elt.(map[int16]any)[4].([]byte)))
+	}
+	cols = make([][]string, len(names))
+
+	var rows int64
+	for _, g := range meta[4].([]any) {
+		group := g.(map[int16]any)
+		rows += group[3].(int64)
+		for i, c := range group[1].([]any) {
+			md := c.(map[int16]any)[3].(map[int16]any)
+			if got := string(md[3].([]any)[0].([]byte)); got != names[i] {
+				t.Errorf("Column %d path: got %q, want %q", i, got, names[i])
+			}
+			r := bytes.NewReader(data[md[9].(int64):])
+			page, err := readCompact(r)
+			if err != nil {
+				t.Fatalf("Error reading page header: %v", err)
+			}
+			raw := make([]byte, page[3].(int64))
+			if _, err := io.ReadFull(r, raw); err != nil {
+				t.Fatalf("Error reading page: %v", err)
+			}
+			switch Codec(md[4].(int64)) {
+			case Snappy:
+				raw, err = snappy.Decode(nil, raw)
+			case Gzip:
+				var gz *gzip.Reader
+				if gz, err = gzip.NewReader(bytes.NewReader(raw)); err == nil {
+					raw, err = io.ReadAll(gz)
+				}
+			}
+			if err != nil {
+				t.Fatalf("Error decompressing page: %v", err)
+			} else if int64(len(raw)) != page[2].(int64) {
+				t.Errorf("Page size: got %d, want %d", len(raw), page[2])
+			}
+			for len(raw) > 0 {
+				n := binary.LittleEndian.Uint32(raw)
+				cols[i] = append(cols[i], string(raw[4:4+n]))
+				raw = raw[4+n:]
+			}
+		}
+	}
+	if rows != meta[3].(int64) {
+		t.Errorf("Row groups have %d rows; file has %d", rows, meta[3])
+	}
+	return names, cols
+}
+
+func TestWriter(t *testing.T) {
+	rows := [][]string{
+		{"apple", "1"},
+		{"banana", ""},
+		{"", "3"},
+		{"cherry", "\x00\xff"},
+		{"date", "5"},
+	}
+	for _, codec := range []Codec{Uncompressed, Snappy, Gzip} {
+		for _, groupSize := range []int{0, 1, 20} {
+			t.Run(fmt.Sprintf("codec=%d/group=%d", codec, groupSize), func(t *testing.T) {
+				var buf bytes.Buffer
+				w, err := NewWriter(&buf, []Column{{Name: "name", String: true}, {Name: "value"}}, &Options{
+					Codec:        &codec,
+					RowGroupSize: groupSize,
+				})
+				if err != nil {
+					t.Fatalf("NewWriter: %v", err)
+				}
+				for _, row := range rows {
+					if err := w.Write([]byte(row[0]), []byte(row[1])); err != nil {
+						t.Fatalf("Write: %v", err)
+					}
+				}
+				if got := w.Rows(); got != int64(len(rows)) {
+					t.Errorf("Rows: got %d, want %d", got, len(rows))
+				}
+				if err := w.Close(); err != nil {
+					t.Fatalf("Close: %v", err)
+				}
+
+				names, cols := readFile(t, buf.Bytes())
+				if diff := cmp.Diff([]string{"name", "value"}, names); diff != "" {
+					t.Errorf("Column names: (-want +got)\n%s", diff)
+				}
+				for i := range names {
+					var want []string
+					for _, row := range rows {
+						want = append(want, row[i])
+					}
+					if diff := cmp.Diff(want, cols[i]); diff != "" {
+						t.Errorf("Column %q: (-want +got)\n%s", names[i], diff)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestWriterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, []Column{{Name: "x"}}, nil)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	} else if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	names, cols := readFile(t, buf.Bytes())
+	if len(names) != 1 || len(cols[0]) != 0 {
+		t.Errorf("Unexpected contents: %v %v", names, cols)
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	if _, err := NewWriter(io.Discard, nil, nil); err == nil {
+		t.Error("NewWriter with no columns: expected error")
+	}
+	if _, err := NewWriter(io.Discard, []Column{{Name: "a"}, {Name: "a"}}, nil); err == nil {
+		t.Error("NewWriter with duplicate columns: expected error")
+	}
+	w, err := NewWriter(io.Discard, []Column{{Name: "a"}, {Name: "b"}}, nil)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	if err := w.Write([]byte("only one")); err == nil {
+		t.Error("Write with missing value: expected error")
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol type codes.
+// See https://github.com/apache/thrift/blob/master/doc/specs/thrift-compact-protocol.md
+const (
+	tI32    = 5
+	tI64    = 6
+	tBinary = 8
+	tList   = 9
+	tStruct = 12
+)
+
+// A compactWriter encodes Thrift structs using the compact protocol.  Only
+// the subset of the protocol needed for Parquet metadata is supported.
+type compactWriter struct {
+	buf   bytes.Buffer
+	last  int16   // ID of the last field written in the current struct
+	stack []int16 // saved last field IDs of enclosing structs
+}
+
+func (c *compactWriter) uvarint(v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	c.buf.Write(tmp[:binary.PutUvarint(tmp[:], v)])
+}
+
+func (c *compactWriter) varint(v int64) { c.uvarint(uint64(v<<1) ^ uint64(v>>63)) }
+
+func (c *compactWriter) field(id int16, typ byte) {
+	if d := id - c.last; d > 0 && d <= 15 {
+		c.buf.WriteByte(byte(d)<<4 | typ)
+	} else {
+		c.buf.WriteByte(typ)
+		c.varint(int64(id))
+	}
+	c.last = id
+}
+
+func (c *compactWriter) i32(id int16, v int32) {
+	c.field(id, tI32)
+	c.varint(int64(v))
+}
+
+func (c *compactWriter) i64(id int16, v int64) {
+	c.field(id, tI64)
+	c.varint(v)
+}
+
+func (c *compactWriter) binary(id int16, v []byte) {
+	c.field(id, tBinary)
+	c.bytes(v)
+}
+
+func (c *compactWriter) bytes(v []byte) {
+	c.uvarint(uint64(len(v)))
+	c.buf.Write(v)
+}
+
+// beginStruct starts a struct.  A top-level struct is written with id 0.
+func (c *compactWriter) beginStruct(id int16) {
+	if id != 0 {
+		c.field(id, tStruct)
+	}
+	c.stack = append(c.stack, c.last)
+	c.last = 0
+}
+
+func (c *compactWriter) endStruct() {
+	c.buf.WriteByte(0) // field stop
+	c.last = c.stack[len(c.stack)-1]
+	c.stack = c.stack[:len(c.stack)-1]
+}
+
+// beginList writes the header for a list of n elements of type elem.  Struct
+// elements are then written with beginStruct(0).
+func (c *compactWriter) beginList(id int16, elem byte, n int) {
+	c.field(id, tList)
+	if n < 15 {
+		c.buf.WriteByte(byte(n)<<4 | elem)
+	} else {
+		c.buf.WriteByte(0xF0 | elem)
+		c.uvarint(uint64(n))
+	}
+}
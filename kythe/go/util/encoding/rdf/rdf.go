@@ -15,7 +15,8 @@
  */
 
 // Package rdf implements encoding of RDF triples, as described in
-// http://www.w3.org/TR/2014/REC-n-triples-20140225/.
+// http://www.w3.org/TR/2014/REC-n-triples-20140225/ and
+// https://www.w3.org/TR/turtle/.
 package rdf // import "kythe.io/kythe/go/util/encoding/rdf"
 
 import (
@@ -16,7 +16,10 @@
 
 package rdf
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func q(s string) string { return `"` + s + `"` }
 
@@ -59,3 +62,59 @@ func TestEncoding(t *testing.T) {
 		}
 	}
 }
+
+func TestStatement(t *testing.T) {
+	tests := []struct {
+		s    Statement
+		want string
+	}{
+		{Statement{IRI("http://a/b"), IRI("http://p"), Literal("x\ny")}, `<http://a/b> <http://p> "x\ny" .`},
+		{Statement{IRI("kythe://c?lang=go#a b"), IRI("http://p"), IRI("urn:<x>")}, `<kythe://c?lang=go#a%20b> <http://p> <urn:%3Cx%3E> .`},
+		{Statement{IRI("s"), IRI("p"), TypedLiteral("AAE=", XSDBase64Binary)}, `<s> <p> "AAE="^^<http://www.w3.org/2001/XMLSchema#base64Binary> .`},
+	}
+	for _, test := range tests {
+		if got := test.s.String(); got != test.want {
+			t.Errorf("Encoding %+v\n got: %s\nwant: %s", test.s, got, test.want)
+		}
+	}
+}
+
+func TestTurtleWriter(t *testing.T) {
+	var buf strings.Builder
+	w := NewTurtleWriter(&buf, map[string]string{
+		"k":   "http://kythe.io/",
+		"ke":  "http://kythe.io/edge/",
+		"xsd": "http://www.w3.org/2001/XMLSchema#",
+	})
+	for _, s := range []*Statement{
+		{IRI("urn:a"), IRI("http://kythe.io/node/kind"), Literal("anchor")},
+		{IRI("urn:a"), IRI("http://kythe.io/edge/ref"), IRI("urn:b")},
+		{IRI("urn:a"), IRI("http://kythe.io/edge/ref"), IRI("urn:c")},
+		{IRI("urn:a"), IRI("http://kythe.io/edge/param.0"), IRI("urn:d")},
+		{IRI("urn:a"), IRI("http://kythe.io/edge/end."), IRI("urn:d")},
+		{IRI("urn:b"), IRI("http://kythe.io/code"), TypedLiteral("AA==", XSDBase64Binary)},
+		{IRI("urn:b"), IRI("http://kythe.io/odd%20name"), Literal("x")},
+	} {
+		if err := w.Write(s); err != nil {
+			t.Fatalf("Write(%v): %v", s, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	want := `@prefix k: <http://kythe.io/> .
+@prefix ke: <http://kythe.io/edge/> .
+@prefix xsd: <http://www.w3.org/2001/XMLSchema#> .
+
+<urn:a> k:node\/kind "anchor" ;
+	ke:ref <urn:b> ,
+		<urn:c> ;
+	ke:param.0 <urn:d> ;
+	ke:end\. <urn:d> .
+<urn:b> k:code "AA=="^^xsd:base64Binary ;
+	<http://kythe.io/odd%20name> "x" .
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Turtle output:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,231 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rdf
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// XSDBase64Binary is the datatype IRI for base64-encoded binary literals.
+const XSDBase64Binary = "http://www.w3.org/2001/XMLSchema#base64Binary"
+
+// A Term is an RDF term: either an IRI or a literal.  Unlike the fields of a
+// Triple, Terms distinguish IRIs from literals and so may be encoded as valid
+// N-Triples or Turtle.
+type Term struct {
+	// Value is the IRI or the lexical form of the literal.
+	Value string
+
+	// IsIRI is true if Value is an IRI rather than a literal.
+	IsIRI bool
+
+	// Datatype is the datatype IRI of a literal; if empty, the literal is a
+	// plain string.
+	Datatype string
+}
+
+// IRI returns an IRI term.
+func IRI(iri string) Term { return Term{Value: iri, IsIRI: true} }
+
+// Literal returns a plain string literal term.
+func Literal(s string) Term { return Term{Value: s} }
+
+// TypedLiteral returns a literal term with the given datatype IRI.
+func TypedLiteral(s, datatype string) Term { return Term{Value: s, Datatype: datatype} }
+
+// String returns the N-Triples encoding of t.
+func (t Term) String() string {
+	var buf bytes.Buffer
+	t.encodeTo(&buf)
+	return buf.String()
+}
+
+func (t Term) encodeTo(buf *bytes.Buffer) {
+	if t.IsIRI {
+		iriTo(buf, t.Value)
+		return
+	}
+	quoteTo(buf, t.Value)
+	if t.Datatype != "" {
+		buf.WriteString("^^")
+		iriTo(buf, t.Datatype)
+	}
+}
+
+// iriTo appends an IRIREF for iri to buf.  Characters not permitted in an
+// IRI are percent-encoded; a \u escape would not do, since it stands for the
+// character itself, which remains invalid.
+func iriTo(buf *bytes.Buffer, iri string) {
+	buf.WriteByte('<')
+	for _, c := range iri {
+		if c <= ' ' || strings.ContainsRune(`<>"{}|^`+"`"+`\`, c) {
+			fmt.Fprintf(buf, "%%%02X", c)
+		} else {
+			buf.WriteRune(c)
+		}
+	}
+	buf.WriteByte('>')
+}
+
+// A Statement is an RDF triple of Terms.
+type Statement struct {
+	Subject, Predicate, Object Term
+}
+
+// String returns the N-Triples encoding of s, without a trailing newline.
+func (s *Statement) String() string {
+	var buf bytes.Buffer
+	s.Subject.encodeTo(&buf)
+	buf.WriteByte(' ')
+	s.Predicate.encodeTo(&buf)
+	buf.WriteByte(' ')
+	s.Object.encodeTo(&buf)
+	buf.WriteString(" .")
+	return buf.String()
+}
+
+// A TurtleWriter writes Statements in the Turtle format.  Consecutive
+// statements with the same subject (and predicate) are abbreviated, and IRIs
+// within a declared namespace are written as prefixed names where possible.
+//
+// Reference: https://www.w3.org/TR/turtle/
+type TurtleWriter struct {
+	w        *bufio.Writer
+	prefixes []turtlePrefix // sorted by decreasing namespace length
+	started  bool
+	last     *Statement
+}
+
+type turtlePrefix struct{ name, namespace string }
+
+// NewTurtleWriter returns a TurtleWriter that writes to w, abbreviating IRIs
+// with the given prefixes (a map from prefix name to namespace IRI).
+func NewTurtleWriter(w io.Writer, prefixes map[string]string) *TurtleWriter {
+	t := &TurtleWriter{w: bufio.NewWriter(w)}
+	for name, ns := range prefixes {
+		t.prefixes = append(t.prefixes, turtlePrefix{name, ns})
+	}
+	sort.Slice(t.prefixes, func(i, j int) bool {
+		a, b := t.prefixes[i], t.prefixes[j]
+		if len(a.namespace) != len(b.namespace) {
+			return len(a.namespace) > len(b.namespace)
+		}
+		return a.name < b.name
+	})
+	return t
+}
+
+// Write adds s to the output.
+func (t *TurtleWriter) Write(s *Statement) error {
+	var buf bytes.Buffer
+	if !t.started {
+		t.started = true
+		names := make([]turtlePrefix, len(t.prefixes))
+		copy(names, t.prefixes)
+		sort.Slice(names, func(i, j int) bool { return names[i].name < names[j].name })
+		for _, p := range names {
+			fmt.Fprintf(&buf, "@prefix %s: ", p.name)
+			iriTo(&buf, p.namespace)
+			buf.WriteString(" .\n")
+		}
+		if len(names) > 0 {
+			buf.WriteByte('\n')
+		}
+	}
+
+	switch {
+	case t.last != nil && t.last.Subject == s.Subject && t.last.Predicate == s.Predicate:
+		buf.WriteString(" ,\n\t\t")
+	case t.last != nil && t.last.Subject == s.Subject:
+		buf.WriteString(" ;\n\t")
+		t.termTo(&buf, s.Predicate)
+		buf.WriteByte(' ')
+	default:
+		if t.last != nil {
+			buf.WriteString(" .\n")
+		}
+		t.termTo(&buf, s.Subject)
+		buf.WriteByte(' ')
+		t.termTo(&buf, s.Predicate)
+		buf.WriteByte(' ')
+	}
+	t.termTo(&buf, s.Object)
+	t.last = s
+	_, err := t.w.Write(buf.Bytes())
+	return err
+}
+
+// Close terminates the final statement and flushes the output.  It does not
+// close the underlying io.Writer.
+func (t *TurtleWriter) Close() error {
+	if t.last != nil {
+		if _, err := t.w.WriteString(" .\n"); err != nil {
+			return err
+		}
+		t.last = nil
+	}
+	return t.w.Flush()
+}
+
+func (t *TurtleWriter) termTo(buf *bytes.Buffer, term Term) {
+	if term.IsIRI {
+		if name, ok := t.prefixedName(term.Value); ok {
+			buf.WriteString(name)
+			return
+		}
+	} else if term.Datatype != "" {
+		quoteTo(buf, term.Value)
+		buf.WriteString("^^")
+		t.termTo(buf, IRI(term.Datatype))
+		return
+	}
+	term.encodeTo(buf)
+}
+
+// prefixedName returns the prefixed name for iri, if it is within a declared
+// namespace and its local part can be written without percent-encoding.
+func (t *TurtleWriter) prefixedName(iri string) (string, bool) {
+	for _, p := range t.prefixes {
+		local, ok := strings.CutPrefix(iri, p.namespace)
+		if !ok {
+			continue
+		}
+		var buf strings.Builder
+		buf.WriteString(p.name)
+		buf.WriteByte(':')
+		for i, c := range local {
+			switch {
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_':
+				buf.WriteRune(c)
+			case c == '-' && i > 0, c == '.' && i > 0 && i < len(local)-1:
+				buf.WriteRune(c)
+			case strings.ContainsRune("/.~!$&'()*+,;=?#@", c):
+				buf.WriteByte('\\')
+				buf.WriteRune(c)
+			default:
+				return "", false
+			}
+		}
+		return buf.String(), true
+	}
+	return "", false
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package tabular implements writing records as comma- or tab-separated
+// values, with a caller-selectable set of named columns.
+//
+// CSV output follows RFC 4180, as implemented by encoding/csv.  TSV output
+// has no quoting; instead, backslash, tab, newline, and carriage return are
+// escaped as \\, \t, \n, and \r respectively, so that each record occupies
+// exactly one line.
+package tabular // import "kythe.io/kythe/go/util/encoding/tabular"
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format is a tabular output format.
+type Format string
+
+// Supported output formats.
+const (
+	CSV Format = "csv"
+	TSV Format = "tsv"
+)
+
+// ParseFormat returns the Format with the given name.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case CSV, TSV:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown tabular format %q", name)
+	}
+}
+
+// A Writer writes rows of string values in a tabular Format.  Output is
+// buffered; Flush must be called once all rows are written.
+type Writer struct {
+	csv *csv.Writer   // set for CSV
+	tsv *bufio.Writer // set for TSV
+}
+
+// NewWriter returns a Writer that writes rows to w in the given format.
+func NewWriter(w io.Writer, f Format) *Writer {
+	if f == TSV {
+		return &Writer{tsv: bufio.NewWriter(w)}
+	}
+	return &Writer{csv: csv.NewWriter(w)}
+}
+
+// Write writes a single row.
+func (w *Writer) Write(row []string) error {
+	if w.csv != nil {
+		return w.csv.Write(row)
+	}
+	for i, v := range row {
+		if i > 0 {
+			w.tsv.WriteByte('\t')
+		}
+		tsvEscaper.WriteString(w.tsv, v)
+	}
+	return w.tsv.WriteByte('\n')
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *Writer) Flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+	return w.tsv.Flush()
+}
+
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// A Column is a named field extracted from records of type T.
+type Column[T any] struct {
+	Name  string
+	Value func(T) string
+}
+
+// SelectColumns returns the columns of all with the given names, in the order
+// named.  If names is empty, all columns are returned.
+func SelectColumns[T any](all []Column[T], names []string) ([]Column[T], error) {
+	if len(names) == 0 {
+		return all, nil
+	}
+	byName := make(map[string]Column[T], len(all))
+	for _, c := range all {
+		byName[c.Name] = c
+	}
+	var cols []Column[T]
+	for _, name := range names {
+		c, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q (available: %s)", name, strings.Join(ColumnNames(all), ","))
+		}
+		cols = append(cols, c)
+	}
+	return cols, nil
+}
+
+// ColumnNames returns the names of cols, in order.  It is suitable for use as
+// a header row.
+func ColumnNames[T any](cols []Column[T]) []string {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// Row returns the values of cols for the record rec.
+func Row[T any](cols []Column[T], rec T) []string {
+	row := make([]string, len(cols))
+	for i, c := range cols {
+		row[i] = c.Value(rec)
+	}
+	return row
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tabular
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriter(t *testing.T) {
+	rows := [][]string{
+		{"name", "value"},
+		{"plain", "1"},
+		{"with,comma", "with\ttab"},
+		{`with "quote"`, "multi\nline\\"},
+	}
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{CSV, `name,value
+plain,1
+"with,comma",` + "with\ttab" + `
+"with ""quote""","multi
+line\"
+`},
+		{TSV, `name	value
+plain	1
+with,comma	with\ttab
+with "quote"	multi\nline\\
+`},
+	}
+	for _, test := range tests {
+		var buf strings.Builder
+		w := NewWriter(&buf, test.format)
+		for _, row := range rows {
+			if err := w.Write(row); err != nil {
+				t.Fatalf("Write(%q): %v", row, err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+		if diff := cmp.Diff(test.want, buf.String()); diff != "" {
+			t.Errorf("%s output: (-want +got)\n%s", test.format, diff)
+		}
+	}
+}
+
+func TestSelectColumns(t *testing.T) {
+	all := []Column[int]{
+		{"n", func(i int) string { return strings.Repeat("x", i) }},
+		{"even", func(i int) string {
+			if i%2 == 0 {
+				return "true"
+			}
+			return "false"
+		}},
+	}
+
+	cols, err := SelectColumns(all, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff([]string{"n", "even"}, ColumnNames(cols)); diff != "" {
+		t.Errorf("Default columns: (-want +got)\n%s", diff)
+	}
+
+	cols, err = SelectColumns(all, []string{"even", "n"})
+	if err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff([]string{"false", "xxx"}, Row(cols, 3)); diff != "" {
+		t.Errorf("Row: (-want +got)\n%s", diff)
+	}
+
+	if _, err := SelectColumns(all, []string{"odd"}); err == nil {
+		t.Error("Expected error for unknown column")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"csv": CSV, "TSV": TSV} {
+		if got, err := ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q): got (%q, %v), want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xlsx"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kytheuri
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func FuzzParse(f *testing.F) {
+	for _, s := range []string{
+		"",
+		"kythe:",
+		"kythe://corpus?lang=go?path=a/b.go?root=r#sig",
+		"//corpus/with/path",
+		"kythe:?path=P?lang=L",
+		"kythe://c?path=%3F%23%25#%2F",
+		"kythe://a/../b//c?path=./x/../y",
+		"kythe:?path",
+		"kythe://c?bogus=x",
+		"kythe://c#a%",
+		"http://corpus",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		u, err := Parse(s)
+		if err != nil {
+			return
+		}
+
+		// The canonical form of a URI parses to the same URI, and is its own
+		// canonical form.
+		canon := u.String()
+		v, err := Parse(canon)
+		if err != nil {
+			t.Fatalf("Parse(%q): canonical form %q does not parse: %v", s, canon, err)
+		}
+		if !u.Equal(v) {
+			t.Errorf("Parse(%q): got %+v from canonical form %q, want %+v", s, v, canon, u)
+		}
+		if fixed, err := Fix(canon); err != nil || fixed != canon {
+			t.Errorf("Fix(%q): got %q [%v], want it unchanged", canon, fixed, err)
+		}
+
+		// The VName of a URI renders to the same URI.
+		vname := u.VName()
+		if got, err := ToVName(ToString(vname)); err != nil || !proto.Equal(got, vname) {
+			t.Errorf("ToVName(ToString(%v)): got %v [%v]", vname, got, err)
+		}
+	})
+}
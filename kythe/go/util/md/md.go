@@ -20,6 +20,7 @@ package md
 import (
 	"fmt"
 	"sort"
+	"strings"
 )
 
 // LinkInfo contains information about the placement and destination of
@@ -64,3 +65,23 @@ func ProcessLinks(content string, links []LinkInfo) string {
 
 	return mdContent
 }
+
+// PlainText returns the text of a Kythe doc node without its link markup.
+// Linked spans of the text are bracketed, and literal brackets and
+// backslashes are escaped by a backslash.
+func PlainText(text string) string {
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '[', ']':
+		case '\\':
+			if i+1 < len(text) {
+				i++
+				sb.WriteByte(text[i])
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
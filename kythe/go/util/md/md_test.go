@@ -103,3 +103,17 @@ func TestProcessLinks(t *testing.T) {
 		}
 	}
 }
+
+func TestPlainText(t *testing.T) {
+	for text, want := range map[string]string{
+		"":                         "",
+		"No links":                 "No links",
+		"Calls [f] and [g].":       "Calls f and g.",
+		`Escaped \[x\] and \\`:     `Escaped [x] and \`,
+		`Linked [\[x\]] trailing\`: "Linked [x] trailing",
+	} {
+		if got := PlainText(text); got != want {
+			t.Errorf("PlainText(%q): got %q, want %q", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package progress tracks the progress of long-running operations, such as
+// populating an in-memory index at startup, and reports it over HTTP.
+//
+// An operation calls Start to begin tracking, Add as it processes items, and
+// Done when it is finished:
+//
+//	t := progress.Start("filetree.Populate", "entries", expected)
+//	defer t.Done()
+//	for ... {
+//	  t.Add(1)
+//	}
+//
+// RegisterHTTPHandler exposes a report of every tracked operation, with its
+// rate and, when the expected number of items is known, its estimated
+// completion time.
+package progress // import "kythe.io/kythe/go/util/progress"
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// keepDone is the number of finished operations kept for reporting.
+const keepDone = 16
+
+var (
+	mu       sync.Mutex
+	active   = make(map[*Tracker]bool)
+	finished []*Tracker // most recent last
+	now      = time.Now
+)
+
+// A Tracker records the progress of a single operation.  Its methods are
+// safe for concurrent use.
+type Tracker struct {
+	name, unit string
+	start      time.Time
+
+	processed atomic.Int64
+	total     atomic.Int64
+	end       atomic.Int64 // UnixNano, or 0 if not done
+}
+
+// Start begins tracking an operation with the given name, which processes
+// total items of the given unit.  If total ≤ 0, the number of items is not
+// known in advance and no completion time is estimated.
+func Start(name, unit string, total int64) *Tracker {
+	t := &Tracker{name: name, unit: unit, start: now()}
+	t.total.Store(total)
+	mu.Lock()
+	defer mu.Unlock()
+	active[t] = true
+	return t
+}
+
+// Add records that n more items have been processed.
+func (t *Tracker) Add(n int64) { t.processed.Add(n) }
+
+// SetTotal updates the number of items the operation is expected to process.
+func (t *Tracker) SetTotal(total int64) { t.total.Store(total) }
+
+// Done records that the operation has finished.  Calling Done more than once
+// has no further effect.
+func (t *Tracker) Done() {
+	if !t.end.CompareAndSwap(0, now().UnixNano()) {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	delete(active, t)
+	finished = append(finished, t)
+	if len(finished) > keepDone {
+		finished = finished[len(finished)-keepDone:]
+	}
+}
+
+// A Report describes the progress of an operation at some moment.
+type Report struct {
+	Name      string        `json:"name"`
+	Unit      string        `json:"unit"`
+	Processed int64         `json:"processed"`
+	Total     int64         `json:"total,omitempty"` // if known
+	Started   time.Time     `json:"started"`         // when the operation started
+	Elapsed   time.Duration `json:"elapsed"`         // how long it has run (or ran)
+	Rate      float64       `json:"rate"`            // items per second
+	Remaining time.Duration `json:"remaining"`       // estimated; 0 if unknown or done
+	ETA       time.Time     `json:"eta"`             // estimated completion; zero if unknown or done
+	Done      bool          `json:"done"`
+}
+
+// Report returns a report of the current progress of t.
+func (t *Tracker) Report() Report {
+	r := Report{
+		Name:      t.name,
+		Unit:      t.unit,
+		Processed: t.processed.Load(),
+		Total:     t.total.Load(),
+		Started:   t.start,
+	}
+	at := now()
+	if end := t.end.Load(); end != 0 {
+		r.Done = true
+		at = time.Unix(0, end)
+	}
+	r.Elapsed = at.Sub(t.start)
+	if secs := r.Elapsed.Seconds(); secs > 0 {
+		r.Rate = float64(r.Processed) / secs
+	}
+	if !r.Done && r.Total > 0 && r.Rate > 0 {
+		left := r.Total - r.Processed
+		if left < 0 {
+			left = 0
+		}
+		r.Remaining = time.Duration(float64(left) / r.Rate * float64(time.Second))
+		r.ETA = at.Add(r.Remaining)
+	}
+	return r
+}
+
+// Fraction returns the fraction of the operation completed, or -1 if it is
+// not known.
+func (r Report) Fraction() float64 {
+	if r.Done {
+		return 1
+	} else if r.Total <= 0 {
+		return -1
+	} else if r.Processed >= r.Total {
+		return 1
+	}
+	return float64(r.Processed) / float64(r.Total)
+}
+
+func (r Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d", r.Name, r.Processed)
+	if r.Total > 0 {
+		fmt.Fprintf(&b, "/%d", r.Total)
+	}
+	fmt.Fprintf(&b, " %s in %s (%.1f/s)", r.Unit, r.Elapsed.Round(time.Millisecond), r.Rate)
+	switch {
+	case r.Done:
+		b.WriteString(", done")
+	case !r.ETA.IsZero():
+		fmt.Fprintf(&b, ", %.1f%%, about %s remaining", 100*r.Fraction(), r.Remaining.Round(time.Second))
+	}
+	return b.String()
+}
+
+// Reports returns reports for the operations in progress, in the order they
+// started, followed by those for recently finished operations.
+func Reports() []Report {
+	mu.Lock()
+	running := make([]*Tracker, 0, len(active))
+	for t := range active {
+		running = append(running, t)
+	}
+	done := append([]*Tracker(nil), finished...)
+	mu.Unlock()
+
+	sort.Slice(running, func(i, j int) bool { return running[i].start.Before(running[j].start) })
+	var reports []Report
+	for _, t := range append(running, done...) {
+		reports = append(reports, t.Report())
+	}
+	return reports
+}
+
+// RegisterHTTPHandler adds a handler for /progress to mux, reporting the
+// progress of all tracked operations.  The reports are written as a JSON
+// array if the "json" query parameter is set, and otherwise as text, one
+// line per operation.
+func RegisterHTTPHandler(mux *http.ServeMux) {
+	mux.HandleFunc("/progress", func(w http.ResponseWriter, r *http.Request) {
+		reports := Reports()
+		if r.URL.Query().Get("json") != "" {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			if reports == nil {
+				reports = []Report{}
+			}
+			json.NewEncoder(w).Encode(reports)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, r := range reports {
+			fmt.Fprintln(w, r)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package progress
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fakeClock(t *testing.T) *time.Time {
+	clock := time.Unix(1000, 0)
+	now = func() time.Time { return clock }
+	t.Cleanup(func() { now = time.Now })
+	return &clock
+}
+
+func TestTracker(t *testing.T) {
+	clock := fakeClock(t)
+	tr := Start("populate", "entries", 1000)
+	*clock = clock.Add(10 * time.Second)
+	tr.Add(250)
+
+	r := tr.Report()
+	if r.Processed != 250 || r.Elapsed != 10*time.Second || r.Rate != 25 || r.Done {
+		t.Errorf("Report: got %+v", r)
+	}
+	if r.Remaining != 30*time.Second || !r.ETA.Equal(clock.Add(30*time.Second)) {
+		t.Errorf("Estimate: got %s remaining, ETA %v; want 30s", r.Remaining, r.ETA)
+	}
+	if got := r.Fraction(); got != 0.25 {
+		t.Errorf("Fraction: got %v, want 0.25", got)
+	}
+	want := "populate: 250/1000 entries in 10s (25.0/s), 25.0%, about 30s remaining"
+	if got := r.String(); got != want {
+		t.Errorf("String: got %q, want %q", got, want)
+	}
+
+	*clock = clock.Add(10 * time.Second)
+	tr.Done()
+	*clock = clock.Add(time.Hour)
+	r = tr.Report()
+	if !r.Done || r.Elapsed != 20*time.Second || r.Remaining != 0 || !r.ETA.IsZero() {
+		t.Errorf("Report after Done: got %+v", r)
+	}
+}
+
+func TestUnknownTotal(t *testing.T) {
+	fakeClock(t)
+	tr := Start("scan", "entries", 0)
+	defer tr.Done()
+	tr.Add(10)
+	if r := tr.Report(); r.Fraction() != -1 || !r.ETA.IsZero() {
+		t.Errorf("Report with unknown total: got %+v, fraction %v", r, r.Fraction())
+	}
+}
+
+func TestHTTPHandler(t *testing.T) {
+	clock := fakeClock(t)
+	first := Start("first", "files", 0)
+	*clock = clock.Add(time.Second)
+	second := Start("second", "files", 10)
+	defer second.Done()
+	first.Done()
+
+	mux := http.NewServeMux()
+	RegisterHTTPHandler(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/progress?json=1", nil))
+	var reports []Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &reports); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, rec.Body)
+	}
+	// Running operations are reported before finished ones.
+	var names []string
+	for _, r := range reports {
+		names = append(names, r.Name)
+	}
+	if len(names) < 2 || names[0] != "second" || names[len(names)-1] != "first" {
+		t.Errorf("Reported operations: got %v, want second first, first last", names)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/progress", nil))
+	if got := rec.Body.String(); !strings.Contains(got, "second: 0/10 files") {
+		t.Errorf("Text report: got %q", got)
+	}
+}
@@ -32,6 +32,8 @@ const Prefix = schema.Prefix + "edge/"
 const (
 	ChildOf                 = Prefix + "childof"
 	Denotes                 = Prefix + "denotes"
+	Depends                 = Prefix + "depends"
+	Exports                 = Prefix + "exports"
 	Extends                 = Prefix + "extends"
 	ExtendsPrivate          = Prefix + "extends/private"
 	ExtendsPrivateVirtual   = Prefix + "extends/private/virtual"
@@ -61,6 +63,7 @@ const (
 	RefDoc          = Prefix + "ref/doc"
 	RefImplicit     = Prefix + "ref/implicit"
 	RefImports      = Prefix + "ref/imports"
+	RefIncludes     = Prefix + "ref/includes"
 	RefInit         = Prefix + "ref/init"
 	RefInitImplicit = Prefix + "ref/init/implicit"
 	RefWrites       = Prefix + "ref/writes"
@@ -33,6 +33,11 @@ const (
 	Message           = prefix + "message"
 	NodeKind          = prefix + "node/kind"
 	ParamDefault      = prefix + "param/default"
+	ProvBuildTime     = prefix + "provenance/build_time"
+	ProvCommit        = prefix + "provenance/commit"
+	ProvInvocation    = prefix + "provenance/invocation"
+	ProvToolchain     = prefix + "provenance/toolchain"
+	ProvUnit          = prefix + "provenance/unit"
 	SemanticGenerated = prefix + "semantic/generated"
 	SnippetEnd        = prefix + "snippet/end"
 	SnippetStart      = prefix + "snippet/start"
@@ -29,6 +29,7 @@ const (
 	Interface  = "interface"
 	Name       = "name"
 	Package    = "package"
+	Process    = "process"
 	Record     = "record"
 	Symbol     = "symbol"
 	TAlias     = "talias"
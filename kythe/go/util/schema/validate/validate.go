@@ -0,0 +1,113 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package validate checks individual entries against the rules of the Kythe
+// schema.  The checks are local to a single entry; they do not verify that,
+// for example, every anchor has both a start and end offset.
+package validate // import "kythe.io/kythe/go/util/schema/validate"
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"kythe.io/kythe/go/util/schema"
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+// Entry reports whether e is a well-formed entry according to the Kythe
+// schema.  A nil error means e is valid; otherwise the error describes the
+// first problem found.
+func Entry(e *spb.Entry) error {
+	if e.GetSource() == nil {
+		return errors.New("missing source")
+	} else if isEmpty(e.GetSource()) {
+		return errors.New("empty source")
+	} else if e.GetFactName() == "" {
+		return errors.New("missing fact name")
+	} else if !strings.HasPrefix(e.GetFactName(), "/") {
+		return fmt.Errorf("fact name %q is not a label path", e.GetFactName())
+	}
+	if e.GetEdgeKind() != "" {
+		return edge(e)
+	}
+	return nodeFact(e)
+}
+
+func edge(e *spb.Entry) error {
+	if e.GetTarget() == nil {
+		return errors.New("edge missing target")
+	} else if isEmpty(e.GetTarget()) {
+		return errors.New("empty edge target")
+	}
+	kind, _, _ := edges.ParseOrdinal(edges.Canonical(e.GetEdgeKind()))
+	if !strings.HasPrefix(kind, "/") {
+		return fmt.Errorf("edge kind %q is not a label path", e.GetEdgeKind())
+	} else if strings.HasPrefix(kind, edges.Prefix) && !knownEdgeKind(kind) {
+		return fmt.Errorf("unknown Kythe edge kind %q", e.GetEdgeKind())
+	}
+	return nil
+}
+
+// knownEdgeKind reports whether kind, or a kind of which it is a variant, is
+// in the schema.
+func knownEdgeKind(kind string) bool {
+	for len(kind) > len(edges.Prefix) {
+		if schema.EdgeKind(kind) != 0 {
+			return true
+		}
+		i := strings.LastIndex(kind, "/")
+		if i < 0 {
+			break
+		}
+		kind = kind[:i]
+	}
+	return false
+}
+
+func nodeFact(e *spb.Entry) error {
+	if e.GetTarget() != nil {
+		return errors.New("node fact has extraneous target")
+	}
+	switch name, value := e.GetFactName(), string(e.GetFactValue()); name {
+	case facts.NodeKind:
+		if value == "" {
+			return errors.New("empty node kind")
+		} else if strings.TrimSpace(value) != value {
+			return fmt.Errorf("node kind %q has surrounding whitespace", value)
+		}
+	case facts.AnchorStart, facts.AnchorEnd, facts.SnippetStart, facts.SnippetEnd:
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			return fmt.Errorf("%s value %q is not a non-negative integer", name, value)
+		}
+	case facts.Complete:
+		switch value {
+		case "definition", "complete", "incomplete":
+		default:
+			return fmt.Errorf("invalid %s value %q", name, value)
+		}
+	}
+	return nil
+}
+
+func isEmpty(v *spb.VName) bool {
+	return v.GetSignature() == "" && v.GetCorpus() == "" && v.GetRoot() == "" &&
+		v.GetPath() == "" && v.GetLanguage() == ""
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validate
+
+import (
+	"testing"
+
+	"kythe.io/kythe/go/util/schema/edges"
+	"kythe.io/kythe/go/util/schema/facts"
+
+	spb "kythe.io/kythe/proto/storage_go_proto"
+)
+
+func TestEntry(t *testing.T) {
+	src := &spb.VName{Signature: "src"}
+	tgt := &spb.VName{Signature: "tgt"}
+	fact := func(name, value string) *spb.Entry {
+		return &spb.Entry{Source: src, FactName: name, FactValue: []byte(value)}
+	}
+	edge := func(kind string) *spb.Entry {
+		return &spb.Entry{Source: src, Target: tgt, EdgeKind: kind, FactName: "/"}
+	}
+
+	valid := []*spb.Entry{
+		fact(facts.NodeKind, "anchor"),
+		fact(facts.AnchorStart, "0"),
+		fact(facts.AnchorEnd, "42"),
+		fact(facts.Complete, "definition"),
+		fact("/custom/fact", "anything"),
+		edge(edges.ChildOf),
+		edge(edges.Mirror(edges.ChildOf)),
+		edge(edges.ParamIndex(3)),
+		edge(edges.ExtendsPublicVirtual),
+		edge("/custom/edge"),
+	}
+	for _, e := range valid {
+		if err := Entry(e); err != nil {
+			t.Errorf("Entry(%v): unexpected error: %v", e, err)
+		}
+	}
+
+	invalid := []*spb.Entry{
+		{FactName: facts.NodeKind, FactValue: []byte("anchor")},
+		{Source: &spb.VName{}, FactName: facts.NodeKind, FactValue: []byte("anchor")},
+		{Source: src},
+		fact("kythe/node/kind", "anchor"),
+		fact(facts.NodeKind, ""),
+		fact(facts.NodeKind, " anchor"),
+		fact(facts.AnchorStart, "-1"),
+		fact(facts.SnippetEnd, "ten"),
+		fact(facts.Complete, "mostly"),
+		{Source: src, Target: tgt, FactName: facts.NodeKind, FactValue: []byte("anchor")},
+		{Source: src, EdgeKind: edges.ChildOf, FactName: "/"},
+		{Source: src, Target: &spb.VName{}, EdgeKind: edges.ChildOf, FactName: "/"},
+		edge("childof"),
+		edge(edges.Prefix + "bogus"),
+	}
+	for _, e := range invalid {
+		if err := Entry(e); err == nil {
+			t.Errorf("Entry(%v): expected error", e)
+		} else {
+			t.Logf("Entry(%v): %v", e, err)
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"unicode/utf8"
 
 	"kythe.io/kythe/go/util/log"
 
@@ -307,8 +308,10 @@ func (p *Patcher) Patch(spanStart, spanEnd int32) (newStart, newEnd int32, exist
 
 // Normalizer fixes xref.Locations within a given source text so that each point
 // has consistent byte_offset, line_number, and column_offset fields within the
-// range of text's length and its line lengths.
+// range of text's length and its line lengths.  Offsets within a multi-byte
+// UTF-8 character are moved back to the start of the character.
 type Normalizer struct {
+	text      []byte
 	textLen   int32
 	lineLen   []int32
 	prefixLen []int32
@@ -324,7 +327,7 @@ func NewNormalizer(text []byte) *Normalizer {
 		prefixLen[i] = prefixLen[i-1] + lineLen[i-1]
 	}
 	lineLen[len(lines)-1] = int32(len(lines[len(lines)-1]) + len(lineEnd))
-	return &Normalizer{int32(len(text)), lineLen, prefixLen}
+	return &Normalizer{text, int32(len(text)), lineLen, prefixLen}
 }
 
 // Location returns a normalized location within the Normalizer's text.
@@ -409,6 +412,10 @@ func (n *Normalizer) Point(p *cpb.Point) *cpb.Point {
 		}
 
 		np.ByteOffset = n.prefixLen[np.LineNumber-1] + np.ColumnOffset
+		if start := n.runeStart(np.ByteOffset); start != np.ByteOffset {
+			np.ColumnOffset -= np.ByteOffset - start
+			np.ByteOffset = start
+		}
 
 		return np
 	}
@@ -424,6 +431,7 @@ func (n *Normalizer) ByteOffset(offset int32) *cpb.Point {
 	if np.ByteOffset > n.textLen {
 		np.ByteOffset = n.textLen
 	}
+	np.ByteOffset = n.runeStart(np.ByteOffset)
 
 	np.LineNumber = int32(sort.Search(len(n.lineLen), func(i int) bool {
 		return n.prefixLen[i] > np.ByteOffset
@@ -432,3 +440,50 @@ func (n *Normalizer) ByteOffset(offset int32) *cpb.Point {
 
 	return np
 }
+
+// runeStart returns the offset of the start of the UTF-8 encoded character
+// containing offset.  Invalid encodings are treated as single bytes.
+func (n *Normalizer) runeStart(offset int32) int32 {
+	if offset <= 0 || offset >= n.textLen || utf8.RuneStart(n.text[offset]) {
+		return offset
+	}
+	for start := offset - 1; start >= 0 && start > offset-utf8.UTFMax; start-- {
+		if utf8.RuneStart(n.text[start]) {
+			if _, size := utf8.DecodeRune(n.text[start:]); start+int32(size) > offset {
+				return start
+			}
+			break
+		}
+	}
+	return offset
+}
+
+// CharacterColumn returns the number of UTF-8 encoded characters preceding p
+// on its line, after p is normalized.  It is the 0-based column of p for
+// tools that count characters rather than bytes.
+func (n *Normalizer) CharacterColumn(p *cpb.Point) int32 {
+	np := n.Point(p)
+	lineStart := np.ByteOffset - np.ColumnOffset
+	return int32(utf8.RuneCount(n.text[lineStart:np.ByteOffset]))
+}
+
+// CharacterPoint returns the normalized point at the given 0-based character
+// column of the given 1-based line.  A column past the end of the line is
+// clamped to the line's end.
+func (n *Normalizer) CharacterPoint(line, col int32) *cpb.Point {
+	np := n.Point(&cpb.Point{LineNumber: line})
+	if np.LineNumber < line {
+		// The line is past the end of the text.
+		return n.ByteOffset(n.textLen)
+	}
+	end := n.textLen
+	if np.LineNumber < int32(len(n.prefixLen)) {
+		end = n.prefixLen[np.LineNumber] - int32(len(lineEnd))
+	}
+	offset := np.ByteOffset
+	for ; col > 0 && offset < end; col-- {
+		_, size := utf8.DecodeRune(n.text[offset:end])
+		offset += int32(size)
+	}
+	return n.ByteOffset(offset)
+}
@@ -118,6 +118,75 @@ last line without newline`
 	}
 }
 
+func TestNormalizerUnicode(t *testing.T) {
+	// "日本" is 6 bytes and "é" is 2; line 2 starts at offset 17.
+	const text = "x := 1 // 日本\ncafé := x\n"
+	n := NewNormalizer([]byte(text))
+
+	tests := []struct {
+		p, expected *cpb.Point
+		col         int32
+	}{
+		{
+			&cpb.Point{ByteOffset: 10},
+			&cpb.Point{ByteOffset: 10, LineNumber: 1, ColumnOffset: 10}, 10,
+		},
+		{
+			&cpb.Point{ByteOffset: 11}, // within 日
+			&cpb.Point{ByteOffset: 10, LineNumber: 1, ColumnOffset: 10}, 10,
+		},
+		{
+			&cpb.Point{ByteOffset: 13},
+			&cpb.Point{ByteOffset: 13, LineNumber: 1, ColumnOffset: 13}, 11,
+		},
+		{
+			&cpb.Point{LineNumber: 1, ColumnOffset: 14}, // within 本
+			&cpb.Point{ByteOffset: 13, LineNumber: 1, ColumnOffset: 13}, 11,
+		},
+		{
+			&cpb.Point{ByteOffset: 16}, // end of line 1
+			&cpb.Point{ByteOffset: 16, LineNumber: 1, ColumnOffset: 16}, 12,
+		},
+		{
+			&cpb.Point{ByteOffset: 21}, // within é
+			&cpb.Point{ByteOffset: 20, LineNumber: 2, ColumnOffset: 3}, 3,
+		},
+		{
+			&cpb.Point{LineNumber: 2, ColumnOffset: 5},
+			&cpb.Point{ByteOffset: 22, LineNumber: 2, ColumnOffset: 5}, 4,
+		},
+	}
+	for _, test := range tests {
+		if p := n.Point(test.p); !proto.Equal(p, test.expected) {
+			t.Errorf("n.Point({%v}): expected {%v}; found {%v}", test.p, test.expected, p)
+		}
+		if col := n.CharacterColumn(test.p); col != test.col {
+			t.Errorf("n.CharacterColumn({%v}): expected %d; found %d", test.p, test.col, col)
+		}
+	}
+
+	for _, test := range []struct {
+		line, col int32
+		expected  *cpb.Point
+	}{
+		{1, 11, &cpb.Point{ByteOffset: 13, LineNumber: 1, ColumnOffset: 13}},
+		{1, 99, &cpb.Point{ByteOffset: 16, LineNumber: 1, ColumnOffset: 16}}, // past end of line
+		{2, 4, &cpb.Point{ByteOffset: 22, LineNumber: 2, ColumnOffset: 5}},
+		{3, 0, &cpb.Point{ByteOffset: 28, LineNumber: 3, ColumnOffset: 0}},
+		{4, 2, &cpb.Point{ByteOffset: 28, LineNumber: 3, ColumnOffset: 0}}, // past end of text
+	} {
+		if p := n.CharacterPoint(test.line, test.col); !proto.Equal(p, test.expected) {
+			t.Errorf("n.CharacterPoint(%d, %d): expected {%v}; found {%v}", test.line, test.col, test.expected, p)
+		}
+	}
+
+	// Invalid UTF-8 is treated as single bytes.
+	n = NewNormalizer([]byte("a\x80\x80b"))
+	if p := n.ByteOffset(2); p.ByteOffset != 2 {
+		t.Errorf("n.ByteOffset(2) in invalid UTF-8: found {%v}", p)
+	}
+}
+
 func TestPatcher(t *testing.T) {
 	tests := []struct {
 		oldText, newText string
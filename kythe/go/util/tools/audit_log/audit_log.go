@@ -0,0 +1,94 @@
+/*
+ * Copyright 2026 The Kythe Authors. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Binary audit_log queries the audit logs written by http_server and
+// write_entries with --audit_log, including their rotated files.
+//
+// Example:
+//
+//	# Every failed admin request in the last day.
+//	audit_log --since 24h --action admin. --failed /var/log/kythe/audit.log
+//
+// Example:
+//
+//	# Every GraphStore write by a user, as JSON.
+//	audit_log --actor alice@build1 --action graphstore. --json /var/log/kythe/audit.log
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"kythe.io/kythe/go/util/audit"
+	"kythe.io/kythe/go/util/flagutil"
+	"kythe.io/kythe/go/util/log"
+)
+
+var (
+	since  = flag.String("since", "", "If set, only events at or after this time: RFC 3339, or a duration before now (e.g. 24h)")
+	until  = flag.String("until", "", "If set, only events before this time: RFC 3339, or a duration before now")
+	actor  = flag.String("actor", "", "If set, only events by this actor")
+	action = flag.String("action", "", "If set, only events whose action has this prefix (e.g. admin. or reload)")
+	failed = flag.Bool("failed", false, "Only events of failed operations")
+
+	jsonOutput = flag.Bool("json", false, "Print events as JSON, one per line")
+)
+
+func init() {
+	flag.Usage = flagutil.SimpleUsage("Queries an audit log",
+		"[--since t] [--until t] [--actor a] [--action prefix] [--failed] [--json] audit-log")
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flagutil.UsageError("expected exactly one audit log")
+	}
+	q := &audit.Query{Actor: *actor, Action: *action, FailedOnly: *failed}
+	var err error
+	if q.Since, err = parseTime(*since); err != nil {
+		flagutil.UsageErrorf("invalid --since: %v", err)
+	}
+	if q.Until, err = parseTime(*until); err != nil {
+		flagutil.UsageErrorf("invalid --until: %v", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	if err := audit.Read(flag.Arg(0), q, func(e *audit.Event) error {
+		if *jsonOutput {
+			return enc.Encode(e)
+		}
+		_, err := fmt.Println(e)
+		return err
+	}); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseTime parses s as an RFC 3339 time or a duration before now.  An empty
+// string is the zero time.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
@@ -69,6 +69,52 @@ func (DirectoryReply_Kind) EnumDescriptor() ([]byte, []int) {
 	return file_kythe_proto_filetree_proto_rawDescGZIP(), []int{3, 0}
 }
 
+type SearchRequest_Syntax int32
+
+const (
+	SearchRequest_SUBSTRING SearchRequest_Syntax = 0
+	SearchRequest_GLOB      SearchRequest_Syntax = 1
+)
+
+// Enum value maps for SearchRequest_Syntax.
+var (
+	SearchRequest_Syntax_name = map[int32]string{
+		0: "SUBSTRING",
+		1: "GLOB",
+	}
+	SearchRequest_Syntax_value = map[string]int32{
+		"SUBSTRING": 0,
+		"GLOB":      1,
+	}
+)
+
+func (x SearchRequest_Syntax) Enum() *SearchRequest_Syntax {
+	p := new(SearchRequest_Syntax)
+	*p = x
+	return p
+}
+
+func (x SearchRequest_Syntax) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SearchRequest_Syntax) Descriptor() protoreflect.EnumDescriptor {
+	return file_kythe_proto_filetree_proto_enumTypes[1].Descriptor()
+}
+
+func (SearchRequest_Syntax) Type() protoreflect.EnumType {
+	return &file_kythe_proto_filetree_proto_enumTypes[1]
+}
+
+func (x SearchRequest_Syntax) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SearchRequest_Syntax.Descriptor instead.
+func (SearchRequest_Syntax) EnumDescriptor() ([]byte, []int) {
+	return file_kythe_proto_filetree_proto_rawDescGZIP(), []int{5, 0}
+}
+
 type CorpusRootsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -163,6 +209,8 @@ type DirectoryRequest struct {
 	Root                    string `protobuf:"bytes,2,opt,name=root,proto3" json:"root,omitempty"`
 	Path                    string `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
 	IncludeFilesMissingText bool   `protobuf:"varint,4,opt,name=include_files_missing_text,json=includeFilesMissingText,proto3" json:"include_files_missing_text,omitempty"`
+	PageSize                int32  `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken               string `protobuf:"bytes,6,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 }
 
 func (x *DirectoryRequest) Reset() {
@@ -225,15 +273,30 @@ func (x *DirectoryRequest) GetIncludeFilesMissingText() bool {
 	return false
 }
 
+func (x *DirectoryRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *DirectoryRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
 type DirectoryReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Corpus string                  `protobuf:"bytes,3,opt,name=corpus,proto3" json:"corpus,omitempty"`
-	Root   string                  `protobuf:"bytes,4,opt,name=root,proto3" json:"root,omitempty"`
-	Path   string                  `protobuf:"bytes,5,opt,name=path,proto3" json:"path,omitempty"`
-	Entry  []*DirectoryReply_Entry `protobuf:"bytes,6,rep,name=entry,proto3" json:"entry,omitempty"`
+	Corpus        string                  `protobuf:"bytes,3,opt,name=corpus,proto3" json:"corpus,omitempty"`
+	Root          string                  `protobuf:"bytes,4,opt,name=root,proto3" json:"root,omitempty"`
+	Path          string                  `protobuf:"bytes,5,opt,name=path,proto3" json:"path,omitempty"`
+	Entry         []*DirectoryReply_Entry `protobuf:"bytes,6,rep,name=entry,proto3" json:"entry,omitempty"`
+	NextPageToken string                  `protobuf:"bytes,7,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
 }
 
 func (x *DirectoryReply) Reset() {
@@ -296,6 +359,226 @@ func (x *DirectoryReply) GetEntry() []*DirectoryReply_Entry {
 	return nil
 }
 
+func (x *DirectoryReply) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type TreeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Corpus                  string `protobuf:"bytes,1,opt,name=corpus,proto3" json:"corpus,omitempty"`
+	Root                    string `protobuf:"bytes,2,opt,name=root,proto3" json:"root,omitempty"`
+	Path                    string `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	MaxDepth                int32  `protobuf:"varint,4,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`
+	IncludeFilesMissingText bool   `protobuf:"varint,5,opt,name=include_files_missing_text,json=includeFilesMissingText,proto3" json:"include_files_missing_text,omitempty"`
+}
+
+func (x *TreeRequest) Reset() {
+	*x = TreeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_kythe_proto_filetree_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeRequest) ProtoMessage() {}
+
+func (x *TreeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kythe_proto_filetree_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeRequest.ProtoReflect.Descriptor instead.
+func (*TreeRequest) Descriptor() ([]byte, []int) {
+	return file_kythe_proto_filetree_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TreeRequest) GetCorpus() string {
+	if x != nil {
+		return x.Corpus
+	}
+	return ""
+}
+
+func (x *TreeRequest) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *TreeRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *TreeRequest) GetMaxDepth() int32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *TreeRequest) GetIncludeFilesMissingText() bool {
+	if x != nil {
+		return x.IncludeFilesMissingText
+	}
+	return false
+}
+
+type SearchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Corpus     string               `protobuf:"bytes,1,opt,name=corpus,proto3" json:"corpus,omitempty"`
+	Root       string               `protobuf:"bytes,2,opt,name=root,proto3" json:"root,omitempty"`
+	Pattern    string               `protobuf:"bytes,3,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	Syntax     SearchRequest_Syntax `protobuf:"varint,4,opt,name=syntax,proto3,enum=kythe.proto.SearchRequest_Syntax" json:"syntax,omitempty"`
+	MaxResults int32                `protobuf:"varint,5,opt,name=max_results,json=maxResults,proto3" json:"max_results,omitempty"`
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_kythe_proto_filetree_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kythe_proto_filetree_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_kythe_proto_filetree_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SearchRequest) GetCorpus() string {
+	if x != nil {
+		return x.Corpus
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetSyntax() SearchRequest_Syntax {
+	if x != nil {
+		return x.Syntax
+	}
+	return SearchRequest_SUBSTRING
+}
+
+func (x *SearchRequest) GetMaxResults() int32 {
+	if x != nil {
+		return x.MaxResults
+	}
+	return 0
+}
+
+type SearchReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ticket    []string `protobuf:"bytes,1,rep,name=ticket,proto3" json:"ticket,omitempty"`
+	Truncated bool     `protobuf:"varint,2,opt,name=truncated,proto3" json:"truncated,omitempty"`
+}
+
+func (x *SearchReply) Reset() {
+	*x = SearchReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_kythe_proto_filetree_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SearchReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchReply) ProtoMessage() {}
+
+func (x *SearchReply) ProtoReflect() protoreflect.Message {
+	mi := &file_kythe_proto_filetree_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchReply.ProtoReflect.Descriptor instead.
+func (*SearchReply) Descriptor() ([]byte, []int) {
+	return file_kythe_proto_filetree_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SearchReply) GetTicket() []string {
+	if x != nil {
+		return x.Ticket
+	}
+	return nil
+}
+
+func (x *SearchReply) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
 type CorpusRootsReply_Corpus struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -309,7 +592,7 @@ type CorpusRootsReply_Corpus struct {
 func (x *CorpusRootsReply_Corpus) Reset() {
 	*x = CorpusRootsReply_Corpus{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_kythe_proto_filetree_proto_msgTypes[4]
+		mi := &file_kythe_proto_filetree_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -322,7 +605,7 @@ func (x *CorpusRootsReply_Corpus) String() string {
 func (*CorpusRootsReply_Corpus) ProtoMessage() {}
 
 func (x *CorpusRootsReply_Corpus) ProtoReflect() protoreflect.Message {
-	mi := &file_kythe_proto_filetree_proto_msgTypes[4]
+	mi := &file_kythe_proto_filetree_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -364,17 +647,21 @@ type DirectoryReply_Entry struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Kind        DirectoryReply_Kind `protobuf:"varint,1,opt,name=kind,proto3,enum=kythe.proto.DirectoryReply_Kind" json:"kind,omitempty"`
-	Name        string              `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	BuildConfig []string            `protobuf:"bytes,3,rep,name=build_config,json=buildConfig,proto3" json:"build_config,omitempty"`
-	Generated   bool                `protobuf:"varint,4,opt,name=generated,proto3" json:"generated,omitempty"`
-	MissingText bool                `protobuf:"varint,5,opt,name=missing_text,json=missingText,proto3" json:"missing_text,omitempty"`
+	Kind        DirectoryReply_Kind     `protobuf:"varint,1,opt,name=kind,proto3,enum=kythe.proto.DirectoryReply_Kind" json:"kind,omitempty"`
+	Name        string                  `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	BuildConfig []string                `protobuf:"bytes,3,rep,name=build_config,json=buildConfig,proto3" json:"build_config,omitempty"`
+	Generated   bool                    `protobuf:"varint,4,opt,name=generated,proto3" json:"generated,omitempty"`
+	MissingText bool                    `protobuf:"varint,5,opt,name=missing_text,json=missingText,proto3" json:"missing_text,omitempty"`
+	Entry       []*DirectoryReply_Entry `protobuf:"bytes,6,rep,name=entry,proto3" json:"entry,omitempty"`
+	Language    string                  `protobuf:"bytes,7,opt,name=language,proto3" json:"language,omitempty"`
+	Encoding    string                  `protobuf:"bytes,8,opt,name=encoding,proto3" json:"encoding,omitempty"`
+	Size        int64                   `protobuf:"varint,9,opt,name=size,proto3" json:"size,omitempty"`
 }
 
 func (x *DirectoryReply_Entry) Reset() {
 	*x = DirectoryReply_Entry{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_kythe_proto_filetree_proto_msgTypes[5]
+		mi := &file_kythe_proto_filetree_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -387,7 +674,7 @@ func (x *DirectoryReply_Entry) String() string {
 func (*DirectoryReply_Entry) ProtoMessage() {}
 
 func (x *DirectoryReply_Entry) ProtoReflect() protoreflect.Message {
-	mi := &file_kythe_proto_filetree_proto_msgTypes[5]
+	mi := &file_kythe_proto_filetree_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -438,6 +725,34 @@ func (x *DirectoryReply_Entry) GetMissingText() bool {
 	return false
 }
 
+func (x *DirectoryReply_Entry) GetEntry() []*DirectoryReply_Entry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+func (x *DirectoryReply_Entry) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *DirectoryReply_Entry) GetEncoding() string {
+	if x != nil {
+		return x.Encoding
+	}
+	return ""
+}
+
+func (x *DirectoryReply_Entry) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
 var File_kythe_proto_filetree_proto protoreflect.FileDescriptor
 
 var file_kythe_proto_filetree_proto_rawDesc = []byte{
@@ -455,7 +770,7 @@ var file_kythe_proto_filetree_proto_rawDesc = []byte{
 	0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04,
 	0x72, 0x6f, 0x6f, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x5f, 0x63, 0x6f,
 	0x6e, 0x66, 0x69, 0x67, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x62, 0x75, 0x69, 0x6c,
-	0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0x8f, 0x01, 0x0a, 0x10, 0x44, 0x69, 0x72, 0x65,
+	0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0xcb, 0x01, 0x0a, 0x10, 0x44, 0x69, 0x72, 0x65,
 	0x63, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06,
 	0x63, 0x6f, 0x72, 0x70, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x6f,
 	0x72, 0x70, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01,
@@ -464,47 +779,98 @@ var file_kythe_proto_filetree_proto_rawDesc = []byte{
 	0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x5f, 0x6d, 0x69,
 	0x73, 0x73, 0x69, 0x6e, 0x67, 0x5f, 0x74, 0x65, 0x78, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08,
 	0x52, 0x17, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x4d, 0x69,
-	0x73, 0x73, 0x69, 0x6e, 0x67, 0x54, 0x65, 0x78, 0x74, 0x22, 0xfb, 0x02, 0x0a, 0x0e, 0x44, 0x69,
-	0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x16, 0x0a, 0x06,
-	0x63, 0x6f, 0x72, 0x70, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x6f,
-	0x72, 0x70, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x04, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68,
-	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x37, 0x0a, 0x05,
-	0x65, 0x6e, 0x74, 0x72, 0x79, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x6b, 0x79,
-	0x74, 0x68, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74,
-	0x6f, 0x72, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x05,
-	0x65, 0x6e, 0x74, 0x72, 0x79, 0x1a, 0xb5, 0x01, 0x0a, 0x05, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
-	0x34, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x20, 0x2e,
-	0x6b, 0x79, 0x74, 0x68, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x69, 0x72, 0x65,
-	0x63, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x2e, 0x4b, 0x69, 0x6e, 0x64, 0x52,
-	0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x62, 0x75, 0x69,
-	0x6c, 0x64, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52,
-	0x0b, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x1c, 0x0a, 0x09,
-	0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52,
-	0x09, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x69,
-	0x73, 0x73, 0x69, 0x6e, 0x67, 0x5f, 0x74, 0x65, 0x78, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08,
-	0x52, 0x0b, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x54, 0x65, 0x78, 0x74, 0x22, 0x2c, 0x0a,
-	0x04, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e,
-	0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x46, 0x49, 0x4c, 0x45, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09,
-	0x44, 0x49, 0x52, 0x45, 0x43, 0x54, 0x4f, 0x52, 0x59, 0x10, 0x02, 0x4a, 0x04, 0x08, 0x01, 0x10,
-	0x02, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x32, 0xad, 0x01, 0x0a, 0x0f, 0x46, 0x69, 0x6c, 0x65,
-	0x54, 0x72, 0x65, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x4f, 0x0a, 0x0b, 0x43,
-	0x6f, 0x72, 0x70, 0x75, 0x73, 0x52, 0x6f, 0x6f, 0x74, 0x73, 0x12, 0x1f, 0x2e, 0x6b, 0x79, 0x74,
+	0x73, 0x73, 0x69, 0x6e, 0x67, 0x54, 0x65, 0x78, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67,
+	0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61,
+	0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0xa8, 0x04, 0x0a, 0x0e, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x72, 0x70,
+	0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x6f, 0x72, 0x70, 0x75, 0x73,
+	0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x72, 0x6f, 0x6f, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x37, 0x0a, 0x05, 0x65, 0x6e, 0x74, 0x72,
+	0x79, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x6b, 0x79, 0x74, 0x68, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x05, 0x65, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74,
+	0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x1a, 0xba, 0x02, 0x0a, 0x05, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x12, 0x34, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x20, 0x2e, 0x6b, 0x79, 0x74, 0x68, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x2e, 0x4b,
+	0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x21, 0x0a,
+	0x0c, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0b, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x12, 0x1c, 0x0a, 0x09, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x09, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x12, 0x21,
+	0x0a, 0x0c, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x5f, 0x74, 0x65, 0x78, 0x74, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x54, 0x65, 0x78,
+	0x74, 0x12, 0x37, 0x0a, 0x05, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x21, 0x2e, 0x6b, 0x79, 0x74, 0x68, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44,
+	0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x2e, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x05, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61,
+	0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61,
+	0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69,
+	0x6e, 0x67, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69,
+	0x6e, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x22, 0x2c, 0x0a, 0x04, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x0b,
+	0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x46,
+	0x49, 0x4c, 0x45, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x44, 0x49, 0x52, 0x45, 0x43, 0x54, 0x4f,
+	0x52, 0x59, 0x10, 0x02, 0x4a, 0x04, 0x08, 0x01, 0x10, 0x02, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03,
+	0x22, 0xa7, 0x01, 0x0a, 0x0b, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x72, 0x70, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x63, 0x6f, 0x72, 0x70, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x70, 0x61, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68,
+	0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x44, 0x65, 0x70, 0x74, 0x68, 0x12, 0x3b, 0x0a,
+	0x1a, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x5f, 0x6d,
+	0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x5f, 0x74, 0x65, 0x78, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x17, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x4d,
+	0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x54, 0x65, 0x78, 0x74, 0x22, 0xd4, 0x01, 0x0a, 0x0d, 0x53,
+	0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x63, 0x6f, 0x72, 0x70, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x6f,
+	0x72, 0x70, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74,
+	0x65, 0x72, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65,
+	0x72, 0x6e, 0x12, 0x39, 0x0a, 0x06, 0x73, 0x79, 0x6e, 0x74, 0x61, 0x78, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x21, 0x2e, 0x6b, 0x79, 0x74, 0x68, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x53,
+	0x79, 0x6e, 0x74, 0x61, 0x78, 0x52, 0x06, 0x73, 0x79, 0x6e, 0x74, 0x61, 0x78, 0x12, 0x1f, 0x0a,
+	0x0b, 0x6d, 0x61, 0x78, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0a, 0x6d, 0x61, 0x78, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x22, 0x21,
+	0x0a, 0x06, 0x53, 0x79, 0x6e, 0x74, 0x61, 0x78, 0x12, 0x0d, 0x0a, 0x09, 0x53, 0x55, 0x42, 0x53,
+	0x54, 0x52, 0x49, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x47, 0x4c, 0x4f, 0x42, 0x10,
+	0x01, 0x22, 0x43, 0x0a, 0x0b, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x16, 0x0a, 0x06, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x06, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x72, 0x75, 0x6e,
+	0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x74, 0x72, 0x75,
+	0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x32, 0xb0, 0x02, 0x0a, 0x0f, 0x46, 0x69, 0x6c, 0x65, 0x54,
+	0x72, 0x65, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x4f, 0x0a, 0x0b, 0x43, 0x6f,
+	0x72, 0x70, 0x75, 0x73, 0x52, 0x6f, 0x6f, 0x74, 0x73, 0x12, 0x1f, 0x2e, 0x6b, 0x79, 0x74, 0x68,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x72, 0x70, 0x75, 0x73, 0x52, 0x6f,
+	0x6f, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x6b, 0x79, 0x74,
 	0x68, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x72, 0x70, 0x75, 0x73, 0x52,
-	0x6f, 0x6f, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x6b, 0x79,
-	0x74, 0x68, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x72, 0x70, 0x75, 0x73,
-	0x52, 0x6f, 0x6f, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x49, 0x0a, 0x09,
-	0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x1d, 0x2e, 0x6b, 0x79, 0x74, 0x68,
-	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72,
-	0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x6b, 0x79, 0x74, 0x68, 0x65,
+	0x6f, 0x6f, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x49, 0x0a, 0x09, 0x44,
+	0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x1d, 0x2e, 0x6b, 0x79, 0x74, 0x68, 0x65,
 	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79,
-	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x49, 0x0a, 0x1f, 0x63, 0x6f, 0x6d, 0x2e, 0x67,
-	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x64, 0x65, 0x76, 0x74, 0x6f, 0x6f, 0x6c, 0x73, 0x2e, 0x6b,
-	0x79, 0x74, 0x68, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5a, 0x26, 0x6b, 0x79, 0x74, 0x68,
-	0x65, 0x2e, 0x69, 0x6f, 0x2f, 0x6b, 0x79, 0x74, 0x68, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x2f, 0x66, 0x69, 0x6c, 0x65, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x67, 0x6f, 0x5f, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x6b, 0x79, 0x74, 0x68, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x3f, 0x0a, 0x04, 0x54, 0x72, 0x65, 0x65, 0x12, 0x18,
+	0x2e, 0x6b, 0x79, 0x74, 0x68, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x54, 0x72, 0x65,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x6b, 0x79, 0x74, 0x68, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x40, 0x0a, 0x06, 0x53, 0x65, 0x61, 0x72, 0x63,
+	0x68, 0x12, 0x1a, 0x2e, 0x6b, 0x79, 0x74, 0x68, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e,
+	0x6b, 0x79, 0x74, 0x68, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x65, 0x61, 0x72,
+	0x63, 0x68, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x49, 0x0a, 0x1f, 0x63, 0x6f, 0x6d,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x64, 0x65, 0x76, 0x74, 0x6f, 0x6f, 0x6c, 0x73,
+	0x2e, 0x6b, 0x79, 0x74, 0x68, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5a, 0x26, 0x6b, 0x79,
+	0x74, 0x68, 0x65, 0x2e, 0x69, 0x6f, 0x2f, 0x6b, 0x79, 0x74, 0x68, 0x65, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2f, 0x66, 0x69, 0x6c, 0x65, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x67, 0x6f, 0x5f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -519,30 +885,40 @@ func file_kythe_proto_filetree_proto_rawDescGZIP() []byte {
 	return file_kythe_proto_filetree_proto_rawDescData
 }
 
-var file_kythe_proto_filetree_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_kythe_proto_filetree_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_kythe_proto_filetree_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_kythe_proto_filetree_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_kythe_proto_filetree_proto_goTypes = []interface{}{
 	(DirectoryReply_Kind)(0),        // 0: kythe.proto.DirectoryReply.Kind
-	(*CorpusRootsRequest)(nil),      // 1: kythe.proto.CorpusRootsRequest
-	(*CorpusRootsReply)(nil),        // 2: kythe.proto.CorpusRootsReply
-	(*DirectoryRequest)(nil),        // 3: kythe.proto.DirectoryRequest
-	(*DirectoryReply)(nil),          // 4: kythe.proto.DirectoryReply
-	(*CorpusRootsReply_Corpus)(nil), // 5: kythe.proto.CorpusRootsReply.Corpus
-	(*DirectoryReply_Entry)(nil),    // 6: kythe.proto.DirectoryReply.Entry
+	(SearchRequest_Syntax)(0),       // 1: kythe.proto.SearchRequest.Syntax
+	(*CorpusRootsRequest)(nil),      // 2: kythe.proto.CorpusRootsRequest
+	(*CorpusRootsReply)(nil),        // 3: kythe.proto.CorpusRootsReply
+	(*DirectoryRequest)(nil),        // 4: kythe.proto.DirectoryRequest
+	(*DirectoryReply)(nil),          // 5: kythe.proto.DirectoryReply
+	(*TreeRequest)(nil),             // 6: kythe.proto.TreeRequest
+	(*SearchRequest)(nil),           // 7: kythe.proto.SearchRequest
+	(*SearchReply)(nil),             // 8: kythe.proto.SearchReply
+	(*CorpusRootsReply_Corpus)(nil), // 9: kythe.proto.CorpusRootsReply.Corpus
+	(*DirectoryReply_Entry)(nil),    // 10: kythe.proto.DirectoryReply.Entry
 }
 var file_kythe_proto_filetree_proto_depIdxs = []int32{
-	5, // 0: kythe.proto.CorpusRootsReply.corpus:type_name -> kythe.proto.CorpusRootsReply.Corpus
-	6, // 1: kythe.proto.DirectoryReply.entry:type_name -> kythe.proto.DirectoryReply.Entry
-	0, // 2: kythe.proto.DirectoryReply.Entry.kind:type_name -> kythe.proto.DirectoryReply.Kind
-	1, // 3: kythe.proto.FileTreeService.CorpusRoots:input_type -> kythe.proto.CorpusRootsRequest
-	3, // 4: kythe.proto.FileTreeService.Directory:input_type -> kythe.proto.DirectoryRequest
-	2, // 5: kythe.proto.FileTreeService.CorpusRoots:output_type -> kythe.proto.CorpusRootsReply
-	4, // 6: kythe.proto.FileTreeService.Directory:output_type -> kythe.proto.DirectoryReply
-	5, // [5:7] is the sub-list for method output_type
-	3, // [3:5] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	9,  // 0: kythe.proto.CorpusRootsReply.corpus:type_name -> kythe.proto.CorpusRootsReply.Corpus
+	10, // 1: kythe.proto.DirectoryReply.entry:type_name -> kythe.proto.DirectoryReply.Entry
+	1,  // 2: kythe.proto.SearchRequest.syntax:type_name -> kythe.proto.SearchRequest.Syntax
+	0,  // 3: kythe.proto.DirectoryReply.Entry.kind:type_name -> kythe.proto.DirectoryReply.Kind
+	10, // 4: kythe.proto.DirectoryReply.Entry.entry:type_name -> kythe.proto.DirectoryReply.Entry
+	2,  // 5: kythe.proto.FileTreeService.CorpusRoots:input_type -> kythe.proto.CorpusRootsRequest
+	4,  // 6: kythe.proto.FileTreeService.Directory:input_type -> kythe.proto.DirectoryRequest
+	6,  // 7: kythe.proto.FileTreeService.Tree:input_type -> kythe.proto.TreeRequest
+	7,  // 8: kythe.proto.FileTreeService.Search:input_type -> kythe.proto.SearchRequest
+	3,  // 9: kythe.proto.FileTreeService.CorpusRoots:output_type -> kythe.proto.CorpusRootsReply
+	5,  // 10: kythe.proto.FileTreeService.Directory:output_type -> kythe.proto.DirectoryReply
+	5,  // 11: kythe.proto.FileTreeService.Tree:output_type -> kythe.proto.DirectoryReply
+	8,  // 12: kythe.proto.FileTreeService.Search:output_type -> kythe.proto.SearchReply
+	9,  // [9:13] is the sub-list for method output_type
+	5,  // [5:9] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_kythe_proto_filetree_proto_init() }
@@ -600,7 +976,7 @@ func file_kythe_proto_filetree_proto_init() {
 			}
 		}
 		file_kythe_proto_filetree_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CorpusRootsReply_Corpus); i {
+			switch v := v.(*TreeRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -612,6 +988,42 @@ func file_kythe_proto_filetree_proto_init() {
 			}
 		}
 		file_kythe_proto_filetree_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_kythe_proto_filetree_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_kythe_proto_filetree_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CorpusRootsReply_Corpus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_kythe_proto_filetree_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DirectoryReply_Entry); i {
 			case 0:
 				return &v.state
@@ -629,8 +1041,8 @@ func file_kythe_proto_filetree_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_kythe_proto_filetree_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   6,
+			NumEnums:      2,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -3,6 +3,27 @@ load("@io_bazel_rules_go//proto:def.bzl", _go_proto_library = "go_proto_library"
 
 KYTHE_IMPORT_BASE = "kythe.io/kythe/proto"
 
+# The compilers for a go_proto_library whose gRPC stubs are generated into a
+# separate _grpc.pb.go file, which is synced alongside the .pb.go file.
+_GO_GRPC_COMPILERS = [
+    "@io_bazel_rules_go//proto:go_proto",
+    "@io_bazel_rules_go//proto:go_grpc_v2",
+]
+
+def _select_generated_src(name, src, out, grpc):
+    """Copies the .pb.go file generated by src, or its _grpc.pb.go file if
+    grpc is set, to out."""
+    if grpc:
+        pattern = "*_grpc.pb.go) cp $$f $@"
+    else:
+        pattern = "*_grpc.pb.go) ;; *.pb.go) cp $$f $@"
+    native.genrule(
+        name = name,
+        srcs = [src],
+        outs = [out],
+        cmd = "for f in $(SRCS); do case $$f in %s ;; esac; done" % pattern,
+    )
+
 def go_proto_library(
         name = None,
         proto = None,
@@ -10,6 +31,7 @@ def go_proto_library(
         importpath = None,
         visibility = None,
         compilers = None,
+        grpc = False,
         suggested_update_target = "//{package}:update"):
     """Helper for go_proto_library for kythe project.
 
@@ -33,12 +55,17 @@ def go_proto_library(
     Args:
       proto: the proto lib to build a _go_proto lib for
       deps: the deps for the proto lib
+      compilers: ignored, so that the synced .pb.go file never embeds gRPC
+        stubs; set grpc instead
+      grpc: whether to generate gRPC stubs into a separate _grpc.pb.go file,
+        which is synced alongside the .pb.go file
     """
     if suggested_update_target != None:
         suggested_update_target = suggested_update_target.format(package = native.package_name())
 
     base = proto.rsplit(":", 2)[-1]
-    filename = "_".join(base.split("_")[:-1]) + ".pb.go"
+    stem = "_".join(base.split("_")[:-1])
+    filename = stem + ".pb.go"
     if name == None:
         if base.endswith("_proto"):
             name = base[:-len("proto")] + "go_proto"
@@ -53,15 +80,36 @@ def go_proto_library(
         importpath = importpath,
         proto = proto,
         visibility = visibility,
+        compilers = _GO_GRPC_COMPILERS if grpc else None,
     )
     native.filegroup(
         name = name + "_src",
         output_group = "go_generated_srcs",
         srcs = [name],
     )
+    if not grpc:
+        write_source_file(
+            name = name + "_sync",
+            in_file = name + "_src",
+            out_file = name + "/" + filename,
+            suggested_update_target = suggested_update_target,
+        )
+        return
+
+    # With gRPC stubs the generated sources are two files, which are synced
+    # separately.
+    grpc_filename = stem + "_grpc.pb.go"
+    _select_generated_src(name + "_pb_src", name + "_src", name + "_pb/" + filename, grpc = False)
+    _select_generated_src(name + "_grpc_src", name + "_src", name + "_grpc/" + grpc_filename, grpc = True)
     write_source_file(
         name = name + "_sync",
-        in_file = name + "_src",
+        in_file = name + "_pb_src",
         out_file = name + "/" + filename,
         suggested_update_target = suggested_update_target,
     )
+    write_source_file(
+        name = name + "_grpc_sync",
+        in_file = name + "_grpc_src",
+        out_file = name + "/" + grpc_filename,
+        suggested_update_target = suggested_update_target,
+    )